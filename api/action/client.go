@@ -118,3 +118,57 @@ func (c *Client) ApplicationCharmActions(arg params.Entity) (map[string]params.A
 	}
 	return result.Actions, nil
 }
+
+// ScheduleActions adds schedules on which actions are queued, either
+// once at a given time or repeatedly on a cron-style schedule.
+func (c *Client) ScheduleActions(arg params.ActionSchedules) (params.ActionScheduleResults, error) {
+	results := params.ActionScheduleResults{}
+	if c.BestAPIVersion() < 4 {
+		return results, errors.NotSupportedf("scheduling actions on this controller")
+	}
+	err := c.facade.FacadeCall("ScheduleActions", arg, &results)
+	return results, err
+}
+
+// ActionSchedules returns all of the action schedules in the model.
+func (c *Client) ActionSchedules() (params.ActionScheduleResults, error) {
+	results := params.ActionScheduleResults{}
+	if c.BestAPIVersion() < 4 {
+		return results, errors.NotSupportedf("scheduling actions on this controller")
+	}
+	err := c.facade.FacadeCall("ActionSchedules", nil, &results)
+	return results, err
+}
+
+// RemoveActionSchedules removes the action schedules with the given
+// IDs.
+func (c *Client) RemoveActionSchedules(arg params.ActionScheduleIds) (params.ErrorResults, error) {
+	results := params.ErrorResults{}
+	if c.BestAPIVersion() < 4 {
+		return results, errors.NotSupportedf("scheduling actions on this controller")
+	}
+	err := c.facade.FacadeCall("RemoveActionSchedules", arg, &results)
+	return results, err
+}
+
+// EnqueueOperation queues an action on a set of receivers in batches,
+// as a single operation.
+func (c *Client) EnqueueOperation(arg params.OperationArgs) (params.OperationResult, error) {
+	result := params.OperationResult{}
+	if c.BestAPIVersion() < 4 {
+		return result, errors.NotSupportedf("batched actions on this controller")
+	}
+	err := c.facade.FacadeCall("EnqueueOperation", arg, &result)
+	return result, err
+}
+
+// Operations returns the operations with the given IDs, or all of the
+// operations in the model if no IDs are given.
+func (c *Client) Operations(arg params.OperationIds) (params.OperationResults, error) {
+	results := params.OperationResults{}
+	if c.BestAPIVersion() < 4 {
+		return results, errors.NotSupportedf("batched actions on this controller")
+	}
+	err := c.facade.FacadeCall("Operations", arg, &results)
+	return results, err
+}
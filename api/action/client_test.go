@@ -127,3 +127,107 @@ func patchApplicationCharmActions(c *gc.C, apiCli *action.Client, patchResults [
 		},
 	)
 }
+
+func (s *actionSuite) TestScheduleActions(c *gc.C) {
+	args := params.ActionSchedules{
+		Schedules: []params.ActionSchedule{{
+			Receiver: "unit-mysql-0",
+			Name:     "backup",
+			Schedule: "@daily",
+		}},
+	}
+	cleanup := action.PatchClientFacadeCall(s.client,
+		func(req string, paramsIn interface{}, resp interface{}) error {
+			c.Assert(req, gc.Equals, "ScheduleActions")
+			c.Assert(paramsIn, jc.DeepEquals, args)
+			result := resp.(*params.ActionScheduleResults)
+			result.Results = []params.ActionScheduleResult{{
+				Schedule: &params.ActionSchedule{Id: "1"},
+			}}
+			return nil
+		},
+	)
+	defer cleanup()
+
+	results, err := s.client.ScheduleActions(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, jc.DeepEquals, []params.ActionScheduleResult{{
+		Schedule: &params.ActionSchedule{Id: "1"},
+	}})
+}
+
+func (s *actionSuite) TestRemoveActionSchedules(c *gc.C) {
+	cleanup := action.PatchClientFacadeCall(s.client,
+		func(req string, paramsIn interface{}, resp interface{}) error {
+			c.Assert(req, gc.Equals, "RemoveActionSchedules")
+			c.Assert(paramsIn, jc.DeepEquals, params.ActionScheduleIds{Ids: []string{"1"}})
+			result := resp.(*params.ErrorResults)
+			result.Results = []params.ErrorResult{{}}
+			return errors.New("boom")
+		},
+	)
+	defer cleanup()
+
+	_, err := s.client.RemoveActionSchedules(params.ActionScheduleIds{Ids: []string{"1"}})
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
+
+func (s *actionSuite) TestEnqueueOperation(c *gc.C) {
+	args := params.OperationArgs{
+		Receivers:   []string{"unit-mysql-0", "unit-mysql-1"},
+		Name:        "backup",
+		MaxParallel: 1,
+	}
+	cleanup := action.PatchClientFacadeCall(s.client,
+		func(req string, paramsIn interface{}, resp interface{}) error {
+			c.Assert(req, gc.Equals, "EnqueueOperation")
+			c.Assert(paramsIn, jc.DeepEquals, args)
+			result := resp.(*params.OperationResult)
+			result.Operation = &params.Operation{Id: "1"}
+			return nil
+		},
+	)
+	defer cleanup()
+
+	result, err := s.client.EnqueueOperation(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.OperationResult{
+		Operation: &params.Operation{Id: "1"},
+	})
+}
+
+func (s *actionSuite) TestRunOperation(c *gc.C) {
+	args := params.RunParams{
+		Commands:    "hostname",
+		Units:       []string{"mysql/0"},
+		MaxParallel: 1,
+	}
+	cleanup := action.PatchClientFacadeCall(s.client,
+		func(req string, paramsIn interface{}, resp interface{}) error {
+			c.Assert(req, gc.Equals, "RunOperation")
+			c.Assert(paramsIn, jc.DeepEquals, args)
+			result := resp.(*params.OperationResult)
+			result.Operation = &params.Operation{Id: "2"}
+			return nil
+		},
+	)
+	defer cleanup()
+
+	result, err := s.client.RunOperation(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Operation, jc.DeepEquals, &params.Operation{Id: "2"})
+}
+
+func (s *actionSuite) TestOperations(c *gc.C) {
+	cleanup := action.PatchClientFacadeCall(s.client,
+		func(req string, paramsIn interface{}, resp interface{}) error {
+			c.Assert(req, gc.Equals, "Operations")
+			c.Assert(paramsIn, jc.DeepEquals, params.OperationIds{Ids: []string{"1"}})
+			return errors.New("boom")
+		},
+	)
+	defer cleanup()
+
+	_, err := s.client.Operations(params.OperationIds{Ids: []string{"1"}})
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
@@ -6,6 +6,8 @@ package action
 import (
 	"time"
 
+	"github.com/juju/errors"
+
 	"github.com/juju/juju/api/base"
 	"github.com/juju/juju/api/common"
 	"github.com/juju/juju/apiserver/params"
@@ -33,3 +35,23 @@ func (s *Facade) Prune(maxHistoryTime time.Duration, maxHistoryMB int) error {
 	}
 	return s.facade.FacadeCall("Prune", p, nil)
 }
+
+// PrunableActions returns a page of at most limit of the completed
+// actions that Prune would remove by age, starting at offset, so that
+// their results can be exported first. The controller may return
+// fewer than limit actions even when there are more to come.
+func (s *Facade) PrunableActions(maxHistoryTime time.Duration, offset, limit int) ([]params.ActionResult, error) {
+	if s.facade.BestAPIVersion() < 2 {
+		return nil, errors.NotImplementedf("PrunableActions() (need V2+)")
+	}
+	p := params.PrunableActionsArgs{
+		MaxHistoryTime: maxHistoryTime,
+		Offset:         offset,
+		Limit:          limit,
+	}
+	var results params.ActionResults
+	if err := s.facade.FacadeCall("PrunableActions", p, &results); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return results.Results, nil
+}
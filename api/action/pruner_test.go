@@ -0,0 +1,52 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package action_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/api/action"
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type prunerSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&prunerSuite{})
+
+func (s *prunerSuite) TestPrunableActions(c *gc.C) {
+	apiCaller := basetesting.BestVersionCaller{
+		APICallerFunc: func(objType string, version int, id, request string, arg, result interface{}) error {
+			c.Check(objType, gc.Equals, "ActionPruner")
+			c.Check(request, gc.Equals, "PrunableActions")
+			c.Check(arg, jc.DeepEquals, params.PrunableActionsArgs{MaxHistoryTime: time.Hour, Offset: 10, Limit: 5})
+			*(result.(*params.ActionResults)) = params.ActionResults{
+				Results: []params.ActionResult{{Status: "completed"}},
+			}
+			return nil
+		},
+		BestVersion: 2,
+	}
+	results, err := action.NewFacade(apiCaller).PrunableActions(time.Hour, 10, 5)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, jc.DeepEquals, []params.ActionResult{{Status: "completed"}})
+}
+
+func (s *prunerSuite) TestPrunableActionsNotImplemented(c *gc.C) {
+	apiCaller := basetesting.BestVersionCaller{
+		APICallerFunc: func(objType string, version int, id, request string, arg, result interface{}) error {
+			c.Fatalf("unexpected call to %s", request)
+			return nil
+		},
+		BestVersion: 1,
+	}
+	_, err := action.NewFacade(apiCaller).PrunableActions(time.Hour, 0, 5)
+	c.Assert(err, gc.ErrorMatches, `PrunableActions\(\) \(need V2\+\) not implemented`)
+}
@@ -6,6 +6,8 @@ package action
 import (
 	"time"
 
+	"github.com/juju/errors"
+
 	"github.com/juju/juju/apiserver/params"
 )
 
@@ -25,3 +27,24 @@ func (c *Client) Run(run params.RunParams) ([]params.ActionResult, error) {
 	err := c.facade.FacadeCall("Run", run, &results)
 	return results.Results, err
 }
+
+// RunOperation runs the commands on the machines and units identified
+// by the run parameters in batches, as a single operation.
+func (c *Client) RunOperation(run params.RunParams) (params.OperationResult, error) {
+	return c.runOperation("RunOperation", run)
+}
+
+// RunOperationOnAllMachines runs the commands on all the machines in
+// batches, as a single operation.
+func (c *Client) RunOperationOnAllMachines(run params.RunParams) (params.OperationResult, error) {
+	return c.runOperation("RunOperationOnAllMachines", run)
+}
+
+func (c *Client) runOperation(method string, run params.RunParams) (params.OperationResult, error) {
+	var result params.OperationResult
+	if c.BestAPIVersion() < 4 {
+		return result, errors.NotSupportedf("batched actions on this controller")
+	}
+	err := c.facade.FacadeCall(method, run, &result)
+	return result, err
+}
@@ -0,0 +1,85 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package actionscheduler
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/base"
+	apiwatcher "github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/core/watcher"
+)
+
+const actionSchedulerFacade = "ActionScheduler"
+
+// API provides access to the ActionScheduler API facade.
+type API struct {
+	facade base.FacadeCaller
+}
+
+// NewAPI creates a new client-side ActionScheduler facade.
+func NewAPI(caller base.APICaller) *API {
+	facadeCaller := base.NewFacadeCaller(caller, actionSchedulerFacade)
+	return &API{facade: facadeCaller}
+}
+
+// WatchActionSchedules returns a watcher that notifies when action
+// schedules in the model are added, changed or removed.
+func (api *API) WatchActionSchedules() (watcher.NotifyWatcher, error) {
+	return api.watch("WatchActionSchedules")
+}
+
+// WatchOperations returns a watcher that notifies when operations in
+// the model are added or change, including when their actions finish.
+func (api *API) WatchOperations() (watcher.NotifyWatcher, error) {
+	return api.watch("WatchOperations")
+}
+
+func (api *API) watch(method string) (watcher.NotifyWatcher, error) {
+	var result params.NotifyWatchResult
+	err := api.facade.FacadeCall(method, nil, &result)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := result.Error; err != nil {
+		return nil, errors.Trace(err)
+	}
+	w := apiwatcher.NewNotifyWatcher(api.facade.RawAPICaller(), result)
+	return w, nil
+}
+
+// RunDueActionSchedules queues the actions of the schedules that are
+// due to run, and returns the time at which a schedule is next due, or
+// the zero time if no more runs are scheduled.
+func (api *API) RunDueActionSchedules() (time.Time, error) {
+	var result params.ActionSchedulerRunResult
+	err := api.facade.FacadeCall("RunDueActionSchedules", nil, &result)
+	if err != nil {
+		return time.Time{}, errors.Trace(err)
+	}
+	if err := result.Error; err != nil {
+		return time.Time{}, errors.Trace(err)
+	}
+	if result.NextRun == nil {
+		return time.Time{}, nil
+	}
+	return *result.NextRun, nil
+}
+
+// AdvanceOperations queues the actions of the running operations on
+// as many further receivers as their parallelism allows.
+func (api *API) AdvanceOperations() error {
+	var result params.ErrorResult
+	err := api.facade.FacadeCall("AdvanceOperations", nil, &result)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if result.Error != nil {
+		return errors.Trace(result.Error)
+	}
+	return nil
+}
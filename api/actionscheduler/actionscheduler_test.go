@@ -0,0 +1,111 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package actionscheduler_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/api/actionscheduler"
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type ActionSchedulerSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&ActionSchedulerSuite{})
+
+func (s *ActionSchedulerSuite) newAPI(c *gc.C, request string, result interface{}, err error) *actionscheduler.API {
+	apiCaller := basetesting.APICallerFunc(func(objType string, version int, id, req string, arg, res interface{}) error {
+		c.Check(objType, gc.Equals, "ActionScheduler")
+		c.Check(id, gc.Equals, "")
+		c.Check(req, gc.Equals, request)
+		c.Check(arg, gc.IsNil)
+		switch result := result.(type) {
+		case params.ActionSchedulerRunResult:
+			*(res.(*params.ActionSchedulerRunResult)) = result
+		case params.ErrorResult:
+			*(res.(*params.ErrorResult)) = result
+		}
+		return err
+	})
+	return actionscheduler.NewAPI(apiCaller)
+}
+
+func (s *ActionSchedulerSuite) TestRunDueActionSchedules(c *gc.C) {
+	nextRun := time.Date(2018, 7, 1, 13, 0, 0, 0, time.UTC)
+	api := s.newAPI(c, "RunDueActionSchedules", params.ActionSchedulerRunResult{NextRun: &nextRun}, nil)
+	result, err := api.RunDueActionSchedules()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, gc.Equals, nextRun)
+}
+
+func (s *ActionSchedulerSuite) TestRunDueActionSchedulesNoNextRun(c *gc.C) {
+	api := s.newAPI(c, "RunDueActionSchedules", params.ActionSchedulerRunResult{}, nil)
+	result, err := api.RunDueActionSchedules()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.IsZero(), jc.IsTrue)
+}
+
+func (s *ActionSchedulerSuite) TestRunDueActionSchedulesResultError(c *gc.C) {
+	api := s.newAPI(c, "RunDueActionSchedules", params.ActionSchedulerRunResult{
+		Error: &params.Error{Message: "boom"},
+	}, nil)
+	_, err := api.RunDueActionSchedules()
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
+
+func (s *ActionSchedulerSuite) TestRunDueActionSchedulesCallError(c *gc.C) {
+	api := s.newAPI(c, "RunDueActionSchedules", nil, errors.New("kaboom"))
+	_, err := api.RunDueActionSchedules()
+	c.Assert(err, gc.ErrorMatches, "kaboom")
+}
+
+func (s *ActionSchedulerSuite) TestAdvanceOperations(c *gc.C) {
+	api := s.newAPI(c, "AdvanceOperations", params.ErrorResult{}, nil)
+	err := api.AdvanceOperations()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ActionSchedulerSuite) TestAdvanceOperationsResultError(c *gc.C) {
+	api := s.newAPI(c, "AdvanceOperations", params.ErrorResult{
+		Error: &params.Error{Message: "boom"},
+	}, nil)
+	err := api.AdvanceOperations()
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
+
+func (s *ActionSchedulerSuite) TestWatchActionSchedulesError(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		c.Check(objType, gc.Equals, "ActionScheduler")
+		c.Check(request, gc.Equals, "WatchActionSchedules")
+		*(result.(*params.NotifyWatchResult)) = params.NotifyWatchResult{
+			Error: &params.Error{Message: "boom"},
+		}
+		return nil
+	})
+	w, err := actionscheduler.NewAPI(apiCaller).WatchActionSchedules()
+	c.Assert(err, gc.ErrorMatches, "boom")
+	c.Assert(w, gc.IsNil)
+}
+
+func (s *ActionSchedulerSuite) TestWatchOperationsError(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		c.Check(objType, gc.Equals, "ActionScheduler")
+		c.Check(request, gc.Equals, "WatchOperations")
+		*(result.(*params.NotifyWatchResult)) = params.NotifyWatchResult{
+			Error: &params.Error{Message: "boom"},
+		}
+		return nil
+	})
+	w, err := actionscheduler.NewAPI(apiCaller).WatchOperations()
+	c.Assert(err, gc.ErrorMatches, "boom")
+	c.Assert(w, gc.IsNil)
+}
@@ -26,3 +26,42 @@ func (c *Client) Create(notes string, keepCopy, noDownload bool) (*params.Backup
 
 	return &result, nil
 }
+
+// CreateIncremental sends a request to create an incremental backup of
+// the full backup with the ID. It returns the metadata associated with
+// the resulting backup, which is kept on the controller.
+func (c *Client) CreateIncremental(base, notes string) (*params.BackupsMetadataResult, error) {
+	if c.facade.BestAPIVersion() < 3 {
+		return nil, errors.NotSupportedf("incremental backups on this controller")
+	}
+	var result params.BackupsMetadataResult
+	args := params.BackupsCreateArgs{
+		Notes: notes,
+		Base:  base,
+	}
+	if err := c.facade.FacadeCall("Create", args, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &result, nil
+}
+
+// CreateEncrypted sends a request to create a backup of juju's state,
+// as Create does, with the archive encrypted with either the passphrase
+// or the armored OpenPGP public key.
+func (c *Client) CreateEncrypted(notes string, keepCopy, noDownload bool, passphrase, publicKey string) (*params.BackupsMetadataResult, error) {
+	if c.facade.BestAPIVersion() < 4 {
+		return nil, errors.NotSupportedf("encrypted backups on this controller")
+	}
+	var result params.BackupsMetadataResult
+	args := params.BackupsCreateArgs{
+		Notes:      notes,
+		KeepCopy:   keepCopy,
+		NoDownload: noDownload,
+		Passphrase: passphrase,
+		PublicKey:  publicKey,
+	}
+	if err := c.facade.FacadeCall("Create", args, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &result, nil
+}
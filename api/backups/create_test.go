@@ -4,6 +4,7 @@
 package backups_test
 
 import (
+	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
@@ -47,3 +48,88 @@ func (s *createSuite) TestCreate(c *gc.C) {
 	meta := backupstesting.UpdateNotes(s.Meta, "important")
 	s.checkMetadataResult(c, result, meta)
 }
+
+func (s *createSuite) TestCreateIncremental(c *gc.C) {
+	cleanup := backups.PatchClientFacadeCallVersion(s.client, 3,
+		func(req string, paramsIn interface{}, resp interface{}) error {
+			c.Check(req, gc.Equals, "Create")
+
+			c.Assert(paramsIn, gc.FitsTypeOf, params.BackupsCreateArgs{})
+			p := paramsIn.(params.BackupsCreateArgs)
+			c.Check(p.Notes, gc.Equals, "important")
+			c.Check(p.Base, gc.Equals, "base-id")
+
+			if result, ok := resp.(*params.BackupsMetadataResult); ok {
+				*result = apiserverbackups.CreateResult(s.Meta, "")
+				result.Notes = p.Notes
+			} else {
+				c.Fatalf("wrong output structure")
+			}
+			return nil
+		},
+	)
+	defer cleanup()
+
+	result, err := s.client.CreateIncremental("base-id", "important")
+	c.Assert(err, jc.ErrorIsNil)
+	meta := backupstesting.UpdateNotes(s.Meta, "important")
+	s.checkMetadataResult(c, result, meta)
+}
+
+func (s *createSuite) TestCreateIncrementalNotSupported(c *gc.C) {
+	cleanup := backups.PatchClientFacadeCallVersion(s.client, 2,
+		func(req string, paramsIn interface{}, resp interface{}) error {
+			c.Fatalf("unexpected call to %q", req)
+			return nil
+		},
+	)
+	defer cleanup()
+
+	_, err := s.client.CreateIncremental("base-id", "important")
+	c.Check(err, gc.ErrorMatches, "incremental backups on this controller not supported")
+	c.Check(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+func (s *createSuite) TestCreateEncrypted(c *gc.C) {
+	cleanup := backups.PatchClientFacadeCallVersion(s.client, 4,
+		func(req string, paramsIn interface{}, resp interface{}) error {
+			c.Check(req, gc.Equals, "Create")
+
+			c.Assert(paramsIn, gc.FitsTypeOf, params.BackupsCreateArgs{})
+			p := paramsIn.(params.BackupsCreateArgs)
+			c.Check(p.Notes, gc.Equals, "important")
+			c.Check(p.KeepCopy, jc.IsTrue)
+			c.Check(p.NoDownload, jc.IsFalse)
+			c.Check(p.Passphrase, gc.Equals, "")
+			c.Check(p.PublicKey, gc.Equals, "<public key>")
+
+			if result, ok := resp.(*params.BackupsMetadataResult); ok {
+				*result = apiserverbackups.CreateResult(s.Meta, "")
+				result.Notes = p.Notes
+			} else {
+				c.Fatalf("wrong output structure")
+			}
+			return nil
+		},
+	)
+	defer cleanup()
+
+	result, err := s.client.CreateEncrypted("important", true, false, "", "<public key>")
+	c.Assert(err, jc.ErrorIsNil)
+	meta := backupstesting.UpdateNotes(s.Meta, "important")
+	s.checkMetadataResult(c, result, meta)
+}
+
+func (s *createSuite) TestCreateEncryptedNotSupported(c *gc.C) {
+	cleanup := backups.PatchClientFacadeCallVersion(s.client, 3,
+		func(req string, paramsIn interface{}, resp interface{}) error {
+			c.Fatalf("unexpected call to %q", req)
+			return nil
+		},
+	)
+	defer cleanup()
+
+	_, err := s.client.CreateEncrypted("important", true, false, "secret", "")
+	c.Check(err, gc.ErrorMatches, "encrypted backups on this controller not supported")
+	c.Check(err, jc.Satisfies, errors.IsNotSupported)
+}
@@ -83,7 +83,7 @@ func (c *Client) RestoreReader(r io.ReadSeeker, meta *params.BackupsMetadataResu
 	list := results.List
 	for _, b := range list {
 		if b.Checksum == meta.Checksum {
-			return c.restore(b.ID, newClient)
+			return c.restore(b.ID, nil, newClient)
 		}
 	}
 
@@ -95,7 +95,7 @@ func (c *Client) RestoreReader(r io.ReadSeeker, meta *params.BackupsMetadataResu
 		return errors.Annotatef(err, "cannot upload backup file")
 	}
 
-	return c.restore(backupId, newClient)
+	return c.restore(backupId, nil, newClient)
 }
 
 // Restore performs restore using a backup id corresponding to a backup stored in the server.
@@ -104,7 +104,41 @@ func (c *Client) Restore(backupId string, newClient ClientConnection) error {
 		return errors.Trace(err)
 	}
 	logger.Debugf("Server in 'about to restore' mode")
-	return c.restore(backupId, newClient)
+	return c.restore(backupId, nil, newClient)
+}
+
+// RestoreToTime performs restore using a full backup stored in the
+// server, and then replays the changes recorded in its incremental
+// backups up to the given time.
+func (c *Client) RestoreToTime(backupId string, toTime time.Time, newClient ClientConnection) error {
+	if c.facade.BestAPIVersion() < 3 {
+		return errors.NotSupportedf("restoring to a point in time on this controller")
+	}
+	if err := prepareRestore(newClient); err != nil {
+		return errors.Trace(err)
+	}
+	logger.Debugf("Server in 'about to restore' mode")
+	return c.restore(backupId, &toTime, newClient)
+}
+
+// RestoreEncrypted performs restore using an encrypted backup stored
+// in the server, which decrypts it as it's restored with either the
+// passphrase or the armored OpenPGP private key, unlocked with the
+// passphrase if it's protected. Neither the key nor the decrypted
+// archive is kept by the server.
+func (c *Client) RestoreEncrypted(backupId, passphrase, privateKey string, newClient ClientConnection) error {
+	if c.facade.BestAPIVersion() < 4 {
+		return errors.NotSupportedf("restoring encrypted backups on this controller")
+	}
+	if err := prepareRestore(newClient); err != nil {
+		return errors.Trace(err)
+	}
+	logger.Debugf("Server in 'about to restore' mode")
+	return c.restoreWithArgs(params.RestoreArgs{
+		BackupId:   backupId,
+		Passphrase: passphrase,
+		PrivateKey: privateKey,
+	}, newClient)
 }
 
 func restoreAttempt(client *Client, restoreArgs params.RestoreArgs) (error, error) {
@@ -117,16 +151,22 @@ func restoreAttempt(client *Client, restoreArgs params.RestoreArgs) (error, erro
 // restore is responsible for triggering the whole restore process in a remote
 // machine. The backup information for the process should already be in the
 // server and loaded in the backup storage under the backupId id.
-// It takes backupId as the identifier for the remote backup file and a
-// client connection factory newClient (newClient should no longer be
-// necessary when lp:1399722 is sorted out).
-func (c *Client) restore(backupId string, newClient ClientConnection) error {
-	var err, remoteError error
-
-	// Restore
-	restoreArgs := params.RestoreArgs{
+// It takes backupId as the identifier for the remote backup file, an
+// optional time to restore to, and a client connection factory
+// newClient (newClient should no longer be necessary when lp:1399722
+// is sorted out).
+func (c *Client) restore(backupId string, toTime *time.Time, newClient ClientConnection) error {
+	return c.restoreWithArgs(params.RestoreArgs{
 		BackupId: backupId,
-	}
+		ToTime:   toTime,
+	}, newClient)
+}
+
+// restoreWithArgs triggers the restore process as restore does, with
+// the given arguments.
+func (c *Client) restoreWithArgs(restoreArgs params.RestoreArgs, newClient ClientConnection) error {
+	var err, remoteError error
+	backupId := restoreArgs.BackupId
 
 	cleanExit := false
 	for a := restoreStrategy.Start(); a.Next(); {
@@ -4,6 +4,10 @@
 package backups_test
 
 import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
 	"github.com/golang/mock/gomock"
@@ -52,3 +56,40 @@ func (s *restoreSuite) TestRestoreFromFileBackupExistsOnController(c *gc.C) {
 	mockBackupsClient, _ := connFunc()
 	mockBackupsClient.RestoreReader(nil, &testBackupResults, connFunc)
 }
+
+func (s *restoreSuite) TestRestoreToTime(c *gc.C) {
+	mockController := gomock.NewController(c)
+	mockBackupFacadeCaller := mocks.NewMockFacadeCaller(mockController)
+	mockBackupClientFacade := mocks.NewMockClientFacade(mockController)
+	mockBackupClientFacade.EXPECT().Close().AnyTimes()
+	mockBackupFacadeCaller.EXPECT().BestAPIVersion().Return(3)
+
+	toTime := time.Date(2018, 7, 1, 12, 0, 0, 0, time.UTC)
+	restoreArgs := params.RestoreArgs{
+		BackupId: "base-id",
+		ToTime:   &toTime,
+	}
+	gomock.InOrder(
+		mockBackupFacadeCaller.EXPECT().FacadeCall("PrepareRestore", nil, gomock.Any()),
+		mockBackupFacadeCaller.EXPECT().FacadeCall("Restore", restoreArgs, gomock.Any()).Times(1),
+		mockBackupFacadeCaller.EXPECT().FacadeCall("FinishRestore", gomock.Any(), gomock.Any()).Times(1),
+	)
+
+	connFunc := func() (*backups.Client, error) {
+		return backups.MakeClient(mockBackupClientFacade, mockBackupFacadeCaller, nil), nil
+	}
+	client, _ := connFunc()
+	err := client.RestoreToTime("base-id", toTime, connFunc)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *restoreSuite) TestRestoreToTimeNotSupported(c *gc.C) {
+	mockController := gomock.NewController(c)
+	mockBackupFacadeCaller := mocks.NewMockFacadeCaller(mockController)
+	mockBackupClientFacade := mocks.NewMockClientFacade(mockController)
+	mockBackupFacadeCaller.EXPECT().BestAPIVersion().Return(2)
+
+	client := backups.MakeClient(mockBackupClientFacade, mockBackupFacadeCaller, nil)
+	err := client.RestoreToTime("base-id", time.Now(), nil)
+	c.Check(err, jc.Satisfies, errors.IsNotSupported)
+}
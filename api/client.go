@@ -33,6 +33,7 @@ import (
 	"github.com/juju/juju/downloader"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/tools"
+	jujuversion "github.com/juju/juju/version"
 )
 
 // Client represents the client-accessible part of the state.
@@ -44,8 +45,18 @@ type Client struct {
 
 // Status returns the status of the juju model.
 func (c *Client) Status(patterns []string) (*params.FullStatus, error) {
+	return c.FilteredStatus(patterns, nil)
+}
+
+// FilteredStatus returns the status of the juju model, restricted to the
+// entities matching the patterns and satisfying any of the filter
+// expressions, as parsed by status.ParseFilter.
+func (c *Client) FilteredStatus(patterns, filters []string) (*params.FullStatus, error) {
+	if len(filters) > 0 && c.facade.BestAPIVersion() < 3 {
+		return nil, errors.New("status filters not supported by the controller")
+	}
 	var result params.FullStatus
-	p := params.StatusParams{Patterns: patterns}
+	p := params.StatusParams{Patterns: patterns, Filters: filters}
 	if err := c.facade.FacadeCall("FullStatus", p, &result); err != nil {
 		return nil, err
 	}
@@ -62,17 +73,9 @@ func (c *Client) Status(patterns []string) (*params.FullStatus, error) {
 // for <name> unit
 func (c *Client) StatusHistory(kind status.HistoryKind, tag names.Tag, filter status.StatusHistoryFilter) (status.History, error) {
 	var results params.StatusHistoryResults
-	args := params.StatusHistoryRequest{
-		Kind: string(kind),
-		Filter: params.StatusHistoryFilter{
-			Size:    filter.Size,
-			Date:    filter.FromDate,
-			Delta:   filter.Delta,
-			Exclude: filter.Exclude.Values(),
-		},
-		Tag: tag.String(),
-	}
-	bulkArgs := params.StatusHistoryRequests{Requests: []params.StatusHistoryRequest{args}}
+	bulkArgs := params.StatusHistoryRequests{Requests: []params.StatusHistoryRequest{
+		statusHistoryRequest(kind, tag, filter),
+	}}
 	err := c.facade.FacadeCall("StatusHistory", bulkArgs, &results)
 	if err != nil {
 		return status.History{}, errors.Trace(err)
@@ -83,11 +86,56 @@ func (c *Client) StatusHistory(kind status.HistoryKind, tag names.Tag, filter st
 	if results.Results[0].Error != nil {
 		return status.History{}, errors.Annotatef(results.Results[0].Error, "while processing the request")
 	}
-	history := make(status.History, len(results.Results[0].History.Statuses))
 	if results.Results[0].History.Error != nil {
 		return status.History{}, results.Results[0].History.Error
 	}
-	for i, h := range results.Results[0].History.Statuses {
+	return historyFromParams(results.Results[0].History), nil
+}
+
+// StatusHistories retrieves the status history of the given kind for
+// each of the entities in a single call, in the order of the tags.
+func (c *Client) StatusHistories(kind status.HistoryKind, tags []names.Tag, filter status.StatusHistoryFilter) ([]status.History, error) {
+	var results params.StatusHistoryResults
+	bulkArgs := params.StatusHistoryRequests{Requests: make([]params.StatusHistoryRequest, len(tags))}
+	for i, tag := range tags {
+		bulkArgs.Requests[i] = statusHistoryRequest(kind, tag, filter)
+	}
+	err := c.facade.FacadeCall("StatusHistory", bulkArgs, &results)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(results.Results) != len(tags) {
+		return nil, errors.Errorf("expected %d results got %d", len(tags), len(results.Results))
+	}
+	histories := make([]status.History, len(tags))
+	for i, result := range results.Results {
+		if result.Error != nil {
+			return nil, errors.Annotatef(result.Error, "getting status history for %s", names.ReadableString(tags[i]))
+		}
+		if result.History.Error != nil {
+			return nil, errors.Annotatef(result.History.Error, "getting status history for %s", names.ReadableString(tags[i]))
+		}
+		histories[i] = historyFromParams(result.History)
+	}
+	return histories, nil
+}
+
+func statusHistoryRequest(kind status.HistoryKind, tag names.Tag, filter status.StatusHistoryFilter) params.StatusHistoryRequest {
+	return params.StatusHistoryRequest{
+		Kind: string(kind),
+		Filter: params.StatusHistoryFilter{
+			Size:    filter.Size,
+			Date:    filter.FromDate,
+			Delta:   filter.Delta,
+			Exclude: filter.Exclude.Values(),
+		},
+		Tag: tag.String(),
+	}
+}
+
+func historyFromParams(in params.History) status.History {
+	history := make(status.History, len(in.Statuses))
+	for i, h := range in.Statuses {
 		history[i] = status.DetailedStatus{
 			Status:  status.Status(h.Status),
 			Info:    h.Info,
@@ -104,7 +152,7 @@ func (c *Client) StatusHistory(kind status.HistoryKind, tag names.Tag, filter st
 			logger.Errorf("history returned an unknown status kind %q", h.Kind)
 		}
 	}
-	return history, nil
+	return history
 }
 
 // Resolved clears errors on a unit.
@@ -662,3 +710,25 @@ func websocketDialWithErrors(dialer WebsocketDialer, urlStr string, requestHeade
 func (c *Client) WatchDebugLog(args common.DebugLogParams) (<-chan common.LogMessage, error) {
 	return common.StreamDebugLog(c.st, args)
 }
+
+// OpenDebugLog returns a stream of structured log messages. Only log
+// entries that match the filtering specified in the DebugLogParams
+// are returned. Unlike WatchDebugLog, the stream reports whether it
+// ended cleanly.
+func (c *Client) OpenDebugLog(args common.DebugLogParams) (common.DebugLogStream, error) {
+	return common.OpenDebugLog(c.st, args)
+}
+
+// OpenLogImportStream connects to the log import endpoint for the
+// client's model and returns a stream that previously exported log
+// records can be fed into. The objects written should be
+// params.LogRecords.
+func (c *Client) OpenLogImportStream() (base.Stream, error) {
+	attrs := url.Values{}
+	attrs.Set("jujuclientversion", jujuversion.Current.String())
+	stream, err := c.st.ConnectStream("/logimport", attrs)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return stream, nil
+}
@@ -34,6 +34,7 @@ import (
 	"github.com/juju/juju/api/common"
 	servercommon "github.com/juju/juju/apiserver/common"
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/core/status"
 	jujunames "github.com/juju/juju/juju/names"
 	jujutesting "github.com/juju/juju/juju/testing"
 	"github.com/juju/juju/rpc"
@@ -41,6 +42,7 @@ import (
 	"github.com/juju/juju/testcharms"
 	coretesting "github.com/juju/juju/testing"
 	"github.com/juju/juju/testing/factory"
+	jujuversion "github.com/juju/juju/version"
 )
 
 type clientSuite struct {
@@ -537,6 +539,39 @@ func (s *clientSuite) TestWatchDebugLogConnected(c *gc.C) {
 	c.Assert(messages, gc.NotNil)
 }
 
+func (s *clientSuite) TestOpenDebugLogEndsCleanly(c *gc.C) {
+	client := s.APIState.Client()
+	stream, err := client.OpenDebugLog(common.DebugLogParams{NoTail: true})
+	c.Assert(err, jc.ErrorIsNil)
+	waitForDebugLogEnd(c, stream)
+	c.Assert(stream.Err(), jc.ErrorIsNil)
+}
+
+func (s *clientSuite) TestOpenDebugLogTruncated(c *gc.C) {
+	catcher := urlCatcher{}
+	s.PatchValue(api.WebsocketDial, catcher.recordLocation)
+
+	client := s.APIState.Client()
+	stream, err := client.OpenDebugLog(common.DebugLogParams{NoTail: true})
+	c.Assert(err, jc.ErrorIsNil)
+	waitForDebugLogEnd(c, stream)
+	c.Assert(stream.Err(), gc.ErrorMatches, "log stream ended before it was complete: .*")
+}
+
+func waitForDebugLogEnd(c *gc.C, stream common.DebugLogStream) {
+	timeout := time.After(coretesting.LongWait)
+	for {
+		select {
+		case _, ok := <-stream.Messages():
+			if !ok {
+				return
+			}
+		case <-timeout:
+			c.Fatalf("timed out waiting for debug log stream to end")
+		}
+	}
+}
+
 func (s *clientSuite) TestConnectStreamRequiresSlashPathPrefix(c *gc.C) {
 	reader, err := s.APIState.ConnectStream("foo", nil)
 	c.Assert(err, gc.ErrorMatches, `cannot make API path from non-slash-prefixed path "foo"`)
@@ -611,16 +646,21 @@ func (s *clientSuite) TestWatchDebugLogParamsEncoded(c *gc.C) {
 	s.PatchValue(api.WebsocketDial, catcher.recordLocation)
 
 	params := common.DebugLogParams{
-		IncludeEntity: []string{"a", "b"},
-		IncludeModule: []string{"c", "d"},
-		ExcludeEntity: []string{"e", "f"},
-		ExcludeModule: []string{"g", "h"},
-		Limit:         100,
-		Backlog:       200,
-		Level:         loggo.ERROR,
-		Replay:        true,
-		NoTail:        true,
-		StartTime:     time.Date(2016, 11, 30, 11, 48, 0, 100, time.UTC),
+		IncludeEntity:   []string{"a", "b"},
+		IncludeModule:   []string{"c", "d"},
+		ExcludeEntity:   []string{"e", "f"},
+		ExcludeModule:   []string{"g", "h"},
+		IncludeLocation: []string{"i.go"},
+		ExcludeLocation: []string{"j.go:10"},
+		IncludeMessage:  "k*",
+		ExcludeMessage:  "l",
+		Limit:           100,
+		Backlog:         200,
+		Level:           loggo.ERROR,
+		Replay:          true,
+		NoTail:          true,
+		StartTime:       time.Date(2016, 11, 30, 11, 48, 0, 100, time.UTC),
+		EndTime:         time.Date(2016, 11, 30, 12, 48, 0, 0, time.UTC),
 	}
 
 	client := s.APIState.Client()
@@ -632,19 +672,39 @@ func (s *clientSuite) TestWatchDebugLogParamsEncoded(c *gc.C) {
 
 	values := connectURL.Query()
 	c.Assert(values, jc.DeepEquals, url.Values{
-		"includeEntity": params.IncludeEntity,
-		"includeModule": params.IncludeModule,
-		"excludeEntity": params.ExcludeEntity,
-		"excludeModule": params.ExcludeModule,
-		"maxLines":      {"100"},
-		"backlog":       {"200"},
-		"level":         {"ERROR"},
-		"replay":        {"true"},
-		"noTail":        {"true"},
-		"startTime":     {"2016-11-30T11:48:00.0000001Z"},
+		"includeEntity":   params.IncludeEntity,
+		"includeModule":   params.IncludeModule,
+		"excludeEntity":   params.ExcludeEntity,
+		"excludeModule":   params.ExcludeModule,
+		"includeLocation": params.IncludeLocation,
+		"excludeLocation": params.ExcludeLocation,
+		"includeMessage":  {"k*"},
+		"excludeMessage":  {"l"},
+		"maxLines":        {"100"},
+		"backlog":         {"200"},
+		"level":           {"ERROR"},
+		"replay":          {"true"},
+		"noTail":          {"true"},
+		"startTime":       {"2016-11-30T11:48:00.0000001Z"},
+		"endTime":         {"2016-11-30T12:48:00Z"},
 	})
 }
 
+func (s *clientSuite) TestOpenLogImportStream(c *gc.C) {
+	catcher := urlCatcher{}
+	s.PatchValue(api.WebsocketDial, catcher.recordLocation)
+
+	client := s.APIState.Client()
+	stream, err := client.OpenLogImportStream()
+	c.Assert(err, jc.ErrorIsNil)
+	defer stream.Close()
+
+	connectURL, err := url.Parse(catcher.location)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(connectURL.Path, gc.Matches, "/model/[a-z0-9-]+/logimport")
+	c.Assert(connectURL.Query().Get("jujuclientversion"), gc.Equals, jujuversion.Current.String())
+}
+
 func (s *clientSuite) TestConnectStreamAtUUIDPath(c *gc.C) {
 	catcher := urlCatcher{}
 	s.PatchValue(api.WebsocketDial, catcher.recordLocation)
@@ -850,6 +910,92 @@ type IsolatedClientSuite struct {
 
 var _ = gc.Suite(&IsolatedClientSuite{})
 
+func (s *IsolatedClientSuite) TestFilteredStatus(c *gc.C) {
+	apiCaller := apitesting.BestVersionCaller{
+		APICallerFunc: func(objType string, version int, id, request string, arg, result interface{}) error {
+			c.Check(objType, gc.Equals, "Client")
+			c.Check(request, gc.Equals, "FullStatus")
+			c.Check(arg, jc.DeepEquals, params.StatusParams{
+				Patterns: []string{"mysql"},
+				Filters:  []string{"workload=blocked"},
+			})
+			result.(*params.FullStatus).Model.Name = "test"
+			return nil
+		},
+		BestVersion: 3,
+	}
+	client := api.APIClient(apiCaller)
+	status, err := client.FilteredStatus([]string{"mysql"}, []string{"workload=blocked"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status.Model.Name, gc.Equals, "test")
+}
+
+func (s *IsolatedClientSuite) TestFilteredStatusErrorsOnOlderController(c *gc.C) {
+	apiCaller := apitesting.BestVersionCaller{BestVersion: 2}
+	client := api.APIClient(apiCaller)
+	_, err := client.FilteredStatus(nil, []string{"workload=blocked"})
+	c.Assert(err, gc.ErrorMatches, "status filters not supported by the controller")
+}
+
+func (s *IsolatedClientSuite) TestStatusHistories(c *gc.C) {
+	since := time.Date(2018, 7, 1, 0, 0, 0, 0, time.UTC)
+	apiCaller := apitesting.BestVersionCaller{
+		APICallerFunc: func(objType string, version int, id, request string, arg, result interface{}) error {
+			c.Check(objType, gc.Equals, "Client")
+			c.Check(request, gc.Equals, "StatusHistory")
+			c.Check(arg, jc.DeepEquals, params.StatusHistoryRequests{Requests: []params.StatusHistoryRequest{{
+				Kind:   "workload",
+				Filter: params.StatusHistoryFilter{Date: &since},
+				Tag:    "unit-mysql-0",
+			}, {
+				Kind:   "workload",
+				Filter: params.StatusHistoryFilter{Date: &since},
+				Tag:    "unit-mysql-1",
+			}}})
+			*(result.(*params.StatusHistoryResults)) = params.StatusHistoryResults{
+				Results: []params.StatusHistoryResult{{
+					History: params.History{Statuses: []params.DetailedStatus{{
+						Status: "blocked",
+						Info:   "need config",
+						Since:  &since,
+						Kind:   "workload",
+					}}},
+				}, {}},
+			}
+			return nil
+		},
+		BestVersion: 3,
+	}
+	client := api.APIClient(apiCaller)
+	histories, err := client.StatusHistories(status.KindWorkload,
+		[]names.Tag{names.NewUnitTag("mysql/0"), names.NewUnitTag("mysql/1")},
+		status.StatusHistoryFilter{FromDate: &since})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(histories, jc.DeepEquals, []status.History{{{
+		Status: status.Blocked,
+		Info:   "need config",
+		Since:  &since,
+		Kind:   status.KindWorkload,
+	}}, {}})
+}
+
+func (s *IsolatedClientSuite) TestStatusHistoriesError(c *gc.C) {
+	apiCaller := apitesting.BestVersionCaller{
+		APICallerFunc: func(objType string, version int, id, request string, arg, result interface{}) error {
+			*(result.(*params.StatusHistoryResults)) = params.StatusHistoryResults{
+				Results: []params.StatusHistoryResult{{
+					Error: &params.Error{Message: "unit not found"},
+				}},
+			}
+			return nil
+		},
+		BestVersion: 3,
+	}
+	client := api.APIClient(apiCaller)
+	_, err := client.StatusHistories(status.KindWorkload, []names.Tag{names.NewUnitTag("mysql/0")}, status.StatusHistoryFilter{Size: 1})
+	c.Assert(err, gc.ErrorMatches, "getting status history for mysql/0: unit not found")
+}
+
 func (s *IsolatedClientSuite) TestFindAllErrorsOnOlderController(c *gc.C) {
 	apiCaller := apitesting.BestVersionCaller{BestVersion: 1}
 	client := api.APIClient(apiCaller)
@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"time"
 
+	"github.com/gorilla/websocket"
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
 
@@ -53,6 +54,22 @@ type DebugLogParams struct {
 	// StartTime should be a time in the past - only records with a
 	// log time on or after StartTime will be returned.
 	StartTime time.Time
+	// EndTime, if set, limits the response to records with a log time
+	// before EndTime. Once EndTime has passed the server closes the
+	// connection.
+	EndTime time.Time
+	// IncludeLocation lists source locations to include in the response.
+	// A location is a file name, optionally followed by ":<line>".
+	IncludeLocation []string
+	// ExcludeLocation lists source locations to exclude from the response.
+	ExcludeLocation []string
+	// IncludeMessage is a pattern that log messages must contain to
+	// be included in the response. In a pattern "*" matches any
+	// sequence of characters and every other character matches itself.
+	IncludeMessage string
+	// ExcludeMessage is a pattern that log messages must not contain
+	// to be included in the response.
+	ExcludeMessage string
 }
 
 func (args DebugLogParams) URLQuery() url.Values {
@@ -62,6 +79,18 @@ func (args DebugLogParams) URLQuery() url.Values {
 		"excludeEntity": args.ExcludeEntity,
 		"excludeModule": args.ExcludeModule,
 	}
+	if len(args.IncludeLocation) > 0 {
+		attrs["includeLocation"] = args.IncludeLocation
+	}
+	if len(args.ExcludeLocation) > 0 {
+		attrs["excludeLocation"] = args.ExcludeLocation
+	}
+	if args.IncludeMessage != "" {
+		attrs.Set("includeMessage", args.IncludeMessage)
+	}
+	if args.ExcludeMessage != "" {
+		attrs.Set("excludeMessage", args.ExcludeMessage)
+	}
 	if args.Replay {
 		attrs.Set("replay", fmt.Sprint(args.Replay))
 	}
@@ -80,6 +109,9 @@ func (args DebugLogParams) URLQuery() url.Values {
 	if !args.StartTime.IsZero() {
 		attrs.Set("startTime", args.StartTime.Format(time.RFC3339Nano))
 	}
+	if !args.EndTime.IsZero() {
+		attrs.Set("endTime", args.EndTime.Format(time.RFC3339Nano))
+	}
 	return attrs
 }
 
@@ -96,6 +128,28 @@ type LogMessage struct {
 // StreamDebugLog requests the specified debug log records from the
 // server and returns a channel of the messages that come back.
 func StreamDebugLog(source base.StreamConnector, args DebugLogParams) (<-chan LogMessage, error) {
+	stream, err := OpenDebugLog(source, args)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return stream.Messages(), nil
+}
+
+// DebugLogStream is a stream of log messages from the server.
+type DebugLogStream interface {
+	// Messages returns the channel on which the log messages are
+	// delivered. It is closed when the stream ends.
+	Messages() <-chan LogMessage
+
+	// Err returns the reason the stream ended. It must only be
+	// called once the Messages channel has been closed, and returns
+	// nil only if the server marked the stream as complete.
+	Err() error
+}
+
+// OpenDebugLog requests the specified debug log records from the
+// server and returns a stream of the messages that come back.
+func OpenDebugLog(source base.StreamConnector, args DebugLogParams) (DebugLogStream, error) {
 	// TODO(babbageclunk): this isn't cancellable - if the caller stops
 	// reading from the channel (because it has an error, for example),
 	// the goroutine will be leaked. This is OK when used from the command
@@ -111,17 +165,18 @@ func StreamDebugLog(source base.StreamConnector, args DebugLogParams) (<-chan Lo
 		return nil, errors.Trace(err)
 	}
 
-	messages := make(chan LogMessage)
+	stream := &debugLogStream{messages: make(chan LogMessage)}
 	go func() {
-		defer close(messages)
+		defer close(stream.messages)
 
 		for {
 			var msg params.LogMessage
 			err := connection.ReadJSON(&msg)
 			if err != nil {
+				stream.err = streamEndError(err)
 				return
 			}
-			messages <- LogMessage{
+			stream.messages <- LogMessage{
 				Entity:    msg.Entity,
 				Timestamp: msg.Timestamp,
 				Severity:  msg.Severity,
@@ -132,5 +187,33 @@ func StreamDebugLog(source base.StreamConnector, args DebugLogParams) (<-chan Lo
 		}
 	}()
 
-	return messages, nil
+	return stream, nil
+}
+
+type debugLogStream struct {
+	messages chan LogMessage
+	// err is set before messages is closed.
+	err error
+}
+
+// Messages is part of the DebugLogStream interface.
+func (s *debugLogStream) Messages() <-chan LogMessage {
+	return s.messages
+}
+
+// Err is part of the DebugLogStream interface.
+func (s *debugLogStream) Err() error {
+	return s.err
+}
+
+// streamEndError returns the error that ended a debug log stream, or
+// nil if the server marked the stream as complete.
+func streamEndError(err error) error {
+	if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+		return nil
+	}
+	if closeErr, ok := err.(*websocket.CloseError); ok && closeErr.Text != "" {
+		return errors.New(closeErr.Text)
+	}
+	return errors.Annotate(err, "log stream ended before it was complete")
 }
@@ -13,6 +13,9 @@ import (
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/core/watcher"
 	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/logfwd"
+	"github.com/juju/juju/logfwd/httpjson"
+	"github.com/juju/juju/logfwd/logfile"
 	"github.com/juju/juju/logfwd/syslog"
 )
 
@@ -61,16 +64,32 @@ func (e *ModelWatcher) WatchForLogForwardConfigChanges() (watcher.NotifyWatcher,
 	return e.WatchForModelConfigChanges()
 }
 
-// LogForwardConfig returns the current log forward syslog configuration.
-func (e *ModelWatcher) LogForwardConfig() (*syslog.RawConfig, bool, error) {
+// LogForwardConfig returns the current log forward configuration for
+// the given type of sink (e.g. syslog.SinkType).
+func (e *ModelWatcher) LogForwardConfig(sinkType string) (logfwd.SinkConfig, bool, error) {
 	// TODO(wallyworld) - lp:1602237 - this needs to have it's own backend implementation.
 	// For now, we'll piggyback off the ModelConfig API.
 	modelConfig, err := e.ModelConfig()
 	if err != nil {
 		return nil, false, err
 	}
-	cfg, ok := modelConfig.LogFwdSyslog()
-	return cfg, ok, nil
+	switch sinkType {
+	case syslog.SinkType:
+		if cfg, ok := modelConfig.LogFwdSyslog(); ok {
+			return cfg, true, nil
+		}
+	case httpjson.SinkType:
+		if cfg, ok := modelConfig.LogFwdHTTP(); ok {
+			return cfg, true, nil
+		}
+	case logfile.SinkType:
+		if cfg, ok := modelConfig.LogFwdFile(); ok {
+			return cfg, true, nil
+		}
+	default:
+		return nil, false, errors.NotValidf("log sink type %q", sinkType)
+	}
+	return nil, false, nil
 }
 
 // UpdateStatusHookInterval returns the current update status hook interval.
@@ -257,6 +257,18 @@ func (c *Client) ConfigSet(values map[string]interface{}) error {
 	)
 }
 
+// AuditLog returns the audit log conversations matching the query
+// that were recorded by the controller machine this client is
+// connected to.
+func (c *Client) AuditLog(args params.AuditLogQueryArgs) (params.AuditLogResults, error) {
+	var result params.AuditLogResults
+	if c.BestAPIVersion() < 6 {
+		return result, errors.NotSupportedf("querying the audit log with this controller version")
+	}
+	err := c.facade.FacadeCall("AuditLog", args, &result)
+	return result, errors.Trace(err)
+}
+
 // MigrationSpec holds the details required to start the migration of
 // a single model.
 type MigrationSpec struct {
@@ -5,6 +5,7 @@ package controller_test
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/juju/errors"
 	jujutesting "github.com/juju/testing"
@@ -372,6 +373,50 @@ func (s *Suite) TestConfigSet(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "ruth mundy")
 }
 
+func (s *Suite) TestAuditLog(c *gc.C) {
+	after := time.Date(2018, 5, 1, 0, 0, 0, 0, time.UTC)
+	apiCaller := apitesting.BestVersionCaller{
+		BestVersion: 6,
+		APICallerFunc: func(objType string, version int, id, request string, args, result interface{}) error {
+			c.Assert(objType, gc.Equals, "Controller")
+			c.Assert(version, gc.Equals, 6)
+			c.Assert(request, gc.Equals, "AuditLog")
+			c.Assert(args, jc.DeepEquals, params.AuditLogQueryArgs{
+				Who:   "fred",
+				After: &after,
+			})
+			*(result.(*params.AuditLogResults)) = params.AuditLogResults{
+				ControllerMachine: "2",
+				Conversations: []params.AuditConversation{{
+					Who:            "fred",
+					ConversationID: "0000000000000001",
+				}},
+			}
+			return nil
+		},
+	}
+	client := controller.NewClient(apiCaller)
+	result, err := client.AuditLog(params.AuditLogQueryArgs{
+		Who:   "fred",
+		After: &after,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.AuditLogResults{
+		ControllerMachine: "2",
+		Conversations: []params.AuditConversation{{
+			Who:            "fred",
+			ConversationID: "0000000000000001",
+		}},
+	})
+}
+
+func (s *Suite) TestAuditLogAgainstOlderAPIVersion(c *gc.C) {
+	apiCaller := apitesting.BestVersionCaller{BestVersion: 5}
+	client := controller.NewClient(apiCaller)
+	_, err := client.AuditLog(params.AuditLogQueryArgs{})
+	c.Assert(err, gc.ErrorMatches, "querying the audit log with this controller version not supported")
+}
+
 func (s *Suite) TestConfigSetAgainstOlderAPIVersion(c *gc.C) {
 	apiCaller := apitesting.BestVersionCaller{BestVersion: 4}
 	client := controller.NewClient(apiCaller)
@@ -11,8 +11,9 @@ package api
 // New facades should start at 1.
 // Facades that existed before versioning start at 0.
 var facadeVersions = map[string]int{
-	"Action":                       3,
-	"ActionPruner":                 1,
+	"Action":                       4,
+	"ActionPruner":                 2,
+	"ActionScheduler":              1,
 	"Agent":                        2,
 	"AgentTools":                   1,
 	"AllModelWatcher":              2,
@@ -21,7 +22,7 @@ var facadeVersions = map[string]int{
 	"Application":                  8,
 	"ApplicationOffers":            2,
 	"ApplicationScaler":            1,
-	"Backups":                      2,
+	"Backups":                      4,
 	"Block":                        2,
 	"Bundle":                       2,
 	"CAASAgent":                    1,
@@ -32,9 +33,9 @@ var facadeVersions = map[string]int{
 	"CharmRevisionUpdater":         2,
 	"Charms":                       2,
 	"Cleaner":                      2,
-	"Client":                       2,
+	"Client":                       3,
 	"Cloud":                        3,
-	"Controller":                   5,
+	"Controller":                   6,
 	"CredentialManager":            1,
 	"CredentialValidator":          2,
 	"CrossController":              1,
@@ -73,6 +74,7 @@ var facadeVersions = map[string]int{
 	"MigrationStatusWatcher":       1,
 	"MigrationTarget":              1,
 	"ModelConfig":                  2,
+	"ModelGeneration":              1,
 	"ModelManager":                 5,
 	"ModelUpgrader":                1,
 	"NotifyWatcher":                1,
@@ -100,7 +102,7 @@ var facadeVersions = map[string]int{
 	"Subnets":                      2,
 	"Undertaker":                   1,
 	"UnitAssigner":                 1,
-	"Uniter":                       9,
+	"Uniter":                       10,
 	"Upgrader":                     1,
 	"UpgradeSeries":                1,
 	"UserManager":                  2,
@@ -5,6 +5,7 @@ package modelgeneration
 
 import (
 	"github.com/juju/errors"
+	"gopkg.in/juju/names.v2"
 
 	"github.com/juju/juju/api/base"
 	"github.com/juju/juju/apiserver/params"
@@ -31,5 +32,81 @@ func (c *Client) AddGeneration() error {
 	if err != nil {
 		return errors.Trace(err)
 	}
-	return result.Error
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+// AdvanceGeneration advances the input units and applications to the
+// model's next generation. It returns true if this completed the
+// generation.
+func (c *Client) AdvanceGeneration(entities []string) (bool, error) {
+	var args params.Entities
+	for _, entity := range entities {
+		switch {
+		case names.IsValidUnit(entity):
+			args.Entities = append(args.Entities, params.Entity{Tag: names.NewUnitTag(entity).String()})
+		case names.IsValidApplication(entity):
+			args.Entities = append(args.Entities, params.Entity{Tag: names.NewApplicationTag(entity).String()})
+		default:
+			return false, errors.NotValidf("unit or application name %q", entity)
+		}
+	}
+
+	var result params.AdvanceGenerationResult
+	err := c.facade.FacadeCall("AdvanceGeneration", args, &result)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	if len(result.AdvanceResults) != len(args.Entities) {
+		return false, errors.Errorf("expected %d results, got %d", len(args.Entities), len(result.AdvanceResults))
+	}
+	for i, res := range result.AdvanceResults {
+		if res.Error != nil {
+			return false, errors.Annotatef(res.Error, "advancing %q", entities[i])
+		}
+	}
+	if result.CompleteResult.Error != nil {
+		return false, errors.Annotate(result.CompleteResult.Error, "completing generation")
+	}
+	return result.CompleteResult.Result, nil
+}
+
+// CancelGeneration cancels the model's next generation.
+func (c *Client) CancelGeneration() error {
+	var result params.ErrorResult
+	err := c.facade.FacadeCall("CancelGeneration", nil, &result)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+// GenerationInfo returns details of the model's next generation,
+// including the config changes made in it.
+func (c *Client) GenerationInfo() (params.Generation, error) {
+	var result params.GenerationResult
+	err := c.facade.FacadeCall("GenerationInfo", nil, &result)
+	if err != nil {
+		return params.Generation{}, errors.Trace(err)
+	}
+	if result.Error != nil {
+		return params.Generation{}, result.Error
+	}
+	return result.Generation, nil
+}
+
+// Generations returns all of the model's generations, in the order
+// they were added.
+func (c *Client) Generations() ([]params.Generation, error) {
+	var result params.GenerationResults
+	err := c.facade.FacadeCall("Generations", nil, &result)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return result.Generations, nil
 }
@@ -0,0 +1,119 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package modelgeneration_test
+
+import (
+	"github.com/juju/errors"
+	gitjujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/modelgeneration"
+	"github.com/juju/juju/apiserver/params"
+)
+
+type modelGenerationSuite struct {
+	gitjujutesting.IsolationSuite
+}
+
+var _ = gc.Suite(&modelGenerationSuite{})
+
+func (s *modelGenerationSuite) TestAddGeneration(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(func(objType string, version int, id, request string, a, result interface{}) error {
+		c.Check(objType, gc.Equals, "ModelGeneration")
+		c.Check(request, gc.Equals, "AddGeneration")
+		c.Check(a, gc.IsNil)
+		c.Assert(result, gc.FitsTypeOf, &params.ErrorResult{})
+		return nil
+	})
+	client := modelgeneration.NewClient(apiCaller)
+	err := client.AddGeneration()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *modelGenerationSuite) TestAdvanceGeneration(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(func(objType string, version int, id, request string, a, result interface{}) error {
+		c.Check(objType, gc.Equals, "ModelGeneration")
+		c.Check(request, gc.Equals, "AdvanceGeneration")
+		c.Check(a, jc.DeepEquals, params.Entities{Entities: []params.Entity{
+			{Tag: "unit-riak-0"},
+			{Tag: "application-redis"},
+		}})
+		c.Assert(result, gc.FitsTypeOf, &params.AdvanceGenerationResult{})
+		*(result.(*params.AdvanceGenerationResult)) = params.AdvanceGenerationResult{
+			AdvanceResults: []params.ErrorResult{{}, {}},
+			CompleteResult: params.BoolResult{Result: true},
+		}
+		return nil
+	})
+	client := modelgeneration.NewClient(apiCaller)
+	completed, err := client.AdvanceGeneration([]string{"riak/0", "redis"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(completed, jc.IsTrue)
+}
+
+func (s *modelGenerationSuite) TestAdvanceGenerationError(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(func(objType string, version int, id, request string, a, result interface{}) error {
+		*(result.(*params.AdvanceGenerationResult)) = params.AdvanceGenerationResult{
+			AdvanceResults: []params.ErrorResult{{Error: &params.Error{Message: "boom"}}},
+		}
+		return nil
+	})
+	client := modelgeneration.NewClient(apiCaller)
+	_, err := client.AdvanceGeneration([]string{"riak/0"})
+	c.Assert(err, gc.ErrorMatches, `advancing "riak/0": boom`)
+}
+
+func (s *modelGenerationSuite) TestAdvanceGenerationInvalidEntity(c *gc.C) {
+	client := modelgeneration.NewClient(basetesting.APICallerFunc(func(string, int, string, string, interface{}, interface{}) error {
+		c.Fatalf("unexpected API call")
+		return nil
+	}))
+	_, err := client.AdvanceGeneration([]string{"riak/0/1"})
+	c.Assert(err, gc.ErrorMatches, `unit or application name "riak/0/1" not valid`)
+}
+
+func (s *modelGenerationSuite) TestCancelGeneration(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(func(objType string, version int, id, request string, a, result interface{}) error {
+		c.Check(request, gc.Equals, "CancelGeneration")
+		*(result.(*params.ErrorResult)) = params.ErrorResult{
+			Error: &params.Error{Message: "cannot cancel generation, there are units behind a generation"},
+		}
+		return nil
+	})
+	client := modelgeneration.NewClient(apiCaller)
+	err := client.CancelGeneration()
+	c.Assert(err, gc.ErrorMatches, "cannot cancel generation, there are units behind a generation")
+}
+
+func (s *modelGenerationSuite) TestGenerationInfo(c *gc.C) {
+	expected := params.Generation{
+		Id:     "1",
+		Active: true,
+		Applications: []params.GenerationApplication{{
+			ApplicationName: "riak",
+			UnitsAssigned:   []string{"riak/0"},
+		}},
+	}
+	apiCaller := basetesting.APICallerFunc(func(objType string, version int, id, request string, a, result interface{}) error {
+		c.Check(request, gc.Equals, "GenerationInfo")
+		*(result.(*params.GenerationResult)) = params.GenerationResult{Generation: expected}
+		return nil
+	})
+	client := modelgeneration.NewClient(apiCaller)
+	gen, err := client.GenerationInfo()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(gen, jc.DeepEquals, expected)
+}
+
+func (s *modelGenerationSuite) TestGenerations(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(func(objType string, version int, id, request string, a, result interface{}) error {
+		c.Check(request, gc.Equals, "Generations")
+		return errors.New("boom")
+	})
+	client := modelgeneration.NewClient(apiCaller)
+	_, err := client.Generations()
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
@@ -36,6 +36,7 @@ import (
 	"github.com/juju/juju/apiserver/observer"
 	"github.com/juju/juju/apiserver/websocket"
 	"github.com/juju/juju/core/auditlog"
+	"github.com/juju/juju/core/cache"
 	"github.com/juju/juju/core/lease"
 	"github.com/juju/juju/core/presence"
 	"github.com/juju/juju/pubsub/apiserver"
@@ -167,6 +168,11 @@ type ServerConfig struct {
 	// and checkers for use in API facades.
 	LeaseManager lease.Manager
 
+	// Controller is the in-memory cache of the models in the
+	// controller, for use in API facades. It may be nil, in which
+	// case facades read from the database.
+	Controller *cache.Controller
+
 	// PrometheusRegisterer registers Prometheus collectors.
 	PrometheusRegisterer prometheus.Registerer
 }
@@ -249,6 +255,7 @@ func newServer(cfg ServerConfig) (_ *Server, err error) {
 		centralHub:   cfg.Hub,
 		presence:     cfg.Presence,
 		leaseManager: cfg.LeaseManager,
+		controller:   cfg.Controller,
 		logger:       loggo.GetLogger("juju.apiserver"),
 	})
 	if err != nil {
@@ -434,9 +441,14 @@ func (srv *Server) endpoints() []apihttp.Endpoint {
 		authorizer      httpcontext.Authorizer
 		tracked         bool
 		noModelUUID     bool
+		// unaudited handlers aren't recorded in the audit log. It's
+		// used for the streaming endpoints agents hold open, which
+		// would otherwise flood it.
+		unaudited bool
 	}
 	var endpoints []apihttp.Endpoint
 	controllerModelUUID := srv.shared.statePool.SystemState().ModelUUID()
+	httpAuditor := newHTTPAuditor(srv)
 	addHandler := func(handler handler) {
 		methods := handler.methods
 		if methods == nil {
@@ -447,6 +459,9 @@ func (srv *Server) endpoints() []apihttp.Endpoint {
 			h = srv.trackRequests(h)
 		}
 		if !handler.unauthenticated {
+			if !handler.unaudited {
+				h = httpAuditor.wrap(h)
+			}
 			h = &httpcontext.BasicAuthHandler{
 				Handler:       h,
 				Authenticator: srv.authenticator,
@@ -479,7 +494,7 @@ func (srv *Server) endpoints() []apihttp.Endpoint {
 	mainAPIHandler := http.HandlerFunc(srv.apiHandler)
 	logStreamHandler := newLogStreamEndpointHandler(httpCtxt)
 	debugLogHandler := newDebugLogDBHandler(
-		httpCtxt, srv.authenticator,
+		httpCtxt, srv.authenticator, httpAuditor,
 		tagKindAuthorizer{names.MachineTagKind, names.UserTagKind, names.ApplicationTagKind})
 	pubsubHandler := newPubSubHandler(httpCtxt, srv.shared.centralHub)
 	logSinkHandler := logsink.NewHTTPHandler(
@@ -495,6 +510,11 @@ func (srv *Server) endpoints() []apihttp.Endpoint {
 		httpCtxt.stop(),
 		nil, // no rate-limiting
 	)
+	logImportHandler := logsink.NewHTTPHandler(
+		newImportLogWriteCloserFunc(httpCtxt, &srv.dbloggers),
+		httpCtxt.stop(),
+		nil, // no rate-limiting
+	)
 	modelRestHandler := &modelRestHandler{
 		ctxt:          httpCtxt,
 		dataDir:       srv.dataDir,
@@ -596,10 +616,12 @@ func (srv *Server) endpoints() []apihttp.Endpoint {
 		handler:    pubsubHandler,
 		tracked:    true,
 		authorizer: controllerAuthorizer{},
+		unaudited:  true,
 	}, {
-		pattern: modelRoutePrefix + "/logstream",
-		handler: logStreamHandler,
-		tracked: true,
+		pattern:   modelRoutePrefix + "/logstream",
+		handler:   logStreamHandler,
+		tracked:   true,
+		unaudited: true,
 	}, {
 		pattern: modelRoutePrefix + "/log",
 		handler: debugLogHandler,
@@ -612,6 +634,15 @@ func (srv *Server) endpoints() []apihttp.Endpoint {
 		handler:    logSinkHandler,
 		tracked:    true,
 		authorizer: logSinkAuthorizer,
+		unaudited:  true,
+	}, {
+		// Importing logs is restricted to controller admins, as
+		// with migration log transfer, because the records can
+		// claim to come from any entity.
+		pattern:    modelRoutePrefix + "/logimport",
+		handler:    logImportHandler,
+		tracked:    true,
+		authorizer: controllerAdminAuthorizer,
 	}, {
 		pattern:         modelRoutePrefix + "/api",
 		handler:         mainAPIHandler,
@@ -785,6 +816,12 @@ func (srv *Server) trackRequests(handler http.Handler) http.Handler {
 	})
 }
 
+// nextConnectionID returns a new ID for identifying an API
+// connection or HTTP request in logs.
+func (srv *Server) nextConnectionID() uint64 {
+	return atomic.AddUint64(&srv.lastConnectionID, 1)
+}
+
 func (srv *Server) apiHandler(w http.ResponseWriter, req *http.Request) {
 	atomic.AddInt64(&srv.totalConn, 1)
 	addCount := func(delta int64) {
@@ -794,7 +831,7 @@ func (srv *Server) apiHandler(w http.ResponseWriter, req *http.Request) {
 	addCount(1)
 	defer addCount(-1)
 
-	connectionID := atomic.AddUint64(&srv.lastConnectionID, 1)
+	connectionID := srv.nextConnectionID()
 
 	apiObserver := srv.newObserver()
 	apiObserver.Join(req, connectionID)
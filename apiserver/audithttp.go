@@ -0,0 +1,210 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/juju/clock"
+	"github.com/juju/errors"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/httpcontext"
+	"github.com/juju/juju/apiserver/observer"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/core/auditlog"
+)
+
+// httpAuditor records requests made to the API server's HTTP
+// endpoints in the audit log. Each request is recorded as its own
+// conversation, since there's no login to tie requests together.
+type httpAuditor struct {
+	getAuditConfig   func() auditlog.Config
+	clock            clock.Clock
+	nextConnectionID func() uint64
+	modelName        func(modelUUID string) string
+}
+
+// newHTTPAuditor returns an httpAuditor that uses the server's audit
+// configuration.
+func newHTTPAuditor(srv *Server) *httpAuditor {
+	return &httpAuditor{
+		getAuditConfig:   srv.GetAuditConfig,
+		clock:            srv.clock,
+		nextConnectionID: srv.nextConnectionID,
+		modelName: func(modelUUID string) string {
+			st, err := srv.shared.statePool.Get(modelUUID)
+			if err != nil {
+				return ""
+			}
+			defer st.Release()
+			model, err := st.Model()
+			if err != nil {
+				return ""
+			}
+			return model.Name()
+		},
+	}
+}
+
+// wrap returns an http.Handler that records each authenticated
+// request handled by h. It must be wrapped in an
+// httpcontext.BasicAuthHandler so the authenticated entity is known.
+func (a *httpAuditor) wrap(h http.Handler) http.Handler {
+	return &auditHTTPHandler{Handler: h, auditor: a}
+}
+
+// startRequest records the conversation and request for req, made
+// by the given entity. It returns nil if auditing is disabled or the
+// request couldn't be recorded.
+func (a *httpAuditor) startRequest(req *http.Request, entity httpcontext.Entity) *httpAuditRecord {
+	cfg := a.getAuditConfig()
+	if !cfg.Enabled || cfg.Target == nil || entity == nil {
+		return nil
+	}
+	modelUUID := httpcontext.RequestModelUUID(req)
+	filter := observer.MakeInterestingRequestFilter(cfg.ExcludeMethods)
+	recorder, err := auditlog.NewRecorder(
+		observer.NewAuditLogFilter(cfg.Target, filter),
+		a.clock,
+		auditlog.ConversationArgs{
+			Who:          httpAuditWho(entity.Tag()),
+			What:         fmt.Sprintf("%s %s", req.Method, req.URL.Path),
+			ModelName:    a.modelName(modelUUID),
+			ModelUUID:    modelUUID,
+			ConnectionID: a.nextConnectionID(),
+		},
+	)
+	if err != nil {
+		logger.Errorf("couldn't add HTTP request to audit log: %+v", err)
+		return nil
+	}
+	size := req.ContentLength
+	if size < 0 {
+		size = 0
+	}
+	err = recorder.AddRequest(auditlog.RequestArgs{
+		Facade:    auditlog.HTTPFacade,
+		Method:    req.Method,
+		Path:      req.URL.Path,
+		Size:      size,
+		RequestID: 1,
+	})
+	if err != nil {
+		logger.Errorf("couldn't add HTTP request to audit log: %+v", err)
+		return nil
+	}
+	return &httpAuditRecord{recorder: recorder}
+}
+
+// httpAuditWho returns the name recorded in the audit log for the
+// authenticated entity. Users are recorded by name to match
+// conversations made over the RPC connection; agents by tag.
+func httpAuditWho(tag names.Tag) string {
+	if tag.Kind() == names.UserTagKind {
+		return tag.Id()
+	}
+	return tag.String()
+}
+
+// httpAuditRecord tracks a recorded HTTP request so that its
+// response can be recorded once the handler has finished.
+type httpAuditRecord struct {
+	recorder *auditlog.Recorder
+}
+
+// finish records the response status of the request if it
+// indicates a failure.
+func (r *httpAuditRecord) finish(status int) {
+	if r == nil || status < http.StatusBadRequest {
+		return
+	}
+	err := r.recorder.AddResponse(auditlog.ResponseErrorsArgs{
+		RequestID: 1,
+		Errors: []*auditlog.Error{{
+			Message: http.StatusText(status),
+			Code:    strconv.Itoa(status),
+		}},
+	})
+	if err != nil {
+		logger.Errorf("couldn't add HTTP response to audit log: %+v", err)
+	}
+}
+
+// finishStream records the error, if any, that ended a streaming
+// request.
+func (r *httpAuditRecord) finishStream(err error) {
+	if r == nil || err == nil {
+		return
+	}
+	addErr := r.recorder.AddResponse(auditlog.ResponseErrorsArgs{
+		RequestID: 1,
+		Errors: []*auditlog.Error{{
+			Message: err.Error(),
+			Code:    params.ErrCode(err),
+		}},
+	})
+	if addErr != nil {
+		logger.Errorf("couldn't add HTTP response to audit log: %+v", addErr)
+	}
+}
+
+// auditHTTPHandler is an http.Handler that records the requests it
+// serves in the audit log.
+type auditHTTPHandler struct {
+	http.Handler
+	auditor *httpAuditor
+}
+
+// ServeHTTP is part of the http.Handler interface.
+func (h *auditHTTPHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	authInfo, ok := httpcontext.RequestAuthInfo(req)
+	if !ok {
+		h.Handler.ServeHTTP(w, req)
+		return
+	}
+	record := h.auditor.startRequest(req, authInfo.Entity)
+	if record == nil {
+		h.Handler.ServeHTTP(w, req)
+		return
+	}
+	sw := &statusResponseWriter{ResponseWriter: w, status: http.StatusOK}
+	h.Handler.ServeHTTP(sw, req)
+	record.finish(sw.status)
+}
+
+// statusResponseWriter wraps an http.ResponseWriter to capture the
+// status code sent. It supports hijacking so that it can be used
+// with websocket handlers.
+type statusResponseWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader is part of the http.ResponseWriter interface.
+func (w *statusResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Flush is part of the http.Flusher interface.
+func (w *statusResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack is part of the http.Hijacker interface.
+func (w *statusResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.NotSupportedf("hijacking %T", w.ResponseWriter)
+	}
+	w.status = http.StatusSwitchingProtocols
+	return hijacker.Hijack()
+}
@@ -0,0 +1,187 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/juju/clock/testclock"
+	"github.com/juju/collections/set"
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/httpcontext"
+	"github.com/juju/juju/apiserver/params"
+	servertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/core/auditlog"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type auditHTTPSuite struct {
+	coretesting.BaseSuite
+
+	log     *servertesting.FakeAuditLog
+	config  auditlog.Config
+	entity  names.Tag
+	auditor *httpAuditor
+}
+
+var _ = gc.Suite(&auditHTTPSuite{})
+
+func (s *auditHTTPSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.log = &servertesting.FakeAuditLog{}
+	s.config = auditlog.Config{
+		Enabled: true,
+		Target:  s.log,
+	}
+	s.entity = names.NewUserTag("bob")
+	var lastID uint64
+	s.auditor = &httpAuditor{
+		getAuditConfig: func() auditlog.Config { return s.config },
+		clock:          testclock.NewClock(time.Date(2018, 5, 8, 10, 0, 0, 0, time.UTC)),
+		nextConnectionID: func() uint64 {
+			lastID++
+			return lastID
+		},
+		modelName: func(modelUUID string) string {
+			return "name-of-" + modelUUID
+		},
+	}
+}
+
+func (s *auditHTTPSuite) serve(c *gc.C, method, body string, status int) {
+	h := &httpcontext.ImpliedModelHandler{
+		ModelUUID: coretesting.ModelTag.Id(),
+		Handler: &httpcontext.BasicAuthHandler{
+			Authenticator: fakeHTTPAuthenticator{s.entity},
+			Handler: s.auditor.wrap(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(status)
+			})),
+		},
+	}
+	req := httptest.NewRequest(method, "/charms?series=bionic", strings.NewReader(body))
+	h.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func (s *auditHTTPSuite) TestRecordsRequest(c *gc.C) {
+	s.serve(c, "POST", "charm-archive", http.StatusOK)
+
+	s.log.CheckCallNames(c, "AddConversation", "AddRequest")
+	conv := s.log.Calls()[0].Args[0].(auditlog.Conversation)
+	c.Assert(conv.ConversationID, gc.HasLen, 16)
+	conv.ConversationID = ""
+	c.Assert(conv, gc.Equals, auditlog.Conversation{
+		Who:          "bob",
+		What:         "POST /charms",
+		When:         "2018-05-08T10:00:00Z",
+		ModelName:    "name-of-" + coretesting.ModelTag.Id(),
+		ModelUUID:    coretesting.ModelTag.Id(),
+		ConnectionID: "1",
+	})
+	req := s.log.Calls()[1].Args[0].(auditlog.Request)
+	req.ConversationID = ""
+	c.Assert(req, gc.Equals, auditlog.Request{
+		ConnectionID: "1",
+		RequestID:    1,
+		When:         "2018-05-08T10:00:00Z",
+		Facade:       "HTTP",
+		Method:       "POST",
+		Path:         "/charms",
+		Size:         int64(len("charm-archive")),
+	})
+}
+
+func (s *auditHTTPSuite) TestRecordsErrorStatus(c *gc.C) {
+	s.serve(c, "PUT", "", http.StatusForbidden)
+
+	s.log.CheckCallNames(c, "AddConversation", "AddRequest", "AddResponse")
+	resp := s.log.Calls()[2].Args[0].(auditlog.ResponseErrors)
+	c.Assert(resp.RequestID, gc.Equals, uint64(1))
+	c.Assert(resp.Errors, jc.DeepEquals, []*auditlog.Error{{
+		Message: "Forbidden",
+		Code:    "403",
+	}})
+}
+
+func (s *auditHTTPSuite) TestRecordsAgentsByTag(c *gc.C) {
+	s.entity = names.NewMachineTag("0")
+	s.serve(c, "GET", "", http.StatusOK)
+
+	s.log.CheckCallNames(c, "AddConversation", "AddRequest")
+	conv := s.log.Calls()[0].Args[0].(auditlog.Conversation)
+	c.Assert(conv.Who, gc.Equals, "machine-0")
+}
+
+func (s *auditHTTPSuite) TestUsesExcludeMethods(c *gc.C) {
+	s.config.ExcludeMethods = set.NewStrings("HTTP.GET")
+	s.serve(c, "GET", "", http.StatusOK)
+	s.log.CheckNoCalls(c)
+
+	s.serve(c, "POST", "", http.StatusOK)
+	s.log.CheckCallNames(c, "AddConversation", "AddRequest")
+}
+
+func (s *auditHTTPSuite) TestDisabled(c *gc.C) {
+	s.config.Enabled = false
+	s.serve(c, "POST", "charm-archive", http.StatusOK)
+	s.log.CheckNoCalls(c)
+}
+
+func (s *auditHTTPSuite) TestFinishStream(c *gc.C) {
+	req := httptest.NewRequest("GET", "/log", nil)
+	record := s.auditor.startRequest(req, fakeHTTPEntity{s.entity})
+	c.Assert(record, gc.NotNil)
+
+	record.finishStream(errors.New("stream broke"))
+	s.log.CheckCallNames(c, "AddConversation", "AddRequest", "AddResponse")
+	resp := s.log.Calls()[2].Args[0].(auditlog.ResponseErrors)
+	c.Assert(resp.Errors, jc.DeepEquals, []*auditlog.Error{{
+		Message: "stream broke",
+	}})
+}
+
+func (s *auditHTTPSuite) TestFinishStreamCleanEnd(c *gc.C) {
+	req := httptest.NewRequest("GET", "/log", nil)
+	record := s.auditor.startRequest(req, fakeHTTPEntity{s.entity})
+	record.finishStream(nil)
+	s.log.CheckCallNames(c, "AddConversation", "AddRequest")
+
+	// Nothing is recorded when auditing is disabled.
+	var none *httpAuditRecord
+	none.finishStream(errors.New("stream broke"))
+}
+
+func (s *auditHTTPSuite) TestStatusWriterSupportsHijack(c *gc.C) {
+	w := &statusResponseWriter{ResponseWriter: httptest.NewRecorder()}
+	_, _, err := w.Hijack()
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+	var _ http.Hijacker = w
+	var _ http.Flusher = w
+}
+
+type fakeHTTPAuthenticator struct {
+	tag names.Tag
+}
+
+func (a fakeHTTPAuthenticator) Authenticate(req *http.Request) (httpcontext.AuthInfo, error) {
+	return httpcontext.AuthInfo{Entity: fakeHTTPEntity{a.tag}}, nil
+}
+
+func (a fakeHTTPAuthenticator) AuthenticateLoginRequest(string, string, params.LoginRequest) (httpcontext.AuthInfo, error) {
+	return httpcontext.AuthInfo{}, errors.NotImplementedf("AuthenticateLoginRequest")
+}
+
+type fakeHTTPEntity struct {
+	tag names.Tag
+}
+
+func (e fakeHTTPEntity) Tag() names.Tag {
+	return e.tag
+}
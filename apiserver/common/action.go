@@ -5,6 +5,8 @@
 package common
 
 import (
+	"strings"
+
 	"github.com/juju/errors"
 	"gopkg.in/juju/names.v2"
 
@@ -57,6 +59,36 @@ func TagToActionReceiverFn(findEntity func(names.Tag) (state.Entity, error)) fun
 	}
 }
 
+// ActionReceiverResolverFn returns a function that converts the
+// receiver of an action, given either as a tag string or as
+// "<application>/leader", to an ActionReceiver. Application leaders
+// are looked up at most once.
+func ActionReceiverResolverFn(
+	findEntity func(names.Tag) (state.Entity, error),
+	applicationLeaders func() (map[string]string, error),
+) func(receiver string) (state.ActionReceiver, error) {
+	tagToActionReceiver := TagToActionReceiverFn(findEntity)
+	var leaders map[string]string
+	return func(receiver string) (state.ActionReceiver, error) {
+		if strings.HasSuffix(receiver, "leader") {
+			app := strings.Split(receiver, "/")[0]
+			if leaders == nil {
+				var err error
+				leaders, err = applicationLeaders()
+				if err != nil {
+					return nil, err
+				}
+			}
+			leader, ok := leaders[app]
+			if !ok {
+				return nil, errors.Errorf("could not determine leader for %q", app)
+			}
+			receiver = names.NewUnitTag(leader).String()
+		}
+		return tagToActionReceiver(receiver)
+	}
+}
+
 // AuthAndActionFromTagFn takes in an authorizer function and a function that can fetch action by tags from state
 // and returns a function that can fetch an action from state by id and check the authorization.
 func AuthAndActionFromTagFn(canAccess AuthFunc, getActionByTag func(names.ActionTag) (state.Action, error)) func(string) (state.Action, error) {
@@ -131,6 +163,30 @@ func FinishActions(args params.ActionExecutionResults, actionFn func(string) (st
 	return results
 }
 
+// LogActionsMessages records the progress messages logged by running
+// Actions.
+// It's a helper function currently used by the uniter.
+// It needs an actionFn that can fetch an action from state using it's id that's usually created by AuthAndActionFromTagFn
+func LogActionsMessages(args params.ActionMessageParams, actionFn func(string) (state.Action, error)) params.ErrorResults {
+	results := params.ErrorResults{Results: make([]params.ErrorResult, len(args.Messages))}
+
+	for i, arg := range args.Messages {
+		action, err := actionFn(arg.Tag)
+		if err != nil {
+			results.Results[i].Error = ServerError(err)
+			continue
+		}
+
+		err = action.Log(arg.Value)
+		if err != nil {
+			results.Results[i].Error = ServerError(err)
+			continue
+		}
+	}
+
+	return results
+}
+
 // Actions returns the Actions by Tags passed in and ensures that the receiver asking for
 // them is the same one that has the action.
 // It's a helper function currently used by the uniter and by machineactions.
@@ -233,6 +289,13 @@ func ConvertActions(ar state.ActionReceiver, fn GetActionsFn) ([]params.ActionRe
 // to params.ActionResult.
 func MakeActionResult(actionReceiverTag names.Tag, action state.Action) params.ActionResult {
 	output, message := action.Results()
+	var log []params.ActionMessage
+	for _, m := range action.Messages() {
+		log = append(log, params.ActionMessage{
+			Timestamp: m.Timestamp,
+			Message:   m.Message,
+		})
+	}
 	return params.ActionResult{
 		Action: &params.Action{
 			Receiver:   actionReceiverTag.String(),
@@ -243,6 +306,7 @@ func MakeActionResult(actionReceiverTag names.Tag, action state.Action) params.A
 		Status:    string(action.Status()),
 		Message:   message,
 		Output:    output,
+		Log:       log,
 		Enqueued:  action.Enqueued(),
 		Started:   action.Started(),
 		Completed: action.Completed(),
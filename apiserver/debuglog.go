@@ -29,6 +29,7 @@ import (
 type debugLogHandler struct {
 	ctxt          httpContext
 	authenticator httpcontext.Authenticator
+	auditor       *httpAuditor
 	authorizer    httpcontext.Authorizer
 	handle        debugLogHandlerFunc
 }
@@ -43,12 +44,14 @@ type debugLogHandlerFunc func(
 func newDebugLogHandler(
 	ctxt httpContext,
 	authenticator httpcontext.Authenticator,
+	auditor *httpAuditor,
 	authorizer httpcontext.Authorizer,
 	handle debugLogHandlerFunc,
 ) *debugLogHandler {
 	return &debugLogHandler{
 		ctxt:          ctxt,
 		authenticator: authenticator,
+		auditor:       auditor,
 		authorizer:    authorizer,
 		handle:        handle,
 	}
@@ -74,6 +77,12 @@ func newDebugLogHandler(
 //   excludeEntity -> []string - lists entity tags to exclude from the response
 //      - as with include, it may finish with a '*'
 //   excludeModule -> []string - lists logging modules to exclude from the response
+//   includeLocation -> []string - lists source locations to include in the response
+//      - a location is a filename, optionally followed by ":<line>"
+//   excludeLocation -> []string - lists source locations to exclude from the response
+//   includeMessage -> string - a pattern log messages must contain
+//      - "*" matches any sequence of characters, all else matches itself
+//   excludeMessage -> string - a pattern log messages must not contain
 //   limit -> uint - show *at most* this many lines
 //   backlog -> uint
 //      - go back this many lines from the end before starting to filter
@@ -82,6 +91,9 @@ func newDebugLogHandler(
 //   replay -> string - one of [true, false], if true, start the file from the start
 //   noTail -> string - one of [true, false], if true, existing logs are sent back,
 //      - but the command does not wait for new ones.
+//   startTime -> string - only logs recorded at or after this time (RFC3339) are sent
+//   endTime -> string - only logs recorded before this time (RFC3339) are sent
+//      - once it has passed, the response ends.
 func (h *debugLogHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	handler := func(conn *websocket.Conn) {
 		socket := &debugLogSocketImpl{conn}
@@ -98,6 +110,12 @@ func (h *debugLogHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 			socket.sendError(errors.Annotate(err, "authorization failed"))
 			return
 		}
+		// The request bypasses the audit wrapper applied to other
+		// authenticated handlers, so record it here.
+		var record *httpAuditRecord
+		if h.auditor != nil {
+			record = h.auditor.startRequest(req, authInfo.Entity)
+		}
 
 		st, err := h.ctxt.stateForRequestUnauthenticated(req)
 		if err != nil {
@@ -112,13 +130,29 @@ func (h *debugLogHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 			return
 		}
 
-		if err := h.handle(st, params, socket, h.ctxt.stop()); err != nil {
+		err = h.handle(st, params, socket, h.ctxt.stop())
+		if err != nil {
 			if isBrokenPipe(err) {
 				logger.Tracef("debug-log handler stopped (client disconnected)")
+				err = nil
 			} else {
 				logger.Errorf("debug-log handler error: %v", err)
 			}
 		}
+		record.finishStream(err)
+
+		// Mark the end of the stream, so that clients exporting the
+		// logs can tell a complete response from a truncated one.
+		if err == nil {
+			select {
+			case <-h.ctxt.stop():
+				err = errors.New("controller stopping")
+			default:
+			}
+		}
+		if sendErr := conn.SendEnd(err); sendErr != nil {
+			logger.Tracef("cannot mark end of debug-log stream: %v", sendErr)
+		}
 	}
 	websocket.Serve(w, req, handler)
 }
@@ -174,16 +208,21 @@ func (s *debugLogSocketImpl) sendLogRecord(record *params.LogMessage) error {
 
 // debugLogParams contains the parsed debuglog API request parameters.
 type debugLogParams struct {
-	startTime     time.Time
-	maxLines      uint
-	fromTheStart  bool
-	noTail        bool
-	backlog       uint
-	filterLevel   loggo.Level
-	includeEntity []string
-	excludeEntity []string
-	includeModule []string
-	excludeModule []string
+	startTime       time.Time
+	endTime         time.Time
+	maxLines        uint
+	fromTheStart    bool
+	noTail          bool
+	backlog         uint
+	filterLevel     loggo.Level
+	includeEntity   []string
+	excludeEntity   []string
+	includeModule   []string
+	excludeModule   []string
+	includeLocation []string
+	excludeLocation []string
+	includeMessage  string
+	excludeMessage  string
 }
 
 func readDebugLogParams(queryMap url.Values) (debugLogParams, error) {
@@ -239,10 +278,33 @@ func readDebugLogParams(queryMap url.Values) (debugLogParams, error) {
 		params.startTime = startTime
 	}
 
+	if value := queryMap.Get("endTime"); value != "" {
+		endTime, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return params, errors.Errorf("end time %q is not a valid time in RFC3339 format", value)
+		}
+		if !params.startTime.IsZero() && !endTime.After(params.startTime) {
+			return params, errors.Errorf("end time %q is not after start time", value)
+		}
+		params.endTime = endTime
+	}
+
+	for _, key := range []string{"includeMessage", "excludeMessage"} {
+		if value := queryMap.Get(key); value != "" {
+			if err := state.ValidateLogMessagePattern(value); err != nil {
+				return params, errors.Annotatef(err, "%s value", key)
+			}
+		}
+	}
+	params.includeMessage = queryMap.Get("includeMessage")
+	params.excludeMessage = queryMap.Get("excludeMessage")
+
 	params.includeEntity = queryMap["includeEntity"]
 	params.excludeEntity = queryMap["excludeEntity"]
 	params.includeModule = queryMap["includeModule"]
 	params.excludeModule = queryMap["excludeModule"]
+	params.includeLocation = queryMap["includeLocation"]
+	params.excludeLocation = queryMap["excludeLocation"]
 
 	return params, nil
 }
@@ -16,9 +16,10 @@ import (
 func newDebugLogDBHandler(
 	ctxt httpContext,
 	authenticator httpcontext.Authenticator,
+	auditor *httpAuditor,
 	authorizer httpcontext.Authorizer,
 ) http.Handler {
-	return newDebugLogHandler(ctxt, authenticator, authorizer, handleDebugLogDBRequest)
+	return newDebugLogHandler(ctxt, authenticator, auditor, authorizer, handleDebugLogDBRequest)
 }
 
 func handleDebugLogDBRequest(
@@ -61,14 +62,19 @@ func handleDebugLogDBRequest(
 
 func makeLogTailerParams(reqParams debugLogParams) state.LogTailerParams {
 	params := state.LogTailerParams{
-		MinLevel:      reqParams.filterLevel,
-		NoTail:        reqParams.noTail,
-		StartTime:     reqParams.startTime,
-		InitialLines:  int(reqParams.backlog),
-		IncludeEntity: reqParams.includeEntity,
-		ExcludeEntity: reqParams.excludeEntity,
-		IncludeModule: reqParams.includeModule,
-		ExcludeModule: reqParams.excludeModule,
+		MinLevel:        reqParams.filterLevel,
+		NoTail:          reqParams.noTail,
+		StartTime:       reqParams.startTime,
+		EndTime:         reqParams.endTime,
+		InitialLines:    int(reqParams.backlog),
+		IncludeEntity:   reqParams.includeEntity,
+		ExcludeEntity:   reqParams.excludeEntity,
+		IncludeModule:   reqParams.includeModule,
+		ExcludeModule:   reqParams.excludeModule,
+		IncludeLocation: reqParams.includeLocation,
+		ExcludeLocation: reqParams.excludeLocation,
+		IncludeMessage:  reqParams.includeMessage,
+		ExcludeMessage:  reqParams.excludeMessage,
 	}
 	if reqParams.fromTheStart {
 		params.InitialLines = 0
@@ -31,16 +31,22 @@ func (s *debugLogDBIntSuite) SetUpTest(c *gc.C) {
 
 func (s *debugLogDBIntSuite) TestParamConversion(c *gc.C) {
 	t1 := time.Date(2016, 11, 30, 10, 51, 0, 0, time.UTC)
+	t2 := time.Date(2016, 11, 30, 11, 51, 0, 0, time.UTC)
 	reqParams := debugLogParams{
-		fromTheStart:  false,
-		noTail:        true,
-		backlog:       11,
-		startTime:     t1,
-		filterLevel:   loggo.INFO,
-		includeEntity: []string{"foo"},
-		includeModule: []string{"bar"},
-		excludeEntity: []string{"baz"},
-		excludeModule: []string{"qux"},
+		fromTheStart:    false,
+		noTail:          true,
+		backlog:         11,
+		startTime:       t1,
+		endTime:         t2,
+		filterLevel:     loggo.INFO,
+		includeEntity:   []string{"foo"},
+		includeModule:   []string{"bar"},
+		excludeEntity:   []string{"baz"},
+		excludeModule:   []string{"qux"},
+		includeLocation: []string{"foo.go"},
+		excludeLocation: []string{"bar.go:12"},
+		includeMessage:  "started*",
+		excludeMessage:  "ping",
 	}
 
 	called := false
@@ -57,6 +63,11 @@ func (s *debugLogDBIntSuite) TestParamConversion(c *gc.C) {
 		c.Assert(params.IncludeModule, jc.DeepEquals, []string{"bar"})
 		c.Assert(params.ExcludeEntity, jc.DeepEquals, []string{"baz"})
 		c.Assert(params.ExcludeModule, jc.DeepEquals, []string{"qux"})
+		c.Assert(params.EndTime, gc.Equals, t2)
+		c.Assert(params.IncludeLocation, jc.DeepEquals, []string{"foo.go"})
+		c.Assert(params.ExcludeLocation, jc.DeepEquals, []string{"bar.go:12"})
+		c.Assert(params.IncludeMessage, gc.Equals, "started*")
+		c.Assert(params.ExcludeMessage, gc.Equals, "ping")
 
 		return newFakeLogTailer(), nil
 	})
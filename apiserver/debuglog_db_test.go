@@ -6,6 +6,7 @@ package apiserver_test
 import (
 	"net/http"
 	"net/url"
+	"strings"
 
 	"github.com/gorilla/websocket"
 	jc "github.com/juju/testing/checkers"
@@ -35,6 +36,34 @@ func (s *debugLogDBSuite) TestBadParams(c *gc.C) {
 	websockettest.AssertWebsocketClosed(c, conn)
 }
 
+func (s *debugLogDBSuite) TestBadFilterParams(c *gc.C) {
+	for i, test := range []struct {
+		params url.Values
+		err    string
+	}{{
+		params: url.Values{"includeMessage": {"a*b*c*d*e*f"}},
+		err:    `includeMessage value: message pattern "a\*b\*c\*d\*e\*f" with more than 4 wildcards not valid`,
+	}, {
+		params: url.Values{"excludeMessage": {strings.Repeat("x", 257)}},
+		err:    `excludeMessage value: message pattern longer than 256 characters not valid`,
+	}, {
+		params: url.Values{"endTime": {"yesterday"}},
+		err:    `end time "yesterday" is not a valid time in RFC3339 format`,
+	}, {
+		params: url.Values{
+			"startTime": {"2018-10-01T12:00:00Z"},
+			"endTime":   {"2018-10-01T11:00:00Z"},
+		},
+		err: `end time "2018-10-01T11:00:00Z" is not after start time`,
+	}} {
+		c.Logf("test %d: %v", i, test.params)
+		conn := s.dialWebsocket(c, test.params)
+		websockettest.AssertJSONError(c, conn, test.err)
+		websockettest.AssertWebsocketClosed(c, conn)
+		conn.Close()
+	}
+}
+
 func (s *debugLogDBSuite) TestWithHTTP(c *gc.C) {
 	uri := s.logURL("http", nil).String()
 	apitesting.SendHTTPRequest(c, apitesting.HTTPRequestParams{
@@ -80,6 +109,16 @@ func (s *debugLogDBSuite) TestUserLoginsAccepted(c *gc.C) {
 	c.Assert(result.Error, gc.IsNil)
 }
 
+func (s *debugLogDBSuite) TestCompleteStreamMarkedWithNormalClosure(c *gc.C) {
+	conn := s.dialWebsocket(c, noResultsPlease)
+	defer conn.Close()
+
+	result := websockettest.ReadJSONErrorLine(c, conn)
+	c.Assert(result.Error, gc.IsNil)
+	_, _, err := conn.NextReader()
+	c.Assert(websocket.IsCloseError(err, websocket.CloseNormalClosure), jc.IsTrue, gc.Commentf("%#v", err))
+}
+
 func (s *debugLogDBSuite) TestMachineLoginsAccepted(c *gc.C) {
 	m, password := s.Factory.MakeMachineReturningPassword(c, &factory.MachineParams{
 		Nonce: "foo-nonce",
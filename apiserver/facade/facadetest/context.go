@@ -5,6 +5,7 @@ package facadetest
 
 import (
 	"github.com/juju/juju/apiserver/facade"
+	"github.com/juju/juju/core/cache"
 	"github.com/juju/juju/core/leadership"
 	"github.com/juju/juju/core/lease"
 	"github.com/juju/juju/state"
@@ -20,6 +21,8 @@ type Context struct {
 	StatePool_ *state.StatePool
 	ID_        string
 
+	Controller_ *cache.Controller
+
 	LeadershipClaimer_ leadership.Claimer
 	LeadershipChecker_ leadership.Checker
 	LeadershipPinner_  leadership.Pinner
@@ -59,6 +62,11 @@ func (context Context) StatePool() *state.StatePool {
 	return context.StatePool_
 }
 
+// Controller is part of the facade.Context interface.
+func (context Context) Controller() *cache.Controller {
+	return context.Controller_
+}
+
 // ID is part of the facade.Context interface.
 func (context Context) ID() string {
 	return context.ID_
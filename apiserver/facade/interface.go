@@ -6,6 +6,7 @@ package facade
 import (
 	"gopkg.in/juju/names.v2"
 
+	"github.com/juju/juju/core/cache"
 	"github.com/juju/juju/core/leadership"
 	"github.com/juju/juju/core/lease"
 	"github.com/juju/juju/core/presence"
@@ -64,6 +65,11 @@ type Context interface {
 	// creation of the expensive *State instances.
 	StatePool() *state.StatePool
 
+	// Controller returns the in-memory cache of the controller's
+	// models. It may be nil, in which case the facade should read
+	// from the database instead.
+	Controller() *cache.Controller
+
 	// Presence returns an instance that is able to be asked for
 	// the current model presence.
 	Presence() Presence
@@ -19,6 +19,7 @@ import (
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/container"
+	"github.com/juju/juju/core/cache"
 	"github.com/juju/juju/core/instance"
 	"github.com/juju/juju/core/lxdprofile"
 	"github.com/juju/juju/core/status"
@@ -63,6 +64,7 @@ type ProvisionerAPI struct {
 	configGetter            environs.EnvironConfigGetter
 	getAuthFunc             common.GetAuthFunc
 	getCanModify            common.GetAuthFunc
+	cacheController         *cache.Controller
 	providerCallContext     context.ProviderCallContext
 }
 
@@ -168,8 +170,8 @@ type ProvisionerAPIV7 struct {
 }
 
 // NewProvisionerAPIV4 creates a new server-side version 4 Provisioner API facade.
-func NewProvisionerAPIV4(st *state.State, resources facade.Resources, authorizer facade.Authorizer) (*ProvisionerAPIV4, error) {
-	provisionerAPI, err := NewProvisionerAPIV5(st, resources, authorizer)
+func NewProvisionerAPIV4(ctx facade.Context) (*ProvisionerAPIV4, error) {
+	provisionerAPI, err := NewProvisionerAPIV5(ctx)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -177,8 +179,8 @@ func NewProvisionerAPIV4(st *state.State, resources facade.Resources, authorizer
 }
 
 // NewProvisionerAPIV5 creates a new server-side Provisioner API facade.
-func NewProvisionerAPIV5(st *state.State, resources facade.Resources, authorizer facade.Authorizer) (*ProvisionerAPIV5, error) {
-	provisionerAPI, err := NewProvisionerAPIV6(st, resources, authorizer)
+func NewProvisionerAPIV5(ctx facade.Context) (*ProvisionerAPIV5, error) {
+	provisionerAPI, err := NewProvisionerAPIV6(ctx)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -186,8 +188,8 @@ func NewProvisionerAPIV5(st *state.State, resources facade.Resources, authorizer
 }
 
 // NewProvisionerAPIV6 creates a new server-side Provisioner API facade.
-func NewProvisionerAPIV6(st *state.State, resources facade.Resources, authorizer facade.Authorizer) (*ProvisionerAPIV6, error) {
-	provisionerAPI, err := NewProvisionerAPIV7(st, resources, authorizer)
+func NewProvisionerAPIV6(ctx facade.Context) (*ProvisionerAPIV6, error) {
+	provisionerAPI, err := NewProvisionerAPIV7(ctx)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -195,11 +197,14 @@ func NewProvisionerAPIV6(st *state.State, resources facade.Resources, authorizer
 }
 
 // NewProvisionerAPIV7 creates a new server-side Provisioner API facade.
-func NewProvisionerAPIV7(st *state.State, resources facade.Resources, authorizer facade.Authorizer) (*ProvisionerAPIV7, error) {
-	provisionerAPI, err := NewProvisionerAPI(st, resources, authorizer)
+// Container watchers are served from the model cache when the
+// context provides one.
+func NewProvisionerAPIV7(ctx facade.Context) (*ProvisionerAPIV7, error) {
+	provisionerAPI, err := NewProvisionerAPI(ctx.State(), ctx.Resources(), ctx.Auth())
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	provisionerAPI.cacheController = ctx.Controller()
 	return &ProvisionerAPIV7{provisionerAPI}, nil
 }
 
@@ -234,7 +239,9 @@ func (p *ProvisionerAPI) watchOneMachineContainers(arg params.WatchContainer) (p
 		return nothing, err
 	}
 	var watch state.StringsWatcher
-	if arg.ContainerType != "" {
+	if cached := p.cachedMachine(tag.Id()); cached != nil {
+		watch = cached.WatchContainers(instance.ContainerType(arg.ContainerType))
+	} else if arg.ContainerType != "" {
 		watch = machine.WatchContainers(instance.ContainerType(arg.ContainerType))
 	} else {
 		watch = machine.WatchAllContainers()
@@ -249,6 +256,23 @@ func (p *ProvisionerAPI) watchOneMachineContainers(arg params.WatchContainer) (p
 	return nothing, watcher.EnsureErr(watch)
 }
 
+// cachedMachine returns the machine from the model cache, or nil if
+// there is no cache or the machine hasn't reached it yet.
+func (p *ProvisionerAPI) cachedMachine(id string) *cache.Machine {
+	if p.cacheController == nil {
+		return nil
+	}
+	model, err := p.cacheController.Model(p.st.ModelUUID())
+	if err != nil {
+		return nil
+	}
+	machine, err := model.Machine(id)
+	if err != nil {
+		return nil
+	}
+	return machine
+}
+
 // WatchContainers starts a StringsWatcher to watch containers deployed to
 // any machine passed in args.
 func (p *ProvisionerAPI) WatchContainers(args params.WatchContainers) (params.StringsWatchResults, error) {
@@ -19,6 +19,7 @@ import (
 
 	"github.com/juju/juju/apiserver/common"
 	commontesting "github.com/juju/juju/apiserver/common/testing"
+	"github.com/juju/juju/apiserver/facade/facadetest"
 	"github.com/juju/juju/apiserver/facades/agent/provisioner"
 	"github.com/juju/juju/apiserver/facades/agent/provisioner/mocks"
 	"github.com/juju/juju/apiserver/params"
@@ -94,11 +95,11 @@ func (s *provisionerSuite) setUpTest(c *gc.C, withController bool) {
 	s.resources = common.NewResources()
 
 	// Create a provisioner API for the machine.
-	provisionerAPI, err := provisioner.NewProvisionerAPIV6(
-		s.State,
-		s.resources,
-		s.authorizer,
-	)
+	provisionerAPI, err := provisioner.NewProvisionerAPIV6(facadetest.Context{
+		State_:     s.State,
+		Resources_: s.resources,
+		Auth_:      s.authorizer,
+	})
 	c.Assert(err, jc.ErrorIsNil)
 	s.provisioner = provisionerAPI
 }
@@ -1176,7 +1177,11 @@ func (s *withoutControllerSuite) TestDistributionGroupByMachineIdMachineAgentAut
 	anAuthorizer := s.authorizer
 	anAuthorizer.Tag = names.NewMachineTag("1")
 	anAuthorizer.Controller = false
-	provisionerV5, err := provisioner.NewProvisionerAPIV5(s.State, s.resources, anAuthorizer)
+	provisionerV5, err := provisioner.NewProvisionerAPIV5(facadetest.Context{
+		State_:     s.State,
+		Resources_: s.resources,
+		Auth_:      anAuthorizer,
+	})
 	c.Check(err, jc.ErrorIsNil)
 	args := params.Entities{Entities: []params.Entity{
 		{Tag: "machine-0"},
@@ -1555,7 +1560,11 @@ func (s *withoutControllerSuite) TestContainerConfigV5(c *gc.C) {
 		NoProxy: "127.0.0.1,localhost,::1",
 	}
 
-	provisionerV5, err := provisioner.NewProvisionerAPIV5(s.State, s.resources, s.authorizer)
+	provisionerV5, err := provisioner.NewProvisionerAPIV5(facadetest.Context{
+		State_:     s.State,
+		Resources_: s.resources,
+		Auth_:      s.authorizer,
+	})
 	c.Check(err, jc.ErrorIsNil)
 
 	var results params.ContainerConfigV5
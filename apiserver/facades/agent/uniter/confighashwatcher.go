@@ -0,0 +1,120 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter
+
+import (
+	"github.com/juju/errors"
+	"gopkg.in/juju/worker.v1"
+	"gopkg.in/juju/worker.v1/catacomb"
+
+	"github.com/juju/juju/state"
+)
+
+// cachedConfigHashWatcher yields the hash of a unit's charm config.
+// The hash comes from the model cache, except while the unit is
+// assigned to a model generation that changes the config, when it is
+// read from the database with those changes applied. Either way it is
+// the hash that the unit's WatchConfigSettingsHash would yield.
+type cachedConfigHashWatcher struct {
+	catacomb    catacomb.Catacomb
+	unit        *state.Unit
+	cached      state.StringsWatcher
+	generations state.NotifyWatcher
+	out         chan []string
+}
+
+func newCachedConfigHashWatcher(
+	unit *state.Unit, cached state.StringsWatcher, generations state.NotifyWatcher,
+) (state.StringsWatcher, error) {
+	w := &cachedConfigHashWatcher{
+		unit:        unit,
+		cached:      cached,
+		generations: generations,
+		out:         make(chan []string),
+	}
+	err := catacomb.Invoke(catacomb.Plan{
+		Site: &w.catacomb,
+		Work: w.loop,
+		Init: []worker.Worker{cached, generations},
+	})
+	return w, errors.Trace(err)
+}
+
+func (w *cachedConfigHashWatcher) loop() error {
+	defer close(w.out)
+	var (
+		cachedHash      string
+		haveCached      bool
+		haveGenerations bool
+		sentInitial     bool
+		lastHash, hash  string
+		out             chan []string
+	)
+	for {
+		select {
+		case <-w.catacomb.Dying():
+			return w.catacomb.ErrDying()
+		case hashes, ok := <-w.cached.Changes():
+			if !ok {
+				return errors.New("cached config watcher closed")
+			}
+			if len(hashes) > 0 {
+				cachedHash = hashes[len(hashes)-1]
+			}
+			haveCached = true
+		case _, ok := <-w.generations.Changes():
+			if !ok {
+				return errors.New("generations watcher closed")
+			}
+			haveGenerations = true
+		case out <- []string{hash}:
+			sentInitial = true
+			lastHash = hash
+			out = nil
+			continue
+		}
+		if !haveCached || !haveGenerations {
+			continue
+		}
+		newHash, overlaid, err := w.unit.GenerationConfigSettingsHash()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if !overlaid {
+			newHash = cachedHash
+		}
+		hash = newHash
+		if !sentInitial || hash != lastHash {
+			out = w.out
+		} else {
+			out = nil
+		}
+	}
+}
+
+// Changes implements state.StringsWatcher.
+func (w *cachedConfigHashWatcher) Changes() <-chan []string {
+	return w.out
+}
+
+// Err implements state.StringsWatcher.
+func (w *cachedConfigHashWatcher) Err() error {
+	return w.catacomb.Err()
+}
+
+// Kill implements state.StringsWatcher.
+func (w *cachedConfigHashWatcher) Kill() {
+	w.catacomb.Kill(nil)
+}
+
+// Stop implements state.StringsWatcher.
+func (w *cachedConfigHashWatcher) Stop() error {
+	w.Kill()
+	return w.Wait()
+}
+
+// Wait implements state.StringsWatcher.
+func (w *cachedConfigHashWatcher) Wait() error {
+	return w.catacomb.Wait()
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/juju/juju/apiserver/facades/client/application"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/caas"
+	"github.com/juju/juju/core/cache"
 	"github.com/juju/juju/core/leadership"
 	"github.com/juju/juju/core/status"
 	"github.com/juju/juju/network"
@@ -34,9 +35,8 @@ import (
 
 var logger = loggo.GetLogger("juju.apiserver.uniter")
 
-// UniterAPI implements the latest version (v9) of the Uniter API,
-// which adds WatchConfigSettingsHash, WatchTrustConfigSettingsHash
-// and WatchUnitAddressesHash.
+// UniterAPI implements the latest version (v10) of the Uniter API,
+// which adds LogActionsMessages.
 type UniterAPI struct {
 	*common.LifeGetter
 	*StatusAPI
@@ -57,6 +57,7 @@ type UniterAPI struct {
 	accessUnit        common.GetAuthFunc
 	accessApplication common.GetAuthFunc
 	accessMachine     common.GetAuthFunc
+	cacheController   *cache.Controller
 	*StorageAPI
 
 	// A cloud spec can only be accessed for the model of the unit or
@@ -66,11 +67,17 @@ type UniterAPI struct {
 	cloudSpec       cloudspec.CloudSpecAPI
 }
 
+// UniterAPIV9 adds WatchConfigSettingsHash, WatchTrustConfigSettingsHash
+// and WatchUnitAddressesHash.
+type UniterAPIV9 struct {
+	UniterAPI
+}
+
 // UniterAPIV8 adds SetContainerSpec, GoalStates, CloudSpec,
 // WatchTrustConfigSettings, WatchActionNotifications,
 // UpgradeSeriesStatus, SetUpgradeSeriesStatus.
 type UniterAPIV8 struct {
-	UniterAPI
+	UniterAPIV9
 }
 
 // UniterAPIV7 adds CMR support to NetworkInfo.
@@ -254,6 +261,7 @@ func NewUniterAPI(context facade.Context) (*UniterAPI, error) {
 		auth:              authorizer,
 		resources:         resources,
 		leadershipChecker: leadershipChecker,
+		cacheController:   context.Controller(),
 		accessUnit:        accessUnit,
 		accessApplication: accessApplication,
 		accessMachine:     accessMachine,
@@ -263,14 +271,25 @@ func NewUniterAPI(context facade.Context) (*UniterAPI, error) {
 	}, nil
 }
 
+// NewUniterAPIV9 creates an instance of the V9 uniter API.
+func NewUniterAPIV9(context facade.Context) (*UniterAPIV9, error) {
+	uniterAPI, err := NewUniterAPI(context)
+	if err != nil {
+		return nil, err
+	}
+	return &UniterAPIV9{
+		UniterAPI: *uniterAPI,
+	}, nil
+}
+
 // NewUniterAPIV8 creates an instance of the V8 uniter API.
 func NewUniterAPIV8(context facade.Context) (*UniterAPIV8, error) {
-	uniterAPI, err := NewUniterAPI(context)
+	uniterAPI, err := NewUniterAPIV9(context)
 	if err != nil {
 		return nil, err
 	}
 	return &UniterAPIV8{
-		UniterAPI: *uniterAPI,
+		UniterAPIV9: *uniterAPI,
 	}, nil
 }
 
@@ -739,19 +758,96 @@ func (u *UniterAPI) charmModifiedVersion(tagStr string, canAccess func(names.Tag
 	if err != nil {
 		return -1, err
 	}
-	var application *state.Application
 	switch entity := unitOrApplication.(type) {
 	case *state.Application:
-		application = entity
-	case *state.Unit:
-		application, err = entity.Application()
+		unit, err := u.authUnitOfApplication(entity.Name())
 		if err != nil {
 			return -1, err
 		}
+		if unit != nil {
+			return unit.TargetCharmModifiedVersion()
+		}
+		return entity.CharmModifiedVersion(), nil
+	case *state.Unit:
+		return entity.TargetCharmModifiedVersion()
 	default:
 		return -1, errors.BadRequestf("type %T does not have a CharmModifiedVersion", entity)
 	}
-	return application.CharmModifiedVersion(), nil
+}
+
+// Watch starts a NotifyWatcher for each given unit or application.
+func (u *UniterAPI) Watch(args params.Entities) (params.NotifyWatchResults, error) {
+	result := params.NotifyWatchResults{
+		Results: make([]params.NotifyWatchResult, len(args.Entities)),
+	}
+	var others params.Entities
+	var otherIndexes []int
+	for i, entity := range args.Entities {
+		id, ok, err := u.watchOwnApplication(entity.Tag)
+		if !ok {
+			others.Entities = append(others.Entities, entity)
+			otherIndexes = append(otherIndexes, i)
+			continue
+		}
+		result.Results[i].NotifyWatcherId = id
+		result.Results[i].Error = common.ServerError(err)
+	}
+	if len(others.Entities) == 0 {
+		return result, nil
+	}
+	otherResults, err := u.AgentEntityWatcher.Watch(others)
+	if err != nil {
+		return params.NotifyWatchResults{}, errors.Trace(err)
+	}
+	for j, i := range otherIndexes {
+		result.Results[i] = otherResults.Results[j]
+	}
+	return result, nil
+}
+
+// watchOwnApplication starts a watcher for the application of the
+// authenticated unit, and reports false if the tag isn't for that
+// application. As well as changes to the application, the watcher
+// notifies of changes to the model's generations, since the charm and
+// resources the unit should run depend on the generation it's in.
+func (u *UniterAPI) watchOwnApplication(tagStr string) (string, bool, error) {
+	tag, err := names.ParseApplicationTag(tagStr)
+	if err != nil {
+		return "", false, nil
+	}
+	unit, err := u.authUnitOfApplication(tag.Id())
+	if err != nil || unit == nil {
+		return "", false, nil
+	}
+	app, err := u.st.Application(tag.Id())
+	if err != nil {
+		return "", true, err
+	}
+	w := common.NewMultiNotifyWatcher(app.Watch(), u.st.WatchGenerations())
+	// Consume the initial event, which is "returned" by Watch.
+	if _, ok := <-w.Changes(); ok {
+		return u.resources.Register(w), true, nil
+	}
+	return "", true, watcher.EnsureErr(w)
+}
+
+// authUnitOfApplication returns the authenticated unit if it belongs to
+// the named application, and nil otherwise. The application's charm
+// details are reported as the unit should see them, which differ if
+// the unit is assigned to the model's next generation.
+func (u *UniterAPI) authUnitOfApplication(appName string) (*state.Unit, error) {
+	tag, ok := u.auth.GetAuthTag().(names.UnitTag)
+	if !ok {
+		return nil, nil
+	}
+	unitAppName, err := names.UnitApplication(tag.Id())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if unitAppName != appName {
+		return nil, nil
+	}
+	return u.st.Unit(tag.Id())
 }
 
 // CharmURL returns the charm URL for all given units or applications.
@@ -775,10 +871,9 @@ func (u *UniterAPI) CharmURL(args params.Entities) (params.StringBoolResults, er
 			var unitOrApplication state.Entity
 			unitOrApplication, err = u.st.FindEntity(tag)
 			if err == nil {
-				charmURLer := unitOrApplication.(interface {
-					CharmURL() (*charm.URL, bool)
-				})
-				curl, ok := charmURLer.CharmURL()
+				var curl *charm.URL
+				var ok bool
+				curl, ok, err = u.charmURL(unitOrApplication)
 				if curl != nil {
 					result.Results[i].Result = curl.String()
 					result.Results[i].Ok = ok
@@ -790,6 +885,28 @@ func (u *UniterAPI) CharmURL(args params.Entities) (params.StringBoolResults, er
 	return result, nil
 }
 
+// charmURL returns the charm URL of the input unit or application,
+// and for an application, whether units should be forced to upgrade
+// to it.
+func (u *UniterAPI) charmURL(unitOrApplication state.Entity) (*charm.URL, bool, error) {
+	switch entity := unitOrApplication.(type) {
+	case *state.Application:
+		unit, err := u.authUnitOfApplication(entity.Name())
+		if err != nil {
+			return nil, false, err
+		}
+		if unit != nil {
+			return unit.TargetCharmURL()
+		}
+		curl, force := entity.CharmURL()
+		return curl, force, nil
+	case *state.Unit:
+		curl, ok := entity.CharmURL()
+		return curl, ok, nil
+	}
+	return nil, false, errors.BadRequestf("type %T does not have a CharmURL", unitOrApplication)
+}
+
 // SetCharmURL sets the charm URL for each given unit. An error will
 // be returned if a unit is dead, or the charm URL is not know.
 func (u *UniterAPI) SetCharmURL(args params.EntitiesCharmURL) (params.ErrorResults, error) {
@@ -1143,6 +1260,23 @@ func (u *UniterAPI) FinishActions(args params.ActionExecutionResults) (params.Er
 	return common.FinishActions(args, actionFn), nil
 }
 
+// LogActionsMessages records the progress messages logged by running
+// Actions.
+func (u *UniterAPI) LogActionsMessages(args params.ActionMessageParams) (params.ErrorResults, error) {
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+
+	m, err := u.st.Model()
+	if err != nil {
+		return params.ErrorResults{}, errors.Trace(err)
+	}
+
+	actionFn := common.AuthAndActionFromTagFn(canAccess, m.ActionByTag)
+	return common.LogActionsMessages(args, actionFn), nil
+}
+
 // RelationById returns information about all given relations,
 // specified by their ids, including their key and the local
 // endpoint.
@@ -2702,6 +2836,9 @@ func (u *UniterAPI) goalStateUnits(app *state.Application, principalName string)
 // substantive config change).
 func (u *UniterAPI) WatchConfigSettingsHash(args params.Entities) (params.StringsWatchResults, error) {
 	getWatcher := func(unit *state.Unit) (state.StringsWatcher, error) {
+		if w, err := u.watchCachedConfigSettingsHash(unit); err != nil || w != nil {
+			return w, errors.Trace(err)
+		}
 		return unit.WatchConfigSettingsHash()
 	}
 	result, err := u.watchHashes(args, getWatcher)
@@ -2711,6 +2848,33 @@ func (u *UniterAPI) WatchConfigSettingsHash(args params.Entities) (params.String
 	return result, nil
 }
 
+// watchCachedConfigSettingsHash returns a watcher for the unit's
+// charm config from the model cache, or nil if the cache can't be
+// used. The cache only holds the config for the application's current
+// charm, so units that are running a different charm are watched in
+// the database. The cache doesn't know about model generations, so
+// the watcher also reads the generation's changes to the config from
+// the database while the unit is assigned to one.
+func (u *UniterAPI) watchCachedConfigSettingsHash(unit *state.Unit) (state.StringsWatcher, error) {
+	if u.cacheController == nil {
+		return nil, nil
+	}
+	curl, ok := unit.CharmURL()
+	if !ok {
+		return nil, nil
+	}
+	model, err := u.cacheController.Model(u.m.UUID())
+	if err != nil {
+		return nil, nil
+	}
+	app, err := model.Application(unit.ApplicationName())
+	if err != nil || app.CharmURL() != curl.String() {
+		return nil, nil
+	}
+	w, err := newCachedConfigHashWatcher(unit, app.WatchConfig(), u.st.WatchGenerations())
+	return w, errors.Trace(err)
+}
+
 // WatchTrustConfigSettingsHash returns a StringsWatcher that yields a
 // hash of the application config values whenever they change. The
 // uniter can use the hash to determine whether the actual values have
@@ -2766,6 +2930,9 @@ func (u *UniterAPIV8) WatchTrustConfigSettingsHash(_, _ struct{}) {}
 // WatchUnitAddressesHash isn't on the v8 API.
 func (u *UniterAPIV8) WatchUnitAddressesHash(_, _ struct{}) {}
 
+// LogActionsMessages isn't on the V9 API.
+func (u *UniterAPIV9) LogActionsMessages(_, _ struct{}) {}
+
 func (u *UniterAPI) watchHashes(args params.Entities, getWatcher func(u *state.Unit) (state.StringsWatcher, error)) (params.StringsWatchResults, error) {
 	result := params.StringsWatchResults{
 		Results: make([]params.StringsWatchResult, len(args.Entities)),
@@ -969,6 +969,74 @@ func (s *uniterSuite) TestCharmModifiedVersion(c *gc.C) {
 	})
 }
 
+func (s *uniterSuite) TestCharmURLAndVersionInNextGeneration(c *gc.C) {
+	version := s.wordpress.CharmModifiedVersion()
+	newCharm := s.Factory.MakeCharm(c, &factory.CharmParams{
+		Name: "wordpress",
+		URL:  "cs:quantal/wordpress-4",
+	})
+	c.Assert(s.Model.AddGeneration(), jc.ErrorIsNil)
+	err := s.wordpress.SetCharm(state.SetCharmConfig{Charm: newCharm})
+	c.Assert(err, jc.ErrorIsNil)
+	gen, err := s.Model.NextGeneration()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(gen.AssignUnit(s.wordpressUnit.Name()), jc.ErrorIsNil)
+
+	// The unit, being assigned to the generation, sees its
+	// application's charm as the one upgraded to in it.
+	args := params.Entities{Entities: []params.Entity{{Tag: "application-wordpress"}}}
+	urls, err := s.uniter.CharmURL(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(urls, gc.DeepEquals, params.StringBoolResults{
+		Results: []params.StringBoolResult{{Result: newCharm.String()}},
+	})
+	versions, err := s.uniter.CharmModifiedVersion(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(versions, gc.DeepEquals, params.IntResults{
+		Results: []params.IntResult{{Result: version + 1}},
+	})
+}
+
+func (s *uniterSuite) TestWatchOwnApplicationNotifiesGenerationChanges(c *gc.C) {
+	c.Assert(s.resources.Count(), gc.Equals, 0)
+	args := params.Entities{Entities: []params.Entity{{Tag: "application-wordpress"}}}
+	result, err := s.uniter.Watch(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, gc.DeepEquals, params.NotifyWatchResults{
+		Results: []params.NotifyWatchResult{{NotifyWatcherId: "1"}},
+	})
+	resource := s.resources.Get("1")
+	defer statetesting.AssertStop(c, resource)
+	wc := statetesting.NewNotifyWatcherC(c, s.State, resource.(state.NotifyWatcher))
+	wc.AssertNoChange()
+
+	// Upgrading the charm in a generation doesn't change the
+	// application document until a unit is assigned, but the unit
+	// needs to know when it could be affected.
+	c.Assert(s.Model.AddGeneration(), jc.ErrorIsNil)
+	wc.AssertOneChange()
+	newCharm := s.Factory.MakeCharm(c, &factory.CharmParams{
+		Name: "wordpress",
+		URL:  "cs:quantal/wordpress-4",
+	})
+	err = s.wordpress.SetCharm(state.SetCharmConfig{Charm: newCharm})
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+
+	gen, err := s.Model.NextGeneration()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(gen.AssignUnit(s.wordpressUnit.Name()), jc.ErrorIsNil)
+	wc.AssertOneChange()
+	urls, err := s.uniter.CharmURL(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(urls.Results[0].Result, gc.Equals, newCharm.String())
+
+	// Every unit is assigned, so cancelling the generation makes the
+	// upgrade current.
+	c.Assert(gen.Cancel(), jc.ErrorIsNil)
+	wc.AssertOneChange()
+}
+
 func (s *uniterSuite) TestOpenPorts(c *gc.C) {
 	openedPorts, err := s.wordpressUnit.OpenedPorts()
 	c.Assert(err, jc.ErrorIsNil)
@@ -1792,6 +1860,36 @@ func (s *uniterSuite) TestBeginActions(c *gc.C) {
 	c.Assert(started.After(enqueued) || started.Equal(enqueued), jc.IsTrue, gc.Commentf("started should be after or equal to enqueued time"))
 }
 
+func (s *uniterSuite) TestLogActionsMessages(c *gc.C) {
+	good, err := s.wordpressUnit.AddAction("fakeaction", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = good.Begin()
+	c.Assert(err, jc.ErrorIsNil)
+	pending, err := s.wordpressUnit.AddAction("fakeaction", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	bad, err := s.mysqlUnit.AddAction("fakeaction", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	res, err := s.uniter.LogActionsMessages(params.ActionMessageParams{
+		Messages: []params.EntityString{
+			{Tag: good.ActionTag().String(), Value: "hello"},
+			{Tag: pending.ActionTag().String(), Value: "hello"},
+			{Tag: bad.ActionTag().String(), Value: "hello"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(res.Results, gc.HasLen, 3)
+	c.Check(res.Results[0].Error, gc.IsNil)
+	c.Check(res.Results[1].Error, gc.ErrorMatches, `cannot log message to action ".*": action not running`)
+	c.Check(res.Results[2].Error, gc.ErrorMatches, common.ErrPerm.Error())
+
+	logged, err := s.Model.Action(good.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	messages := logged.Messages()
+	c.Assert(messages, gc.HasLen, 1)
+	c.Check(messages[0].Message, gc.Equals, "hello")
+}
+
 func (s *uniterSuite) TestRelation(c *gc.C) {
 	rel := s.addRelation(c, "wordpress", "mysql")
 	wpEp, err := rel.Endpoint("wordpress")
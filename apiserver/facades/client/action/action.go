@@ -4,8 +4,6 @@
 package action
 
 import (
-	"strings"
-
 	"github.com/juju/errors"
 	"gopkg.in/juju/names.v2"
 
@@ -32,6 +30,14 @@ type APIv2 struct {
 
 // APIv3 provides the Action API facade for version 3.
 type APIv3 struct {
+	*APIv4
+}
+
+// APIv4 provides the Action API facade for version 4, which adds
+// ScheduleActions, ActionSchedules and RemoveActionSchedules, and
+// EnqueueOperation, RunOperation, RunOperationOnAllMachines and
+// Operations.
+type APIv4 struct {
 	*ActionAPI
 }
 
@@ -46,13 +52,22 @@ func NewActionAPIV2(ctx facade.Context) (*APIv2, error) {
 
 // NewActionAPIV3 returns an initialized ActionAPI for version 3.
 func NewActionAPIV3(ctx facade.Context) (*APIv3, error) {
-	api, err := newActionAPI(ctx.State(), ctx.Resources(), ctx.Auth())
+	api, err := NewActionAPIV4(ctx)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
 	return &APIv3{api}, nil
 }
 
+// NewActionAPIV4 returns an initialized ActionAPI for version 4.
+func NewActionAPIV4(ctx facade.Context) (*APIv4, error) {
+	api, err := newActionAPI(ctx.State(), ctx.Resources(), ctx.Auth())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &APIv4{api}, nil
+}
+
 func newActionAPI(st *state.State, resources facade.Resources, authorizer facade.Authorizer) (*ActionAPI, error) {
 	if !authorizer.AuthClient() {
 		return nil, common.ErrPerm
@@ -213,36 +228,11 @@ func (a *ActionAPI) Enqueue(arg params.Actions) (params.ActionResults, error) {
 		return params.ActionResults{}, errors.Trace(err)
 	}
 
-	var leaders map[string]string
-	getLeader := func(appName string) (string, error) {
-		if leaders == nil {
-			var err error
-			leaders, err = a.state.ApplicationLeaders()
-			if err != nil {
-				return "", err
-			}
-		}
-		if leader, ok := leaders[appName]; ok {
-			return leader, nil
-		}
-		return "", errors.Errorf("could not determine leader for %q", appName)
-	}
-
-	tagToActionReceiver := common.TagToActionReceiverFn(a.state.FindEntity)
+	actionReceiver := common.ActionReceiverResolverFn(a.state.FindEntity, a.state.ApplicationLeaders)
 	response := params.ActionResults{Results: make([]params.ActionResult, len(arg.Actions))}
 	for i, action := range arg.Actions {
 		currentResult := &response.Results[i]
-		actionReceiver := action.Receiver
-		if strings.HasSuffix(actionReceiver, "leader") {
-			app := strings.Split(actionReceiver, "/")[0]
-			receiverName, err := getLeader(app)
-			if err != nil {
-				currentResult.Error = common.ServerError(err)
-				continue
-			}
-			actionReceiver = names.NewUnitTag(receiverName).String()
-		}
-		receiver, err := tagToActionReceiver(actionReceiver)
+		receiver, err := actionReceiver(action.Receiver)
 		if err != nil {
 			currentResult.Error = common.ServerError(err)
 			continue
@@ -160,6 +160,30 @@ func (s *actionSuite) TestActions(c *gc.C) {
 	}
 }
 
+func (s *actionSuite) TestActionsIncludeLog(c *gc.C) {
+	a, err := s.wordpressUnit.AddAction("fakeaction", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	a, err = a.Begin()
+	c.Assert(err, jc.ErrorIsNil)
+	err = a.Log("first")
+	c.Assert(err, jc.ErrorIsNil)
+	err = a.Log("second")
+	c.Assert(err, jc.ErrorIsNil)
+
+	actions, err := s.action.Actions(params.Entities{
+		Entities: []params.Entity{{Tag: a.ActionTag().String()}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(actions.Results, gc.HasLen, 1)
+	got := actions.Results[0]
+	c.Assert(got.Error, gc.IsNil)
+	c.Check(got.Status, gc.Equals, params.ActionRunning)
+	c.Assert(got.Log, gc.HasLen, 2)
+	c.Check(got.Log[0].Message, gc.Equals, "first")
+	c.Check(got.Log[1].Message, gc.Equals, "second")
+	c.Check(got.Log[0].Timestamp.IsZero(), jc.IsFalse)
+}
+
 func (s *actionSuite) TestFindActionTagsByPrefix(c *gc.C) {
 	// NOTE: full testing with multiple matches has been moved to state package.
 	arg := params.Actions{Actions: []params.Action{{Receiver: s.wordpressUnit.Tag().String(), Name: "fakeaction", Parameters: map[string]interface{}{}}}}
@@ -0,0 +1,196 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package action
+
+import (
+	"github.com/juju/collections/set"
+	"github.com/juju/errors"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/core/actions"
+	"github.com/juju/juju/state"
+)
+
+// EnqueueOperation queues an action on a set of receivers in batches,
+// no more than MaxParallel at a time, and stops queueing it once
+// MaxFailures of the actions have failed. The action is queued on the
+// first batch of receivers straight away; the rest are queued by the
+// model's action scheduler as earlier actions finish.
+func (a *ActionAPI) EnqueueOperation(arg params.OperationArgs) (params.OperationResult, error) {
+	if err := a.checkCanWrite(); err != nil {
+		return params.OperationResult{}, errors.Trace(err)
+	}
+
+	if err := a.check.ChangeAllowed(); err != nil {
+		return params.OperationResult{}, errors.Trace(err)
+	}
+
+	actionReceiver := common.ActionReceiverResolverFn(a.state.FindEntity, a.state.ApplicationLeaders)
+	receivers := make([]state.ActionReceiver, len(arg.Receivers))
+	for i, receiver := range arg.Receivers {
+		var err error
+		if receivers[i], err = actionReceiver(receiver); err != nil {
+			return params.OperationResult{Error: common.ServerError(err)}, nil
+		}
+	}
+	return a.addOperation(receivers, arg.Name, arg.Parameters, arg.MaxParallel, arg.MaxFailures), nil
+}
+
+// RunOperation runs the commands on the machines and units identified
+// by the run parameters as an operation, no more than MaxParallel at a
+// time; see EnqueueOperation.
+func (a *ActionAPI) RunOperation(run params.RunParams) (params.OperationResult, error) {
+	if err := a.checkCanAdmin(); err != nil {
+		return params.OperationResult{}, err
+	}
+
+	if err := a.check.ChangeAllowed(); err != nil {
+		return params.OperationResult{}, errors.Trace(err)
+	}
+
+	tags, err := a.runReceivers(run)
+	if err != nil {
+		return params.OperationResult{}, errors.Trace(err)
+	}
+	return a.runOperation(tags, run), nil
+}
+
+// RunOperationOnAllMachines runs the commands on all the machines as
+// an operation; see RunOperation.
+func (a *ActionAPI) RunOperationOnAllMachines(run params.RunParams) (params.OperationResult, error) {
+	if err := a.checkCanAdmin(); err != nil {
+		return params.OperationResult{}, err
+	}
+
+	if err := a.check.ChangeAllowed(); err != nil {
+		return params.OperationResult{}, errors.Trace(err)
+	}
+
+	tags, err := a.allMachineTags()
+	if err != nil {
+		return params.OperationResult{}, errors.Trace(err)
+	}
+	return a.runOperation(tags, run), nil
+}
+
+func (a *ActionAPI) runOperation(tags []names.Tag, run params.RunParams) params.OperationResult {
+	tagToActionReceiver := common.TagToActionReceiverFn(a.state.FindEntity)
+	receivers := make([]state.ActionReceiver, len(tags))
+	for i, tag := range tags {
+		var err error
+		if receivers[i], err = tagToActionReceiver(tag.String()); err != nil {
+			return params.OperationResult{Error: common.ServerError(err)}
+		}
+	}
+	parameters := runActionParameters(run.Commands, run.Timeout)
+	return a.addOperation(receivers, actions.JujuRunActionName, parameters, run.MaxParallel, run.MaxFailures)
+}
+
+// addOperation checks that the action is valid for each of the
+// receivers, and adds an operation that queues it on them, queueing
+// the first batch.
+func (a *ActionAPI) addOperation(
+	receivers []state.ActionReceiver,
+	name string,
+	parameters map[string]interface{},
+	maxParallel, maxFailures int,
+) params.OperationResult {
+	seen := set.NewStrings()
+	var tags []names.Tag
+	for _, receiver := range receivers {
+		if _, err := receiver.PrepareActionPayload(name, parameters); err != nil {
+			return params.OperationResult{Error: common.ServerError(err)}
+		}
+		if tag := receiver.Tag(); !seen.Contains(tag.String()) {
+			seen.Add(tag.String())
+			tags = append(tags, tag)
+		}
+	}
+	op, err := a.model.AddOperation(state.OperationArgs{
+		Receivers:   tags,
+		Name:        name,
+		Parameters:  parameters,
+		MaxParallel: maxParallel,
+		MaxFailures: maxFailures,
+	})
+	if err != nil {
+		return params.OperationResult{Error: common.ServerError(err)}
+	}
+	if _, err := op.Advance(); err != nil {
+		return params.OperationResult{Error: common.ServerError(err)}
+	}
+	return params.OperationResult{Operation: makeOperation(op)}
+}
+
+// Operations returns the operations with the given IDs, or all of the
+// operations in the model if no IDs are given.
+func (a *ActionAPI) Operations(arg params.OperationIds) (params.OperationResults, error) {
+	if err := a.checkCanRead(); err != nil {
+		return params.OperationResults{}, errors.Trace(err)
+	}
+
+	if len(arg.Ids) == 0 {
+		ops, err := a.model.AllOperations()
+		if err != nil {
+			return params.OperationResults{}, errors.Trace(err)
+		}
+		response := params.OperationResults{Results: make([]params.OperationResult, len(ops))}
+		for i, op := range ops {
+			response.Results[i].Operation = makeOperation(op)
+		}
+		return response, nil
+	}
+
+	response := params.OperationResults{Results: make([]params.OperationResult, len(arg.Ids))}
+	for i, id := range arg.Ids {
+		op, err := a.model.Operation(id)
+		if err != nil {
+			response.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		response.Results[i].Operation = makeOperation(op)
+	}
+	return response, nil
+}
+
+func makeOperation(op *state.Operation) *params.Operation {
+	result := &params.Operation{
+		Id:          op.Id(),
+		Name:        op.Name(),
+		Parameters:  op.Parameters(),
+		Receivers:   op.Receivers(),
+		MaxParallel: op.MaxParallel(),
+		MaxFailures: op.MaxFailures(),
+		Status:      string(op.Status()),
+		Completed:   op.Completed(),
+		Failed:      op.Failed(),
+		Created:     op.Created(),
+		Finished:    timePtr(op.Finished()),
+	}
+	for _, action := range op.Actions() {
+		resultAction := params.OperationAction{
+			Receiver: action.Receiver,
+			Error:    action.Error,
+		}
+		if action.ActionId != "" {
+			resultAction.Action = names.NewActionTag(action.ActionId).String()
+		}
+		result.Actions = append(result.Actions, resultAction)
+	}
+	return result
+}
+
+// EnqueueOperation isn't on the v3 API.
+func (a *APIv3) EnqueueOperation(_, _ struct{}) {}
+
+// RunOperation isn't on the v3 API.
+func (a *APIv3) RunOperation(_, _ struct{}) {}
+
+// RunOperationOnAllMachines isn't on the v3 API.
+func (a *APIv3) RunOperationOnAllMachines(_, _ struct{}) {}
+
+// Operations isn't on the v3 API.
+func (a *APIv3) Operations(_, _ struct{}) {}
@@ -0,0 +1,181 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package action_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/facades/client/action"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing"
+)
+
+func (s *actionSuite) TestBlockEnqueueOperation(c *gc.C) {
+	s.BlockAllChanges(c, "EnqueueOperation")
+	_, err := s.action.EnqueueOperation(params.OperationArgs{})
+	s.AssertBlocked(c, err, "EnqueueOperation")
+}
+
+func (s *actionSuite) TestEnqueueOperation(c *gc.C) {
+	result, err := s.action.EnqueueOperation(params.OperationArgs{
+		Receivers: []string{
+			s.wordpressUnit.Tag().String(),
+			s.mysqlUnit.Tag().String(),
+			s.wordpressUnit.Tag().String(),
+		},
+		Name:        "fakeaction",
+		MaxParallel: 1,
+		MaxFailures: 1,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Error, gc.IsNil)
+	op := result.Operation
+	c.Check(op.Name, gc.Equals, "fakeaction")
+	c.Check(op.Receivers, jc.DeepEquals, []string{"unit-wordpress-0", "unit-mysql-0"})
+	c.Check(op.MaxParallel, gc.Equals, 1)
+	c.Check(op.MaxFailures, gc.Equals, 1)
+	c.Check(op.Status, gc.Equals, "running")
+	c.Check(op.Finished, gc.IsNil)
+	c.Assert(op.Actions, gc.HasLen, 1)
+	c.Check(op.Actions[0].Receiver, gc.Equals, "unit-wordpress-0")
+
+	pending, err := s.wordpressUnit.PendingActions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pending, gc.HasLen, 1)
+	c.Check(op.Actions[0].Action, gc.Equals, pending[0].ActionTag().String())
+
+	pending, err = s.mysqlUnit.PendingActions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(pending, gc.HasLen, 0)
+
+	listed, err := s.action.Operations(params.OperationIds{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(listed.Results, gc.HasLen, 1)
+	c.Check(listed.Results[0].Operation, jc.DeepEquals, op)
+}
+
+func (s *actionSuite) TestEnqueueOperationInvalid(c *gc.C) {
+	for i, test := range []struct {
+		args params.OperationArgs
+		err  string
+	}{{
+		args: params.OperationArgs{
+			Receivers: []string{s.wordpressUnit.Tag().String(), s.mysqlUnit.Tag().String()},
+			Name:      "nope",
+		},
+		err: `action "nope" not defined on unit "wordpress/0"`,
+	}, {
+		args: params.OperationArgs{
+			Receivers: []string{s.wordpressUnit.Tag().String(), "unit-wordpress-9"},
+			Name:      "fakeaction",
+		},
+		err: `unit-wordpress-9 not found`,
+	}, {
+		args: params.OperationArgs{Name: "fakeaction"},
+		err:  `cannot add operation: empty receivers not valid`,
+	}} {
+		c.Logf("test %d", i)
+		result, err := s.action.EnqueueOperation(test.args)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(result.Error, gc.ErrorMatches, test.err)
+	}
+
+	listed, err := s.action.Operations(params.OperationIds{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(listed.Results, gc.HasLen, 0)
+}
+
+func (s *actionSuite) TestOperations(c *gc.C) {
+	result, err := s.action.EnqueueOperation(params.OperationArgs{
+		Receivers: []string{s.mysqlUnit.Tag().String()},
+		Name:      "fakeaction",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Error, gc.IsNil)
+
+	listed, err := s.action.Operations(params.OperationIds{Ids: []string{result.Operation.Id, "42"}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(listed.Results, gc.HasLen, 2)
+	c.Check(listed.Results[0].Operation, jc.DeepEquals, result.Operation)
+	c.Check(listed.Results[1].Error, jc.Satisfies, params.IsCodeNotFound)
+}
+
+func (s *runSuite) TestBlockRunOperation(c *gc.C) {
+	s.BlockAllChanges(c, "TestBlockRunOperation")
+	_, err := s.client.RunOperation(params.RunParams{
+		Commands: "hostname",
+		Machines: []string{"0"},
+	})
+	s.AssertBlocked(c, err, "TestBlockRunOperation")
+}
+
+func (s *runSuite) TestRunOperation(c *gc.C) {
+	s.addMachine(c)
+	charm := s.AddTestingCharm(c, "dummy")
+	magic, err := s.State.AddApplication(state.AddApplicationArgs{Name: "magic", Charm: charm})
+	c.Assert(err, jc.ErrorIsNil)
+	s.addUnit(c, magic)
+	s.addUnit(c, magic)
+
+	result, err := s.client.RunOperation(params.RunParams{
+		Commands:     "hostname",
+		Timeout:      testing.LongWait,
+		Machines:     []string{"0"},
+		Applications: []string{"magic"},
+		MaxParallel:  2,
+		MaxFailures:  1,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Error, gc.IsNil)
+	op := result.Operation
+	c.Check(op.Name, gc.Equals, "juju-run")
+	c.Check(op.Parameters, jc.DeepEquals, map[string]interface{}{
+		"command": "hostname",
+		"timeout": testing.LongWait.Nanoseconds(),
+	})
+	c.Check(op.Receivers, jc.DeepEquals, []string{"unit-magic-0", "unit-magic-1", "machine-0"})
+	c.Check(op.MaxParallel, gc.Equals, 2)
+	c.Check(op.MaxFailures, gc.Equals, 1)
+	c.Assert(op.Actions, gc.HasLen, 2)
+	c.Check(op.Actions[0].Receiver, gc.Equals, "unit-magic-0")
+	c.Check(op.Actions[1].Receiver, gc.Equals, "unit-magic-1")
+}
+
+func (s *runSuite) TestRunOperationOnAllMachines(c *gc.C) {
+	s.addMachine(c)
+	s.addMachine(c)
+	s.addMachine(c)
+
+	result, err := s.client.RunOperationOnAllMachines(params.RunParams{
+		Commands:    "hostname",
+		Timeout:     testing.LongWait,
+		MaxParallel: 1,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Error, gc.IsNil)
+	op := result.Operation
+	c.Check(op.Receivers, jc.DeepEquals, []string{"machine-0", "machine-1", "machine-2"})
+	c.Assert(op.Actions, gc.HasLen, 1)
+	c.Check(op.Actions[0].Receiver, gc.Equals, "machine-0")
+}
+
+func (s *runSuite) TestRunOperationRequiresAdmin(c *gc.C) {
+	alpha := names.NewUserTag("alpha@bravo")
+	auth := apiservertesting.FakeAuthorizer{
+		Tag:         alpha,
+		HasWriteTag: alpha,
+	}
+	client, err := action.NewActionAPI(s.State, nil, auth)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = client.RunOperation(params.RunParams{})
+	c.Assert(errors.Cause(err), gc.Equals, common.ErrPerm)
+	_, err = client.RunOperationOnAllMachines(params.RunParams{})
+	c.Assert(errors.Cause(err), gc.Equals, common.ErrPerm)
+}
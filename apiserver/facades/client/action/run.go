@@ -51,22 +51,32 @@ func (a *ActionAPI) Run(run params.RunParams) (results params.ActionResults, err
 		return results, errors.Trace(err)
 	}
 
-	units, err := getAllUnitNames(a.state, run.Units, run.Applications)
+	receivers, err := a.runReceivers(run)
 	if err != nil {
 		return results, errors.Trace(err)
 	}
 
+	actionParams := a.createActionsParams(receivers, run.Commands, run.Timeout)
+
+	return queueActions(a, actionParams)
+}
+
+// runReceivers returns the tags of the units and machines identified
+// by the run parameters.
+func (a *ActionAPI) runReceivers(run params.RunParams) ([]names.Tag, error) {
+	units, err := getAllUnitNames(a.state, run.Units, run.Applications)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
 	machines := make([]names.Tag, len(run.Machines))
 	for i, machineId := range run.Machines {
 		if !names.IsValidMachine(machineId) {
-			return results, errors.Errorf("invalid machine id %q", machineId)
+			return nil, errors.Errorf("invalid machine id %q", machineId)
 		}
 		machines[i] = names.NewMachineTag(machineId)
 	}
-
-	actionParams := a.createActionsParams(append(units, machines...), run.Commands, run.Timeout)
-
-	return queueActions(a, actionParams)
+	return append(units, machines...), nil
 }
 
 // RunOnAllMachines attempts to run the specified command on all the machines.
@@ -79,27 +89,43 @@ func (a *ActionAPI) RunOnAllMachines(run params.RunParams) (results params.Actio
 		return results, errors.Trace(err)
 	}
 
-	machines, err := a.state.AllMachines()
+	machineTags, err := a.allMachineTags()
 	if err != nil {
 		return results, err
 	}
+
+	actionParams := a.createActionsParams(machineTags, run.Commands, run.Timeout)
+
+	return queueActions(a, actionParams)
+}
+
+// allMachineTags returns the tags of all the machines in the model.
+func (a *ActionAPI) allMachineTags() ([]names.Tag, error) {
+	machines, err := a.state.AllMachines()
+	if err != nil {
+		return nil, err
+	}
 	machineTags := make([]names.Tag, len(machines))
 	for i, machine := range machines {
 		machineTags[i] = machine.Tag()
 	}
+	return machineTags, nil
+}
 
-	actionParams := a.createActionsParams(machineTags, run.Commands, run.Timeout)
-
-	return queueActions(a, actionParams)
+// runActionParameters returns the parameters of the juju-run action
+// that runs the given commands.
+func runActionParameters(quotedCommands string, timeout time.Duration) map[string]interface{} {
+	actionParams := map[string]interface{}{}
+	actionParams["command"] = quotedCommands
+	actionParams["timeout"] = timeout.Nanoseconds()
+	return actionParams
 }
 
 func (a *ActionAPI) createActionsParams(actionReceiverTags []names.Tag, quotedCommands string, timeout time.Duration) params.Actions {
 
 	apiActionParams := params.Actions{Actions: []params.Action{}}
 
-	actionParams := map[string]interface{}{}
-	actionParams["command"] = quotedCommands
-	actionParams["timeout"] = timeout.Nanoseconds()
+	actionParams := runActionParameters(quotedCommands, timeout)
 
 	for _, tag := range actionReceiverTags {
 		apiActionParams.Actions = append(apiActionParams.Actions, params.Action{
@@ -0,0 +1,146 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package action
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+// ScheduleActions adds schedules on which actions are queued, either
+// once at a given time or repeatedly on a cron-style schedule. The
+// receiver and parameters of each action are checked when the schedule
+// is added, and again each time it runs.
+func (a *ActionAPI) ScheduleActions(args params.ActionSchedules) (params.ActionScheduleResults, error) {
+	if err := a.checkCanWrite(); err != nil {
+		return params.ActionScheduleResults{}, errors.Trace(err)
+	}
+
+	if err := a.check.ChangeAllowed(); err != nil {
+		return params.ActionScheduleResults{}, errors.Trace(err)
+	}
+
+	actionReceiver := common.ActionReceiverResolverFn(a.state.FindEntity, a.state.ApplicationLeaders)
+	response := params.ActionScheduleResults{Results: make([]params.ActionScheduleResult, len(args.Schedules))}
+	for i, arg := range args.Schedules {
+		schedule, err := a.scheduleAction(arg, actionReceiver)
+		if err != nil {
+			response.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		response.Results[i].Schedule = schedule
+	}
+	return response, nil
+}
+
+func (a *ActionAPI) scheduleAction(
+	arg params.ActionSchedule,
+	actionReceiver func(string) (state.ActionReceiver, error),
+) (*params.ActionSchedule, error) {
+	receiver, err := actionReceiver(arg.Receiver)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if _, err := receiver.PrepareActionPayload(arg.Name, arg.Parameters); err != nil {
+		return nil, errors.Trace(err)
+	}
+	scheduleArgs := state.ActionScheduleArgs{
+		Receiver:   arg.Receiver,
+		Name:       arg.Name,
+		Parameters: arg.Parameters,
+		Schedule:   arg.Schedule,
+	}
+	if arg.At != nil {
+		scheduleArgs.At = *arg.At
+	}
+	schedule, err := a.model.AddActionSchedule(scheduleArgs)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return makeActionSchedule(schedule), nil
+}
+
+// ActionSchedules returns all of the action schedules in the model,
+// with the history of their recent runs.
+func (a *ActionAPI) ActionSchedules() (params.ActionScheduleResults, error) {
+	if err := a.checkCanRead(); err != nil {
+		return params.ActionScheduleResults{}, errors.Trace(err)
+	}
+
+	schedules, err := a.model.AllActionSchedules()
+	if err != nil {
+		return params.ActionScheduleResults{}, errors.Trace(err)
+	}
+	response := params.ActionScheduleResults{Results: make([]params.ActionScheduleResult, len(schedules))}
+	for i, schedule := range schedules {
+		response.Results[i].Schedule = makeActionSchedule(schedule)
+	}
+	return response, nil
+}
+
+// RemoveActionSchedules removes the action schedules with the given
+// IDs. Actions already queued by the schedules are not affected.
+func (a *ActionAPI) RemoveActionSchedules(args params.ActionScheduleIds) (params.ErrorResults, error) {
+	if err := a.checkCanWrite(); err != nil {
+		return params.ErrorResults{}, errors.Trace(err)
+	}
+
+	if err := a.check.RemoveAllowed(); err != nil {
+		return params.ErrorResults{}, errors.Trace(err)
+	}
+
+	response := params.ErrorResults{Results: make([]params.ErrorResult, len(args.Ids))}
+	for i, id := range args.Ids {
+		err := a.model.RemoveActionSchedule(id)
+		response.Results[i].Error = common.ServerError(err)
+	}
+	return response, nil
+}
+
+func makeActionSchedule(schedule *state.ActionSchedule) *params.ActionSchedule {
+	result := &params.ActionSchedule{
+		Id:         schedule.Id(),
+		Receiver:   schedule.Receiver(),
+		Name:       schedule.Name(),
+		Parameters: schedule.Parameters(),
+		Schedule:   schedule.Schedule(),
+		Created:    timePtr(schedule.Created()),
+		NextRun:    timePtr(schedule.NextRun()),
+	}
+	for _, run := range schedule.Runs() {
+		resultRun := params.ActionScheduleRun{
+			Time:  run.Time,
+			Error: run.Error,
+		}
+		if run.ActionId != "" {
+			resultRun.Action = names.NewActionTag(run.ActionId).String()
+		}
+		result.Runs = append(result.Runs, resultRun)
+	}
+	return result
+}
+
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// ScheduleActions isn't on the v3 API. The API reflection code in
+// rpc/rpcreflect/type.go:newMethod skips 2-argument methods, so this
+// removes the method as far as the RPC machinery is concerned.
+func (a *APIv3) ScheduleActions(_, _ struct{}) {}
+
+// ActionSchedules isn't on the v3 API.
+func (a *APIv3) ActionSchedules(_, _ struct{}) {}
+
+// RemoveActionSchedules isn't on the v3 API.
+func (a *APIv3) RemoveActionSchedules(_, _ struct{}) {}
@@ -0,0 +1,92 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package action_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+)
+
+func (s *actionSuite) TestBlockScheduleActions(c *gc.C) {
+	s.BlockAllChanges(c, "ScheduleActions")
+	_, err := s.action.ScheduleActions(params.ActionSchedules{})
+	s.AssertBlocked(c, err, "ScheduleActions")
+}
+
+func (s *actionSuite) TestBlockRemoveActionSchedules(c *gc.C) {
+	s.BlockRemoveObject(c, "RemoveActionSchedules")
+	_, err := s.action.RemoveActionSchedules(params.ActionScheduleIds{})
+	s.AssertBlocked(c, err, "RemoveActionSchedules")
+}
+
+func (s *actionSuite) TestScheduleActions(c *gc.C) {
+	at := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	receiver := s.wordpressUnit.Tag().String()
+	results, err := s.action.ScheduleActions(params.ActionSchedules{
+		Schedules: []params.ActionSchedule{
+			{Receiver: receiver, Name: "fakeaction", At: &at},
+			{Receiver: receiver, Name: "fakeaction", Schedule: "*/15 * * * *"},
+			{Receiver: receiver, Name: "fakeaction"},
+			{Receiver: receiver, Name: "nope", Schedule: "@daily"},
+			{Receiver: "unit-wordpress-9", Name: "fakeaction", Schedule: "@daily"},
+			{Receiver: "wordpress/9", Name: "fakeaction", Schedule: "@daily"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 6)
+
+	oneOff := results.Results[0]
+	c.Assert(oneOff.Error, gc.IsNil)
+	c.Check(oneOff.Schedule.Receiver, gc.Equals, receiver)
+	c.Check(oneOff.Schedule.Name, gc.Equals, "fakeaction")
+	c.Check(oneOff.Schedule.Schedule, gc.Equals, "")
+	c.Check(oneOff.Schedule.NextRun, jc.DeepEquals, &at)
+	c.Check(oneOff.Schedule.Created, gc.NotNil)
+
+	recurring := results.Results[1]
+	c.Assert(recurring.Error, gc.IsNil)
+	c.Check(recurring.Schedule.Schedule, gc.Equals, "*/15 * * * *")
+	c.Check(recurring.Schedule.NextRun, gc.NotNil)
+	c.Check(recurring.Schedule.Id, gc.Not(gc.Equals), oneOff.Schedule.Id)
+
+	c.Check(results.Results[2].Error, gc.ErrorMatches,
+		"cannot add action schedule: specifying both or neither of a time and a schedule not valid")
+	c.Check(results.Results[3].Error, gc.ErrorMatches, `action "nope" not defined on unit "wordpress/0"`)
+	c.Check(results.Results[4].Error, gc.ErrorMatches, `unit-wordpress-9 not found`)
+	c.Check(results.Results[5].Error, gc.ErrorMatches, `wordpress/9 not valid`)
+
+	listed, err := s.action.ActionSchedules()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(listed.Results, gc.HasLen, 2)
+	c.Check(listed.Results[0].Schedule, jc.DeepEquals, oneOff.Schedule)
+	c.Check(listed.Results[1].Schedule, jc.DeepEquals, recurring.Schedule)
+}
+
+func (s *actionSuite) TestRemoveActionSchedules(c *gc.C) {
+	results, err := s.action.ScheduleActions(params.ActionSchedules{
+		Schedules: []params.ActionSchedule{{
+			Receiver: s.wordpressUnit.Tag().String(),
+			Name:     "fakeaction",
+			Schedule: "@hourly",
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	id := results.Results[0].Schedule.Id
+
+	removed, err := s.action.RemoveActionSchedules(params.ActionScheduleIds{Ids: []string{id, "42"}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(removed.Results, gc.HasLen, 2)
+	c.Check(removed.Results[0].Error, gc.IsNil)
+	c.Check(removed.Results[1].Error, jc.Satisfies, params.IsCodeNotFound)
+
+	listed, err := s.action.ActionSchedules()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(listed.Results, gc.HasLen, 0)
+}
@@ -36,6 +36,7 @@ type Backend interface {
 	ControllerConfig() (controller.Config, error)
 	StateServingInfo() (state.StateServingInfo, error)
 	RestoreInfo() *state.RestoreInfo
+	BackupScheduleStatus() (state.BackupScheduleStatus, error)
 }
 
 // API provides backup-specific API methods.
@@ -60,6 +61,34 @@ func NewAPIv2(backend Backend, resources facade.Resources, authorizer facade.Aut
 	return &APIv2{api}, nil
 }
 
+// APIv3 serves backup-specific API methods for version 3, which adds
+// incremental backups and restoring to a point in time.
+type APIv3 struct {
+	*APIv2
+}
+
+func NewAPIv3(backend Backend, resources facade.Resources, authorizer facade.Authorizer) (*APIv3, error) {
+	api, err := NewAPIv2(backend, resources, authorizer)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &APIv3{api}, nil
+}
+
+// APIv4 serves backup-specific API methods for version 4, which adds
+// encrypted backup archives.
+type APIv4 struct {
+	*APIv3
+}
+
+func NewAPIv4(backend Backend, resources facade.Resources, authorizer facade.Authorizer) (*APIv4, error) {
+	api, err := NewAPIv3(backend, resources, authorizer)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &APIv4{api}, nil
+}
+
 // NewAPI creates a new instance of the Backups API facade.
 func NewAPI(backend Backend, resources facade.Resources, authorizer facade.Authorizer) (*API, error) {
 	isControllerAdmin, err := authorizer.HasPermission(permission.SuperuserAccess, backend.ControllerTag())
@@ -148,6 +177,12 @@ func CreateResult(meta *backups.Metadata, filename string) params.BackupsMetadat
 		result.Finished = *meta.Finished
 	}
 	result.Notes = meta.Notes
+	result.Scheduled = meta.Scheduled
+	result.Base = meta.Base
+	result.OplogStart = meta.OplogStart
+	result.OplogEnd = meta.OplogEnd
+	result.Encrypted = meta.Encrypted
+	result.KeyFingerprint = meta.KeyFingerprint
 
 	result.Model = meta.Origin.Model
 	result.Machine = meta.Origin.Machine
@@ -183,6 +218,12 @@ func MetadataFromResult(result params.BackupsMetadataResult) *backups.Metadata {
 	meta.Origin.Version = result.Version
 	meta.Origin.Series = result.Series
 	meta.Notes = result.Notes
+	meta.Scheduled = result.Scheduled
+	meta.Base = result.Base
+	meta.OplogStart = result.OplogStart
+	meta.OplogEnd = result.OplogEnd
+	meta.Encrypted = result.Encrypted
+	meta.KeyFingerprint = result.KeyFingerprint
 	meta.SetFileInfo(result.Size, result.Checksum, result.ChecksumFormat)
 	return meta
 }
@@ -12,7 +12,10 @@ import (
 	"github.com/juju/juju/state/backups"
 )
 
-var waitUntilReady = replicaset.WaitUntilReady
+var (
+	waitUntilReady = replicaset.WaitUntilReady
+	newOplogSource = backups.NewOplogSource
+)
 
 // Create is the API method that requests juju to create a new backup
 // of its state.  It returns the metadata for that backup.
@@ -31,6 +34,12 @@ func (a *API) Create(args params.BackupsCreateArgs) (params.BackupsMetadataResul
 }
 
 func (a *APIv2) Create(args params.BackupsCreateArgs) (params.BackupsMetadataResult, error) {
+	return a.create(args, nil)
+}
+
+// create creates a new full backup of the controller's state, and
+// encrypts the archive with the key if it isn't nil.
+func (a *API) create(args params.BackupsCreateArgs, key *backups.ArchiveKey) (params.BackupsMetadataResult, error) {
 	backupsMethods, closer := newBackups(a.backend)
 	defer closer.Close()
 
@@ -71,7 +80,7 @@ func (a *APIv2) Create(args params.BackupsCreateArgs) (params.BackupsMetadataRes
 	}
 	meta.Notes = args.Notes
 
-	fileName, err := backupsMethods.Create(meta, a.paths, dbInfo, args.KeepCopy, args.NoDownload)
+	fileName, err := backupsMethods.Create(meta, a.paths, dbInfo, args.KeepCopy, args.NoDownload, key)
 	if err != nil {
 		return result, errors.Trace(err)
 	}
@@ -79,3 +88,65 @@ func (a *APIv2) Create(args params.BackupsCreateArgs) (params.BackupsMetadataRes
 	result = CreateResult(meta, fileName)
 	return result, nil
 }
+
+// Create creates a new backup of the controller's state, as APIv2.Create
+// does. If a base backup is given, the new backup is an incremental
+// backup of the oplog since the base backup, or since the most recent
+// incremental backup of it.
+func (a *APIv3) Create(args params.BackupsCreateArgs) (params.BackupsMetadataResult, error) {
+	if args.Base == "" {
+		return a.APIv2.Create(args)
+	}
+
+	backupsMethods, closer := newBackups(a.backend)
+	defer closer.Close()
+
+	session := a.backend.MongoSession().Copy()
+	defer session.Close()
+
+	result := params.BackupsMetadataResult{}
+	// Don't go if HA isn't ready.
+	err := waitUntilReady(session, 60)
+	if err != nil {
+		return result, errors.Annotatef(err, "HA not ready; try again later")
+	}
+
+	mSeries, err := a.backend.MachineSeries(a.machineID)
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	meta, err := backups.NewMetadataState(a.backend, a.machineID, mSeries)
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	meta.Notes = args.Notes
+	meta.Base = args.Base
+
+	oplog := newOplogSource(session)
+	if err := backupsMethods.CreateIncremental(meta, a.paths, oplog); err != nil {
+		return result, errors.Trace(err)
+	}
+	return CreateResult(meta, ""), nil
+}
+
+// Create creates a new backup of the controller's state, as APIv3.Create
+// does. If a passphrase or a public key is given, the archive of a full
+// backup is encrypted with it.
+func (a *APIv4) Create(args params.BackupsCreateArgs) (params.BackupsMetadataResult, error) {
+	if args.Passphrase == "" && args.PublicKey == "" {
+		return a.APIv3.Create(args)
+	}
+	if args.Base != "" {
+		// Incremental backups are replayed by the controller when
+		// restoring, so it must be able to read them.
+		return params.BackupsMetadataResult{}, errors.New("incremental backups cannot be encrypted")
+	}
+	key := &backups.ArchiveKey{
+		Passphrase: args.Passphrase,
+		PublicKey:  args.PublicKey,
+	}
+	if err := key.Validate(); err != nil {
+		return params.BackupsMetadataResult{}, errors.Trace(err)
+	}
+	return a.create(args, key)
+}
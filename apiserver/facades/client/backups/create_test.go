@@ -10,6 +10,7 @@ import (
 
 	"github.com/juju/juju/apiserver/facades/client/backups"
 	"github.com/juju/juju/apiserver/params"
+	statebackups "github.com/juju/juju/state/backups"
 )
 
 func (s *backupsSuite) TestCreateOkay(c *gc.C) {
@@ -54,3 +55,74 @@ func (s *backupsSuite) TestCreateError(c *gc.C) {
 	c.Logf("%v", err)
 	c.Check(err, gc.ErrorMatches, "failed!")
 }
+
+func (s *backupsSuite) TestCreateIncremental(c *gc.C) {
+	s.PatchValue(backups.WaitUntilReady,
+		func(*mgo.Session, int) error { return nil },
+	)
+	oplog := &fakeOplog{}
+	s.PatchValue(backups.NewOplogSource,
+		func(*mgo.Session) statebackups.OplogSource { return oplog },
+	)
+	s.meta.Base = "base-id"
+	fake := s.setBackups(c, s.meta, "")
+	api, err := backups.NewAPIv3(&stateShim{s.State, s.Model}, s.resources, s.authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := api.Create(params.BackupsCreateArgs{
+		Notes: "nightly",
+		Base:  "base-id",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(fake.Calls, jc.DeepEquals, []string{"CreateIncremental"})
+	c.Check(fake.OplogArg, gc.Equals, oplog)
+	c.Check(result, gc.DeepEquals, backups.CreateResult(s.meta, ""))
+	c.Check(result.Base, gc.Equals, "base-id")
+}
+
+func (s *backupsSuite) TestCreateIncrementalError(c *gc.C) {
+	s.PatchValue(backups.WaitUntilReady,
+		func(*mgo.Session, int) error { return nil },
+	)
+	s.PatchValue(backups.NewOplogSource,
+		func(*mgo.Session) statebackups.OplogSource { return &fakeOplog{} },
+	)
+	s.setBackups(c, nil, "failed!")
+	api, err := backups.NewAPIv3(&stateShim{s.State, s.Model}, s.resources, s.authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = api.Create(params.BackupsCreateArgs{Base: "base-id"})
+	c.Check(err, gc.ErrorMatches, "failed!")
+}
+
+func (s *backupsSuite) TestCreateEncrypted(c *gc.C) {
+	s.PatchValue(backups.WaitUntilReady,
+		func(*mgo.Session, int) error { return nil },
+	)
+	s.meta.Encrypted = true
+	fake := s.setBackups(c, s.meta, "")
+	api, err := backups.NewAPIv4(&stateShim{s.State, s.Model}, s.resources, s.authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := api.Create(params.BackupsCreateArgs{Passphrase: "secret"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(fake.Calls, jc.DeepEquals, []string{"Create"})
+	c.Check(fake.KeyArg, jc.DeepEquals, &statebackups.ArchiveKey{Passphrase: "secret"})
+	c.Check(result.Encrypted, jc.IsTrue)
+}
+
+func (s *backupsSuite) TestCreateEncryptedInvalid(c *gc.C) {
+	fake := s.setBackups(c, s.meta, "")
+	api, err := backups.NewAPIv4(&stateShim{s.State, s.Model}, s.resources, s.authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = api.Create(params.BackupsCreateArgs{Passphrase: "secret", Base: "base-id"})
+	c.Check(err, gc.ErrorMatches, "incremental backups cannot be encrypted")
+	_, err = api.Create(params.BackupsCreateArgs{Passphrase: "secret", PublicKey: "<key>"})
+	c.Check(err, gc.ErrorMatches, "cannot use both a passphrase and a public key")
+	c.Check(fake.Calls, gc.HasLen, 0)
+}
+
+type fakeOplog struct {
+	statebackups.OplogSource
+}
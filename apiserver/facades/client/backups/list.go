@@ -26,5 +26,30 @@ func (a *API) List(args params.BackupsListArgs) (params.BackupsListResult, error
 		result.List[i] = CreateResult(meta, "")
 	}
 
+	controllerConfig, err := a.backend.ControllerConfig()
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	// The backup scheduler records when the next scheduled backup is
+	// due, and how the last went.
+	status, err := a.backend.BackupScheduleStatus()
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	if spec := controllerConfig.BackupSchedule(); spec != "" {
+		result.Schedule = spec
+		if !status.NextRun.IsZero() {
+			next := status.NextRun
+			result.NextScheduled = &next
+		}
+		result.CopyDir = controllerConfig.BackupCopyDir()
+	}
+	if !status.LastRun.IsZero() {
+		last := status.LastRun
+		result.LastScheduled = &last
+		result.LastScheduledID = status.LastBackupID
+		result.LastScheduledError = status.LastError
+	}
+
 	return result, nil
 }
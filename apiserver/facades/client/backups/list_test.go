@@ -6,12 +6,14 @@ package backups_test
 import (
 	"bytes"
 	"io/ioutil"
+	"time"
 
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
 	"github.com/juju/juju/apiserver/facades/client/backups"
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
 )
 
 func (s *backupsSuite) TestListOkay(c *gc.C) {
@@ -29,6 +31,46 @@ func (s *backupsSuite) TestListOkay(c *gc.C) {
 	c.Check(result, gc.DeepEquals, expected)
 }
 
+func (s *backupsSuite) TestListSchedule(c *gc.C) {
+	s.setBackups(c, s.meta, "")
+	err := s.State.UpdateControllerConfig(map[string]interface{}{
+		"backup-schedule": "@daily",
+		"backup-copy-dir": "/srv/backups",
+	}, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	next := time.Date(2018, 7, 3, 0, 0, 0, 0, time.UTC)
+	last := time.Date(2018, 7, 2, 0, 0, 0, 0, time.UTC)
+	err = s.State.SetBackupScheduleStatus(state.BackupScheduleStatus{
+		NextRun:   next,
+		LastRun:   last,
+		LastError: "creating backup: disk full",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.api.List(params.BackupsListArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(result.Schedule, gc.Equals, "@daily")
+	c.Check(result.CopyDir, gc.Equals, "/srv/backups")
+	c.Check(result.NextScheduled, jc.DeepEquals, &next)
+	c.Check(result.LastScheduled, jc.DeepEquals, &last)
+	c.Check(result.LastScheduledID, gc.Equals, "")
+	c.Check(result.LastScheduledError, gc.Equals, "creating backup: disk full")
+}
+
+func (s *backupsSuite) TestListScheduleNotYetRun(c *gc.C) {
+	s.setBackups(c, s.meta, "")
+	err := s.State.UpdateControllerConfig(map[string]interface{}{
+		"backup-schedule": "@daily",
+	}, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.api.List(params.BackupsListArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(result.Schedule, gc.Equals, "@daily")
+	c.Check(result.NextScheduled, gc.IsNil)
+	c.Check(result.LastScheduled, gc.IsNil)
+}
+
 func (s *backupsSuite) TestListError(c *gc.C) {
 	s.setBackups(c, nil, "failed!")
 	args := params.BackupsListArgs{}
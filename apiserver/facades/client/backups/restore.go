@@ -6,6 +6,7 @@ package backups
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/juju/errors"
 	"gopkg.in/juju/names.v2"
@@ -67,6 +68,16 @@ func (a *API) Restore(p params.RestoreArgs) error {
 		NewInstTag:     machine.Tag(),
 		NewInstSeries:  machine.Series(),
 	}
+	if p.ToTime != nil {
+		restoreArgs.ToTime = *p.ToTime
+		logger.Infof("restoring to %s", p.ToTime.Format(time.RFC3339))
+	}
+	if p.Passphrase != "" || p.PrivateKey != "" {
+		restoreArgs.Key = &backups.ArchiveKey{
+			Passphrase: p.Passphrase,
+			PrivateKey: p.PrivateKey,
+		}
+	}
 
 	session := a.backend.MongoSession().Copy()
 	defer session.Close()
@@ -359,6 +359,12 @@ func (c *Client) FullStatus(args params.StatusParams) (params.FullStatus, error)
 		}
 	}
 
+	if len(args.Filters) > 0 {
+		if err := context.applyFilters(args.Filters); err != nil {
+			return noStatus, errors.Annotate(err, "could not filter status")
+		}
+	}
+
 	modelStatus, err := c.modelStatus()
 	if err != nil {
 		return noStatus, errors.Annotate(err, "cannot determine model status")
@@ -374,6 +380,13 @@ func (c *Client) FullStatus(args params.StatusParams) (params.FullStatus, error)
 	}, nil
 }
 
+// FullStatus gives the information needed for juju status over the
+// api. Status filters aren't supported on the v2 API, so are ignored.
+func (c *ClientV2) FullStatus(args params.StatusParams) (params.FullStatus, error) {
+	args.Filters = nil
+	return c.Client.FullStatus(args)
+}
+
 // newToolsVersionAvailable will return a string representing a tools
 // version only if the latest check is newer than current tools.
 func (c *Client) modelStatus() (params.ModelStatusInfo, error) {
@@ -19,6 +19,7 @@ import (
 	apiservertesting "github.com/juju/juju/apiserver/testing"
 	"github.com/juju/juju/core/instance"
 	"github.com/juju/juju/core/migration"
+	"github.com/juju/juju/core/status"
 	jujutesting "github.com/juju/juju/juju/testing"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/testing/factory"
@@ -567,6 +568,71 @@ func (s *statusUnitTestSuite) TestFilterOutRelationsForRelatedApplicationsThatDo
 	c.Assert(status.Relations, gc.HasLen, 0)
 }
 
+func (s *statusUnitTestSuite) TestFilterByWorkloadStatus(c *gc.C) {
+	app := s.Factory.MakeApplication(c, &factory.ApplicationParams{
+		Charm: s.Factory.MakeCharm(c, &factory.CharmParams{Name: "mysql"}),
+	})
+	s.Factory.MakeUnit(c, &factory.UnitParams{
+		Application: app,
+		Status:      &status.StatusInfo{Status: status.Active},
+	})
+	blocked := s.Factory.MakeUnit(c, &factory.UnitParams{
+		Application: app,
+		Status:      &status.StatusInfo{Status: status.Blocked, Message: "need config"},
+	})
+	s.Factory.MakeApplication(c, &factory.ApplicationParams{
+		Charm: s.Factory.MakeCharm(c, &factory.CharmParams{Name: "wordpress"}),
+	})
+
+	client := s.APIState.Client()
+	fullStatus, err := client.FilteredStatus(nil, []string{"workload=blocked"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(fullStatus.Applications, gc.HasLen, 1)
+	units := fullStatus.Applications[app.Name()].Units
+	c.Assert(units, gc.HasLen, 1)
+	_, ok := units[blocked.Name()]
+	c.Check(ok, jc.IsTrue)
+	c.Check(fullStatus.Machines, gc.HasLen, 1)
+}
+
+func (s *statusUnitTestSuite) TestFilterByCharmOrRelation(c *gc.C) {
+	wordpress := s.Factory.MakeApplication(c, &factory.ApplicationParams{
+		Name:  "wordpress",
+		Charm: s.Factory.MakeCharm(c, &factory.CharmParams{Name: "wordpress"}),
+	})
+	mysql := s.Factory.MakeApplication(c, &factory.ApplicationParams{
+		Name:  "mysql",
+		Charm: s.Factory.MakeCharm(c, &factory.CharmParams{Name: "mysql"}),
+	})
+	s.Factory.MakeApplication(c, &factory.ApplicationParams{
+		Name:  "logging",
+		Charm: s.Factory.MakeCharm(c, &factory.CharmParams{Name: "logging"}),
+	})
+	e1, err := wordpress.Endpoint("db")
+	c.Assert(err, jc.ErrorIsNil)
+	e2, err := mysql.Endpoint("server")
+	c.Assert(err, jc.ErrorIsNil)
+	s.Factory.MakeRelation(c, &factory.RelationParams{
+		Endpoints: []state.Endpoint{e1, e2},
+	})
+
+	client := s.APIState.Client()
+	fullStatus, err := client.FilteredStatus(nil, []string{"relation=mysql:server"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(fullStatus.Applications, gc.HasLen, 1)
+	c.Check(fullStatus.Applications["wordpress"], gc.NotNil)
+	c.Check(fullStatus.Relations, gc.HasLen, 1)
+
+	fullStatus, err = client.FilteredStatus(nil, []string{"charm=cs:logging", "charm=mysql"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(fullStatus.Applications, gc.HasLen, 2)
+	_, ok := fullStatus.Applications["wordpress"]
+	c.Check(ok, jc.IsFalse)
+
+	_, err = client.FilteredStatus(nil, []string{"workload=sleepy"})
+	c.Assert(err, gc.ErrorMatches, `could not filter status: .*sleepy.*`)
+}
+
 func assertApplicationRelations(c *gc.C, appName string, expectedNumber int, relations []params.RelationStatus) {
 	c.Assert(relations, gc.HasLen, expectedNumber)
 	for _, relation := range relations {
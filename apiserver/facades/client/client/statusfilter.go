@@ -0,0 +1,216 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client
+
+import (
+	"strings"
+
+	"github.com/juju/collections/set"
+	"github.com/juju/errors"
+	"gopkg.in/juju/charm.v6"
+
+	"github.com/juju/juju/core/status"
+	"github.com/juju/juju/state"
+)
+
+// applyFilters removes the applications, units and machines that match
+// none of the filter expressions from the status context. Applications
+// are kept if they satisfy a filter's application terms and, when it
+// has unit terms, have a unit satisfying them. The principals of kept
+// subordinate units are kept too, so that the subordinates are shown.
+func (context *statusContext) applyFilters(exprs []string) error {
+	filters := make([]status.Filter, len(exprs))
+	for i, expr := range exprs {
+		filter, err := status.ParseFilter(expr)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		filters[i] = filter
+	}
+
+	keptApps := set.NewStrings()
+	keptUnits := set.NewStrings()
+	for appName, app := range context.allAppsUnitsCharmBindings.applications {
+		units := context.allAppsUnitsCharmBindings.units[appName]
+		for _, filter := range filters {
+			matches, err := context.applicationMatches(app, filter)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if !matches {
+				continue
+			}
+			if filter.Values(status.FilterWorkload) == nil && filter.Values(status.FilterAgent) == nil {
+				keptApps.Add(appName)
+				for unitName := range units {
+					keptUnits.Add(unitName)
+				}
+				continue
+			}
+			for unitName, unit := range units {
+				if context.unitMatches(unit, filter) {
+					keptApps.Add(appName)
+					keptUnits.Add(unitName)
+				}
+			}
+		}
+	}
+
+	// Keep the principals of kept subordinates.
+	for _, unitName := range keptUnits.Values() {
+		unit := context.unitByName(unitName)
+		if principal, ok := unit.PrincipalName(); ok {
+			keptUnits.Add(principal)
+			keptApps.Add(strings.Split(principal, "/")[0])
+		}
+	}
+
+	matchedMachines := set.NewStrings()
+	for appName, units := range context.allAppsUnitsCharmBindings.units {
+		for unitName, unit := range units {
+			if !keptUnits.Contains(unitName) {
+				delete(units, unitName)
+				continue
+			}
+			if machineId, err := unit.AssignedMachineId(); err == nil {
+				matchedMachines.Add(machineId)
+			}
+		}
+		if !keptApps.Contains(appName) {
+			delete(context.allAppsUnitsCharmBindings.units, appName)
+		}
+	}
+	for appName := range context.allAppsUnitsCharmBindings.applications {
+		if keptApps.Contains(appName) {
+			continue
+		}
+		delete(context.allAppsUnitsCharmBindings.applications, appName)
+		for _, r := range context.relations[appName] {
+			delete(context.relationsById, r.Id())
+		}
+		delete(context.relations, appName)
+	}
+	for offerName, offer := range context.offers {
+		if !keptApps.Contains(offer.ApplicationName) {
+			delete(context.offers, offerName)
+		}
+	}
+
+	for host, machineList := range context.machines {
+		var matched []*state.Machine
+		for _, m := range machineList {
+			if matchedMachines.Contains(m.Id()) {
+				matched = append(matched, m)
+			}
+		}
+		if len(matched) == 0 {
+			delete(context.machines, host)
+			continue
+		}
+		// The host machine is always listed first, so that the
+		// containers are nested under it.
+		if matched[0] != machineList[0] {
+			matched = append([]*state.Machine{machineList[0]}, matched...)
+		}
+		context.machines[host] = matched
+	}
+	return nil
+}
+
+// applicationMatches reports whether the application satisfies the
+// filter's relation and charm terms.
+func (context *statusContext) applicationMatches(app *state.Application, filter status.Filter) (bool, error) {
+	if values := filter.Values(status.FilterCharm); values != nil {
+		curl, _ := app.CharmURL()
+		matches := false
+		for _, value := range values {
+			ok, err := charmMatches(curl, value)
+			if err != nil {
+				return false, errors.Trace(err)
+			}
+			matches = matches || ok
+		}
+		if !matches {
+			return false, nil
+		}
+	}
+	if values := filter.Values(status.FilterRelation); values != nil {
+		matches := false
+		for _, value := range values {
+			matches = matches || context.hasRelation(app.Name(), value)
+		}
+		if !matches {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// hasRelation reports whether the application takes part in a relation
+// with the given endpoint, "<application>[:<endpoint>]".
+func (context *statusContext) hasRelation(appName, endpoint string) bool {
+	parts := strings.SplitN(endpoint, ":", 2)
+	for _, rel := range context.relations[appName] {
+		for _, ep := range rel.Endpoints() {
+			if ep.ApplicationName != parts[0] {
+				continue
+			}
+			if len(parts) == 1 || ep.Name == parts[1] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// charmMatches reports whether the charm URL matches the value, which
+// is either a charm name or a charm URL that may omit the series and
+// revision.
+func charmMatches(curl *charm.URL, value string) (bool, error) {
+	if curl == nil {
+		return false, nil
+	}
+	if !strings.Contains(value, ":") {
+		return curl.Name == value, nil
+	}
+	want, err := charm.ParseURL(value)
+	if err != nil {
+		return false, errors.NotValidf("charm filter %q", value)
+	}
+	return want.Schema == curl.Schema &&
+		want.Name == curl.Name &&
+		(want.User == "" || want.User == curl.User) &&
+		(want.Series == "" || want.Series == curl.Series) &&
+		(want.Revision < 0 || want.Revision == curl.Revision), nil
+}
+
+// unitMatches reports whether the unit satisfies the filter's workload
+// and agent terms. The workload of a unit whose agent is in error is
+// considered to be in error too, as it is shown in status.
+func (context *statusContext) unitMatches(unit *state.Unit, filter status.Filter) bool {
+	agent, workload := context.presence.UnitStatus(&contextUnit{unit, context})
+	if agent.Err != nil || workload.Err != nil {
+		return false
+	}
+	workloadStatus := workload.Status.Status
+	if agent.Status.Status == status.Error {
+		workloadStatus = status.Error
+	}
+	return statusMatches(filter.Values(status.FilterWorkload), workloadStatus) &&
+		statusMatches(filter.Values(status.FilterAgent), agent.Status.Status)
+}
+
+// statusMatches reports whether the status is one of the values, or
+// there are no values.
+func statusMatches(values []string, s status.Status) bool {
+	if values == nil {
+		return true
+	}
+	for _, value := range values {
+		if status.Status(value) == s {
+			return true
+		}
+	}
+	return false
+}
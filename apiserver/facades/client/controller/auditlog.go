@@ -0,0 +1,124 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package controller
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	corecontroller "github.com/juju/juju/controller"
+	"github.com/juju/juju/core/auditlog"
+)
+
+// AuditLog returns the conversations recorded in the audit log of the
+// controller machine serving the request that match the given filter.
+// In an HA controller each machine keeps its own audit log, so
+// clients need to query each controller machine and merge the
+// results. Only controller superusers can read the audit log.
+func (c *ControllerAPI) AuditLog(args params.AuditLogQueryArgs) (params.AuditLogResults, error) {
+	var result params.AuditLogResults
+	if err := c.checkHasAdmin(); err != nil {
+		return result, errors.Trace(err)
+	}
+	// Only the file sink keeps records on the controller; the syslog
+	// and webhook sinks send them elsewhere.
+	cfg, err := c.state.ControllerConfig()
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	if sink := cfg.AuditLogSink(); sink != corecontroller.AuditLogSinkFile {
+		return result, errors.NotSupportedf("querying the audit log with the %q sink", sink)
+	}
+	logDir, err := c.stringResource("logDir")
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	machineID, err := c.stringResource("machineID")
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+
+	filter := auditlog.Filter{
+		Who:            args.Who,
+		Model:          args.Model,
+		Facade:         args.Facade,
+		Method:         args.Method,
+		ConversationID: args.ConversationID,
+		Limit:          args.Limit,
+	}
+	if args.After != nil {
+		filter.After = *args.After
+	}
+	if args.Before != nil {
+		filter.Before = *args.Before
+	}
+	records, err := auditlog.Query(logDir, filter)
+	if err != nil {
+		return result, errors.Annotate(err, "reading audit log")
+	}
+
+	result.ControllerMachine = machineID
+	result.Conversations = make([]params.AuditConversation, len(records))
+	for i, record := range records {
+		result.Conversations[i] = auditConversationResult(record)
+	}
+	return result, nil
+}
+
+// AuditLog isn't on the v5 API.
+func (c *ControllerAPIv5) AuditLog(_, _ struct{}) {}
+
+func (c *ControllerAPI) stringResource(key string) (string, error) {
+	value, ok := c.resources.Get(key).(common.StringResource)
+	if !ok {
+		return "", errors.NotFoundf("%s resource", key)
+	}
+	return value.String(), nil
+}
+
+func auditConversationResult(record auditlog.ConversationRecord) params.AuditConversation {
+	result := params.AuditConversation{
+		Who:            record.Who,
+		What:           record.What,
+		When:           parseAuditTime(record.When),
+		ModelName:      record.ModelName,
+		ModelUUID:      record.ModelUUID,
+		ConversationID: record.ConversationID,
+		ConnectionID:   record.ConnectionID,
+	}
+	for _, req := range record.Requests {
+		request := params.AuditRequest{
+			RequestID: req.RequestID,
+			When:      parseAuditTime(req.When),
+			Facade:    req.Facade,
+			Method:    req.Method,
+			Version:   req.Version,
+			Args:      req.Args,
+			Path:      req.Path,
+			Size:      req.Size,
+		}
+		for _, e := range req.Errors {
+			request.Errors = append(request.Errors, params.AuditError{
+				Message: e.Message,
+				Code:    e.Code,
+			})
+		}
+		result.Requests = append(result.Requests, request)
+	}
+	return result
+}
+
+// parseAuditTime converts the RFC 3339 timestamps written to the
+// audit log, returning the zero time if the value is malformed.
+func parseAuditTime(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		logger.Debugf("unexpected audit log time %q: %v", value, err)
+		return time.Time{}
+	}
+	return t.UTC()
+}
@@ -0,0 +1,115 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package controller_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/facade/facadetest"
+	"github.com/juju/juju/apiserver/facades/client/controller"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/permission"
+	"github.com/juju/juju/testing/factory"
+)
+
+const auditLogContents = `
+{"conversation":{"who":"fred","what":"juju deploy mysql","when":"2018-05-01T10:00:00Z","model-name":"fred/prod","model-uuid":"0f5dba5b-94f4-4fb4-8e0f-3c7d3e4c8a3a","conversation-id":"0000000000000001","connection-id":"1"}}
+{"request":{"conversation-id":"0000000000000001","connection-id":"1","request-id":1,"when":"2018-05-01T10:00:01Z","facade":"Application","method":"Deploy","version":6}}
+{"conversation":{"who":"mary","what":"juju remove-application mysql","when":"2018-05-08T09:30:00Z","model-name":"fred/prod","model-uuid":"0f5dba5b-94f4-4fb4-8e0f-3c7d3e4c8a3a","conversation-id":"0000000000000002","connection-id":"2"}}
+{"request":{"conversation-id":"0000000000000002","connection-id":"2","request-id":1,"when":"2018-05-08T09:30:01Z","facade":"Application","method":"DestroyApplication","version":6}}
+{"errors":{"conversation-id":"0000000000000002","connection-id":"2","request-id":1,"when":"2018-05-08T09:30:02Z","errors":[{"message":"oops","code":"not found"}]}}
+`
+
+func (s *controllerSuite) setUpAuditLog(c *gc.C) {
+	logDir := c.MkDir()
+	err := ioutil.WriteFile(filepath.Join(logDir, "audit.log"), []byte(auditLogContents[1:]), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.resources.RegisterNamed("logDir", common.StringResource(logDir))
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.resources.RegisterNamed("machineID", common.StringResource("1"))
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *controllerSuite) TestAuditLog(c *gc.C) {
+	s.setUpAuditLog(c)
+	after := time.Date(2018, 5, 2, 0, 0, 0, 0, time.UTC)
+	result, err := s.controller.AuditLog(params.AuditLogQueryArgs{
+		Model: "fred/prod",
+		After: &after,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.AuditLogResults{
+		ControllerMachine: "1",
+		Conversations: []params.AuditConversation{{
+			Who:            "mary",
+			What:           "juju remove-application mysql",
+			When:           time.Date(2018, 5, 8, 9, 30, 0, 0, time.UTC),
+			ModelName:      "fred/prod",
+			ModelUUID:      "0f5dba5b-94f4-4fb4-8e0f-3c7d3e4c8a3a",
+			ConversationID: "0000000000000002",
+			ConnectionID:   "2",
+			Requests: []params.AuditRequest{{
+				RequestID: 1,
+				When:      time.Date(2018, 5, 8, 9, 30, 1, 0, time.UTC),
+				Facade:    "Application",
+				Method:    "DestroyApplication",
+				Version:   6,
+				Errors: []params.AuditError{{
+					Message: "oops",
+					Code:    "not found",
+				}},
+			}},
+		}},
+	})
+}
+
+func (s *controllerSuite) TestAuditLogFilterByMethod(c *gc.C) {
+	s.setUpAuditLog(c)
+	result, err := s.controller.AuditLog(params.AuditLogQueryArgs{
+		Facade: "Application",
+		Method: "Deploy",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Conversations, gc.HasLen, 1)
+	c.Assert(result.Conversations[0].Who, gc.Equals, "fred")
+}
+
+func (s *controllerSuite) TestAuditLogNotSupportedForOtherSinks(c *gc.C) {
+	s.setUpAuditLog(c)
+	err := s.State.UpdateControllerConfig(map[string]interface{}{
+		"audit-log-sink":        "webhook",
+		"audit-log-webhook-url": "https://audit.example.com/records",
+	}, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = s.controller.AuditLog(params.AuditLogQueryArgs{})
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+	c.Assert(err, gc.ErrorMatches, `querying the audit log with the "webhook" sink not supported`)
+}
+
+func (s *controllerSuite) TestAuditLogRequiresSuperUser(c *gc.C) {
+	s.setUpAuditLog(c)
+	user := s.Factory.MakeUser(c, &factory.UserParams{
+		Access: permission.ReadAccess,
+	})
+	endpoint, err := controller.NewControllerAPIv6(
+		facadetest.Context{
+			State_:     s.State,
+			StatePool_: s.StatePool,
+			Resources_: s.resources,
+			Auth_:      apiservertesting.FakeAuthorizer{Tag: user.Tag()},
+		})
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = endpoint.AuditLog(params.AuditLogQueryArgs{})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
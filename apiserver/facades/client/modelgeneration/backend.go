@@ -0,0 +1,109 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package modelgeneration
+
+import (
+	"time"
+
+	"gopkg.in/juju/charm.v6"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/state"
+)
+
+// Backend contains the state methods used in this package,
+// allowing stubs to be created for testing.
+type Backend interface {
+	ModelTag() names.ModelTag
+	AddGeneration() error
+	NextGeneration() (Generation, error)
+	Generations() ([]Generation, error)
+	Application(string) (Application, error)
+}
+
+// Generation describes the methods of a model generation used by
+// this package.
+type Generation interface {
+	Id() string
+	Active() bool
+	Created() time.Time
+	Completed() (time.Time, bool)
+	Cancelled() bool
+	AssignedUnits() map[string][]string
+	Config() map[string]charm.Settings
+	CharmURL(string) (*charm.URL, bool)
+	Resources(string) map[string]string
+	AssignApplication(string) error
+	AssignUnit(string) error
+	AutoComplete() (bool, error)
+	Cancel() error
+}
+
+// Application describes the methods of an application used by this
+// package.
+type Application interface {
+	CharmConfig() (charm.Settings, error)
+	UnitNames() ([]string, error)
+}
+
+type stateShim struct {
+	model *state.Model
+}
+
+func (st stateShim) ModelTag() names.ModelTag {
+	return st.model.ModelTag()
+}
+
+func (st stateShim) AddGeneration() error {
+	return st.model.AddGeneration()
+}
+
+func (st stateShim) NextGeneration() (Generation, error) {
+	gen, err := st.model.NextGeneration()
+	if err != nil {
+		return nil, err
+	}
+	return gen, nil
+}
+
+func (st stateShim) Generations() ([]Generation, error) {
+	gens, err := st.model.Generations()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Generation, len(gens))
+	for i, gen := range gens {
+		result[i] = gen
+	}
+	return result, nil
+}
+
+func (st stateShim) Application(name string) (Application, error) {
+	app, err := st.model.State().Application(name)
+	if err != nil {
+		return nil, err
+	}
+	return applicationShim{app}, nil
+}
+
+type applicationShim struct {
+	*state.Application
+}
+
+func (a applicationShim) UnitNames() ([]string, error) {
+	units, err := a.AllUnits()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(units))
+	for i, u := range units {
+		names[i] = u.Name()
+	}
+	return names, nil
+}
+
+// NewStateBackend creates a backend for the facade to use.
+func NewStateBackend(m *state.Model) Backend {
+	return stateShim{model: m}
+}
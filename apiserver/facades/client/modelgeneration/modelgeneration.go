@@ -0,0 +1,263 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package modelgeneration
+
+import (
+	"sort"
+
+	"github.com/juju/errors"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/facade"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/permission"
+)
+
+// API implements the ModelGeneration facade, which manages the
+// lifecycle of a model's generations.
+type API struct {
+	backend Backend
+	auth    facade.Authorizer
+}
+
+// NewModelGenerationFacade is used for API registration.
+func NewModelGenerationFacade(ctx facade.Context) (*API, error) {
+	model, err := ctx.State().Model()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return NewModelGenerationAPI(NewStateBackend(model), ctx.Auth())
+}
+
+// NewModelGenerationAPI creates a new instance of the ModelGeneration
+// facade.
+func NewModelGenerationAPI(backend Backend, authorizer facade.Authorizer) (*API, error) {
+	if !authorizer.AuthClient() {
+		return nil, common.ErrPerm
+	}
+	return &API{
+		backend: backend,
+		auth:    authorizer,
+	}, nil
+}
+
+func (api *API) checkCanRead() error {
+	canRead, err := api.auth.HasPermission(permission.ReadAccess, api.backend.ModelTag())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !canRead {
+		return common.ErrPerm
+	}
+	return nil
+}
+
+func (api *API) checkCanWrite() error {
+	canWrite, err := api.auth.HasPermission(permission.WriteAccess, api.backend.ModelTag())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !canWrite {
+		return common.ErrPerm
+	}
+	return nil
+}
+
+// AddGeneration adds a "next" generation to the model, which becomes
+// the model's active generation.
+func (api *API) AddGeneration() (params.ErrorResult, error) {
+	if err := api.checkCanWrite(); err != nil {
+		return params.ErrorResult{}, errors.Trace(err)
+	}
+	var result params.ErrorResult
+	result.Error = common.ServerError(api.backend.AddGeneration())
+	return result, nil
+}
+
+// AdvanceGeneration assigns the input units and applications to the
+// model's next generation, so that they realise the config changes
+// made in it. Advancing an application assigns all of its units.
+// If every unit of each changed application has been advanced, the
+// generation is completed.
+func (api *API) AdvanceGeneration(args params.Entities) (params.AdvanceGenerationResult, error) {
+	if err := api.checkCanWrite(); err != nil {
+		return params.AdvanceGenerationResult{}, errors.Trace(err)
+	}
+	gen, err := api.backend.NextGeneration()
+	if err != nil {
+		return params.AdvanceGenerationResult{}, errors.Trace(err)
+	}
+
+	result := params.AdvanceGenerationResult{
+		AdvanceResults: make([]params.ErrorResult, len(args.Entities)),
+	}
+	for i, entity := range args.Entities {
+		result.AdvanceResults[i].Error = common.ServerError(api.advance(gen, entity.Tag))
+	}
+
+	completed, err := gen.AutoComplete()
+	result.CompleteResult = params.BoolResult{
+		Result: completed,
+		Error:  common.ServerError(err),
+	}
+	return result, nil
+}
+
+func (api *API) advance(gen Generation, tagString string) error {
+	tag, err := names.ParseTag(tagString)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	switch tag.Kind() {
+	case names.UnitTagKind:
+		return errors.Trace(gen.AssignUnit(tag.Id()))
+	case names.ApplicationTagKind:
+		app, err := api.backend.Application(tag.Id())
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err := gen.AssignApplication(tag.Id()); err != nil {
+			return errors.Trace(err)
+		}
+		unitNames, err := app.UnitNames()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		for _, name := range unitNames {
+			if err := gen.AssignUnit(name); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		return nil
+	}
+	return errors.NotValidf("expected unit or application tag, got %q", tagString)
+}
+
+// CancelGeneration completes the model's next generation without
+// waiting for every unit to be advanced. Config changes for applications
+// with all units advanced become current; the others are discarded.
+// Applications with only some of their units advanced must be fully
+// advanced first.
+func (api *API) CancelGeneration() (params.ErrorResult, error) {
+	if err := api.checkCanWrite(); err != nil {
+		return params.ErrorResult{}, errors.Trace(err)
+	}
+	var result params.ErrorResult
+	gen, err := api.backend.NextGeneration()
+	if err == nil {
+		err = gen.Cancel()
+	}
+	result.Error = common.ServerError(err)
+	return result, nil
+}
+
+// GenerationInfo returns the model's next generation, with the
+// applications changed in it and their config deltas from the current
+// generation.
+func (api *API) GenerationInfo() (params.GenerationResult, error) {
+	if err := api.checkCanRead(); err != nil {
+		return params.GenerationResult{}, errors.Trace(err)
+	}
+	gen, err := api.backend.NextGeneration()
+	if err != nil {
+		return params.GenerationResult{Error: common.ServerError(err)}, nil
+	}
+	generation, err := api.generationInfo(gen, true)
+	if err != nil {
+		return params.GenerationResult{Error: common.ServerError(err)}, nil
+	}
+	return params.GenerationResult{Generation: generation}, nil
+}
+
+// Generations returns all of the model's generations, completed or
+// not, in the order they were added.
+func (api *API) Generations() (params.GenerationResults, error) {
+	if err := api.checkCanRead(); err != nil {
+		return params.GenerationResults{}, errors.Trace(err)
+	}
+	gens, err := api.backend.Generations()
+	if err != nil {
+		return params.GenerationResults{}, errors.Trace(err)
+	}
+	result := params.GenerationResults{
+		Generations: make([]params.Generation, len(gens)),
+	}
+	for i, gen := range gens {
+		generation, err := api.generationInfo(gen, false)
+		if err != nil {
+			return params.GenerationResults{}, errors.Trace(err)
+		}
+		result.Generations[i] = generation
+	}
+	return result, nil
+}
+
+// generationInfo converts the input generation to its params
+// representation. If withDeltas is true, each application's unit count
+// and current config values are included.
+func (api *API) generationInfo(gen Generation, withDeltas bool) (params.Generation, error) {
+	result := params.Generation{
+		Id:        gen.Id(),
+		Active:    gen.Active(),
+		Created:   gen.Created(),
+		Cancelled: gen.Cancelled(),
+	}
+	if completed, ok := gen.Completed(); ok {
+		result.Completed = &completed
+	}
+
+	config := gen.Config()
+	var appNames []string
+	for name := range gen.AssignedUnits() {
+		appNames = append(appNames, name)
+	}
+	sort.Strings(appNames)
+	for _, name := range appNames {
+		units := append([]string{}, gen.AssignedUnits()[name]...)
+		sort.Strings(units)
+		genApp := params.GenerationApplication{
+			ApplicationName: name,
+			UnitsAssigned:   units,
+		}
+		if curl, ok := gen.CharmURL(name); ok {
+			genApp.CharmURL = curl.String()
+		}
+		for resName := range gen.Resources(name) {
+			genApp.Resources = append(genApp.Resources, resName)
+		}
+		sort.Strings(genApp.Resources)
+
+		changes := config[name]
+		var current map[string]interface{}
+		if withDeltas {
+			app, err := api.backend.Application(name)
+			if err != nil {
+				return params.Generation{}, errors.Trace(err)
+			}
+			unitNames, err := app.UnitNames()
+			if err != nil {
+				return params.Generation{}, errors.Trace(err)
+			}
+			genApp.UnitCount = len(unitNames)
+			if current, err = app.CharmConfig(); err != nil {
+				return params.Generation{}, errors.Trace(err)
+			}
+		}
+		var keys []string
+		for key := range changes {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			genApp.ConfigChanges = append(genApp.ConfigChanges, params.GenerationConfigChange{
+				Key:     key,
+				Current: current[key],
+				Next:    changes[key],
+			})
+		}
+		result.Applications = append(result.Applications, genApp)
+	}
+	return result, nil
+}
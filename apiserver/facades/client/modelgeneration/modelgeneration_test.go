@@ -0,0 +1,317 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package modelgeneration_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/charm.v6"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/facades/client/modelgeneration"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type modelGenerationSuite struct {
+	testing.IsolationSuite
+
+	backend    *mockBackend
+	generation *mockGeneration
+	authorizer apiservertesting.FakeAuthorizer
+	api        *modelgeneration.API
+}
+
+var _ = gc.Suite(&modelGenerationSuite{})
+
+var created = time.Date(2018, 12, 20, 10, 0, 0, 0, time.UTC)
+
+func (s *modelGenerationSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.authorizer = apiservertesting.FakeAuthorizer{
+		Tag:      names.NewUserTag("bruce@local"),
+		AdminTag: names.NewUserTag("bruce@local"),
+	}
+	s.generation = &mockGeneration{
+		id:     "1",
+		active: true,
+		assigned: map[string][]string{
+			"riak": {"riak/1"},
+		},
+		config: map[string]charm.Settings{
+			"riak": {"title": "next title", "outlook": nil},
+		},
+	}
+	s.backend = &mockBackend{
+		generation: s.generation,
+		apps: map[string]*mockApplication{
+			"riak": {
+				units:  []string{"riak/0", "riak/1"},
+				config: charm.Settings{"title": "My Title", "outlook": "grim"},
+			},
+		},
+	}
+	var err error
+	s.api, err = modelgeneration.NewModelGenerationAPI(s.backend, &s.authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *modelGenerationSuite) TestNewAPIRequiresClient(c *gc.C) {
+	s.authorizer.Tag = names.NewMachineTag("0")
+	_, err := modelgeneration.NewModelGenerationAPI(s.backend, &s.authorizer)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *modelGenerationSuite) TestAddGeneration(c *gc.C) {
+	result, err := s.api.AddGeneration()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Error, gc.IsNil)
+	s.backend.CheckCallNames(c, "ModelTag", "AddGeneration")
+}
+
+func (s *modelGenerationSuite) TestAddGenerationError(c *gc.C) {
+	s.backend.SetErrors(errors.New("model has a next generation that is not completed"))
+	result, err := s.api.AddGeneration()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Error, gc.ErrorMatches, "model has a next generation that is not completed")
+}
+
+func (s *modelGenerationSuite) TestAddGenerationRequiresWrite(c *gc.C) {
+	s.authorizer.Tag = names.NewUserTag("read-only")
+	s.authorizer.AdminTag = names.NewUserTag("someone-else")
+	_, err := s.api.AddGeneration()
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *modelGenerationSuite) TestAdvanceGeneration(c *gc.C) {
+	s.generation.completed = true
+	result, err := s.api.AdvanceGeneration(params.Entities{Entities: []params.Entity{
+		{Tag: names.NewUnitTag("riak/0").String()},
+		{Tag: names.NewApplicationTag("riak").String()},
+		{Tag: names.NewMachineTag("0").String()},
+	}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.AdvanceResults, gc.HasLen, 3)
+	c.Check(result.AdvanceResults[0].Error, gc.IsNil)
+	c.Check(result.AdvanceResults[1].Error, gc.IsNil)
+	c.Check(result.AdvanceResults[2].Error, gc.ErrorMatches, `expected unit or application tag, got "machine-0" not valid`)
+	c.Check(result.CompleteResult, jc.DeepEquals, params.BoolResult{Result: true})
+
+	s.generation.CheckCalls(c, []testing.StubCall{
+		{"AssignUnit", []interface{}{"riak/0"}},
+		{"AssignApplication", []interface{}{"riak"}},
+		{"AssignUnit", []interface{}{"riak/0"}},
+		{"AssignUnit", []interface{}{"riak/1"}},
+		{"AutoComplete", nil},
+	})
+}
+
+func (s *modelGenerationSuite) TestCancelGeneration(c *gc.C) {
+	result, err := s.api.CancelGeneration()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Error, gc.IsNil)
+	s.generation.CheckCallNames(c, "Cancel")
+}
+
+func (s *modelGenerationSuite) TestCancelGenerationError(c *gc.C) {
+	s.generation.SetErrors(errors.New("cannot cancel generation, there are units behind a generation"))
+	result, err := s.api.CancelGeneration()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Error, gc.ErrorMatches, "cannot cancel generation, there are units behind a generation")
+}
+
+func (s *modelGenerationSuite) TestGenerationInfo(c *gc.C) {
+	result, err := s.api.GenerationInfo()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.GenerationResult{
+		Generation: params.Generation{
+			Id:      "1",
+			Active:  true,
+			Created: created,
+			Applications: []params.GenerationApplication{{
+				ApplicationName: "riak",
+				UnitsAssigned:   []string{"riak/1"},
+				UnitCount:       2,
+				ConfigChanges: []params.GenerationConfigChange{
+					{Key: "outlook", Current: "grim", Next: nil},
+					{Key: "title", Current: "My Title", Next: "next title"},
+				},
+			}},
+		},
+	})
+}
+
+func (s *modelGenerationSuite) TestGenerationInfoCharmAndResources(c *gc.C) {
+	s.generation.config = nil
+	s.generation.charms = map[string]*charm.URL{"riak": charm.MustParseURL("cs:riak-7")}
+	s.generation.resources = map[string]map[string]string{
+		"riak": {"store": "pending-2", "data": "pending-1"},
+	}
+	result, err := s.api.GenerationInfo()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Generation.Applications, jc.DeepEquals, []params.GenerationApplication{{
+		ApplicationName: "riak",
+		UnitsAssigned:   []string{"riak/1"},
+		UnitCount:       2,
+		CharmURL:        "cs:riak-7",
+		Resources:       []string{"data", "store"},
+	}})
+}
+
+func (s *modelGenerationSuite) TestGenerationInfoNotFound(c *gc.C) {
+	s.backend.generation = nil
+	result, err := s.api.GenerationInfo()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Error, jc.Satisfies, params.IsCodeNotFound)
+}
+
+func (s *modelGenerationSuite) TestGenerations(c *gc.C) {
+	completed := created.Add(time.Hour)
+	old := &mockGeneration{
+		id:           "1",
+		assigned:     map[string][]string{"riak": {}},
+		config:       map[string]charm.Settings{"riak": {"title": "abandoned"}},
+		completedAt:  completed,
+		wasCancelled: true,
+	}
+	s.generation.id = "2"
+	s.backend.generations = []modelgeneration.Generation{old, s.generation}
+
+	result, err := s.api.Generations()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.GenerationResults{
+		Generations: []params.Generation{{
+			Id:        "1",
+			Created:   created,
+			Completed: &completed,
+			Cancelled: true,
+			Applications: []params.GenerationApplication{{
+				ApplicationName: "riak",
+				UnitsAssigned:   []string{},
+				ConfigChanges: []params.GenerationConfigChange{
+					{Key: "title", Next: "abandoned"},
+				},
+			}},
+		}, {
+			Id:      "2",
+			Active:  true,
+			Created: created,
+			Applications: []params.GenerationApplication{{
+				ApplicationName: "riak",
+				UnitsAssigned:   []string{"riak/1"},
+				ConfigChanges: []params.GenerationConfigChange{
+					{Key: "outlook"},
+					{Key: "title", Next: "next title"},
+				},
+			}},
+		}},
+	})
+}
+
+type mockBackend struct {
+	testing.Stub
+	generation  *mockGeneration
+	generations []modelgeneration.Generation
+	apps        map[string]*mockApplication
+}
+
+func (b *mockBackend) ModelTag() names.ModelTag {
+	b.MethodCall(b, "ModelTag")
+	return coretesting.ModelTag
+}
+
+func (b *mockBackend) AddGeneration() error {
+	b.MethodCall(b, "AddGeneration")
+	return b.NextErr()
+}
+
+func (b *mockBackend) NextGeneration() (modelgeneration.Generation, error) {
+	b.MethodCall(b, "NextGeneration")
+	if b.generation == nil {
+		return nil, errors.NotFoundf("next generation")
+	}
+	return b.generation, b.NextErr()
+}
+
+func (b *mockBackend) Generations() ([]modelgeneration.Generation, error) {
+	b.MethodCall(b, "Generations")
+	return b.generations, b.NextErr()
+}
+
+func (b *mockBackend) Application(name string) (modelgeneration.Application, error) {
+	b.MethodCall(b, "Application", name)
+	app, ok := b.apps[name]
+	if !ok {
+		return nil, errors.NotFoundf("application %q", name)
+	}
+	return app, nil
+}
+
+type mockGeneration struct {
+	testing.Stub
+	id           string
+	active       bool
+	assigned     map[string][]string
+	config       map[string]charm.Settings
+	charms       map[string]*charm.URL
+	resources    map[string]map[string]string
+	completed    bool
+	completedAt  time.Time
+	wasCancelled bool
+}
+
+func (g *mockGeneration) Id() string                         { return g.id }
+func (g *mockGeneration) Active() bool                       { return g.active }
+func (g *mockGeneration) Created() time.Time                 { return created }
+func (g *mockGeneration) Cancelled() bool                    { return g.wasCancelled }
+func (g *mockGeneration) AssignedUnits() map[string][]string { return g.assigned }
+func (g *mockGeneration) Config() map[string]charm.Settings  { return g.config }
+func (g *mockGeneration) Completed() (time.Time, bool)       { return g.completedAt, !g.completedAt.IsZero() }
+
+func (g *mockGeneration) CharmURL(appName string) (*charm.URL, bool) {
+	curl, ok := g.charms[appName]
+	return curl, ok
+}
+
+func (g *mockGeneration) Resources(appName string) map[string]string {
+	return g.resources[appName]
+}
+
+func (g *mockGeneration) AssignApplication(name string) error {
+	g.MethodCall(g, "AssignApplication", name)
+	return g.NextErr()
+}
+
+func (g *mockGeneration) AssignUnit(name string) error {
+	g.MethodCall(g, "AssignUnit", name)
+	return g.NextErr()
+}
+
+func (g *mockGeneration) AutoComplete() (bool, error) {
+	g.MethodCall(g, "AutoComplete")
+	return g.completed, g.NextErr()
+}
+
+func (g *mockGeneration) Cancel() error {
+	g.MethodCall(g, "Cancel")
+	return g.NextErr()
+}
+
+type mockApplication struct {
+	units  []string
+	config charm.Settings
+}
+
+func (a *mockApplication) CharmConfig() (charm.Settings, error) {
+	return a.config, nil
+}
+
+func (a *mockApplication) UnitNames() ([]string, error) {
+	return a.units, nil
+}
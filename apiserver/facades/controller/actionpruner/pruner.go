@@ -4,12 +4,21 @@
 package actionpruner
 
 import (
+	"github.com/juju/errors"
+	"gopkg.in/juju/names.v2"
+
 	"github.com/juju/juju/apiserver/common"
 	"github.com/juju/juju/apiserver/facade"
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/core/actions"
 	"github.com/juju/juju/state"
 )
 
+// MaxPrunableActions is the most actions returned by a single call
+// to PrunableActions.
+const MaxPrunableActions = 1000
+
+// API implements the ActionPruner facade, version 2.
 type API struct {
 	*common.ModelWatcher
 	st         *state.State
@@ -17,6 +26,20 @@ type API struct {
 	authorizer facade.Authorizer
 }
 
+// APIv1 implements version 1 of the ActionPruner facade.
+type APIv1 struct {
+	*API
+}
+
+// NewAPIv1 creates a new ActionPruner API, version 1.
+func NewAPIv1(st *state.State, r facade.Resources, auth facade.Authorizer) (*APIv1, error) {
+	api, err := NewAPI(st, r, auth)
+	if err != nil {
+		return nil, err
+	}
+	return &APIv1{api}, nil
+}
+
 func NewAPI(st *state.State, r facade.Resources, auth facade.Authorizer) (*API, error) {
 	m, err := st.Model()
 	if err != nil {
@@ -26,14 +49,67 @@ func NewAPI(st *state.State, r facade.Resources, auth facade.Authorizer) (*API,
 	return &API{
 		ModelWatcher: common.NewModelWatcher(m, r, auth),
 		st:           st,
+		model:        m,
 		authorizer:   auth,
 	}, nil
 }
 
+// Prune removes the completed actions older than the given age, or
+// the age set for them by the model's action results retention, and
+// then the oldest actions until the collection is smaller than the
+// given size.
 func (api *API) Prune(p params.ActionPruneArgs) error {
 	if !api.authorizer.AuthController() {
 		return common.ErrPerm
 	}
 
-	return state.PruneActions(api.st, p.MaxHistoryTime, p.MaxHistoryMB)
+	retention, err := api.retention()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return state.PruneActions(api.st, p.MaxHistoryTime, p.MaxHistoryMB, retention...)
 }
+
+// PrunableActions returns a page of the completed actions that Prune
+// would remove by age with the same arguments, so that their results
+// can be exported before they are pruned. At most MaxPrunableActions
+// are returned, however large the requested limit.
+func (api *API) PrunableActions(p params.PrunableActionsArgs) (params.ActionResults, error) {
+	if !api.authorizer.AuthController() {
+		return params.ActionResults{}, common.ErrPerm
+	}
+
+	retention, err := api.retention()
+	if err != nil {
+		return params.ActionResults{}, errors.Trace(err)
+	}
+	limit := p.Limit
+	if limit <= 0 || limit > MaxPrunableActions {
+		limit = MaxPrunableActions
+	}
+	prunable, err := state.PrunableActions(api.st, p.MaxHistoryTime, p.Offset, limit, retention...)
+	if err != nil {
+		return params.ActionResults{}, errors.Trace(err)
+	}
+	response := params.ActionResults{Results: make([]params.ActionResult, len(prunable))}
+	for i, action := range prunable {
+		receiverTag, err := names.ActionReceiverTag(action.Receiver())
+		if err != nil {
+			response.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		response.Results[i] = common.MakeActionResult(receiverTag, action)
+	}
+	return response, nil
+}
+
+func (api *API) retention() ([]actions.RetentionRule, error) {
+	cfg, err := api.model.ModelConfig()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return cfg.ActionResultsRetention(), nil
+}
+
+// PrunableActions isn't on the v1 API.
+func (api *APIv1) PrunableActions(_, _ struct{}) {}
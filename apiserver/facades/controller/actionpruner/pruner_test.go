@@ -0,0 +1,112 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package actionpruner_test
+
+import (
+	"time"
+
+	"github.com/juju/clock/testclock"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/facades/controller/actionpruner"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing/factory"
+)
+
+type prunerSuite struct {
+	jujutesting.JujuConnSuite
+
+	clock *testclock.Clock
+	charm *state.Charm
+	api   *actionpruner.API
+}
+
+var _ = gc.Suite(&prunerSuite{})
+
+func (s *prunerSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.clock = testclock.NewClock(time.Now())
+	err := s.State.SetClockForTesting(s.clock)
+	c.Assert(err, jc.ErrorIsNil)
+	s.charm = s.AddTestingCharm(c, "dummy")
+	api, err := actionpruner.NewAPI(s.State, nil, apiservertesting.FakeAuthorizer{
+		Tag:        names.NewMachineTag("0"),
+		Controller: true,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	s.api = api
+}
+
+func (s *prunerSuite) addCompletedAction(c *gc.C, application string) state.Action {
+	unit := s.Factory.MakeUnit(c, &factory.UnitParams{
+		Application: s.Factory.MakeApplication(c, &factory.ApplicationParams{Name: application, Charm: s.charm}),
+	})
+	action, err := unit.AddAction("snapshot", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = action.Begin()
+	c.Assert(err, jc.ErrorIsNil)
+	action, err = action.Finish(state.ActionResults{Status: state.ActionCompleted})
+	c.Assert(err, jc.ErrorIsNil)
+	return action
+}
+
+func (s *prunerSuite) TestPrunableActions(c *gc.C) {
+	pruned := s.addCompletedAction(c, "mysql")
+	s.addCompletedAction(c, "wordpress")
+	s.AssertConfigParameterUpdated(c, "action-results-retention", "wordpress:snapshot=0s")
+	s.clock.Advance(2 * time.Hour)
+
+	results, err := s.api.PrunableActions(params.PrunableActionsArgs{MaxHistoryTime: time.Hour})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	result := results.Results[0]
+	c.Assert(result.Error, gc.IsNil)
+	c.Check(result.Action.Tag, gc.Equals, pruned.ActionTag().String())
+	c.Check(result.Action.Receiver, gc.Equals, "unit-mysql-0")
+	c.Check(result.Status, gc.Equals, "completed")
+
+	err = s.api.Prune(params.ActionPruneArgs{MaxHistoryTime: time.Hour})
+	c.Assert(err, jc.ErrorIsNil)
+	all, err := s.Model.AllActions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(all, gc.HasLen, 1)
+	c.Check(all[0].Receiver(), gc.Equals, "wordpress/0")
+}
+
+func (s *prunerSuite) TestPrunableActionsPaged(c *gc.C) {
+	first := s.addCompletedAction(c, "mysql")
+	s.clock.Advance(time.Minute)
+	second := s.addCompletedAction(c, "wordpress")
+	s.clock.Advance(2 * time.Hour)
+
+	results, err := s.api.PrunableActions(params.PrunableActionsArgs{MaxHistoryTime: time.Hour, Limit: 1})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	c.Check(results.Results[0].Action.Tag, gc.Equals, first.ActionTag().String())
+
+	results, err = s.api.PrunableActions(params.PrunableActionsArgs{MaxHistoryTime: time.Hour, Offset: 1, Limit: 1})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	c.Check(results.Results[0].Action.Tag, gc.Equals, second.ActionTag().String())
+
+	results, err = s.api.PrunableActions(params.PrunableActionsArgs{MaxHistoryTime: time.Hour, Offset: 2, Limit: 1})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 0)
+}
+
+func (s *prunerSuite) TestPrunableActionsRequiresController(c *gc.C) {
+	api, err := actionpruner.NewAPI(s.State, nil, apiservertesting.FakeAuthorizer{
+		Tag: names.NewUserTag("admin"),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = api.PrunableActions(params.PrunableActionsArgs{MaxHistoryTime: time.Hour})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	err = api.Prune(params.ActionPruneArgs{MaxHistoryTime: time.Hour})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
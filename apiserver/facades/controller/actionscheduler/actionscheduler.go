@@ -0,0 +1,146 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package actionscheduler implements the API used by the action
+// scheduler worker, which queues actions as their schedules fall due,
+// and advances operations as their actions finish.
+package actionscheduler
+
+import (
+	"time"
+
+	"github.com/juju/clock"
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/facade"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/watcher"
+)
+
+var logger = loggo.GetLogger("juju.apiserver.actionscheduler")
+
+// API implements the API used by the action scheduler worker.
+type API struct {
+	backend   Backend
+	resources facade.Resources
+	clock     clock.Clock
+}
+
+// NewFacade creates a new instance of the ActionScheduler API.
+func NewFacade(
+	st *state.State,
+	resources facade.Resources,
+	authorizer facade.Authorizer,
+) (*API, error) {
+	if !authorizer.AuthController() {
+		return nil, common.ErrPerm
+	}
+	m, err := st.Model()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return newAPI(backendShim{st, m}, resources, clock.WallClock), nil
+}
+
+func newAPI(backend Backend, resources facade.Resources, clock clock.Clock) *API {
+	return &API{
+		backend:   backend,
+		resources: resources,
+		clock:     clock,
+	}
+}
+
+// WatchActionSchedules returns a watcher that notifies when action
+// schedules are added, changed or removed.
+func (api *API) WatchActionSchedules() (params.NotifyWatchResult, error) {
+	return api.watch(api.backend.WatchActionSchedules())
+}
+
+// WatchOperations returns a watcher that notifies when operations are
+// added or change, including when their actions finish.
+func (api *API) WatchOperations() (params.NotifyWatchResult, error) {
+	return api.watch(api.backend.WatchOperations())
+}
+
+func (api *API) watch(watch state.NotifyWatcher) (params.NotifyWatchResult, error) {
+	if _, ok := <-watch.Changes(); ok {
+		return params.NotifyWatchResult{
+			NotifyWatcherId: api.resources.Register(watch),
+		}, nil
+	}
+	return params.NotifyWatchResult{
+		Error: common.ServerError(watcher.EnsureErr(watch)),
+	}, nil
+}
+
+// RunDueActionSchedules queues the actions of all the schedules that
+// are due to run, and returns the time at which a schedule is next due.
+// A schedule whose action can't be queued has the failure recorded in
+// its history, and isn't retried until its next run.
+func (api *API) RunDueActionSchedules() (params.ActionSchedulerRunResult, error) {
+	now := api.clock.Now()
+	schedules, err := api.backend.DueActionSchedules(now)
+	if err != nil {
+		return params.ActionSchedulerRunResult{Error: common.ServerError(err)}, nil
+	}
+	actionReceiver := common.ActionReceiverResolverFn(api.backend.FindEntity, api.backend.ApplicationLeaders)
+	for _, schedule := range schedules {
+		api.run(schedule, actionReceiver, now)
+	}
+	nextRun, err := api.backend.NextActionScheduleRun()
+	if err != nil {
+		return params.ActionSchedulerRunResult{Error: common.ServerError(err)}, nil
+	}
+	var result params.ActionSchedulerRunResult
+	if !nextRun.IsZero() {
+		result.NextRun = &nextRun
+	}
+	return result, nil
+}
+
+func (api *API) run(
+	schedule ActionSchedule,
+	actionReceiver func(string) (state.ActionReceiver, error),
+	now time.Time,
+) {
+	receiver, err := actionReceiver(schedule.Receiver())
+	if err == nil {
+		var action state.Action
+		if action, err = schedule.Run(receiver, now); err == nil {
+			logger.Debugf("action schedule %q queued action %q", schedule.Id(), action.Id())
+			return
+		}
+	}
+	logger.Warningf("action schedule %q failed to queue %q on %q: %v",
+		schedule.Id(), schedule.Name(), schedule.Receiver(), err)
+	if err := schedule.RecordFailure(now, err); err != nil {
+		// The schedule has most likely been removed, or run by
+		// another controller; either way there's nothing more to do.
+		logger.Warningf("%v", err)
+	}
+}
+
+// AdvanceOperations queues the actions of all the running operations
+// on as many further receivers as their parallelism allows, and
+// completes or stops the operations as appropriate. An operation that
+// can't be advanced is logged, and retried when it next changes.
+func (api *API) AdvanceOperations() (params.ErrorResult, error) {
+	ops, err := api.backend.RunningOperations()
+	if err != nil {
+		return params.ErrorResult{Error: common.ServerError(err)}, nil
+	}
+	for _, op := range ops {
+		actions, err := op.Advance()
+		if err != nil {
+			logger.Warningf("%v", err)
+			continue
+		}
+		for _, action := range actions {
+			logger.Debugf("operation %q queued action %q", op.Id(), action.Id())
+		}
+	}
+	return params.ErrorResult{}, nil
+}
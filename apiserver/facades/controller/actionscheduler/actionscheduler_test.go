@@ -0,0 +1,311 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package actionscheduler_test
+
+import (
+	"time"
+
+	"github.com/juju/clock/testclock"
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/facades/controller/actionscheduler"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type ActionSchedulerSuite struct {
+	coretesting.BaseSuite
+
+	backend   *mockBackend
+	resources *common.Resources
+	clock     *testclock.Clock
+	api       *actionscheduler.API
+}
+
+var _ = gc.Suite(&ActionSchedulerSuite{})
+
+func (s *ActionSchedulerSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.backend = &mockBackend{
+		Stub:    &testing.Stub{},
+		leaders: map[string]string{"mysql": "mysql/1"},
+	}
+	s.resources = common.NewResources()
+	s.AddCleanup(func(*gc.C) { s.resources.StopAll() })
+	s.clock = testclock.NewClock(time.Date(2018, 7, 1, 12, 0, 0, 0, time.UTC))
+	s.api = actionscheduler.NewAPI(s.backend, s.resources, s.clock)
+}
+
+func (s *ActionSchedulerSuite) TestNewFacadeRequiresController(c *gc.C) {
+	api, err := actionscheduler.NewFacade(nil, nil, apiservertesting.FakeAuthorizer{})
+	c.Assert(api, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	c.Assert(common.ServerError(err), jc.Satisfies, params.IsCodeUnauthorized)
+}
+
+func (s *ActionSchedulerSuite) TestWatchActionSchedules(c *gc.C) {
+	result, err := s.api.WatchActionSchedules()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Error, gc.IsNil)
+	c.Assert(result.NotifyWatcherId, gc.Equals, "1")
+	c.Assert(s.resources.Get("1"), gc.NotNil)
+	s.backend.CheckCallNames(c, "WatchActionSchedules")
+}
+
+func (s *ActionSchedulerSuite) TestWatchActionSchedulesFailure(c *gc.C) {
+	s.backend.watchFails = true
+	s.backend.SetErrors(errors.New("boom"))
+	result, err := s.api.WatchActionSchedules()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Error, gc.ErrorMatches, "boom")
+	c.Assert(s.resources.Count(), gc.Equals, 0)
+}
+
+func (s *ActionSchedulerSuite) TestRunDueActionSchedules(c *gc.C) {
+	nextRun := s.clock.Now().Add(time.Hour)
+	ok := newMockSchedule("1", "unit-mysql-0", nil)
+	leader := newMockSchedule("2", "mysql/leader", nil)
+	failed := newMockSchedule("3", "unit-mysql-0", errors.New("action \"snapshot\" not defined"))
+	missing := newMockSchedule("4", "unit-mysql-9", nil)
+	s.backend.due = []actionscheduler.ActionSchedule{ok, leader, failed, missing}
+	s.backend.nextRun = nextRun
+
+	result, err := s.api.RunDueActionSchedules()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ActionSchedulerRunResult{NextRun: &nextRun})
+
+	s.backend.CheckCalls(c, []testing.StubCall{
+		{"DueActionSchedules", []interface{}{s.clock.Now()}},
+		{"FindEntity", []interface{}{names.NewUnitTag("mysql/0")}},
+		{"ApplicationLeaders", nil},
+		{"FindEntity", []interface{}{names.NewUnitTag("mysql/1")}},
+		{"FindEntity", []interface{}{names.NewUnitTag("mysql/0")}},
+		{"FindEntity", []interface{}{names.NewUnitTag("mysql/9")}},
+		{"NextActionScheduleRun", nil},
+	})
+
+	ok.CheckCallNames(c, "Run")
+	ok.CheckCall(c, 0, "Run", names.NewUnitTag("mysql/0"), s.clock.Now())
+	leader.CheckCall(c, 0, "Run", names.NewUnitTag("mysql/1"), s.clock.Now())
+
+	failed.CheckCallNames(c, "Run", "RecordFailure")
+	failed.CheckCall(c, 1, "RecordFailure", s.clock.Now(), `action "snapshot" not defined`)
+
+	missing.CheckCallNames(c, "RecordFailure")
+	missing.CheckCall(c, 0, "RecordFailure", s.clock.Now(), "unit-mysql-9 not found")
+}
+
+func (s *ActionSchedulerSuite) TestRunDueActionSchedulesNoNextRun(c *gc.C) {
+	result, err := s.api.RunDueActionSchedules()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ActionSchedulerRunResult{})
+}
+
+func (s *ActionSchedulerSuite) TestRunDueActionSchedulesError(c *gc.C) {
+	s.backend.SetErrors(errors.New("boom"))
+	result, err := s.api.RunDueActionSchedules()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Error, gc.ErrorMatches, "boom")
+	s.backend.CheckCallNames(c, "DueActionSchedules")
+}
+
+func (s *ActionSchedulerSuite) TestWatchOperations(c *gc.C) {
+	result, err := s.api.WatchOperations()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Error, gc.IsNil)
+	c.Assert(result.NotifyWatcherId, gc.Equals, "1")
+	c.Assert(s.resources.Get("1"), gc.NotNil)
+	s.backend.CheckCallNames(c, "WatchOperations")
+}
+
+func (s *ActionSchedulerSuite) TestAdvanceOperations(c *gc.C) {
+	ok := newMockOperation("1", nil)
+	failed := newMockOperation("2", errors.New("boom"))
+	later := newMockOperation("3", nil)
+	s.backend.running = []actionscheduler.Operation{ok, failed, later}
+
+	result, err := s.api.AdvanceOperations()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Error, gc.IsNil)
+	s.backend.CheckCallNames(c, "RunningOperations")
+	ok.CheckCallNames(c, "Advance")
+	failed.CheckCallNames(c, "Advance")
+	later.CheckCallNames(c, "Advance")
+}
+
+func (s *ActionSchedulerSuite) TestAdvanceOperationsError(c *gc.C) {
+	s.backend.SetErrors(errors.New("boom"))
+	result, err := s.api.AdvanceOperations()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Error, gc.ErrorMatches, "boom")
+}
+
+type mockBackend struct {
+	*testing.Stub
+	watchFails bool
+	due        []actionscheduler.ActionSchedule
+	nextRun    time.Time
+	leaders    map[string]string
+	running    []actionscheduler.Operation
+}
+
+func (b *mockBackend) WatchActionSchedules() state.NotifyWatcher {
+	b.MethodCall(b, "WatchActionSchedules")
+	return b.newWatcher()
+}
+
+func (b *mockBackend) WatchOperations() state.NotifyWatcher {
+	b.MethodCall(b, "WatchOperations")
+	return b.newWatcher()
+}
+
+func (b *mockBackend) newWatcher() state.NotifyWatcher {
+	w := &mockWatcher{
+		changes: make(chan struct{}, 1),
+		err:     b.NextErr(),
+	}
+	if b.watchFails {
+		close(w.changes)
+	} else {
+		w.changes <- struct{}{}
+	}
+	return w
+}
+
+func (b *mockBackend) DueActionSchedules(now time.Time) ([]actionscheduler.ActionSchedule, error) {
+	b.MethodCall(b, "DueActionSchedules", now)
+	if err := b.NextErr(); err != nil {
+		return nil, err
+	}
+	return b.due, nil
+}
+
+func (b *mockBackend) NextActionScheduleRun() (time.Time, error) {
+	b.MethodCall(b, "NextActionScheduleRun")
+	return b.nextRun, b.NextErr()
+}
+
+func (b *mockBackend) FindEntity(tag names.Tag) (state.Entity, error) {
+	b.MethodCall(b, "FindEntity", tag)
+	if tag.Id() == "mysql/9" {
+		return nil, errors.NotFoundf("unit %q", tag.Id())
+	}
+	return &mockReceiver{tag: tag}, b.NextErr()
+}
+
+func (b *mockBackend) ApplicationLeaders() (map[string]string, error) {
+	b.MethodCall(b, "ApplicationLeaders")
+	return b.leaders, b.NextErr()
+}
+
+func (b *mockBackend) RunningOperations() ([]actionscheduler.Operation, error) {
+	b.MethodCall(b, "RunningOperations")
+	if err := b.NextErr(); err != nil {
+		return nil, err
+	}
+	return b.running, nil
+}
+
+type mockWatcher struct {
+	state.NotifyWatcher
+	changes chan struct{}
+	err     error
+}
+
+func (w *mockWatcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+func (w *mockWatcher) Stop() error {
+	return nil
+}
+
+func (w *mockWatcher) Err() error {
+	return w.err
+}
+
+type mockSchedule struct {
+	testing.Stub
+	id       string
+	receiver string
+}
+
+func newMockSchedule(id, receiver string, runErr error) *mockSchedule {
+	s := &mockSchedule{id: id, receiver: receiver}
+	s.SetErrors(runErr)
+	return s
+}
+
+func (s *mockSchedule) Id() string {
+	return s.id
+}
+
+func (s *mockSchedule) Receiver() string {
+	return s.receiver
+}
+
+func (s *mockSchedule) Name() string {
+	return "snapshot"
+}
+
+func (s *mockSchedule) Run(receiver state.ActionReceiver, now time.Time) (state.Action, error) {
+	s.MethodCall(s, "Run", receiver.Tag(), now)
+	if err := s.NextErr(); err != nil {
+		return nil, err
+	}
+	return &mockAction{id: s.id + "-action"}, nil
+}
+
+func (s *mockSchedule) RecordFailure(now time.Time, reason error) error {
+	s.MethodCall(s, "RecordFailure", now, reason.Error())
+	return s.NextErr()
+}
+
+type mockReceiver struct {
+	state.ActionReceiver
+	tag names.Tag
+}
+
+func (r *mockReceiver) Tag() names.Tag {
+	return r.tag
+}
+
+type mockAction struct {
+	state.Action
+	id string
+}
+
+func (a *mockAction) Id() string {
+	return a.id
+}
+
+type mockOperation struct {
+	testing.Stub
+	id string
+}
+
+func newMockOperation(id string, advanceErr error) *mockOperation {
+	op := &mockOperation{id: id}
+	op.SetErrors(advanceErr)
+	return op
+}
+
+func (op *mockOperation) Id() string {
+	return op.id
+}
+
+func (op *mockOperation) Advance() ([]state.Action, error) {
+	op.MethodCall(op, "Advance")
+	if err := op.NextErr(); err != nil {
+		return nil, err
+	}
+	return []state.Action{&mockAction{id: op.id + "-action"}}, nil
+}
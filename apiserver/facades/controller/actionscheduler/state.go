@@ -0,0 +1,82 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package actionscheduler
+
+import (
+	"time"
+
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/state"
+)
+
+// Backend provides the state methods used by the action scheduler
+// facade.
+type Backend interface {
+	WatchActionSchedules() state.NotifyWatcher
+	DueActionSchedules(now time.Time) ([]ActionSchedule, error)
+	NextActionScheduleRun() (time.Time, error)
+	FindEntity(names.Tag) (state.Entity, error)
+	ApplicationLeaders() (map[string]string, error)
+	WatchOperations() state.NotifyWatcher
+	RunningOperations() ([]Operation, error)
+}
+
+// ActionSchedule provides the methods of a state.ActionSchedule used
+// by the action scheduler facade.
+type ActionSchedule interface {
+	Id() string
+	Receiver() string
+	Name() string
+	Run(receiver state.ActionReceiver, now time.Time) (state.Action, error)
+	RecordFailure(now time.Time, reason error) error
+}
+
+// Operation provides the methods of a state.Operation used by the
+// action scheduler facade.
+type Operation interface {
+	Id() string
+	Advance() ([]state.Action, error)
+}
+
+type backendShim struct {
+	*state.State
+	model *state.Model
+}
+
+func (b backendShim) WatchActionSchedules() state.NotifyWatcher {
+	return b.model.WatchActionSchedules()
+}
+
+func (b backendShim) DueActionSchedules(now time.Time) ([]ActionSchedule, error) {
+	schedules, err := b.model.DueActionSchedules(now)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]ActionSchedule, len(schedules))
+	for i, schedule := range schedules {
+		result[i] = schedule
+	}
+	return result, nil
+}
+
+func (b backendShim) NextActionScheduleRun() (time.Time, error) {
+	return b.model.NextActionScheduleRun()
+}
+
+func (b backendShim) WatchOperations() state.NotifyWatcher {
+	return b.model.WatchOperations()
+}
+
+func (b backendShim) RunningOperations() ([]Operation, error) {
+	ops, err := b.model.RunningOperations()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Operation, len(ops))
+	for i, op := range ops {
+		result[i] = op
+	}
+	return result, nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"net/http"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/logsink"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/logdb"
+)
+
+type importLoggingStrategy struct {
+	dbloggers *dbloggers
+
+	dblogger *logdb.BufferedLogger
+	releaser func()
+}
+
+// importingDbLogger writes log records to the database, skipping
+// those already recorded so that importing an archive more than once,
+// or archives that overlap, does not duplicate records.
+type importingDbLogger struct {
+	*state.DbLogger
+}
+
+// Log is part of the logdb.Logger interface.
+func (l importingDbLogger) Log(records []state.LogRecord) error {
+	return errors.Trace(l.ImportLog(records))
+}
+
+// newImportLogWriteCloserFunc returns a function that will create a
+// logsink.LoggingStrategy given an *http.Request, that writes log
+// messages previously exported from a model (possibly on another
+// controller) to the state database of the request's model.
+func newImportLogWriteCloserFunc(ctxt httpContext, dbloggers *dbloggers) logsink.NewLogWriteCloserFunc {
+	return func(req *http.Request) (logsink.LogWriteCloser, error) {
+		strategy := &importLoggingStrategy{dbloggers: dbloggers}
+		if err := strategy.init(ctxt, req); err != nil {
+			return nil, errors.Annotate(err, "initialising log import session")
+		}
+		return strategy, nil
+	}
+}
+
+func (s *importLoggingStrategy) init(ctxt httpContext, req *http.Request) error {
+	st, err := ctxt.stateForRequestAuthenticatedUser(req)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	// As with migrated logs, the version number provided should be
+	// the Juju version of the client that exported the logs.
+	_, err = logsink.JujuClientVersionFromRequest(req)
+	if err != nil {
+		st.Release()
+		return errors.Trace(err)
+	}
+
+	// Imported records are checked against those already in the
+	// database, so they don't share the agents' buffered loggers.
+	dbl := state.NewDbLogger(st.State)
+	s.dblogger = logdb.NewBufferedLogger(
+		importingDbLogger{dbl},
+		s.dbloggers.dbLoggerBufferSize,
+		s.dbloggers.dbLoggerFlushInterval,
+		s.dbloggers.clock,
+	)
+	s.releaser = func() {
+		dbl.Close()
+		if removed := st.Release(); removed {
+			s.dbloggers.remove(st.State)
+		}
+	}
+	return nil
+}
+
+// Close is part of the logsink.LogWriteCloser interface.
+func (s *importLoggingStrategy) Close() error {
+	err := s.dblogger.Flush()
+	s.releaser()
+	return errors.Annotate(err, "logging to DB failed")
+}
+
+// WriteLog is part of the logsink.LogWriteCloser interface.
+func (s *importLoggingStrategy) WriteLog(m params.LogRecord) error {
+	level, _ := loggo.ParseLevel(m.Level)
+	var entity names.Tag
+	if m.Entity != "" {
+		var err error
+		entity, err = names.ParseTag(m.Entity)
+		if err != nil {
+			return errors.Annotate(err, "parsing entity from log record")
+		}
+	}
+	err := s.dblogger.Log([]state.LogRecord{{
+		Time:     m.Time,
+		Entity:   entity,
+		Module:   m.Module,
+		Location: m.Location,
+		Level:    level,
+		Message:  m.Message,
+	}})
+	return errors.Annotate(err, "logging to DB failed")
+}
@@ -0,0 +1,163 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver_test
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/juju/loggo"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/names.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/apiserver/websocket/websockettest"
+	"github.com/juju/juju/permission"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/testing/factory"
+	"github.com/juju/juju/version"
+)
+
+type logimportSuite struct {
+	apiserverBaseSuite
+	userTag  names.UserTag
+	password string
+	url      string
+}
+
+var _ = gc.Suite(&logimportSuite{})
+
+func (s *logimportSuite) SetUpTest(c *gc.C) {
+	s.apiserverBaseSuite.SetUpTest(c)
+	s.password = "jabberwocky"
+	u := s.Factory.MakeUser(c, &factory.UserParams{Password: s.password})
+	s.userTag = u.Tag().(names.UserTag)
+	s.setUserAccess(c, permission.SuperuserAccess)
+
+	url := s.URL("/model/"+s.State.ModelUUID()+"/logimport", url.Values{
+		"jujuclientversion": {version.Current.String()},
+	})
+	url.Scheme = "wss"
+	s.url = url.String()
+}
+
+func (s *logimportSuite) makeAuthHeader() http.Header {
+	return utils.BasicAuthHeader(s.userTag.String(), s.password)
+}
+
+func (s *logimportSuite) setUserAccess(c *gc.C, level permission.Access) {
+	_, err := s.State.SetUserAccess(s.userTag, s.State.ControllerTag(), level)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *logimportSuite) TestRequiresSuperUser(c *gc.C) {
+	s.setUserAccess(c, permission.LoginAccess)
+	_, resp, err := dialWebsocketFromURL(c, s.url, s.makeAuthHeader())
+	c.Assert(err, gc.Equals, websocket.ErrBadHandshake)
+	defer resp.Body.Close()
+	c.Assert(resp.StatusCode, gc.Equals, http.StatusForbidden)
+}
+
+func (s *logimportSuite) TestRejectsInvalidVersion(c *gc.C) {
+	url := s.URL("/model/"+s.State.ModelUUID()+"/logimport", url.Values{"jujuclientversion": {"blah"}})
+	url.Scheme = "wss"
+	conn, _, err := dialWebsocketFromURL(c, url.String(), s.makeAuthHeader())
+	c.Assert(err, jc.ErrorIsNil)
+	defer conn.Close()
+	websockettest.AssertJSONError(c, conn, `^initialising log import session: invalid jujuclientversion "blah".*`)
+	websockettest.AssertWebsocketClosed(c, conn)
+}
+
+func (s *logimportSuite) TestImport(c *gc.C) {
+	conn, _, err := dialWebsocketFromURL(c, s.url, s.makeAuthHeader())
+	c.Assert(err, jc.ErrorIsNil)
+	defer conn.Close()
+
+	// Read back the nil error, indicating that all is well.
+	websockettest.AssertJSONInitialErrorNil(c, conn)
+
+	t0 := time.Date(2015, time.June, 1, 23, 2, 1, 0, time.UTC)
+	err = conn.WriteJSON(&params.LogRecord{
+		Entity:   "unit-mysql-0",
+		Time:     t0,
+		Module:   "some.where",
+		Location: "foo.go:42",
+		Level:    loggo.WARNING.String(),
+		Message:  "from the archive",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Wait for the log document to be written to the DB.
+	logsColl := s.State.MongoSession().DB("logs").C("logs." + s.State.ModelUUID())
+	var docs []bson.M
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		err := logsColl.Find(bson.M{"x": "from the archive"}).All(&docs)
+		c.Assert(err, jc.ErrorIsNil)
+		if len(docs) == 1 {
+			break
+		}
+		if !a.HasNext() {
+			c.Fatalf("timed out waiting for log writes")
+		}
+	}
+	c.Assert(docs[0]["t"], gc.Equals, t0.UnixNano())
+	c.Assert(docs[0]["n"], gc.Equals, "unit-mysql-0")
+	c.Assert(docs[0]["m"], gc.Equals, "some.where")
+	c.Assert(docs[0]["l"], gc.Equals, "foo.go:42")
+	c.Assert(docs[0]["v"], gc.Equals, int(loggo.WARNING))
+}
+
+func (s *logimportSuite) TestImportSkipsExistingRecords(c *gc.C) {
+	t0 := time.Date(2015, time.June, 1, 23, 2, 1, 0, time.UTC)
+	record := params.LogRecord{
+		Entity:   "unit-mysql-0",
+		Time:     t0,
+		Module:   "some.where",
+		Location: "foo.go:42",
+		Level:    loggo.WARNING.String(),
+		Message:  "from the archive",
+	}
+	marker := record
+	marker.Time = t0.Add(time.Second)
+	marker.Message = "marker"
+
+	s.importRecords(c, record)
+	s.waitForMessage(c, record.Message)
+	// Importing an overlapping archive adds only the new records.
+	s.importRecords(c, record, marker)
+	s.waitForMessage(c, marker.Message)
+
+	logsColl := s.State.MongoSession().DB("logs").C("logs." + s.State.ModelUUID())
+	count, err := logsColl.Find(bson.M{"x": record.Message}).Count()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(count, gc.Equals, 1)
+}
+
+func (s *logimportSuite) importRecords(c *gc.C, records ...params.LogRecord) {
+	conn, _, err := dialWebsocketFromURL(c, s.url, s.makeAuthHeader())
+	c.Assert(err, jc.ErrorIsNil)
+	defer conn.Close()
+	websockettest.AssertJSONInitialErrorNil(c, conn)
+	for _, record := range records {
+		err := conn.WriteJSON(&record)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+}
+
+func (s *logimportSuite) waitForMessage(c *gc.C, message string) {
+	logsColl := s.State.MongoSession().DB("logs").C("logs." + s.State.ModelUUID())
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		count, err := logsColl.Find(bson.M{"x": message}).Count()
+		c.Assert(err, jc.ErrorIsNil)
+		if count > 0 {
+			return
+		}
+	}
+	c.Fatalf("timed out waiting for %q to be logged", message)
+}
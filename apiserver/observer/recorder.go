@@ -41,6 +41,14 @@ func NewRecorderFactory(
 	}
 }
 
+// redactor is implemented by API arguments holding secrets, such as
+// passphrases, that mustn't be recorded in the audit log.
+type redactor interface {
+	// Redacted returns a copy of the arguments with the secrets
+	// replaced.
+	Redacted() interface{}
+}
+
 // combinedRecorder wraps an observer (which might be a multiplexer)
 // up with an auditlog recorder into an rpc.Recorder.
 type combinedRecorder struct {
@@ -57,6 +65,9 @@ func (cr *combinedRecorder) HandleRequest(hdr *rpc.Header, body interface{}) err
 	}
 	var args string
 	if cr.captureArgs {
+		if r, ok := body.(redactor); ok {
+			body = r.Redacted()
+		}
 		jsonArgs, err := json.Marshal(body)
 		if err != nil {
 			return errors.Trace(err)
@@ -97,6 +97,43 @@ func (s *recorderSuite) TestServerRequestNoArgs(c *gc.C) {
 	})
 }
 
+func (s *recorderSuite) TestServerRequestRedactsSecrets(c *gc.C) {
+	s.checkServerRequestArgs(c,
+		params.BackupsCreateArgs{Notes: "nightly", Passphrase: "secret"},
+		`{"notes":"nightly","keep-copy":false,"no-download":false,"passphrase":"\u003credacted\u003e"}`,
+	)
+	s.checkServerRequestArgs(c,
+		params.RestoreArgs{BackupId: "an_id", Passphrase: "secret", PrivateKey: "key"},
+		`{"backup-id":"an_id","passphrase":"\u003credacted\u003e","private-key":"\u003credacted\u003e"}`,
+	)
+	s.checkServerRequestArgs(c,
+		params.RestoreArgs{BackupId: "an_id"},
+		`{"backup-id":"an_id"}`,
+	)
+}
+
+func (s *recorderSuite) checkServerRequestArgs(c *gc.C, body interface{}, expected string) {
+	fake := &fakeobserver.Instance{}
+	log := &apitesting.FakeAuditLog{}
+	clock := testclock.NewClock(time.Now())
+	auditRecorder, err := auditlog.NewRecorder(log, clock, auditlog.ConversationArgs{
+		ConnectionID: 4567,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	factory := observer.NewRecorderFactory(fake, auditRecorder, observer.CaptureArgs)
+	recorder := factory()
+	hdr := &rpc.Header{
+		RequestId: 123,
+		Request:   rpc.Request{"Backups", 4, "", "Create"},
+	}
+	err = recorder.HandleRequest(hdr, body)
+	c.Assert(err, jc.ErrorIsNil)
+
+	log.CheckCallNames(c, "AddConversation", "AddRequest")
+	request := log.Calls()[1].Args[0].(auditlog.Request)
+	c.Assert(request.Args, gc.Equals, expected)
+}
+
 func (s *recorderSuite) TestServerReply(c *gc.C) {
 	fake := &fakeobserver.Instance{}
 	log := &apitesting.FakeAuditLog{}
@@ -54,9 +54,16 @@ type ActionResult struct {
 	Status    string                 `json:"status,omitempty"`
 	Message   string                 `json:"message,omitempty"`
 	Output    map[string]interface{} `json:"output,omitempty"`
+	Log       []ActionMessage        `json:"log,omitempty"`
 	Error     *Error                 `json:"error,omitempty"`
 }
 
+// ActionMessage represents a progress message logged by an action.
+type ActionMessage struct {
+	Timestamp time.Time `json:"timestamp"`
+	Message   string    `json:"message"`
+}
+
 // ActionsByReceivers wrap a slice of Actions for API calls.
 type ActionsByReceivers struct {
 	Actions []ActionsByReceiver `json:"actions,omitempty"`
@@ -116,6 +123,12 @@ type ActionExecutionResult struct {
 	Message   string                 `json:"message,omitempty"`
 }
 
+// ActionMessageParams holds the arguments for logging progress
+// messages for some actions.
+type ActionMessageParams struct {
+	Messages []EntityString `json:"messages"`
+}
+
 // ApplicationsCharmActionsResults holds a slice of ApplicationCharmActionsResult for
 // a bulk result of charm Actions for Applications.
 type ApplicationsCharmActionsResults struct {
@@ -143,3 +156,117 @@ type ActionPruneArgs struct {
 	MaxHistoryTime time.Duration `json:"max-history-time"`
 	MaxHistoryMB   int           `json:"max-history-mb"`
 }
+
+// PrunableActionsArgs holds the arguments for reading a page of the
+// actions that would be pruned by age.
+type PrunableActionsArgs struct {
+	MaxHistoryTime time.Duration `json:"max-history-time"`
+	Offset         int           `json:"offset"`
+	Limit          int           `json:"limit"`
+}
+
+// ActionSchedules holds the schedules to add for bulk requests.
+type ActionSchedules struct {
+	Schedules []ActionSchedule `json:"schedules"`
+}
+
+// ActionSchedule describes an action that is queued at a given time,
+// or repeatedly on a cron-style schedule. Exactly one of At and
+// Schedule is set when adding a schedule.
+type ActionSchedule struct {
+	Id         string                 `json:"id,omitempty"`
+	Receiver   string                 `json:"receiver"`
+	Name       string                 `json:"name"`
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+	At         *time.Time             `json:"at,omitempty"`
+	Schedule   string                 `json:"schedule,omitempty"`
+	Created    *time.Time             `json:"created,omitempty"`
+	NextRun    *time.Time             `json:"next-run,omitempty"`
+	Runs       []ActionScheduleRun    `json:"runs,omitempty"`
+}
+
+// ActionScheduleRun records a single run of an action schedule, and
+// the action it queued or the reason it couldn't queue one.
+type ActionScheduleRun struct {
+	Time   time.Time `json:"time"`
+	Action string    `json:"action,omitempty"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// ActionScheduleResults holds the results of bulk action schedule
+// requests.
+type ActionScheduleResults struct {
+	Results []ActionScheduleResult `json:"results,omitempty"`
+}
+
+// ActionScheduleResult holds an action schedule or an error.
+type ActionScheduleResult struct {
+	Schedule *ActionSchedule `json:"schedule,omitempty"`
+	Error    *Error          `json:"error,omitempty"`
+}
+
+// ActionScheduleIds holds the IDs of action schedules.
+type ActionScheduleIds struct {
+	Ids []string `json:"ids"`
+}
+
+// ActionSchedulerRunResult holds the result of running the due
+// action schedules in a model.
+type ActionSchedulerRunResult struct {
+	// NextRun is the time at which a schedule is next due to run,
+	// or nil if there are no more runs scheduled.
+	NextRun *time.Time `json:"next-run,omitempty"`
+	Error   *Error     `json:"error,omitempty"`
+}
+
+// OperationArgs describes an action to queue on a set of receivers in
+// batches, no more than MaxParallel at a time, stopping once
+// MaxFailures of the actions have failed. Zero values mean no limit.
+type OperationArgs struct {
+	Receivers   []string               `json:"receivers"`
+	Name        string                 `json:"name"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+	MaxParallel int                    `json:"max-parallel,omitempty"`
+	MaxFailures int                    `json:"max-failures,omitempty"`
+}
+
+// Operation describes the progress of an action queued on a set of
+// receivers in batches.
+type Operation struct {
+	Id          string                 `json:"id"`
+	Name        string                 `json:"name"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+	Receivers   []string               `json:"receivers"`
+	MaxParallel int                    `json:"max-parallel,omitempty"`
+	MaxFailures int                    `json:"max-failures,omitempty"`
+	Status      string                 `json:"status"`
+	Completed   int                    `json:"completed"`
+	Failed      int                    `json:"failed"`
+	Created     time.Time              `json:"created"`
+	Finished    *time.Time             `json:"finished,omitempty"`
+	Actions     []OperationAction      `json:"actions,omitempty"`
+}
+
+// OperationAction records the action queued on one receiver of an
+// operation, or the reason the receiver was skipped.
+type OperationAction struct {
+	Receiver string `json:"receiver"`
+	Action   string `json:"action,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// OperationResult holds an operation or an error.
+type OperationResult struct {
+	Operation *Operation `json:"operation,omitempty"`
+	Error     *Error     `json:"error,omitempty"`
+}
+
+// OperationResults holds the results of bulk operation requests.
+type OperationResults struct {
+	Results []OperationResult `json:"results,omitempty"`
+}
+
+// OperationIds holds the IDs of operations.
+type OperationIds struct {
+	Ids []string `json:"ids"`
+}
@@ -14,6 +14,29 @@ type BackupsCreateArgs struct {
 	Notes      string `json:"notes"`
 	KeepCopy   bool   `json:"keep-copy"`
 	NoDownload bool   `json:"no-download"`
+
+	// Base is the ID of the full backup to create an incremental
+	// backup of. It's only supported by version 3 of the facade.
+	Base string `json:"base,omitempty"`
+
+	// Passphrase or PublicKey, an armored OpenPGP public key, is
+	// used to encrypt the backup archive. They're only supported by
+	// version 4 of the facade.
+	Passphrase string `json:"passphrase,omitempty"`
+	PublicKey  string `json:"public-key,omitempty"`
+}
+
+// redactedSecret replaces secrets in API arguments recorded in the
+// audit log.
+const redactedSecret = "<redacted>"
+
+// Redacted returns a copy of the args, with the passphrase replaced so
+// it can be recorded in the audit log.
+func (args BackupsCreateArgs) Redacted() interface{} {
+	if args.Passphrase != "" {
+		args.Passphrase = redactedSecret
+	}
+	return args
 }
 
 // BackupsInfoArgs holds the args for the API Info method.
@@ -44,6 +67,22 @@ type BackupsRemoveArgs struct {
 // BackupsListResult holds the list of all stored backups.
 type BackupsListResult struct {
 	List []BackupsMetadataResult `json:"list"`
+
+	// Schedule is the controller's backup schedule, if backups are
+	// scheduled, and NextScheduled is when the next is due.
+	Schedule      string     `json:"schedule,omitempty"`
+	NextScheduled *time.Time `json:"next-scheduled,omitempty"`
+
+	// CopyDir is the directory on the controller machines that
+	// scheduled backups are copied to, if any.
+	CopyDir string `json:"copy-dir,omitempty"`
+
+	// LastScheduled is when the last scheduled backup started, if
+	// there has been one. LastScheduledID is the ID of the backup it
+	// made, and LastScheduledError why it failed, if it did.
+	LastScheduled      *time.Time `json:"last-scheduled,omitempty"`
+	LastScheduledID    string     `json:"last-scheduled-id,omitempty"`
+	LastScheduledError string     `json:"last-scheduled-error,omitempty"`
 }
 
 // BackupsListResult holds the list of all stored backups.
@@ -70,6 +109,19 @@ type BackupsMetadataResult struct {
 	Version  version.Number `json:"version"`
 	Series   string         `json:"series"`
 
+	Scheduled bool `json:"scheduled,omitempty"`
+
+	// Base is the ID of the full backup an incremental backup builds
+	// on, and OplogStart and OplogEnd bound the changes it holds.
+	Base       string    `json:"base,omitempty"`
+	OplogStart time.Time `json:"oplog-start,omitempty"`
+	OplogEnd   time.Time `json:"oplog-end,omitempty"`
+
+	// Encrypted records whether the archive is encrypted, and
+	// KeyFingerprint identifies the public key it was encrypted to.
+	Encrypted      bool   `json:"encrypted,omitempty"`
+	KeyFingerprint string `json:"key-fingerprint,omitempty"`
+
 	CACert       string `json:"ca-cert"`
 	CAPrivateKey string `json:"ca-private-key"`
 	Filename     string `json:"filename"`
@@ -79,4 +131,26 @@ type BackupsMetadataResult struct {
 type RestoreArgs struct {
 	// BackupId holds the id of the backup in server if any
 	BackupId string `json:"backup-id"`
+
+	// ToTime, if set, is the time to restore the backup to, by
+	// replaying the changes in its incremental backups.
+	ToTime *time.Time `json:"to-time,omitempty"`
+
+	// Passphrase or PrivateKey, an armored OpenPGP private key that
+	// Passphrase unlocks if it's protected, decrypts an encrypted
+	// backup. They're only supported by version 4 of the facade.
+	Passphrase string `json:"passphrase,omitempty"`
+	PrivateKey string `json:"private-key,omitempty"`
+}
+
+// Redacted returns a copy of the args, with the passphrase and private
+// key replaced so they can be recorded in the audit log.
+func (args RestoreArgs) Redacted() interface{} {
+	if args.Passphrase != "" {
+		args.Passphrase = redactedSecret
+	}
+	if args.PrivateKey != "" {
+		args.PrivateKey = redactedSecret
+	}
+	return args
 }
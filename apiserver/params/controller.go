@@ -3,6 +3,8 @@
 
 package params
 
+import "time"
+
 // DestroyControllerArgs holds the arguments for destroying a controller.
 type DestroyControllerArgs struct {
 	// DestroyModels specifies whether or not the hosted models
@@ -104,3 +106,58 @@ const (
 	GrantControllerAccess  ControllerAction = "grant"
 	RevokeControllerAccess ControllerAction = "revoke"
 )
+
+// AuditLogQueryArgs holds the filter used to select conversations
+// from the audit log in Controller.AuditLog. Empty fields match
+// everything.
+type AuditLogQueryArgs struct {
+	Who            string     `json:"who,omitempty"`
+	Model          string     `json:"model,omitempty"`
+	Facade         string     `json:"facade,omitempty"`
+	Method         string     `json:"method,omitempty"`
+	ConversationID string     `json:"conversation-id,omitempty"`
+	After          *time.Time `json:"after,omitempty"`
+	Before         *time.Time `json:"before,omitempty"`
+	Limit          int        `json:"limit,omitempty"`
+}
+
+// AuditLogResults holds the audit log conversations recorded by a
+// single controller machine.
+type AuditLogResults struct {
+	ControllerMachine string              `json:"controller-machine"`
+	Conversations     []AuditConversation `json:"conversations"`
+}
+
+// AuditConversation describes a conversation recorded in the audit
+// log, with the requests made as part of it.
+type AuditConversation struct {
+	Who            string         `json:"who"`
+	What           string         `json:"what"`
+	When           time.Time      `json:"when"`
+	ModelName      string         `json:"model-name"`
+	ModelUUID      string         `json:"model-uuid"`
+	ConversationID string         `json:"conversation-id"`
+	ConnectionID   string         `json:"connection-id"`
+	Requests       []AuditRequest `json:"requests,omitempty"`
+}
+
+// AuditRequest describes an API request recorded in the audit log,
+// along with any errors returned.
+type AuditRequest struct {
+	RequestID uint64       `json:"request-id"`
+	When      time.Time    `json:"when"`
+	Facade    string       `json:"facade"`
+	Method    string       `json:"method"`
+	Version   int          `json:"version"`
+	Args      string       `json:"args,omitempty"`
+	Path      string       `json:"path,omitempty"`
+	Size      int64        `json:"size,omitempty"`
+	Errors    []AuditError `json:"errors,omitempty"`
+}
+
+// AuditError holds an error returned in response to an audited
+// request.
+type AuditError struct {
+	Message string `json:"message"`
+	Code    string `json:"code"`
+}
@@ -643,12 +643,16 @@ type CharmsResponse struct {
 // RunParams is used to provide the parameters to the Run method.
 // Commands and Timeout are expected to have values, and one or more
 // values should be in the Machines, Applications, or Units slices.
+// MaxParallel and MaxFailures are only used when the commands are run
+// as an operation; see OperationArgs.
 type RunParams struct {
 	Commands     string        `json:"commands"`
 	Timeout      time.Duration `json:"timeout"`
 	Machines     []string      `json:"machines,omitempty"`
 	Applications []string      `json:"applications,omitempty"`
 	Units        []string      `json:"units,omitempty"`
+	MaxParallel  int           `json:"max-parallel,omitempty"`
+	MaxFailures  int           `json:"max-failures,omitempty"`
 }
 
 // RunResult contains the result from an individual run call on a machine.
@@ -0,0 +1,62 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+import "time"
+
+// AdvanceGenerationResult holds the results of advancing units and
+// applications to the model's next generation.
+type AdvanceGenerationResult struct {
+	// AdvanceResults holds a result for each unit or application
+	// advanced, in the order they were given.
+	AdvanceResults []ErrorResult `json:"advance-results"`
+
+	// CompleteResult indicates whether the generation was completed
+	// as a result of the units advanced.
+	CompleteResult BoolResult `json:"complete-result"`
+}
+
+// GenerationConfigChange describes a charm config setting changed in
+// a generation, with its current value and the value in the generation.
+// A nil Next value indicates that the setting is reset to its default.
+type GenerationConfigChange struct {
+	Key     string      `json:"key"`
+	Current interface{} `json:"current,omitempty"`
+	Next    interface{} `json:"next,omitempty"`
+}
+
+// GenerationApplication describes an application with changes in a
+// generation. CharmURL is set if the application's charm was upgraded
+// in the generation, and Resources holds the names of the resources
+// attached to it in the generation.
+type GenerationApplication struct {
+	ApplicationName string                   `json:"application"`
+	UnitsAssigned   []string                 `json:"units"`
+	UnitCount       int                      `json:"unit-count"`
+	ConfigChanges   []GenerationConfigChange `json:"config,omitempty"`
+	CharmURL        string                   `json:"charm-url,omitempty"`
+	Resources       []string                 `json:"resources,omitempty"`
+}
+
+// Generation describes a model generation.
+type Generation struct {
+	Id           string                  `json:"id"`
+	Active       bool                    `json:"active"`
+	Created      time.Time               `json:"created"`
+	Completed    *time.Time              `json:"completed,omitempty"`
+	Cancelled    bool                    `json:"cancelled"`
+	Applications []GenerationApplication `json:"applications,omitempty"`
+}
+
+// GenerationResult holds a generation, or an error.
+type GenerationResult struct {
+	Generation Generation `json:"generation"`
+	Error      *Error     `json:"error,omitempty"`
+}
+
+// GenerationResults holds all of a model's generations, in the order
+// they were added.
+type GenerationResults struct {
+	Generations []Generation `json:"generations"`
+}
@@ -16,6 +16,11 @@ import (
 // StatusParams holds parameters for the Status call.
 type StatusParams struct {
 	Patterns []string `json:"patterns"`
+
+	// Filters holds filter expressions, as parsed by
+	// status.ParseFilter; the status includes the entities that
+	// satisfy any of them.
+	Filters []string `json:"filters,omitempty"`
 }
 
 // TODO(ericsnow) Add FullStatusResult.
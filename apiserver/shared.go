@@ -10,6 +10,7 @@ import (
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
 
+	"github.com/juju/juju/core/cache"
 	"github.com/juju/juju/core/lease"
 	"github.com/juju/juju/core/presence"
 	"github.com/juju/juju/feature"
@@ -38,6 +39,7 @@ type sharedServerContext struct {
 	centralHub   SharedHub
 	presence     presence.Recorder
 	leaseManager lease.Manager
+	controller   *cache.Controller
 	logger       loggo.Logger
 
 	featuresMutex sync.RWMutex
@@ -51,6 +53,7 @@ type sharedServerConfig struct {
 	centralHub   SharedHub
 	presence     presence.Recorder
 	leaseManager lease.Manager
+	controller   *cache.Controller
 	logger       loggo.Logger
 }
 
@@ -79,6 +82,7 @@ func newSharedServerContex(config sharedServerConfig) (*sharedServerContext, err
 		centralHub:   config.centralHub,
 		presence:     config.presence,
 		leaseManager: config.leaseManager,
+		controller:   config.controller,
 		logger:       config.logger,
 	}
 	controllerConfig, err := ctx.statePool.SystemState().ControllerConfig()
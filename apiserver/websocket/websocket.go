@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gorilla/websocket"
 	"github.com/juju/errors"
@@ -97,3 +98,28 @@ func (conn *Conn) SendInitialErrorV0(err error) error {
 
 	return errors.Trace(err)
 }
+
+// maxCloseReasonLength is the longest reason a close message can
+// carry; control frames hold at most 125 bytes, two of which are the
+// close code.
+const maxCloseReasonLength = 123
+
+// SendEnd writes a close message marking the end of a stream, so that
+// the client can tell a stream that ended cleanly from one that was
+// cut short. A nil error is sent as a normal closure; otherwise the
+// close message carries the error.
+func (conn *Conn) SendEnd(err error) error {
+	code, reason := websocket.CloseNormalClosure, ""
+	if err != nil {
+		code, reason = websocket.CloseInternalServerErr, err.Error()
+		if len(reason) > maxCloseReasonLength {
+			reason = reason[:maxCloseReasonLength]
+			// Don't split a multi-byte character.
+			for !utf8.ValidString(reason) {
+				reason = reason[:len(reason)-1]
+			}
+		}
+	}
+	msg := websocket.FormatCloseMessage(code, reason)
+	return errors.Trace(conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(WriteWait)))
+}
@@ -4,6 +4,9 @@
 package caas
 
 import (
+	"strings"
+
+	"github.com/juju/collections/set"
 	"github.com/juju/errors"
 	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 )
@@ -51,6 +54,10 @@ type ContainerSpec struct {
 	Config map[string]interface{} `yaml:"config,omitempty"`
 	Files  []FileSet              `yaml:"files,omitempty"`
 
+	// Init containers are run to completion, one at a time, before
+	// any of the other containers are started.
+	Init bool `yaml:"init,omitempty"`
+
 	// ProviderContainer defines config which is specific to a substrate, eg k8s
 	ProviderContainer `yaml:"-"`
 }
@@ -61,6 +68,98 @@ type PodSpec struct {
 	Containers                []ContainerSpec            `yaml:"-"`
 	OmitServiceFrontend       bool                       `yaml:"omitServiceFrontend"`
 	CustomResourceDefinitions []CustomResourceDefinition `yaml:"customResourceDefinition,omitempty"`
+	ServiceAccount            *ServiceAccountSpec        `yaml:"serviceAccount,omitempty"`
+	CustomResources           []CustomResource           `yaml:"-"`
+	Secrets                   []Secret                   `yaml:"secrets,omitempty"`
+	DeploymentType            DeploymentType             `yaml:"deploymentType,omitempty"`
+}
+
+// DeploymentType defines how the substrate runs an application's pods.
+// If it isn't set, pods are stateful when the application has storage
+// and stateless otherwise. Charm metadata has no field for it, so it's
+// declared by the charm in its pod spec. Each pod is reported as a unit
+// of the application, whatever the deployment type.
+type DeploymentType string
+
+const (
+	// DeploymentStateless runs a pod for each unit, with no identity
+	// or storage kept between restarts.
+	DeploymentStateless DeploymentType = "stateless"
+
+	// DeploymentStateful runs a pod for each unit, each with a stable
+	// identity and storage.
+	DeploymentStateful DeploymentType = "stateful"
+
+	// DeploymentDaemon runs a pod on every node, regardless of the
+	// number of units requested, so there's a unit for each node.
+	DeploymentDaemon DeploymentType = "daemon"
+
+	// DeploymentJob runs a pod for each unit until it completes.
+	// The job's pods can't be changed once it's created, and are
+	// kept as units after they complete. The number of pods run to
+	// completion is the number of units when the job is created;
+	// scaling the application afterwards only changes how many of
+	// them run at once.
+	DeploymentJob DeploymentType = "job"
+)
+
+// Validate returns an error if the deployment type is not valid.
+func (dt DeploymentType) Validate() error {
+	switch dt {
+	case "", DeploymentStateless, DeploymentStateful, DeploymentDaemon, DeploymentJob:
+		return nil
+	}
+	return errors.NotValidf("deployment type %q", dt)
+}
+
+// PolicyRule defines a set of requests to the substrate's API
+// which a service account is allowed to make.
+type PolicyRule struct {
+	Verbs           []string `yaml:"verbs"`
+	APIGroups       []string `yaml:"apiGroups,omitempty"`
+	Resources       []string `yaml:"resources,omitempty"`
+	ResourceNames   []string `yaml:"resourceNames,omitempty"`
+	NonResourceURLs []string `yaml:"nonResourceURLs,omitempty"`
+}
+
+// Validate returns an error if the rule is not valid.
+func (r *PolicyRule) Validate() error {
+	if len(r.Verbs) == 0 {
+		return errors.NotValidf("rule with missing verbs")
+	}
+	if len(r.Resources) == 0 && len(r.NonResourceURLs) == 0 {
+		return errors.NotValidf("rule with missing resources or non resource URLs")
+	}
+	return nil
+}
+
+// ServiceAccountSpec defines the service account the application's
+// pods run as, and the access to the substrate's API granted to it.
+type ServiceAccountSpec struct {
+	AutomountServiceAccountToken *bool `yaml:"automountServiceAccountToken,omitempty"`
+	// Rules are granted within the model.
+	Rules []PolicyRule `yaml:"rules,omitempty"`
+	// ClusterRules are granted across the whole cluster, so they're
+	// only granted to trusted applications.
+	ClusterRules []PolicyRule `yaml:"clusterRules,omitempty"`
+}
+
+// Validate returns an error if the service account spec is not valid.
+func (sa *ServiceAccountSpec) Validate() error {
+	for _, r := range sa.Rules {
+		if err := r.Validate(); err != nil {
+			return errors.Trace(err)
+		}
+		if len(r.NonResourceURLs) > 0 {
+			return errors.NotValidf("non resource URLs in rules granted within the model")
+		}
+	}
+	for _, r := range sa.ClusterRules {
+		if err := r.Validate(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
 }
 
 // CustomResourceDefinitionValidation defines the custom resource definition validation schema.
@@ -94,8 +193,80 @@ func (crd *CustomResourceDefinition) Validate() error {
 	return nil
 }
 
+// CustomResource defines an instance of a custom resource, usually one
+// whose definition is also in the pod spec. The resource is named for
+// the application, as "<application>-<name>".
+type CustomResource struct {
+	// APIVersion is the group and version of the resource's
+	// definition, eg "example.com/v1".
+	APIVersion string `yaml:"apiVersion" json:"apiVersion"`
+	Kind       string `yaml:"kind" json:"kind"`
+	// Plural is the name of the resource's kind in the substrate's
+	// API. It defaults to the lower case kind with an "s" appended,
+	// as for the definitions in pod specs.
+	Plural string                 `yaml:"plural,omitempty" json:"plural,omitempty"`
+	Name   string                 `yaml:"name" json:"name"`
+	Spec   map[string]interface{} `yaml:"spec,omitempty" json:"spec,omitempty"`
+}
+
+// Validate returns an error if the custom resource is not valid.
+func (cr *CustomResource) Validate() error {
+	if cr.Name == "" {
+		return errors.NotValidf("custom resource with missing name")
+	}
+	if cr.Kind == "" {
+		return errors.NotValidf("custom resource %q with missing kind", cr.Name)
+	}
+	if parts := strings.Split(cr.APIVersion, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return errors.NotValidf("custom resource %q api version %q", cr.Name, cr.APIVersion)
+	}
+	return nil
+}
+
+// Secret defines a secret made available to the application's pods.
+// The secret is named for the application, as "<application>-<name>".
+// Its data is supplied each time the charm sets the pod spec, either
+// directly or taken from the charm's config or relation data.
+type Secret struct {
+	Name string            `yaml:"name"`
+	Type string            `yaml:"type,omitempty"`
+	Data map[string]string `yaml:"data,omitempty"`
+	// ConfigData maps data keys to the charm config options whose
+	// values are used as the data.
+	ConfigData map[string]string `yaml:"configData,omitempty"`
+	// RelationData maps data keys to relation settings, written as
+	// "<endpoint>:<setting>", whose values are used as the data.
+	RelationData map[string]string `yaml:"relationData,omitempty"`
+}
+
+// Validate returns an error if the secret is not valid.
+func (s *Secret) Validate() error {
+	if s.Name == "" {
+		return errors.NotValidf("secret with missing name")
+	}
+	for key := range s.Data {
+		if key == "" {
+			return errors.NotValidf("secret %q with empty data key", s.Name)
+		}
+	}
+	for key, option := range s.ConfigData {
+		if key == "" || option == "" {
+			return errors.NotValidf("secret %q config data %q: %q", s.Name, key, option)
+		}
+	}
+	for key, setting := range s.RelationData {
+		if _, _, err := parseRelationSetting(setting); key == "" || err != nil {
+			return errors.NotValidf("secret %q relation data %q: %q", s.Name, key, setting)
+		}
+	}
+	return nil
+}
+
 // Validate returns an error if the spec is not valid.
 func (spec *PodSpec) Validate() error {
+	if err := spec.DeploymentType.Validate(); err != nil {
+		return errors.Trace(err)
+	}
 	for _, c := range spec.Containers {
 		if err := c.Validate(); err != nil {
 			return errors.Trace(err)
@@ -106,6 +277,26 @@ func (spec *PodSpec) Validate() error {
 			return errors.Trace(err)
 		}
 	}
+	if spec.ServiceAccount != nil {
+		if err := spec.ServiceAccount.Validate(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	for _, cr := range spec.CustomResources {
+		if err := cr.Validate(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	secretNames := set.NewStrings()
+	for _, s := range spec.Secrets {
+		if err := s.Validate(); err != nil {
+			return errors.Trace(err)
+		}
+		if secretNames.Contains(s.Name) {
+			return errors.NotValidf("duplicate secret name %q", s.Name)
+		}
+		secretNames.Add(s.Name)
+	}
 	return nil
 }
 
@@ -16,6 +16,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	watch "k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 
@@ -42,8 +43,11 @@ type BaseSuite struct {
 	mockApps                   *mocks.MockAppsV1Interface
 	mockExtensions             *mocks.MockExtensionsV1beta1Interface
 	mockSecrets                *mocks.MockSecretInterface
+	mockEvents                 *mocks.MockEventInterface
 	mockDeployments            *mocks.MockDeploymentInterface
 	mockStatefulSets           *mocks.MockStatefulSetInterface
+	mockDaemonSets             *mocks.MockDaemonSetInterface
+	mockJobs                   *mocks.MockJobInterface
 	mockPods                   *mocks.MockPodInterface
 	mockServices               *mocks.MockServiceInterface
 	mockConfigMaps             *mocks.MockConfigMapInterface
@@ -52,11 +56,20 @@ type BaseSuite struct {
 	mockStorage                *mocks.MockStorageV1Interface
 	mockStorageClass           *mocks.MockStorageClassInterface
 	mockIngressInterface       *mocks.MockIngressInterface
+	mockServiceAccounts        *mocks.MockServiceAccountInterface
+	mockRoles                  *mocks.MockRoleInterface
+	mockRoleBindings           *mocks.MockRoleBindingInterface
+	mockClusterRoles           *mocks.MockClusterRoleInterface
+	mockClusterRoleBindings    *mocks.MockClusterRoleBindingInterface
 
 	mockApiextensionsV1          *mocks.MockApiextensionsV1beta1Interface
 	mockApiextensionsClient      *mocks.MockApiExtensionsClientInterface
 	mockCustomResourceDefinition *mocks.MockCustomResourceDefinitionInterface
 
+	mockDynamicClient               *mocks.MockDynamicInterface
+	mockNamespaceableResourceClient *mocks.MockNamespaceableResourceInterface
+	mockResourceClient              *mocks.MockResourceInterface
+
 	watcher *provider.KubernetesWatcher
 }
 
@@ -111,6 +124,12 @@ func (s *BaseSuite) setupBroker(c *gc.C) *gomock.Controller {
 	s.mockSecrets = mocks.NewMockSecretInterface(ctrl)
 	mockCoreV1.EXPECT().Secrets(testNamespace).AnyTimes().Return(s.mockSecrets)
 
+	s.mockEvents = mocks.NewMockEventInterface(ctrl)
+	mockCoreV1.EXPECT().Events(testNamespace).AnyTimes().Return(s.mockEvents)
+
+	s.mockServiceAccounts = mocks.NewMockServiceAccountInterface(ctrl)
+	mockCoreV1.EXPECT().ServiceAccounts(testNamespace).AnyTimes().Return(s.mockServiceAccounts)
+
 	s.mockApps = mocks.NewMockAppsV1Interface(ctrl)
 	s.mockExtensions = mocks.NewMockExtensionsV1beta1Interface(ctrl)
 	s.mockStatefulSets = mocks.NewMockStatefulSetInterface(ctrl)
@@ -120,6 +139,14 @@ func (s *BaseSuite) setupBroker(c *gc.C) *gomock.Controller {
 	s.k8sClient.EXPECT().AppsV1().AnyTimes().Return(s.mockApps)
 	s.mockApps.EXPECT().StatefulSets(testNamespace).AnyTimes().Return(s.mockStatefulSets)
 	s.mockApps.EXPECT().Deployments(testNamespace).AnyTimes().Return(s.mockDeployments)
+
+	s.mockDaemonSets = mocks.NewMockDaemonSetInterface(ctrl)
+	s.mockApps.EXPECT().DaemonSets(testNamespace).AnyTimes().Return(s.mockDaemonSets)
+
+	mockBatchV1 := mocks.NewMockBatchV1Interface(ctrl)
+	s.mockJobs = mocks.NewMockJobInterface(ctrl)
+	s.k8sClient.EXPECT().BatchV1().AnyTimes().Return(mockBatchV1)
+	mockBatchV1.EXPECT().Jobs(testNamespace).AnyTimes().Return(s.mockJobs)
 	s.mockExtensions.EXPECT().Ingresses(testNamespace).AnyTimes().Return(s.mockIngressInterface)
 
 	s.mockStorage = mocks.NewMockStorageV1Interface(ctrl)
@@ -127,14 +154,32 @@ func (s *BaseSuite) setupBroker(c *gc.C) *gomock.Controller {
 	s.k8sClient.EXPECT().StorageV1().AnyTimes().Return(s.mockStorage)
 	s.mockStorage.EXPECT().StorageClasses().AnyTimes().Return(s.mockStorageClass)
 
+	mockRbacV1 := mocks.NewMockRbacV1Interface(ctrl)
+	s.mockRoles = mocks.NewMockRoleInterface(ctrl)
+	s.mockRoleBindings = mocks.NewMockRoleBindingInterface(ctrl)
+	s.mockClusterRoles = mocks.NewMockClusterRoleInterface(ctrl)
+	s.mockClusterRoleBindings = mocks.NewMockClusterRoleBindingInterface(ctrl)
+	s.k8sClient.EXPECT().RbacV1().AnyTimes().Return(mockRbacV1)
+	mockRbacV1.EXPECT().Roles(testNamespace).AnyTimes().Return(s.mockRoles)
+	mockRbacV1.EXPECT().RoleBindings(testNamespace).AnyTimes().Return(s.mockRoleBindings)
+	mockRbacV1.EXPECT().ClusterRoles().AnyTimes().Return(s.mockClusterRoles)
+	mockRbacV1.EXPECT().ClusterRoleBindings().AnyTimes().Return(s.mockClusterRoleBindings)
+
 	s.mockApiextensionsClient = mocks.NewMockApiExtensionsClientInterface(ctrl)
 	s.mockApiextensionsV1 = mocks.NewMockApiextensionsV1beta1Interface(ctrl)
 	s.mockCustomResourceDefinition = mocks.NewMockCustomResourceDefinitionInterface(ctrl)
 	s.mockApiextensionsClient.EXPECT().ApiextensionsV1beta1().AnyTimes().Return(s.mockApiextensionsV1)
 	s.mockApiextensionsV1.EXPECT().CustomResourceDefinitions().AnyTimes().Return(s.mockCustomResourceDefinition)
 
+	// The resources asked of the dynamic client depend on the test, so
+	// only the namespace is wired up here.
+	s.mockDynamicClient = mocks.NewMockDynamicInterface(ctrl)
+	s.mockNamespaceableResourceClient = mocks.NewMockNamespaceableResourceInterface(ctrl)
+	s.mockResourceClient = mocks.NewMockResourceInterface(ctrl)
+	s.mockNamespaceableResourceClient.EXPECT().Namespace(testNamespace).AnyTimes().Return(s.mockResourceClient)
+
 	// Set up the mock k8sClient we pass to our broker under test.
-	newClient := func(cfg *rest.Config) (kubernetes.Interface, apiextensionsclientset.Interface, error) {
+	newClient := func(cfg *rest.Config) (kubernetes.Interface, apiextensionsclientset.Interface, dynamic.Interface, error) {
 		c.Assert(cfg.Username, gc.Equals, "fred")
 		c.Assert(cfg.Password, gc.Equals, "secret")
 		c.Assert(cfg.Host, gc.Equals, "some-host")
@@ -143,7 +188,7 @@ func (s *BaseSuite) setupBroker(c *gc.C) *gomock.Controller {
 			KeyData:  []byte("cert-key"),
 			CAData:   []byte(testing.CACert),
 		})
-		return s.k8sClient, s.mockApiextensionsClient, nil
+		return s.k8sClient, s.mockApiextensionsClient, s.mockDynamicClient, nil
 	}
 
 	s.clock = testclock.NewClock(time.Time{})
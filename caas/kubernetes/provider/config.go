@@ -27,6 +27,9 @@ const (
 	ingressSSLRedirectKey    = "kubernetes-ingress-ssl-redirect"
 	ingressSSLPassthroughKey = "kubernetes-ingress-ssl-passthrough"
 	ingressAllowHTTPKey      = "kubernetes-ingress-allow-http"
+
+	// trustConfigKey is the application config set by "juju trust".
+	trustConfigKey = "trust"
 )
 
 var configFields = environschema.Fields{
@@ -8,7 +8,10 @@ import (
 
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 
+	"github.com/juju/juju/caas/kubernetes/provider"
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/environs/context"
 )
@@ -87,3 +90,57 @@ func (s *ConstraintsSuite) TestConstraintsValidatorUnsupported(c *gc.C) {
 	}
 	c.Check(unsupported, jc.SameContents, expected)
 }
+
+func (s *ConstraintsSuite) TestMergeConstraint(c *gc.C) {
+	resources := core.ResourceRequirements{}
+	err := provider.MergeConstraint("memory", "64Mi", &resources)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(resources, jc.DeepEquals, core.ResourceRequirements{
+		Limits: core.ResourceList{"memory": resource.MustParse("64Mi")},
+	})
+}
+
+func (s *ConstraintsSuite) TestMergeConstraintLowersLimit(c *gc.C) {
+	// The charm's limit and request are both more than the constraint
+	// allows, so they're lowered to it.
+	resources := core.ResourceRequirements{
+		Limits:   core.ResourceList{"memory": resource.MustParse("256Mi")},
+		Requests: core.ResourceList{"memory": resource.MustParse("128Mi")},
+	}
+	err := provider.MergeConstraint("memory", "64Mi", &resources)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(resources, jc.DeepEquals, core.ResourceRequirements{
+		Limits:   core.ResourceList{"memory": resource.MustParse("64Mi")},
+		Requests: core.ResourceList{"memory": resource.MustParse("64Mi")},
+	})
+}
+
+func (s *ConstraintsSuite) TestMergeConstraintLowersLimitKeepsRequest(c *gc.C) {
+	resources := core.ResourceRequirements{
+		Limits:   core.ResourceList{"cpu": resource.MustParse("2")},
+		Requests: core.ResourceList{"cpu": resource.MustParse("250m")},
+	}
+	err := provider.MergeConstraint("cpu", "500m", &resources)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(resources, jc.DeepEquals, core.ResourceRequirements{
+		Limits:   core.ResourceList{"cpu": resource.MustParse("500m")},
+		Requests: core.ResourceList{"cpu": resource.MustParse("250m")},
+	})
+}
+
+func (s *ConstraintsSuite) TestMergeConstraintKeepsLowerLimit(c *gc.C) {
+	resources := core.ResourceRequirements{
+		Limits: core.ResourceList{"memory": resource.MustParse("32Mi")},
+	}
+	err := provider.MergeConstraint("memory", "64Mi", &resources)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(resources, jc.DeepEquals, core.ResourceRequirements{
+		Limits: core.ResourceList{"memory": resource.MustParse("32Mi")},
+	})
+}
+
+func (s *ConstraintsSuite) TestMergeConstraintInvalid(c *gc.C) {
+	resources := core.ResourceRequirements{}
+	err := provider.MergeConstraint("memory", "lots", &resources)
+	c.Assert(err, gc.ErrorMatches, `invalid constraint value "lots" for memory: .*`)
+}
@@ -0,0 +1,207 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package provider
+
+import (
+	"strings"
+
+	"github.com/juju/collections/set"
+	"github.com/juju/errors"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/juju/juju/caas"
+)
+
+// ensureCustomResources creates or updates the custom resources in the
+// pod spec, named for the application. Only the definitions the spec
+// names are consulted: other custom resources of those kinds labelled
+// with the application are deleted, while those of kinds no longer in
+// the spec are left until the application is removed.
+func (k *kubernetesClient) ensureCustomResources(appName string, resources []caas.CustomResource, labels map[string]string) error {
+	definitions := k.apiextensionsClient.ApiextensionsV1beta1().CustomResourceDefinitions()
+	crds := make(map[string]*apiextensionsv1beta1.CustomResourceDefinition)
+	var crdNames []string
+	// Check that every resource has a definition before changing anything.
+	for _, r := range resources {
+		name := customResourceDefinitionName(r)
+		crd, ok := crds[name]
+		if !ok {
+			var err error
+			crd, err = definitions.Get(name, v1.GetOptions{})
+			if k8serrors.IsNotFound(err) {
+				return errors.NotFoundf("custom resource definition for %s %q", r.Kind, r.Name)
+			} else if err != nil {
+				return errors.Annotatef(err, "getting custom resource definition %q", name)
+			}
+			crds[name] = crd
+			crdNames = append(crdNames, name)
+		}
+		if _, ok := customResourceVersion(*crd, r); !ok || crd.Spec.Scope != apiextensionsv1beta1.NamespaceScoped {
+			return errors.NotFoundf("custom resource definition for %s %q", r.Kind, r.Name)
+		}
+	}
+
+	options := &v1.DeleteOptions{
+		PropagationPolicy: &defaultPropagationPolicy,
+	}
+	for _, crdName := range crdNames {
+		crd := *crds[crdName]
+		wanted := set.NewStrings()
+		for _, r := range resources {
+			if customResourceDefinitionName(r) != crdName {
+				continue
+			}
+			version, _ := customResourceVersion(crd, r)
+			api := k.customResourceClient(crd, version)
+			obj := customResourceObject(appName, r, k.namespace, labels)
+			if err := ensureCustomResource(api, appName, obj); err != nil {
+				return errors.Annotatef(err, "creating or updating %s %q", r.Kind, r.Name)
+			}
+			wanted.Add(obj.GetName())
+		}
+
+		api := k.customResourceClient(crd, crd.Spec.Version)
+		existing, err := api.List(v1.ListOptions{LabelSelector: applicationSelector(appName)})
+		if err != nil {
+			return errors.Annotatef(err, "listing %s", crd.Spec.Names.Plural)
+		}
+		for _, item := range existing.Items {
+			if wanted.Contains(item.GetName()) {
+				continue
+			}
+			err := api.Delete(item.GetName(), options)
+			if err != nil && !k8serrors.IsNotFound(err) {
+				return errors.Annotatef(err, "deleting %s %q", crd.Spec.Names.Kind, item.GetName())
+			}
+		}
+	}
+	return nil
+}
+
+// ensureCustomResource creates the custom resource, or updates it if it
+// already exists. A resource which isn't labelled with the application
+// isn't the application's to update.
+func ensureCustomResource(api dynamic.ResourceInterface, appName string, obj *unstructured.Unstructured) error {
+	existing, err := api.Get(obj.GetName(), v1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		_, err = api.Create(obj, v1.CreateOptions{})
+		return errors.Trace(err)
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if existing.GetLabels()[labelApplication] != appName {
+		return errors.Errorf("%s %q already exists and isn't managed by %s", obj.GetKind(), obj.GetName(), appName)
+	}
+	obj.SetResourceVersion(existing.GetResourceVersion())
+	_, err = api.Update(obj, v1.UpdateOptions{})
+	return errors.Trace(err)
+}
+
+// deleteCustomResources deletes the custom resources labelled with the
+// application.
+func (k *kubernetesClient) deleteCustomResources(appName string) error {
+	crds, err := k.namespacedCustomResourceDefinitions()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	options := &v1.DeleteOptions{
+		PropagationPolicy: &defaultPropagationPolicy,
+	}
+	for _, crd := range crds {
+		api := k.customResourceClient(crd, crd.Spec.Version)
+		err := api.DeleteCollection(options, v1.ListOptions{LabelSelector: applicationSelector(appName)})
+		switch {
+		case err == nil, k8serrors.IsNotFound(err):
+		case k8serrors.IsForbidden(err), k8serrors.IsMethodNotSupported(err):
+			// Juju can't have made any resources of this kind.
+			logger.Debugf("not deleting %s for %s: %v", crd.Spec.Names.Plural, appName, err)
+		default:
+			return errors.Annotatef(err, "deleting %s", crd.Spec.Names.Plural)
+		}
+	}
+	return nil
+}
+
+// namespacedCustomResourceDefinitions returns the custom resource
+// definitions whose resources live in a namespace.
+func (k *kubernetesClient) namespacedCustomResourceDefinitions() ([]apiextensionsv1beta1.CustomResourceDefinition, error) {
+	list, err := k.apiextensionsClient.ApiextensionsV1beta1().CustomResourceDefinitions().List(v1.ListOptions{})
+	if err != nil {
+		return nil, errors.Annotate(err, "listing custom resource definitions")
+	}
+	var crds []apiextensionsv1beta1.CustomResourceDefinition
+	for _, crd := range list.Items {
+		if crd.Spec.Scope == apiextensionsv1beta1.NamespaceScoped {
+			crds = append(crds, crd)
+		}
+	}
+	return crds, nil
+}
+
+func (k *kubernetesClient) customResourceClient(crd apiextensionsv1beta1.CustomResourceDefinition, version string) dynamic.ResourceInterface {
+	return k.dynamicClient.Resource(schema.GroupVersionResource{
+		Group:    crd.Spec.Group,
+		Version:  version,
+		Resource: crd.Spec.Names.Plural,
+	}).Namespace(k.namespace)
+}
+
+// customResourceVersion returns the version of the definition the
+// custom resource is an instance of, and whether it is one at all.
+func customResourceVersion(crd apiextensionsv1beta1.CustomResourceDefinition, r caas.CustomResource) (string, bool) {
+	if crd.Spec.Names.Kind != r.Kind {
+		return "", false
+	}
+	parts := strings.SplitN(r.APIVersion, "/", 2)
+	if len(parts) != 2 || parts[0] != crd.Spec.Group {
+		return "", false
+	}
+	version := parts[1]
+	if version == crd.Spec.Version {
+		return version, true
+	}
+	for _, v := range crd.Spec.Versions {
+		if v.Name == version && v.Served {
+			return version, true
+		}
+	}
+	return "", false
+}
+
+func customResourceObject(appName string, r caas.CustomResource, namespace string, labels map[string]string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": r.APIVersion,
+		"kind":       r.Kind,
+	}}
+	if r.Spec != nil {
+		obj.Object["spec"] = r.Spec
+	}
+	obj.SetName(appResourceName(appName, r.Name))
+	obj.SetNamespace(namespace)
+	obj.SetLabels(labels)
+	return obj
+}
+
+// customResourceDefinitionName returns the name of the definition the
+// custom resource is an instance of.
+func customResourceDefinitionName(r caas.CustomResource) string {
+	plural := r.Plural
+	if plural == "" {
+		plural = customResourcePlural(r.Kind)
+	}
+	group := strings.SplitN(r.APIVersion, "/", 2)[0]
+	return plural + "." + group
+}
+
+// customResourcePlural returns the plural name Juju gives resources of
+// the kind in the definitions it creates.
+func customResourcePlural(kind string) string {
+	return strings.ToLower(kind) + "s"
+}
@@ -19,6 +19,8 @@ var (
 	CreateDockerConfigJSON = createDockerConfigJSON
 	NewStorageConfig       = newStorageConfig
 	NewKubernetesWatcher   = newKubernetesWatcher
+	JobSpecHash            = jobSpecHash
+	MergeConstraint        = mergeConstraint
 )
 
 type KubernetesWatcher = kubernetesWatcher
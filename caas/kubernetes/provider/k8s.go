@@ -5,6 +5,9 @@ package provider
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"path/filepath"
 	"regexp"
@@ -15,12 +18,14 @@ import (
 	"time"
 
 	jujuclock "github.com/juju/clock"
+	"github.com/juju/collections/set"
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
 	"github.com/juju/utils/arch"
 	"github.com/juju/utils/keyvalues"
 	"gopkg.in/juju/names.v2"
 	apps "k8s.io/api/apps/v1"
+	batch "k8s.io/api/batch/v1"
 	core "k8s.io/api/core/v1"
 	"k8s.io/api/extensions/v1beta1"
 	k8sstorage "k8s.io/api/storage/v1"
@@ -33,6 +38,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 
@@ -60,9 +66,17 @@ const (
 	labelApplication = "juju-application"
 	labelModel       = "juju-model"
 
+	// annotationJobSpecHash records a hash of the parts of a job's
+	// spec which can't be changed once it's created.
+	annotationJobSpecHash = "juju-job-spec-hash"
+
 	defaultOperatorStorageClassName = "juju-operator-storage"
 
 	gpuAffinityNodeSelectorKey = "gpu"
+
+	// eventReasonUnhealthy is the reason given for the events
+	// recorded when a container fails a probe.
+	eventReasonUnhealthy = "Unhealthy"
 )
 
 var defaultPropagationPolicy = v1.DeletePropagationForeground
@@ -71,6 +85,7 @@ type kubernetesClient struct {
 	clock jujuclock.Clock
 	kubernetes.Interface
 	apiextensionsClient apiextensionsclientset.Interface
+	dynamicClient       dynamic.Interface
 
 	// namespace is the k8s namespace to use when
 	// creating k8s resources.
@@ -89,13 +104,16 @@ type kubernetesClient struct {
 // To regenerate the mocks for the kubernetes Client used by this broker,
 // run "go generate" from the package directory.
 //go:generate mockgen -package mocks -destination mocks/k8sclient_mock.go k8s.io/client-go/kubernetes Interface
-//go:generate mockgen -package mocks -destination mocks/appv1_mock.go k8s.io/client-go/kubernetes/typed/apps/v1 AppsV1Interface,DeploymentInterface,StatefulSetInterface
-//go:generate mockgen -package mocks -destination mocks/corev1_mock.go k8s.io/client-go/kubernetes/typed/core/v1 CoreV1Interface,NamespaceInterface,PodInterface,ServiceInterface,ConfigMapInterface,PersistentVolumeInterface,PersistentVolumeClaimInterface,SecretInterface
+//go:generate mockgen -package mocks -destination mocks/appv1_mock.go k8s.io/client-go/kubernetes/typed/apps/v1 AppsV1Interface,DaemonSetInterface,DeploymentInterface,StatefulSetInterface
+//go:generate mockgen -package mocks -destination mocks/batchv1_mock.go k8s.io/client-go/kubernetes/typed/batch/v1 BatchV1Interface,JobInterface
+//go:generate mockgen -package mocks -destination mocks/corev1_mock.go k8s.io/client-go/kubernetes/typed/core/v1 CoreV1Interface,NamespaceInterface,PodInterface,ServiceInterface,ConfigMapInterface,PersistentVolumeInterface,PersistentVolumeClaimInterface,SecretInterface,ServiceAccountInterface,EventInterface
 //go:generate mockgen -package mocks -destination mocks/extenstionsv1_mock.go k8s.io/client-go/kubernetes/typed/extensions/v1beta1 ExtensionsV1beta1Interface,IngressInterface
 //go:generate mockgen -package mocks -destination mocks/storagev1_mock.go k8s.io/client-go/kubernetes/typed/storage/v1 StorageV1Interface,StorageClassInterface
+//go:generate mockgen -package mocks -destination mocks/rbacv1_mock.go k8s.io/client-go/kubernetes/typed/rbac/v1 RbacV1Interface,ClusterRoleInterface,ClusterRoleBindingInterface,RoleInterface,RoleBindingInterface
+//go:generate mockgen -package mocks -destination mocks/dynamic_mock.go -mock_names Interface=MockDynamicInterface k8s.io/client-go/dynamic Interface,NamespaceableResourceInterface,ResourceInterface
 
 // NewK8sClientFunc defines a function which returns a k8s client based on the supplied config.
-type NewK8sClientFunc func(c *rest.Config) (kubernetes.Interface, apiextensionsclientset.Interface, error)
+type NewK8sClientFunc func(c *rest.Config) (kubernetes.Interface, apiextensionsclientset.Interface, dynamic.Interface, error)
 
 // NewK8sWatcherFunc defines a function which returns a k8s watcher based on the supplied config.
 type NewK8sWatcherFunc func(wi watch.Interface, name string, clock jujuclock.Clock) (*kubernetesWatcher, error)
@@ -112,7 +130,7 @@ func NewK8sBroker(
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	k8sClient, apiextensionsClient, err := newClient(k8sConfig)
+	k8sClient, apiextensionsClient, dynamicClient, err := newClient(k8sConfig)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -124,6 +142,7 @@ func NewK8sBroker(
 		clock:               clock,
 		Interface:           k8sClient,
 		apiextensionsClient: apiextensionsClient,
+		dynamicClient:       dynamicClient,
 		namespace:           newCfg.Name(),
 		envCfg:              newCfg,
 		modelUUID:           newCfg.UUID(),
@@ -394,6 +413,67 @@ func (k *kubernetesClient) deleteSecret(imageSecretName string) error {
 	return errors.Trace(err)
 }
 
+// ensureSecrets creates or updates the secrets in the pod spec, named
+// for the application. A secret which isn't labelled with the
+// application isn't the application's to update. Any other secrets
+// labelled with the application, apart from those used to pull its
+// container images, are deleted.
+func (k *kubernetesClient) ensureSecrets(appName string, spec *caas.PodSpec, resourceTags map[string]string) error {
+	secrets := k.CoreV1().Secrets(k.namespace)
+	wanted := set.NewStrings()
+	for _, c := range spec.Containers {
+		if c.ImageDetails.Password != "" {
+			wanted.Add(appSecretName(appName, c.Name))
+		}
+	}
+	for _, s := range spec.Secrets {
+		name := appResourceName(appName, s.Name)
+		newSecret := &core.Secret{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      name,
+				Namespace: k.namespace,
+				Labels:    resourceTags},
+			Type: core.SecretType(s.Type),
+			Data: make(map[string][]byte),
+		}
+		if newSecret.Type == "" {
+			newSecret.Type = core.SecretTypeOpaque
+		}
+		for key, value := range s.Data {
+			newSecret.Data[key] = []byte(value)
+		}
+		existing, err := secrets.Get(name, v1.GetOptions{IncludeUninitialized: true})
+		if k8serrors.IsNotFound(err) {
+			_, err = secrets.Create(newSecret)
+		} else if err == nil {
+			if existing.Labels[labelApplication] != appName {
+				return errors.Errorf("secret %q already exists and isn't managed by %s", name, appName)
+			}
+			_, err = secrets.Update(newSecret)
+		}
+		if err != nil {
+			return errors.Annotatef(err, "creating or updating secret %q", name)
+		}
+		wanted.Add(name)
+	}
+
+	secretList, err := secrets.List(v1.ListOptions{
+		LabelSelector: applicationSelector(appName),
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, s := range secretList.Items {
+		if wanted.Contains(s.Name) {
+			continue
+		}
+		if err := k.deleteSecret(s.Name); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
 // OperatorExists returns true if the operator for the specified
 // application exists.
 func (k *kubernetesClient) OperatorExists(appName string) (bool, error) {
@@ -802,6 +882,12 @@ func (k *kubernetesClient) DeleteService(appName string) (err error) {
 	if err := k.deleteDeployment(deploymentName); err != nil {
 		return errors.Trace(err)
 	}
+	if err := k.deleteDaemonSet(deploymentName); err != nil {
+		return errors.Trace(err)
+	}
+	if err := k.deleteJob(deploymentName); err != nil {
+		return errors.Trace(err)
+	}
 	pods := k.CoreV1().Pods(k.namespace)
 	podsList, err := pods.List(v1.ListOptions{
 		LabelSelector: applicationSelector(appName),
@@ -826,6 +912,12 @@ func (k *kubernetesClient) DeleteService(appName string) (err error) {
 			return errors.Trace(err)
 		}
 	}
+	if err := k.deleteServiceAccounts(appName); err != nil {
+		return errors.Trace(err)
+	}
+	if err := k.deleteCustomResources(appName); err != nil {
+		return errors.Trace(err)
+	}
 	return nil
 }
 
@@ -844,7 +936,7 @@ func (k *kubernetesClient) EnsureCustomResourceDefinition(appName string, podSpe
 func (k *kubernetesClient) ensureCustomResourceDefinitionTemplate(t *caas.CustomResourceDefinition) (
 	crd *apiextensionsv1beta1.CustomResourceDefinition, err error) {
 	singularName := strings.ToLower(t.Kind)
-	pluralName := fmt.Sprintf("%ss", singularName)
+	pluralName := customResourcePlural(t.Kind)
 	crdFullName := fmt.Sprintf("%s.%s", pluralName, t.Group)
 	crdIn := &apiextensionsv1beta1.CustomResourceDefinition{
 		ObjectMeta: v1.ObjectMeta{
@@ -904,6 +996,15 @@ func (k *kubernetesClient) EnsureService(
 	if params.PodSpec.OmitServiceFrontend && len(params.Filesystems) == 0 {
 		return errors.Errorf("kubernetes service is required when using storage")
 	}
+	if len(params.Filesystems) > 0 {
+		switch deploymentType := params.PodSpec.DeploymentType; deploymentType {
+		case caas.DeploymentStateless, caas.DeploymentDaemon, caas.DeploymentJob:
+			return errors.NotSupportedf("storage for %s deployments", deploymentType)
+		}
+	}
+	if sa := params.PodSpec.ServiceAccount; sa != nil && len(sa.ClusterRules) > 0 && !config.GetBool(trustConfigKey, false) {
+		return errors.Errorf("cluster rules for %s need the application to be trusted; run \"juju trust %s\"", appName, appName)
+	}
 
 	var cleanups []func()
 	defer func() {
@@ -957,33 +1058,82 @@ func (k *kubernetesClient) EnsureService(
 		}
 		cleanups = append(cleanups, func() { k.deleteSecret(imageSecretName) })
 	}
+	if sa := params.PodSpec.ServiceAccount; sa != nil {
+		saCleanups, err := k.ensureServiceAccount(appName, sa, resourceTags)
+		cleanups = append(cleanups, saCleanups...)
+		if err != nil {
+			return errors.Annotatef(err, "creating service account for %s", appName)
+		}
+	}
+	if err := k.ensureSecrets(appName, params.PodSpec, resourceTags); err != nil {
+		return errors.Annotatef(err, "creating secrets for %s", appName)
+	}
+	if err := k.ensureCustomResources(appName, params.PodSpec.CustomResources, resourceTags); err != nil {
+		return errors.Annotatef(err, "creating custom resources for %s", appName)
+	}
 
-	// Add a deployment controller or stateful set configured to create the specified number of units/pods.
-	// Defensively check to see if a stateful set is already used.
-	useStatefulSet := len(params.Filesystems) > 0
-	if !useStatefulSet {
-		statefulsets := k.AppsV1().StatefulSets(k.namespace)
-		_, err := statefulsets.Get(deploymentName(appName), v1.GetOptions{IncludeUninitialized: true})
-		if err != nil && !k8serrors.IsNotFound(err) {
-			return errors.Trace(err)
+	numPods := int32(numUnits)
+	// The application's pods are run by a single workload, so any left
+	// from a previous deployment type are deleted once it's in place.
+	var staleWorkloads []func(string) error
+	switch params.PodSpec.DeploymentType {
+	case caas.DeploymentDaemon:
+		// A daemon set runs a pod on every node, so the number of units is ignored.
+		if err := k.configureDaemonSet(appName, resourceTags, unitSpec, params.PodSpec.Containers); err != nil {
+			return errors.Annotate(err, "creating or updating DaemonSet")
+		}
+		cleanups = append(cleanups, func() { k.deleteDaemonSet(deploymentName(appName)) })
+		staleWorkloads = []func(string) error{k.deleteDeployment, k.deleteStatefulSet, k.deleteJob}
+	case caas.DeploymentJob:
+		if err := k.configureJob(appName, resourceTags, unitSpec, params.PodSpec.Containers, &numPods); err != nil {
+			return errors.Annotate(err, "creating or updating Job")
+		}
+		cleanups = append(cleanups, func() { k.deleteJob(deploymentName(appName)) })
+		staleWorkloads = []func(string) error{k.deleteDeployment, k.deleteStatefulSet, k.deleteDaemonSet}
+	default:
+		// Add a deployment controller or stateful set configured to create the specified number of units/pods.
+		// Unless the deployment type says otherwise, defensively check to see if a stateful set is already used.
+		useStatefulSet := len(params.Filesystems) > 0 || params.PodSpec.DeploymentType == caas.DeploymentStateful
+		if !useStatefulSet && params.PodSpec.DeploymentType == "" {
+			statefulsets := k.AppsV1().StatefulSets(k.namespace)
+			_, err := statefulsets.Get(deploymentName(appName), v1.GetOptions{IncludeUninitialized: true})
+			if err != nil && !k8serrors.IsNotFound(err) {
+				return errors.Trace(err)
+			}
+			useStatefulSet = err == nil
+			if useStatefulSet {
+				logger.Debugf("no updated filesystems but already using stateful set for %v", appName)
+			}
 		}
-		useStatefulSet = err == nil
+
 		if useStatefulSet {
-			logger.Debugf("no updated filesystems but already using stateful set for %v", appName)
+			if err := k.configureStatefulSet(appName, resourceTags, unitSpec, params.PodSpec.Containers, &numPods, params.Filesystems); err != nil {
+				return errors.Annotate(err, "creating or updating StatefulSet")
+			}
+			cleanups = append(cleanups, func() { k.deleteDeployment(appName) })
+			staleWorkloads = []func(string) error{k.deleteDeployment, k.deleteDaemonSet, k.deleteJob}
+		} else {
+			if err := k.configureDeployment(appName, deploymentName(appName), resourceTags, unitSpec, params.PodSpec.Containers, &numPods); err != nil {
+				return errors.Annotate(err, "creating or updating DeploymentController")
+			}
+			cleanups = append(cleanups, func() { k.deleteDeployment(appName) })
+			staleWorkloads = []func(string) error{k.deleteDaemonSet, k.deleteJob}
+			if params.PodSpec.DeploymentType == caas.DeploymentStateless {
+				staleWorkloads = append(staleWorkloads, k.deleteStatefulSet)
+			}
 		}
 	}
-
-	numPods := int32(numUnits)
-	if useStatefulSet {
-		if err := k.configureStatefulSet(appName, resourceTags, unitSpec, params.PodSpec.Containers, &numPods, params.Filesystems); err != nil {
-			return errors.Annotate(err, "creating or updating StatefulSet")
+	for _, deleteWorkload := range staleWorkloads {
+		if err := deleteWorkload(deploymentName(appName)); err != nil {
+			return errors.Annotatef(err, "removing previous workload for %s", appName)
 		}
-		cleanups = append(cleanups, func() { k.deleteDeployment(appName) })
-	} else {
-		if err := k.configureDeployment(appName, deploymentName(appName), resourceTags, unitSpec, params.PodSpec.Containers, &numPods); err != nil {
-			return errors.Annotate(err, "creating or updating DeploymentController")
+	}
+	if params.PodSpec.ServiceAccount == nil {
+		// The pods no longer run as the service account, so it's
+		// removed along with the access granted to it.
+		if err := k.removeServiceAccount(appName); err != nil {
+			return errors.Annotatef(err, "removing service account for %s", appName)
 		}
-		cleanups = append(cleanups, func() { k.deleteDeployment(appName) })
 	}
 
 	var ports []core.ContainerPort
@@ -1018,14 +1168,31 @@ func (k *kubernetesClient) deleteAllPods(appName string) error {
 
 	deployments := k.AppsV1().Deployments(k.namespace)
 	deployment, err := deployments.Get(deploymentName(appName), v1.GetOptions{IncludeUninitialized: true})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return errors.Trace(err)
+	}
+	if err == nil {
+		deployment.Spec.Replicas = &zero
+		_, err = deployments.Update(deployment)
+		return errors.Trace(err)
+	}
+
+	// A daemon set can't be scaled, so it is removed, to be created
+	// again when the application has units.
+	if err := k.deleteDaemonSet(deploymentName(appName)); err != nil {
+		return errors.Trace(err)
+	}
+
+	jobs := k.BatchV1().Jobs(k.namespace)
+	job, err := jobs.Get(deploymentName(appName), v1.GetOptions{IncludeUninitialized: true})
 	if k8serrors.IsNotFound(err) {
 		return nil
 	}
 	if err != nil {
 		return errors.Trace(err)
 	}
-	deployment.Spec.Replicas = &zero
-	_, err = deployments.Update(deployment)
+	job.Spec.Parallelism = &zero
+	_, err = jobs.Update(job)
 	return errors.Trace(err)
 }
 
@@ -1110,13 +1277,17 @@ func (k *kubernetesClient) configureDevices(unitSpec *unitSpec, devices []device
 }
 
 func (k *kubernetesClient) configureConstraint(unitSpec *unitSpec, constraint, value string) error {
-	for i := range unitSpec.Pod.Containers {
-		resources := unitSpec.Pod.Containers[i].Resources
-		err := mergeConstraint(constraint, value, &resources)
-		if err != nil {
-			return errors.Annotatef(err, "merging constraint %q to %#v", constraint, resources)
+	// Init containers run before the others, so they're held to the
+	// constraint too.
+	for _, containers := range [][]core.Container{unitSpec.Pod.InitContainers, unitSpec.Pod.Containers} {
+		for i := range containers {
+			resources := containers[i].Resources
+			err := mergeConstraint(constraint, value, &resources)
+			if err != nil {
+				return errors.Annotatef(err, "merging constraint %q to %#v", constraint, resources)
+			}
+			containers[i].Resources = resources
 		}
-		unitSpec.Pod.Containers[i].Resources = resources
 	}
 	return nil
 }
@@ -1124,7 +1295,21 @@ func (k *kubernetesClient) configureConstraint(unitSpec *unitSpec, constraint, v
 type configMapNameFunc func(fileSetName string) string
 
 func (k *kubernetesClient) configurePodFiles(podSpec *core.PodSpec, containers []caas.ContainerSpec, cfgMapName configMapNameFunc) error {
-	for i, container := range containers {
+	for _, container := range containers {
+		podContainers := podSpec.Containers
+		if container.Init {
+			podContainers = podSpec.InitContainers
+		}
+		var podContainer *core.Container
+		for i := range podContainers {
+			if podContainers[i].Name == container.Name {
+				podContainer = &podContainers[i]
+				break
+			}
+		}
+		if podContainer == nil {
+			return errors.NotFoundf("container %q", container.Name)
+		}
 		for _, fileSet := range container.Files {
 			cfgName := cfgMapName(fileSet.Name)
 			vol := core.Volume{Name: cfgName}
@@ -1137,7 +1322,7 @@ func (k *kubernetesClient) configurePodFiles(podSpec *core.PodSpec, containers [
 				},
 			}
 			podSpec.Volumes = append(podSpec.Volumes, vol)
-			podSpec.Containers[i].VolumeMounts = append(podSpec.Containers[i].VolumeMounts, core.VolumeMount{
+			podContainer.VolumeMounts = append(podContainer.VolumeMounts, core.VolumeMount{
 				Name:      cfgName,
 				MountPath: fileSet.MountPath,
 			})
@@ -1277,6 +1462,146 @@ func (k *kubernetesClient) deleteStatefulSet(name string) error {
 	return errors.Trace(err)
 }
 
+func (k *kubernetesClient) configureDaemonSet(
+	appName string, labels map[string]string, unitSpec *unitSpec, containers []caas.ContainerSpec,
+) error {
+	logger.Debugf("creating/updating daemon set for %s", appName)
+
+	// Add the specified file to the pod spec.
+	cfgName := func(fileSetName string) string {
+		return applicationConfigMapName(appName, fileSetName)
+	}
+	podSpec := unitSpec.Pod
+	if err := k.configurePodFiles(&podSpec, containers, cfgName); err != nil {
+		return errors.Trace(err)
+	}
+
+	daemonSet := &apps.DaemonSet{
+		ObjectMeta: v1.ObjectMeta{
+			Name:   deploymentName(appName),
+			Labels: labels},
+		Spec: apps.DaemonSetSpec{
+			Selector: &v1.LabelSelector{
+				MatchLabels: map[string]string{labelApplication: appName},
+			},
+			Template: core.PodTemplateSpec{
+				ObjectMeta: v1.ObjectMeta{
+					GenerateName: deploymentName(appName) + "-",
+					Labels:       labels,
+				},
+				Spec: podSpec,
+			},
+		},
+	}
+	return k.ensureDaemonSet(daemonSet)
+}
+
+func (k *kubernetesClient) ensureDaemonSet(spec *apps.DaemonSet) error {
+	daemonSets := k.AppsV1().DaemonSets(k.namespace)
+	_, err := daemonSets.Update(spec)
+	if k8serrors.IsNotFound(err) {
+		_, err = daemonSets.Create(spec)
+	}
+	return errors.Trace(err)
+}
+
+func (k *kubernetesClient) deleteDaemonSet(name string) error {
+	daemonSets := k.AppsV1().DaemonSets(k.namespace)
+	err := daemonSets.Delete(name, &v1.DeleteOptions{
+		PropagationPolicy: &defaultPropagationPolicy,
+	})
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+	return errors.Trace(err)
+}
+
+func (k *kubernetesClient) configureJob(
+	appName string, labels map[string]string, unitSpec *unitSpec, containers []caas.ContainerSpec, completions *int32,
+) error {
+	logger.Debugf("creating/updating job for %s", appName)
+
+	// Add the specified file to the pod spec.
+	cfgName := func(fileSetName string) string {
+		return applicationConfigMapName(appName, fileSetName)
+	}
+	podSpec := unitSpec.Pod
+	if err := k.configurePodFiles(&podSpec, containers, cfgName); err != nil {
+		return errors.Trace(err)
+	}
+	// Job pods run to completion, so they're only restarted if they fail.
+	podSpec.RestartPolicy = core.RestartPolicyOnFailure
+
+	job := &batch.Job{
+		ObjectMeta: v1.ObjectMeta{
+			Name:   deploymentName(appName),
+			Labels: labels},
+		Spec: batch.JobSpec{
+			Parallelism: completions,
+			Completions: completions,
+			Template: core.PodTemplateSpec{
+				ObjectMeta: v1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: podSpec,
+			},
+		},
+	}
+	hash, err := jobSpecHash(job.Spec)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	job.Annotations = map[string]string{annotationJobSpecHash: hash}
+	return k.ensureJob(job)
+}
+
+// jobSpecHash returns a hash of the job's pod template, which can't
+// be changed once the job is created. The job's completions can't be
+// changed either, but they follow the number of units, so they're
+// left out: scaling the application only changes its parallelism.
+func jobSpecHash(spec batch.JobSpec) (string, error) {
+	data, err := json.Marshal(spec.Template)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (k *kubernetesClient) ensureJob(spec *batch.Job) error {
+	jobs := k.BatchV1().Jobs(k.namespace)
+	_, err := jobs.Create(spec)
+	if !k8serrors.IsAlreadyExists(err) {
+		return errors.Trace(err)
+	}
+
+	// The job already exists, and once created all we are allowed to
+	// update is its parallelism; it keeps the completions it was
+	// created with, and the pods it has run are left alone.
+	existing, err := jobs.Get(spec.Name, v1.GetOptions{IncludeUninitialized: true})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if existing.Annotations[annotationJobSpecHash] != spec.Annotations[annotationJobSpecHash] {
+		return errors.Errorf(
+			"job %q can't be changed once it's created; remove the application to run the job again", spec.Name)
+	}
+	existing.Spec.Parallelism = spec.Spec.Parallelism
+	_, err = jobs.Update(existing)
+	return errors.Trace(err)
+}
+
+func (k *kubernetesClient) deleteJob(name string) error {
+	jobs := k.BatchV1().Jobs(k.namespace)
+	err := jobs.Delete(name, &v1.DeleteOptions{
+		PropagationPolicy: &defaultPropagationPolicy,
+	})
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+	return errors.Trace(err)
+}
+
 func (k *kubernetesClient) deleteVolumeClaims(appName string, p *core.Pod) ([]string, error) {
 	volumesByName := make(map[string]core.Volume)
 	for _, pv := range p.Spec.Volumes {
@@ -1472,6 +1797,8 @@ func applicationSelector(appName string) string {
 
 // WatchUnits returns a watcher which notifies when there
 // are changes to units of the specified application.
+// Units are the application's pods, whichever kind of
+// workload runs them, so pods are watched by their label.
 func (k *kubernetesClient) WatchUnits(appName string) (watcher.NotifyWatcher, error) {
 	pods := k.CoreV1().Pods(k.namespace)
 	w, err := pods.Watch(v1.ListOptions{
@@ -1504,6 +1831,8 @@ var jujuPVNameRegexp = regexp.MustCompile(`^juju-(?P<storageName>\D+)-\d+$`)
 
 // Units returns all units and any associated filesystems of the specified application.
 // Filesystems are mounted via volumes bound to the unit.
+// Each of the application's pods is a unit, so a daemon set has a unit on every
+// node and a job keeps a unit for each pod it has run until the application is removed.
 func (k *kubernetesClient) Units(appName string) ([]caas.Unit, error) {
 	pods := k.CoreV1().Pods(k.namespace)
 	podsList, err := pods.List(v1.ListOptions{
@@ -1670,6 +1999,17 @@ func (k *kubernetesClient) Operator(appName string) (*caas.Operator, error) {
 func (k *kubernetesClient) getPODStatus(pod core.Pod, now time.Time) (string, status.Status, time.Time, error) {
 	terminated := pod.DeletionTimestamp != nil
 	jujuStatus := k.jujuStatus(pod.Status.Phase, terminated)
+	if jujuStatus == status.Running {
+		// A running pod with containers failing their probes is
+		// reported as in error, with the most recent failure.
+		event, err := k.probeFailure(pod)
+		if err != nil {
+			return "", "", time.Time{}, errors.Trace(err)
+		}
+		if event != nil {
+			return event.Message, status.Error, event.LastTimestamp.Time, nil
+		}
+	}
 	statusMessage := pod.Status.Message
 	since := now
 	if statusMessage == "" {
@@ -1703,6 +2043,47 @@ func (k *kubernetesClient) getPODStatus(pod core.Pod, now time.Time) (string, st
 	return statusMessage, jujuStatus, since, nil
 }
 
+// probeFailure returns the most recent event recording a failed probe
+// for the pod, if any of its running containers are not ready. Events
+// from before a container was last started are ignored.
+func (k *kubernetesClient) probeFailure(pod core.Pod) (*core.Event, error) {
+	// Probes are only run against running containers, so the pod's
+	// events are only needed if one of those isn't ready.
+	started := make(map[string]time.Time)
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.Ready || cs.State.Running == nil {
+			continue
+		}
+		started[fmt.Sprintf("spec.containers{%s}", cs.Name)] = cs.State.Running.StartedAt.Time
+	}
+	if len(started) == 0 {
+		return nil, nil
+	}
+	events := k.CoreV1().Events(k.namespace)
+	eventList, err := events.List(v1.ListOptions{
+		IncludeUninitialized: true,
+		FieldSelector: fields.AndSelectors(
+			fields.OneTermEqualSelector("involvedObject.name", pod.Name),
+			fields.OneTermEqualSelector("reason", eventReasonUnhealthy),
+		).String(),
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	// The events aren't ordered, so take the most recent.
+	var latest *core.Event
+	for i, event := range eventList.Items {
+		since, ok := started[event.InvolvedObject.FieldPath]
+		if !ok || event.LastTimestamp.Time.Before(since) {
+			continue
+		}
+		if latest == nil || event.LastTimestamp.After(latest.LastTimestamp.Time) {
+			latest = &eventList.Items[i]
+		}
+	}
+	return latest, nil
+}
+
 func (k *kubernetesClient) jujuStatus(podPhase core.PodPhase, terminated bool) status.Status {
 	if terminated {
 		return status.Terminated
@@ -1714,6 +2095,10 @@ func (k *kubernetesClient) jujuStatus(podPhase core.PodPhase, terminated bool) s
 		return status.Error
 	case core.PodPending:
 		return status.Allocating
+	case core.PodSucceeded:
+		// Only pods which run to completion, such as those of a
+		// job, ever succeed.
+		return status.Terminated
 	default:
 		return status.Unknown
 	}
@@ -1914,8 +2299,27 @@ func makeUnitSpec(appName string, podSpec *caas.PodSpec) (*unitSpec, error) {
 		if spec.ReadinessProbe != nil {
 			unitSpec.Pod.Containers[i].ReadinessProbe = spec.ReadinessProbe
 		}
+		if spec.Resources != nil {
+			unitSpec.Pod.Containers[i].Resources = *spec.Resources.DeepCopy()
+		}
 	}
+
+	// Init containers are run separately, before the others.
+	var containers, initContainers []core.Container
+	for i, c := range podSpec.Containers {
+		if c.Init {
+			initContainers = append(initContainers, unitSpec.Pod.Containers[i])
+		} else {
+			containers = append(containers, unitSpec.Pod.Containers[i])
+		}
+	}
+	unitSpec.Pod.Containers = containers
+	unitSpec.Pod.InitContainers = initContainers
 	unitSpec.Pod.ImagePullSecrets = imageSecretNames
+	if sa := podSpec.ServiceAccount; sa != nil {
+		unitSpec.Pod.ServiceAccountName = serviceAccountName(appName)
+		unitSpec.Pod.AutomountServiceAccountToken = sa.AutomountServiceAccountToken
+	}
 	return &unitSpec, nil
 }
 
@@ -1939,6 +2343,13 @@ func deploymentName(appName string) string {
 	return "juju-" + appName
 }
 
+// appResourceName returns the name of a secret or custom resource from
+// the pod spec, prefixed with the application so that applications'
+// resources don't clash.
+func appResourceName(appName, name string) string {
+	return appName + "-" + name
+}
+
 func appSecretName(appName, containerName string) string {
 	// A pod may have multiple containers with different images and thus different secrets
 	return "juju-" + appName + "-" + containerName + "-secret"
@@ -1970,19 +2381,25 @@ func mergeDeviceConstraints(device devices.KubernetesDeviceParams, resources *co
 	return nil
 }
 
+// mergeConstraint sets the resource limit for the constraint. A limit
+// the charm has already set in the pod spec is lowered to the constraint
+// if it's higher, along with any request for more than that.
 func mergeConstraint(constraint string, value string, resources *core.ResourceRequirements) error {
 	if resources.Limits == nil {
 		resources.Limits = core.ResourceList{}
 	}
 	resourceName := core.ResourceName(constraint)
-	if v, ok := resources.Limits[resourceName]; ok {
-		return errors.NotValidf("resource limit for %q has already been set to %v!", resourceName, v)
-	}
 	parsedValue, err := resource.ParseQuantity(value)
 	if err != nil {
 		return errors.Annotatef(err, "invalid constraint value %q for %v", value, constraint)
 	}
+	if v, ok := resources.Limits[resourceName]; ok && v.Cmp(parsedValue) <= 0 {
+		return nil
+	}
 	resources.Limits[resourceName] = parsedValue
+	if v, ok := resources.Requests[resourceName]; ok && v.Cmp(parsedValue) > 0 {
+		resources.Requests[resourceName] = parsedValue
+	}
 	return nil
 }
 
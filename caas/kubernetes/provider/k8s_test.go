@@ -15,13 +15,17 @@ import (
 	"gopkg.in/juju/worker.v1/workertest"
 	apps "k8s.io/api/apps/v1"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	core "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	watch "k8s.io/apimachinery/pkg/watch"
 
@@ -581,6 +585,10 @@ func (s *K8sBrokerSuite) TestDeleteService(c *gc.C) {
 			Return(s.k8sNotFoundError()),
 		s.mockDeployments.EXPECT().Delete("juju-test", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
 			Return(s.k8sNotFoundError()),
+		s.mockDaemonSets.EXPECT().Delete("juju-test", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
+		s.mockJobs.EXPECT().Delete("juju-test", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
 		s.mockPods.EXPECT().List(v1.ListOptions{LabelSelector: "juju-application==test"}).
 			Return(&core.PodList{Items: []core.Pod{}}, nil),
 		s.mockSecrets.EXPECT().List(v1.ListOptions{LabelSelector: "juju-application==test"}).Times(1).
@@ -589,6 +597,49 @@ func (s *K8sBrokerSuite) TestDeleteService(c *gc.C) {
 			}}}, nil),
 		s.mockSecrets.EXPECT().Delete("secret", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
 			Return(s.k8sNotFoundError()),
+		s.mockClusterRoleBindings.EXPECT().DeleteCollection(
+			s.deleteOptions(v1.DeletePropagationForeground),
+			v1.ListOptions{LabelSelector: "juju-application==test,juju-model==test"},
+		).Times(1).Return(nil),
+		s.mockClusterRoles.EXPECT().DeleteCollection(
+			s.deleteOptions(v1.DeletePropagationForeground),
+			v1.ListOptions{LabelSelector: "juju-application==test,juju-model==test"},
+		).Times(1).Return(nil),
+		s.mockRoleBindings.EXPECT().DeleteCollection(
+			s.deleteOptions(v1.DeletePropagationForeground),
+			v1.ListOptions{LabelSelector: "juju-application==test"},
+		).Times(1).Return(nil),
+		s.mockRoles.EXPECT().DeleteCollection(
+			s.deleteOptions(v1.DeletePropagationForeground),
+			v1.ListOptions{LabelSelector: "juju-application==test"},
+		).Times(1).Return(nil),
+		s.mockServiceAccounts.EXPECT().DeleteCollection(
+			s.deleteOptions(v1.DeletePropagationForeground),
+			v1.ListOptions{LabelSelector: "juju-application==test"},
+		).Times(1).Return(s.k8sNotFoundError()),
+		s.mockCustomResourceDefinition.EXPECT().List(v1.ListOptions{}).Times(1).
+			Return(&apiextensionsv1beta1.CustomResourceDefinitionList{Items: []apiextensionsv1beta1.CustomResourceDefinition{{
+				Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{
+					Group:   "kubeflow.org",
+					Version: "v1alpha2",
+					Scope:   "Namespaced",
+					Names:   apiextensionsv1beta1.CustomResourceDefinitionNames{Kind: "TFJob", Plural: "tfjobs"},
+				},
+			}, {
+				Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{
+					Group:   "example.com",
+					Version: "v1",
+					Scope:   "Cluster",
+					Names:   apiextensionsv1beta1.CustomResourceDefinitionNames{Kind: "Thing", Plural: "things"},
+				},
+			}}}, nil),
+		s.mockDynamicClient.EXPECT().Resource(schema.GroupVersionResource{
+			Group: "kubeflow.org", Version: "v1alpha2", Resource: "tfjobs",
+		}).Times(1).Return(s.mockNamespaceableResourceClient),
+		s.mockResourceClient.EXPECT().DeleteCollection(
+			s.deleteOptions(v1.DeletePropagationForeground),
+			v1.ListOptions{LabelSelector: "juju-application==test"},
+		).Times(1).Return(nil),
 	)
 
 	err := s.broker.DeleteService("test")
@@ -674,12 +725,20 @@ func (s *K8sBrokerSuite) TestEnsureServiceNoStorage(c *gc.C) {
 	gomock.InOrder(
 		s.mockSecrets.EXPECT().Update(secretArg).Times(1).
 			Return(nil, nil),
+		s.mockSecrets.EXPECT().List(v1.ListOptions{LabelSelector: "juju-application==app-name"}).Times(1).
+			Return(&core.SecretList{}, nil),
 		s.mockStatefulSets.EXPECT().Get("juju-app-name", v1.GetOptions{IncludeUninitialized: true}).Times(1).
 			Return(nil, s.k8sNotFoundError()),
 		s.mockDeployments.EXPECT().Update(deploymentArg).Times(1).
 			Return(nil, s.k8sNotFoundError()),
 		s.mockDeployments.EXPECT().Create(deploymentArg).Times(1).
 			Return(nil, nil),
+		s.mockDaemonSets.EXPECT().Delete("juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
+		s.mockJobs.EXPECT().Delete("juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
+		s.mockServiceAccounts.EXPECT().List(v1.ListOptions{LabelSelector: "juju-application==app-name"}).Times(1).
+			Return(&core.ServiceAccountList{}, nil),
 		s.mockServices.EXPECT().Get("juju-app-name", v1.GetOptions{IncludeUninitialized: true}).Times(1).
 			Return(nil, s.k8sNotFoundError()),
 		s.mockServices.EXPECT().Update(serviceArg).Times(1).
@@ -700,6 +759,720 @@ func (s *K8sBrokerSuite) TestEnsureServiceNoStorage(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 }
 
+func (s *K8sBrokerSuite) TestEnsureServiceWithServiceAccount(c *gc.C) {
+	ctrl := s.setupBroker(c)
+	defer ctrl.Finish()
+
+	automount := false
+	podSpec := *basicPodspec
+	podSpec.ServiceAccount = &caas.ServiceAccountSpec{
+		AutomountServiceAccountToken: &automount,
+		Rules: []caas.PolicyRule{{
+			APIGroups: []string{""},
+			Resources: []string{"pods"},
+			Verbs:     []string{"get", "watch", "list"},
+		}},
+	}
+
+	numUnits := int32(2)
+	unitSpec, err := provider.MakeUnitSpec("app-name", &podSpec)
+	c.Assert(err, jc.ErrorIsNil)
+	pod := provider.PodSpec(unitSpec)
+	c.Assert(pod.ServiceAccountName, gc.Equals, "juju-app-name")
+	c.Assert(pod.AutomountServiceAccountToken, jc.DeepEquals, &automount)
+
+	labels := map[string]string{"juju-application": "app-name"}
+	deploymentArg := &appsv1.Deployment{
+		ObjectMeta: v1.ObjectMeta{
+			Name:   "juju-app-name",
+			Labels: labels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &numUnits,
+			Selector: &v1.LabelSelector{
+				MatchLabels: map[string]string{"juju-application": "app-name"},
+			},
+			Template: core.PodTemplateSpec{
+				ObjectMeta: v1.ObjectMeta{
+					GenerateName: "juju-app-name-",
+					Labels:       labels,
+				},
+				Spec: pod,
+			},
+		},
+	}
+	serviceAccountArg := &core.ServiceAccount{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "juju-app-name",
+			Namespace: "test",
+			Labels:    labels,
+		},
+		AutomountServiceAccountToken: &automount,
+	}
+	roleArg := &rbacv1.Role{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "juju-app-name",
+			Namespace: "test",
+			Labels:    labels,
+		},
+		Rules: []rbacv1.PolicyRule{{
+			APIGroups: []string{""},
+			Resources: []string{"pods"},
+			Verbs:     []string{"get", "watch", "list"},
+		}},
+	}
+	roleBindingArg := &rbacv1.RoleBinding{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "juju-app-name",
+			Namespace: "test",
+			Labels:    labels,
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: "rbac.authorization.k8s.io",
+			Kind:     "Role",
+			Name:     "juju-app-name",
+		},
+		Subjects: []rbacv1.Subject{{
+			Kind:      "ServiceAccount",
+			Name:      "juju-app-name",
+			Namespace: "test",
+		}},
+	}
+
+	gomock.InOrder(
+		s.mockSecrets.EXPECT().Update(s.secretArg(c, nil)).Times(1).
+			Return(nil, nil),
+		s.mockServiceAccounts.EXPECT().Update(serviceAccountArg).Times(1).
+			Return(nil, s.k8sNotFoundError()),
+		s.mockServiceAccounts.EXPECT().Create(serviceAccountArg).Times(1).
+			Return(nil, nil),
+		s.mockRoles.EXPECT().Update(roleArg).Times(1).
+			Return(nil, s.k8sNotFoundError()),
+		s.mockRoles.EXPECT().Create(roleArg).Times(1).
+			Return(nil, nil),
+		s.mockRoleBindings.EXPECT().Update(roleBindingArg).Times(1).
+			Return(nil, nil),
+		// There are no cluster rules, so any cluster role is removed.
+		s.mockClusterRoleBindings.EXPECT().Delete("test-juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
+		s.mockClusterRoles.EXPECT().Delete("test-juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
+		s.mockSecrets.EXPECT().List(v1.ListOptions{LabelSelector: "juju-application==app-name"}).Times(1).
+			Return(&core.SecretList{}, nil),
+		s.mockStatefulSets.EXPECT().Get("juju-app-name", v1.GetOptions{IncludeUninitialized: true}).Times(1).
+			Return(nil, s.k8sNotFoundError()),
+		s.mockDeployments.EXPECT().Update(deploymentArg).Times(1).
+			Return(nil, s.k8sNotFoundError()),
+		s.mockDeployments.EXPECT().Create(deploymentArg).Times(1).
+			Return(nil, nil),
+		s.mockDaemonSets.EXPECT().Delete("juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
+		s.mockJobs.EXPECT().Delete("juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
+		s.mockServices.EXPECT().Get("juju-app-name", v1.GetOptions{IncludeUninitialized: true}).Times(1).
+			Return(nil, s.k8sNotFoundError()),
+		s.mockServices.EXPECT().Update(gomock.Any()).Times(1).
+			Return(nil, nil),
+	)
+
+	params := &caas.ServiceParams{
+		PodSpec: &podSpec,
+	}
+	err = s.broker.EnsureService("app-name", nil, params, 2, nil)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *K8sBrokerSuite) TestEnsureServiceClusterRulesUntrusted(c *gc.C) {
+	ctrl := s.setupBroker(c)
+	defer ctrl.Finish()
+
+	podSpec := *basicPodspec
+	podSpec.ServiceAccount = &caas.ServiceAccountSpec{
+		ClusterRules: []caas.PolicyRule{{
+			Resources: []string{"nodes"},
+			Verbs:     []string{"list"},
+		}},
+	}
+
+	params := &caas.ServiceParams{
+		PodSpec: &podSpec,
+	}
+	statusCallback := func(appName string, settableStatus status.Status, info string, data map[string]interface{}) error {
+		return nil
+	}
+	err := s.broker.EnsureService("app-name", statusCallback, params, 2, application.ConfigAttributes{
+		"trust": false,
+	})
+	c.Assert(err, gc.ErrorMatches, `cluster rules for app-name need the application to be trusted; run "juju trust app-name"`)
+}
+
+func (s *K8sBrokerSuite) TestEnsureServiceWithServiceAccountFailureKeepsExisting(c *gc.C) {
+	ctrl := s.setupBroker(c)
+	defer ctrl.Finish()
+
+	podSpec := *basicPodspec
+	podSpec.ServiceAccount = &caas.ServiceAccountSpec{
+		Rules: []caas.PolicyRule{{
+			Resources: []string{"pods"},
+			Verbs:     []string{"get"},
+		}},
+	}
+
+	gomock.InOrder(
+		s.mockSecrets.EXPECT().Update(s.secretArg(c, nil)).Times(1).
+			Return(nil, nil),
+		// The service account already exists, so it isn't removed
+		// when the deployment fails; the role and binding are.
+		s.mockServiceAccounts.EXPECT().Update(gomock.Any()).Times(1).
+			Return(nil, nil),
+		s.mockRoles.EXPECT().Update(gomock.Any()).Times(1).
+			Return(nil, s.k8sNotFoundError()),
+		s.mockRoles.EXPECT().Create(gomock.Any()).Times(1).
+			Return(nil, nil),
+		s.mockRoleBindings.EXPECT().Update(gomock.Any()).Times(1).
+			Return(nil, s.k8sNotFoundError()),
+		s.mockRoleBindings.EXPECT().Create(gomock.Any()).Times(1).
+			Return(nil, nil),
+		s.mockClusterRoleBindings.EXPECT().Delete("test-juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
+		s.mockClusterRoles.EXPECT().Delete("test-juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
+		s.mockSecrets.EXPECT().List(v1.ListOptions{LabelSelector: "juju-application==app-name"}).Times(1).
+			Return(&core.SecretList{}, nil),
+		s.mockStatefulSets.EXPECT().Get("juju-app-name", v1.GetOptions{IncludeUninitialized: true}).Times(1).
+			Return(nil, s.k8sNotFoundError()),
+		s.mockDeployments.EXPECT().Update(gomock.Any()).Times(1).
+			Return(nil, errors.New("boom")),
+		s.mockSecrets.EXPECT().Delete("juju-app-name-test-secret", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(nil),
+		s.mockRoles.EXPECT().Delete("juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(nil),
+		s.mockRoleBindings.EXPECT().Delete("juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(nil),
+	)
+
+	params := &caas.ServiceParams{
+		PodSpec: &podSpec,
+	}
+	statusCallback := func(appName string, settableStatus status.Status, info string, data map[string]interface{}) error {
+		return nil
+	}
+	err := s.broker.EnsureService("app-name", statusCallback, params, 2, nil)
+	c.Assert(err, gc.ErrorMatches, "creating or updating DeploymentController: boom")
+}
+
+func (s *K8sBrokerSuite) TestEnsureServiceRemovesServiceAccount(c *gc.C) {
+	ctrl := s.setupBroker(c)
+	defer ctrl.Finish()
+
+	gomock.InOrder(
+		s.mockSecrets.EXPECT().Update(s.secretArg(c, nil)).Times(1).
+			Return(nil, nil),
+		s.mockSecrets.EXPECT().List(v1.ListOptions{LabelSelector: "juju-application==app-name"}).Times(1).
+			Return(&core.SecretList{}, nil),
+		s.mockStatefulSets.EXPECT().Get("juju-app-name", v1.GetOptions{IncludeUninitialized: true}).Times(1).
+			Return(nil, s.k8sNotFoundError()),
+		s.mockDeployments.EXPECT().Update(gomock.Any()).Times(1).
+			Return(nil, nil),
+		// The pod spec no longer has a service account, so the one
+		// made for an earlier spec is removed.
+		s.mockDaemonSets.EXPECT().Delete("juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
+		s.mockJobs.EXPECT().Delete("juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
+		s.mockServiceAccounts.EXPECT().List(v1.ListOptions{LabelSelector: "juju-application==app-name"}).Times(1).
+			Return(&core.ServiceAccountList{Items: []core.ServiceAccount{{
+				ObjectMeta: v1.ObjectMeta{Name: "juju-app-name"},
+			}}}, nil),
+		s.mockClusterRoleBindings.EXPECT().DeleteCollection(
+			s.deleteOptions(v1.DeletePropagationForeground),
+			v1.ListOptions{LabelSelector: "juju-application==app-name,juju-model==test"},
+		).Times(1).Return(nil),
+		s.mockClusterRoles.EXPECT().DeleteCollection(
+			s.deleteOptions(v1.DeletePropagationForeground),
+			v1.ListOptions{LabelSelector: "juju-application==app-name,juju-model==test"},
+		).Times(1).Return(nil),
+		s.mockRoleBindings.EXPECT().DeleteCollection(
+			s.deleteOptions(v1.DeletePropagationForeground),
+			v1.ListOptions{LabelSelector: "juju-application==app-name"},
+		).Times(1).Return(nil),
+		s.mockRoles.EXPECT().DeleteCollection(
+			s.deleteOptions(v1.DeletePropagationForeground),
+			v1.ListOptions{LabelSelector: "juju-application==app-name"},
+		).Times(1).Return(nil),
+		s.mockServiceAccounts.EXPECT().DeleteCollection(
+			s.deleteOptions(v1.DeletePropagationForeground),
+			v1.ListOptions{LabelSelector: "juju-application==app-name"},
+		).Times(1).Return(nil),
+		s.mockServices.EXPECT().Get("juju-app-name", v1.GetOptions{IncludeUninitialized: true}).Times(1).
+			Return(nil, s.k8sNotFoundError()),
+		s.mockServices.EXPECT().Update(gomock.Any()).Times(1).
+			Return(nil, nil),
+	)
+
+	params := &caas.ServiceParams{
+		PodSpec: basicPodspec,
+	}
+	err := s.broker.EnsureService("app-name", nil, params, 2, nil)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *K8sBrokerSuite) TestEnsureServiceWithSecretsAndCustomResources(c *gc.C) {
+	ctrl := s.setupBroker(c)
+	defer ctrl.Finish()
+
+	podSpec := *basicPodspec
+	podSpec.Secrets = []caas.Secret{{
+		Name: "db",
+		Data: map[string]string{"password": "s3cret"},
+	}}
+	podSpec.CustomResources = []caas.CustomResource{{
+		APIVersion: "kubeflow.org/v1alpha2",
+		Kind:       "TFJob",
+		Name:       "train",
+		Spec:       map[string]interface{}{"replicas": float64(2)},
+	}}
+
+	labels := map[string]string{"juju-application": "app-name"}
+	secretArg := &core.Secret{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "app-name-db",
+			Namespace: "test",
+			Labels:    labels,
+		},
+		Type: core.SecretTypeOpaque,
+		Data: map[string][]byte{"password": []byte("s3cret")},
+	}
+	crd := apiextensionsv1beta1.CustomResourceDefinition{
+		ObjectMeta: v1.ObjectMeta{Name: "tfjobs.kubeflow.org"},
+		Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{
+			Group:   "kubeflow.org",
+			Version: "v1alpha2",
+			Scope:   "Namespaced",
+			Names:   apiextensionsv1beta1.CustomResourceDefinitionNames{Kind: "TFJob", Plural: "tfjobs"},
+		},
+	}
+	tfjobs := schema.GroupVersionResource{Group: "kubeflow.org", Version: "v1alpha2", Resource: "tfjobs"}
+	resourceArg := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "kubeflow.org/v1alpha2",
+		"kind":       "TFJob",
+		"metadata": map[string]interface{}{
+			"name":      "app-name-train",
+			"namespace": "test",
+			"labels":    map[string]interface{}{"juju-application": "app-name"},
+		},
+		"spec": map[string]interface{}{"replicas": float64(2)},
+	}}
+	existingResource := func(name string) unstructured.Unstructured {
+		var r unstructured.Unstructured
+		r.SetName(name)
+		return r
+	}
+
+	gomock.InOrder(
+		s.mockSecrets.EXPECT().Update(s.secretArg(c, nil)).Times(1).
+			Return(nil, nil),
+		s.mockSecrets.EXPECT().Get("app-name-db", v1.GetOptions{IncludeUninitialized: true}).Times(1).
+			Return(nil, s.k8sNotFoundError()),
+		s.mockSecrets.EXPECT().Create(secretArg).Times(1).
+			Return(nil, nil),
+		// Secrets no longer in the spec are deleted, but not the image pull secret.
+		s.mockSecrets.EXPECT().List(v1.ListOptions{LabelSelector: "juju-application==app-name"}).Times(1).
+			Return(&core.SecretList{Items: []core.Secret{
+				{ObjectMeta: v1.ObjectMeta{Name: "juju-app-name-test-secret"}},
+				{ObjectMeta: v1.ObjectMeta{Name: "app-name-db"}},
+				{ObjectMeta: v1.ObjectMeta{Name: "app-name-old-secret"}},
+			}}, nil),
+		s.mockSecrets.EXPECT().Delete("app-name-old-secret", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(nil),
+		// Only the definition the spec names is consulted.
+		s.mockCustomResourceDefinition.EXPECT().Get("tfjobs.kubeflow.org", v1.GetOptions{}).Times(1).
+			Return(&crd, nil),
+		s.mockDynamicClient.EXPECT().Resource(tfjobs).Times(1).
+			Return(s.mockNamespaceableResourceClient),
+		s.mockResourceClient.EXPECT().Get("app-name-train", v1.GetOptions{}).Times(1).
+			Return(nil, s.k8sNotFoundError()),
+		s.mockResourceClient.EXPECT().Create(resourceArg, v1.CreateOptions{}).Times(1).
+			Return(resourceArg, nil),
+		// Custom resources no longer in the spec are deleted.
+		s.mockDynamicClient.EXPECT().Resource(tfjobs).Times(1).
+			Return(s.mockNamespaceableResourceClient),
+		s.mockResourceClient.EXPECT().List(v1.ListOptions{LabelSelector: "juju-application==app-name"}).Times(1).
+			Return(&unstructured.UnstructuredList{Items: []unstructured.Unstructured{
+				existingResource("app-name-train"), existingResource("app-name-old-job"),
+			}}, nil),
+		s.mockResourceClient.EXPECT().Delete("app-name-old-job", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(nil),
+		s.mockStatefulSets.EXPECT().Get("juju-app-name", v1.GetOptions{IncludeUninitialized: true}).Times(1).
+			Return(nil, s.k8sNotFoundError()),
+		s.mockDeployments.EXPECT().Update(gomock.Any()).Times(1).
+			Return(nil, nil),
+		s.mockDaemonSets.EXPECT().Delete("juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
+		s.mockJobs.EXPECT().Delete("juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
+		s.mockServiceAccounts.EXPECT().List(v1.ListOptions{LabelSelector: "juju-application==app-name"}).Times(1).
+			Return(&core.ServiceAccountList{}, nil),
+		s.mockServices.EXPECT().Get("juju-app-name", v1.GetOptions{IncludeUninitialized: true}).Times(1).
+			Return(nil, s.k8sNotFoundError()),
+		s.mockServices.EXPECT().Update(gomock.Any()).Times(1).
+			Return(nil, nil),
+	)
+
+	params := &caas.ServiceParams{
+		PodSpec: &podSpec,
+	}
+	err := s.broker.EnsureService("app-name", nil, params, 2, nil)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *K8sBrokerSuite) TestEnsureServiceSecretNotManaged(c *gc.C) {
+	ctrl := s.setupBroker(c)
+	defer ctrl.Finish()
+
+	podSpec := *basicPodspec
+	podSpec.Secrets = []caas.Secret{{
+		Name: "db",
+		Data: map[string]string{"password": "s3cret"},
+	}}
+
+	gomock.InOrder(
+		s.mockSecrets.EXPECT().Update(s.secretArg(c, nil)).Times(1).
+			Return(nil, nil),
+		// A secret of the same name which isn't labelled with the
+		// application is left alone.
+		s.mockSecrets.EXPECT().Get("app-name-db", v1.GetOptions{IncludeUninitialized: true}).Times(1).
+			Return(&core.Secret{ObjectMeta: v1.ObjectMeta{Name: "app-name-db"}}, nil),
+		s.mockSecrets.EXPECT().Delete("juju-app-name-test-secret", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(nil),
+	)
+
+	params := &caas.ServiceParams{
+		PodSpec: &podSpec,
+	}
+	statusCallback := func(appName string, settableStatus status.Status, info string, data map[string]interface{}) error {
+		return nil
+	}
+	err := s.broker.EnsureService("app-name", statusCallback, params, 2, nil)
+	c.Assert(err, gc.ErrorMatches, `creating secrets for app-name: creating or updating secret "app-name-db": secret "app-name-db" already exists and isn't managed by app-name`)
+}
+
+func (s *K8sBrokerSuite) TestEnsureServiceCustomResourceWithoutDefinition(c *gc.C) {
+	ctrl := s.setupBroker(c)
+	defer ctrl.Finish()
+
+	podSpec := *basicPodspec
+	podSpec.CustomResources = []caas.CustomResource{{
+		APIVersion: "kubeflow.org/v1alpha2",
+		Kind:       "TFJob",
+		Name:       "train",
+	}}
+
+	gomock.InOrder(
+		s.mockSecrets.EXPECT().Update(s.secretArg(c, nil)).Times(1).
+			Return(nil, nil),
+		s.mockSecrets.EXPECT().List(v1.ListOptions{LabelSelector: "juju-application==app-name"}).Times(1).
+			Return(&core.SecretList{}, nil),
+		s.mockCustomResourceDefinition.EXPECT().Get("tfjobs.kubeflow.org", v1.GetOptions{}).Times(1).
+			Return(nil, s.k8sNotFoundError()),
+		s.mockSecrets.EXPECT().Delete("juju-app-name-test-secret", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(nil),
+	)
+
+	params := &caas.ServiceParams{
+		PodSpec: &podSpec,
+	}
+	statusCallback := func(appName string, settableStatus status.Status, info string, data map[string]interface{}) error {
+		return nil
+	}
+	err := s.broker.EnsureService("app-name", statusCallback, params, 2, nil)
+	c.Assert(err, gc.ErrorMatches, `creating custom resources for app-name: custom resource definition for TFJob "train" not found`)
+}
+
+func (s *K8sBrokerSuite) TestEnsureServiceNoUnitsDaemonSet(c *gc.C) {
+	ctrl := s.setupBroker(c)
+	defer ctrl.Finish()
+
+	gomock.InOrder(
+		s.mockStatefulSets.EXPECT().Get("juju-app-name", v1.GetOptions{IncludeUninitialized: true}).Times(1).
+			Return(nil, s.k8sNotFoundError()),
+		s.mockDeployments.EXPECT().Get("juju-app-name", v1.GetOptions{IncludeUninitialized: true}).Times(1).
+			Return(nil, s.k8sNotFoundError()),
+		s.mockDaemonSets.EXPECT().Delete("juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(nil),
+		s.mockJobs.EXPECT().Get("juju-app-name", v1.GetOptions{IncludeUninitialized: true}).Times(1).
+			Return(nil, s.k8sNotFoundError()),
+	)
+
+	params := &caas.ServiceParams{}
+	err := s.broker.EnsureService("app-name", nil, params, 0, nil)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *K8sBrokerSuite) TestEnsureServiceDaemonSet(c *gc.C) {
+	ctrl := s.setupBroker(c)
+	defer ctrl.Finish()
+
+	podSpec := *basicPodspec
+	podSpec.DeploymentType = caas.DeploymentDaemon
+	unitSpec, err := provider.MakeUnitSpec("app-name", &podSpec)
+	c.Assert(err, jc.ErrorIsNil)
+
+	labels := map[string]string{"juju-application": "app-name"}
+	daemonSetArg := &appsv1.DaemonSet{
+		ObjectMeta: v1.ObjectMeta{
+			Name:   "juju-app-name",
+			Labels: labels,
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &v1.LabelSelector{
+				MatchLabels: map[string]string{"juju-application": "app-name"},
+			},
+			Template: core.PodTemplateSpec{
+				ObjectMeta: v1.ObjectMeta{
+					GenerateName: "juju-app-name-",
+					Labels:       labels,
+				},
+				Spec: provider.PodSpec(unitSpec),
+			},
+		},
+	}
+
+	gomock.InOrder(
+		s.mockSecrets.EXPECT().Update(s.secretArg(c, nil)).Times(1).
+			Return(nil, nil),
+		s.mockSecrets.EXPECT().List(v1.ListOptions{LabelSelector: "juju-application==app-name"}).Times(1).
+			Return(&core.SecretList{}, nil),
+		s.mockDaemonSets.EXPECT().Update(daemonSetArg).Times(1).
+			Return(nil, s.k8sNotFoundError()),
+		s.mockDaemonSets.EXPECT().Create(daemonSetArg).Times(1).
+			Return(nil, nil),
+		s.mockDeployments.EXPECT().Delete("juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
+		s.mockStatefulSets.EXPECT().Delete("juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
+		s.mockJobs.EXPECT().Delete("juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
+		s.mockServiceAccounts.EXPECT().List(v1.ListOptions{LabelSelector: "juju-application==app-name"}).Times(1).
+			Return(&core.ServiceAccountList{}, nil),
+		s.mockServices.EXPECT().Get("juju-app-name", v1.GetOptions{IncludeUninitialized: true}).Times(1).
+			Return(nil, s.k8sNotFoundError()),
+		s.mockServices.EXPECT().Update(gomock.Any()).Times(1).
+			Return(nil, nil),
+	)
+
+	params := &caas.ServiceParams{
+		PodSpec: &podSpec,
+	}
+	err = s.broker.EnsureService("app-name", nil, params, 2, nil)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *K8sBrokerSuite) TestEnsureServiceJob(c *gc.C) {
+	ctrl := s.setupBroker(c)
+	defer ctrl.Finish()
+
+	podSpec := *basicPodspec
+	podSpec.DeploymentType = caas.DeploymentJob
+	unitSpec, err := provider.MakeUnitSpec("app-name", &podSpec)
+	c.Assert(err, jc.ErrorIsNil)
+	pod := provider.PodSpec(unitSpec)
+	pod.RestartPolicy = core.RestartPolicyOnFailure
+
+	numUnits := int32(2)
+	labels := map[string]string{"juju-application": "app-name"}
+	jobArg := &batchv1.Job{
+		ObjectMeta: v1.ObjectMeta{
+			Name:   "juju-app-name",
+			Labels: labels,
+		},
+		Spec: batchv1.JobSpec{
+			Parallelism: &numUnits,
+			Completions: &numUnits,
+			Template: core.PodTemplateSpec{
+				ObjectMeta: v1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: pod,
+			},
+		},
+	}
+	hash, err := provider.JobSpecHash(jobArg.Spec)
+	c.Assert(err, jc.ErrorIsNil)
+	jobArg.Annotations = map[string]string{"juju-job-spec-hash": hash}
+
+	// The application was scaled to zero, so the existing job
+	// isn't running any pods.
+	zero := int32(0)
+	existing := *jobArg
+	existing.Spec.Parallelism = &zero
+	updated := existing
+	updated.Spec.Parallelism = &numUnits
+
+	gomock.InOrder(
+		s.mockSecrets.EXPECT().Update(s.secretArg(c, nil)).Times(1).
+			Return(nil, nil),
+		s.mockSecrets.EXPECT().List(v1.ListOptions{LabelSelector: "juju-application==app-name"}).Times(1).
+			Return(&core.SecretList{}, nil),
+		s.mockJobs.EXPECT().Create(jobArg).Times(1).
+			Return(nil, s.k8sAlreadyExists()),
+		s.mockJobs.EXPECT().Get("juju-app-name", v1.GetOptions{IncludeUninitialized: true}).Times(1).
+			Return(&existing, nil),
+		// Only the parallelism of an existing job can be changed.
+		s.mockJobs.EXPECT().Update(&updated).Times(1).
+			Return(nil, nil),
+		s.mockDeployments.EXPECT().Delete("juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
+		s.mockStatefulSets.EXPECT().Delete("juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
+		s.mockDaemonSets.EXPECT().Delete("juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
+		s.mockServiceAccounts.EXPECT().List(v1.ListOptions{LabelSelector: "juju-application==app-name"}).Times(1).
+			Return(&core.ServiceAccountList{}, nil),
+		s.mockServices.EXPECT().Get("juju-app-name", v1.GetOptions{IncludeUninitialized: true}).Times(1).
+			Return(nil, s.k8sNotFoundError()),
+		s.mockServices.EXPECT().Update(gomock.Any()).Times(1).
+			Return(nil, nil),
+	)
+
+	params := &caas.ServiceParams{
+		PodSpec: &podSpec,
+	}
+	err = s.broker.EnsureService("app-name", nil, params, 2, nil)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *K8sBrokerSuite) TestEnsureServiceJobScaled(c *gc.C) {
+	ctrl := s.setupBroker(c)
+	defer ctrl.Finish()
+
+	podSpec := *basicPodspec
+	podSpec.DeploymentType = caas.DeploymentJob
+	unitSpec, err := provider.MakeUnitSpec("app-name", &podSpec)
+	c.Assert(err, jc.ErrorIsNil)
+	pod := provider.PodSpec(unitSpec)
+	pod.RestartPolicy = core.RestartPolicyOnFailure
+
+	numUnits := int32(3)
+	labels := map[string]string{"juju-application": "app-name"}
+	jobArg := &batchv1.Job{
+		ObjectMeta: v1.ObjectMeta{
+			Name:   "juju-app-name",
+			Labels: labels,
+		},
+		Spec: batchv1.JobSpec{
+			Parallelism: &numUnits,
+			Completions: &numUnits,
+			Template: core.PodTemplateSpec{
+				ObjectMeta: v1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: pod,
+			},
+		},
+	}
+	hash, err := provider.JobSpecHash(jobArg.Spec)
+	c.Assert(err, jc.ErrorIsNil)
+	jobArg.Annotations = map[string]string{"juju-job-spec-hash": hash}
+
+	// The job was created with 2 units, which it keeps as its
+	// completions; scaling to 3 units only changes its parallelism.
+	created := int32(2)
+	existing := *jobArg
+	existing.Spec.Parallelism = &created
+	existing.Spec.Completions = &created
+	updated := existing
+	updated.Spec.Parallelism = &numUnits
+
+	gomock.InOrder(
+		s.mockSecrets.EXPECT().Update(s.secretArg(c, nil)).Times(1).
+			Return(nil, nil),
+		s.mockSecrets.EXPECT().List(v1.ListOptions{LabelSelector: "juju-application==app-name"}).Times(1).
+			Return(&core.SecretList{}, nil),
+		s.mockJobs.EXPECT().Create(jobArg).Times(1).
+			Return(nil, s.k8sAlreadyExists()),
+		s.mockJobs.EXPECT().Get("juju-app-name", v1.GetOptions{IncludeUninitialized: true}).Times(1).
+			Return(&existing, nil),
+		s.mockJobs.EXPECT().Update(&updated).Times(1).
+			Return(nil, nil),
+		s.mockDeployments.EXPECT().Delete("juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
+		s.mockStatefulSets.EXPECT().Delete("juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
+		s.mockDaemonSets.EXPECT().Delete("juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
+		s.mockServiceAccounts.EXPECT().List(v1.ListOptions{LabelSelector: "juju-application==app-name"}).Times(1).
+			Return(&core.ServiceAccountList{}, nil),
+		s.mockServices.EXPECT().Get("juju-app-name", v1.GetOptions{IncludeUninitialized: true}).Times(1).
+			Return(nil, s.k8sNotFoundError()),
+		s.mockServices.EXPECT().Update(gomock.Any()).Times(1).
+			Return(nil, nil),
+	)
+
+	params := &caas.ServiceParams{
+		PodSpec: &podSpec,
+	}
+	err = s.broker.EnsureService("app-name", nil, params, 3, nil)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *K8sBrokerSuite) TestEnsureServiceJobChanged(c *gc.C) {
+	ctrl := s.setupBroker(c)
+	defer ctrl.Finish()
+
+	podSpec := *basicPodspec
+	podSpec.DeploymentType = caas.DeploymentJob
+
+	existing := &batchv1.Job{
+		ObjectMeta: v1.ObjectMeta{
+			Name:        "juju-app-name",
+			Annotations: map[string]string{"juju-job-spec-hash": "old-hash"},
+		},
+	}
+	gomock.InOrder(
+		s.mockSecrets.EXPECT().Update(s.secretArg(c, nil)).Times(1).
+			Return(nil, nil),
+		s.mockSecrets.EXPECT().List(v1.ListOptions{LabelSelector: "juju-application==app-name"}).Times(1).
+			Return(&core.SecretList{}, nil),
+		s.mockJobs.EXPECT().Create(gomock.Any()).Times(1).
+			Return(nil, s.k8sAlreadyExists()),
+		s.mockJobs.EXPECT().Get("juju-app-name", v1.GetOptions{IncludeUninitialized: true}).Times(1).
+			Return(existing, nil),
+		s.mockSecrets.EXPECT().Delete("juju-app-name-test-secret", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(nil),
+	)
+
+	params := &caas.ServiceParams{
+		PodSpec: &podSpec,
+	}
+	statusCallback := func(appName string, settableStatus status.Status, info string, data map[string]interface{}) error {
+		return nil
+	}
+	err := s.broker.EnsureService("app-name", statusCallback, params, 3, nil)
+	c.Assert(err, gc.ErrorMatches, `creating or updating Job: job "juju-app-name" can't be changed once it's created; .*`)
+}
+
+func (s *K8sBrokerSuite) TestEnsureServiceDaemonSetWithStorage(c *gc.C) {
+	ctrl := s.setupBroker(c)
+	defer ctrl.Finish()
+
+	podSpec := *basicPodspec
+	podSpec.DeploymentType = caas.DeploymentDaemon
+	params := &caas.ServiceParams{
+		PodSpec: &podSpec,
+		Filesystems: []storage.KubernetesFilesystemParams{{
+			StorageName: "database",
+			Size:        100,
+		}},
+	}
+	statusCallback := func(appName string, settableStatus status.Status, info string, data map[string]interface{}) error {
+		return nil
+	}
+	err := s.broker.EnsureService("app-name", statusCallback, params, 2, nil)
+	c.Assert(err, gc.ErrorMatches, `storage for daemon deployments not supported`)
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
+
 func (s *K8sBrokerSuite) TestEnsureCustomResourceDefinitionCreate(c *gc.C) {
 	ctrl := s.setupBroker(c)
 	defer ctrl.Finish()
@@ -925,6 +1698,8 @@ func (s *K8sBrokerSuite) TestEnsureServiceWithStorage(c *gc.C) {
 	gomock.InOrder(
 		s.mockSecrets.EXPECT().Update(s.secretArg(c, nil)).Times(1).
 			Return(nil, nil),
+		s.mockSecrets.EXPECT().List(v1.ListOptions{LabelSelector: "juju-application==app-name"}).Times(1).
+			Return(&core.SecretList{}, nil),
 		s.mockStorageClass.EXPECT().Get("test-juju-unit-storage", v1.GetOptions{IncludeUninitialized: false}).Times(1).
 			Return(nil, s.k8sNotFoundError()),
 		s.mockStorageClass.EXPECT().Get("juju-unit-storage", v1.GetOptions{IncludeUninitialized: false}).Times(1).
@@ -933,6 +1708,14 @@ func (s *K8sBrokerSuite) TestEnsureServiceWithStorage(c *gc.C) {
 			Return(nil, s.k8sNotFoundError()),
 		s.mockStatefulSets.EXPECT().Create(statefulSetArg).Times(1).
 			Return(nil, nil),
+		s.mockDeployments.EXPECT().Delete("juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
+		s.mockDaemonSets.EXPECT().Delete("juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
+		s.mockJobs.EXPECT().Delete("juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
+		s.mockServiceAccounts.EXPECT().List(v1.ListOptions{LabelSelector: "juju-application==app-name"}).Times(1).
+			Return(&core.ServiceAccountList{}, nil),
 		s.mockServices.EXPECT().Get("juju-app-name", v1.GetOptions{IncludeUninitialized: true}).Times(1).
 			Return(nil, s.k8sNotFoundError()),
 		s.mockServices.EXPECT().Update(basicServiceArg).Times(1).
@@ -1003,12 +1786,20 @@ func (s *K8sBrokerSuite) TestEnsureServiceForDeploymentWithDevices(c *gc.C) {
 	gomock.InOrder(
 		s.mockSecrets.EXPECT().Update(s.secretArg(c, nil)).Times(1).
 			Return(nil, nil),
+		s.mockSecrets.EXPECT().List(v1.ListOptions{LabelSelector: "juju-application==app-name"}).Times(1).
+			Return(&core.SecretList{}, nil),
 		s.mockStatefulSets.EXPECT().Get("juju-app-name", v1.GetOptions{IncludeUninitialized: true}).Times(1).
 			Return(nil, s.k8sNotFoundError()),
 		s.mockDeployments.EXPECT().Update(deploymentArg).Times(1).
 			Return(nil, s.k8sNotFoundError()),
 		s.mockDeployments.EXPECT().Create(deploymentArg).Times(1).
 			Return(nil, nil),
+		s.mockDaemonSets.EXPECT().Delete("juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
+		s.mockJobs.EXPECT().Delete("juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
+		s.mockServiceAccounts.EXPECT().List(v1.ListOptions{LabelSelector: "juju-application==app-name"}).Times(1).
+			Return(&core.ServiceAccountList{}, nil),
 		s.mockServices.EXPECT().Get("juju-app-name", v1.GetOptions{IncludeUninitialized: true}).Times(1).
 			Return(nil, s.k8sNotFoundError()),
 		s.mockServices.EXPECT().Update(basicServiceArg).Times(1).
@@ -1062,6 +1853,8 @@ func (s *K8sBrokerSuite) TestEnsureServiceForStatefulSetWithDevices(c *gc.C) {
 	gomock.InOrder(
 		s.mockSecrets.EXPECT().Update(s.secretArg(c, nil)).Times(1).
 			Return(nil, nil),
+		s.mockSecrets.EXPECT().List(v1.ListOptions{LabelSelector: "juju-application==app-name"}).Times(1).
+			Return(&core.SecretList{}, nil),
 		s.mockStorageClass.EXPECT().Get("test-juju-unit-storage", v1.GetOptions{IncludeUninitialized: false}).Times(1).
 			Return(nil, s.k8sNotFoundError()),
 		s.mockStorageClass.EXPECT().Get("juju-unit-storage", v1.GetOptions{IncludeUninitialized: false}).Times(1).
@@ -1070,6 +1863,14 @@ func (s *K8sBrokerSuite) TestEnsureServiceForStatefulSetWithDevices(c *gc.C) {
 			Return(nil, s.k8sNotFoundError()),
 		s.mockStatefulSets.EXPECT().Create(statefulSetArg).Times(1).
 			Return(nil, nil),
+		s.mockDeployments.EXPECT().Delete("juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
+		s.mockDaemonSets.EXPECT().Delete("juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
+		s.mockJobs.EXPECT().Delete("juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
+		s.mockServiceAccounts.EXPECT().List(v1.ListOptions{LabelSelector: "juju-application==app-name"}).Times(1).
+			Return(&core.ServiceAccountList{}, nil),
 		s.mockServices.EXPECT().Get("juju-app-name", v1.GetOptions{IncludeUninitialized: true}).Times(1).
 			Return(nil, s.k8sNotFoundError()),
 		s.mockServices.EXPECT().Update(basicServiceArg).Times(1).
@@ -1109,26 +1910,39 @@ func (s *K8sBrokerSuite) TestEnsureServiceWithConstraints(c *gc.C) {
 	ctrl := s.setupBroker(c)
 	defer ctrl.Finish()
 
-	unitSpec, err := provider.MakeUnitSpec("app-name", basicPodspec)
+	// The constraints apply to init containers too.
+	spec := *basicPodspec
+	spec.Containers = append([]caas.ContainerSpec{{
+		Name:  "init",
+		Image: "juju/init",
+		Init:  true,
+	}}, basicPodspec.Containers...)
+	unitSpec, err := provider.MakeUnitSpec("app-name", &spec)
 	c.Assert(err, jc.ErrorIsNil)
 	podSpec := provider.PodSpec(unitSpec)
 	podSpec.Containers[0].VolumeMounts = []core.VolumeMount{{
 		Name:      "juju-database-0",
 		MountPath: "path/to/here",
 	}}
+	constrained := core.ResourceRequirements{
+		Limits: core.ResourceList{
+			"memory": resource.MustParse("64Mi"),
+			"cpu":    resource.MustParse("500m"),
+		},
+	}
+	for i := range podSpec.InitContainers {
+		podSpec.InitContainers[i].Resources = constrained
+	}
 	for i := range podSpec.Containers {
-		podSpec.Containers[i].Resources = core.ResourceRequirements{
-			Limits: core.ResourceList{
-				"memory": resource.MustParse("64Mi"),
-				"cpu":    resource.MustParse("500m"),
-			},
-		}
+		podSpec.Containers[i].Resources = constrained
 	}
 	statefulSetArg := unitStatefulSetArg(2, "juju-unit-storage", podSpec)
 
 	gomock.InOrder(
 		s.mockSecrets.EXPECT().Update(s.secretArg(c, nil)).Times(1).
 			Return(nil, nil),
+		s.mockSecrets.EXPECT().List(v1.ListOptions{LabelSelector: "juju-application==app-name"}).Times(1).
+			Return(&core.SecretList{}, nil),
 		s.mockStorageClass.EXPECT().Get("test-juju-unit-storage", v1.GetOptions{IncludeUninitialized: false}).Times(1).
 			Return(nil, s.k8sNotFoundError()),
 		s.mockStorageClass.EXPECT().Get("juju-unit-storage", v1.GetOptions{IncludeUninitialized: false}).Times(1).
@@ -1137,6 +1951,14 @@ func (s *K8sBrokerSuite) TestEnsureServiceWithConstraints(c *gc.C) {
 			Return(nil, s.k8sNotFoundError()),
 		s.mockStatefulSets.EXPECT().Create(statefulSetArg).Times(1).
 			Return(nil, nil),
+		s.mockDeployments.EXPECT().Delete("juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
+		s.mockDaemonSets.EXPECT().Delete("juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
+		s.mockJobs.EXPECT().Delete("juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
+		s.mockServiceAccounts.EXPECT().List(v1.ListOptions{LabelSelector: "juju-application==app-name"}).Times(1).
+			Return(&core.ServiceAccountList{}, nil),
 		s.mockServices.EXPECT().Get("juju-app-name", v1.GetOptions{IncludeUninitialized: true}).Times(1).
 			Return(nil, s.k8sNotFoundError()),
 		s.mockServices.EXPECT().Update(basicServiceArg).Times(1).
@@ -1146,7 +1968,7 @@ func (s *K8sBrokerSuite) TestEnsureServiceWithConstraints(c *gc.C) {
 	)
 
 	params := &caas.ServiceParams{
-		PodSpec: basicPodspec,
+		PodSpec: &spec,
 		Filesystems: []storage.KubernetesFilesystemParams{{
 			StorageName: "database",
 			Size:        100,
@@ -1183,6 +2005,8 @@ func (s *K8sBrokerSuite) TestEnsureServiceWithPlacement(c *gc.C) {
 	gomock.InOrder(
 		s.mockSecrets.EXPECT().Update(s.secretArg(c, nil)).Times(1).
 			Return(nil, nil),
+		s.mockSecrets.EXPECT().List(v1.ListOptions{LabelSelector: "juju-application==app-name"}).Times(1).
+			Return(&core.SecretList{}, nil),
 		s.mockStorageClass.EXPECT().Get("test-juju-unit-storage", v1.GetOptions{IncludeUninitialized: false}).Times(1).
 			Return(nil, s.k8sNotFoundError()),
 		s.mockStorageClass.EXPECT().Get("juju-unit-storage", v1.GetOptions{IncludeUninitialized: false}).Times(1).
@@ -1191,6 +2015,14 @@ func (s *K8sBrokerSuite) TestEnsureServiceWithPlacement(c *gc.C) {
 			Return(nil, s.k8sNotFoundError()),
 		s.mockStatefulSets.EXPECT().Create(statefulSetArg).Times(1).
 			Return(nil, nil),
+		s.mockDeployments.EXPECT().Delete("juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
+		s.mockDaemonSets.EXPECT().Delete("juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
+		s.mockJobs.EXPECT().Delete("juju-app-name", s.deleteOptions(v1.DeletePropagationForeground)).Times(1).
+			Return(s.k8sNotFoundError()),
+		s.mockServiceAccounts.EXPECT().List(v1.ListOptions{LabelSelector: "juju-application==app-name"}).Times(1).
+			Return(&core.ServiceAccountList{}, nil),
 		s.mockServices.EXPECT().Get("juju-app-name", v1.GetOptions{IncludeUninitialized: true}).Times(1).
 			Return(nil, s.k8sNotFoundError()),
 		s.mockServices.EXPECT().Update(basicServiceArg).Times(1).
@@ -1220,6 +2052,156 @@ func (s *K8sBrokerSuite) TestEnsureServiceWithPlacement(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 }
 
+func (s *K8sBrokerSuite) TestUnitsProbeFailure(c *gc.C) {
+	ctrl := s.setupBroker(c)
+	defer ctrl.Finish()
+
+	pod := core.Pod{
+		ObjectMeta: v1.ObjectMeta{
+			Name: "juju-app-name-0",
+			UID:  "uuid",
+		},
+		Spec: core.PodSpec{
+			Containers: []core.Container{{Name: "test"}},
+		},
+		Status: core.PodStatus{
+			Phase: core.PodRunning,
+			PodIP: "10.0.0.1",
+			ContainerStatuses: []core.ContainerStatus{{
+				Name:  "test",
+				Ready: false,
+				State: core.ContainerState{
+					Running: &core.ContainerStateRunning{
+						StartedAt: v1.NewTime(time.Date(2018, 10, 1, 11, 0, 0, 0, time.UTC)),
+					},
+				},
+			}},
+		},
+	}
+	failedAt := time.Date(2018, 10, 1, 12, 0, 0, 0, time.UTC)
+	probeEvent := func(message string, at time.Time) core.Event {
+		return core.Event{
+			InvolvedObject: core.ObjectReference{FieldPath: "spec.containers{test}"},
+			Message:        message,
+			LastTimestamp:  v1.NewTime(at),
+		}
+	}
+	gomock.InOrder(
+		s.mockPods.EXPECT().List(v1.ListOptions{LabelSelector: "juju-application==app-name"}).Times(1).
+			Return(&core.PodList{Items: []core.Pod{pod}}, nil),
+		s.mockEvents.EXPECT().List(v1.ListOptions{
+			IncludeUninitialized: true,
+			FieldSelector:        "involvedObject.name=juju-app-name-0,reason=Unhealthy",
+		}).Times(1).
+			Return(&core.EventList{Items: []core.Event{
+				probeEvent("Readiness probe failed: HTTP probe failed with statuscode: 500", failedAt),
+				probeEvent("Readiness probe failed: HTTP probe failed with statuscode: 503", failedAt.Add(-time.Minute)),
+				// The container has restarted since this one.
+				probeEvent("Liveness probe failed: connection refused", failedAt.Add(-2*time.Hour)),
+			}}, nil),
+	)
+
+	units, err := s.broker.Units("app-name")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(units, gc.HasLen, 1)
+	c.Assert(units[0].Id, gc.Equals, "uuid")
+	c.Assert(units[0].Status.Status, gc.Equals, status.Error)
+	c.Assert(units[0].Status.Message, gc.Equals, "Readiness probe failed: HTTP probe failed with statuscode: 500")
+	c.Assert(*units[0].Status.Since, gc.Equals, failedAt)
+}
+
+func (s *K8sBrokerSuite) TestUnitsProbeFailureBeforeStart(c *gc.C) {
+	ctrl := s.setupBroker(c)
+	defer ctrl.Finish()
+
+	startedAt := time.Date(2018, 10, 1, 12, 0, 0, 0, time.UTC)
+	pod := core.Pod{
+		ObjectMeta: v1.ObjectMeta{
+			Name: "juju-app-name-0",
+			UID:  "uuid",
+		},
+		Spec: core.PodSpec{
+			Containers: []core.Container{{Name: "test"}},
+		},
+		Status: core.PodStatus{
+			Phase:   core.PodRunning,
+			Message: "running",
+			ContainerStatuses: []core.ContainerStatus{{
+				Name:  "test",
+				Ready: false,
+				State: core.ContainerState{
+					Running: &core.ContainerStateRunning{StartedAt: v1.NewTime(startedAt)},
+				},
+			}, {
+				// Containers which aren't running aren't probed.
+				Name:  "waiting",
+				Ready: false,
+				State: core.ContainerState{
+					Waiting: &core.ContainerStateWaiting{Reason: "ContainerCreating"},
+				},
+			}},
+		},
+	}
+	gomock.InOrder(
+		s.mockPods.EXPECT().List(v1.ListOptions{LabelSelector: "juju-application==app-name"}).Times(1).
+			Return(&core.PodList{Items: []core.Pod{pod}}, nil),
+		s.mockEvents.EXPECT().List(v1.ListOptions{
+			IncludeUninitialized: true,
+			FieldSelector:        "involvedObject.name=juju-app-name-0,reason=Unhealthy",
+		}).Times(1).
+			Return(&core.EventList{Items: []core.Event{{
+				InvolvedObject: core.ObjectReference{FieldPath: "spec.containers{test}"},
+				Message:        "Liveness probe failed: connection refused",
+				LastTimestamp:  v1.NewTime(startedAt.Add(-time.Minute)),
+			}, {
+				InvolvedObject: core.ObjectReference{FieldPath: "spec.containers{waiting}"},
+				Message:        "Readiness probe failed: connection refused",
+				LastTimestamp:  v1.NewTime(startedAt.Add(time.Minute)),
+			}}}, nil),
+	)
+
+	units, err := s.broker.Units("app-name")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(units, gc.HasLen, 1)
+	c.Assert(units[0].Status.Status, gc.Equals, status.Running)
+	c.Assert(units[0].Status.Message, gc.Equals, "running")
+}
+
+func (s *K8sBrokerSuite) TestUnitsNotReadyWithoutRunningContainers(c *gc.C) {
+	ctrl := s.setupBroker(c)
+	defer ctrl.Finish()
+
+	pod := core.Pod{
+		ObjectMeta: v1.ObjectMeta{
+			Name: "juju-app-name-0",
+			UID:  "uuid",
+		},
+		Spec: core.PodSpec{
+			Containers: []core.Container{{Name: "test"}},
+		},
+		Status: core.PodStatus{
+			Phase:   core.PodRunning,
+			Message: "running",
+			ContainerStatuses: []core.ContainerStatus{{
+				Name:  "test",
+				Ready: false,
+				State: core.ContainerState{
+					Waiting: &core.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+				},
+			}},
+		},
+	}
+	// No containers are being probed, so there are no events to get.
+	s.mockPods.EXPECT().List(v1.ListOptions{LabelSelector: "juju-application==app-name"}).Times(1).
+		Return(&core.PodList{Items: []core.Pod{pod}}, nil)
+
+	units, err := s.broker.Units("app-name")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(units, gc.HasLen, 1)
+	c.Assert(units[0].Status.Status, gc.Equals, status.Running)
+	c.Assert(units[0].Status.Message, gc.Equals, "running")
+}
+
 func (s *K8sBrokerSuite) TestOperator(c *gc.C) {
 	ctrl := s.setupBroker(c)
 	defer ctrl.Finish()
@@ -24,19 +24,73 @@ type k8sContainer struct {
 }
 
 type k8sContainers struct {
-	Containers []k8sContainer `json:"containers"`
+	Containers      []k8sContainer        `json:"containers"`
+	CustomResources []caas.CustomResource `json:"customResources,omitempty"`
+}
+
+// Validate returns an error if the container is not valid.
+func (c *k8sContainer) Validate() error {
+	if c.K8sContainerSpec == nil {
+		return nil
+	}
+	if err := c.K8sContainerSpec.Validate(); err != nil {
+		return errors.Annotatef(err, "container %q", c.Name)
+	}
+	if c.Init && (c.LivenessProbe != nil || c.ReadinessProbe != nil) {
+		return errors.NotValidf("probes for init container %q", c.Name)
+	}
+	return nil
 }
 
 // K8sContainerSpec is a subset of v1.Container which defines
 // attributes we expose for charms to set.
 type K8sContainerSpec struct {
-	LivenessProbe   *core.Probe     `json:"livenessProbe,omitempty"`
-	ReadinessProbe  *core.Probe     `json:"readinessProbe,omitempty"`
-	ImagePullPolicy core.PullPolicy `json:"imagePullPolicy,omitempty"`
+	LivenessProbe   *core.Probe                `json:"livenessProbe,omitempty"`
+	ReadinessProbe  *core.Probe                `json:"readinessProbe,omitempty"`
+	ImagePullPolicy core.PullPolicy            `json:"imagePullPolicy,omitempty"`
+	Resources       *core.ResourceRequirements `json:"resources,omitempty"`
 }
 
 // Validate is defined on ProviderContainer.
-func (*K8sContainerSpec) Validate() error {
+func (spec *K8sContainerSpec) Validate() error {
+	if err := validateProbe(spec.LivenessProbe); err != nil {
+		return errors.Annotate(err, "liveness probe")
+	}
+	if err := validateProbe(spec.ReadinessProbe); err != nil {
+		return errors.Annotate(err, "readiness probe")
+	}
+	if spec.Resources != nil {
+		for name, request := range spec.Resources.Requests {
+			limit, ok := spec.Resources.Limits[name]
+			if ok && request.Cmp(limit) > 0 {
+				return errors.NotValidf("%s request %v greater than limit %v", name, request.String(), limit.String())
+			}
+		}
+	}
+	return nil
+}
+
+func validateProbe(probe *core.Probe) error {
+	if probe == nil {
+		return nil
+	}
+	handlers := 0
+	if probe.Exec != nil {
+		handlers++
+	}
+	if probe.HTTPGet != nil {
+		handlers++
+	}
+	if probe.TCPSocket != nil {
+		handlers++
+	}
+	if handlers != 1 {
+		return errors.NotValidf("probe without exactly one of exec, httpGet or tcpSocket")
+	}
+	if probe.InitialDelaySeconds < 0 || probe.TimeoutSeconds < 0 || probe.PeriodSeconds < 0 ||
+		probe.SuccessThreshold < 0 || probe.FailureThreshold < 0 {
+		return errors.NotValidf("probe with negative timings")
+	}
 	return nil
 }
 
@@ -53,7 +107,8 @@ func parseK8sPodSpec(in string) (*caas.PodSpec, error) {
 		return nil, errors.Trace(err)
 	}
 
-	// Do the k8s containers.
+	// Do the k8s containers and custom resources, which are decoded as
+	// JSON so that arbitrary resource specs can be sent to the cluster.
 	var containers k8sContainers
 	decoder := k8syaml.NewYAMLOrJSONDecoder(strings.NewReader(in), len(in))
 	if err := decoder.Decode(&containers); err != nil {
@@ -63,6 +118,13 @@ func parseK8sPodSpec(in string) (*caas.PodSpec, error) {
 	if len(containers.Containers) == 0 {
 		return nil, errors.New("require at least one container spec")
 	}
+	initOnly := true
+	for _, c := range containers.Containers {
+		initOnly = initOnly && c.Init
+	}
+	if initOnly {
+		return nil, errors.New("require at least one container spec which is not an init container")
+	}
 
 	// Any string config values that could be interpreted as bools need to be quoted.
 	for _, container := range containers.Containers {
@@ -93,10 +155,12 @@ func parseK8sPodSpec(in string) (*caas.PodSpec, error) {
 			WorkingDir:   c.WorkingDir,
 			Config:       c.Config,
 			Files:        c.Files,
+			Init:         c.Init,
 		}
 		if c.K8sContainerSpec != nil {
 			spec.Containers[i].ProviderContainer = c.K8sContainerSpec
 		}
 	}
+	spec.CustomResources = containers.CustomResources
 	return &spec, nil
 }
@@ -8,6 +8,7 @@ import (
 	gc "gopkg.in/check.v1"
 	core "k8s.io/api/core/v1"
 	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"github.com/juju/juju/caas"
@@ -295,3 +296,309 @@ containers:
 	err = spec.Validate()
 	c.Assert(err, gc.ErrorMatches, `mount path is missing for file set "configuration"`)
 }
+
+func (s *ContainersSuite) TestParseServiceAccount(c *gc.C) {
+
+	specStr := `
+containers:
+  - name: gitlab
+    image: gitlab/latest
+serviceAccount:
+  automountServiceAccountToken: true
+  rules:
+    - apiGroups: [""]
+      resources: ["pods"]
+      verbs: ["get", "watch", "list"]
+  clusterRules:
+    - nonResourceURLs: ["/healthz"]
+      verbs: ["get"]
+`[1:]
+
+	spec, err := provider.ParseK8sPodSpec(specStr)
+	c.Assert(err, jc.ErrorIsNil)
+	automount := true
+	c.Assert(spec.ServiceAccount, jc.DeepEquals, &caas.ServiceAccountSpec{
+		AutomountServiceAccountToken: &automount,
+		Rules: []caas.PolicyRule{{
+			APIGroups: []string{""},
+			Resources: []string{"pods"},
+			Verbs:     []string{"get", "watch", "list"},
+		}},
+		ClusterRules: []caas.PolicyRule{{
+			NonResourceURLs: []string{"/healthz"},
+			Verbs:           []string{"get"},
+		}},
+	})
+	c.Assert(spec.Validate(), jc.ErrorIsNil)
+}
+
+func (s *ContainersSuite) TestValidateServiceAccountMissingVerbs(c *gc.C) {
+
+	specStr := `
+containers:
+  - name: gitlab
+    image: gitlab/latest
+serviceAccount:
+  rules:
+    - resources: ["pods"]
+`[1:]
+
+	spec, err := provider.ParseK8sPodSpec(specStr)
+	c.Assert(err, jc.ErrorIsNil)
+	err = spec.Validate()
+	c.Assert(err, gc.ErrorMatches, `rule with missing verbs not valid`)
+}
+
+func (s *ContainersSuite) TestValidateServiceAccountNonResourceURLs(c *gc.C) {
+
+	specStr := `
+containers:
+  - name: gitlab
+    image: gitlab/latest
+serviceAccount:
+  rules:
+    - nonResourceURLs: ["/healthz"]
+      verbs: ["get"]
+`[1:]
+
+	spec, err := provider.ParseK8sPodSpec(specStr)
+	c.Assert(err, jc.ErrorIsNil)
+	err = spec.Validate()
+	c.Assert(err, gc.ErrorMatches, `non resource URLs in rules granted within the model not valid`)
+}
+
+func (s *ContainersSuite) TestParseSecretsAndCustomResources(c *gc.C) {
+
+	specStr := `
+containers:
+  - name: gitlab
+    image: gitlab/latest
+secrets:
+  - name: gitlab-db
+    data:
+      username: gitlab
+      password: s3cret
+customResources:
+  - apiVersion: kubeflow.org/v1alpha2
+    kind: TFJob
+    name: train
+    spec:
+      tfReplicaSpecs:
+        Worker:
+          replicas: 3
+          args: ["--epochs", "10"]
+`[1:]
+
+	spec, err := provider.ParseK8sPodSpec(specStr)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(spec.Secrets, jc.DeepEquals, []caas.Secret{{
+		Name: "gitlab-db",
+		Data: map[string]string{"username": "gitlab", "password": "s3cret"},
+	}})
+	c.Assert(spec.CustomResources, jc.DeepEquals, []caas.CustomResource{{
+		APIVersion: "kubeflow.org/v1alpha2",
+		Kind:       "TFJob",
+		Name:       "train",
+		Spec: map[string]interface{}{
+			"tfReplicaSpecs": map[string]interface{}{
+				"Worker": map[string]interface{}{
+					"replicas": float64(3),
+					"args":     []interface{}{"--epochs", "10"},
+				},
+			},
+		},
+	}})
+	c.Assert(spec.Validate(), jc.ErrorIsNil)
+}
+
+func (s *ContainersSuite) TestValidateCustomResourceAPIVersion(c *gc.C) {
+
+	specStr := `
+containers:
+  - name: gitlab
+    image: gitlab/latest
+customResources:
+  - apiVersion: v1alpha2
+    kind: TFJob
+    name: train
+`[1:]
+
+	spec, err := provider.ParseK8sPodSpec(specStr)
+	c.Assert(err, jc.ErrorIsNil)
+	err = spec.Validate()
+	c.Assert(err, gc.ErrorMatches, `custom resource "train" api version "v1alpha2" not valid`)
+}
+
+func (s *ContainersSuite) TestValidateDuplicateSecrets(c *gc.C) {
+
+	specStr := `
+containers:
+  - name: gitlab
+    image: gitlab/latest
+secrets:
+  - name: gitlab-db
+  - name: gitlab-db
+`[1:]
+
+	spec, err := provider.ParseK8sPodSpec(specStr)
+	c.Assert(err, jc.ErrorIsNil)
+	err = spec.Validate()
+	c.Assert(err, gc.ErrorMatches, `duplicate secret name "gitlab-db" not valid`)
+}
+
+func (s *ContainersSuite) TestValidateSecretRelationData(c *gc.C) {
+
+	specStr := `
+containers:
+  - name: gitlab
+    image: gitlab/latest
+secrets:
+  - name: gitlab-db
+    relationData:
+      password: password
+`[1:]
+
+	spec, err := provider.ParseK8sPodSpec(specStr)
+	c.Assert(err, jc.ErrorIsNil)
+	err = spec.Validate()
+	c.Assert(err, gc.ErrorMatches, `secret "gitlab-db" relation data "password": "password" not valid`)
+}
+
+func (s *ContainersSuite) TestParseDeploymentType(c *gc.C) {
+
+	specStr := `
+deploymentType: daemon
+containers:
+  - name: fluentd
+    image: fluentd/latest
+`[1:]
+
+	spec, err := provider.ParseK8sPodSpec(specStr)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(spec.DeploymentType, gc.Equals, caas.DeploymentDaemon)
+	c.Assert(spec.Validate(), jc.ErrorIsNil)
+}
+
+func (s *ContainersSuite) TestValidateDeploymentType(c *gc.C) {
+
+	specStr := `
+deploymentType: cron
+containers:
+  - name: fluentd
+    image: fluentd/latest
+`[1:]
+
+	spec, err := provider.ParseK8sPodSpec(specStr)
+	c.Assert(err, jc.ErrorIsNil)
+	err = spec.Validate()
+	c.Assert(err, gc.ErrorMatches, `deployment type "cron" not valid`)
+}
+
+func (s *ContainersSuite) TestParseInitContainersAndResources(c *gc.C) {
+
+	specStr := `
+containers:
+  - name: migrate
+    init: true
+    image: gitlab/latest
+    command: ["gitlab-rake", "db:migrate"]
+  - name: gitlab
+    image: gitlab/latest
+    resources:
+      requests:
+        cpu: 250m
+        memory: 64Mi
+      limits:
+        memory: 128Mi
+`[1:]
+
+	spec, err := provider.ParseK8sPodSpec(specStr)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(spec.Validate(), jc.ErrorIsNil)
+	c.Assert(spec.Containers, gc.HasLen, 2)
+	c.Assert(spec.Containers[0].Init, jc.IsTrue)
+	c.Assert(spec.Containers[1].Init, jc.IsFalse)
+	c.Assert(spec.Containers[1].ProviderContainer, jc.DeepEquals, &provider.K8sContainerSpec{
+		Resources: &core.ResourceRequirements{
+			Requests: core.ResourceList{
+				core.ResourceCPU:    resource.MustParse("250m"),
+				core.ResourceMemory: resource.MustParse("64Mi"),
+			},
+			Limits: core.ResourceList{
+				core.ResourceMemory: resource.MustParse("128Mi"),
+			},
+		},
+	})
+
+	unitSpec, err := provider.MakeUnitSpec("app-name", spec)
+	c.Assert(err, jc.ErrorIsNil)
+	pod := provider.PodSpec(unitSpec)
+	c.Assert(pod.InitContainers, gc.HasLen, 1)
+	c.Assert(pod.InitContainers[0].Name, gc.Equals, "migrate")
+	c.Assert(pod.InitContainers[0].Command, jc.DeepEquals, []string{"gitlab-rake", "db:migrate"})
+	c.Assert(pod.Containers, gc.HasLen, 1)
+	c.Assert(pod.Containers[0].Name, gc.Equals, "gitlab")
+	c.Assert(pod.Containers[0].Resources.Limits[core.ResourceMemory], jc.DeepEquals, resource.MustParse("128Mi"))
+}
+
+func (s *ContainersSuite) TestValidateOnlyInitContainers(c *gc.C) {
+
+	specStr := `
+containers:
+  - name: migrate
+    init: true
+    image: gitlab/latest
+`[1:]
+
+	_, err := provider.ParseK8sPodSpec(specStr)
+	c.Assert(err, gc.ErrorMatches, "require at least one container spec which is not an init container")
+}
+
+func (s *ContainersSuite) TestValidateInitContainerProbes(c *gc.C) {
+
+	specStr := `
+containers:
+  - name: migrate
+    init: true
+    image: gitlab/latest
+    livenessProbe:
+      exec:
+        command: ["true"]
+  - name: gitlab
+    image: gitlab/latest
+`[1:]
+
+	_, err := provider.ParseK8sPodSpec(specStr)
+	c.Assert(err, gc.ErrorMatches, `probes for init container "migrate" not valid`)
+}
+
+func (s *ContainersSuite) TestValidateProbeHandler(c *gc.C) {
+
+	specStr := `
+containers:
+  - name: gitlab
+    image: gitlab/latest
+    readinessProbe:
+      initialDelaySeconds: 10
+`[1:]
+
+	_, err := provider.ParseK8sPodSpec(specStr)
+	c.Assert(err, gc.ErrorMatches, `container "gitlab": readiness probe: probe without exactly one of exec, httpGet or tcpSocket not valid`)
+}
+
+func (s *ContainersSuite) TestValidateResourceRequestOverLimit(c *gc.C) {
+
+	specStr := `
+containers:
+  - name: gitlab
+    image: gitlab/latest
+    resources:
+      requests:
+        memory: 256Mi
+      limits:
+        memory: 128Mi
+`[1:]
+
+	_, err := provider.ParseK8sPodSpec(specStr)
+	c.Assert(err, gc.ErrorMatches, `container "gitlab": memory request 256Mi greater than limit 128Mi not valid`)
+}
@@ -0,0 +1,295 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package provider
+
+import (
+	"fmt"
+
+	"github.com/juju/errors"
+	core "k8s.io/api/core/v1"
+	rbac "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/juju/juju/caas"
+)
+
+// ensureServiceAccount creates or updates the service account the
+// application's pods run as, along with the roles and role bindings
+// granting it the rules in the spec. Roles and bindings for rules no
+// longer in the spec are deleted. It returns functions which delete
+// the objects it created, to clean up if deploying the application
+// fails; objects which already existed are left alone.
+func (k *kubernetesClient) ensureServiceAccount(
+	appName string, spec *caas.ServiceAccountSpec, labels map[string]string,
+) (cleanups []func(), _ error) {
+	name := serviceAccountName(appName)
+	options := &v1.DeleteOptions{
+		PropagationPolicy: &defaultPropagationPolicy,
+	}
+	rbacClient := k.RbacV1()
+
+	accounts := k.CoreV1().ServiceAccounts(k.namespace)
+	account := &core.ServiceAccount{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      name,
+			Namespace: k.namespace,
+			Labels:    labels,
+		},
+		AutomountServiceAccountToken: spec.AutomountServiceAccountToken,
+	}
+	created, err := ensureObject(func() error {
+		_, err := accounts.Update(account)
+		return err
+	}, func() error {
+		_, err := accounts.Create(account)
+		return err
+	})
+	if err != nil {
+		return cleanups, errors.Annotate(err, "creating or updating service account")
+	}
+	if created {
+		cleanups = append(cleanups, func() { accounts.Delete(name, options) })
+	}
+
+	subjects := []rbac.Subject{{
+		Kind:      rbac.ServiceAccountKind,
+		Name:      name,
+		Namespace: k.namespace,
+	}}
+	if len(spec.Rules) > 0 {
+		roles := rbacClient.Roles(k.namespace)
+		role := &rbac.Role{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      name,
+				Namespace: k.namespace,
+				Labels:    labels,
+			},
+			Rules: policyRules(spec.Rules),
+		}
+		created, err := ensureObject(func() error {
+			_, err := roles.Update(role)
+			return err
+		}, func() error {
+			_, err := roles.Create(role)
+			return err
+		})
+		if err != nil {
+			return cleanups, errors.Annotate(err, "creating or updating role")
+		}
+		if created {
+			cleanups = append(cleanups, func() { roles.Delete(name, options) })
+		}
+
+		bindings := rbacClient.RoleBindings(k.namespace)
+		binding := &rbac.RoleBinding{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      name,
+				Namespace: k.namespace,
+				Labels:    labels,
+			},
+			RoleRef: rbac.RoleRef{
+				APIGroup: rbac.GroupName,
+				Kind:     "Role",
+				Name:     name,
+			},
+			Subjects: subjects,
+		}
+		created, err = ensureObject(func() error {
+			_, err := bindings.Update(binding)
+			return err
+		}, func() error {
+			_, err := bindings.Create(binding)
+			return err
+		})
+		if err != nil {
+			return cleanups, errors.Annotate(err, "creating or updating role binding")
+		}
+		if created {
+			cleanups = append(cleanups, func() { bindings.Delete(name, options) })
+		}
+	} else if err := k.deleteRole(name); err != nil {
+		return cleanups, errors.Trace(err)
+	}
+
+	// Cluster roles aren't namespaced, so they're named and labelled
+	// for the model as well as the application.
+	clusterName := clusterRoleName(k.namespace, appName)
+	clusterLabels := map[string]string{labelModel: k.namespace}
+	for key, value := range labels {
+		clusterLabels[key] = value
+	}
+	if len(spec.ClusterRules) > 0 {
+		roles := rbacClient.ClusterRoles()
+		role := &rbac.ClusterRole{
+			ObjectMeta: v1.ObjectMeta{
+				Name:   clusterName,
+				Labels: clusterLabels,
+			},
+			Rules: policyRules(spec.ClusterRules),
+		}
+		created, err := ensureObject(func() error {
+			_, err := roles.Update(role)
+			return err
+		}, func() error {
+			_, err := roles.Create(role)
+			return err
+		})
+		if err != nil {
+			return cleanups, errors.Annotate(err, "creating or updating cluster role")
+		}
+		if created {
+			cleanups = append(cleanups, func() { roles.Delete(clusterName, options) })
+		}
+
+		bindings := rbacClient.ClusterRoleBindings()
+		binding := &rbac.ClusterRoleBinding{
+			ObjectMeta: v1.ObjectMeta{
+				Name:   clusterName,
+				Labels: clusterLabels,
+			},
+			RoleRef: rbac.RoleRef{
+				APIGroup: rbac.GroupName,
+				Kind:     "ClusterRole",
+				Name:     clusterName,
+			},
+			Subjects: subjects,
+		}
+		created, err = ensureObject(func() error {
+			_, err := bindings.Update(binding)
+			return err
+		}, func() error {
+			_, err := bindings.Create(binding)
+			return err
+		})
+		if err != nil {
+			return cleanups, errors.Annotate(err, "creating or updating cluster role binding")
+		}
+		if created {
+			cleanups = append(cleanups, func() { bindings.Delete(clusterName, options) })
+		}
+	} else if err := k.deleteClusterRole(clusterName); err != nil {
+		return cleanups, errors.Trace(err)
+	}
+	return cleanups, nil
+}
+
+// ensureObject calls update, or create if there's no object to update,
+// and reports whether the object was created.
+func ensureObject(update, create func() error) (bool, error) {
+	err := update()
+	if err == nil {
+		return false, nil
+	} else if !k8serrors.IsNotFound(err) {
+		return false, errors.Trace(err)
+	}
+	if err := create(); err != nil {
+		return false, errors.Trace(err)
+	}
+	return true, nil
+}
+
+// removeServiceAccount deletes the service account, roles and role
+// bindings labelled with the application, if it has a service account.
+// It's used when the application's pod spec no longer defines one.
+func (k *kubernetesClient) removeServiceAccount(appName string) error {
+	accounts, err := k.CoreV1().ServiceAccounts(k.namespace).List(v1.ListOptions{
+		LabelSelector: applicationSelector(appName),
+	})
+	if err != nil {
+		return errors.Annotate(err, "listing service accounts")
+	}
+	if len(accounts.Items) == 0 {
+		return nil
+	}
+	return errors.Trace(k.deleteServiceAccounts(appName))
+}
+
+// deleteRole deletes the named role and its binding.
+func (k *kubernetesClient) deleteRole(name string) error {
+	options := &v1.DeleteOptions{
+		PropagationPolicy: &defaultPropagationPolicy,
+	}
+	err := k.RbacV1().RoleBindings(k.namespace).Delete(name, options)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return errors.Annotate(err, "deleting role binding")
+	}
+	err = k.RbacV1().Roles(k.namespace).Delete(name, options)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return errors.Annotate(err, "deleting role")
+	}
+	return nil
+}
+
+// deleteClusterRole deletes the named cluster role and its binding.
+func (k *kubernetesClient) deleteClusterRole(name string) error {
+	options := &v1.DeleteOptions{
+		PropagationPolicy: &defaultPropagationPolicy,
+	}
+	err := k.RbacV1().ClusterRoleBindings().Delete(name, options)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return errors.Annotate(err, "deleting cluster role binding")
+	}
+	err = k.RbacV1().ClusterRoles().Delete(name, options)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return errors.Annotate(err, "deleting cluster role")
+	}
+	return nil
+}
+
+// deleteServiceAccounts deletes the service account, roles and role
+// bindings labelled with the application.
+func (k *kubernetesClient) deleteServiceAccounts(appName string) error {
+	options := &v1.DeleteOptions{
+		PropagationPolicy: &defaultPropagationPolicy,
+	}
+	appSelector := v1.ListOptions{LabelSelector: applicationSelector(appName)}
+	clusterSelector := v1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s,%s==%s", applicationSelector(appName), labelModel, k.namespace),
+	}
+	rbacClient := k.RbacV1()
+	err := rbacClient.ClusterRoleBindings().DeleteCollection(options, clusterSelector)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return errors.Annotate(err, "deleting cluster role bindings")
+	}
+	err = rbacClient.ClusterRoles().DeleteCollection(options, clusterSelector)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return errors.Annotate(err, "deleting cluster roles")
+	}
+	err = rbacClient.RoleBindings(k.namespace).DeleteCollection(options, appSelector)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return errors.Annotate(err, "deleting role bindings")
+	}
+	err = rbacClient.Roles(k.namespace).DeleteCollection(options, appSelector)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return errors.Annotate(err, "deleting roles")
+	}
+	err = k.CoreV1().ServiceAccounts(k.namespace).DeleteCollection(options, appSelector)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return errors.Annotate(err, "deleting service accounts")
+	}
+	return nil
+}
+
+func policyRules(rules []caas.PolicyRule) []rbac.PolicyRule {
+	result := make([]rbac.PolicyRule, len(rules))
+	for i, r := range rules {
+		result[i] = rbac.PolicyRule{
+			Verbs:           r.Verbs,
+			APIGroups:       r.APIGroups,
+			Resources:       r.Resources,
+			ResourceNames:   r.ResourceNames,
+			NonResourceURLs: r.NonResourceURLs,
+		}
+	}
+	return result
+}
+
+func serviceAccountName(appName string) string {
+	return deploymentName(appName)
+}
+
+func clusterRoleName(namespace, appName string) string {
+	return namespace + "-" + deploymentName(appName)
+}
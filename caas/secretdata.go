@@ -0,0 +1,97 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package caas
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/juju/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// SecretDataSource supplies the charm config and relation data which
+// the secrets in pod specs take their data from.
+type SecretDataSource interface {
+	// ConfigValue returns the value of the charm config option, and
+	// whether it's set.
+	ConfigValue(option string) (string, bool, error)
+
+	// RelationValue returns the value of the setting from the first
+	// unit, in name order, related over the endpoint which has set
+	// it, and whether one has.
+	RelationValue(endpoint, setting string) (string, bool, error)
+}
+
+// ResolveSecretData returns the pod spec with the data its secrets take
+// from charm config and relation data filled in. It's done as the charm
+// sets the pod spec, so the data is current each time it's set. Data
+// whose config option or relation setting isn't set yet is left out.
+func ResolveSecretData(specYaml string, source SecretDataSource) (string, error) {
+	var spec map[string]interface{}
+	if err := yaml.Unmarshal([]byte(specYaml), &spec); err != nil {
+		return "", errors.Trace(err)
+	}
+	secrets, _ := spec["secrets"].([]interface{})
+	resolved := false
+	for _, item := range secrets {
+		secret, ok := item.(map[interface{}]interface{})
+		if !ok {
+			continue
+		}
+		configData, _ := secret["configData"].(map[interface{}]interface{})
+		relationData, _ := secret["relationData"].(map[interface{}]interface{})
+		if configData == nil && relationData == nil {
+			continue
+		}
+		data, _ := secret["data"].(map[interface{}]interface{})
+		if data == nil {
+			data = make(map[interface{}]interface{})
+		}
+		for key, option := range configData {
+			value, ok, err := source.ConfigValue(fmt.Sprint(option))
+			if err != nil {
+				return "", errors.Annotatef(err, "secret %v config data %v", secret["name"], key)
+			}
+			if ok {
+				data[key] = value
+			}
+		}
+		for key, setting := range relationData {
+			endpoint, name, err := parseRelationSetting(fmt.Sprint(setting))
+			if err != nil {
+				return "", errors.Annotatef(err, "secret %v relation data %v", secret["name"], key)
+			}
+			value, ok, err := source.RelationValue(endpoint, name)
+			if err != nil {
+				return "", errors.Annotatef(err, "secret %v relation data %v", secret["name"], key)
+			}
+			if ok {
+				data[key] = value
+			}
+		}
+		delete(secret, "configData")
+		delete(secret, "relationData")
+		secret["data"] = data
+		resolved = true
+	}
+	if !resolved {
+		return specYaml, nil
+	}
+	out, err := yaml.Marshal(spec)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	return string(out), nil
+}
+
+// parseRelationSetting returns the endpoint and setting named by a
+// relation setting, written as "<endpoint>:<setting>".
+func parseRelationSetting(setting string) (string, string, error) {
+	parts := strings.SplitN(setting, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", errors.NotValidf("relation setting %q", setting)
+	}
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package caas_test
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/yaml.v2"
+
+	"github.com/juju/juju/caas"
+)
+
+type SecretDataSuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&SecretDataSuite{})
+
+type fakeSecretDataSource struct {
+	config   map[string]string
+	relation map[string]string
+	err      error
+}
+
+func (f *fakeSecretDataSource) ConfigValue(option string) (string, bool, error) {
+	value, ok := f.config[option]
+	return value, ok, f.err
+}
+
+func (f *fakeSecretDataSource) RelationValue(endpoint, setting string) (string, bool, error) {
+	value, ok := f.relation[endpoint+":"+setting]
+	return value, ok, f.err
+}
+
+func (s *SecretDataSuite) TestResolveSecretData(c *gc.C) {
+	specStr := `
+containers:
+  - name: gitlab
+    image: gitlab/latest
+secrets:
+  - name: gitlab-db
+    data:
+      username: gitlab
+    configData:
+      password: db-password
+      missing: unset-option
+    relationData:
+      host: db:host
+`[1:]
+	source := &fakeSecretDataSource{
+		config:   map[string]string{"db-password": "s3cret"},
+		relation: map[string]string{"db:host": "10.0.0.1"},
+	}
+	out, err := caas.ResolveSecretData(specStr, source)
+	c.Assert(err, jc.ErrorIsNil)
+
+	var spec struct {
+		Secrets []map[string]interface{} `yaml:"secrets"`
+	}
+	err = yaml.Unmarshal([]byte(out), &spec)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(spec.Secrets, jc.DeepEquals, []map[string]interface{}{{
+		"name": "gitlab-db",
+		"data": map[interface{}]interface{}{
+			"username": "gitlab",
+			"password": "s3cret",
+			"host":     "10.0.0.1",
+		},
+	}})
+}
+
+func (s *SecretDataSuite) TestResolveSecretDataUnchanged(c *gc.C) {
+	specStr := `
+containers:
+  - name: gitlab
+    image: gitlab/latest
+secrets:
+  - name: gitlab-db
+    data:
+      username: gitlab
+`[1:]
+	out, err := caas.ResolveSecretData(specStr, &fakeSecretDataSource{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(out, gc.Equals, specStr)
+}
+
+func (s *SecretDataSuite) TestResolveSecretDataError(c *gc.C) {
+	specStr := `
+secrets:
+  - name: gitlab-db
+    configData:
+      password: db-password
+`[1:]
+	_, err := caas.ResolveSecretData(specStr, &fakeSecretDataSource{err: errors.New("boom")})
+	c.Assert(err, gc.ErrorMatches, `secret gitlab-db config data password: boom`)
+}
+
+func (s *SecretDataSuite) TestResolveSecretDataInvalidRelationSetting(c *gc.C) {
+	specStr := `
+secrets:
+  - name: gitlab-db
+    relationData:
+      host: host
+`[1:]
+	_, err := caas.ResolveSecretData(specStr, &fakeSecretDataSource{})
+	c.Assert(err, gc.ErrorMatches, `secret gitlab-db relation data host: relation setting "host" not valid`)
+}
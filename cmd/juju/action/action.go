@@ -60,6 +60,26 @@ type APIClient interface {
 	// FindActionsByNames takes a list of names and finds a corresponding list of
 	// Actions for every name.
 	FindActionsByNames(params.FindActionsByNames) (params.ActionsByNames, error)
+
+	// ScheduleActions adds schedules on which actions are queued,
+	// either once at a given time or repeatedly on a cron-style
+	// schedule.
+	ScheduleActions(params.ActionSchedules) (params.ActionScheduleResults, error)
+
+	// ActionSchedules returns all of the action schedules in the model.
+	ActionSchedules() (params.ActionScheduleResults, error)
+
+	// RemoveActionSchedules removes the action schedules with the
+	// given IDs.
+	RemoveActionSchedules(params.ActionScheduleIds) (params.ErrorResults, error)
+
+	// EnqueueOperation queues an action on a set of receivers in
+	// batches, as a single operation.
+	EnqueueOperation(params.OperationArgs) (params.OperationResult, error)
+
+	// Operations returns the operations with the given IDs, or all of
+	// the operations in the model if no IDs are given.
+	Operations(params.OperationIds) (params.OperationResults, error)
 }
 
 // ActionCommandBase is the base type for action sub-commands.
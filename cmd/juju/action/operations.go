@@ -0,0 +1,149 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package action
+
+import (
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/gnuflag"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/params"
+	jujucmd "github.com/juju/juju/cmd"
+	"github.com/juju/juju/cmd/modelcmd"
+	"github.com/juju/juju/cmd/output"
+)
+
+func NewShowOperationCommand() cmd.Command {
+	return modelcmd.Wrap(&showOperationCommand{})
+}
+
+// showOperationCommand shows the progress of an operation.
+type showOperationCommand struct {
+	ActionCommandBase
+	id  string
+	out cmd.Output
+}
+
+const showOperationDoc = `
+Show the progress of an operation, which queues an action on many units in
+batches. Operations are started with the --max-parallel option of
+'juju run-action' and 'juju run'.
+
+The action IDs listed may be used with 'juju show-action-output <ID>'. A unit
+on which the action couldn't be queued, for example because it was removed,
+is listed with the reason instead, and counts as a failure.
+
+Examples:
+
+    juju show-operation 1
+
+See also:
+    run-action
+    run
+    show-action-output
+`
+
+// SetFlags offers YAML and JSON output.
+func (c *showOperationCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.ActionCommandBase.SetFlags(f)
+	c.out.AddFlags(f, "yaml", output.DefaultFormatters)
+}
+
+func (c *showOperationCommand) Info() *cmd.Info {
+	return jujucmd.Info(&cmd.Info{
+		Name:    "show-operation",
+		Args:    "<operation ID>",
+		Purpose: "Show the progress of an operation.",
+		Doc:     showOperationDoc,
+	})
+}
+
+func (c *showOperationCommand) Init(args []string) error {
+	switch len(args) {
+	case 0:
+		return errors.New("no operation ID specified")
+	case 1:
+		c.id = args[0]
+		return nil
+	default:
+		return cmd.CheckEmpty(args[1:])
+	}
+}
+
+type operationActionOutput struct {
+	Receiver string `yaml:"receiver" json:"receiver"`
+	Action   string `yaml:"action,omitempty" json:"action,omitempty"`
+	Error    string `yaml:"error,omitempty" json:"error,omitempty"`
+}
+
+type operationOutput struct {
+	ID          string                  `yaml:"id" json:"id"`
+	Action      string                  `yaml:"action" json:"action"`
+	Parameters  map[string]interface{}  `yaml:"parameters,omitempty" json:"parameters,omitempty"`
+	Status      string                  `yaml:"status" json:"status"`
+	MaxParallel int                     `yaml:"max-parallel,omitempty" json:"max-parallel,omitempty"`
+	MaxFailures int                     `yaml:"max-failures,omitempty" json:"max-failures,omitempty"`
+	Receivers   int                     `yaml:"receivers" json:"receivers"`
+	Completed   int                     `yaml:"completed" json:"completed"`
+	Failed      int                     `yaml:"failed" json:"failed"`
+	Created     string                  `yaml:"created" json:"created"`
+	Finished    string                  `yaml:"finished,omitempty" json:"finished,omitempty"`
+	Actions     []operationActionOutput `yaml:"actions,omitempty" json:"actions,omitempty"`
+}
+
+func (c *showOperationCommand) Run(ctx *cmd.Context) error {
+	api, err := c.NewActionAPIClient()
+	if err != nil {
+		return err
+	}
+	defer api.Close()
+
+	results, err := api.Operations(params.OperationIds{Ids: []string{c.id}})
+	if err != nil {
+		return err
+	}
+	if len(results.Results) != 1 {
+		return errors.New("illegal number of results returned")
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.Operation == nil {
+		return errors.NotFoundf("operation %q", c.id)
+	}
+	return c.out.Write(ctx, makeOperationOutput(*result.Operation))
+}
+
+func makeOperationOutput(op params.Operation) operationOutput {
+	result := operationOutput{
+		ID:          op.Id,
+		Action:      op.Name,
+		Parameters:  op.Parameters,
+		Status:      op.Status,
+		MaxParallel: op.MaxParallel,
+		MaxFailures: op.MaxFailures,
+		Receivers:   len(op.Receivers),
+		Completed:   op.Completed,
+		Failed:      op.Failed,
+		Created:     op.Created.UTC().Format(time.RFC3339),
+	}
+	if op.Finished != nil {
+		result.Finished = op.Finished.UTC().Format(time.RFC3339)
+	}
+	for _, action := range op.Actions {
+		actionOutput := operationActionOutput{
+			Receiver: receiverName(action.Receiver),
+			Error:    action.Error,
+		}
+		if tag, err := names.ParseActionTag(action.Action); err == nil {
+			actionOutput.Action = tag.Id()
+		}
+		result.Actions = append(result.Actions, actionOutput)
+	}
+	return result
+}
@@ -0,0 +1,195 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package action_test
+
+import (
+	"time"
+
+	"github.com/juju/cmd/cmdtesting"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/yaml.v2"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/juju/action"
+)
+
+type OperationsSuite struct {
+	BaseActionSuite
+}
+
+var _ = gc.Suite(&OperationsSuite{})
+
+func (s *OperationsSuite) TestRunBatched(c *gc.C) {
+	fakeClient := &fakeAPIClient{
+		apiVersion: 4,
+		operationResults: []params.OperationResult{{
+			Operation: &params.Operation{
+				Id:     "1",
+				Status: "running",
+				Actions: []params.OperationAction{{
+					Receiver: "unit-mysql-0",
+					Action:   validActionTagString,
+				}},
+			},
+		}},
+	}
+	restore := s.patchAPIClient(fakeClient)
+	defer restore()
+
+	wrappedCommand, _ := action.NewRunCommandForTest(s.store)
+	ctx, err := cmdtesting.RunCommand(c, wrappedCommand,
+		"-m", "admin", "mysql/0", "mysql/1", "restart", "--batch-size", "1", "--max-failures", "1", "delay=5")
+	c.Assert(err, jc.ErrorIsNil)
+	var out map[string]interface{}
+	err = yaml.Unmarshal([]byte(cmdtesting.Stdout(ctx)), &out)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(out, jc.DeepEquals, map[string]interface{}{
+		"operation": "1",
+		"status":    "running",
+		"queued": map[interface{}]interface{}{
+			"mysql/0": validActionId,
+		},
+	})
+	c.Check(fakeClient.enqueuedOperation, jc.DeepEquals, params.OperationArgs{
+		Receivers:   []string{"unit-mysql-0", "unit-mysql-1"},
+		Name:        "restart",
+		Parameters:  map[string]interface{}{"delay": 5},
+		MaxParallel: 1,
+		MaxFailures: 1,
+	})
+	c.Check(fakeClient.EnqueuedActions().Actions, gc.HasLen, 0)
+}
+
+func (s *OperationsSuite) TestRunBatchedError(c *gc.C) {
+	fakeClient := &fakeAPIClient{
+		apiVersion: 4,
+		operationResults: []params.OperationResult{{
+			Error: &params.Error{Message: `action "restart" not defined on unit "mysql/1"`},
+		}},
+	}
+	restore := s.patchAPIClient(fakeClient)
+	defer restore()
+
+	wrappedCommand, _ := action.NewRunCommandForTest(s.store)
+	_, err := cmdtesting.RunCommand(c, wrappedCommand,
+		"-m", "admin", "mysql/0", "mysql/1", "restart", "--max-parallel", "1")
+	c.Assert(err, gc.ErrorMatches, `action "restart" not defined on unit "mysql/1"`)
+}
+
+func (s *OperationsSuite) TestRunBatchedOldController(c *gc.C) {
+	restore := s.patchAPIClient(&fakeAPIClient{apiVersion: 3})
+	defer restore()
+
+	wrappedCommand, _ := action.NewRunCommandForTest(s.store)
+	_, err := cmdtesting.RunCommand(c, wrappedCommand,
+		"-m", "admin", validUnitId, "restart", "--max-parallel", "1")
+	c.Assert(err, gc.ErrorMatches, "batched actions are unsupported by this API\nupgrade your controller to queue actions in batches")
+}
+
+func (s *OperationsSuite) TestRunBatchedInit(c *gc.C) {
+	for i, test := range []struct {
+		args []string
+		err  string
+	}{{
+		args: []string{"--max-parallel", "-1"},
+		err:  "--max-parallel must not be negative",
+	}, {
+		args: []string{"--max-parallel", "1", "--max-failures", "-1"},
+		err:  "--max-failures must not be negative",
+	}, {
+		args: []string{"--max-failures", "1"},
+		err:  "--max-failures requires --max-parallel",
+	}, {
+		args: []string{"--max-parallel", "1", "--schedule", "@daily"},
+		err:  "cannot specify --max-parallel with --at or --schedule",
+	}, {
+		args: []string{"--max-parallel", "1", "--wait"},
+		err:  "cannot wait for the results of a batched action, use 'juju show-operation'",
+	}} {
+		c.Logf("test %d: %v", i, test.args)
+		wrappedCommand, _ := action.NewRunCommandForTest(s.store)
+		args := append([]string{"-m", "admin", validUnitId, "restart"}, test.args...)
+		err := cmdtesting.InitCommand(wrappedCommand, args)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *OperationsSuite) TestShowOperation(c *gc.C) {
+	created := time.Date(2018, 7, 1, 2, 30, 0, 0, time.UTC)
+	fakeClient := &fakeAPIClient{
+		operationResults: []params.OperationResult{{
+			Operation: &params.Operation{
+				Id:          "1",
+				Name:        "restart",
+				Receivers:   []string{"unit-mysql-0", "unit-mysql-1", "unit-mysql-2"},
+				MaxParallel: 1,
+				MaxFailures: 1,
+				Status:      "stopped",
+				Completed:   2,
+				Failed:      1,
+				Created:     created,
+				Finished:    &created,
+				Actions: []params.OperationAction{{
+					Receiver: "unit-mysql-0",
+					Action:   validActionTagString,
+				}, {
+					Receiver: "unit-mysql-1",
+					Error:    "unit not found",
+				}},
+			},
+		}},
+	}
+	restore := s.patchAPIClient(fakeClient)
+	defer restore()
+
+	ctx, err := cmdtesting.RunCommand(c, action.NewShowOperationCommandForTest(s.store), "-m", "admin", "1")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(fakeClient.operationIds, jc.DeepEquals, params.OperationIds{Ids: []string{"1"}})
+	var out map[string]interface{}
+	err = yaml.Unmarshal([]byte(cmdtesting.Stdout(ctx)), &out)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(out, jc.DeepEquals, map[string]interface{}{
+		"id":           "1",
+		"action":       "restart",
+		"status":       "stopped",
+		"max-parallel": 1,
+		"max-failures": 1,
+		"receivers":    3,
+		"completed":    2,
+		"failed":       1,
+		"created":      "2018-07-01T02:30:00Z",
+		"finished":     "2018-07-01T02:30:00Z",
+		"actions": []interface{}{
+			map[interface{}]interface{}{
+				"receiver": "mysql/0",
+				"action":   validActionId,
+			},
+			map[interface{}]interface{}{
+				"receiver": "mysql/1",
+				"error":    "unit not found",
+			},
+		},
+	})
+}
+
+func (s *OperationsSuite) TestShowOperationNotFound(c *gc.C) {
+	fakeClient := &fakeAPIClient{
+		operationResults: []params.OperationResult{{
+			Error: &params.Error{Message: `operation "42" not found`, Code: params.CodeNotFound},
+		}},
+	}
+	restore := s.patchAPIClient(fakeClient)
+	defer restore()
+
+	_, err := cmdtesting.RunCommand(c, action.NewShowOperationCommandForTest(s.store), "-m", "admin", "42")
+	c.Assert(err, gc.ErrorMatches, `operation "42" not found`)
+}
+
+func (s *OperationsSuite) TestShowOperationInit(c *gc.C) {
+	_, err := cmdtesting.RunCommand(c, action.NewShowOperationCommandForTest(s.store), "-m", "admin")
+	c.Assert(err, gc.ErrorMatches, "no operation ID specified")
+	_, err = cmdtesting.RunCommand(c, action.NewShowOperationCommandForTest(s.store), "-m", "admin", "1", "2")
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["2"\]`)
+}
@@ -130,6 +130,13 @@ type fakeAPIClient struct {
 	actionTagMatches   params.FindTagsResults
 	actionsByNames     params.ActionsByNames
 	charmActions       map[string]params.ActionSpec
+	scheduledActions   params.ActionSchedules
+	scheduleResults    []params.ActionScheduleResult
+	removedSchedules   params.ActionScheduleIds
+	removeResults      []params.ErrorResult
+	enqueuedOperation  params.OperationArgs
+	operationIds       params.OperationIds
+	operationResults   []params.OperationResult
 	apiVersion         int
 	apiErr             error
 }
@@ -217,3 +224,31 @@ func (c *fakeAPIClient) FindActionTagsByPrefix(arg params.FindTags) (params.Find
 func (c *fakeAPIClient) FindActionsByNames(args params.FindActionsByNames) (params.ActionsByNames, error) {
 	return c.actionsByNames, c.apiErr
 }
+
+func (c *fakeAPIClient) ScheduleActions(args params.ActionSchedules) (params.ActionScheduleResults, error) {
+	c.scheduledActions = args
+	return params.ActionScheduleResults{Results: c.scheduleResults}, c.apiErr
+}
+
+func (c *fakeAPIClient) ActionSchedules() (params.ActionScheduleResults, error) {
+	return params.ActionScheduleResults{Results: c.scheduleResults}, c.apiErr
+}
+
+func (c *fakeAPIClient) RemoveActionSchedules(args params.ActionScheduleIds) (params.ErrorResults, error) {
+	c.removedSchedules = args
+	return params.ErrorResults{Results: c.removeResults}, c.apiErr
+}
+
+func (c *fakeAPIClient) EnqueueOperation(args params.OperationArgs) (params.OperationResult, error) {
+	c.enqueuedOperation = args
+	var result params.OperationResult
+	if len(c.operationResults) > 0 {
+		result = c.operationResults[0]
+	}
+	return result, c.apiErr
+}
+
+func (c *fakeAPIClient) Operations(args params.OperationIds) (params.OperationResults, error) {
+	c.operationIds = args
+	return params.OperationResults{Results: c.operationResults}, c.apiErr
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/juju/juju/cmd/juju/common"
 	"github.com/juju/juju/cmd/modelcmd"
 	"github.com/juju/juju/cmd/output"
+	"github.com/juju/juju/core/actions"
 )
 
 // leaderSnippet is a regular expression for unit ID-like syntax that is used
@@ -46,6 +47,11 @@ type runCommand struct {
 	paramsYAML    cmd.FileVar
 	parseStrings  bool
 	wait          waitFlag
+	atString      string
+	at            time.Time
+	schedule      string
+	maxParallel   int
+	maxFailures   int
 	out           cmd.Output
 	args          [][]string
 }
@@ -138,6 +144,34 @@ $ juju run-action sleeper/0 pause time=1000
 $ juju run-action sleeper/0 pause --string-args time=1000
 ...
 The value for the "time" param will be the string literal "1000".
+
+Instead of being queued straight away, an action may be queued once at a
+given time with --at, or repeatedly on a cron-style schedule with --schedule.
+Schedules have five fields: minute, hour, day of month, month and day of
+week, and are evaluated in UTC. The schedule ID is returned for use with
+'juju action-schedules' and 'juju remove-action-schedule <ID>'.
+
+$ juju run-action mysql/leader backup --at 2018-07-01T02:00:00Z
+mysql/leader:
+  id: "1"
+  next-run: 2018-07-01T02:00:00Z
+
+$ juju run-action mysql/leader backup --schedule "30 2 * * 1-5"
+...
+The backup action will be queued on the leader at 02:30 UTC on weekdays.
+
+When an action is run on many units, it may be queued on them in batches
+with --max-parallel (or --batch-size), so that no more than the given number
+of units run it at once. With --max-failures, no more batches are queued
+once the action has failed on the given number of units. The units are
+tracked as a single operation, whose ID is returned for use with
+'juju show-operation <ID>'.
+
+$ juju run-action mysql/0 mysql/1 mysql/2 restart --max-parallel 1 --max-failures 1
+operation: "1"
+queued:
+  mysql/0: <ID>
+status: running
 `
 
 // SetFlags offers an option for YAML output.
@@ -147,6 +181,11 @@ func (c *runCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.Var(&c.paramsYAML, "params", "Path to yaml-formatted params file")
 	f.BoolVar(&c.parseStrings, "string-args", false, "Use raw string values of CLI args")
 	f.Var(&c.wait, "wait", "Wait for results, with optional timeout")
+	f.StringVar(&c.atString, "at", "", "Queue the action once at the given RFC3339 time")
+	f.StringVar(&c.schedule, "schedule", "", "Queue the action repeatedly on the given cron-style schedule")
+	f.IntVar(&c.maxParallel, "max-parallel", 0, "Queue the action on no more than this many units at once")
+	f.IntVar(&c.maxParallel, "batch-size", 0, "")
+	f.IntVar(&c.maxFailures, "max-failures", 0, "Stop queueing the action once it has failed on this many units")
 }
 
 func (c *runCommand) Info() *cmd.Info {
@@ -176,6 +215,12 @@ func (c *runCommand) Init(args []string) (err error) {
 	if c.actionName == "" {
 		return errors.New("no action specified")
 	}
+	if err := c.initSchedule(); err != nil {
+		return errors.Trace(err)
+	}
+	if err := c.initBatch(); err != nil {
+		return errors.Trace(err)
+	}
 
 	// Parse CLI key-value args if they exist.
 	c.args = make([][]string, 0)
@@ -272,6 +317,12 @@ func (c *runCommand) Run(ctx *cmd.Context) error {
 		actions[i].Name = c.actionName
 		actions[i].Parameters = actionParams
 	}
+	if c.scheduled() {
+		return c.scheduleActions(ctx, actions)
+	}
+	if c.batched() {
+		return c.enqueueOperation(ctx, actions)
+	}
 	results, err := c.api.Enqueue(params.Actions{Actions: actions})
 	if err != nil {
 		return err
@@ -354,6 +405,149 @@ func (c *runCommand) Run(ctx *cmd.Context) error {
 	return c.out.Write(ctx, out)
 }
 
+// initSchedule checks the --at and --schedule flags.
+func (c *runCommand) initSchedule() error {
+	if c.atString != "" && c.schedule != "" {
+		return errors.New("cannot specify both --at and --schedule")
+	}
+	if c.atString != "" {
+		at, err := time.Parse(time.RFC3339, c.atString)
+		if err != nil {
+			return errors.Errorf("invalid --at time %q, expected a time such as 2018-07-01T02:00:00Z", c.atString)
+		}
+		c.at = at
+	}
+	if c.schedule != "" {
+		if _, err := actions.ParseSchedule(c.schedule); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if c.scheduled() && (c.wait.forever || c.wait.d > 0) {
+		return errors.New("cannot wait for the results of a scheduled action")
+	}
+	return nil
+}
+
+// scheduled reports whether the action is to be queued later rather
+// than straight away.
+func (c *runCommand) scheduled() bool {
+	return !c.at.IsZero() || c.schedule != ""
+}
+
+// scheduleActions adds a schedule for each of the given actions, and
+// writes out the ID and next run of each schedule.
+func (c *runCommand) scheduleActions(ctx *cmd.Context, actions []params.Action) error {
+	if c.api.BestAPIVersion() < 4 {
+		return errors.New("scheduling actions is unsupported by this API" +
+			"\nupgrade your controller to schedule actions")
+	}
+	args := params.ActionSchedules{Schedules: make([]params.ActionSchedule, len(actions))}
+	for i, action := range actions {
+		args.Schedules[i] = params.ActionSchedule{
+			Receiver:   action.Receiver,
+			Name:       action.Name,
+			Parameters: action.Parameters,
+			Schedule:   c.schedule,
+		}
+		if !c.at.IsZero() {
+			at := c.at.UTC()
+			args.Schedules[i].At = &at
+		}
+	}
+	results, err := c.api.ScheduleActions(args)
+	if err != nil {
+		return err
+	}
+	if len(results.Results) != len(c.unitReceivers) {
+		return errors.New("illegal number of results returned")
+	}
+
+	out := make(map[string]interface{}, len(results.Results))
+	for i, result := range results.Results {
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.Schedule == nil {
+			return errors.Errorf("action failed to be scheduled on %q", c.unitReceivers[i])
+		}
+		scheduled := map[string]string{"id": result.Schedule.Id}
+		if result.Schedule.NextRun != nil {
+			scheduled["next-run"] = result.Schedule.NextRun.UTC().Format(time.RFC3339)
+		}
+		out[c.unitReceivers[i]] = scheduled
+	}
+	return c.out.Write(ctx, out)
+}
+
+// initBatch checks the --max-parallel and --max-failures flags.
+func (c *runCommand) initBatch() error {
+	if c.maxParallel < 0 {
+		return errors.New("--max-parallel must not be negative")
+	}
+	if c.maxFailures < 0 {
+		return errors.New("--max-failures must not be negative")
+	}
+	if c.maxFailures > 0 && !c.batched() {
+		return errors.New("--max-failures requires --max-parallel")
+	}
+	if !c.batched() {
+		return nil
+	}
+	if c.scheduled() {
+		return errors.New("cannot specify --max-parallel with --at or --schedule")
+	}
+	if c.wait.forever || c.wait.d > 0 {
+		return errors.New("cannot wait for the results of a batched action, use 'juju show-operation'")
+	}
+	return nil
+}
+
+// batched reports whether the action is to be queued on the units in
+// batches, as an operation.
+func (c *runCommand) batched() bool {
+	return c.maxParallel > 0
+}
+
+// enqueueOperation queues the given actions in batches as a single
+// operation, and writes out the operation ID and the actions queued in
+// the first batch.
+func (c *runCommand) enqueueOperation(ctx *cmd.Context, actions []params.Action) error {
+	if c.api.BestAPIVersion() < 4 {
+		return errors.New("batched actions are unsupported by this API" +
+			"\nupgrade your controller to queue actions in batches")
+	}
+	args := params.OperationArgs{
+		Name:        c.actionName,
+		MaxParallel: c.maxParallel,
+		MaxFailures: c.maxFailures,
+	}
+	for _, action := range actions {
+		args.Receivers = append(args.Receivers, action.Receiver)
+		args.Parameters = action.Parameters
+	}
+	result, err := c.api.EnqueueOperation(args)
+	if err != nil {
+		return err
+	}
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.Operation == nil {
+		return errors.New("action failed to enqueue")
+	}
+	queued := make(map[string]string)
+	for _, action := range result.Operation.Actions {
+		if tag, err := names.ParseActionTag(action.Action); err == nil {
+			queued[receiverName(action.Receiver)] = tag.Id()
+		}
+	}
+	return c.out.Write(ctx, map[string]interface{}{
+		"operation": result.Operation.Id,
+		"status":    result.Operation.Status,
+		"queued":    queued,
+	})
+}
+
 func (c *runCommand) ensureAPI() (err error) {
 	if c.api != nil {
 		return nil
@@ -197,6 +197,34 @@ func (s *RunSuite) TestInit(c *gc.C) {
 		expectUnits:  []string{"mysql/leader"},
 		expectAction: "valid-action-name",
 		expectKVArgs: [][]string{},
+	}, {
+		should:       "work with a time to run at",
+		args:         []string{validUnitId, "valid-action-name", "--at", "2018-07-01T02:00:00Z"},
+		expectUnits:  []string{validUnitId},
+		expectAction: "valid-action-name",
+		expectKVArgs: [][]string{},
+	}, {
+		should:       "work with a schedule",
+		args:         []string{validUnitId, "valid-action-name", "--schedule", "30 2 * * 1-5"},
+		expectUnits:  []string{validUnitId},
+		expectAction: "valid-action-name",
+		expectKVArgs: [][]string{},
+	}, {
+		should:      "fail with both a time and a schedule",
+		args:        []string{validUnitId, "valid-action-name", "--at", "2018-07-01T02:00:00Z", "--schedule", "@daily"},
+		expectError: "cannot specify both --at and --schedule",
+	}, {
+		should:      "fail with an invalid time",
+		args:        []string{validUnitId, "valid-action-name", "--at", "tomorrow"},
+		expectError: `invalid --at time "tomorrow", expected a time such as 2018-07-01T02:00:00Z`,
+	}, {
+		should:      "fail with an invalid schedule",
+		args:        []string{validUnitId, "valid-action-name", "--schedule", "61 * * * *"},
+		expectError: `invalid schedule "61 \* \* \* \*": minute 61 out of range 0-59`,
+	}, {
+		should:      "fail when waiting for a scheduled action",
+		args:        []string{validUnitId, "valid-action-name", "--schedule", "@daily", "--wait"},
+		expectError: "cannot wait for the results of a scheduled action",
 	}}
 
 	for i, t := range tests {
@@ -0,0 +1,251 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package action
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/gnuflag"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/params"
+	jujucmd "github.com/juju/juju/cmd"
+	"github.com/juju/juju/cmd/modelcmd"
+	"github.com/juju/juju/cmd/output"
+)
+
+func NewSchedulesCommand() cmd.Command {
+	return modelcmd.Wrap(&schedulesCommand{})
+}
+
+// schedulesCommand lists the action schedules in a model.
+type schedulesCommand struct {
+	ActionCommandBase
+	out cmd.Output
+}
+
+const schedulesDoc = `
+List the schedules on which actions are queued, with the time each is next
+due to run and the result of its most recent run. Schedules are added with
+the --at and --schedule options of 'juju run-action'.
+
+Use --format yaml or --format json to see the history of recent runs,
+including the IDs of the actions that were queued.
+
+Examples:
+
+    juju action-schedules
+    juju action-schedules --format yaml
+
+See also:
+    run-action
+    remove-action-schedule
+`
+
+// SetFlags offers tabular, YAML and JSON output.
+func (c *schedulesCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.ActionCommandBase.SetFlags(f)
+	c.out.AddFlags(f, "tabular", map[string]cmd.Formatter{
+		"yaml":    cmd.FormatYaml,
+		"json":    cmd.FormatJson,
+		"tabular": printSchedulesTabular,
+	})
+}
+
+func (c *schedulesCommand) Info() *cmd.Info {
+	return jujucmd.Info(&cmd.Info{
+		Name:    "action-schedules",
+		Purpose: "List the schedules on which actions are queued.",
+		Doc:     schedulesDoc,
+	})
+}
+
+func (c *schedulesCommand) Init(args []string) error {
+	return cmd.CheckEmpty(args)
+}
+
+type scheduleRunOutput struct {
+	Time   string `yaml:"time" json:"time"`
+	Action string `yaml:"action,omitempty" json:"action,omitempty"`
+	Error  string `yaml:"error,omitempty" json:"error,omitempty"`
+}
+
+type scheduleOutput struct {
+	ID         string                 `yaml:"id" json:"id"`
+	Receiver   string                 `yaml:"receiver" json:"receiver"`
+	Action     string                 `yaml:"action" json:"action"`
+	Parameters map[string]interface{} `yaml:"parameters,omitempty" json:"parameters,omitempty"`
+	Schedule   string                 `yaml:"schedule,omitempty" json:"schedule,omitempty"`
+	NextRun    string                 `yaml:"next-run,omitempty" json:"next-run,omitempty"`
+	Runs       []scheduleRunOutput    `yaml:"runs,omitempty" json:"runs,omitempty"`
+}
+
+func (c *schedulesCommand) Run(ctx *cmd.Context) error {
+	api, err := c.NewActionAPIClient()
+	if err != nil {
+		return err
+	}
+	defer api.Close()
+
+	results, err := api.ActionSchedules()
+	if err != nil {
+		return err
+	}
+	out := make([]scheduleOutput, 0, len(results.Results))
+	for _, result := range results.Results {
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.Schedule == nil {
+			continue
+		}
+		out = append(out, makeScheduleOutput(*result.Schedule))
+	}
+	if len(out) == 0 && c.out.Name() == "tabular" {
+		ctx.Infof("No action schedules in this model.")
+		return nil
+	}
+	return c.out.Write(ctx, out)
+}
+
+func makeScheduleOutput(schedule params.ActionSchedule) scheduleOutput {
+	result := scheduleOutput{
+		ID:         schedule.Id,
+		Receiver:   receiverName(schedule.Receiver),
+		Action:     schedule.Name,
+		Parameters: schedule.Parameters,
+		Schedule:   schedule.Schedule,
+	}
+	if schedule.NextRun != nil {
+		result.NextRun = schedule.NextRun.UTC().Format(time.RFC3339)
+	}
+	for _, run := range schedule.Runs {
+		runOutput := scheduleRunOutput{
+			Time:  run.Time.UTC().Format(time.RFC3339),
+			Error: run.Error,
+		}
+		if tag, err := names.ParseActionTag(run.Action); err == nil {
+			runOutput.Action = tag.Id()
+		}
+		result.Runs = append(result.Runs, runOutput)
+	}
+	return result
+}
+
+// receiverName returns the unit name for a receiver given as a unit
+// tag, and the receiver unchanged otherwise; this leaves the leader
+// syntax, such as mysql/leader, as it was given.
+func receiverName(receiver string) string {
+	if tag, err := names.ParseUnitTag(receiver); err == nil {
+		return tag.Id()
+	}
+	return receiver
+}
+
+// printSchedulesTabular prints the action schedules in tabular format.
+func printSchedulesTabular(writer io.Writer, value interface{}) error {
+	schedules, ok := value.([]scheduleOutput)
+	if !ok {
+		return errors.Errorf("expected value of type %T, got %T", schedules, value)
+	}
+
+	tw := output.TabWriter(writer)
+	fmt.Fprintf(tw, "ID\tReceiver\tAction\tSchedule\tNext run\tLast run\n")
+	for _, s := range schedules {
+		schedule := s.Schedule
+		if schedule == "" {
+			schedule = "once"
+		}
+		nextRun := s.NextRun
+		if nextRun == "" {
+			nextRun = "-"
+		}
+		lastRun := "-"
+		if len(s.Runs) > 0 {
+			run := s.Runs[len(s.Runs)-1]
+			lastRun = run.Time
+			if run.Error != "" {
+				lastRun += " (failed: " + run.Error + ")"
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", s.ID, s.Receiver, s.Action, schedule, nextRun, lastRun)
+	}
+	tw.Flush()
+	return nil
+}
+
+func NewRemoveScheduleCommand() cmd.Command {
+	return modelcmd.Wrap(&removeScheduleCommand{})
+}
+
+// removeScheduleCommand removes action schedules from a model.
+type removeScheduleCommand struct {
+	ActionCommandBase
+	ids []string
+}
+
+const removeScheduleDoc = `
+Remove the action schedules with the given IDs, so that no more actions are
+queued on them. Actions already queued by the schedules are not affected;
+use 'juju cancel-action' to cancel those.
+
+Examples:
+
+    juju remove-action-schedule 3
+    juju remove-action-schedule 3 4
+
+See also:
+    action-schedules
+    cancel-action
+`
+
+func (c *removeScheduleCommand) Info() *cmd.Info {
+	return jujucmd.Info(&cmd.Info{
+		Name:    "remove-action-schedule",
+		Args:    "<schedule ID> [<schedule ID>...]",
+		Purpose: "Remove action schedules.",
+		Doc:     removeScheduleDoc,
+	})
+}
+
+func (c *removeScheduleCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no action schedules specified")
+	}
+	c.ids = args
+	return nil
+}
+
+func (c *removeScheduleCommand) Run(ctx *cmd.Context) error {
+	api, err := c.NewActionAPIClient()
+	if err != nil {
+		return err
+	}
+	defer api.Close()
+
+	results, err := api.RemoveActionSchedules(params.ActionScheduleIds{Ids: c.ids})
+	if err != nil {
+		return err
+	}
+	if len(results.Results) != len(c.ids) {
+		return errors.New("illegal number of results returned")
+	}
+	failed := false
+	for i, result := range results.Results {
+		if result.Error != nil {
+			ctx.Infof("cannot remove action schedule %q: %v", c.ids[i], result.Error)
+			failed = true
+			continue
+		}
+		ctx.Verbosef("removed action schedule %q", c.ids[i])
+	}
+	if failed {
+		return cmd.ErrSilent
+	}
+	return nil
+}
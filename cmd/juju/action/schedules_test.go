@@ -0,0 +1,238 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package action_test
+
+import (
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/cmd/cmdtesting"
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/yaml.v2"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/juju/action"
+)
+
+type SchedulesSuite struct {
+	BaseActionSuite
+}
+
+var _ = gc.Suite(&SchedulesSuite{})
+
+func (s *SchedulesSuite) TestRunScheduled(c *gc.C) {
+	nextRun := time.Date(2018, 7, 1, 2, 30, 0, 0, time.UTC)
+	fakeClient := &fakeAPIClient{
+		apiVersion: 4,
+		scheduleResults: []params.ActionScheduleResult{{
+			Schedule: &params.ActionSchedule{Id: "3", NextRun: &nextRun},
+		}},
+	}
+	restore := s.patchAPIClient(fakeClient)
+	defer restore()
+
+	wrappedCommand, _ := action.NewRunCommandForTest(s.store)
+	ctx, err := cmdtesting.RunCommand(c, wrappedCommand,
+		"-m", "admin", "mysql/leader", "backup", "--schedule", "30 2 * * *", "out=backup.tar")
+	c.Assert(err, jc.ErrorIsNil)
+	var out map[string]map[string]string
+	err = yaml.Unmarshal([]byte(cmdtesting.Stdout(ctx)), &out)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(out, jc.DeepEquals, map[string]map[string]string{
+		"mysql/leader": {"id": "3", "next-run": "2018-07-01T02:30:00Z"},
+	})
+	c.Check(fakeClient.scheduledActions, jc.DeepEquals, params.ActionSchedules{
+		Schedules: []params.ActionSchedule{{
+			Receiver:   "mysql/leader",
+			Name:       "backup",
+			Parameters: map[string]interface{}{"out": "backup.tar"},
+			Schedule:   "30 2 * * *",
+		}},
+	})
+	c.Check(fakeClient.EnqueuedActions().Actions, gc.HasLen, 0)
+}
+
+func (s *SchedulesSuite) TestRunAt(c *gc.C) {
+	at := time.Date(2018, 7, 1, 2, 0, 0, 0, time.UTC)
+	fakeClient := &fakeAPIClient{
+		apiVersion: 4,
+		scheduleResults: []params.ActionScheduleResult{{
+			Schedule: &params.ActionSchedule{Id: "4", NextRun: &at},
+		}},
+	}
+	restore := s.patchAPIClient(fakeClient)
+	defer restore()
+
+	wrappedCommand, _ := action.NewRunCommandForTest(s.store)
+	_, err := cmdtesting.RunCommand(c, wrappedCommand,
+		"-m", "admin", validUnitId, "backup", "--at", "2018-07-01T04:00:00+02:00")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(fakeClient.scheduledActions.Schedules, gc.HasLen, 1)
+	scheduled := fakeClient.scheduledActions.Schedules[0]
+	c.Check(scheduled.Receiver, gc.Equals, "unit-mysql-0")
+	c.Check(scheduled.At, jc.DeepEquals, &at)
+	c.Check(scheduled.Schedule, gc.Equals, "")
+}
+
+func (s *SchedulesSuite) TestRunScheduledOldController(c *gc.C) {
+	fakeClient := &fakeAPIClient{apiVersion: 3}
+	restore := s.patchAPIClient(fakeClient)
+	defer restore()
+
+	wrappedCommand, _ := action.NewRunCommandForTest(s.store)
+	_, err := cmdtesting.RunCommand(c, wrappedCommand,
+		"-m", "admin", validUnitId, "backup", "--schedule", "@daily")
+	c.Assert(err, gc.ErrorMatches, "scheduling actions is unsupported by this API\nupgrade your controller to schedule actions")
+}
+
+func (s *SchedulesSuite) TestRunScheduledError(c *gc.C) {
+	fakeClient := &fakeAPIClient{
+		apiVersion: 4,
+		scheduleResults: []params.ActionScheduleResult{{
+			Error: &params.Error{Message: `action "backup" not defined on unit "mysql/0"`},
+		}},
+	}
+	restore := s.patchAPIClient(fakeClient)
+	defer restore()
+
+	wrappedCommand, _ := action.NewRunCommandForTest(s.store)
+	_, err := cmdtesting.RunCommand(c, wrappedCommand,
+		"-m", "admin", validUnitId, "backup", "--schedule", "@daily")
+	c.Assert(err, gc.ErrorMatches, `action "backup" not defined on unit "mysql/0"`)
+}
+
+func (s *SchedulesSuite) TestListTabular(c *gc.C) {
+	nextRun := time.Date(2018, 7, 2, 2, 30, 0, 0, time.UTC)
+	lastRun := time.Date(2018, 7, 1, 2, 30, 0, 0, time.UTC)
+	fakeClient := &fakeAPIClient{
+		scheduleResults: []params.ActionScheduleResult{{
+			Schedule: &params.ActionSchedule{
+				Id:       "1",
+				Receiver: "unit-mysql-0",
+				Name:     "backup",
+				Schedule: "30 2 * * *",
+				NextRun:  &nextRun,
+				Runs: []params.ActionScheduleRun{{
+					Time:   lastRun,
+					Action: validActionTagString,
+				}},
+			},
+		}, {
+			Schedule: &params.ActionSchedule{
+				Id:       "2",
+				Receiver: "mysql/leader",
+				Name:     "snapshot",
+				Runs: []params.ActionScheduleRun{{
+					Time:  lastRun,
+					Error: "unit not found",
+				}},
+			},
+		}},
+	}
+	restore := s.patchAPIClient(fakeClient)
+	defer restore()
+
+	ctx, err := cmdtesting.RunCommand(c, action.NewSchedulesCommandForTest(s.store), "-m", "admin")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(cmdtesting.Stdout(ctx), gc.Equals, `
+ID  Receiver      Action    Schedule    Next run              Last run
+1   mysql/0       backup    30 2 * * *  2018-07-02T02:30:00Z  2018-07-01T02:30:00Z
+2   mysql/leader  snapshot  once        -                     2018-07-01T02:30:00Z (failed: unit not found)
+`[1:])
+}
+
+func (s *SchedulesSuite) TestListYAML(c *gc.C) {
+	lastRun := time.Date(2018, 7, 1, 2, 30, 0, 0, time.UTC)
+	fakeClient := &fakeAPIClient{
+		scheduleResults: []params.ActionScheduleResult{{
+			Schedule: &params.ActionSchedule{
+				Id:         "1",
+				Receiver:   "unit-mysql-0",
+				Name:       "backup",
+				Parameters: map[string]interface{}{"out": "backup.tar"},
+				Schedule:   "@daily",
+				Runs: []params.ActionScheduleRun{{
+					Time:   lastRun,
+					Action: validActionTagString,
+				}},
+			},
+		}},
+	}
+	restore := s.patchAPIClient(fakeClient)
+	defer restore()
+
+	ctx, err := cmdtesting.RunCommand(c, action.NewSchedulesCommandForTest(s.store), "-m", "admin", "--format", "yaml")
+	c.Assert(err, jc.ErrorIsNil)
+	var out []interface{}
+	err = yaml.Unmarshal([]byte(cmdtesting.Stdout(ctx)), &out)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(out, jc.DeepEquals, []interface{}{
+		map[interface{}]interface{}{
+			"id":       "1",
+			"receiver": "mysql/0",
+			"action":   "backup",
+			"parameters": map[interface{}]interface{}{
+				"out": "backup.tar",
+			},
+			"schedule": "@daily",
+			"runs": []interface{}{
+				map[interface{}]interface{}{
+					"time":   "2018-07-01T02:30:00Z",
+					"action": validActionId,
+				},
+			},
+		},
+	})
+}
+
+func (s *SchedulesSuite) TestListEmpty(c *gc.C) {
+	restore := s.patchAPIClient(&fakeAPIClient{})
+	defer restore()
+
+	ctx, err := cmdtesting.RunCommand(c, action.NewSchedulesCommandForTest(s.store), "-m", "admin")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(cmdtesting.Stdout(ctx), gc.Equals, "")
+	c.Check(cmdtesting.Stderr(ctx), gc.Equals, "No action schedules in this model.\n")
+}
+
+func (s *SchedulesSuite) TestRemove(c *gc.C) {
+	fakeClient := &fakeAPIClient{
+		removeResults: []params.ErrorResult{{}, {}},
+	}
+	restore := s.patchAPIClient(fakeClient)
+	defer restore()
+
+	_, err := cmdtesting.RunCommand(c, action.NewRemoveScheduleCommandForTest(s.store), "-m", "admin", "1", "2")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(fakeClient.removedSchedules, jc.DeepEquals, params.ActionScheduleIds{Ids: []string{"1", "2"}})
+}
+
+func (s *SchedulesSuite) TestRemoveFailure(c *gc.C) {
+	fakeClient := &fakeAPIClient{
+		removeResults: []params.ErrorResult{{}, {
+			Error: &params.Error{Message: `action schedule "2" not found`, Code: params.CodeNotFound},
+		}},
+	}
+	restore := s.patchAPIClient(fakeClient)
+	defer restore()
+
+	ctx, err := cmdtesting.RunCommand(c, action.NewRemoveScheduleCommandForTest(s.store), "-m", "admin", "1", "2")
+	c.Assert(err, gc.Equals, cmd.ErrSilent)
+	c.Check(cmdtesting.Stderr(ctx), gc.Equals, `cannot remove action schedule "2": action schedule "2" not found`+"\n")
+}
+
+func (s *SchedulesSuite) TestRemoveNoIds(c *gc.C) {
+	_, err := cmdtesting.RunCommand(c, action.NewRemoveScheduleCommandForTest(s.store), "-m", "admin")
+	c.Assert(err, gc.ErrorMatches, "no action schedules specified")
+}
+
+func (s *SchedulesSuite) TestRemoveAPIError(c *gc.C) {
+	restore := s.patchAPIClient(&fakeAPIClient{apiErr: errors.New("boom")})
+	defer restore()
+
+	_, err := cmdtesting.RunCommand(c, action.NewRemoveScheduleCommandForTest(s.store), "-m", "admin", "1")
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
@@ -4,6 +4,8 @@
 package action
 
 import (
+	"fmt"
+	"io"
 	"regexp"
 	"time"
 
@@ -28,6 +30,7 @@ type showOutputCommand struct {
 	requestedId string
 	fullSchema  bool
 	wait        string
+	watch       bool
 }
 
 const showOutputDoc = `
@@ -39,6 +42,17 @@ to wait indefinitely.  If units are left off, seconds are assumed.
 The default behavior without --wait is to immediately check and return; if
 the results are "pending" then only the available information will be
 displayed.  This is also the behavior when any negative time is given.
+
+Actions may log progress messages while they run, using the action-log hook
+tool.  To follow the messages as they are logged, use the --watch option; the
+messages are written to stderr, and the results are shown once the action
+finishes.  Unless --wait is also given, --watch waits indefinitely.
+
+Examples:
+
+    juju show-action-output 1234abcd
+    juju show-action-output 1234abcd --wait 1m
+    juju show-action-output 1234abcd --watch
 `
 
 // Set up the output.
@@ -46,6 +60,7 @@ func (c *showOutputCommand) SetFlags(f *gnuflag.FlagSet) {
 	c.ActionCommandBase.SetFlags(f)
 	c.out.AddFlags(f, "yaml", output.DefaultFormatters)
 	f.StringVar(&c.wait, "wait", "-1s", "Wait for results")
+	f.BoolVar(&c.watch, "watch", false, "Print progress messages as they are logged, until the action finishes")
 }
 
 func (c *showOutputCommand) Info() *cmd.Info {
@@ -84,6 +99,10 @@ func (c *showOutputCommand) Run(ctx *cmd.Context) error {
 	if err != nil {
 		return err
 	}
+	if c.watch && waitDur < 0 {
+		// Watching waits indefinitely, unless told otherwise.
+		waitDur = 0
+	}
 
 	api, err := c.NewActionAPIClient()
 	if err != nil {
@@ -105,7 +124,13 @@ func (c *showOutputCommand) Run(ctx *cmd.Context) error {
 		wait = time.NewTimer(waitDur)
 	}
 
-	result, err := GetActionResult(api, c.requestedId, wait)
+	var result params.ActionResult
+	if c.watch {
+		tick := time.NewTimer(2 * time.Second)
+		result, err = timerLoop(api, c.requestedId, wait, tick, newLogPrinter(ctx.Stderr))
+	} else {
+		result, err = GetActionResult(api, c.requestedId, wait)
+	}
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -122,13 +147,31 @@ func GetActionResult(api APIClient, requestedId string, wait *time.Timer) (param
 	// TODO(fwereade): 2016-03-17 lp:1558657
 	tick := time.NewTimer(2 * time.Second)
 
-	return timerLoop(api, requestedId, wait, tick)
+	return timerLoop(api, requestedId, wait, tick, nil)
+}
+
+// newLogPrinter returns a function which writes the progress messages
+// of each action result passed to it, skipping those it has already
+// written.
+func newLogPrinter(w io.Writer) func(params.ActionResult) {
+	printed := 0
+	return func(result params.ActionResult) {
+		for ; printed < len(result.Log); printed++ {
+			fmt.Fprintln(w, formatActionMessage(result.Log[printed]))
+		}
+	}
+}
+
+// formatActionMessage formats a progress message logged by an action.
+func formatActionMessage(m params.ActionMessage) string {
+	return fmt.Sprintf("%s %s", m.Timestamp.UTC().Format(time.RFC3339), m.Message)
 }
 
 // timerLoop loops indefinitely to query the given API, until "wait" times
 // out, using the "tick" timer to delay the API queries.  It writes the
-// result to the given output.
-func timerLoop(api APIClient, requestedId string, wait, tick *time.Timer) (params.ActionResult, error) {
+// result to the given output.  If onResult isn't nil, it is called with
+// each result fetched.
+func timerLoop(api APIClient, requestedId string, wait, tick *time.Timer, onResult func(params.ActionResult)) (params.ActionResult, error) {
 	var (
 		result params.ActionResult
 		err    error
@@ -141,6 +184,9 @@ func timerLoop(api APIClient, requestedId string, wait, tick *time.Timer) (param
 		if err != nil {
 			return result, err
 		}
+		if onResult != nil {
+			onResult(result)
+		}
 
 		// Whether or not we're waiting for a result, if a completed
 		// result arrives, we're done.
@@ -205,6 +251,13 @@ func FormatActionResult(result params.ActionResult) map[string]interface{} {
 	if len(result.Output) != 0 {
 		response["results"] = result.Output
 	}
+	if len(result.Log) != 0 {
+		log := make([]string, len(result.Log))
+		for i, m := range result.Log {
+			log[i] = formatActionMessage(m)
+		}
+		response["log"] = log
+	}
 
 	if result.Enqueued.IsZero() && result.Started.IsZero() && result.Completed.IsZero() {
 		return response
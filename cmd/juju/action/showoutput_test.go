@@ -326,3 +326,42 @@ func makeFakeClient(
 	}
 	return client
 }
+
+func (s *ShowOutputSuite) TestWatch(c *gc.C) {
+	logged := time.Date(2015, time.February, 14, 8, 14, 0, 0, time.UTC)
+	client := makeFakeClient(
+		0,
+		10*time.Second,
+		tagsForIdPrefix(validActionId, validActionTagString),
+		[]params.ActionResult{{
+			Status: "completed",
+			Log: []params.ActionMessage{
+				{Timestamp: logged, Message: "starting"},
+				{Timestamp: logged.Add(time.Minute), Message: "all done"},
+			},
+			Enqueued:  time.Date(2015, time.February, 14, 8, 13, 0, 0, time.UTC),
+			Completed: time.Date(2015, time.February, 14, 8, 15, 30, 0, time.UTC),
+		}},
+		params.ActionsByNames{},
+		"",
+	)
+	unpatch := s.BaseActionSuite.patchAPIClient(client)
+	defer unpatch()
+
+	cmd, _ := action.NewShowOutputCommandForTest(s.store)
+	ctx, err := cmdtesting.RunCommand(c, cmd, "-m", "admin", validActionId, "--watch")
+	c.Assert(err, gc.IsNil)
+	c.Check(cmdtesting.Stderr(ctx), gc.Equals, `
+2015-02-14T08:14:00Z starting
+2015-02-14T08:15:00Z all done
+`[1:])
+	c.Check(cmdtesting.Stdout(ctx), gc.Equals, `
+log:
+- 2015-02-14T08:14:00Z starting
+- 2015-02-14T08:15:00Z all done
+status: completed
+timing:
+  completed: 2015-02-14 08:15:30 +0000 UTC
+  enqueued: 2015-02-14 08:13:00 +0000 UTC
+`[1:])
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"time"
 
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
@@ -29,6 +30,12 @@ type APIClient interface {
 	io.Closer
 	// Create sends an RPC request to create a new backup.
 	Create(notes string, keepCopy, noDownload bool) (*params.BackupsMetadataResult, error)
+	// CreateEncrypted sends an RPC request to create a new backup,
+	// with the archive encrypted with a passphrase or public key.
+	CreateEncrypted(notes string, keepCopy, noDownload bool, passphrase, publicKey string) (*params.BackupsMetadataResult, error)
+	// CreateIncremental sends an RPC request to create a new
+	// incremental backup of the full backup with the given ID.
+	CreateIncremental(base, notes string) (*params.BackupsMetadataResult, error)
 	// Info gets the backup's metadata.
 	Info(id string) (*params.BackupsMetadataResult, error)
 	// List gets all stored metadata.
@@ -41,8 +48,15 @@ type APIClient interface {
 	Remove(ids ...string) ([]params.ErrorResult, error)
 	// Restore will restore a backup with the given id into the controller.
 	Restore(string, backups.ClientConnection) error
+	// RestoreToTime will restore a backup with the given id into the
+	// controller, and replay its incremental backups up to the given time.
+	RestoreToTime(string, time.Time, backups.ClientConnection) error
 	// RestoreReader will restore a backup file into the controller.
 	RestoreReader(io.ReadSeeker, *params.BackupsMetadataResult, backups.ClientConnection) error
+	// RestoreEncrypted will restore an encrypted backup with the given
+	// id into the controller, which decrypts it with the given
+	// passphrase or private key.
+	RestoreEncrypted(backupId, passphrase, privateKey string, newClient backups.ClientConnection) error
 }
 
 // CommandBase is the base type for backups sub-commands.
@@ -103,6 +117,20 @@ func (c *CommandBase) dumpMetadata(ctx *cmd.Context, result *params.BackupsMetad
 	fmt.Fprintf(ctx.Stdout, "started:         %v\n", result.Started)
 	fmt.Fprintf(ctx.Stdout, "finished:        %v\n", result.Finished)
 	fmt.Fprintf(ctx.Stdout, "notes:           %q\n", result.Notes)
+	if result.Scheduled {
+		fmt.Fprintf(ctx.Stdout, "scheduled:       %v\n", result.Scheduled)
+	}
+	if result.Base != "" {
+		fmt.Fprintf(ctx.Stdout, "base backup ID:  %q\n", result.Base)
+		fmt.Fprintf(ctx.Stdout, "changes from:    %v\n", result.OplogStart)
+		fmt.Fprintf(ctx.Stdout, "changes to:      %v\n", result.OplogEnd)
+	}
+	if result.Encrypted {
+		fmt.Fprintf(ctx.Stdout, "encrypted:       %v\n", result.Encrypted)
+		if result.KeyFingerprint != "" {
+			fmt.Fprintf(ctx.Stdout, "key fingerprint: %s\n", result.KeyFingerprint)
+		}
+	}
 
 	fmt.Fprintf(ctx.Stdout, "model ID:        %q\n", result.Model)
 	fmt.Fprintf(ctx.Stdout, "machine ID:      %q\n", result.Machine)
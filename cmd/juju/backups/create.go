@@ -5,6 +5,7 @@ package backups
 
 import (
 	"io"
+	"io/ioutil"
 	"os"
 	"time"
 
@@ -38,6 +39,19 @@ Use --keep-copy option to store a copy of backup remotely on the controller.
 
 Use --verbose to see extra information about backup.
 
+Use --base to create an incremental backup of the controller's database
+changes since the given full backup, or since its most recent incremental
+backup. Incremental backups are quick to create and are kept on the
+controller; restore the full backup with 'juju restore-backup --to-time' to
+replay them.
+
+Use --passphrase-file or --public-key to encrypt the backup archive, with
+the passphrase in the given file, or to the armored OpenPGP public key in
+the given file. The controller can't decrypt encrypted archives: supply the
+passphrase, or the matching private key, to 'juju download-backup',
+'juju upload-backup' or 'juju restore-backup' to decrypt them. Incremental
+backups can't be encrypted.
+
 To access remote backups stored on the controller, see 'juju download-backup'.
 
 Examples:
@@ -46,6 +60,8 @@ Examples:
     juju create-backup --no-download --keep-copy=false // ignores --keep-copy
     juju create-backup --keep-copy
     juju create-backup --verbose
+    juju create-backup --base 20180701-000000.2cd1b5e1-4ad8-4e12-8e6c-4f06bd6ca5e1
+    juju create-backup --public-key backup-key.asc
 
 See also:
     backups
@@ -68,7 +84,14 @@ type createCommand struct {
 	Notes string
 	// KeepCopy means the backup archive should be stored in the controller db.
 	KeepCopy bool
-	fs       *gnuflag.FlagSet
+	// Base is the ID of the full backup to create an incremental backup of.
+	Base string
+	// PassphraseFile holds the passphrase to encrypt the archive with.
+	PassphraseFile string
+	// PublicKeyFile holds the armored OpenPGP public key to encrypt
+	// the archive to.
+	PublicKeyFile string
+	fs            *gnuflag.FlagSet
 }
 
 // Info implements Command.Info.
@@ -87,11 +110,27 @@ func (c *createCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.BoolVar(&c.NoDownload, "no-download", false, "Do not download the archive, implies keep-copy")
 	f.BoolVar(&c.KeepCopy, "keep-copy", false, "Keep a copy of the archive on the controller")
 	f.StringVar(&c.Filename, "filename", notset, "Download to this file")
+	f.StringVar(&c.Base, "base", "", "Create an incremental backup of this full backup")
+	f.StringVar(&c.PassphraseFile, "passphrase-file", "", "Encrypt the archive with the passphrase in this file")
+	f.StringVar(&c.PublicKeyFile, "public-key", "", "Encrypt the archive to the OpenPGP public key in this file")
 	c.fs = f
 }
 
 // Init implements Command.Init.
 func (c *createCommand) Init(args []string) error {
+	if c.PassphraseFile != "" && c.PublicKeyFile != "" {
+		return errors.Errorf("cannot mix --passphrase-file and --public-key")
+	}
+	if c.encrypt() && c.Base != "" {
+		return errors.Errorf("incremental backups cannot be encrypted")
+	}
+	// Incremental backups are only kept on the controller.
+	if c.Base != "" {
+		if c.Filename != notset {
+			return errors.Errorf("cannot mix --base and --filename")
+		}
+		c.NoDownload = true
+	}
 	// If user specifies that a download is not desired (i.e. no-download == true),
 	// and they have EXPLICITLY not wanted to store a remote backup file copy
 	// (i.e keep-copy == false), then there is no point for us to proceed as
@@ -144,9 +183,17 @@ func (c *createCommand) Run(ctx *cmd.Context) error {
 		// for API v1, keepCopy is the default and only choice, so set it here
 		c.KeepCopy = true
 	}
+	if apiVersion < 3 && c.Base != "" {
+		return errors.New("--base is not supported by this controller")
+	}
+	if apiVersion < 4 && c.encrypt() {
+		return errors.New("encrypted backups are not supported by this controller")
+	}
 
 	if c.NoDownload {
-		ctx.Warningf(downloadWarning)
+		if c.Base == "" {
+			ctx.Warningf(downloadWarning)
+		}
 		c.KeepCopy = true
 	}
 
@@ -183,7 +230,16 @@ func (c *createCommand) decideFilename(ctx *cmd.Context, filename string, timest
 		return filename
 	}
 	// Downloading but no filename given, so generate one.
-	return timestamp.Format(backups.FilenameTemplate)
+	filename = timestamp.Format(backups.FilenameTemplate)
+	if c.encrypt() {
+		filename += encryptedSuffix
+	}
+	return filename
+}
+
+// encrypt reports whether the backup archive is to be encrypted.
+func (c *createCommand) encrypt() bool {
+	return c.PassphraseFile != "" || c.PublicKeyFile != ""
 }
 
 func (c *createCommand) download(ctx *cmd.Context, client APIClient, copyFrom string, archiveFilename string) error {
@@ -208,7 +264,20 @@ func (c *createCommand) download(ctx *cmd.Context, client APIClient, copyFrom st
 }
 
 func (c *createCommand) create(client APIClient, apiVersion int) (*params.BackupsMetadataResult, string, error) {
-	result, err := client.Create(c.Notes, c.KeepCopy, c.NoDownload)
+	if c.Base != "" {
+		result, err := client.CreateIncremental(c.Base, c.Notes)
+		if err != nil {
+			return nil, "", errors.Trace(err)
+		}
+		return result, result.ID, nil
+	}
+	var result *params.BackupsMetadataResult
+	var err error
+	if c.encrypt() {
+		result, err = c.createEncrypted(client)
+	} else {
+		result, err = client.Create(c.Notes, c.KeepCopy, c.NoDownload)
+	}
 	if err != nil {
 		return nil, "", errors.Trace(err)
 	}
@@ -220,3 +289,22 @@ func (c *createCommand) create(client APIClient, apiVersion int) (*params.Backup
 
 	return result, copyFrom, err
 }
+
+func (c *createCommand) createEncrypted(client APIClient) (*params.BackupsMetadataResult, error) {
+	var passphrase, publicKey string
+	if c.PassphraseFile != "" {
+		var err error
+		passphrase, err = readPassphrase(c.PassphraseFile)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	} else {
+		data, err := ioutil.ReadFile(c.PublicKeyFile)
+		if err != nil {
+			return nil, errors.Annotate(err, "cannot read public key")
+		}
+		publicKey = string(data)
+	}
+	result, err := client.CreateEncrypted(c.Notes, c.KeepCopy, c.NoDownload, passphrase, publicKey)
+	return result, errors.Trace(err)
+}
@@ -282,3 +282,63 @@ func (s *createSuite) TestError(c *gc.C) {
 
 	c.Check(errors.Cause(err), gc.ErrorMatches, "failed!")
 }
+
+func (s *createSuite) TestIncremental(c *gc.C) {
+	s.apiVersion = 3
+	client := s.setSuccess()
+	ctx, err := cmdtesting.RunCommand(c, s.wrappedCommand, "--base", "base-id", "nightly")
+	c.Assert(err, jc.ErrorIsNil)
+
+	client.CheckCalls(c, "CreateIncremental")
+	client.CheckArgs(c, "base-id", "nightly")
+	expectedMsg := fmt.Sprintf("Remote backup stored on the controller as %v.\n", s.metaresult.ID)
+	s.checkStd(c, ctx, MetaResultString, expectedMsg)
+}
+
+func (s *createSuite) TestIncrementalNotSupported(c *gc.C) {
+	s.setSuccess()
+	_, err := cmdtesting.RunCommand(c, s.wrappedCommand, "--base", "base-id")
+
+	c.Check(err, gc.ErrorMatches, "--base is not supported by this controller")
+}
+
+func (s *createSuite) TestIncrementalAndFilename(c *gc.C) {
+	s.apiVersion = 3
+	s.setSuccess()
+	_, err := cmdtesting.RunCommand(c, s.wrappedCommand, "--base", "base-id", "--filename", "backup.tgz")
+
+	c.Check(err, gc.ErrorMatches, "cannot mix --base and --filename")
+	// Nothing was downloaded, so there's nothing to clean up.
+	s.command.Filename = backups.NotSet
+}
+
+func (s *createSuite) TestEncrypted(c *gc.C) {
+	s.apiVersion = 4
+	client := s.setSuccess()
+	ctx, err := cmdtesting.RunCommand(c, s.wrappedCommand, "--no-download",
+		"--passphrase-file", writePassphrase(c, "secret"))
+	c.Assert(err, jc.ErrorIsNil)
+
+	client.CheckCalls(c, "CreateEncrypted")
+	client.CheckArgs(c, "", "true", "true", "secret", "")
+	expectedMsg := fmt.Sprintf("WARNING %v\nRemote backup stored on the controller as %v.\n", backups.DownloadWarning, s.metaresult.ID)
+	s.checkStd(c, ctx, MetaResultString, expectedMsg)
+}
+
+func (s *createSuite) TestEncryptedNotSupported(c *gc.C) {
+	s.apiVersion = 3
+	s.setSuccess()
+	_, err := cmdtesting.RunCommand(c, s.wrappedCommand, "--no-download",
+		"--passphrase-file", writePassphrase(c, "secret"))
+
+	c.Check(err, gc.ErrorMatches, "encrypted backups are not supported by this controller")
+}
+
+func (s *createSuite) TestEncryptedIncremental(c *gc.C) {
+	s.apiVersion = 4
+	s.setSuccess()
+	_, err := cmdtesting.RunCommand(c, s.wrappedCommand, "--base", "base-id",
+		"--passphrase-file", writePassphrase(c, "secret"))
+
+	c.Check(err, gc.ErrorMatches, "incremental backups cannot be encrypted")
+}
@@ -22,6 +22,9 @@ download-backup retrieves a backup archive file.
 
 If --filename is not used, the archive is downloaded to a temporary
 location and the filename is printed to stdout.
+
+Encrypted archives are downloaded as they are, unless --passphrase-file or
+--private-key is used to decrypt them.
 `
 
 // NewDownloadCommand returns a commant used to download backups.
@@ -36,6 +39,7 @@ type downloadCommand struct {
 	Filename string
 	// ID is the backup ID to download.
 	ID string
+	keyFlags
 }
 
 // Info implements Command.Info.
@@ -52,6 +56,7 @@ func (c *downloadCommand) Info() *cmd.Info {
 func (c *downloadCommand) SetFlags(f *gnuflag.FlagSet) {
 	c.CommandBase.SetFlags(f)
 	f.StringVar(&c.Filename, "filename", "", "Download target")
+	c.keyFlags.setFlags(f)
 }
 
 // Init implements Command.Init.
@@ -79,6 +84,10 @@ func (c *downloadCommand) Run(ctx *cmd.Context) error {
 		return errors.Trace(err)
 	}
 	defer client.Close()
+	key, err := c.key()
+	if err != nil {
+		return errors.Trace(err)
+	}
 
 	// Download the archive.
 	resultArchive, err := client.Download(c.ID)
@@ -86,9 +95,23 @@ func (c *downloadCommand) Run(ctx *cmd.Context) error {
 		return errors.Trace(err)
 	}
 	defer resultArchive.Close()
+	encrypted, source, err := backups.IsEncryptedArchive(resultArchive)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	filename := c.ResolveFilename()
+	if encrypted {
+		if key != nil {
+			source, err = backups.NewDecryptingReader(source, *key)
+			if err != nil {
+				return errors.Trace(err)
+			}
+		} else if c.Filename == "" {
+			filename += encryptedSuffix
+		}
+	}
 
 	// Prepare the local archive.
-	filename := c.ResolveFilename()
 	archive, err := os.Create(filename)
 	if err != nil {
 		return errors.Annotate(err, "while creating local archive file")
@@ -96,8 +119,11 @@ func (c *downloadCommand) Run(ctx *cmd.Context) error {
 	defer archive.Close()
 
 	// Write out the archive.
-	_, err = io.Copy(archive, resultArchive)
+	_, err = io.Copy(archive, source)
 	if err != nil {
+		// Don't leave a partly decrypted archive around.
+		archive.Close()
+		os.Remove(filename)
 		return errors.Annotate(err, "while copying local archive file")
 	}
 
@@ -4,6 +4,10 @@
 package backups_test
 
 import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+
 	"github.com/juju/cmd"
 	"github.com/juju/cmd/cmdtesting"
 	"github.com/juju/errors"
@@ -12,6 +16,7 @@ import (
 
 	"github.com/juju/juju/cmd/juju/backups"
 	"github.com/juju/juju/jujuclient/jujuclienttesting"
+	statebackups "github.com/juju/juju/state/backups"
 )
 
 type downloadSuite struct {
@@ -72,3 +77,58 @@ func (s *downloadSuite) TestError(c *gc.C) {
 	_, err := cmdtesting.RunCommand(c, s.wrappedCommand, s.metaresult.ID)
 	c.Check(errors.Cause(err), gc.ErrorMatches, "failed!")
 }
+
+// encrypt returns the data encrypted with the key.
+func encrypt(c *gc.C, data string, key statebackups.ArchiveKey) []byte {
+	var buf bytes.Buffer
+	w, err := statebackups.NewEncryptingWriter(&buf, key)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = w.Write([]byte(data))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(w.Close(), jc.ErrorIsNil)
+	return buf.Bytes()
+}
+
+// writePassphrase writes the passphrase to a new file, and returns its name.
+func writePassphrase(c *gc.C, passphrase string) string {
+	filename := filepath.Join(c.MkDir(), "passphrase")
+	err := ioutil.WriteFile(filename, []byte(passphrase+"\n"), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+	return filename
+}
+
+func (s *downloadSuite) TestEncrypted(c *gc.C) {
+	encrypted := encrypt(c, s.data, statebackups.ArchiveKey{Passphrase: "secret"})
+	client := s.setSuccess()
+	client.archive = ioutil.NopCloser(bytes.NewReader(encrypted))
+	ctx, err := cmdtesting.RunCommand(c, s.wrappedCommand, s.metaresult.ID)
+	c.Check(err, jc.ErrorIsNil)
+
+	// The archive is kept encrypted.
+	s.filename = "juju-backup-" + s.metaresult.ID + ".tar.gz.gpg"
+	s.checkStd(c, ctx, s.filename+"\n", "")
+	s.data = string(encrypted)
+	s.checkArchive(c)
+}
+
+func (s *downloadSuite) TestDecrypt(c *gc.C) {
+	client := s.setSuccess()
+	client.archive = ioutil.NopCloser(bytes.NewReader(
+		encrypt(c, s.data, statebackups.ArchiveKey{Passphrase: "secret"})))
+	ctx, err := cmdtesting.RunCommand(c, s.wrappedCommand, s.metaresult.ID,
+		"--passphrase-file", writePassphrase(c, "secret"))
+	c.Check(err, jc.ErrorIsNil)
+
+	s.filename = "juju-backup-" + s.metaresult.ID + ".tar.gz"
+	s.checkStd(c, ctx, s.filename+"\n", "")
+	s.checkArchive(c)
+}
+
+func (s *downloadSuite) TestDecryptWrongPassphrase(c *gc.C) {
+	client := s.setSuccess()
+	client.archive = ioutil.NopCloser(bytes.NewReader(
+		encrypt(c, s.data, statebackups.ArchiveKey{Passphrase: "secret"})))
+	_, err := cmdtesting.RunCommand(c, s.wrappedCommand, s.metaresult.ID,
+		"--passphrase-file", writePassphrase(c, "wrong"))
+	c.Check(err, gc.ErrorMatches, "cannot decrypt archive: incorrect passphrase or key")
+}
@@ -0,0 +1,186 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/gnuflag"
+
+	apiserverbackups "github.com/juju/juju/apiserver/facades/client/backups"
+	"github.com/juju/juju/apiserver/params"
+	statebackups "github.com/juju/juju/state/backups"
+)
+
+// encryptedSuffix is added to the names generated for encrypted
+// archive files.
+const encryptedSuffix = ".gpg"
+
+// keyFlags holds the flags that supply the key to decrypt an
+// encrypted backup archive with.
+type keyFlags struct {
+	// PassphraseFile holds the passphrase the archive was encrypted
+	// with, or which unlocks the private key.
+	PassphraseFile string
+	// PrivateKeyFile holds the armored OpenPGP private key matching
+	// the public key the archive was encrypted to.
+	PrivateKeyFile string
+}
+
+func (f *keyFlags) setFlags(fs *gnuflag.FlagSet) {
+	fs.StringVar(&f.PassphraseFile, "passphrase-file", "", "Decrypt an encrypted archive with the passphrase in this file")
+	fs.StringVar(&f.PrivateKeyFile, "private-key", "", "Decrypt an encrypted archive with the OpenPGP private key in this file")
+}
+
+// isSet reports whether either flag was used.
+func (f *keyFlags) isSet() bool {
+	return f.PassphraseFile != "" || f.PrivateKeyFile != ""
+}
+
+// key returns the key read from the files named by the flags, or nil
+// if neither flag was used.
+func (f *keyFlags) key() (*statebackups.ArchiveKey, error) {
+	if !f.isSet() {
+		return nil, nil
+	}
+	var key statebackups.ArchiveKey
+	if f.PassphraseFile != "" {
+		passphrase, err := readPassphrase(f.PassphraseFile)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		key.Passphrase = passphrase
+	}
+	if f.PrivateKeyFile != "" {
+		data, err := ioutil.ReadFile(f.PrivateKeyFile)
+		if err != nil {
+			return nil, errors.Annotate(err, "cannot read private key")
+		}
+		key.PrivateKey = string(data)
+	}
+	return &key, nil
+}
+
+// requireKey returns the key read from the files named by the flags,
+// failing if there isn't one to decrypt the named archive with.
+func (f *keyFlags) requireKey(name string) (statebackups.ArchiveKey, error) {
+	key, err := f.key()
+	if err != nil {
+		return statebackups.ArchiveKey{}, errors.Trace(err)
+	}
+	if key == nil {
+		return statebackups.ArchiveKey{}, errors.Errorf(
+			"backup %q is encrypted; use --passphrase-file or --private-key to decrypt it", name)
+	}
+	return *key, nil
+}
+
+// readPassphrase returns the passphrase in the file, ignoring any
+// trailing newline.
+func readPassphrase(filename string) (string, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return "", errors.Annotate(err, "cannot read passphrase")
+	}
+	passphrase := strings.TrimRight(string(data), "\r\n")
+	if passphrase == "" {
+		return "", errors.Errorf("passphrase file %q is empty", filename)
+	}
+	return passphrase, nil
+}
+
+// isEncryptedFile reports whether the archive file is encrypted.
+func isEncryptedFile(filename string) (bool, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	defer f.Close()
+	encrypted, _, err := statebackups.IsEncryptedArchive(f)
+	return encrypted, errors.Trace(err)
+}
+
+// getEncryptedArchive opens the encrypted archive file, and returns it
+// along with its metadata, which is read by decrypting it with the key.
+func getEncryptedArchive(filename string, key statebackups.ArchiveKey) (_ ArchiveReader, _ *params.BackupsMetadataResult, err error) {
+	archive, err := os.Open(filename)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	defer func() {
+		if err != nil {
+			archive.Close()
+		}
+	}()
+
+	decrypted, err := statebackups.NewDecryptingReader(archive, key)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	ad, err := statebackups.NewArchiveDataReader(decrypted)
+	if err != nil {
+		return nil, nil, errors.Annotate(err, "cannot decrypt archive")
+	}
+	// Make sure the whole archive decrypted intact.
+	if _, err := io.Copy(ioutil.Discard, decrypted); err != nil {
+		return nil, nil, errors.Annotate(err, "cannot decrypt archive")
+	}
+	if _, err := archive.Seek(0, io.SeekStart); err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	fileMeta, err := statebackups.BuildMetadata(archive)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	meta, err := ad.Metadata()
+	if errors.IsNotFound(err) {
+		meta = fileMeta
+	} else if err != nil {
+		return nil, nil, errors.Trace(err)
+	} else {
+		// The file info describes the encrypted archive.
+		err := meta.SetFileInfo(fileMeta.Size(), fileMeta.Checksum(), fileMeta.ChecksumFormat())
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		if meta.Finished == nil || meta.Finished.IsZero() {
+			meta.Finished = fileMeta.Finished
+		}
+	}
+	meta.Encrypted = true
+	meta.KeyFingerprint, err = key.Fingerprint()
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	if _, err := archive.Seek(0, io.SeekStart); err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+
+	result := apiserverbackups.CreateResult(meta, "")
+	return archive, &result, nil
+}
+
+// openArchive opens the archive file, and returns it along with its
+// metadata and, if it's encrypted, the key from the flags to decrypt it
+// with. An encrypted archive is returned still encrypted.
+func (f *keyFlags) openArchive(filename string) (ArchiveReader, *params.BackupsMetadataResult, *statebackups.ArchiveKey, error) {
+	// Any problem opening the file is left to getArchive to report.
+	if encrypted, err := isEncryptedFile(filename); err != nil || !encrypted {
+		archive, meta, err := getArchive(filename)
+		return archive, meta, nil, err
+	}
+	key, err := f.requireKey(filename)
+	if err != nil {
+		return nil, nil, nil, errors.Trace(err)
+	}
+	archive, meta, err := getEncryptedArchive(filename, key)
+	if err != nil {
+		return nil, nil, nil, errors.Trace(err)
+	}
+	return archive, meta, &key, nil
+}
@@ -68,6 +68,13 @@ func NewUploadCommandForTest(store jujuclient.ClientStore) cmd.Command {
 	return modelcmd.Wrap(c)
 }
 
+func NewVerifyCommandForTest(store jujuclient.ClientStore) cmd.Command {
+	c := &verifyCommand{}
+	c.Log = &cmd.Log{}
+	c.SetClientStore(store)
+	return modelcmd.Wrap(c)
+}
+
 func NewRemoveCommandForTest(store jujuclient.ClientStore) cmd.Command {
 	c := &removeCommand{}
 	c.Log = &cmd.Log{}
@@ -5,17 +5,26 @@ package backups
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
 	"github.com/juju/gnuflag"
 
+	"github.com/juju/juju/apiserver/params"
 	jujucmd "github.com/juju/juju/cmd"
 	"github.com/juju/juju/cmd/modelcmd"
 )
 
 const listDoc = `
 backups provides the metadata associated with all backups.
+
+If the controller backs itself up on a schedule, set with the
+backup-schedule controller config setting, the schedule and the time of
+the next backup are shown too, along with when the last scheduled
+backup ran and whether it succeeded. Scheduled backups are marked as
+such in the verbose output, and are removed as the
+backup-retention-count and backup-retention-age settings require.
 `
 
 // NewListCommand returns a command used to list metadata for backups.
@@ -70,6 +79,12 @@ func (c *listCommand) Run(ctx *cmd.Context) error {
 		return errors.Trace(err)
 	}
 
+	if result.Schedule != "" {
+		c.showSchedule(ctx, result)
+	}
+	if result.LastScheduled != nil {
+		c.showLastScheduled(ctx, result)
+	}
 	if len(result.List) == 0 {
 		ctx.Infof("No backups to display.")
 		return nil
@@ -91,3 +106,25 @@ func (c *listCommand) Run(ctx *cmd.Context) error {
 	}
 	return nil
 }
+
+// showSchedule reports the controller's backup schedule.
+func (c *listCommand) showSchedule(ctx *cmd.Context, result *params.BackupsListResult) {
+	msg := fmt.Sprintf("Backups are scheduled %q", result.Schedule)
+	if result.NextScheduled != nil {
+		msg += fmt.Sprintf(", the next at %v", result.NextScheduled.UTC().Format(time.RFC3339))
+	}
+	if result.CopyDir != "" {
+		msg += fmt.Sprintf(", and copied to %s on each controller machine", result.CopyDir)
+	}
+	ctx.Infof("%s.", msg)
+}
+
+// showLastScheduled reports how the last scheduled backup went.
+func (c *listCommand) showLastScheduled(ctx *cmd.Context, result *params.BackupsListResult) {
+	started := result.LastScheduled.UTC().Format(time.RFC3339)
+	if result.LastScheduledError != "" {
+		ctx.Infof("The last scheduled backup, at %v, failed: %s", started, result.LastScheduledError)
+		return
+	}
+	ctx.Infof("The last scheduled backup, at %v, made %s.", started, result.LastScheduledID)
+}
@@ -4,12 +4,15 @@
 package backups_test
 
 import (
+	"time"
+
 	"github.com/juju/cmd"
 	"github.com/juju/cmd/cmdtesting"
 	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
+	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/juju/backups"
 	"github.com/juju/juju/jujuclient/jujuclienttesting"
 )
@@ -43,6 +46,50 @@ func (s *listSuite) TestBrief(c *gc.C) {
 	s.checkStd(c, ctx, out, "")
 }
 
+func (s *listSuite) TestSchedule(c *gc.C) {
+	client := s.setSuccess()
+	next := time.Date(2018, 7, 2, 0, 0, 0, 0, time.UTC)
+	client.listResult = params.BackupsListResult{
+		Schedule:      "@daily",
+		NextScheduled: &next,
+		CopyDir:       "/srv/backups",
+	}
+	ctx, err := cmdtesting.RunCommand(c, s.subcommand)
+	c.Assert(err, jc.ErrorIsNil)
+	out := s.metaresult.ID + "\n"
+	s.checkStd(c, ctx, out, `Backups are scheduled "@daily", the next at 2018-07-02T00:00:00Z, and copied to /srv/backups on each controller machine.`+"\n")
+}
+
+func (s *listSuite) TestLastScheduled(c *gc.C) {
+	client := s.setSuccess()
+	next := time.Date(2018, 7, 3, 0, 0, 0, 0, time.UTC)
+	last := time.Date(2018, 7, 2, 0, 0, 0, 0, time.UTC)
+	client.listResult = params.BackupsListResult{
+		Schedule:        "@daily",
+		NextScheduled:   &next,
+		LastScheduled:   &last,
+		LastScheduledID: "20180702-000000.deadbeef",
+	}
+	ctx, err := cmdtesting.RunCommand(c, s.subcommand)
+	c.Assert(err, jc.ErrorIsNil)
+	out := s.metaresult.ID + "\n"
+	s.checkStd(c, ctx, out, `Backups are scheduled "@daily", the next at 2018-07-03T00:00:00Z.`+"\n"+
+		"The last scheduled backup, at 2018-07-02T00:00:00Z, made 20180702-000000.deadbeef.\n")
+}
+
+func (s *listSuite) TestLastScheduledFailed(c *gc.C) {
+	client := s.setSuccess()
+	last := time.Date(2018, 7, 2, 0, 0, 0, 0, time.UTC)
+	client.listResult = params.BackupsListResult{
+		LastScheduled:      &last,
+		LastScheduledError: "creating backup: disk full",
+	}
+	ctx, err := cmdtesting.RunCommand(c, s.subcommand)
+	c.Assert(err, jc.ErrorIsNil)
+	out := s.metaresult.ID + "\n"
+	s.checkStd(c, ctx, out, "The last scheduled backup, at 2018-07-02T00:00:00Z, failed: creating backup: disk full\n")
+}
+
 func (s *listSuite) TestError(c *gc.C) {
 	s.setFailure("failed!")
 	_, err := cmdtesting.RunCommand(c, s.subcommand)
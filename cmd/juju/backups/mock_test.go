@@ -7,6 +7,7 @@ package backups_test
 import (
 	io "io"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	backups "github.com/juju/juju/api/backups"
@@ -122,6 +123,32 @@ func (mr *MockAPIClientMockRecorder) Create(arg0, arg1, arg2 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAPIClient)(nil).Create), arg0, arg1, arg2)
 }
 
+// CreateEncrypted mocks base method
+func (m *MockAPIClient) CreateEncrypted(arg0 string, arg1, arg2 bool, arg3, arg4 string) (*params.BackupsMetadataResult, error) {
+	ret := m.ctrl.Call(m, "CreateEncrypted", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*params.BackupsMetadataResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateEncrypted indicates an expected call of CreateEncrypted
+func (mr *MockAPIClientMockRecorder) CreateEncrypted(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEncrypted", reflect.TypeOf((*MockAPIClient)(nil).CreateEncrypted), arg0, arg1, arg2, arg3, arg4)
+}
+
+// CreateIncremental mocks base method
+func (m *MockAPIClient) CreateIncremental(arg0, arg1 string) (*params.BackupsMetadataResult, error) {
+	ret := m.ctrl.Call(m, "CreateIncremental", arg0, arg1)
+	ret0, _ := ret[0].(*params.BackupsMetadataResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateIncremental indicates an expected call of CreateIncremental
+func (mr *MockAPIClientMockRecorder) CreateIncremental(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIncremental", reflect.TypeOf((*MockAPIClient)(nil).CreateIncremental), arg0, arg1)
+}
+
 // Download mocks base method
 func (m *MockAPIClient) Download(arg0 string) (io.ReadCloser, error) {
 	ret := m.ctrl.Call(m, "Download", arg0)
@@ -190,6 +217,18 @@ func (mr *MockAPIClientMockRecorder) Restore(arg0, arg1 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockAPIClient)(nil).Restore), arg0, arg1)
 }
 
+// RestoreEncrypted mocks base method
+func (m *MockAPIClient) RestoreEncrypted(arg0, arg1, arg2 string, arg3 backups.ClientConnection) error {
+	ret := m.ctrl.Call(m, "RestoreEncrypted", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreEncrypted indicates an expected call of RestoreEncrypted
+func (mr *MockAPIClientMockRecorder) RestoreEncrypted(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreEncrypted", reflect.TypeOf((*MockAPIClient)(nil).RestoreEncrypted), arg0, arg1, arg2, arg3)
+}
+
 // RestoreReader mocks base method
 func (m *MockAPIClient) RestoreReader(arg0 io.ReadSeeker, arg1 *params.BackupsMetadataResult, arg2 backups.ClientConnection) error {
 	ret := m.ctrl.Call(m, "RestoreReader", arg0, arg1, arg2)
@@ -202,6 +241,18 @@ func (mr *MockAPIClientMockRecorder) RestoreReader(arg0, arg1, arg2 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreReader", reflect.TypeOf((*MockAPIClient)(nil).RestoreReader), arg0, arg1, arg2)
 }
 
+// RestoreToTime mocks base method
+func (m *MockAPIClient) RestoreToTime(arg0 string, arg1 time.Time, arg2 backups.ClientConnection) error {
+	ret := m.ctrl.Call(m, "RestoreToTime", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreToTime indicates an expected call of RestoreToTime
+func (mr *MockAPIClientMockRecorder) RestoreToTime(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreToTime", reflect.TypeOf((*MockAPIClient)(nil).RestoreToTime), arg0, arg1, arg2)
+}
+
 // Upload mocks base method
 func (m *MockAPIClient) Upload(arg0 io.ReadSeeker, arg1 params.BackupsMetadataResult) (string, error) {
 	ret := m.ctrl.Call(m, "Upload", arg0, arg1)
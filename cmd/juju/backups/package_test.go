@@ -10,6 +10,7 @@ import (
 	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
@@ -139,13 +140,15 @@ func (s *BaseBackupsSuite) checkStd(c *gc.C, ctx *cmd.Context, out, err string)
 // Replace this fakeAPIClient with MockAPIClient for all tests.
 type fakeAPIClient struct {
 	metaresult *params.BackupsMetadataResult
+	listResult params.BackupsListResult
 	archive    io.ReadCloser
 	err        error
 
-	calls []string
-	args  []string
-	idArg string
-	notes string
+	calls      []string
+	args       []string
+	idArg      string
+	notes      string
+	uploadMeta params.BackupsMetadataResult
 }
 
 func (f *fakeAPIClient) Check(c *gc.C, id, notes string, calls ...string) {
@@ -174,6 +177,26 @@ func (c *fakeAPIClient) Create(notes string, keepCopy, noDownload bool) (*params
 	return createResult, nil
 }
 
+func (c *fakeAPIClient) CreateEncrypted(notes string, keepCopy, noDownload bool, passphrase, publicKey string) (*params.BackupsMetadataResult, error) {
+	c.calls = append(c.calls, "CreateEncrypted")
+	c.args = append(c.args, notes, fmt.Sprintf("%t", keepCopy), fmt.Sprintf("%t", noDownload), passphrase, publicKey)
+	c.notes = notes
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.metaresult, nil
+}
+
+func (c *fakeAPIClient) CreateIncremental(base, notes string) (*params.BackupsMetadataResult, error) {
+	c.calls = append(c.calls, "CreateIncremental")
+	c.args = append(c.args, base, notes)
+	c.notes = notes
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.metaresult, nil
+}
+
 func (c *fakeAPIClient) Info(id string) (*params.BackupsMetadataResult, error) {
 	c.calls = append(c.calls, "Info")
 	c.args = append(c.args, id)
@@ -189,7 +212,7 @@ func (c *fakeAPIClient) List() (*params.BackupsListResult, error) {
 	if c.err != nil {
 		return nil, c.err
 	}
-	var result params.BackupsListResult
+	result := c.listResult
 	result.List = []params.BackupsMetadataResult{*c.metaresult}
 	return &result, nil
 }
@@ -205,6 +228,7 @@ func (c *fakeAPIClient) Download(id string) (io.ReadCloser, error) {
 
 func (c *fakeAPIClient) Upload(ar io.ReadSeeker, meta params.BackupsMetadataResult) (string, error) {
 	c.args = append(c.args, "ar", "meta")
+	c.uploadMeta = meta
 	if c.err != nil {
 		return "", c.err
 	}
@@ -234,3 +258,11 @@ func (c *fakeAPIClient) RestoreReader(io.ReadSeeker, *params.BackupsMetadataResu
 func (c *fakeAPIClient) Restore(string, apibackups.ClientConnection) error {
 	return nil
 }
+
+func (c *fakeAPIClient) RestoreToTime(string, time.Time, apibackups.ClientConnection) error {
+	return nil
+}
+
+func (c *fakeAPIClient) RestoreEncrypted(string, string, string, apibackups.ClientConnection) error {
+	return nil
+}
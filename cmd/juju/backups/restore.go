@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"io"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
@@ -21,6 +23,7 @@ import (
 	"github.com/juju/juju/cmd/juju/controller"
 	"github.com/juju/juju/cmd/modelcmd"
 	"github.com/juju/juju/environs/bootstrap"
+	statebackups "github.com/juju/juju/state/backups"
 )
 
 // NewRestoreCommand returns a command used to restore a backup.
@@ -34,10 +37,15 @@ func NewRestoreCommand() cmd.Command {
 // it is invoked with "juju restore-backup".
 type restoreCommand struct {
 	CommandBase
+	keyFlags
 	getModelStatusAPI func() (ModelStatusAPI, error)
 
 	Filename string
 	BackupId string
+	ToTime   time.Time
+	DryRun   bool
+
+	toTime string
 }
 
 // RestoreAPI is used to invoke various API calls.
@@ -48,6 +56,9 @@ type RestoreAPI interface {
 	// Restore is taken from backups.Client.
 	Restore(backupId string, newClient backups.ClientConnection) error
 
+	// RestoreToTime is taken from backups.Client.
+	RestoreToTime(backupId string, toTime time.Time, newClient backups.ClientConnection) error
+
 	// RestoreReader is taken from backups.Client.
 	RestoreReader(r io.ReadSeeker, meta *params.BackupsMetadataResult, newClient backups.ClientConnection) error
 }
@@ -72,8 +83,32 @@ machines will be added or removed during the restore process.
 Note: Extra care is needed to restore in an HA environment, please see
 https://docs.jujucharms.com/stable/controllers-backup for more information.
 
+Use --to-time with the ID of a full backup kept on the controller to restore
+the database as it was at a later time, by replaying the changes recorded in
+the backup's incremental backups (see "juju create-backup --base"). The time
+is given in RFC3339 format, and must be covered by the incremental backups.
+
+An encrypted backup (see "juju create-backup --passphrase-file") is restored
+with the key given by --passphrase-file or --private-key. The key is sent to
+the controller, which decrypts the backup as it restores it; neither the key
+nor the decrypted archive is kept there, and an encrypted backup file is
+uploaded still encrypted. --to-time cannot be used with an encrypted backup.
+
+Use --dry-run to check the backup, and report what restoring it would replace,
+without changing the controller (see also "juju verify-backup"). A dry run
+can be made in an HA environment.
+
 If the provided state cannot be restored, this command will fail with
 an explanation.
+
+Examples:
+    juju restore-backup --file juju-backup-20180701-000000.tar.gz
+    juju restore-backup --id 20180701-000000.2cd1b5e1-4ad8-4e12-8e6c-4f06bd6ca5e1
+    juju restore-backup --id 20180701-000000.2cd1b5e1-4ad8-4e12-8e6c-4f06bd6ca5e1 \
+        --to-time 2018-07-01T14:30:00Z
+    juju restore-backup --file juju-backup-20180701-000000.tar.gz --dry-run
+    juju restore-backup --file juju-backup-20180701-000000.tar.gz.gpg \
+        --passphrase-file ~/.juju-backup-passphrase
 `
 
 // Info returns the content for --help.
@@ -91,6 +126,9 @@ func (c *restoreCommand) SetFlags(f *gnuflag.FlagSet) {
 	c.CommandBase.SetFlags(f)
 	f.StringVar(&c.Filename, "file", "", "Provide a file to be used as the backup")
 	f.StringVar(&c.BackupId, "id", "", "Provide the name of the backup to be restored")
+	f.StringVar(&c.toTime, "to-time", "", "Replay incremental backups up to this time (RFC3339)")
+	f.BoolVar(&c.DryRun, "dry-run", false, "Report what would be restored without changing the controller")
+	c.keyFlags.setFlags(f)
 }
 
 // Init is where the preconditions for this command can be checked.
@@ -101,6 +139,17 @@ func (c *restoreCommand) Init(args []string) error {
 	if c.Filename != "" && c.BackupId != "" {
 		return errors.Errorf("you must specify either a file or a backup id but not both.")
 	}
+	c.ToTime = time.Time{}
+	if c.toTime != "" {
+		if c.BackupId == "" {
+			return errors.Errorf("--to-time can only be used with a backup id")
+		}
+		var err error
+		c.ToTime, err = time.Parse(time.RFC3339, c.toTime)
+		if err != nil {
+			return errors.Errorf("expected --to-time in RFC3339 format, got %q", c.toTime)
+		}
+	}
 
 	if c.Filename != "" {
 		var err error
@@ -160,24 +209,29 @@ func (c *restoreCommand) Run(ctx *cmd.Context) error {
 		}
 	}
 
-	// Don't allow restore in an HA environment
-	controllerModelUUID, modelStatus, err := c.modelStatus()
-	if err != nil {
-		return errors.Trace(err)
-	}
-	activeCount, _ := controller.ControllerMachineCounts(controllerModelUUID, modelStatus)
-	if activeCount > 1 {
-		return errors.Errorf("unable to restore backup in HA configuration.  For help see https://docs.jujucharms.com/stable/controllers-backup")
+	// Don't allow restore in an HA environment. A dry run doesn't
+	// change the controller, so it's allowed.
+	if !c.DryRun {
+		controllerModelUUID, modelStatus, err := c.modelStatus()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		activeCount, _ := controller.ControllerMachineCounts(controllerModelUUID, modelStatus)
+		if activeCount > 1 {
+			return errors.Errorf("unable to restore backup in HA configuration.  For help see https://docs.jujucharms.com/stable/controllers-backup")
+		}
 	}
 
 	var archive ArchiveReader
 	var meta *params.BackupsMetadataResult
+	// key is set if the backup is encrypted.
+	var key *statebackups.ArchiveKey
 	target := c.BackupId
 	if c.Filename != "" {
 		// Read archive specified by the Filename
 		target = c.Filename
 		var err error
-		archive, meta, err = getArchive(c.Filename)
+		archive, meta, key, err = c.openArchive(c.Filename)
 		if err != nil {
 			return errors.Trace(err)
 		}
@@ -190,11 +244,33 @@ func (c *restoreCommand) Run(ctx *cmd.Context) error {
 	}
 	defer client.Close()
 
+	if c.DryRun {
+		return errors.Trace(c.dryRun(ctx, client, archive, target))
+	}
+
+	if archive == nil && c.keyFlags.isSet() {
+		if key, err = c.storedKey(client); err != nil {
+			return errors.Trace(err)
+		}
+	}
+
 	// We have a backup client, now use the relevant method
-	// to restore the backup.
-	if c.Filename != "" {
+	// to restore the backup. An encrypted backup is only ever
+	// decrypted by the controller as it's restored.
+	switch {
+	case archive != nil && key != nil:
+		var id string
+		id, err = client.Upload(archive, *meta)
+		if err == nil {
+			err = client.RestoreEncrypted(id, key.Passphrase, key.PrivateKey, c.newClient)
+		}
+	case archive != nil:
 		err = client.RestoreReader(archive, meta, c.newClient)
-	} else {
+	case key != nil:
+		err = client.RestoreEncrypted(c.BackupId, key.Passphrase, key.PrivateKey, c.newClient)
+	case !c.ToTime.IsZero():
+		err = client.RestoreToTime(c.BackupId, c.ToTime, c.newClient)
+	default:
 		err = client.Restore(c.BackupId, c.newClient)
 	}
 	if err != nil {
@@ -203,3 +279,97 @@ func (c *restoreCommand) Run(ctx *cmd.Context) error {
 	fmt.Fprintf(ctx.Stdout, "restore from %q completed\n", target)
 	return nil
 }
+
+// dryRun verifies the backup, and reports what restoring it would
+// replace, without changing the controller.
+func (c *restoreCommand) dryRun(ctx *cmd.Context, client APIClient, archive ArchiveReader, target string) error {
+	key, err := c.key()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	var v *statebackups.Verification
+	if archive != nil && key != nil {
+		v, err = statebackups.VerifyEncryptedArchive(archive, nil, *key)
+	} else if archive != nil {
+		v, err = statebackups.VerifyArchive(archive, nil)
+	} else {
+		v, err = verifyStored(client, c.BackupId, key)
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !c.ToTime.IsZero() {
+		if err := c.checkIncrementals(client, v); err != nil {
+			return errors.Trace(err)
+		}
+	}
+
+	fmt.Fprintf(ctx.Stdout, "restoring %q would:\n", target)
+	if meta := v.Metadata; meta != nil {
+		fmt.Fprintf(ctx.Stdout, "  restore model %q as backed up from machine %q at %v by Juju %v\n",
+			meta.Origin.Model, meta.Origin.Machine, meta.Started, meta.Origin.Version)
+	}
+	var databases []string
+	for name := range v.Databases {
+		databases = append(databases, name)
+	}
+	sort.Strings(databases)
+	if len(databases) > 0 {
+		fmt.Fprintf(ctx.Stdout, "  replace the databases: %s\n", strings.Join(databases, ", "))
+	}
+	if !c.ToTime.IsZero() {
+		fmt.Fprintf(ctx.Stdout, "  replay the incremental backups' changes up to %s\n", c.ToTime.Format(time.RFC3339))
+	}
+	for _, path := range v.RestoredPaths() {
+		fmt.Fprintf(ctx.Stdout, "  replace %s\n", path)
+	}
+	for _, problem := range v.Problems {
+		fmt.Fprintf(ctx.Stdout, "problem: %s\n", problem)
+	}
+	if len(v.Problems) > 0 {
+		return errors.Errorf("backup %q failed verification", target)
+	}
+	fmt.Fprintln(ctx.Stdout, "dry run: the controller was not changed")
+	return nil
+}
+
+// storedKey returns the key from the flags if the stored backup being
+// restored is encrypted, or nil if it isn't.
+func (c *restoreCommand) storedKey(client APIClient) (*statebackups.ArchiveKey, error) {
+	result, err := client.Info(c.BackupId)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if !result.Encrypted {
+		return nil, nil
+	}
+	key, err := c.requireKey(c.BackupId)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if !c.ToTime.IsZero() {
+		return nil, errors.Errorf("cannot use --to-time with encrypted backup %q", c.BackupId)
+	}
+	return &key, nil
+}
+
+// checkIncrementals records a problem if the incremental backups of
+// the backup being restored don't extend to the time to restore to.
+func (c *restoreCommand) checkIncrementals(client APIClient, v *statebackups.Verification) error {
+	list, err := client.List()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	var end time.Time
+	for _, result := range list.List {
+		if result.Base == c.BackupId && result.OplogEnd.After(end) {
+			end = result.OplogEnd
+		}
+	}
+	if end.Before(c.ToTime) {
+		v.Problems = append(v.Problems, fmt.Sprintf(
+			"incremental backups of %q only extend to %s, not %s",
+			c.BackupId, end.Format(time.RFC3339), c.ToTime.Format(time.RFC3339)))
+	}
+	return nil
+}
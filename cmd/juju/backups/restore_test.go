@@ -4,14 +4,19 @@
 package backups_test
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"path/filepath"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/juju/cmd"
 	"github.com/juju/cmd/cmdtesting"
 	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version"
 	gc "gopkg.in/check.v1"
 	"gopkg.in/juju/names.v2"
 
@@ -23,6 +28,8 @@ import (
 	"github.com/juju/juju/jujuclient"
 	_ "github.com/juju/juju/provider/dummy"
 	_ "github.com/juju/juju/provider/lxd"
+	statebackups "github.com/juju/juju/state/backups"
+	bt "github.com/juju/juju/state/backups/testing"
 	"github.com/juju/juju/testing"
 )
 
@@ -115,6 +122,7 @@ type restoreBackupArgParsing struct {
 	errMatch string
 	id       string
 	filename string
+	toTime   time.Time
 }
 
 var testRestoreBackupArgParsing = []restoreBackupArgParsing{
@@ -138,6 +146,22 @@ var testRestoreBackupArgParsing = []restoreBackupArgParsing{
 		args:     []string{"--file", "afile"},
 		filename: "afile",
 	},
+	{
+		title:  "id and time",
+		args:   []string{"--id", "anid", "--to-time", "2018-07-01T14:30:00Z"},
+		id:     "anid",
+		toTime: time.Date(2018, 7, 1, 14, 30, 0, 0, time.UTC),
+	},
+	{
+		title:    "file and time",
+		args:     []string{"--file", "afile", "--to-time", "2018-07-01T14:30:00Z"},
+		errMatch: "--to-time can only be used with a backup id",
+	},
+	{
+		title:    "bad time",
+		args:     []string{"--id", "anid", "--to-time", "yesterday"},
+		errMatch: `expected --to-time in RFC3339 format, got "yesterday"`,
+	},
 }
 
 func (s *restoreSuite) TestArgParsing(c *gc.C) {
@@ -150,6 +174,7 @@ func (s *restoreSuite) TestArgParsing(c *gc.C) {
 			expectedName := filepath.Base(test.filename)
 			c.Assert(obtainedName, gc.Equals, expectedName)
 			c.Assert(s.command.BackupId, gc.Equals, test.id)
+			c.Assert(s.command.ToTime.Equal(test.toTime), jc.IsTrue)
 		} else {
 			c.Assert(err, gc.ErrorMatches, test.errMatch)
 		}
@@ -204,6 +229,74 @@ func (s *restoreSuite) TestRestoreFromBackupId(c *gc.C) {
 	c.Check(cmdtesting.Stdout(ctx), gc.Equals, out)
 }
 
+func (s *restoreSuite) TestRestoreFromBackupIdToTime(c *gc.C) {
+	ctlr, apiClient, _, modelStatusClient := s.patch(c, nil)
+	defer ctlr.Finish()
+	expectModelStatus(modelStatusClient)
+	toTime := time.Date(2018, 7, 1, 14, 30, 0, 0, time.UTC)
+	gomock.InOrder(
+		apiClient.EXPECT().RestoreToTime("an_id", toTime, gomock.Any()).Return(
+			nil,
+		),
+		apiClient.EXPECT().Close(),
+	)
+	ctx, err := cmdtesting.RunCommand(c, s.wrappedCommand, "restore", "--id", "an_id", "--to-time", "2018-07-01T14:30:00Z")
+	c.Assert(err, jc.ErrorIsNil)
+	out := fmt.Sprintf("restore from %q completed\n", s.command.BackupId)
+	c.Check(cmdtesting.Stdout(ctx), gc.Equals, out)
+}
+
+func (s *restoreSuite) TestRestoreDryRun(c *gc.C) {
+	ctlr, apiClient, _, _ := s.patch(c, nil)
+	defer ctlr.Finish()
+	// A dry run is allowed in HA, so the controller machines
+	// aren't checked.
+	data := newTestArchive(c)
+	gomock.InOrder(
+		apiClient.EXPECT().Info("an_id").Return(&params.BackupsMetadataResult{
+			ID:       "an_id",
+			Checksum: checksum(data),
+			Model:    bt.NewMetadataStarted().Origin.Model,
+			Version:  version.MustParse("2.4.0"),
+		}, nil),
+		apiClient.EXPECT().Download("an_id").Return(ioutil.NopCloser(bytes.NewBuffer(data)), nil),
+		apiClient.EXPECT().Close(),
+	)
+	ctx, err := cmdtesting.RunCommand(c, s.wrappedCommand, "restore", "--id", "an_id", "--dry-run")
+	c.Assert(err, jc.ErrorIsNil)
+	out := cmdtesting.Stdout(ctx)
+	c.Check(out, jc.HasPrefix, `restoring "an_id" would:`+"\n")
+	c.Check(out, jc.Contains, "  replace the databases: juju\n")
+	c.Check(out, jc.Contains, "  replace /var/lib/juju/agents\n")
+	c.Check(out, jc.HasSuffix, "dry run: the controller was not changed\n")
+}
+
+func (s *restoreSuite) TestRestoreDryRunToTimeNotCovered(c *gc.C) {
+	ctlr, apiClient, _, _ := s.patch(c, nil)
+	defer ctlr.Finish()
+	data := newTestArchive(c)
+	gomock.InOrder(
+		apiClient.EXPECT().Info("an_id").Return(&params.BackupsMetadataResult{
+			ID:      "an_id",
+			Model:   bt.NewMetadataStarted().Origin.Model,
+			Version: version.MustParse("2.4.0"),
+		}, nil),
+		apiClient.EXPECT().Download("an_id").Return(ioutil.NopCloser(bytes.NewBuffer(data)), nil),
+		apiClient.EXPECT().List().Return(&params.BackupsListResult{
+			List: []params.BackupsMetadataResult{{
+				ID:       "inc_id",
+				Base:     "an_id",
+				OplogEnd: time.Date(2018, 7, 1, 12, 0, 0, 0, time.UTC),
+			}},
+		}, nil),
+		apiClient.EXPECT().Close(),
+	)
+	ctx, err := cmdtesting.RunCommand(c, s.wrappedCommand, "restore", "--id", "an_id", "--to-time", "2018-07-01T14:30:00Z", "--dry-run")
+	c.Assert(err, gc.ErrorMatches, `backup "an_id" failed verification`)
+	c.Check(cmdtesting.Stdout(ctx), jc.Contains,
+		`problem: incremental backups of "an_id" only extend to 2018-07-01T12:00:00Z, not 2018-07-01T14:30:00Z`)
+}
+
 func (s *restoreSuite) TestRestoreFromBackupIdFail(c *gc.C) {
 	ctlr, apiClient, _, modelStatusClient := s.patch(c, nil)
 	defer ctlr.Finish()
@@ -218,6 +311,64 @@ func (s *restoreSuite) TestRestoreFromBackupIdFail(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "restore failed")
 }
 
+func (s *restoreSuite) TestRestoreFromEncryptedFile(c *gc.C) {
+	ctlr, apiClient, _, modelStatusClient := s.patch(c, nil)
+	defer ctlr.Finish()
+	expectModelStatus(modelStatusClient)
+	// The archive is uploaded still encrypted, for the controller to
+	// decrypt as it restores it.
+	encrypted := encrypt(c, string(newTestArchive(c)), statebackups.ArchiveKey{Passphrase: "secret"})
+	var uploaded []byte
+	gomock.InOrder(
+		apiClient.EXPECT().Upload(gomock.Any(), gomock.Any()).Do(
+			func(r io.ReadSeeker, meta params.BackupsMetadataResult) {
+				c.Check(meta.Encrypted, jc.IsTrue)
+				var err error
+				uploaded, err = ioutil.ReadAll(r)
+				c.Check(err, jc.ErrorIsNil)
+			},
+		).Return("new_id", nil),
+		apiClient.EXPECT().RestoreEncrypted("new_id", "secret", "", gomock.Any()).Return(nil),
+		apiClient.EXPECT().Close(),
+	)
+	filename := filepath.Join(c.MkDir(), "backup.tar.gz.gpg")
+	err := ioutil.WriteFile(filename, encrypted, 0600)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = cmdtesting.RunCommand(c, s.wrappedCommand, "restore", "--file", filename,
+		"--passphrase-file", writePassphrase(c, "secret"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(uploaded, jc.DeepEquals, encrypted)
+}
+
+func (s *restoreSuite) TestRestoreFromEncryptedFileMissingKey(c *gc.C) {
+	ctlr, _, _, modelStatusClient := s.patch(c, nil)
+	defer ctlr.Finish()
+	expectModelStatus(modelStatusClient)
+	filename := filepath.Join(c.MkDir(), "backup.tar.gz.gpg")
+	err := ioutil.WriteFile(filename, encrypt(c, "<archive>", statebackups.ArchiveKey{Passphrase: "secret"}), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = cmdtesting.RunCommand(c, s.wrappedCommand, "restore", "--file", filename)
+	c.Assert(err, gc.ErrorMatches, `backup ".*" is encrypted; use --passphrase-file or --private-key to decrypt it`)
+}
+
+func (s *restoreSuite) TestRestoreFromEncryptedBackupId(c *gc.C) {
+	ctlr, apiClient, _, modelStatusClient := s.patch(c, nil)
+	defer ctlr.Finish()
+	expectModelStatus(modelStatusClient)
+	// The backup isn't downloaded; the controller decrypts it as it
+	// restores it.
+	gomock.InOrder(
+		apiClient.EXPECT().Info("an_id").Return(&params.BackupsMetadataResult{Encrypted: true}, nil),
+		apiClient.EXPECT().RestoreEncrypted("an_id", "secret", "", gomock.Any()).Return(nil),
+		apiClient.EXPECT().Close(),
+	)
+	ctx, err := cmdtesting.RunCommand(c, s.wrappedCommand, "restore", "--id", "an_id",
+		"--passphrase-file", writePassphrase(c, "secret"))
+	c.Assert(err, jc.ErrorIsNil)
+	out := fmt.Sprintf("restore from %q completed\n", s.command.BackupId)
+	c.Check(cmdtesting.Stdout(ctx), gc.Equals, out)
+}
+
 func (s *restoreSuite) TestRestoreFromBackupGetArchiveFail(c *gc.C) {
 	ctlr, _, _, modelStatusClient := s.patch(c, errors.New("get archive fail"))
 	defer ctlr.Finish()
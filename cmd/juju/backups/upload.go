@@ -8,6 +8,7 @@ import (
 
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
+	"github.com/juju/gnuflag"
 
 	"github.com/juju/juju/apiserver/params"
 	jujucmd "github.com/juju/juju/cmd"
@@ -16,6 +17,10 @@ import (
 
 const uploadDoc = `
 upload-backup sends a backup archive file to remote storage.
+
+An encrypted archive is stored as it is, still encrypted; its metadata is
+read by decrypting it with the key given by --passphrase-file or
+--private-key, which the controller is never sent.
 `
 
 // NewUploadCommand returns a command used to send a backup
@@ -27,6 +32,7 @@ func NewUploadCommand() cmd.Command {
 // uploadCommand is the sub-command for uploading a backup archive.
 type uploadCommand struct {
 	CommandBase
+	keyFlags
 	// Filename is where to find the archive to upload.
 	Filename string
 }
@@ -41,6 +47,12 @@ func (c *uploadCommand) Info() *cmd.Info {
 	})
 }
 
+// SetFlags implements Command.SetFlags.
+func (c *uploadCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.CommandBase.SetFlags(f)
+	c.keyFlags.setFlags(f)
+}
+
 // Init implements Command.Init.
 func (c *uploadCommand) Init(args []string) error {
 	if len(args) == 0 {
@@ -67,7 +79,7 @@ func (c *uploadCommand) Run(ctx *cmd.Context) error {
 	}
 	defer client.Close()
 
-	archive, meta, err := getArchive(c.Filename)
+	archive, meta, err := c.openArchive()
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -100,6 +112,25 @@ func (c *uploadCommand) Run(ctx *cmd.Context) error {
 	return nil
 }
 
+// openArchive opens the archive file to upload, along with its
+// metadata. An encrypted archive is uploaded still encrypted.
+func (c *uploadCommand) openArchive() (ArchiveReader, *params.BackupsMetadataResult, error) {
+	encrypted, err := isEncryptedFile(c.Filename)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	if !encrypted {
+		archive, meta, err := getArchive(c.Filename)
+		return archive, meta, errors.Trace(err)
+	}
+	key, err := c.requireKey(c.Filename)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	archive, meta, err := getEncryptedArchive(c.Filename, key)
+	return archive, meta, errors.Trace(err)
+}
+
 func (c *uploadCommand) getStoredMetadata(id string) (*params.BackupsMetadataResult, error) {
 	// TODO(ericsnow) lp-1399722 This should be addressed.
 	// There is at least anecdotal evidence that we cannot use an API
@@ -6,6 +6,7 @@ package backups_test
 import (
 	"archive/tar"
 	"compress/gzip"
+	"io/ioutil"
 	"os"
 
 	"github.com/juju/cmd"
@@ -16,6 +17,7 @@ import (
 
 	"github.com/juju/juju/cmd/juju/backups"
 	"github.com/juju/juju/jujuclient/jujuclienttesting"
+	statebackups "github.com/juju/juju/state/backups"
 )
 
 type uploadSuite struct {
@@ -92,3 +94,37 @@ func (s *uploadSuite) TestError(c *gc.C) {
 	_, err := cmdtesting.RunCommand(c, s.command, s.filename)
 	c.Check(errors.Cause(err), gc.ErrorMatches, "failed!")
 }
+
+// encryptArchive encrypts the archive file with the passphrase, and
+// returns the encrypted data.
+func (s *uploadSuite) encryptArchive(c *gc.C, passphrase string) []byte {
+	data, err := ioutil.ReadFile(s.filename)
+	c.Assert(err, jc.ErrorIsNil)
+	encrypted := encrypt(c, string(data), statebackups.ArchiveKey{Passphrase: passphrase})
+	err = ioutil.WriteFile(s.filename, encrypted, 0600)
+	c.Assert(err, jc.ErrorIsNil)
+	return encrypted
+}
+
+func (s *uploadSuite) TestEncrypted(c *gc.C) {
+	s.createArchive(c)
+	encrypted := s.encryptArchive(c, "secret")
+	client := s.setSuccess()
+	_, err := cmdtesting.RunCommand(c, s.command, s.filename,
+		"--passphrase-file", writePassphrase(c, "secret"))
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The archive is uploaded still encrypted.
+	c.Check(client.uploadMeta.Encrypted, jc.IsTrue)
+	c.Check(client.uploadMeta.Checksum, gc.Equals, checksum(encrypted))
+	c.Check(client.uploadMeta.Size, gc.Equals, int64(len(encrypted)))
+}
+
+func (s *uploadSuite) TestEncryptedMissingKey(c *gc.C) {
+	s.createArchive(c)
+	s.encryptArchive(c, "secret")
+	s.setSuccess()
+	_, err := cmdtesting.RunCommand(c, s.command, s.filename)
+	c.Check(err, gc.ErrorMatches,
+		`backup ".*" is encrypted; use --passphrase-file or --private-key to decrypt it`)
+}
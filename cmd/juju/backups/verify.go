@@ -0,0 +1,206 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/gnuflag"
+
+	apiserverbackups "github.com/juju/juju/apiserver/facades/client/backups"
+	jujucmd "github.com/juju/juju/cmd"
+	"github.com/juju/juju/cmd/modelcmd"
+	statebackups "github.com/juju/juju/state/backups"
+)
+
+const verifyDoc = `
+verify-backup checks that a backup archive could be restored, without
+restoring it. The archive is unpacked, and its metadata, its files and the
+collections in its database dump are checked against those expected for the
+Juju version that created it.
+
+The backup is either a local archive file, or the ID of a backup stored on
+the controller, which is downloaded to be checked; its checksum and size
+must then match those the controller recorded. An encrypted archive is
+decrypted to be checked with the key given by --passphrase-file or
+--private-key.
+
+Examples:
+    juju verify-backup juju-backup-20180701-000000.tar.gz
+    juju verify-backup 20180701-000000.2cd1b5e1-4ad8-4e12-8e6c-4f06bd6ca5e1
+    juju verify-backup juju-backup-20180701-000000.tar.gz.gpg \
+        --passphrase-file ~/.juju-backup-passphrase
+
+See also:
+    create-backup
+    restore-backup
+`
+
+// NewVerifyCommand returns a command used to verify a backup archive.
+func NewVerifyCommand() cmd.Command {
+	return modelcmd.Wrap(&verifyCommand{})
+}
+
+// verifyCommand is the sub-command for verifying a backup archive.
+type verifyCommand struct {
+	CommandBase
+	keyFlags
+	// Target is the backup ID or the archive filename to verify.
+	Target string
+}
+
+// Info implements Command.Info.
+func (c *verifyCommand) Info() *cmd.Info {
+	return jujucmd.Info(&cmd.Info{
+		Name:    "verify-backup",
+		Args:    "<ID>|<filename>",
+		Purpose: "Check that a backup archive could be restored.",
+		Doc:     verifyDoc,
+	})
+}
+
+// SetFlags implements Command.SetFlags.
+func (c *verifyCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.CommandBase.SetFlags(f)
+	c.keyFlags.setFlags(f)
+}
+
+// Init implements Command.Init.
+func (c *verifyCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing backup ID or filename")
+	}
+	target, args := args[0], args[1:]
+	if err := cmd.CheckEmpty(args); err != nil {
+		return errors.Trace(err)
+	}
+	c.Target = target
+	return nil
+}
+
+// Run implements Command.Run.
+func (c *verifyCommand) Run(ctx *cmd.Context) error {
+	if c.Log != nil {
+		if err := c.Log.Start(ctx); err != nil {
+			return err
+		}
+	}
+
+	key, err := c.key()
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	var v *statebackups.Verification
+	if _, err := os.Stat(c.Target); err == nil {
+		v, err = verifyFile(c.Target, key)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	} else {
+		client, err := c.NewAPIClient()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		defer client.Close()
+		v, err = verifyStored(client, c.Target, key)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+
+	dumpVerification(ctx, v)
+	if len(v.Problems) > 0 {
+		return errors.Errorf("backup %q failed verification", c.Target)
+	}
+	fmt.Fprintf(ctx.Stdout, "backup %q verified\n", c.Target)
+	return nil
+}
+
+// verifyFile verifies the backup archive in the local file, decrypting
+// it with the key if it's encrypted and the key isn't nil.
+func verifyFile(filename string, key *statebackups.ArchiveKey) (*statebackups.Verification, error) {
+	archive, err := os.Open(filename)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer archive.Close()
+	v, err := verifyArchive(archive, nil, key)
+	return v, errors.Trace(err)
+}
+
+// verifyStored downloads and verifies the backup stored on the
+// controller with the ID, decrypting it with the key if it's encrypted
+// and the key isn't nil.
+func verifyStored(client APIClient, id string, key *statebackups.ArchiveKey) (*statebackups.Verification, error) {
+	result, err := client.Info(id)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	expected := apiserverbackups.MetadataFromResult(*result)
+
+	archive, err := client.Download(id)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer archive.Close()
+	v, err := verifyArchive(archive, expected, key)
+	return v, errors.Trace(err)
+}
+
+// verifyArchive verifies the backup archive, decrypting it with the key
+// if it's encrypted and the key isn't nil.
+func verifyArchive(archive io.Reader, expected *statebackups.Metadata, key *statebackups.ArchiveKey) (*statebackups.Verification, error) {
+	if key == nil {
+		return statebackups.VerifyArchive(archive, expected)
+	}
+	return statebackups.VerifyEncryptedArchive(archive, expected, *key)
+}
+
+// dumpVerification writes the results of verifying a backup archive
+// to stdout.
+func dumpVerification(ctx *cmd.Context, v *statebackups.Verification) {
+	if meta := v.Metadata; meta != nil {
+		fmt.Fprintf(ctx.Stdout, "started:         %v\n", meta.Started)
+		fmt.Fprintf(ctx.Stdout, "model ID:        %q\n", meta.Origin.Model)
+		fmt.Fprintf(ctx.Stdout, "machine ID:      %q\n", meta.Origin.Machine)
+		fmt.Fprintf(ctx.Stdout, "juju version:    %v\n", meta.Origin.Version)
+		if meta.Base != "" {
+			fmt.Fprintf(ctx.Stdout, "base backup ID:  %q\n", meta.Base)
+		}
+	}
+	fmt.Fprintf(ctx.Stdout, "checksum:        %q\n", v.Checksum)
+	fmt.Fprintf(ctx.Stdout, "size (B):        %d\n", v.Size)
+	if v.Encrypted {
+		fmt.Fprintf(ctx.Stdout, "encrypted:       %v\n", v.Encrypted)
+	}
+	if len(v.Databases) > 0 {
+		fmt.Fprintf(ctx.Stdout, "databases:       %s\n", describeDatabases(v.Databases))
+	}
+	fmt.Fprintf(ctx.Stdout, "files:           %d\n", len(v.Files))
+	for _, problem := range v.Problems {
+		fmt.Fprintf(ctx.Stdout, "problem:         %s\n", problem)
+	}
+}
+
+// describeDatabases returns a summary of the dumped databases and the
+// number of collections in each.
+func describeDatabases(databases map[string][]string) string {
+	var names []string
+	for name := range databases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var parts []string
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s (%d collections)", name, len(databases[name])))
+	}
+	return strings.Join(parts, ", ")
+}
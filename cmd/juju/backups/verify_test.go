@@ -0,0 +1,135 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups_test
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/juju/cmd"
+	"github.com/juju/cmd/cmdtesting"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/cmd/juju/backups"
+	"github.com/juju/juju/jujuclient/jujuclienttesting"
+	statebackups "github.com/juju/juju/state/backups"
+	bt "github.com/juju/juju/state/backups/testing"
+)
+
+type verifySuite struct {
+	BaseBackupsSuite
+	subcommand cmd.Command
+}
+
+var _ = gc.Suite(&verifySuite{})
+
+func (s *verifySuite) SetUpTest(c *gc.C) {
+	s.BaseBackupsSuite.SetUpTest(c)
+	s.subcommand = backups.NewVerifyCommandForTest(jujuclienttesting.MinimalStore())
+}
+
+// newTestArchive returns the data of a backup archive that passes
+// verification.
+func newTestArchive(c *gc.C) []byte {
+	meta := bt.NewMetadataStarted()
+	meta.Origin.Version = version.MustParse("2.4.0")
+	files := []bt.File{
+		{Name: "var/lib/juju/agents/machine-0/agent.conf", Content: "<agent config>"},
+		{Name: "var/lib/juju/tools/2.4.0-bionic-amd64/jujud", Content: "<jujud>"},
+		{Name: "var/lib/juju/system-identity", Content: "<ssh key>"},
+		{Name: "var/lib/juju/server.pem", Content: "<cert>"},
+		{Name: "var/lib/juju/shared-secret", Content: "<secret>"},
+	}
+	dump := []bt.File{{Name: "juju", IsDir: true}}
+	for _, name := range []string{"applications", "controllers", "machines", "models", "settings", "txns", "units", "users"} {
+		dump = append(dump, bt.File{Name: "juju/" + name + ".bson", Content: "<BSON>"})
+	}
+	archive, err := bt.NewArchive(meta, files, dump)
+	c.Assert(err, jc.ErrorIsNil)
+	return archive.Bytes()
+}
+
+func checksum(data []byte) string {
+	sum := sha1.Sum(data)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func (s *verifySuite) TestMissingArgs(c *gc.C) {
+	_, err := cmdtesting.RunCommand(c, s.subcommand)
+	c.Check(err, gc.ErrorMatches, "missing backup ID or filename")
+}
+
+func (s *verifySuite) TestVerifyFile(c *gc.C) {
+	filename := filepath.Join(c.MkDir(), "backup.tar.gz")
+	err := ioutil.WriteFile(filename, newTestArchive(c), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+
+	ctx, err := cmdtesting.RunCommand(c, s.subcommand, filename)
+	c.Assert(err, jc.ErrorIsNil)
+	out := cmdtesting.Stdout(ctx)
+	c.Check(out, jc.Contains, "databases:       juju (8 collections)\n")
+	c.Check(out, jc.Contains, "files:           5\n")
+	c.Check(out, jc.HasSuffix, "backup \""+filename+"\" verified\n")
+}
+
+func (s *verifySuite) TestVerifyStored(c *gc.C) {
+	data := newTestArchive(c)
+	s.metaresult.Checksum = checksum(data)
+	s.metaresult.Size = int64(len(data))
+	s.metaresult.Model = bt.NewMetadataStarted().Origin.Model
+	s.metaresult.Version = version.MustParse("2.4.0")
+	client := s.setSuccess()
+	client.archive = ioutil.NopCloser(bytes.NewBuffer(data))
+
+	ctx, err := cmdtesting.RunCommand(c, s.subcommand, s.metaresult.ID)
+	c.Assert(err, jc.ErrorIsNil)
+	client.CheckCalls(c, "Info", "Download")
+	c.Check(cmdtesting.Stdout(ctx), jc.HasSuffix, "backup \"spam\" verified\n")
+}
+
+func (s *verifySuite) TestVerifyStoredChecksumMismatch(c *gc.C) {
+	data := newTestArchive(c)
+	s.metaresult.Checksum = "bad-checksum"
+	s.metaresult.Model = bt.NewMetadataStarted().Origin.Model
+	s.metaresult.Version = version.MustParse("2.4.0")
+	client := s.setSuccess()
+	client.archive = ioutil.NopCloser(bytes.NewBuffer(data))
+
+	ctx, err := cmdtesting.RunCommand(c, s.subcommand, s.metaresult.ID)
+	c.Check(err, gc.ErrorMatches, `backup "spam" failed verification`)
+	c.Check(cmdtesting.Stdout(ctx), jc.Contains,
+		`problem:         checksum "`+checksum(data)+`" does not match expected "bad-checksum"`)
+}
+
+func (s *verifySuite) TestVerifyEncryptedFile(c *gc.C) {
+	filename := filepath.Join(c.MkDir(), "backup.tar.gz.gpg")
+	data := encrypt(c, string(newTestArchive(c)), statebackups.ArchiveKey{Passphrase: "secret"})
+	err := ioutil.WriteFile(filename, data, 0600)
+	c.Assert(err, jc.ErrorIsNil)
+
+	ctx, err := cmdtesting.RunCommand(c, s.subcommand, filename,
+		"--passphrase-file", writePassphrase(c, "secret"))
+	c.Assert(err, jc.ErrorIsNil)
+	out := cmdtesting.Stdout(ctx)
+	c.Check(out, jc.Contains, "encrypted:       true\n")
+	c.Check(out, jc.Contains, "files:           5\n")
+	c.Check(out, jc.HasSuffix, "backup \""+filename+"\" verified\n")
+}
+
+func (s *verifySuite) TestVerifyEncryptedFileMissingKey(c *gc.C) {
+	filename := filepath.Join(c.MkDir(), "backup.tar.gz.gpg")
+	data := encrypt(c, string(newTestArchive(c)), statebackups.ArchiveKey{Passphrase: "secret"})
+	err := ioutil.WriteFile(filename, data, 0600)
+	c.Assert(err, jc.ErrorIsNil)
+
+	ctx, err := cmdtesting.RunCommand(c, s.subcommand, filename)
+	c.Check(err, gc.ErrorMatches, `backup ".*" failed verification`)
+	c.Check(cmdtesting.Stdout(ctx), jc.Contains,
+		"problem:         archive is encrypted; its key is needed to verify it\n")
+}
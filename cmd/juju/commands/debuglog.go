@@ -4,12 +4,15 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"time"
 
 	"github.com/juju/ansiterm"
+	"github.com/juju/clock"
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
 	"github.com/juju/gnuflag"
@@ -50,13 +53,34 @@ The '--include-module' and '--exclude-module' options filter by (dotted)
 logging module name. The module name can be truncated such that all loggers
 with the prefix will match.
 
+The '--include-location' and '--exclude-location' options filter by the
+source file that logged the message, optionally followed by ":<line-no>".
+
+The '--include-message' and '--exclude-message' options take a pattern
+that log messages must, or must not, contain. In a pattern '*' matches
+any sequence of characters and every other character matches itself.
+
+The '--since' and '--until' options limit the messages to a time range.
+Each takes either a time in RFC3339 format (e.g. 2018-10-01T12:00:00Z) or
+a duration (e.g. 30m or 2h) meaning that long before now. Once the time
+given by '--until' has passed, the command exits.
+
+All filtering is done by the controller, so only matching messages are
+sent to the client.
+
 The filtering options combine as follows:
 * All --include options are logically ORed together.
 * All --exclude options are logically ORed together.
 * All --include-module options are logically ORed together.
 * All --exclude-module options are logically ORed together.
-* The combined --include, --exclude, --include-module and --exclude-module
-  selections are logically ANDed to form the complete filter.
+* All --include-location options are logically ORed together.
+* All --exclude-location options are logically ORed together.
+* The combined entity, module, location, message and time selections are
+  logically ANDed to form the complete filter.
+
+The '--format json' option emits each message as a JSON object on its own
+line, with the fields "entity", "timestamp", "level", "module", "location"
+and "message". Timestamps are always in RFC3339 format and UTC.
 
 Examples:
 
@@ -95,6 +119,19 @@ new WARNING and ERROR messages as they are logged:
 
     juju debug-log --replay --level WARNING
 
+Show the messages logged in the last hour which mention "hook failed",
+excluding those from the juju.worker.uniter.operation module, and then exit:
+
+    juju debug-log --since 1h --until 0s \
+        --include-message "hook failed" \
+        --exclude-module juju.worker.uniter.operation
+
+Show all messages logged from the uniter's hook runner as JSON, one
+object per line:
+
+    juju debug-log --replay --no-tail --format json \
+        --include-location runner.go
+
 See also: 
     status
     ssh`
@@ -134,6 +171,11 @@ type debugLogCommand struct {
 
 	format string
 	tz     *time.Location
+
+	since        string
+	until        string
+	outputFormat string
+	clock        clock.Clock
 }
 
 func (c *debugLogCommand) SetFlags(f *gnuflag.FlagSet) {
@@ -144,6 +186,12 @@ func (c *debugLogCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.Var(cmd.NewAppendStringsValue(&c.params.ExcludeEntity), "exclude", "Do not show log messages for these entities")
 	f.Var(cmd.NewAppendStringsValue(&c.params.IncludeModule), "include-module", "Only show log messages for these logging modules")
 	f.Var(cmd.NewAppendStringsValue(&c.params.ExcludeModule), "exclude-module", "Do not show log messages for these logging modules")
+	f.Var(cmd.NewAppendStringsValue(&c.params.IncludeLocation), "include-location", "Only show log messages from these source files (optionally file:line)")
+	f.Var(cmd.NewAppendStringsValue(&c.params.ExcludeLocation), "exclude-location", "Do not show log messages from these source files (optionally file:line)")
+	f.StringVar(&c.params.IncludeMessage, "include-message", "", "Only show log messages containing this pattern (* is a wildcard)")
+	f.StringVar(&c.params.ExcludeMessage, "exclude-message", "", "Do not show log messages containing this pattern (* is a wildcard)")
+	f.StringVar(&c.since, "since", "", "Only show log messages logged after this time (RFC3339) or duration ago")
+	f.StringVar(&c.until, "until", "", "Only show log messages logged before this time (RFC3339) or duration ago, then exit")
 
 	f.StringVar(&c.level, "l", "", "Log level to show, one of [TRACE, DEBUG, INFO, WARNING, ERROR]")
 	f.StringVar(&c.level, "level", "", "")
@@ -161,6 +209,7 @@ func (c *debugLogCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.BoolVar(&c.location, "location", false, "Show filename and line numbers")
 	f.BoolVar(&c.date, "date", false, "Show dates as well as times")
 	f.BoolVar(&c.ms, "ms", false, "Show times to millisecond precision")
+	f.StringVar(&c.outputFormat, "format", "text", "Output format, one of [text, json]")
 }
 
 func (c *debugLogCommand) Init(args []string) error {
@@ -175,6 +224,20 @@ func (c *debugLogCommand) Init(args []string) error {
 	if c.tail && c.notail {
 		return errors.NotValidf("setting --tail and --no-tail")
 	}
+	switch c.outputFormat {
+	case "", "text", "json":
+	default:
+		return errors.Errorf("format value %q is not one of %q, %q", c.outputFormat, "text", "json")
+	}
+	if c.clock == nil {
+		c.clock = clock.WallClock
+	}
+	start, end, err := parseTimeRange(c.clock, c.since, c.until)
+	if err != nil {
+		return err
+	}
+	c.params.StartTime = start
+	c.params.EndTime = end
 	if c.utc {
 		c.tz = time.UTC
 	}
@@ -191,6 +254,40 @@ func (c *debugLogCommand) Init(args []string) error {
 	return cmd.CheckEmpty(args)
 }
 
+// parseTimeFlag parses the value of a time range flag, which is
+// either a time in RFC3339 format or a duration before now.
+func parseTimeFlag(clk clock.Clock, flag, value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return time.Time{}, errors.Errorf("%s value %q is not a valid time or duration", flag, value)
+	}
+	return clk.Now().Add(-d).UTC(), nil
+}
+
+// parseTimeRange parses the values of the --since and --until flags,
+// either of which may be empty.
+func parseTimeRange(clk clock.Clock, since, until string) (start, end time.Time, err error) {
+	if since != "" {
+		start, err = parseTimeFlag(clk, "--since", since)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+	if until != "" {
+		end, err = parseTimeFlag(clk, "--until", until)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		if !start.IsZero() && !end.After(start) {
+			return time.Time{}, time.Time{}, errors.NotValidf("--until time before --since time")
+		}
+	}
+	return start, end, nil
+}
+
 func (c *debugLogCommand) processEntities(entities []string) []string {
 	if entities == nil {
 		return nil
@@ -261,6 +358,10 @@ func (c *debugLogCommand) Run(ctx *cmd.Context) (err error) {
 	if err != nil {
 		return err
 	}
+	if c.outputFormat == "json" {
+		_, err := writeJSONLogRecords(ctx.Stdout, messages)
+		return err
+	}
 	writer := ansiterm.NewWriter(ctx.Stdout)
 	if c.color {
 		writer.SetColorCapable(true)
@@ -276,6 +377,39 @@ func (c *debugLogCommand) Run(ctx *cmd.Context) (err error) {
 	return nil
 }
 
+// jsonLogRecord is the form in which log records are written when
+// --format json is specified, and by export-logs.
+type jsonLogRecord struct {
+	Entity    string    `json:"entity"`
+	Timestamp time.Time `json:"timestamp"`
+	Level     string    `json:"level"`
+	Module    string    `json:"module"`
+	Location  string    `json:"location"`
+	Message   string    `json:"message"`
+}
+
+// writeJSONLogRecords writes each message received on the channel as a
+// JSON object on its own line, and returns the number written.
+func writeJSONLogRecords(w io.Writer, messages <-chan common.LogMessage) (int, error) {
+	enc := json.NewEncoder(w)
+	count := 0
+	for msg := range messages {
+		err := enc.Encode(jsonLogRecord{
+			Entity:    msg.Entity,
+			Timestamp: msg.Timestamp.UTC(),
+			Level:     msg.Severity,
+			Module:    msg.Module,
+			Location:  msg.Location,
+			Message:   msg.Message,
+		})
+		if err != nil {
+			return count, errors.Trace(err)
+		}
+		count++
+	}
+	return count, nil
+}
+
 var SeverityColor = map[string]*ansiterm.Context{
 	"TRACE":   ansiterm.Foreground(ansiterm.Default),
 	"DEBUG":   ansiterm.Foreground(ansiterm.Green),
@@ -6,6 +6,7 @@ package commands
 import (
 	"time"
 
+	"github.com/juju/clock/testclock"
 	"github.com/juju/cmd/cmdtesting"
 	"github.com/juju/loggo"
 	jc "github.com/juju/testing/checkers"
@@ -104,10 +105,52 @@ func (s *DebugLogSuite) TestArgParsing(c *gc.C) {
 				Backlog: 10,
 				Limit:   100,
 			},
+		}, {
+			args: []string{"--include-location", "uniter.go", "--exclude-location", "runner.go:42"},
+			expected: common.DebugLogParams{
+				IncludeLocation: []string{"uniter.go"},
+				ExcludeLocation: []string{"runner.go:42"},
+				Backlog:         10,
+			},
+		}, {
+			args: []string{"--include-message", "hook*started", "--exclude-message", "failed"},
+			expected: common.DebugLogParams{
+				IncludeMessage: "hook*started",
+				ExcludeMessage: "failed",
+				Backlog:        10,
+			},
+		}, {
+			args: []string{"--since", "2018-10-01T12:00:00+01:00", "--until", "2018-10-01T12:30:00Z"},
+			expected: common.DebugLogParams{
+				StartTime: time.Date(2018, 10, 1, 11, 0, 0, 0, time.UTC),
+				EndTime:   time.Date(2018, 10, 1, 12, 30, 0, 0, time.UTC),
+				Backlog:   10,
+			},
+		}, {
+			args: []string{"--since", "2h", "--until", "30m"},
+			expected: common.DebugLogParams{
+				StartTime: time.Date(2018, 10, 1, 10, 0, 0, 0, time.UTC),
+				EndTime:   time.Date(2018, 10, 1, 11, 30, 0, 0, time.UTC),
+				Backlog:   10,
+			},
+		}, {
+			args:     []string{"--since", "yesterday"},
+			errMatch: `--since value "yesterday" is not a valid time or duration`,
+		}, {
+			args:     []string{"--until", "-1h"},
+			errMatch: `--until value "-1h" is not a valid time or duration`,
+		}, {
+			args:     []string{"--since", "1h", "--until", "2h"},
+			errMatch: `--until time before --since time not valid`,
+		}, {
+			args:     []string{"--format", "yaml"},
+			errMatch: `format value "yaml" is not one of "text", "json"`,
 		},
 	} {
 		c.Logf("test %v", i)
-		command := &debugLogCommand{}
+		command := &debugLogCommand{
+			clock: testclock.NewClock(time.Date(2018, 10, 1, 12, 0, 0, 0, time.UTC)),
+		}
 		command.SetClientStore(jujuclienttesting.MinimalStore())
 		err := cmdtesting.InitCommand(modelcmd.Wrap(command), test.args)
 		if test.errMatch == "" {
@@ -182,12 +225,38 @@ func (s *DebugLogSuite) TestLogOutput(c *gc.C) {
 	checkOutput(
 		"--location",
 		"machine-0: 14:15:23 INFO test.module somefile.go:123 this is the log output\n")
+	checkOutput(
+		"--format", "json",
+		`{"entity":"machine-0","timestamp":"2016-10-09T08:15:23.345Z","level":"INFO",`+
+			`"module":"test.module","location":"somefile.go:123","message":"this is the log output"}`+"\n")
 }
 
 type fakeDebugLogAPI struct {
-	log    []common.LogMessage
-	params common.DebugLogParams
-	err    error
+	log       []common.LogMessage
+	params    common.DebugLogParams
+	err       error
+	streamErr error
+}
+
+func (fake *fakeDebugLogAPI) OpenDebugLog(params common.DebugLogParams) (common.DebugLogStream, error) {
+	messages, err := fake.WatchDebugLog(params)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeDebugLogStream{messages: messages, err: fake.streamErr}, nil
+}
+
+type fakeDebugLogStream struct {
+	messages <-chan common.LogMessage
+	err      error
+}
+
+func (s *fakeDebugLogStream) Messages() <-chan common.LogMessage {
+	return s.messages
+}
+
+func (s *fakeDebugLogStream) Err() error {
+	return s.err
 }
 
 func (fake *fakeDebugLogAPI) WatchDebugLog(params common.DebugLogParams) (<-chan common.LogMessage, error) {
@@ -0,0 +1,158 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package commands
+
+import (
+	"compress/gzip"
+	"os"
+
+	"github.com/juju/clock"
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/gnuflag"
+
+	"github.com/juju/juju/api/common"
+	jujucmd "github.com/juju/juju/cmd"
+	"github.com/juju/juju/cmd/modelcmd"
+	"github.com/juju/juju/jujuclient"
+)
+
+var usageExportLogsSummary = `
+Exports a model's log messages to a compressed archive.`[1:]
+
+var usageExportLogsDetails = `
+The log messages recorded for the model are written to the named file
+as gzip-compressed JSON, one object per line, with the same fields as
+`[1:] + "`juju debug-log --format json`" + `. The archive can later be
+loaded into a model on any controller with ` + "`juju import-logs`" + `,
+so that the logs of a model outlive the model itself.
+
+The '--since' and '--until' options limit the exported messages to a
+time range. Each takes either a time in RFC3339 format or a duration
+meaning that long before now. By default all messages are exported.
+
+If the controller does not confirm that every message was sent, the
+command fails and the incomplete archive is removed.
+
+Examples:
+
+    juju export-logs mymodel-logs.json.gz
+    juju export-logs -m prod --since 24h prod-logs.json.gz
+    juju export-logs --since 2018-10-01T00:00:00Z --until 2018-10-02T00:00:00Z \
+        logs.json.gz
+
+See also:
+    debug-log
+    import-logs`
+
+func newExportLogsCommand(store jujuclient.ClientStore) cmd.Command {
+	cmd := &exportLogsCommand{clock: clock.WallClock}
+	cmd.SetClientStore(store)
+	return modelcmd.Wrap(cmd)
+}
+
+// exportLogsCommand writes a model's logs to a local archive.
+type exportLogsCommand struct {
+	modelcmd.ModelCommandBase
+
+	filename string
+	since    string
+	until    string
+	params   common.DebugLogParams
+	clock    clock.Clock
+}
+
+// Info implements Command.
+func (c *exportLogsCommand) Info() *cmd.Info {
+	return jujucmd.Info(&cmd.Info{
+		Name:    "export-logs",
+		Args:    "<filename>",
+		Purpose: usageExportLogsSummary,
+		Doc:     usageExportLogsDetails,
+	})
+}
+
+// SetFlags implements Command.
+func (c *exportLogsCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.ModelCommandBase.SetFlags(f)
+	f.StringVar(&c.since, "since", "", "Only export log messages logged after this time (RFC3339) or duration ago")
+	f.StringVar(&c.until, "until", "", "Only export log messages logged before this time (RFC3339) or duration ago")
+}
+
+// Init implements Command.
+func (c *exportLogsCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no filename specified")
+	}
+	c.filename, args = args[0], args[1:]
+	if c.clock == nil {
+		c.clock = clock.WallClock
+	}
+	start, end, err := parseTimeRange(c.clock, c.since, c.until)
+	if err != nil {
+		return err
+	}
+	c.params = common.DebugLogParams{
+		Replay:    true,
+		NoTail:    true,
+		StartTime: start,
+		EndTime:   end,
+	}
+	return cmd.CheckEmpty(args)
+}
+
+// ExportLogsAPI provides access to the log messages exported by
+// export-logs.
+type ExportLogsAPI interface {
+	OpenDebugLog(params common.DebugLogParams) (common.DebugLogStream, error)
+	Close() error
+}
+
+var getExportLogsAPI = func(c *exportLogsCommand) (ExportLogsAPI, error) {
+	return c.NewAPIClient()
+}
+
+// Run implements Command.
+func (c *exportLogsCommand) Run(ctx *cmd.Context) (err error) {
+	client, err := getExportLogsAPI(c)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	path := ctx.AbsPath(c.filename)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return errors.Annotate(err, "creating archive")
+	}
+	defer func() {
+		f.Close()
+		if err != nil {
+			// Don't leave an incomplete archive to be mistaken
+			// for a complete one.
+			os.Remove(path)
+		}
+	}()
+
+	stream, err := client.OpenDebugLog(c.params)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(f)
+	count, err := writeJSONLogRecords(zw, stream.Messages())
+	if err != nil {
+		return errors.Annotate(err, "writing archive")
+	}
+	if err := stream.Err(); err != nil {
+		return errors.Annotate(err, "exporting logs")
+	}
+	if err := zw.Close(); err != nil {
+		return errors.Annotate(err, "writing archive")
+	}
+	if err := f.Close(); err != nil {
+		return errors.Annotate(err, "writing archive")
+	}
+	ctx.Infof("exported %d log messages to %s", count, c.filename)
+	return nil
+}
@@ -0,0 +1,144 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package commands
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/juju/clock/testclock"
+	"github.com/juju/cmd/cmdtesting"
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/api/common"
+	"github.com/juju/juju/cmd/modelcmd"
+	"github.com/juju/juju/jujuclient/jujuclienttesting"
+	"github.com/juju/juju/testing"
+)
+
+type ExportLogsSuite struct {
+	testing.FakeJujuXDGDataHomeSuite
+}
+
+var _ = gc.Suite(&ExportLogsSuite{})
+
+func (s *ExportLogsSuite) TestInit(c *gc.C) {
+	for i, test := range []struct {
+		args     []string
+		expected common.DebugLogParams
+		errMatch string
+	}{{
+		args:     []string{},
+		errMatch: "no filename specified",
+	}, {
+		args:     []string{"logs.json.gz", "extra"},
+		errMatch: `unrecognized args: \["extra"\]`,
+	}, {
+		args: []string{"logs.json.gz"},
+		expected: common.DebugLogParams{
+			Replay: true,
+			NoTail: true,
+		},
+	}, {
+		args: []string{"--since", "2h", "--until", "1h", "logs.json.gz"},
+		expected: common.DebugLogParams{
+			Replay:    true,
+			NoTail:    true,
+			StartTime: time.Date(2018, 10, 1, 10, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2018, 10, 1, 11, 0, 0, 0, time.UTC),
+		},
+	}, {
+		args:     []string{"--since", "1h", "--until", "2h", "logs.json.gz"},
+		errMatch: `--until time before --since time not valid`,
+	}} {
+		c.Logf("test %d: %v", i, test.args)
+		command := &exportLogsCommand{
+			clock: testclock.NewClock(time.Date(2018, 10, 1, 12, 0, 0, 0, time.UTC)),
+		}
+		command.SetClientStore(jujuclienttesting.MinimalStore())
+		err := cmdtesting.InitCommand(modelcmd.Wrap(command), test.args)
+		if test.errMatch == "" {
+			c.Check(err, jc.ErrorIsNil)
+			c.Check(command.params, jc.DeepEquals, test.expected)
+		} else {
+			c.Check(err, gc.ErrorMatches, test.errMatch)
+		}
+	}
+}
+
+func (s *ExportLogsSuite) TestExport(c *gc.C) {
+	fake := &fakeDebugLogAPI{log: []common.LogMessage{{
+		Entity:    "machine-0",
+		Timestamp: time.Date(2016, 10, 9, 8, 15, 23, 345000000, time.UTC),
+		Severity:  "INFO",
+		Module:    "test.module",
+		Location:  "somefile.go:123",
+		Message:   "this is the log output",
+	}, {
+		Entity:    "unit-mysql-0",
+		Timestamp: time.Date(2016, 10, 9, 8, 15, 24, 0, time.UTC),
+		Severity:  "ERROR",
+		Module:    "juju.worker.uniter",
+		Location:  "uniter.go:42",
+		Message:   "hook failed",
+	}}}
+	s.PatchValue(&getExportLogsAPI, func(_ *exportLogsCommand) (ExportLogsAPI, error) {
+		return fake, nil
+	})
+	path := filepath.Join(c.MkDir(), "logs.json.gz")
+	ctx, err := cmdtesting.RunCommand(c, newExportLogsCommand(jujuclienttesting.MinimalStore()), path)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(cmdtesting.Stderr(ctx), gc.Equals, "exported 2 log messages to "+path+"\n")
+	c.Check(fake.params, jc.DeepEquals, common.DebugLogParams{Replay: true, NoTail: true})
+
+	f, err := os.Open(path)
+	c.Assert(err, jc.ErrorIsNil)
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	c.Assert(err, jc.ErrorIsNil)
+	data, err := ioutil.ReadAll(zr)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(string(data), gc.Equals, ""+
+		`{"entity":"machine-0","timestamp":"2016-10-09T08:15:23.345Z","level":"INFO",`+
+		`"module":"test.module","location":"somefile.go:123","message":"this is the log output"}`+"\n"+
+		`{"entity":"unit-mysql-0","timestamp":"2016-10-09T08:15:24Z","level":"ERROR",`+
+		`"module":"juju.worker.uniter","location":"uniter.go:42","message":"hook failed"}`+"\n")
+}
+
+func (s *ExportLogsSuite) TestExportExistingFile(c *gc.C) {
+	s.PatchValue(&getExportLogsAPI, func(_ *exportLogsCommand) (ExportLogsAPI, error) {
+		return &fakeDebugLogAPI{}, nil
+	})
+	path := filepath.Join(c.MkDir(), "logs.json.gz")
+	err := ioutil.WriteFile(path, []byte("precious"), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = cmdtesting.RunCommand(c, newExportLogsCommand(jujuclienttesting.MinimalStore()), path)
+	c.Assert(err, gc.ErrorMatches, "creating archive: .* file exists")
+}
+
+func (s *ExportLogsSuite) TestExportTruncatedStream(c *gc.C) {
+	fake := &fakeDebugLogAPI{
+		log: []common.LogMessage{{
+			Entity:    "machine-0",
+			Timestamp: time.Date(2016, 10, 9, 8, 15, 23, 0, time.UTC),
+			Severity:  "INFO",
+			Module:    "test.module",
+			Message:   "this is the log output",
+		}},
+		streamErr: errors.New("log stream ended before it was complete: unexpected EOF"),
+	}
+	s.PatchValue(&getExportLogsAPI, func(_ *exportLogsCommand) (ExportLogsAPI, error) {
+		return fake, nil
+	})
+	path := filepath.Join(c.MkDir(), "logs.json.gz")
+	_, err := cmdtesting.RunCommand(c, newExportLogsCommand(jujuclienttesting.MinimalStore()), path)
+	c.Assert(err, gc.ErrorMatches, "exporting logs: log stream ended before it was complete: unexpected EOF")
+	_, err = os.Stat(path)
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+}
@@ -0,0 +1,147 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package commands
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+	jujucmd "github.com/juju/juju/cmd"
+	"github.com/juju/juju/cmd/modelcmd"
+	"github.com/juju/juju/jujuclient"
+)
+
+var usageImportLogsSummary = `
+Imports log messages from an archive into a model.`[1:]
+
+var usageImportLogsDetails = `
+The log messages in an archive created by `[1:] + "`juju export-logs`" + ` are
+added to the logs of the current model, where they can be viewed with
+` + "`juju debug-log`" + `. The model may be on a different controller from
+the one the archive was exported from. Messages already in the model's
+logs are skipped, so archives may overlap or be imported more than once.
+
+Importing logs requires controller superuser access.
+
+Examples:
+
+    juju import-logs mymodel-logs.json.gz
+    juju import-logs -m post-mortem prod-logs.json.gz
+
+See also:
+    debug-log
+    export-logs`
+
+func newImportLogsCommand(store jujuclient.ClientStore) cmd.Command {
+	cmd := &importLogsCommand{}
+	cmd.SetClientStore(store)
+	return modelcmd.Wrap(cmd)
+}
+
+// importLogsCommand replays an archive of exported logs into a model.
+type importLogsCommand struct {
+	modelcmd.ModelCommandBase
+
+	filename string
+}
+
+// Info implements Command.
+func (c *importLogsCommand) Info() *cmd.Info {
+	return jujucmd.Info(&cmd.Info{
+		Name:    "import-logs",
+		Args:    "<filename>",
+		Purpose: usageImportLogsSummary,
+		Doc:     usageImportLogsDetails,
+	})
+}
+
+// Init implements Command.
+func (c *importLogsCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no filename specified")
+	}
+	c.filename, args = args[0], args[1:]
+	return cmd.CheckEmpty(args)
+}
+
+// ImportLogsAPI is the API used by the import-logs command.
+type ImportLogsAPI interface {
+	OpenLogImportStream() (base.Stream, error)
+	Close() error
+}
+
+var getImportLogsAPI = func(c *importLogsCommand) (ImportLogsAPI, error) {
+	return c.NewAPIClient()
+}
+
+// Run implements Command.
+func (c *importLogsCommand) Run(ctx *cmd.Context) error {
+	f, err := os.Open(ctx.AbsPath(c.filename))
+	if err != nil {
+		return errors.Annotate(err, "opening archive")
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return errors.Annotate(err, "reading archive")
+	}
+
+	client, err := getImportLogsAPI(c)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	stream, err := client.OpenLogImportStream()
+	if err != nil {
+		return errors.Annotate(err, "opening log import stream")
+	}
+	defer stream.Close()
+
+	count, err := importLogRecords(zr, stream)
+	if err != nil {
+		return errors.Annotatef(err, "importing %s", c.filename)
+	}
+	ctx.Infof("imported %d log messages from %s", count, c.filename)
+	return nil
+}
+
+// importLogRecords reads exported log records from r, one JSON object
+// per line, and writes them to the stream. It returns the number of
+// records written.
+func importLogRecords(r io.Reader, stream base.Stream) (int, error) {
+	scanner := bufio.NewScanner(r)
+	// Log messages may be far longer than the default line limit.
+	scanner.Buffer(nil, 1024*1024)
+	count := 0
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var rec jsonLogRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return count, errors.Annotatef(err, "line %d", line)
+		}
+		err := stream.WriteJSON(params.LogRecord{
+			Entity:   rec.Entity,
+			Time:     rec.Timestamp,
+			Module:   rec.Module,
+			Location: rec.Location,
+			Level:    rec.Level,
+			Message:  rec.Message,
+		})
+		if err != nil {
+			return count, errors.Trace(err)
+		}
+		count++
+	}
+	return count, errors.Trace(scanner.Err())
+}
@@ -0,0 +1,149 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package commands
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/juju/cmd/cmdtesting"
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/jujuclient/jujuclienttesting"
+	"github.com/juju/juju/testing"
+)
+
+type ImportLogsSuite struct {
+	testing.FakeJujuXDGDataHomeSuite
+
+	api *fakeImportLogsAPI
+}
+
+var _ = gc.Suite(&ImportLogsSuite{})
+
+func (s *ImportLogsSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuXDGDataHomeSuite.SetUpTest(c)
+	s.api = &fakeImportLogsAPI{}
+	s.PatchValue(&getImportLogsAPI, func(_ *importLogsCommand) (ImportLogsAPI, error) {
+		return s.api, nil
+	})
+}
+
+func (s *ImportLogsSuite) writeArchive(c *gc.C, content string) string {
+	path := filepath.Join(c.MkDir(), "logs.json.gz")
+	f, err := os.Create(path)
+	c.Assert(err, jc.ErrorIsNil)
+	defer f.Close()
+	zw := gzip.NewWriter(f)
+	_, err = io.WriteString(zw, content)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(zw.Close(), jc.ErrorIsNil)
+	return path
+}
+
+func (s *ImportLogsSuite) TestInit(c *gc.C) {
+	_, err := cmdtesting.RunCommand(c, newImportLogsCommand(jujuclienttesting.MinimalStore()))
+	c.Assert(err, gc.ErrorMatches, "no filename specified")
+	_, err = cmdtesting.RunCommand(c, newImportLogsCommand(jujuclienttesting.MinimalStore()), "a", "b")
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["b"\]`)
+}
+
+func (s *ImportLogsSuite) TestImport(c *gc.C) {
+	path := s.writeArchive(c, ""+
+		`{"entity":"machine-0","timestamp":"2016-10-09T08:15:23.345Z","level":"INFO",`+
+		`"module":"test.module","location":"somefile.go:123","message":"this is the log output"}`+"\n"+
+		"\n"+
+		`{"entity":"unit-mysql-0","timestamp":"2016-10-09T08:15:24Z","level":"ERROR",`+
+		`"module":"juju.worker.uniter","location":"uniter.go:42","message":"hook failed"}`+"\n")
+
+	ctx, err := cmdtesting.RunCommand(c, newImportLogsCommand(jujuclienttesting.MinimalStore()), path)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(cmdtesting.Stderr(ctx), gc.Equals, "imported 2 log messages from "+path+"\n")
+	c.Check(s.api.stream.closed, jc.IsTrue)
+	c.Check(s.api.stream.written, jc.DeepEquals, []interface{}{
+		params.LogRecord{
+			Entity:   "machine-0",
+			Time:     time.Date(2016, 10, 9, 8, 15, 23, 345000000, time.UTC),
+			Module:   "test.module",
+			Location: "somefile.go:123",
+			Level:    "INFO",
+			Message:  "this is the log output",
+		},
+		params.LogRecord{
+			Entity:   "unit-mysql-0",
+			Time:     time.Date(2016, 10, 9, 8, 15, 24, 0, time.UTC),
+			Module:   "juju.worker.uniter",
+			Location: "uniter.go:42",
+			Level:    "ERROR",
+			Message:  "hook failed",
+		},
+	})
+}
+
+func (s *ImportLogsSuite) TestImportBadRecord(c *gc.C) {
+	path := s.writeArchive(c, ""+
+		`{"entity":"machine-0","timestamp":"2016-10-09T08:15:23Z","level":"INFO","message":"ok"}`+"\n"+
+		`{"entity":`+"\n")
+
+	_, err := cmdtesting.RunCommand(c, newImportLogsCommand(jujuclienttesting.MinimalStore()), path)
+	c.Assert(err, gc.ErrorMatches, `importing .*logs.json.gz: line 2: unexpected end of JSON input`)
+	c.Check(s.api.stream.written, gc.HasLen, 1)
+}
+
+func (s *ImportLogsSuite) TestImportNotArchive(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "logs.json")
+	f, err := os.Create(path)
+	c.Assert(err, jc.ErrorIsNil)
+	f.Close()
+
+	_, err = cmdtesting.RunCommand(c, newImportLogsCommand(jujuclienttesting.MinimalStore()), path)
+	c.Assert(err, gc.ErrorMatches, `reading archive: EOF`)
+}
+
+type fakeImportLogsAPI struct {
+	stream *fakeLogStream
+	err    error
+}
+
+func (f *fakeImportLogsAPI) OpenLogImportStream() (base.Stream, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.stream = &fakeLogStream{}
+	return f.stream, nil
+}
+
+func (f *fakeImportLogsAPI) Close() error {
+	return nil
+}
+
+type fakeLogStream struct {
+	written []interface{}
+	closed  bool
+}
+
+func (s *fakeLogStream) NextReader() (int, io.Reader, error) {
+	return 0, nil, errors.NotImplementedf("NextReader")
+}
+
+func (s *fakeLogStream) WriteJSON(v interface{}) error {
+	s.written = append(s.written, v)
+	return nil
+}
+
+func (s *fakeLogStream) ReadJSON(v interface{}) error {
+	return errors.NotImplementedf("ReadJSON")
+}
+
+func (s *fakeLogStream) Close() error {
+	s.closed = true
+	return nil
+}
@@ -305,6 +305,7 @@ func registerCommands(r commandRegistry, ctx *cmd.Context) {
 	r.Register(status.NewStatusCommand())
 	r.Register(newSwitchCommand())
 	r.Register(status.NewStatusHistoryCommand())
+	r.Register(status.NewExportStatusLogCommand())
 
 	// Error resolution and debugging commands.
 	r.Register(newDefaultRunCommand(nil))
@@ -312,6 +313,8 @@ func registerCommands(r commandRegistry, ctx *cmd.Context) {
 	r.Register(newSSHCommand(nil, nil))
 	r.Register(application.NewResolvedCommand())
 	r.Register(newDebugLogCommand(nil))
+	r.Register(newExportLogsCommand(nil))
+	r.Register(newImportLogsCommand(nil))
 	r.Register(newDebugHooksCommand(nil))
 
 	// Configuration commands.
@@ -336,6 +339,7 @@ func registerCommands(r commandRegistry, ctx *cmd.Context) {
 	r.Register(backups.NewRemoveCommand())
 	r.Register(backups.NewRestoreCommand())
 	r.Register(backups.NewUploadCommand())
+	r.Register(backups.NewVerifyCommand())
 
 	// Manage authorized ssh keys.
 	r.Register(NewAddKeysCommand())
@@ -377,6 +381,10 @@ func registerCommands(r commandRegistry, ctx *cmd.Context) {
 	r.Register(model.NewModelCredentialCommand())
 	if featureflag.Enabled(feature.Generations) {
 		r.Register(model.NewAddGenerationCommand())
+		r.Register(model.NewAdvanceGenerationCommand())
+		r.Register(model.NewCancelGenerationCommand())
+		r.Register(model.NewShowGenerationCommand())
+		r.Register(model.NewGenerationsCommand())
 	}
 
 	r.Register(newMigrateCommand())
@@ -393,6 +401,9 @@ func registerCommands(r commandRegistry, ctx *cmd.Context) {
 	r.Register(action.NewShowOutputCommand())
 	r.Register(action.NewListCommand())
 	r.Register(action.NewCancelCommand())
+	r.Register(action.NewSchedulesCommand())
+	r.Register(action.NewRemoveScheduleCommand())
+	r.Register(action.NewShowOperationCommand())
 
 	// Manage controller availability
 	r.Register(newEnableHACommand())
@@ -452,6 +463,7 @@ func registerCommands(r commandRegistry, ctx *cmd.Context) {
 	r.Register(controller.NewEnableDestroyControllerCommand())
 	r.Register(controller.NewShowControllerCommand())
 	r.Register(controller.NewConfigCommand())
+	r.Register(controller.NewAuditLogCommand())
 
 	// Debug Metrics
 	r.Register(metricsdebug.New())
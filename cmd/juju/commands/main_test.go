@@ -402,6 +402,7 @@ func assertNoArgs(c *gc.C, argChan <-chan []string) {
 }
 
 var commandNames = []string{
+	"action-schedules",
 	"actions",
 	"add-cloud",
 	"add-credential",
@@ -420,6 +421,7 @@ var commandNames = []string{
 	"attach",
 	"attach-resource",
 	"attach-storage",
+	"audit-log",
 	"autoload-credentials",
 	"backups",
 	"bootstrap",
@@ -455,6 +457,8 @@ var commandNames = []string{
 	"enable-ha",
 	"enable-user",
 	"export-bundle",
+	"export-logs",
+	"export-status-log",
 	"expose",
 	"find-offers",
 	"firewall-rules",
@@ -468,6 +472,7 @@ var commandNames = []string{
 	"hook-tool",
 	"hook-tools",
 	"import-filesystem",
+	"import-logs",
 	"import-ssh-key",
 	"kill-controller",
 	"list-actions",
@@ -511,6 +516,7 @@ var commandNames = []string{
 	"register",
 	"relate", //alias for add-relation
 	"reload-spaces",
+	"remove-action-schedule",
 	"remove-application",
 	"remove-backup",
 	"remove-cached-images",
@@ -558,6 +564,7 @@ var commandNames = []string{
 	"show-machine",
 	"show-model",
 	"show-offer",
+	"show-operation",
 	"show-status",
 	"show-status-log",
 	"show-storage",
@@ -587,6 +594,7 @@ var commandNames = []string{
 	"upgrade-series",
 	"upload-backup",
 	"users",
+	"verify-backup",
 	"version",
 	"wallets",
 	"whoami",
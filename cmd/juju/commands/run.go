@@ -48,6 +48,8 @@ type runCommand struct {
 	applications []string
 	units        []string
 	commands     string
+	maxParallel  int
+	maxFailures  int
 	timeAfter    func(time.Duration) <-chan time.Time
 }
 
@@ -90,6 +92,18 @@ command and its arguments with "--", to tell "juju run" to stop processing
 those arguments. For example:
 
     juju run --all -- hostname -f
+
+--max-parallel (or --batch-size) runs the command on no more than the given
+number of targets at once, starting on the next target as soon as the command
+finishes on an earlier one. With --max-failures, the command is not run on
+any more targets once it has failed on the given number of them. The targets
+are tracked as a single operation, which may be inspected with
+"juju show-operation". The timeout applies to each target, so the command
+waits for up to the timeout for every batch. For example, to restart the
+units of an application two at a time, stopping if two restarts fail:
+
+    juju run --application mysql --max-parallel 2 --max-failures 2 -- \
+        sudo systemctl restart mysql
 `
 
 func (c *runCommand) Info() *cmd.Info {
@@ -117,6 +131,9 @@ func (c *runCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.Var(cmd.NewStringsValue(nil, &c.applications), "application", "")
 	f.Var(cmd.NewStringsValue(nil, &c.units), "u", "One or more unit ids")
 	f.Var(cmd.NewStringsValue(nil, &c.units), "unit", "")
+	f.IntVar(&c.maxParallel, "max-parallel", 0, "Run the commands on no more than this many targets at once")
+	f.IntVar(&c.maxParallel, "batch-size", 0, "")
+	f.IntVar(&c.maxFailures, "max-failures", 0, "Stop running the commands once they have failed on this many targets")
 }
 
 func (c *runCommand) Init(args []string) error {
@@ -171,6 +188,15 @@ func (c *runCommand) Init(args []string) error {
 			strings.Join(nameErrors, "\n"))
 	}
 
+	if c.maxParallel < 0 {
+		return errors.New("--max-parallel must not be negative")
+	}
+	if c.maxFailures < 0 {
+		return errors.New("--max-failures must not be negative")
+	}
+	if c.maxFailures > 0 && c.maxParallel == 0 {
+		return errors.New("--max-failures requires --max-parallel")
+	}
 	return nil
 }
 
@@ -229,6 +255,10 @@ func (c *runCommand) Run(ctx *cmd.Context) error {
 	}
 	defer client.Close()
 
+	if c.maxParallel > 0 {
+		return c.runOperation(ctx, client)
+	}
+
 	var runResults []params.ActionResult
 	if c.all {
 		runResults, err = client.RunOnAllMachines(c.commands, c.timeout)
@@ -263,19 +293,10 @@ func (c *runCommand) Run(ctx *cmd.Context) error {
 			fmt.Fprintf(ctx.GetStderr(), "got invalid action receiver tag %v for action %v\n", result.Action.Receiver, result.Action.Tag)
 			continue
 		}
-		var receiverType string
-		switch receiverTag.(type) {
-		case names.UnitTag:
-			receiverType = "UnitId"
-		case names.MachineTag:
-			receiverType = "MachineId"
-		default:
-			receiverType = "ReceiverId"
-		}
 		actionsToQuery = append(actionsToQuery, actionQuery{
 			actionTag: actionTag,
 			receiver: actionReceiver{
-				receiverType: receiverType,
+				receiverType: receiverType(receiverTag),
 				tag:          receiverTag,
 			}})
 	}
@@ -287,25 +308,11 @@ func (c *runCommand) Run(ctx *cmd.Context) error {
 	timeout := c.timeAfter(c.timeout)
 	values := []interface{}{}
 	for len(actionsToQuery) > 0 {
-		actionResults, err := client.Actions(entities(actionsToQuery))
+		actionsToQuery, values, err = queryActions(client, actionsToQuery, values)
 		if err != nil {
 			return errors.Trace(err)
 		}
 
-		newActionsToQuery := []actionQuery{}
-		for i, result := range actionResults.Results {
-			if result.Error == nil {
-				switch result.Status {
-				case params.ActionRunning, params.ActionPending:
-					newActionsToQuery = append(newActionsToQuery, actionsToQuery[i])
-					continue
-				}
-			}
-
-			values = append(values, ConvertActionResults(result, actionsToQuery[i]))
-		}
-		actionsToQuery = newActionsToQuery
-
 		if len(actionsToQuery) > 0 {
 			var timedOut bool
 			select {
@@ -323,6 +330,37 @@ func (c *runCommand) Run(ctx *cmd.Context) error {
 		}
 	}
 
+	return c.writeResults(ctx, values, actionsToQuery)
+}
+
+// queryActions fetches the results of the given actions, and appends
+// those of the actions that have finished to values. It returns the
+// actions that are yet to finish.
+func queryActions(client RunClient, actionsToQuery []actionQuery, values []interface{}) ([]actionQuery, []interface{}, error) {
+	actionResults, err := client.Actions(entities(actionsToQuery))
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+
+	newActionsToQuery := []actionQuery{}
+	for i, result := range actionResults.Results {
+		if result.Error == nil {
+			switch result.Status {
+			case params.ActionRunning, params.ActionPending:
+				newActionsToQuery = append(newActionsToQuery, actionsToQuery[i])
+				continue
+			}
+		}
+
+		values = append(values, ConvertActionResults(result, actionsToQuery[i]))
+	}
+	return newActionsToQuery, values, nil
+}
+
+// writeResults writes out the results of the finished actions, and
+// returns an error naming the receivers of any actions that are yet to
+// finish.
+func (c *runCommand) writeResults(ctx *cmd.Context, values []interface{}, actionsToQuery []actionQuery) error {
 	// If we are just dealing with one result, AND we are using the default
 	// format, then pretend we were running it locally.
 	if len(actionsToQuery) == 0 && len(values) == 1 && c.out.Name() == "default" {
@@ -370,6 +408,154 @@ func (c *runCommand) Run(ctx *cmd.Context) error {
 	return nil
 }
 
+// runOperation runs the commands as an operation, on no more than
+// maxParallel targets at once, and waits for the results from each
+// target as the operation progresses.
+func (c *runCommand) runOperation(ctx *cmd.Context, client RunClient) error {
+	if client.BestAPIVersion() < 4 {
+		return errors.New("running commands in batches is unsupported by this API" +
+			"\nupgrade your controller to use --max-parallel")
+	}
+	run := params.RunParams{
+		Commands:     c.commands,
+		Timeout:      c.timeout,
+		Machines:     c.machines,
+		Applications: c.applications,
+		Units:        c.units,
+		MaxParallel:  c.maxParallel,
+		MaxFailures:  c.maxFailures,
+	}
+	var (
+		result params.OperationResult
+		err    error
+	)
+	if c.all {
+		result, err = client.RunOperationOnAllMachines(run)
+	} else {
+		result, err = client.RunOperation(run)
+	}
+	if err != nil {
+		return block.ProcessBlockedError(err, block.BlockChange)
+	}
+	if result.Error != nil {
+		return result.Error
+	}
+	op := result.Operation
+	if op == nil {
+		return errors.New("commands failed to be queued")
+	}
+	ctx.Verbosef("running commands as operation %s", op.Id)
+
+	// The commands may take up to the timeout on each batch of targets.
+	batches := (len(op.Receivers) + c.maxParallel - 1) / c.maxParallel
+	timeout := c.timeAfter(c.timeout * time.Duration(batches))
+	values := []interface{}{}
+	actionsToQuery := []actionQuery{}
+	seen := 0
+	for {
+		for _, opAction := range op.Actions[seen:] {
+			receiverTag, err := names.ActionReceiverFromTag(opAction.Receiver)
+			if err != nil {
+				fmt.Fprintf(ctx.GetStderr(), "got invalid action receiver tag %v\n", opAction.Receiver)
+				continue
+			}
+			if opAction.Error != "" {
+				values = append(values, map[string]interface{}{
+					receiverType(receiverTag): receiverTag.Id(),
+					"Error":                   opAction.Error,
+				})
+				continue
+			}
+			actionTag, err := names.ParseActionTag(opAction.Action)
+			if err != nil {
+				fmt.Fprintf(ctx.GetStderr(), "got invalid action tag %v for receiver %v\n", opAction.Action, opAction.Receiver)
+				continue
+			}
+			actionsToQuery = append(actionsToQuery, actionQuery{
+				actionTag: actionTag,
+				receiver: actionReceiver{
+					receiverType: receiverType(receiverTag),
+					tag:          receiverTag,
+				}})
+		}
+		seen = len(op.Actions)
+
+		if len(actionsToQuery) > 0 {
+			actionsToQuery, values, err = queryActions(client, actionsToQuery, values)
+			if err != nil {
+				return errors.Trace(err)
+			}
+		}
+		if op.Status != "running" && len(actionsToQuery) == 0 {
+			break
+		}
+
+		var timedOut bool
+		select {
+		case <-timeout:
+			timedOut = true
+		case <-c.timeAfter(1 * time.Second):
+		}
+		if timedOut {
+			break
+		}
+		if op, err = operation(client, op.Id); err != nil {
+			return errors.Trace(err)
+		}
+	}
+
+	if err := c.writeResults(ctx, values, actionsToQuery); err != nil {
+		return err
+	}
+	if remaining := op.Receivers[seen:]; len(remaining) > 0 {
+		receivers := make([]string, len(remaining))
+		for i, receiver := range remaining {
+			receivers[i] = receiver
+			if tag, err := names.ParseTag(receiver); err == nil {
+				receivers[i] = names.ReadableString(tag)
+			}
+		}
+		reason := "timed out"
+		if op.Status == "stopped" {
+			reason = fmt.Sprintf("stopped after %d failures", op.Failed)
+		}
+		return errors.Errorf("operation %s %s, commands not run on: %s",
+			op.Id, reason, strings.Join(receivers, ", "))
+	}
+	return nil
+}
+
+// operation returns the operation with the given ID.
+func operation(client RunClient, id string) (*params.Operation, error) {
+	results, err := client.Operations(params.OperationIds{Ids: []string{id}})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return nil, errors.New("illegal number of results returned")
+	}
+	if err := results.Results[0].Error; err != nil {
+		return nil, err
+	}
+	if results.Results[0].Operation == nil {
+		return nil, errors.NotFoundf("operation %q", id)
+	}
+	return results.Results[0].Operation, nil
+}
+
+// receiverType returns the key under which the ID of the given action
+// receiver is reported in the results.
+func receiverType(tag names.Tag) string {
+	switch tag.(type) {
+	case names.UnitTag:
+		return "UnitId"
+	case names.MachineTag:
+		return "MachineId"
+	default:
+		return "ReceiverId"
+	}
+}
+
 type actionReceiver struct {
 	receiverType string
 	tag          names.Tag
@@ -385,6 +571,8 @@ type RunClient interface {
 	action.APIClient
 	RunOnAllMachines(commands string, timeout time.Duration) ([]params.ActionResult, error)
 	Run(params.RunParams) ([]params.ActionResult, error)
+	RunOperation(params.RunParams) (params.OperationResult, error)
+	RunOperationOnAllMachines(params.RunParams) (params.OperationResult, error)
 }
 
 // In order to be able to easily mock out the API side for testing,
@@ -408,6 +408,136 @@ func (s *RunSuite) TestTimeout(c *gc.C) {
 	})
 }
 
+func (s *RunSuite) TestBatchArgParsing(c *gc.C) {
+	for i, test := range []struct {
+		args []string
+		err  string
+	}{{
+		args: []string{"--max-parallel", "-1"},
+		err:  "--max-parallel must not be negative",
+	}, {
+		args: []string{"--batch-size", "1", "--max-failures", "-1"},
+		err:  "--max-failures must not be negative",
+	}, {
+		args: []string{"--max-failures", "1"},
+		err:  "--max-failures requires --max-parallel",
+	}} {
+		c.Logf("%d: %v", i, test.args)
+		args := append([]string{"--all", "hostname"}, test.args...)
+		err := cmdtesting.InitCommand(newTestRunCommand(&mockClock{}), args)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *RunSuite) TestRunOperation(c *gc.C) {
+	mock := s.setupMockAPI()
+	mock.apiVersion = 4
+	mock.setResponse("0", mockResponse{stdout: "megatron\n", machineTag: "machine-0"})
+	mock.setResponse("1", mockResponse{stdout: "bumblebee\n", machineTag: "machine-1"})
+	machine0Result := mock.runResponses["0"]
+	machine1Result := mock.runResponses["1"]
+	mock.actionResponses = map[string]params.ActionResult{
+		mock.receiverIdMap["0"]: machine0Result,
+		mock.receiverIdMap["1"]: machine1Result,
+	}
+	op := params.Operation{
+		Id:        "1",
+		Receivers: []string{"machine-0", "machine-1"},
+		Status:    "running",
+		Actions: []params.OperationAction{{
+			Receiver: "machine-0",
+			Action:   machine0Result.Action.Tag,
+		}},
+	}
+	finished := op
+	finished.Status = "completed"
+	finished.Actions = append(finished.Actions, params.OperationAction{
+		Receiver: "machine-1",
+		Action:   machine1Result.Action.Tag,
+	})
+	mock.operations = []params.Operation{op, finished}
+
+	var buf bytes.Buffer
+	err := cmd.FormatJson(&buf, []interface{}{
+		ConvertActionResults(machine0Result, makeActionQuery(mock.receiverIdMap["0"], "MachineId", names.NewMachineTag("0"))),
+		ConvertActionResults(machine1Result, makeActionQuery(mock.receiverIdMap["1"], "MachineId", names.NewMachineTag("1"))),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	var clock mockClock
+	context, err := cmdtesting.RunCommand(
+		c, newTestRunCommand(&clock),
+		"--format=json", "--machine", "0,1", "--max-parallel", "1", "--max-failures", "1",
+		"--timeout", "99s", "hostname",
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(cmdtesting.Stdout(context), gc.Equals, buf.String())
+	c.Check(mock.runParams, jc.DeepEquals, params.RunParams{
+		Commands:    "hostname",
+		Timeout:     99 * time.Second,
+		Machines:    []string{"0", "1"},
+		MaxParallel: 1,
+		MaxFailures: 1,
+	})
+	clock.CheckCalls(c, []gitjujutesting.StubCall{
+		{"After", []interface{}{2 * 99 * time.Second}},
+		{"After", []interface{}{1 * time.Second}},
+	})
+}
+
+func (s *RunSuite) TestRunOperationStopped(c *gc.C) {
+	mock := s.setupMockAPI()
+	mock.apiVersion = 4
+	mock.setResponse("0", mockResponse{
+		stdout:     "megatron\n",
+		code:       "1",
+		machineTag: "machine-0",
+	})
+	machine0Result := mock.runResponses["0"]
+	mock.actionResponses = map[string]params.ActionResult{
+		mock.receiverIdMap["0"]: machine0Result,
+	}
+	mock.operations = []params.Operation{{
+		Id:        "1",
+		Receivers: []string{"machine-0", "machine-1", "machine-2"},
+		Status:    "stopped",
+		Completed: 2,
+		Failed:    1,
+		Actions: []params.OperationAction{{
+			Receiver: "machine-0",
+			Action:   machine0Result.Action.Tag,
+		}, {
+			Receiver: "machine-1",
+			Error:    "machine 1 not found",
+		}},
+	}}
+
+	var buf bytes.Buffer
+	err := cmd.FormatJson(&buf, []interface{}{
+		ConvertActionResults(machine0Result, makeActionQuery(mock.receiverIdMap["0"], "MachineId", names.NewMachineTag("0"))),
+		map[string]interface{}{
+			"MachineId": "1",
+			"Error":     "machine 1 not found",
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	context, err := cmdtesting.RunCommand(
+		c, newTestRunCommand(&mockClock{}),
+		"--format=json", "--all", "--max-parallel", "1", "--max-failures", "1", "hostname",
+	)
+	c.Assert(err, gc.ErrorMatches, "operation 1 stopped after 1 failures, commands not run on: machine 2")
+	c.Check(cmdtesting.Stdout(context), gc.Equals, buf.String())
+	c.Check(mock.runParams.MaxParallel, gc.Equals, 1)
+}
+
+func (s *RunSuite) TestRunOperationOldController(c *gc.C) {
+	mock := s.setupMockAPI()
+	mock.apiVersion = 3
+	_, err := cmdtesting.RunCommand(c, newTestRunCommand(&mockClock{}), "--all", "--max-parallel", "1", "hostname")
+	c.Assert(err, gc.ErrorMatches, "running commands in batches is unsupported by this API\nupgrade your controller to use --max-parallel")
+}
+
 type mockClock struct {
 	gitjujutesting.Stub
 	clock.Clock
@@ -533,6 +663,11 @@ type mockRunAPI struct {
 	actionResponses map[string]params.ActionResult
 	receiverIdMap   map[string]string
 	block           bool
+	apiVersion      int
+	// operations are returned in turn by RunOperation and Operations,
+	// the last one repeatedly.
+	operations []params.Operation
+	runParams  params.RunParams
 }
 
 type mockResponse struct {
@@ -662,6 +797,32 @@ func (m *mockRunAPI) Run(runParams params.RunParams) ([]params.ActionResult, err
 	return result, nil
 }
 
+func (m *mockRunAPI) BestAPIVersion() int {
+	return m.apiVersion
+}
+
+func (m *mockRunAPI) RunOperation(runParams params.RunParams) (params.OperationResult, error) {
+	m.runParams = runParams
+	return m.nextOperation(), nil
+}
+
+func (m *mockRunAPI) RunOperationOnAllMachines(runParams params.RunParams) (params.OperationResult, error) {
+	m.runParams = runParams
+	return m.nextOperation(), nil
+}
+
+func (m *mockRunAPI) Operations(arg params.OperationIds) (params.OperationResults, error) {
+	return params.OperationResults{Results: []params.OperationResult{m.nextOperation()}}, nil
+}
+
+func (m *mockRunAPI) nextOperation() params.OperationResult {
+	op := m.operations[0]
+	if len(m.operations) > 1 {
+		m.operations = m.operations[1:]
+	}
+	return params.OperationResult{Operation: &op}
+}
+
 func (m *mockRunAPI) Actions(actionTags params.Entities) (params.ActionResults, error) {
 	results := params.ActionResults{Results: make([]params.ActionResult, len(actionTags.Entities))}
 
@@ -0,0 +1,347 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package controller
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/gnuflag"
+
+	"github.com/juju/juju/api"
+	apicontroller "github.com/juju/juju/api/controller"
+	"github.com/juju/juju/apiserver/params"
+	jujucmd "github.com/juju/juju/cmd"
+	"github.com/juju/juju/cmd/modelcmd"
+	"github.com/juju/juju/cmd/output"
+	"github.com/juju/juju/juju"
+	"github.com/juju/juju/jujuclient"
+)
+
+// auditLogDialTimeout bounds how long we wait to connect to each
+// controller machine, so that an unreachable address doesn't stall
+// the whole query.
+const auditLogDialTimeout = 30 * time.Second
+
+// NewAuditLogCommand returns a command that queries the audit log of
+// each controller machine.
+func NewAuditLogCommand() cmd.Command {
+	return modelcmd.WrapController(&auditLogCommand{})
+}
+
+// AuditLogAPI defines the API methods used by the audit-log command.
+type AuditLogAPI interface {
+	Close() error
+	AuditLog(params.AuditLogQueryArgs) (params.AuditLogResults, error)
+}
+
+type auditLogCommand struct {
+	modelcmd.ControllerCommandBase
+	out cmd.Output
+
+	// newAPIs returns a client for each of the controller's API
+	// addresses. It can be replaced for testing.
+	newAPIs func() ([]AuditLogAPI, error)
+
+	query  params.AuditLogQueryArgs
+	after  string
+	before string
+}
+
+const auditLogCommandHelpDoc = `
+Displays the conversations (juju commands and the API requests they
+made) recorded in the controller's audit log. Each machine in an HA
+controller keeps its own audit log; records from all of them are
+merged, oldest first.
+
+Conversations can be filtered by user, model (name or UUID), facade
+and method called, conversation ID and time. Times can be given as
+RFC 3339 timestamps, dates (2006-01-02), or durations (for example
+"2h" means two hours ago).
+
+Only controller superusers can read the audit log.
+
+Examples:
+
+    juju audit-log
+    juju audit-log --user mary --after 24h
+    juju audit-log --model admin/prod --method DestroyApplication \
+        --after 2018-05-08 --before 2018-05-09
+    juju audit-log --conversation-id 0123456789abcdef --format yaml
+
+See also:
+    controller-config
+`
+
+// Info implements cmd.Command.
+func (c *auditLogCommand) Info() *cmd.Info {
+	return jujucmd.Info(&cmd.Info{
+		Name:    "audit-log",
+		Purpose: "Displays the controller's audit log.",
+		Doc:     strings.TrimSpace(auditLogCommandHelpDoc),
+	})
+}
+
+// SetFlags implements cmd.Command.
+func (c *auditLogCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.ControllerCommandBase.SetFlags(f)
+	f.StringVar(&c.query.Who, "user", "", "Only show conversations started by this user")
+	f.StringVar(&c.query.Model, "model", "", "Only show conversations with this model (name or UUID)")
+	f.StringVar(&c.query.Facade, "facade", "", "Only show requests to this facade")
+	f.StringVar(&c.query.Method, "method", "", "Only show requests to this method")
+	f.StringVar(&c.query.ConversationID, "conversation-id", "", "Only show this conversation")
+	f.StringVar(&c.after, "after", "", "Only show conversations started at or after this time")
+	f.StringVar(&c.before, "before", "", "Only show conversations started before this time")
+	f.IntVar(&c.query.Limit, "limit", 0, "Only show the most recent conversations, up to this number")
+	c.out.AddFlags(f, "tabular", map[string]cmd.Formatter{
+		"json":    cmd.FormatJson,
+		"tabular": formatAuditLogTabular,
+		"yaml":    cmd.FormatYaml,
+	})
+}
+
+// Init implements cmd.Command.
+func (c *auditLogCommand) Init(args []string) error {
+	if c.query.Limit < 0 {
+		return errors.NotValidf("negative limit")
+	}
+	now := time.Now()
+	if c.after != "" {
+		after, err := parseAuditLogTime(c.after, now)
+		if err != nil {
+			return errors.Annotate(err, "invalid --after")
+		}
+		c.query.After = &after
+	}
+	if c.before != "" {
+		before, err := parseAuditLogTime(c.before, now)
+		if err != nil {
+			return errors.Annotate(err, "invalid --before")
+		}
+		c.query.Before = &before
+	}
+	return cmd.CheckEmpty(args)
+}
+
+// parseAuditLogTime accepts an RFC 3339 timestamp, a date or a
+// duration before now.
+func parseAuditLogTime(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t.UTC(), nil
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d).UTC(), nil
+	}
+	return time.Time{}, errors.Errorf("expected a timestamp, date or duration, got %q", value)
+}
+
+// Run implements cmd.Command.
+func (c *auditLogCommand) Run(ctx *cmd.Context) error {
+	newAPIs := c.newAPIs
+	if newAPIs == nil {
+		newAPIs = c.controllerAPIs
+	}
+	apis, err := newAPIs()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer func() {
+		for _, client := range apis {
+			client.Close()
+		}
+	}()
+
+	var conversations []auditConversation
+	seen := make(map[string]bool)
+	var lastErr error
+	for _, client := range apis {
+		result, err := client.AuditLog(c.query)
+		if err != nil {
+			if errors.IsNotSupported(err) {
+				return errors.Trace(err)
+			}
+			logger.Warningf("reading audit log: %v", err)
+			lastErr = err
+			continue
+		}
+		// Several addresses can lead to the same controller machine.
+		if seen[result.ControllerMachine] {
+			continue
+		}
+		seen[result.ControllerMachine] = true
+		for _, conv := range result.Conversations {
+			conversations = append(conversations, makeAuditConversation(result.ControllerMachine, conv))
+		}
+	}
+	if len(seen) == 0 && lastErr != nil {
+		return errors.Annotate(lastErr, "reading audit log")
+	}
+
+	sort.SliceStable(conversations, func(i, j int) bool {
+		return conversations[i].When.Before(conversations[j].When)
+	})
+	if limit := c.query.Limit; limit > 0 && len(conversations) > limit {
+		conversations = conversations[len(conversations)-limit:]
+	}
+	if len(conversations) == 0 && c.out.Name() == "tabular" {
+		ctx.Infof("No matching audit log records.")
+		return nil
+	}
+	return c.out.Write(ctx, conversations)
+}
+
+// controllerAPIs connects to each of the controller's API addresses
+// in turn, since each controller machine only serves its own audit
+// log.
+func (c *auditLogCommand) controllerAPIs() ([]AuditLogAPI, error) {
+	controllerName, err := c.ControllerName()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	store := c.ClientStore()
+	details, err := store.ControllerByName(controllerName)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(details.APIEndpoints) <= 1 {
+		root, err := c.NewAPIRoot()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return []AuditLogAPI{apicontroller.NewClient(root)}, nil
+	}
+
+	accountDetails, err := store.AccountDetails(controllerName)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, errors.Trace(err)
+	}
+	if accountDetails == nil {
+		accountDetails = &jujuclient.AccountDetails{}
+	}
+	var apis []AuditLogAPI
+	for _, addr := range details.APIEndpoints {
+		connParams, err := c.NewAPIConnectionParams(store, controllerName, "", accountDetails)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		connParams.DialOpts.Timeout = auditLogDialTimeout
+		connParams.OpenAPI = openAPIAddress(connParams.OpenAPI, addr)
+		root, err := juju.NewAPIConnection(connParams)
+		if err != nil {
+			logger.Warningf("cannot connect to controller at %s: %v", addr, err)
+			continue
+		}
+		apis = append(apis, apicontroller.NewClient(root))
+	}
+	if len(apis) == 0 {
+		return nil, errors.Errorf("cannot connect to any of the controller's API addresses")
+	}
+	return apis, nil
+}
+
+// openAPIAddress returns an api.OpenFunc that only dials the given
+// address.
+func openAPIAddress(open api.OpenFunc, addr string) api.OpenFunc {
+	return func(info *api.Info, opts api.DialOpts) (api.Connection, error) {
+		infoCopy := *info
+		infoCopy.Addrs = []string{addr}
+		return open(&infoCopy, opts)
+	}
+}
+
+type auditConversation struct {
+	When              time.Time      `json:"when" yaml:"when"`
+	ControllerMachine string         `json:"controller-machine" yaml:"controller-machine"`
+	Who               string         `json:"who" yaml:"who"`
+	What              string         `json:"what" yaml:"what"`
+	Model             string         `json:"model" yaml:"model"`
+	ModelUUID         string         `json:"model-uuid" yaml:"model-uuid"`
+	ConversationID    string         `json:"conversation-id" yaml:"conversation-id"`
+	ConnectionID      string         `json:"connection-id" yaml:"connection-id"`
+	Requests          []auditRequest `json:"requests,omitempty" yaml:"requests,omitempty"`
+}
+
+type auditRequest struct {
+	When    time.Time `json:"when" yaml:"when"`
+	ID      uint64    `json:"id" yaml:"id"`
+	Facade  string    `json:"facade" yaml:"facade"`
+	Method  string    `json:"method" yaml:"method"`
+	Version int       `json:"version" yaml:"version"`
+	Args    string    `json:"args,omitempty" yaml:"args,omitempty"`
+	Path    string    `json:"path,omitempty" yaml:"path,omitempty"`
+	Size    int64     `json:"size,omitempty" yaml:"size,omitempty"`
+	Errors  []string  `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+func makeAuditConversation(machine string, conv params.AuditConversation) auditConversation {
+	result := auditConversation{
+		When:              conv.When,
+		ControllerMachine: machine,
+		Who:               conv.Who,
+		What:              conv.What,
+		Model:             conv.ModelName,
+		ModelUUID:         conv.ModelUUID,
+		ConversationID:    conv.ConversationID,
+		ConnectionID:      conv.ConnectionID,
+	}
+	for _, req := range conv.Requests {
+		request := auditRequest{
+			When:    req.When,
+			ID:      req.RequestID,
+			Facade:  req.Facade,
+			Method:  req.Method,
+			Version: req.Version,
+			Args:    req.Args,
+			Path:    req.Path,
+			Size:    req.Size,
+		}
+		for _, e := range req.Errors {
+			msg := e.Message
+			if e.Code != "" {
+				msg = fmt.Sprintf("%s (%s)", e.Message, e.Code)
+			}
+			request.Errors = append(request.Errors, msg)
+		}
+		result.Requests = append(result.Requests, request)
+	}
+	return result
+}
+
+func formatAuditLogTabular(writer io.Writer, value interface{}) error {
+	conversations, ok := value.([]auditConversation)
+	if !ok {
+		return errors.Errorf("expected value of type %T, got %T", conversations, value)
+	}
+	tw := output.TabWriter(writer)
+	w := output.Wrapper{tw}
+	w.Println("Time", "Controller", "User", "Model", "Command", "Request", "Errors")
+	for _, conv := range conversations {
+		when := conv.When.Format(time.RFC3339)
+		if len(conv.Requests) == 0 {
+			w.Println(when, conv.ControllerMachine, conv.Who, conv.Model, conv.What, "", "")
+			continue
+		}
+		for i, req := range conv.Requests {
+			request := fmt.Sprintf("%s.%s", req.Facade, req.Method)
+			if req.Path != "" {
+				request = fmt.Sprintf("%s %s", req.Method, req.Path)
+			}
+			errs := strings.Join(req.Errors, "; ")
+			if i == 0 {
+				w.Println(when, conv.ControllerMachine, conv.Who, conv.Model, conv.What, request, errs)
+			} else {
+				w.Println("", "", "", "", "", request, errs)
+			}
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,226 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package controller_test
+
+import (
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/cmd/cmdtesting"
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/juju/controller"
+)
+
+type AuditLogSuite struct {
+	baseControllerSuite
+}
+
+var _ = gc.Suite(&AuditLogSuite{})
+
+func (s *AuditLogSuite) SetUpTest(c *gc.C) {
+	s.baseControllerSuite.SetUpTest(c)
+	s.createTestClientStore(c)
+}
+
+func (s *AuditLogSuite) run(c *gc.C, apis []*fakeAuditLogAPI, args ...string) (*cmd.Context, error) {
+	var wrapped []controller.AuditLogAPI
+	for _, api := range apis {
+		wrapped = append(wrapped, api)
+	}
+	command := controller.NewAuditLogCommandForTest(s.store, wrapped...)
+	return cmdtesting.RunCommand(c, command, args...)
+}
+
+func (s *AuditLogSuite) TestInit(c *gc.C) {
+	tests := []struct {
+		args []string
+		err  string
+	}{{
+		args: []string{"--after", "2018-05-08T10:00:00Z", "--before", "2018-05-09"},
+	}, {
+		args: []string{"--after", "2h"},
+	}, {
+		args: []string{"--after", "yesterday"},
+		err:  `invalid --after: expected a timestamp, date or duration, got "yesterday"`,
+	}, {
+		args: []string{"--limit", "-1"},
+		err:  "negative limit not valid",
+	}, {
+		args: []string{"extra"},
+		err:  `unrecognized args: \["extra"\]`,
+	}}
+	for i, test := range tests {
+		c.Logf("%d: %v", i, test.args)
+		command := controller.NewAuditLogCommandForTest(s.store)
+		err := cmdtesting.InitCommand(command, test.args)
+		if test.err == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, gc.ErrorMatches, test.err)
+		}
+	}
+}
+
+func (s *AuditLogSuite) TestPassesFilter(c *gc.C) {
+	api := &fakeAuditLogAPI{machine: "0"}
+	_, err := s.run(c, []*fakeAuditLogAPI{api},
+		"--user", "mary",
+		"--model", "prod",
+		"--facade", "Application",
+		"--method", "Deploy",
+		"--conversation-id", "abc",
+		"--after", "2018-05-08T10:00:00Z",
+		"--limit", "5",
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	after := time.Date(2018, 5, 8, 10, 0, 0, 0, time.UTC)
+	api.CheckCalls(c, []testing.StubCall{
+		{"AuditLog", []interface{}{params.AuditLogQueryArgs{
+			Who:            "mary",
+			Model:          "prod",
+			Facade:         "Application",
+			Method:         "Deploy",
+			ConversationID: "abc",
+			After:          &after,
+			Limit:          5,
+		}}},
+		{"Close", nil},
+	})
+}
+
+func (s *AuditLogSuite) TestMergesControllerMachines(c *gc.C) {
+	t0 := time.Date(2018, 5, 8, 10, 0, 0, 0, time.UTC)
+	api0 := &fakeAuditLogAPI{
+		machine: "0",
+		conversations: []params.AuditConversation{{
+			Who:       "mary",
+			What:      "juju deploy mysql",
+			When:      t0,
+			ModelName: "prod",
+			Requests: []params.AuditRequest{{
+				When: t0, Facade: "Application", Method: "Deploy", Version: 7,
+			}, {
+				When: t0, Facade: "Application", Method: "SetConstraints", Version: 7,
+				Errors: []params.AuditError{{Message: "boom", Code: "not found"}},
+			}},
+		}},
+	}
+	api1 := &fakeAuditLogAPI{
+		machine: "1",
+		conversations: []params.AuditConversation{{
+			Who:       "bob",
+			What:      "juju status",
+			When:      t0.Add(-time.Minute),
+			ModelName: "dev",
+		}},
+	}
+	// A second address for machine 0 returns the same records, which
+	// should only be shown once.
+	api0b := &fakeAuditLogAPI{machine: "0", conversations: api0.conversations}
+	context, err := s.run(c, []*fakeAuditLogAPI{api0, api1, api0b})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cmdtesting.Stdout(context), gc.Equals, ""+
+		"Time                  Controller  User  Model  Command            Request                     Errors\n"+
+		"2018-05-08T09:59:00Z  1           bob   dev    juju status                                    \n"+
+		"2018-05-08T10:00:00Z  0           mary  prod   juju deploy mysql  Application.Deploy          \n"+
+		"                                                                  Application.SetConstraints  boom (not found)\n")
+}
+
+func (s *AuditLogSuite) TestLimitAppliesAcrossMachines(c *gc.C) {
+	t0 := time.Date(2018, 5, 8, 10, 0, 0, 0, time.UTC)
+	api0 := &fakeAuditLogAPI{
+		machine: "0",
+		conversations: []params.AuditConversation{
+			{Who: "mary", When: t0},
+			{Who: "mary", When: t0.Add(2 * time.Minute)},
+		},
+	}
+	api1 := &fakeAuditLogAPI{
+		machine: "1",
+		conversations: []params.AuditConversation{
+			{Who: "bob", When: t0.Add(time.Minute)},
+			{Who: "bob", When: t0.Add(3 * time.Minute)},
+		},
+	}
+	context, err := s.run(c, []*fakeAuditLogAPI{api0, api1}, "--limit", "2", "--format", "yaml")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cmdtesting.Stdout(context), gc.Equals, `
+- when: 2018-05-08T10:02:00Z
+  controller-machine: "0"
+  who: mary
+  what: ""
+  model: ""
+  model-uuid: ""
+  conversation-id: ""
+  connection-id: ""
+- when: 2018-05-08T10:03:00Z
+  controller-machine: "1"
+  who: bob
+  what: ""
+  model: ""
+  model-uuid: ""
+  conversation-id: ""
+  connection-id: ""
+`[1:])
+}
+
+func (s *AuditLogSuite) TestSkipsFailingMachine(c *gc.C) {
+	api0 := &fakeAuditLogAPI{machine: "0"}
+	api0.SetErrors(errors.New("connection reset"))
+	api1 := &fakeAuditLogAPI{
+		machine:       "1",
+		conversations: []params.AuditConversation{{Who: "bob"}},
+	}
+	context, err := s.run(c, []*fakeAuditLogAPI{api0, api1}, "--format", "json")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cmdtesting.Stdout(context), jc.Contains, `"who":"bob"`)
+}
+
+func (s *AuditLogSuite) TestAllMachinesFailing(c *gc.C) {
+	api0 := &fakeAuditLogAPI{machine: "0"}
+	api0.SetErrors(errors.New("connection reset"))
+	_, err := s.run(c, []*fakeAuditLogAPI{api0})
+	c.Assert(err, gc.ErrorMatches, "reading audit log: connection reset")
+}
+
+func (s *AuditLogSuite) TestNotSupported(c *gc.C) {
+	api0 := &fakeAuditLogAPI{machine: "0"}
+	api0.SetErrors(errors.NotSupportedf("querying the audit log with this controller version"))
+	_, err := s.run(c, []*fakeAuditLogAPI{api0})
+	c.Assert(err, gc.ErrorMatches, "querying the audit log with this controller version not supported")
+}
+
+func (s *AuditLogSuite) TestNoRecords(c *gc.C) {
+	context, err := s.run(c, []*fakeAuditLogAPI{{machine: "0"}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cmdtesting.Stdout(context), gc.Equals, "")
+	c.Assert(cmdtesting.Stderr(context), gc.Equals, "No matching audit log records.\n")
+}
+
+type fakeAuditLogAPI struct {
+	testing.Stub
+	machine       string
+	conversations []params.AuditConversation
+}
+
+func (f *fakeAuditLogAPI) Close() error {
+	f.MethodCall(f, "Close")
+	return nil
+}
+
+func (f *fakeAuditLogAPI) AuditLog(args params.AuditLogQueryArgs) (params.AuditLogResults, error) {
+	f.MethodCall(f, "AuditLog", args)
+	if err := f.NextErr(); err != nil {
+		return params.AuditLogResults{}, err
+	}
+	return params.AuditLogResults{
+		ControllerMachine: f.machine,
+		Conversations:     f.conversations,
+	}, nil
+}
@@ -189,3 +189,15 @@ func NewData(api destroyControllerAPI, ctrUUID string) (ctrData, []modelData, er
 var (
 	NoModelsMessage = noModelsMessage
 )
+
+// NewAuditLogCommandForTest returns an audit-log command that queries
+// the given APIs, one per controller machine.
+func NewAuditLogCommandForTest(store jujuclient.ClientStore, apis ...AuditLogAPI) cmd.Command {
+	c := &auditLogCommand{
+		newAPIs: func() ([]AuditLogAPI, error) {
+			return apis, nil
+		},
+	}
+	c.SetClientStore(store)
+	return modelcmd.WrapController(c)
+}
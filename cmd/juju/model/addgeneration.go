@@ -25,9 +25,10 @@ Examples:
     juju add-generation
 
 See also:
-	cancel-generation
-    set-generation
-    switch-generation
+    advance-generation
+    cancel-generation
+    show-generation
+    generations
 `
 )
 
@@ -96,10 +97,9 @@ func (c *addGenerationCommand) Run(ctx *cmd.Context) error {
 	}
 	defer client.Close()
 
-	// TODO (hml) 20-12-2018
-	// update to check err when AddGeneration() is implemented in the
-	// apiserver.
-	client.AddGeneration()
+	if err := client.AddGeneration(); err != nil {
+		return err
+	}
 
 	ctx.Stdout.Write([]byte("target generation set to next\n"))
 	return nil
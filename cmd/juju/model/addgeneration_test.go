@@ -22,14 +22,14 @@ import (
 	"github.com/juju/juju/testing"
 )
 
-type AddGenerationSuite struct {
+// generationBaseSuite sets up a client store with a current model and
+// enables the generations feature flag.
+type generationBaseSuite struct {
 	testing.FakeJujuXDGDataHomeSuite
 	store *jujuclient.MemStore
 }
 
-var _ = gc.Suite(&AddGenerationSuite{})
-
-func (s *AddGenerationSuite) SetUpTest(c *gc.C) {
+func (s *generationBaseSuite) SetUpTest(c *gc.C) {
 	s.FakeJujuXDGDataHomeSuite.SetUpTest(c)
 	if err := os.Setenv(osenv.JujuFeatureFlagEnvKey, feature.Generations); err != nil {
 		panic(err)
@@ -49,6 +49,12 @@ func (s *AddGenerationSuite) SetUpTest(c *gc.C) {
 	s.store.Models["testing"].CurrentModel = "admin/mymodel"
 }
 
+type AddGenerationSuite struct {
+	generationBaseSuite
+}
+
+var _ = gc.Suite(&AddGenerationSuite{})
+
 func (s *AddGenerationSuite) runInit(args ...string) error {
 	cmd := model.NewAddGenerationCommandForTest(nil, s.store)
 	return cmdtesting.InitCommand(cmd, args)
@@ -88,14 +94,12 @@ func (s *AddGenerationSuite) TestRunCommand(c *gc.C) {
 }
 
 func (s *AddGenerationSuite) TestRunCommandFail(c *gc.C) {
-	c.Skip("Until apiserver ModelGeneration.AddGeneration() implemented")
-
 	mockController, mockAddGenerationCommandAPI := setUpMocks(c)
 	defer mockController.Finish()
 
 	mockAddGenerationCommandAPI.EXPECT().AddGeneration().Return(errors.Errorf("failme")).Times(1)
 
 	ctx, err := s.runCommand(c, mockAddGenerationCommandAPI)
-	c.Assert(err, jc.ErrorIsNil)
-	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "failme")
+	c.Assert(err, gc.ErrorMatches, "failme")
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "")
 }
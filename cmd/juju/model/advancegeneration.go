@@ -0,0 +1,117 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package model
+
+import (
+	"fmt"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/gnuflag"
+
+	"github.com/juju/juju/api/modelgeneration"
+	jujucmd "github.com/juju/juju/cmd"
+	"github.com/juju/juju/cmd/modelcmd"
+)
+
+const (
+	advanceGenerationSummary = "Advances units and/or applications to the next generation."
+	advanceGenerationDoc     = `
+Advancing a unit to the next generation means that it realises the
+configuration changes made in that generation. Advancing an application
+advances all of its units.
+
+Once every unit of each application changed in the generation has been
+advanced, the generation is completed and its changes become current.
+
+Examples:
+    juju advance-generation redis/0
+    juju advance-generation redis/0 mysql
+
+See also:
+    add-generation
+    cancel-generation
+    show-generation
+    generations
+`
+)
+
+// NewAdvanceGenerationCommand wraps advanceGenerationCommand with sane model settings.
+func NewAdvanceGenerationCommand() cmd.Command {
+	return modelcmd.Wrap(&advanceGenerationCommand{})
+}
+
+// advanceGenerationCommand is the simplified command for accessing and setting
+// attributes related to advancing model generations.
+type advanceGenerationCommand struct {
+	modelcmd.ModelCommandBase
+
+	api AdvanceGenerationCommandAPI
+
+	entities []string
+}
+
+// AdvanceGenerationCommandAPI defines an API interface to be used during testing.
+//go:generate mockgen -package model_test -destination ./advancegenerationmock_test.go github.com/juju/juju/cmd/juju/model AdvanceGenerationCommandAPI
+type AdvanceGenerationCommandAPI interface {
+	Close() error
+	AdvanceGeneration([]string) (bool, error)
+}
+
+// Info implements part of the cmd.Command interface.
+func (c *advanceGenerationCommand) Info() *cmd.Info {
+	info := &cmd.Info{
+		Name:    "advance-generation",
+		Args:    "<unit name>|<application name> ...",
+		Purpose: advanceGenerationSummary,
+		Doc:     advanceGenerationDoc,
+	}
+	return jujucmd.Info(info)
+}
+
+// SetFlags implements part of the cmd.Command interface.
+func (c *advanceGenerationCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.ModelCommandBase.SetFlags(f)
+}
+
+// Init implements part of the cmd.Command interface.
+func (c *advanceGenerationCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.Errorf("unit and/or application names(s) must be specified")
+	}
+	c.entities = args
+	return nil
+}
+
+// getAPI returns the API. This allows passing in a test AdvanceGenerationCommandAPI
+// implementation.
+func (c *advanceGenerationCommand) getAPI() (AdvanceGenerationCommandAPI, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	api, err := c.NewAPIRoot()
+	if err != nil {
+		return nil, errors.Annotate(err, "opening API connection")
+	}
+	client := modelgeneration.NewClient(api)
+	return client, nil
+}
+
+// Run implements the meaty part of the cmd.Command interface.
+func (c *advanceGenerationCommand) Run(ctx *cmd.Context) error {
+	client, err := c.getAPI()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	completed, err := client.AdvanceGeneration(c.entities)
+	if err != nil {
+		return err
+	}
+	if completed {
+		fmt.Fprintln(ctx.Stdout, "generation completed, changes are now current")
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package model_test
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/juju/cmd"
+	"github.com/juju/cmd/cmdtesting"
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/cmd/juju/model"
+)
+
+type AdvanceGenerationSuite struct {
+	generationBaseSuite
+}
+
+var _ = gc.Suite(&AdvanceGenerationSuite{})
+
+func (s *AdvanceGenerationSuite) runInit(args ...string) error {
+	cmd := model.NewAdvanceGenerationCommandForTest(nil, s.store)
+	return cmdtesting.InitCommand(cmd, args)
+}
+
+func (s *AdvanceGenerationSuite) TestInit(c *gc.C) {
+	err := s.runInit("ubuntu/0", "redis")
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *AdvanceGenerationSuite) TestInitFail(c *gc.C) {
+	err := s.runInit()
+	c.Assert(err, gc.ErrorMatches, "unit and/or application names\\(s\\) must be specified")
+}
+
+func (s *AdvanceGenerationSuite) runCommand(c *gc.C, api model.AdvanceGenerationCommandAPI, args ...string) (*cmd.Context, error) {
+	cmd := model.NewAdvanceGenerationCommandForTest(api, s.store)
+	return cmdtesting.RunCommand(c, cmd, args...)
+}
+
+func setUpAdvanceMocks(c *gc.C) (*gomock.Controller, *MockAdvanceGenerationCommandAPI) {
+	mockController := gomock.NewController(c)
+	mockAdvanceGenerationCommandAPI := NewMockAdvanceGenerationCommandAPI(mockController)
+	mockAdvanceGenerationCommandAPI.EXPECT().Close().Times(1)
+	return mockController, mockAdvanceGenerationCommandAPI
+}
+
+func (s *AdvanceGenerationSuite) TestRunCommand(c *gc.C) {
+	mockController, api := setUpAdvanceMocks(c)
+	defer mockController.Finish()
+
+	api.EXPECT().AdvanceGeneration([]string{"ubuntu/0", "redis"}).Return(false, nil).Times(1)
+
+	ctx, err := s.runCommand(c, api, "ubuntu/0", "redis")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "")
+}
+
+func (s *AdvanceGenerationSuite) TestRunCommandCompletes(c *gc.C) {
+	mockController, api := setUpAdvanceMocks(c)
+	defer mockController.Finish()
+
+	api.EXPECT().AdvanceGeneration([]string{"redis"}).Return(true, nil).Times(1)
+
+	ctx, err := s.runCommand(c, api, "redis")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "generation completed, changes are now current\n")
+}
+
+func (s *AdvanceGenerationSuite) TestRunCommandFail(c *gc.C) {
+	mockController, api := setUpAdvanceMocks(c)
+	defer mockController.Finish()
+
+	api.EXPECT().AdvanceGeneration([]string{"redis"}).Return(false, errors.Errorf("failme")).Times(1)
+
+	_, err := s.runCommand(c, api, "redis")
+	c.Assert(err, gc.ErrorMatches, "failme")
+}
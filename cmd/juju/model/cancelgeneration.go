@@ -0,0 +1,110 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package model
+
+import (
+	"fmt"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/gnuflag"
+
+	"github.com/juju/juju/api/modelgeneration"
+	jujucmd "github.com/juju/juju/cmd"
+	"github.com/juju/juju/cmd/modelcmd"
+)
+
+const (
+	cancelGenerationSummary = "Cancels the next generation of the model."
+	cancelGenerationDoc     = `
+Cancelling a generation completes it without advancing every unit to it.
+Configuration changes for applications that have had all of their units
+advanced become current; changes for applications that have had none of
+their units advanced are discarded.
+
+A generation can not be cancelled while an application has only some of
+its units advanced; advance the remaining units first.
+
+Examples:
+    juju cancel-generation
+
+See also:
+    add-generation
+    advance-generation
+    show-generation
+    generations
+`
+)
+
+// NewCancelGenerationCommand wraps cancelGenerationCommand with sane model settings.
+func NewCancelGenerationCommand() cmd.Command {
+	return modelcmd.Wrap(&cancelGenerationCommand{})
+}
+
+// cancelGenerationCommand is the simplified command for cancelling a model
+// generation.
+type cancelGenerationCommand struct {
+	modelcmd.ModelCommandBase
+
+	api CancelGenerationCommandAPI
+}
+
+// CancelGenerationCommandAPI defines an API interface to be used during testing.
+//go:generate mockgen -package model_test -destination ./cancelgenerationmock_test.go github.com/juju/juju/cmd/juju/model CancelGenerationCommandAPI
+type CancelGenerationCommandAPI interface {
+	Close() error
+	CancelGeneration() error
+}
+
+// Info implements part of the cmd.Command interface.
+func (c *cancelGenerationCommand) Info() *cmd.Info {
+	info := &cmd.Info{
+		Name:    "cancel-generation",
+		Purpose: cancelGenerationSummary,
+		Doc:     cancelGenerationDoc,
+	}
+	return jujucmd.Info(info)
+}
+
+// SetFlags implements part of the cmd.Command interface.
+func (c *cancelGenerationCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.ModelCommandBase.SetFlags(f)
+}
+
+// Init implements part of the cmd.Command interface.
+func (c *cancelGenerationCommand) Init(args []string) error {
+	if len(args) != 0 {
+		return errors.Errorf("No arguments allowed")
+	}
+	return nil
+}
+
+// getAPI returns the API. This allows passing in a test CancelGenerationCommandAPI
+// implementation.
+func (c *cancelGenerationCommand) getAPI() (CancelGenerationCommandAPI, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	api, err := c.NewAPIRoot()
+	if err != nil {
+		return nil, errors.Annotate(err, "opening API connection")
+	}
+	client := modelgeneration.NewClient(api)
+	return client, nil
+}
+
+// Run implements the meaty part of the cmd.Command interface.
+func (c *cancelGenerationCommand) Run(ctx *cmd.Context) error {
+	client, err := c.getAPI()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := client.CancelGeneration(); err != nil {
+		return err
+	}
+	fmt.Fprintln(ctx.Stdout, "generation cancelled, target generation set to current")
+	return nil
+}
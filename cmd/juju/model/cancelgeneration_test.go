@@ -0,0 +1,69 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package model_test
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/juju/cmd"
+	"github.com/juju/cmd/cmdtesting"
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/cmd/juju/model"
+)
+
+type CancelGenerationSuite struct {
+	generationBaseSuite
+}
+
+var _ = gc.Suite(&CancelGenerationSuite{})
+
+func (s *CancelGenerationSuite) runInit(args ...string) error {
+	cmd := model.NewCancelGenerationCommandForTest(nil, s.store)
+	return cmdtesting.InitCommand(cmd, args)
+}
+
+func (s *CancelGenerationSuite) TestInit(c *gc.C) {
+	err := s.runInit()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *CancelGenerationSuite) TestInitFail(c *gc.C) {
+	err := s.runInit("test")
+	c.Assert(err, gc.ErrorMatches, "No arguments allowed")
+}
+
+func (s *CancelGenerationSuite) runCommand(c *gc.C, api model.CancelGenerationCommandAPI) (*cmd.Context, error) {
+	cmd := model.NewCancelGenerationCommandForTest(api, s.store)
+	return cmdtesting.RunCommand(c, cmd)
+}
+
+func setUpCancelMocks(c *gc.C) (*gomock.Controller, *MockCancelGenerationCommandAPI) {
+	mockController := gomock.NewController(c)
+	mockCancelGenerationCommandAPI := NewMockCancelGenerationCommandAPI(mockController)
+	mockCancelGenerationCommandAPI.EXPECT().Close().Times(1)
+	return mockController, mockCancelGenerationCommandAPI
+}
+
+func (s *CancelGenerationSuite) TestRunCommand(c *gc.C) {
+	mockController, api := setUpCancelMocks(c)
+	defer mockController.Finish()
+
+	api.EXPECT().CancelGeneration().Return(nil).Times(1)
+
+	ctx, err := s.runCommand(c, api)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "generation cancelled, target generation set to current\n")
+}
+
+func (s *CancelGenerationSuite) TestRunCommandFail(c *gc.C) {
+	mockController, api := setUpCancelMocks(c)
+	defer mockController.Finish()
+
+	api.EXPECT().CancelGeneration().Return(errors.Errorf("failme")).Times(1)
+
+	_, err := s.runCommand(c, api)
+	c.Assert(err, gc.ErrorMatches, "failme")
+}
@@ -188,3 +188,35 @@ func NewAddGenerationCommandForTest(api AddGenerationCommandAPI, store jujuclien
 	cmd.SetClientStore(store)
 	return modelcmd.Wrap(cmd)
 }
+
+func NewAdvanceGenerationCommandForTest(api AdvanceGenerationCommandAPI, store jujuclient.ClientStore) cmd.Command {
+	cmd := &advanceGenerationCommand{
+		api: api,
+	}
+	cmd.SetClientStore(store)
+	return modelcmd.Wrap(cmd)
+}
+
+func NewCancelGenerationCommandForTest(api CancelGenerationCommandAPI, store jujuclient.ClientStore) cmd.Command {
+	cmd := &cancelGenerationCommand{
+		api: api,
+	}
+	cmd.SetClientStore(store)
+	return modelcmd.Wrap(cmd)
+}
+
+func NewShowGenerationCommandForTest(api ShowGenerationCommandAPI, store jujuclient.ClientStore) cmd.Command {
+	cmd := &showGenerationCommand{
+		api: api,
+	}
+	cmd.SetClientStore(store)
+	return modelcmd.Wrap(cmd)
+}
+
+func NewGenerationsCommandForTest(api GenerationsCommandAPI, store jujuclient.ClientStore) cmd.Command {
+	cmd := &generationsCommand{
+		api: api,
+	}
+	cmd.SetClientStore(store)
+	return modelcmd.Wrap(cmd)
+}
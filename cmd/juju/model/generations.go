@@ -0,0 +1,165 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package model
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/gnuflag"
+
+	"github.com/juju/juju/api/modelgeneration"
+	"github.com/juju/juju/apiserver/params"
+	jujucmd "github.com/juju/juju/cmd"
+	"github.com/juju/juju/cmd/modelcmd"
+	"github.com/juju/juju/cmd/output"
+)
+
+const (
+	generationsSummary = "Lists the generations of the model."
+	generationsDoc     = `
+Lists every generation added to the model, oldest first, with the time
+it was created and completed, whether it was cancelled, and the
+applications changed in it.
+
+Examples:
+    juju generations
+    juju generations --format yaml
+
+See also:
+    add-generation
+    advance-generation
+    cancel-generation
+    show-generation
+`
+)
+
+// NewGenerationsCommand wraps generationsCommand with sane model settings.
+func NewGenerationsCommand() cmd.Command {
+	return modelcmd.Wrap(&generationsCommand{})
+}
+
+// generationsCommand lists the history of a model's generations.
+type generationsCommand struct {
+	modelcmd.ModelCommandBase
+	out cmd.Output
+
+	api GenerationsCommandAPI
+}
+
+// GenerationsCommandAPI defines an API interface to be used during testing.
+//go:generate mockgen -package model_test -destination ./generationsmock_test.go github.com/juju/juju/cmd/juju/model GenerationsCommandAPI
+type GenerationsCommandAPI interface {
+	Close() error
+	Generations() ([]params.Generation, error)
+}
+
+// Info implements part of the cmd.Command interface.
+func (c *generationsCommand) Info() *cmd.Info {
+	info := &cmd.Info{
+		Name:    "generations",
+		Purpose: generationsSummary,
+		Doc:     generationsDoc,
+	}
+	return jujucmd.Info(info)
+}
+
+// SetFlags implements part of the cmd.Command interface.
+func (c *generationsCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.ModelCommandBase.SetFlags(f)
+	c.out.AddFlags(f, "tabular", map[string]cmd.Formatter{
+		"yaml":    cmd.FormatYaml,
+		"json":    cmd.FormatJson,
+		"tabular": formatGenerationsTabular,
+	})
+}
+
+// Init implements part of the cmd.Command interface.
+func (c *generationsCommand) Init(args []string) error {
+	if len(args) != 0 {
+		return errors.Errorf("No arguments allowed")
+	}
+	return nil
+}
+
+// getAPI returns the API. This allows passing in a test GenerationsCommandAPI
+// implementation.
+func (c *generationsCommand) getAPI() (GenerationsCommandAPI, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	api, err := c.NewAPIRoot()
+	if err != nil {
+		return nil, errors.Annotate(err, "opening API connection")
+	}
+	client := modelgeneration.NewClient(api)
+	return client, nil
+}
+
+// Run implements the meaty part of the cmd.Command interface.
+func (c *generationsCommand) Run(ctx *cmd.Context) error {
+	client, err := c.getAPI()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	gens, err := client.Generations()
+	if err != nil {
+		return err
+	}
+	if len(gens) == 0 && c.out.Name() == "tabular" {
+		ctx.Infof("No generations have been added to this model.")
+		return nil
+	}
+	formatted := make([]formattedGeneration, len(gens))
+	for i, gen := range gens {
+		formatted[i] = formatGeneration(gen, false)
+	}
+	return c.out.Write(ctx, formatted)
+}
+
+// formatGenerationsTabular writes a tabular summary of model generations.
+func formatGenerationsTabular(writer io.Writer, value interface{}) error {
+	gens, ok := value.([]formattedGeneration)
+	if !ok {
+		return errors.Errorf("expected value of type %T, got %T", gens, value)
+	}
+
+	tw := output.TabWriter(writer)
+	w := output.Wrapper{tw}
+	w.Println("Generation", "Status", "Created", "Completed", "Applications")
+	for _, gen := range gens {
+		var apps []string
+		for name, app := range gen.Applications {
+			if app.UnitCount == 0 {
+				// Unit counts are only known for the next generation.
+				apps = append(apps, name)
+				continue
+			}
+			apps = append(apps, fmt.Sprintf("%s (%d/%d)", name, len(app.UnitsAdvanced), app.UnitCount))
+		}
+		sort.Strings(apps)
+		w.Println(gen.Id, generationStatus(gen), gen.Created, gen.Completed, strings.Join(apps, ", "))
+	}
+	tw.Flush()
+	return nil
+}
+
+// generationStatus describes the state of a generation for display.
+func generationStatus(gen formattedGeneration) string {
+	switch {
+	case gen.Cancelled:
+		return "cancelled"
+	case gen.Completed != "":
+		return "completed"
+	case gen.Active:
+		return "active"
+	}
+	return "pending"
+}
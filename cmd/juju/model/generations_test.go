@@ -0,0 +1,103 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package model_test
+
+import (
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/juju/cmd"
+	"github.com/juju/cmd/cmdtesting"
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/juju/model"
+)
+
+type GenerationsSuite struct {
+	generationBaseSuite
+}
+
+var _ = gc.Suite(&GenerationsSuite{})
+
+func (s *GenerationsSuite) runInit(args ...string) error {
+	cmd := model.NewGenerationsCommandForTest(nil, s.store)
+	return cmdtesting.InitCommand(cmd, args)
+}
+
+func (s *GenerationsSuite) TestInit(c *gc.C) {
+	err := s.runInit()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *GenerationsSuite) TestInitFail(c *gc.C) {
+	err := s.runInit("test")
+	c.Assert(err, gc.ErrorMatches, "No arguments allowed")
+}
+
+func (s *GenerationsSuite) runCommand(c *gc.C, api model.GenerationsCommandAPI, args ...string) (*cmd.Context, error) {
+	cmd := model.NewGenerationsCommandForTest(api, s.store)
+	return cmdtesting.RunCommand(c, cmd, args...)
+}
+
+func setUpGenerationsMocks(c *gc.C) (*gomock.Controller, *MockGenerationsCommandAPI) {
+	mockController := gomock.NewController(c)
+	mockGenerationsCommandAPI := NewMockGenerationsCommandAPI(mockController)
+	mockGenerationsCommandAPI.EXPECT().Close().Times(1)
+	return mockController, mockGenerationsCommandAPI
+}
+
+func (s *GenerationsSuite) TestRunCommand(c *gc.C) {
+	mockController, api := setUpGenerationsMocks(c)
+	defer mockController.Finish()
+
+	created := time.Date(2018, 12, 20, 10, 0, 0, 0, time.UTC)
+	completed := created.Add(time.Hour)
+	api.EXPECT().Generations().Return([]params.Generation{{
+		Id:        "1",
+		Created:   created,
+		Completed: &completed,
+		Applications: []params.GenerationApplication{{
+			ApplicationName: "redis",
+			UnitsAssigned:   []string{"redis/0", "redis/1"},
+			UnitCount:       2,
+		}},
+	}, {
+		Id:      "2",
+		Active:  true,
+		Created: completed,
+	}}, nil).Times(1)
+
+	ctx, err := s.runCommand(c, api)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, `
+Generation  Status     Created               Completed             Applications
+1           completed  2018-12-20T10:00:00Z  2018-12-20T11:00:00Z  redis (2/2)
+2           active     2018-12-20T11:00:00Z                        
+`[1:])
+}
+
+func (s *GenerationsSuite) TestRunCommandNone(c *gc.C) {
+	mockController, api := setUpGenerationsMocks(c)
+	defer mockController.Finish()
+
+	api.EXPECT().Generations().Return(nil, nil).Times(1)
+
+	ctx, err := s.runCommand(c, api)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "")
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, "No generations have been added to this model.\n")
+}
+
+func (s *GenerationsSuite) TestRunCommandFail(c *gc.C) {
+	mockController, api := setUpGenerationsMocks(c)
+	defer mockController.Finish()
+
+	api.EXPECT().Generations().Return(nil, errors.Errorf("failme")).Times(1)
+
+	_, err := s.runCommand(c, api)
+	c.Assert(err, gc.ErrorMatches, "failme")
+}
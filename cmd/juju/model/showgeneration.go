@@ -0,0 +1,188 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package model
+
+import (
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/gnuflag"
+
+	"github.com/juju/juju/api/modelgeneration"
+	"github.com/juju/juju/apiserver/params"
+	jujucmd "github.com/juju/juju/cmd"
+	"github.com/juju/juju/cmd/modelcmd"
+	"github.com/juju/juju/cmd/output"
+)
+
+const (
+	showGenerationSummary = "Displays details of the next generation of the model."
+	showGenerationDoc     = `
+Shows the applications changed in the model's next generation, the units
+of each that have been advanced to it, and the changes made: the charm
+each application was upgraded to, the resources attached to it, and its
+charm configuration changes. For each changed setting, both the current
+value and the value in the next generation are shown. A setting with no
+next value will be reset to its charm default.
+
+While a generation is active, upgrade-charm and attach-resource make their
+changes in it, so that only units advanced to it use the new charm or
+resource revision until the generation is completed.
+
+Examples:
+    juju show-generation
+    juju show-generation --format json
+
+See also:
+    add-generation
+    advance-generation
+    cancel-generation
+    generations
+`
+)
+
+// NewShowGenerationCommand wraps showGenerationCommand with sane model settings.
+func NewShowGenerationCommand() cmd.Command {
+	return modelcmd.Wrap(&showGenerationCommand{})
+}
+
+// showGenerationCommand displays the details of a model's next generation.
+type showGenerationCommand struct {
+	modelcmd.ModelCommandBase
+	out cmd.Output
+
+	api ShowGenerationCommandAPI
+}
+
+// ShowGenerationCommandAPI defines an API interface to be used during testing.
+//go:generate mockgen -package model_test -destination ./showgenerationmock_test.go github.com/juju/juju/cmd/juju/model ShowGenerationCommandAPI
+type ShowGenerationCommandAPI interface {
+	Close() error
+	GenerationInfo() (params.Generation, error)
+}
+
+// Info implements part of the cmd.Command interface.
+func (c *showGenerationCommand) Info() *cmd.Info {
+	info := &cmd.Info{
+		Name:    "show-generation",
+		Purpose: showGenerationSummary,
+		Doc:     showGenerationDoc,
+	}
+	return jujucmd.Info(info)
+}
+
+// SetFlags implements part of the cmd.Command interface.
+func (c *showGenerationCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.ModelCommandBase.SetFlags(f)
+	c.out.AddFlags(f, "yaml", output.DefaultFormatters)
+}
+
+// Init implements part of the cmd.Command interface.
+func (c *showGenerationCommand) Init(args []string) error {
+	if len(args) != 0 {
+		return errors.Errorf("No arguments allowed")
+	}
+	return nil
+}
+
+// getAPI returns the API. This allows passing in a test ShowGenerationCommandAPI
+// implementation.
+func (c *showGenerationCommand) getAPI() (ShowGenerationCommandAPI, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	api, err := c.NewAPIRoot()
+	if err != nil {
+		return nil, errors.Annotate(err, "opening API connection")
+	}
+	client := modelgeneration.NewClient(api)
+	return client, nil
+}
+
+// Run implements the meaty part of the cmd.Command interface.
+func (c *showGenerationCommand) Run(ctx *cmd.Context) error {
+	client, err := c.getAPI()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	gen, err := client.GenerationInfo()
+	if err != nil {
+		return err
+	}
+	return c.out.Write(ctx, formatGeneration(gen, true))
+}
+
+// formattedGeneration is the serialisation format of a model generation.
+type formattedGeneration struct {
+	Id           string                                    `yaml:"id" json:"id"`
+	Active       bool                                      `yaml:"active" json:"active"`
+	Created      string                                    `yaml:"created" json:"created"`
+	Completed    string                                    `yaml:"completed,omitempty" json:"completed,omitempty"`
+	Cancelled    bool                                      `yaml:"cancelled,omitempty" json:"cancelled,omitempty"`
+	Applications map[string]formattedGenerationApplication `yaml:"applications,omitempty" json:"applications,omitempty"`
+}
+
+// formattedGenerationApplication is the serialisation format of an
+// application changed in a model generation.
+type formattedGenerationApplication struct {
+	UnitsAdvanced []string                         `yaml:"units-advanced,omitempty" json:"units-advanced,omitempty"`
+	UnitCount     int                              `yaml:"unit-count" json:"unit-count"`
+	Charm         string                           `yaml:"charm,omitempty" json:"charm,omitempty"`
+	Resources     []string                         `yaml:"resources,omitempty" json:"resources,omitempty"`
+	Config        map[string]formattedConfigChange `yaml:"config,omitempty" json:"config,omitempty"`
+}
+
+// formattedConfigChange is the serialisation format of a charm config
+// setting changed in a model generation.
+type formattedConfigChange struct {
+	Current interface{} `yaml:"current,omitempty" json:"current,omitempty"`
+	Next    interface{} `yaml:"next,omitempty" json:"next,omitempty"`
+}
+
+// formatGeneration converts a generation into its serialisation format,
+// including the config changes made in it if withConfig is true.
+func formatGeneration(gen params.Generation, withConfig bool) formattedGeneration {
+	result := formattedGeneration{
+		Id:        gen.Id,
+		Active:    gen.Active,
+		Created:   formatGenerationTime(gen.Created),
+		Cancelled: gen.Cancelled,
+	}
+	if gen.Completed != nil {
+		result.Completed = formatGenerationTime(*gen.Completed)
+	}
+	if len(gen.Applications) > 0 {
+		result.Applications = make(map[string]formattedGenerationApplication)
+	}
+	for _, app := range gen.Applications {
+		formatted := formattedGenerationApplication{
+			UnitsAdvanced: app.UnitsAssigned,
+			UnitCount:     app.UnitCount,
+			Charm:         app.CharmURL,
+			Resources:     app.Resources,
+		}
+		if withConfig && len(app.ConfigChanges) > 0 {
+			formatted.Config = make(map[string]formattedConfigChange)
+			for _, change := range app.ConfigChanges {
+				formatted.Config[change.Key] = formattedConfigChange{
+					Current: change.Current,
+					Next:    change.Next,
+				}
+			}
+		}
+		result.Applications[app.ApplicationName] = formatted
+	}
+	return result
+}
+
+// formatGenerationTime formats a generation timestamp for display.
+func formatGenerationTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,105 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package model_test
+
+import (
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/juju/cmd"
+	"github.com/juju/cmd/cmdtesting"
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/juju/model"
+)
+
+type ShowGenerationSuite struct {
+	generationBaseSuite
+}
+
+var _ = gc.Suite(&ShowGenerationSuite{})
+
+func (s *ShowGenerationSuite) runInit(args ...string) error {
+	cmd := model.NewShowGenerationCommandForTest(nil, s.store)
+	return cmdtesting.InitCommand(cmd, args)
+}
+
+func (s *ShowGenerationSuite) TestInit(c *gc.C) {
+	err := s.runInit()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ShowGenerationSuite) TestInitFail(c *gc.C) {
+	err := s.runInit("test")
+	c.Assert(err, gc.ErrorMatches, "No arguments allowed")
+}
+
+func (s *ShowGenerationSuite) runCommand(c *gc.C, api model.ShowGenerationCommandAPI, args ...string) (*cmd.Context, error) {
+	cmd := model.NewShowGenerationCommandForTest(api, s.store)
+	return cmdtesting.RunCommand(c, cmd, args...)
+}
+
+func setUpShowMocks(c *gc.C) (*gomock.Controller, *MockShowGenerationCommandAPI) {
+	mockController := gomock.NewController(c)
+	mockShowGenerationCommandAPI := NewMockShowGenerationCommandAPI(mockController)
+	mockShowGenerationCommandAPI.EXPECT().Close().Times(1)
+	return mockController, mockShowGenerationCommandAPI
+}
+
+func (s *ShowGenerationSuite) TestRunCommand(c *gc.C) {
+	mockController, api := setUpShowMocks(c)
+	defer mockController.Finish()
+
+	api.EXPECT().GenerationInfo().Return(params.Generation{
+		Id:      "2",
+		Active:  true,
+		Created: time.Date(2018, 12, 20, 10, 0, 0, 0, time.UTC),
+		Applications: []params.GenerationApplication{{
+			ApplicationName: "redis",
+			UnitsAssigned:   []string{"redis/0"},
+			UnitCount:       2,
+			CharmURL:        "cs:redis-3",
+			Resources:       []string{"store"},
+			ConfigChanges: []params.GenerationConfigChange{
+				{Key: "password", Current: "foo", Next: "bar"},
+				{Key: "port", Current: 6379},
+			},
+		}},
+	}, nil).Times(1)
+
+	ctx, err := s.runCommand(c, api)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, `
+id: "2"
+active: true
+created: "2018-12-20T10:00:00Z"
+applications:
+  redis:
+    units-advanced:
+    - redis/0
+    unit-count: 2
+    charm: cs:redis-3
+    resources:
+    - store
+    config:
+      password:
+        current: foo
+        next: bar
+      port:
+        current: 6379
+`[1:])
+}
+
+func (s *ShowGenerationSuite) TestRunCommandFail(c *gc.C) {
+	mockController, api := setUpShowMocks(c)
+	defer mockController.Finish()
+
+	api.EXPECT().GenerationInfo().Return(params.Generation{}, errors.Errorf("failme")).Times(1)
+
+	_, err := s.runCommand(c, api)
+	c.Assert(err, gc.ErrorMatches, "failme")
+}
@@ -0,0 +1,261 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package status
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/naturalsort"
+	goyaml "gopkg.in/yaml.v2"
+
+	"github.com/juju/juju/cmd/output"
+)
+
+// statusDiff describes the entities added, removed and changed between
+// two snapshots of the status. Entities are named by their kind and ID,
+// eg "unit mysql/0".
+type statusDiff struct {
+	Added   []string                           `json:"added,omitempty" yaml:"added,omitempty"`
+	Removed []string                           `json:"removed,omitempty" yaml:"removed,omitempty"`
+	Changed map[string]map[string]statusChange `json:"changed,omitempty" yaml:"changed,omitempty"`
+}
+
+// statusChange holds the old and new values of a changed field.
+type statusChange struct {
+	Was string `json:"was" yaml:"was"`
+	Now string `json:"now" yaml:"now"`
+}
+
+// IsEmpty reports whether nothing changed.
+func (d statusDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// entityKinds orders the kinds of entity compared.
+var entityKinds = []string{"model", "machine", "application", "unit", "application-endpoint", "offer"}
+
+// writeDiff compares the current status with the snapshot in the diff
+// file, and writes the differences.
+func (c *statusCommand) writeDiff(ctx *cmd.Context, current formattedStatus) error {
+	data, err := ioutil.ReadFile(ctx.AbsPath(c.diffFile))
+	if err != nil {
+		return errors.Annotate(err, "cannot read status snapshot")
+	}
+	// YAML is a superset of JSON, so either format can be parsed.
+	var before map[string]interface{}
+	if err := goyaml.Unmarshal(data, &before); err != nil {
+		return errors.Annotatef(err, "cannot parse status snapshot %q", c.diffFile)
+	}
+	data, err = goyaml.Marshal(current)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	var after map[string]interface{}
+	if err := goyaml.Unmarshal(data, &after); err != nil {
+		return errors.Trace(err)
+	}
+
+	diff := diffStatus(statusEntities(before), statusEntities(after))
+	switch c.out.Name() {
+	case "yaml", "json":
+		return c.out.Write(ctx, diff)
+	}
+	if diff.IsEmpty() {
+		ctx.Infof("No changes since %s.", c.diffFile)
+		return nil
+	}
+	return formatStatusDiff(ctx.Stdout, diff)
+}
+
+// statusEntities flattens a status document into the fields of each
+// of its entities, keyed by entity name. Nested entities, such as the
+// units of an application, are entities in their own right rather
+// than fields of their parent.
+func statusEntities(doc map[string]interface{}) map[string]map[string]string {
+	entities := make(map[string]map[string]string)
+	if model, ok := doc["model"]; ok {
+		entities["model"] = flattenFields(model)
+	}
+	var addMachines func(interface{})
+	addMachines = func(machines interface{}) {
+		for id, machine := range asMap(machines) {
+			entities["machine "+id] = flattenFields(machine, "containers")
+			addMachines(asMap(machine)["containers"])
+		}
+	}
+	addMachines(doc["machines"])
+
+	var addUnits func(interface{})
+	addUnits = func(units interface{}) {
+		for name, unit := range asMap(units) {
+			entities["unit "+name] = flattenFields(unit, "subordinates")
+			addUnits(asMap(unit)["subordinates"])
+		}
+	}
+	for name, application := range asMap(doc["applications"]) {
+		entities["application "+name] = flattenFields(application, "units")
+		addUnits(asMap(application)["units"])
+	}
+	for name, endpoint := range asMap(doc["application-endpoints"]) {
+		entities["application-endpoint "+name] = flattenFields(endpoint)
+	}
+	for name, offer := range asMap(doc["offers"]) {
+		entities["offer "+name] = flattenFields(offer)
+	}
+	return entities
+}
+
+// flattenFields returns the leaf values of the document keyed by their
+// dotted path, omitting the excluded top level keys and the times at
+// which statuses were set.
+func flattenFields(doc interface{}, exclude ...string) map[string]string {
+	fields := make(map[string]string)
+	var flatten func(interface{}, string)
+	flatten = func(value interface{}, path string) {
+		switch value := value.(type) {
+		case map[interface{}]interface{}, map[string]interface{}:
+			for key, child := range asMap(value) {
+				if key == "since" {
+					continue
+				}
+				childPath := key
+				if path != "" {
+					childPath = path + "." + key
+				}
+				flatten(child, childPath)
+			}
+		case []interface{}:
+			items := make([]string, len(value))
+			for i, item := range value {
+				items[i] = fmt.Sprint(item)
+			}
+			fields[path] = strings.Join(items, ", ")
+		case nil:
+		default:
+			fields[path] = fmt.Sprint(value)
+		}
+	}
+	for key, value := range asMap(doc) {
+		excluded := false
+		for _, e := range exclude {
+			if key == e {
+				excluded = true
+			}
+		}
+		if !excluded && key != "since" {
+			flatten(value, key)
+		}
+	}
+	return fields
+}
+
+// asMap returns the value as a map with string keys, or nil if it
+// isn't a map.
+func asMap(value interface{}) map[string]interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		return value
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			result[fmt.Sprint(k)] = v
+		}
+		return result
+	}
+	return nil
+}
+
+// diffStatus compares the entities before and after.
+func diffStatus(before, after map[string]map[string]string) statusDiff {
+	var diff statusDiff
+	for name, fields := range after {
+		old, ok := before[name]
+		if !ok {
+			diff.Added = append(diff.Added, name)
+			continue
+		}
+		changes := make(map[string]statusChange)
+		for field, value := range fields {
+			if old[field] != value {
+				changes[field] = statusChange{Was: old[field], Now: value}
+			}
+		}
+		for field, value := range old {
+			if _, ok := fields[field]; !ok {
+				changes[field] = statusChange{Was: value}
+			}
+		}
+		if len(changes) > 0 {
+			if diff.Changed == nil {
+				diff.Changed = make(map[string]map[string]statusChange)
+			}
+			diff.Changed[name] = changes
+		}
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			diff.Removed = append(diff.Removed, name)
+		}
+	}
+	sortEntityNames(diff.Added)
+	sortEntityNames(diff.Removed)
+	return diff
+}
+
+// sortEntityNames sorts entity names by kind, then naturally by ID.
+func sortEntityNames(names []string) {
+	byKind := make([][]string, len(entityKinds)+1)
+	for _, name := range names {
+		kind := strings.SplitN(name, " ", 2)[0]
+		i := 0
+		for ; i < len(entityKinds); i++ {
+			if entityKinds[i] == kind {
+				break
+			}
+		}
+		byKind[i] = append(byKind[i], name)
+	}
+	n := 0
+	for _, kindNames := range byKind {
+		n += copy(names[n:], naturalsort.Sort(kindNames))
+	}
+}
+
+// formatStatusDiff writes a table of the added, removed and changed
+// entities, with a row for each changed field.
+func formatStatusDiff(writer io.Writer, diff statusDiff) error {
+	tw := output.TabWriter(writer)
+	w := output.Wrapper{tw}
+
+	w.Println("Entity", "Change", "Field", "Was", "Now")
+	for _, name := range diff.Added {
+		w.Println(name, "added", "", "", "")
+	}
+	for _, name := range diff.Removed {
+		w.Println(name, "removed", "", "", "")
+	}
+	var changed []string
+	for name := range diff.Changed {
+		changed = append(changed, name)
+	}
+	sortEntityNames(changed)
+	for _, name := range changed {
+		changes := diff.Changed[name]
+		fields := make([]string, 0, len(changes))
+		for field := range changes {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			w.Println(name, "changed", field, changes[field].Was, changes[field].Now)
+		}
+	}
+	return errors.Trace(tw.Flush())
+}
@@ -4,6 +4,7 @@
 package status
 
 import (
+	"github.com/juju/clock"
 	"github.com/juju/cmd"
 
 	"github.com/juju/juju/cmd/juju/storage"
@@ -14,7 +15,16 @@ func NewTestStatusHistoryCommand(api HistoryAPI) cmd.Command {
 	return &statusHistoryCommand{api: api}
 }
 
+func NewTestExportStatusLogCommand(api HistoryExportAPI, clock clock.Clock) cmd.Command {
+	return &exportStatusLogCommand{api: api, clock: clock}
+}
+
 func NewTestStatusCommand(statusapi statusAPI, storageapi storage.StorageListAPI, clock Clock) cmd.Command {
 	return modelcmd.Wrap(
 		&statusCommand{statusAPI: statusapi, storageAPI: storageapi, clock: clock})
 }
+
+func NewTestStatusWatchCommand(statusapi statusAPI, storageapi storage.StorageListAPI, clock Clock, watcher allWatcher) cmd.Command {
+	return modelcmd.Wrap(
+		&statusCommand{statusAPI: statusapi, storageAPI: storageapi, clock: clock, watcher: watcher})
+}
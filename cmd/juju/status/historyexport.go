@@ -0,0 +1,437 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package status
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/juju/clock"
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/gnuflag"
+	"github.com/juju/naturalsort"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/params"
+	jujucmd "github.com/juju/juju/cmd"
+	"github.com/juju/juju/cmd/modelcmd"
+	"github.com/juju/juju/core/status"
+)
+
+// NewExportStatusLogCommand returns a command that exports the status
+// history of all the units of an application, or all the machines in
+// a model.
+func NewExportStatusLogCommand() cmd.Command {
+	return modelcmd.Wrap(&exportStatusLogCommand{})
+}
+
+// HistoryExportAPI is the API surface for the export-status-log command.
+type HistoryExportAPI interface {
+	Status(patterns []string) (*params.FullStatus, error)
+	StatusHistories(kind status.HistoryKind, tags []names.Tag, filter status.StatusHistoryFilter) ([]status.History, error)
+	Close() error
+}
+
+type exportStatusLogCommand struct {
+	modelcmd.ModelCommandBase
+	api   HistoryExportAPI
+	clock clock.Clock
+	out   cmd.Output
+
+	application string
+	kind        string
+	days        int
+	fromDate    string
+	toDate      string
+	summary     bool
+
+	from time.Time
+	to   time.Time
+}
+
+const exportStatusLogDoc = `
+Export the status history of all the units of an application, or of all
+the machines or containers in the model, over a time window.
+
+The --type option selects the statuses exported, as for show-status-log.
+Unit types (unit, workload, juju-unit) require an application name; the
+machine and container types export every machine or container in the
+model and take no arguments.
+
+The window starts at --from-date, or --days days ago (1 by default), and
+ends before --to-date, or now. Dates are YYYY-MM-DD, in UTC.
+
+With --summary, the time each entity spent in each status during the
+window is reported instead of the individual statuses. Time before an
+entity's earliest status in the window isn't counted.
+
+The output is CSV by default; JSON and YAML are available with --format.
+
+Status history is only kept for as long as the model's
+max-status-history-age and max-status-history-size settings allow, after
+which it's pruned; export it before then to keep it.
+
+Examples:
+
+    juju export-status-log mysql
+    juju export-status-log mysql --type workload --summary --days 7
+    juju export-status-log --type machine --from-date 2018-07-01 --to-date 2018-07-08
+    juju export-status-log mysql --format json -o mysql-status.json
+
+See also:
+    show-status-log
+    model-config
+`
+
+func (c *exportStatusLogCommand) Info() *cmd.Info {
+	return jujucmd.Info(&cmd.Info{
+		Name:    "export-status-log",
+		Args:    "[<application name>]",
+		Purpose: "Export the status history of many entities.",
+		Doc:     exportStatusLogDoc,
+	})
+}
+
+func (c *exportStatusLogCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.ModelCommandBase.SetFlags(f)
+	f.StringVar(&c.kind, "type", "unit", fmt.Sprintf("Type of statuses to be exported [%v]", supportedHistoryKindTypes()))
+	f.IntVar(&c.days, "days", 0, "Export the statuses of the past <days> days (cannot be combined with --from-date)")
+	f.StringVar(&c.fromDate, "from-date", "", "Export the statuses from the given date, YYYY-MM-DD")
+	f.StringVar(&c.toDate, "to-date", "", "Export the statuses before the given date, YYYY-MM-DD")
+	f.BoolVar(&c.summary, "summary", false, "Report the time spent in each status")
+	c.out.AddFlags(f, "csv", map[string]cmd.Formatter{
+		"csv":  formatHistoryCSV,
+		"json": cmd.FormatJson,
+		"yaml": cmd.FormatYaml,
+	})
+}
+
+func (c *exportStatusLogCommand) Init(args []string) error {
+	kind := status.HistoryKind(c.kind)
+	if !kind.Valid() {
+		return errors.Errorf("unexpected status type %q", c.kind)
+	}
+	if isUnitHistoryKind(kind) {
+		switch len(args) {
+		case 0:
+			return errors.Errorf("application name is missing")
+		case 1:
+			if !names.IsValidApplication(args[0]) {
+				return errors.NotValidf("application name %q", args[0])
+			}
+			c.application = args[0]
+		default:
+			return cmd.CheckEmpty(args[1:])
+		}
+	} else if err := cmd.CheckEmpty(args); err != nil {
+		return err
+	}
+
+	if c.days < 0 {
+		return errors.New("--days must not be negative")
+	}
+	if c.days != 0 && c.fromDate != "" {
+		return errors.New("--days and --from-date cannot be specified together")
+	}
+	if c.clock == nil {
+		c.clock = clock.WallClock
+	}
+	now := c.clock.Now().UTC()
+	c.to = now
+	if c.toDate != "" {
+		var err error
+		if c.to, err = time.Parse("2006-01-02", c.toDate); err != nil {
+			return errors.Annotate(err, "parsing --to-date")
+		}
+	}
+	if c.fromDate != "" {
+		var err error
+		if c.from, err = time.Parse("2006-01-02", c.fromDate); err != nil {
+			return errors.Annotate(err, "parsing --from-date")
+		}
+	} else {
+		days := c.days
+		if days == 0 {
+			days = 1
+		}
+		c.from = c.to.Add(-time.Duration(days*24) * time.Hour)
+	}
+	if !c.to.After(c.from) {
+		return errors.New("the time window is empty")
+	}
+	return nil
+}
+
+func isUnitHistoryKind(kind status.HistoryKind) bool {
+	switch kind {
+	case status.KindUnit, status.KindWorkload, status.KindUnitAgent:
+		return true
+	}
+	return false
+}
+
+func (c *exportStatusLogCommand) getAPI() (HistoryExportAPI, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	return c.NewAPIClient()
+}
+
+// historyEntry is a status history entry, as exported.
+type historyEntry struct {
+	Entity  string `yaml:"entity" json:"entity"`
+	Kind    string `yaml:"type" json:"type"`
+	Status  string `yaml:"status" json:"status"`
+	Message string `yaml:"message,omitempty" json:"message,omitempty"`
+	Since   string `yaml:"since" json:"since"`
+}
+
+// historySummary is the time an entity spent in a status.
+type historySummary struct {
+	Entity   string `yaml:"entity" json:"entity"`
+	Kind     string `yaml:"type" json:"type"`
+	Status   string `yaml:"status" json:"status"`
+	Duration string `yaml:"duration" json:"duration"`
+	Seconds  int64  `yaml:"seconds" json:"seconds"`
+}
+
+func (c *exportStatusLogCommand) Run(ctx *cmd.Context) error {
+	apiclient, err := c.getAPI()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer apiclient.Close()
+
+	kind := status.HistoryKind(c.kind)
+	entities, err := c.entities(apiclient, kind)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(entities) == 0 {
+		ctx.Infof("No entities found.")
+		return nil
+	}
+	tags := make([]names.Tag, len(entities))
+	for i, entity := range entities {
+		if isUnitHistoryKind(kind) {
+			tags[i] = names.NewUnitTag(entity)
+		} else {
+			tags[i] = names.NewMachineTag(entity)
+		}
+	}
+	from := c.from
+	histories, err := apiclient.StatusHistories(kind, tags, status.StatusHistoryFilter{FromDate: &from})
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	if c.summary {
+		if err := c.addInitialEntries(apiclient, kind, tags, histories); err != nil {
+			return errors.Trace(err)
+		}
+		var summaries []historySummary
+		for i, history := range histories {
+			summaries = append(summaries, c.summarise(entities[i], history)...)
+		}
+		return c.out.Write(ctx, summaries)
+	}
+	var entries []historyEntry
+	for i, history := range histories {
+		for _, entry := range c.inWindow(history) {
+			entries = append(entries, historyEntry{
+				Entity:  entities[i],
+				Kind:    string(entry.Kind),
+				Status:  string(entry.Status),
+				Message: entry.Info,
+				Since:   entry.Since.UTC().Format(time.RFC3339),
+			})
+		}
+	}
+	return c.out.Write(ctx, entries)
+}
+
+// entities returns the names of the units or machines whose history
+// is exported.
+func (c *exportStatusLogCommand) entities(apiclient HistoryExportAPI, kind status.HistoryKind) ([]string, error) {
+	var patterns []string
+	if c.application != "" {
+		patterns = []string{c.application}
+	}
+	fullStatus, err := apiclient.Status(patterns)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	var entities []string
+	switch kind {
+	case status.KindUnit, status.KindWorkload, status.KindUnitAgent:
+		if _, ok := fullStatus.Applications[c.application]; !ok {
+			return nil, errors.NotFoundf("application %q", c.application)
+		}
+		var addUnits func(map[string]params.UnitStatus)
+		addUnits = func(units map[string]params.UnitStatus) {
+			for name, unit := range units {
+				if appName, err := names.UnitApplication(name); err == nil && appName == c.application {
+					entities = append(entities, name)
+				}
+				addUnits(unit.Subordinates)
+			}
+		}
+		for _, app := range fullStatus.Applications {
+			addUnits(app.Units)
+		}
+	case status.KindMachineInstance, status.KindMachine:
+		for id := range fullStatus.Machines {
+			entities = append(entities, id)
+		}
+	default:
+		var addContainers func(map[string]params.MachineStatus)
+		addContainers = func(containers map[string]params.MachineStatus) {
+			for id, container := range containers {
+				entities = append(entities, id)
+				addContainers(container.Containers)
+			}
+		}
+		for _, machine := range fullStatus.Machines {
+			addContainers(machine.Containers)
+		}
+	}
+	naturalsort.Sort(entities)
+	return entities, nil
+}
+
+// addInitialEntries adds to each history the last entry of each kind
+// set at or before the start of the window, so that the time between
+// the start of the window and the first change in it is counted. The
+// API can only limit the history by date or by size, so the entries
+// are found by fetching one more of the latest entries than were set
+// during the window.
+func (c *exportStatusLogCommand) addInitialEntries(
+	apiclient HistoryExportAPI, kind status.HistoryKind, tags []names.Tag, histories []status.History,
+) error {
+	kinds := []status.HistoryKind{kind}
+	if kind == status.KindUnit {
+		// The entries of the two kinds are merged before the size is
+		// applied, so each kind is fetched separately.
+		kinds = []status.HistoryKind{status.KindWorkload, status.KindUnitAgent}
+	}
+	for _, kind := range kinds {
+		size := 0
+		for _, history := range histories {
+			count := 0
+			for _, entry := range history {
+				if entry.Kind == kind {
+					count++
+				}
+			}
+			if count > size {
+				size = count
+			}
+		}
+		latest, err := apiclient.StatusHistories(kind, tags, status.StatusHistoryFilter{Size: size + 1})
+		if err != nil {
+			return errors.Trace(err)
+		}
+		for i, history := range latest {
+			var initial *status.DetailedStatus
+			for j, entry := range history {
+				if entry.Since == nil || entry.Since.After(c.from) {
+					continue
+				}
+				if initial == nil || entry.Since.After(*initial.Since) {
+					initial = &history[j]
+				}
+			}
+			// If the entity changed status again since the window was
+			// fetched, the latest entries may not reach back to the
+			// start of the window; the time before its first change
+			// in the window is then not counted.
+			if initial != nil {
+				histories[i] = append(histories[i], *initial)
+			}
+		}
+	}
+	return nil
+}
+
+// inWindow returns the entries of the history set during the window,
+// oldest first.
+func (c *exportStatusLogCommand) inWindow(history status.History) status.History {
+	var result status.History
+	for _, entry := range history {
+		if entry.Since == nil || entry.Since.Before(c.from) || !entry.Since.Before(c.to) {
+			continue
+		}
+		result = append(result, entry)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Since.Before(*result[j].Since)
+	})
+	return result
+}
+
+// summarise returns the time the entity spent in each status during
+// the window, for each kind of status in its history.
+func (c *exportStatusLogCommand) summarise(entity string, history status.History) []historySummary {
+	byKind := make(map[status.HistoryKind]status.History)
+	var kinds []string
+	for _, entry := range history {
+		if _, ok := byKind[entry.Kind]; !ok {
+			kinds = append(kinds, string(entry.Kind))
+		}
+		byKind[entry.Kind] = append(byKind[entry.Kind], entry)
+	}
+	sort.Strings(kinds)
+
+	var result []historySummary
+	for _, kind := range kinds {
+		durations := status.TimeInState(byKind[status.HistoryKind(kind)], c.from, c.to)
+		var statuses []string
+		for s := range durations {
+			statuses = append(statuses, string(s))
+		}
+		sort.Strings(statuses)
+		for _, s := range statuses {
+			d := durations[status.Status(s)]
+			result = append(result, historySummary{
+				Entity:   entity,
+				Kind:     kind,
+				Status:   s,
+				Duration: d.String(),
+				Seconds:  int64(d / time.Second),
+			})
+		}
+	}
+	return result
+}
+
+// formatHistoryCSV writes exported status history entries or summaries
+// as CSV, with a header row.
+func formatHistoryCSV(writer io.Writer, value interface{}) error {
+	w := csv.NewWriter(writer)
+	switch value := value.(type) {
+	case []historyEntry:
+		w.Write([]string{"entity", "type", "status", "message", "since"})
+		for _, entry := range value {
+			w.Write([]string{entry.Entity, entry.Kind, entry.Status, entry.Message, entry.Since})
+		}
+	case []historySummary:
+		w.Write([]string{"entity", "type", "status", "duration", "seconds"})
+		for _, summary := range value {
+			w.Write([]string{
+				summary.Entity, summary.Kind, summary.Status,
+				summary.Duration, strconv.FormatInt(summary.Seconds, 10),
+			})
+		}
+	default:
+		return errors.Errorf("expected value of type %T or %T, got %T", []historyEntry{}, []historySummary{}, value)
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,262 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package status_test
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/juju/clock/testclock"
+	"github.com/juju/cmd"
+	"github.com/juju/cmd/cmdtesting"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/params"
+	statuscmd "github.com/juju/juju/cmd/juju/status"
+	"github.com/juju/juju/core/status"
+)
+
+type ExportStatusLogSuite struct {
+	testing.IsolationSuite
+	api   *fakeHistoryExportAPI
+	clock *testclock.Clock
+	start time.Time
+}
+
+var _ = gc.Suite(&ExportStatusLogSuite{})
+
+func (s *ExportStatusLogSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.start = time.Date(2018, 7, 1, 0, 0, 0, 0, time.UTC)
+	s.clock = testclock.NewClock(s.start.Add(24 * time.Hour))
+	s.api = &fakeHistoryExportAPI{
+		status: &params.FullStatus{
+			Applications: map[string]params.ApplicationStatus{
+				"mysql": {Units: map[string]params.UnitStatus{
+					"mysql/0":  {},
+					"mysql/10": {},
+					"mysql/2":  {},
+				}},
+			},
+			Machines: map[string]params.MachineStatus{
+				"0": {Containers: map[string]params.MachineStatus{"0/lxd/0": {}}},
+				"1": {},
+			},
+		},
+	}
+}
+
+func (s *ExportStatusLogSuite) newCommand() cmd.Command {
+	return statuscmd.NewTestExportStatusLogCommand(s.api, s.clock)
+}
+
+func (s *ExportStatusLogSuite) at(hours int) *time.Time {
+	t := s.start.Add(time.Duration(hours) * time.Hour)
+	return &t
+}
+
+func (s *ExportStatusLogSuite) TestExportCSV(c *gc.C) {
+	s.api.histories = []status.History{{
+		{Kind: status.KindWorkload, Status: status.Active, Since: s.at(2)},
+		{Kind: status.KindWorkload, Status: status.Blocked, Info: "need config", Since: s.at(1)},
+		{Kind: status.KindWorkload, Status: status.Waiting, Since: s.at(-1)},
+	}, {}, {
+		{Kind: status.KindWorkload, Status: status.Error, Info: `hook failed: "install"`, Since: s.at(3)},
+	}}
+	ctx, err := cmdtesting.RunCommand(c, s.newCommand(), "mysql", "--type", "workload")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(cmdtesting.Stdout(ctx), gc.Equals, ""+
+		"entity,type,status,message,since\n"+
+		"mysql/0,workload,blocked,need config,2018-07-01T01:00:00Z\n"+
+		"mysql/0,workload,active,,2018-07-01T02:00:00Z\n"+
+		`mysql/10,workload,error,"hook failed: ""install""",2018-07-01T03:00:00Z`+"\n")
+
+	c.Check(s.api.patterns, jc.DeepEquals, []string{"mysql"})
+	c.Check(s.api.kind, gc.Equals, status.KindWorkload)
+	c.Check(s.api.tags, jc.DeepEquals, []names.Tag{
+		names.NewUnitTag("mysql/0"),
+		names.NewUnitTag("mysql/2"),
+		names.NewUnitTag("mysql/10"),
+	})
+	c.Check(s.api.filters, gc.HasLen, 1)
+	c.Check(*s.api.filters[0].FromDate, gc.Equals, s.start)
+}
+
+func (s *ExportStatusLogSuite) TestExportSummary(c *gc.C) {
+	s.api.histories = []status.History{{
+		{Kind: status.KindMachine, Status: status.Started, Since: s.at(12)},
+		{Kind: status.KindMachine, Status: status.Down, Since: s.at(2)},
+		{Kind: status.KindMachine, Status: status.Started, Since: s.at(-24)},
+	}, {}}
+	ctx, err := cmdtesting.RunCommand(c, s.newCommand(),
+		"--type", "juju-machine", "--summary", "--from-date", "2018-07-01", "--to-date", "2018-07-02")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(cmdtesting.Stdout(ctx), gc.Equals, ""+
+		"entity,type,status,duration,seconds\n"+
+		"0,juju-machine,down,10h0m0s,36000\n"+
+		"0,juju-machine,started,14h0m0s,50400\n")
+	c.Check(s.api.patterns, gc.IsNil)
+	c.Check(s.api.tags, jc.DeepEquals, []names.Tag{names.NewMachineTag("0"), names.NewMachineTag("1")})
+}
+
+func (s *ExportStatusLogSuite) TestExportSummaryCountsStatusBeforeFirstChange(c *gc.C) {
+	// Only the entries set during the window are returned by date;
+	// the status at the start of the window comes from the latest
+	// entries.
+	s.api.histories = []status.History{{
+		{Kind: status.KindMachine, Status: status.Started, Since: s.at(12)},
+		{Kind: status.KindMachine, Status: status.Down, Since: s.at(2)},
+	}, {}}
+	s.api.latest = map[status.HistoryKind][]status.History{
+		status.KindMachine: {{
+			{Kind: status.KindMachine, Status: status.Started, Since: s.at(12)},
+			{Kind: status.KindMachine, Status: status.Down, Since: s.at(2)},
+			{Kind: status.KindMachine, Status: status.Pending, Since: s.at(-24)},
+		}, {
+			{Kind: status.KindMachine, Status: status.Started, Since: s.at(-48)},
+		}},
+	}
+	ctx, err := cmdtesting.RunCommand(c, s.newCommand(),
+		"--type", "juju-machine", "--summary", "--from-date", "2018-07-01", "--to-date", "2018-07-02")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(cmdtesting.Stdout(ctx), gc.Equals, ""+
+		"entity,type,status,duration,seconds\n"+
+		"0,juju-machine,down,10h0m0s,36000\n"+
+		"0,juju-machine,pending,2h0m0s,7200\n"+
+		"0,juju-machine,started,12h0m0s,43200\n"+
+		"1,juju-machine,started,24h0m0s,86400\n")
+	c.Assert(s.api.filters, gc.HasLen, 2)
+	c.Check(*s.api.filters[0].FromDate, gc.Equals, s.start)
+	c.Check(s.api.filters[1], jc.DeepEquals, status.StatusHistoryFilter{Size: 3})
+	c.Check(s.api.kinds, jc.DeepEquals, []status.HistoryKind{status.KindMachine, status.KindMachine})
+}
+
+func (s *ExportStatusLogSuite) TestExportUnitSummaryFetchesEachKind(c *gc.C) {
+	s.api.histories = []status.History{{
+		{Kind: status.KindUnitAgent, Status: status.Executing, Since: s.at(2)},
+		{Kind: status.KindWorkload, Status: status.Active, Since: s.at(1)},
+		{Kind: status.KindUnitAgent, Status: status.Idle, Since: s.at(1)},
+	}, {}, {}}
+	s.api.latest = map[status.HistoryKind][]status.History{
+		status.KindWorkload: {{
+			{Kind: status.KindWorkload, Status: status.Active, Since: s.at(1)},
+			{Kind: status.KindWorkload, Status: status.Waiting, Since: s.at(-1)},
+		}, {}, {}},
+		status.KindUnitAgent: {{
+			{Kind: status.KindUnitAgent, Status: status.Executing, Since: s.at(2)},
+			{Kind: status.KindUnitAgent, Status: status.Idle, Since: s.at(1)},
+			{Kind: status.KindUnitAgent, Status: status.Allocating, Since: s.at(-2)},
+		}, {}, {}},
+	}
+	ctx, err := cmdtesting.RunCommand(c, s.newCommand(), "mysql", "--summary")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(cmdtesting.Stdout(ctx), gc.Equals, ""+
+		"entity,type,status,duration,seconds\n"+
+		"mysql/0,juju-unit,allocating,1h0m0s,3600\n"+
+		"mysql/0,juju-unit,executing,22h0m0s,79200\n"+
+		"mysql/0,juju-unit,idle,1h0m0s,3600\n"+
+		"mysql/0,workload,active,23h0m0s,82800\n"+
+		"mysql/0,workload,waiting,1h0m0s,3600\n")
+	c.Check(s.api.kinds, jc.DeepEquals, []status.HistoryKind{
+		status.KindUnit, status.KindWorkload, status.KindUnitAgent,
+	})
+	c.Check(s.api.filters[1], jc.DeepEquals, status.StatusHistoryFilter{Size: 2})
+	c.Check(s.api.filters[2], jc.DeepEquals, status.StatusHistoryFilter{Size: 3})
+}
+
+func (s *ExportStatusLogSuite) TestExportContainersJSON(c *gc.C) {
+	s.api.histories = []status.History{{
+		{Kind: status.KindContainerInstance, Status: status.Running, Info: "Running", Since: s.at(1)},
+	}}
+	ctx, err := cmdtesting.RunCommand(c, s.newCommand(), "--type", "container", "--format", "json")
+	c.Assert(err, jc.ErrorIsNil)
+	var out []map[string]interface{}
+	err = json.Unmarshal([]byte(cmdtesting.Stdout(ctx)), &out)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(out, jc.DeepEquals, []map[string]interface{}{{
+		"entity":  "0/lxd/0",
+		"type":    "container",
+		"status":  "running",
+		"message": "Running",
+		"since":   "2018-07-01T01:00:00Z",
+	}})
+	c.Check(s.api.tags, jc.DeepEquals, []names.Tag{names.NewMachineTag("0/lxd/0")})
+}
+
+func (s *ExportStatusLogSuite) TestApplicationNotFound(c *gc.C) {
+	_, err := cmdtesting.RunCommand(c, s.newCommand(), "wordpress")
+	c.Assert(err, gc.ErrorMatches, `application "wordpress" not found`)
+}
+
+func (s *ExportStatusLogSuite) TestInitErrors(c *gc.C) {
+	for i, test := range []struct {
+		args []string
+		err  string
+	}{{
+		args: nil,
+		err:  "application name is missing",
+	}, {
+		args: []string{"mysql/0"},
+		err:  `application name "mysql/0" not valid`,
+	}, {
+		args: []string{"--type", "machine", "mysql"},
+		err:  `unrecognized args: \["mysql"\]`,
+	}, {
+		args: []string{"mysql", "--type", "nope"},
+		err:  `unexpected status type "nope"`,
+	}, {
+		args: []string{"mysql", "--days", "2", "--from-date", "2018-07-01"},
+		err:  "--days and --from-date cannot be specified together",
+	}, {
+		args: []string{"mysql", "--from-date", "2018-07-02", "--to-date", "2018-07-01"},
+		err:  "the time window is empty",
+	}, {
+		args: []string{"mysql", "--to-date", "tomorrow"},
+		err:  "parsing --to-date: .*",
+	}} {
+		c.Logf("test %d: %v", i, test.args)
+		err := cmdtesting.InitCommand(s.newCommand(), test.args)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+type fakeHistoryExportAPI struct {
+	status    *params.FullStatus
+	histories []status.History
+	// latest holds the histories returned, by kind, when the history
+	// is limited by size rather than by date.
+	latest map[status.HistoryKind][]status.History
+
+	patterns []string
+	kind     status.HistoryKind
+	kinds    []status.HistoryKind
+	tags     []names.Tag
+	filters  []status.StatusHistoryFilter
+}
+
+func (*fakeHistoryExportAPI) Close() error {
+	return nil
+}
+
+func (f *fakeHistoryExportAPI) Status(patterns []string) (*params.FullStatus, error) {
+	f.patterns = patterns
+	return f.status, nil
+}
+
+func (f *fakeHistoryExportAPI) StatusHistories(kind status.HistoryKind, tags []names.Tag, filter status.StatusHistoryFilter) ([]status.History, error) {
+	f.kind = kind
+	f.kinds = append(f.kinds, kind)
+	f.tags = tags
+	f.filters = append(f.filters, filter)
+	if filter.Size > 0 {
+		if latest, ok := f.latest[kind]; ok {
+			return latest, nil
+		}
+		return make([]status.History, len(tags)), nil
+	}
+	return f.histories, nil
+}
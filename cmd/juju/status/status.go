@@ -23,6 +23,7 @@ import (
 	jujucmd "github.com/juju/juju/cmd"
 	"github.com/juju/juju/cmd/juju/storage"
 	"github.com/juju/juju/cmd/modelcmd"
+	corestatus "github.com/juju/juju/core/status"
 	"github.com/juju/juju/juju/osenv"
 )
 
@@ -30,6 +31,7 @@ var logger = loggo.GetLogger("juju.cmd.juju.status")
 
 type statusAPI interface {
 	Status(patterns []string) (*params.FullStatus, error)
+	FilteredStatus(patterns, filters []string) (*params.FullStatus, error)
 	Close() error
 }
 
@@ -68,6 +70,20 @@ type statusCommand struct {
 
 	// storage indicates if 'storage' section is displayed
 	storage bool
+
+	// watch indicates that the status is redrawn as the model changes.
+	watch   bool
+	watcher allWatcher
+
+	// filters holds the --filter expressions; an entity is shown if
+	// it satisfies any of them.
+	filters []string
+
+	// diffFile is the path of a status snapshot to compare with.
+	diffFile string
+
+	// formatters holds the output formatters, by name.
+	formatters map[string]cmd.Formatter
 }
 
 var usageSummary = `
@@ -87,6 +103,21 @@ Machine numbers may also be used as output filters. This will only display data
 in each section relevant to the specified machines. For example, application 
 section will only contain the applications that have units on these machines, etc.
 
+The --filter option restricts the output to the applications and units in a
+given state. A filter is a comma separated list of terms, all of which must
+hold, where each term is <key>=<value>[|<value>...]. The supported keys are:
+
+- workload: the unit's workload status, e.g. blocked or error
+- agent: the unit's agent status, e.g. lost or executing
+- relation: an application or endpoint the application is related to,
+      e.g. mysql or mysql:db
+- charm: the application's charm, by name or URL, e.g. postgresql or
+      cs:postgresql; the series and revision may be omitted from a URL
+
+The option may be repeated, in which case entities satisfying any of the
+filters are shown. Machines are shown if they host a unit that is shown.
+Filters are evaluated by the controller, and combine with any patterns.
+
 The available output formats are:
 
 - tabular (default): Displays status in a tabular format with a separate table
@@ -110,12 +141,28 @@ In tabular format, 'Relations' section is not displayed by default.
 Use --relations option to see this section. This option is ignored in all other 
 formats.
 
+With --watch, the status is redrawn whenever the model changes, until the
+command is interrupted. Changes made within a second of the first are drawn
+together.
+
+With --diff, the status is compared with a snapshot previously saved with
+--format yaml or --format json, and the machines, applications, units,
+offers and application endpoints that were added, removed or changed since
+are reported instead. The times at which statuses were last set are not
+compared.
+
 Examples:
     juju show-status
     juju show-status mysql
     juju show-status nova-*
     juju show-status --relations
     juju show-status --storage
+    juju show-status --filter 'workload=blocked,agent=lost'
+    juju show-status --filter 'relation=mysql:db'
+    juju show-status --filter 'charm=cs:postgresql' --filter 'workload=error'
+    juju show-status --watch
+    juju show-status --format yaml > before.yaml
+    juju show-status --diff before.yaml
 
 See also:
     machines
@@ -145,6 +192,10 @@ func (c *statusCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.IntVar(&c.retryCount, "retry-count", 3, "Number of times to retry API failures")
 	f.DurationVar(&c.retryDelay, "retry-delay", 100*time.Millisecond, "Time to wait between retry attempts")
 
+	f.Var(cmd.NewAppendStringsValue(&c.filters), "filter", "Only show entities satisfying the filter, e.g. 'workload=blocked'; may be repeated")
+	f.BoolVar(&c.watch, "watch", false, "Redraw the status as the model changes")
+	f.StringVar(&c.diffFile, "diff", "", "Report changes since the status saved in a yaml or json file")
+
 	c.checkProvidedIgnoredFlagF = func() set.Strings {
 		ignoredFlagForNonTabularFormat := set.NewStrings(
 			"relations",
@@ -162,7 +213,7 @@ func (c *statusCommand) SetFlags(f *gnuflag.FlagSet) {
 
 	defaultFormat := "tabular"
 
-	c.out.AddFlags(f, defaultFormat, map[string]cmd.Formatter{
+	c.formatters = map[string]cmd.Formatter{
 		"yaml":    cmd.FormatYaml,
 		"json":    cmd.FormatJson,
 		"short":   FormatOneline,
@@ -170,11 +221,20 @@ func (c *statusCommand) SetFlags(f *gnuflag.FlagSet) {
 		"line":    FormatOneline,
 		"tabular": c.FormatTabular,
 		"summary": FormatSummary,
-	})
+	}
+	c.out.AddFlags(f, defaultFormat, c.formatters)
 }
 
 func (c *statusCommand) Init(args []string) error {
+	if c.watch && c.diffFile != "" {
+		return errors.New("cannot use --watch with --diff")
+	}
 	c.patterns = args
+	for _, filter := range c.filters {
+		if _, err := corestatus.ParseFilter(filter); err != nil {
+			return errors.Annotatef(err, "invalid --filter %q", filter)
+		}
+	}
 	// If use of ISO time not specified on command line,
 	// check env var.
 	if !c.isoTime {
@@ -233,6 +293,9 @@ func (c *statusCommand) getStatus() (*params.FullStatus, error) {
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	if len(c.filters) > 0 {
+		return apiclient.FilteredStatus(c.patterns, c.filters)
+	}
 	return apiclient.Status(c.patterns)
 }
 
@@ -259,6 +322,56 @@ func (c *statusCommand) Run(ctx *cmd.Context) error {
 		}
 	}()
 
+	if c.watch {
+		return c.runWatch(ctx)
+	}
+
+	status, err := c.fetchStatus(ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	if c.diffFile != "" {
+		formatted, err := c.formatStatus(ctx, status, true, false)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		return c.writeDiff(ctx, formatted)
+	}
+
+	showRelations, showStorage := c.showSections(ctx)
+	formatted, err := c.formatStatus(ctx, status, showRelations, showStorage)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	if err = c.out.Write(ctx, formatted); err != nil {
+		return err
+	}
+
+	if !status.IsEmpty() {
+		return nil
+	}
+	if len(c.patterns) == 0 && len(c.filters) == 0 {
+		modelName, err := c.ModelName()
+		if err != nil {
+			return err
+		}
+		ctx.Infof("Model %q is empty.", modelName)
+	} else {
+		plural := func() string {
+			if len(c.patterns)+len(c.filters) == 1 {
+				return ""
+			}
+			return "s"
+		}
+		ctx.Infof("Nothing matched specified filter%v.", plural())
+	}
+	return nil
+}
+
+// fetchStatus gets the status of the model, retrying if it fails.
+func (c *statusCommand) fetchStatus(ctx *cmd.Context) (*params.FullStatus, error) {
 	// Always attempt to get the status at least once, and retry if it fails.
 	status, err := c.getStatus()
 	if err != nil {
@@ -277,21 +390,21 @@ func (c *statusCommand) Run(ctx *cmd.Context) error {
 	if err != nil {
 		if status == nil {
 			// Status call completely failed, there is nothing to report
-			return errors.Trace(err)
+			return nil, errors.Trace(err)
 		}
 		// Display any error, but continue to print status if some was returned
 		fmt.Fprintf(ctx.Stderr, "%v\n", err)
 	} else if status == nil {
-		return errors.Errorf("unable to obtain the current status")
-	}
-
-	controllerName, err := c.ControllerName()
-	if err != nil {
-		return errors.Trace(err)
+		return nil, errors.Errorf("unable to obtain the current status")
 	}
+	return status, nil
+}
 
-	showRelations := c.relations
-	showStorage := c.storage
+// showSections returns whether the relations and storage sections
+// are displayed in the chosen output format.
+func (c *statusCommand) showSections(ctx *cmd.Context) (showRelations, showStorage bool) {
+	showRelations = c.relations
+	showStorage = c.storage
 	if c.out.Name() != "tabular" {
 		showRelations = true
 		showStorage = true
@@ -307,6 +420,15 @@ func (c *statusCommand) Run(ctx *cmd.Context) error {
 			ctx.Infof("provided %s always enabled in non tabular formats", joinedMsg)
 		}
 	}
+	return showRelations, showStorage
+}
+
+func (c *statusCommand) formatStatus(ctx *cmd.Context, status *params.FullStatus, showRelations, showStorage bool) (formattedStatus, error) {
+	controllerName, err := c.ControllerName()
+	if err != nil {
+		return formattedStatus{}, errors.Trace(err)
+	}
+
 	formatterParams := newStatusFormatterParams{
 		status:         status,
 		controllerName: controllerName,
@@ -316,39 +438,11 @@ func (c *statusCommand) Run(ctx *cmd.Context) error {
 	if showStorage {
 		storageInfo, err := c.getStorageInfo(ctx)
 		if err != nil {
-			return errors.Trace(err)
+			return formattedStatus{}, errors.Trace(err)
 		}
 		formatterParams.storage = storageInfo
 	}
-
-	formatted, err := newStatusFormatter(formatterParams).format()
-	if err != nil {
-		return errors.Trace(err)
-	}
-
-	if err = c.out.Write(ctx, formatted); err != nil {
-		return err
-	}
-
-	if !status.IsEmpty() {
-		return nil
-	}
-	if len(c.patterns) == 0 {
-		modelName, err := c.ModelName()
-		if err != nil {
-			return err
-		}
-		ctx.Infof("Model %q is empty.", modelName)
-	} else {
-		plural := func() string {
-			if len(c.patterns) == 1 {
-				return ""
-			}
-			return "s"
-		}
-		ctx.Infof("Nothing matched specified filter%v.", plural())
-	}
-	return nil
+	return newStatusFormatter(formatterParams).format()
 }
 
 func (c *statusCommand) FormatTabular(writer io.Writer, value interface{}) error {
@@ -4566,6 +4566,11 @@ func (a *fakeAPIClient) Status(patterns []string) (*params.FullStatus, error) {
 	return a.statusReturn, nil
 }
 
+func (a *fakeAPIClient) FilteredStatus(patterns, filters []string) (*params.FullStatus, error) {
+	a.patternsUsed = patterns
+	return a.statusReturn, nil
+}
+
 func (a *fakeAPIClient) Close() error {
 	a.closeCalled = true
 	return nil
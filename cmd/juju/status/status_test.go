@@ -5,6 +5,9 @@ package status_test
 
 import (
 	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/juju/cmd"
@@ -15,6 +18,7 @@ import (
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/juju/status"
 	corestatus "github.com/juju/juju/core/status"
+	"github.com/juju/juju/state/multiwatcher"
 	"github.com/juju/juju/testing"
 )
 
@@ -130,17 +134,182 @@ func (s *MinimalStatusSuite) TestRetryCountOfZero(c *gc.C) {
 	c.Assert(s.clock.waits, gc.HasLen, 0)
 }
 
+func (s *MinimalStatusSuite) TestFilter(c *gc.C) {
+	_, err := s.runStatus(c, "mysql", "--filter", "workload=blocked,agent=lost", "--filter", "charm=cs:postgresql")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(s.statusapi.patterns, jc.DeepEquals, []string{"mysql"})
+	c.Check(s.statusapi.filters, jc.DeepEquals, []string{"workload=blocked,agent=lost", "charm=cs:postgresql"})
+}
+
+func (s *MinimalStatusSuite) TestFilterInvalid(c *gc.C) {
+	_, err := s.runStatus(c, "--filter", "workload=sleepy")
+	c.Assert(err, gc.ErrorMatches, `invalid --filter "workload=sleepy": invalid filter term "workload=sleepy": workload status "sleepy" not valid`)
+	c.Check(s.statusapi.filters, gc.IsNil)
+}
+
+func (s *MinimalStatusSuite) TestWatchAndDiffExclusive(c *gc.C) {
+	_, err := s.runStatus(c, "--watch", "--diff", "before.yaml")
+	c.Assert(err, gc.ErrorMatches, "cannot use --watch with --diff")
+}
+
+func (s *MinimalStatusSuite) TestWatch(c *gc.C) {
+	changed := *s.statusapi.result
+	changed.Model.Version = "2.5.0"
+	s.statusapi.results = []*params.FullStatus{s.statusapi.result, s.statusapi.result, &changed}
+	// Each change is only delivered once the last has been fetched,
+	// so that none are coalesced.
+	s.statusapi.fetched = make(chan struct{}, 3)
+	watcher := &fakeAllWatcher{batches: 3, fetched: s.statusapi.fetched}
+
+	statusCmd := status.NewTestStatusWatchCommand(s.statusapi, s.storageapi, s.clock, watcher)
+	ctx, err := cmdtesting.RunCommand(c, statusCmd, "--watch", "--format", "yaml")
+	c.Assert(err, gc.ErrorMatches, "watching status: watcher stopped")
+	c.Assert(watcher.stopped, jc.IsTrue)
+
+	// The second change doesn't alter the status, so it isn't redrawn.
+	docs := strings.Split(cmdtesting.Stdout(ctx), "---\n")
+	c.Assert(docs, gc.HasLen, 3)
+	c.Check(docs[0], gc.Equals, "")
+	c.Check(docs[1], gc.Matches, `(?s).*version: ""\n.*`)
+	c.Check(docs[2], gc.Matches, `(?s).*version: 2\.5\.0\n.*`)
+	c.Check(s.clock.waits, jc.DeepEquals, []time.Duration{time.Second, time.Second, time.Second})
+}
+
+func (s *MinimalStatusSuite) TestWatchCoalescesChanges(c *gc.C) {
+	changed := *s.statusapi.result
+	changed.Model.Version = "2.5.0"
+	s.statusapi.results = []*params.FullStatus{&changed}
+	s.clock.result = make(chan time.Time)
+	drained := make(chan struct{})
+	release := make(chan struct{})
+	watcher := &fakeAllWatcher{batches: 3, drained: drained, release: release}
+
+	statusCmd := status.NewTestStatusWatchCommand(s.statusapi, s.storageapi, s.clock, watcher)
+	done := make(chan struct{})
+	var ctx *cmd.Context
+	var err error
+	go func() {
+		defer close(done)
+		ctx, err = cmdtesting.RunCommand(c, statusCmd, "--watch", "--format", "yaml")
+	}()
+
+	// All three changes arrive before the status settles, so it is
+	// only fetched and drawn once.
+	select {
+	case <-drained:
+	case <-time.After(testing.LongWait):
+		c.Fatalf("timed out waiting for changes to be delivered")
+	}
+	select {
+	case s.clock.result <- time.Time{}:
+	case <-time.After(testing.LongWait):
+		c.Fatalf("timed out waiting for status to settle")
+	}
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(testing.LongWait):
+		c.Fatalf("timed out waiting for command to finish")
+	}
+	c.Assert(err, gc.ErrorMatches, "watching status: watcher stopped")
+
+	docs := strings.Split(cmdtesting.Stdout(ctx), "---\n")
+	c.Assert(docs, gc.HasLen, 2)
+	c.Check(docs[1], gc.Matches, `(?s).*version: 2\.5\.0\n.*`)
+	c.Check(s.clock.waits, jc.DeepEquals, []time.Duration{time.Second})
+}
+
+func (s *MinimalStatusSuite) writeSnapshot(c *gc.C) string {
+	ctx, err := s.runStatus(c, "--format", "yaml")
+	c.Assert(err, jc.ErrorIsNil)
+	path := filepath.Join(c.MkDir(), "before.yaml")
+	err = ioutil.WriteFile(path, []byte(cmdtesting.Stdout(ctx)), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	return path
+}
+
+func (s *MinimalStatusSuite) TestDiff(c *gc.C) {
+	s.statusapi.result.Machines = map[string]params.MachineStatus{
+		"0": {Id: "0", Series: "bionic"},
+	}
+	path := s.writeSnapshot(c)
+
+	s.statusapi.result.Machines = map[string]params.MachineStatus{
+		"0":  {Id: "0", Series: "xenial"},
+		"10": {Id: "10", Series: "bionic"},
+		"2":  {Id: "2", Series: "bionic"},
+	}
+	ctx, err := s.runStatus(c, "--diff", path, "--format", "yaml")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, `
+added:
+- machine 2
+- machine 10
+changed:
+  machine 0:
+    series:
+      was: bionic
+      now: xenial
+`[1:])
+
+	ctx, err = s.runStatus(c, "--diff", path)
+	c.Assert(err, jc.ErrorIsNil)
+	lines := strings.Split(cmdtesting.Stdout(ctx), "\n")
+	c.Assert(lines, gc.HasLen, 5)
+	c.Check(strings.Fields(lines[0]), jc.DeepEquals, []string{"Entity", "Change", "Field", "Was", "Now"})
+	c.Check(strings.Fields(lines[1]), jc.DeepEquals, []string{"machine", "2", "added"})
+	c.Check(strings.Fields(lines[2]), jc.DeepEquals, []string{"machine", "10", "added"})
+	c.Check(strings.Fields(lines[3]), jc.DeepEquals, []string{"machine", "0", "changed", "series", "bionic", "xenial"})
+}
+
+func (s *MinimalStatusSuite) TestDiffNoChanges(c *gc.C) {
+	path := s.writeSnapshot(c)
+	ctx, err := s.runStatus(c, "--diff", path)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(cmdtesting.Stdout(ctx), gc.Equals, "")
+	c.Check(cmdtesting.Stderr(ctx), gc.Equals, "No changes since "+path+".\n")
+}
+
+func (s *MinimalStatusSuite) TestDiffBadSnapshot(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "before.yaml")
+	err := ioutil.WriteFile(path, []byte("- not a status"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.runStatus(c, "--diff", path)
+	c.Assert(err, gc.ErrorMatches, `cannot parse status snapshot ".*before.yaml": .*`)
+}
+
 type fakeStatusAPI struct {
-	result *params.FullStatus
-	errors []error
+	result   *params.FullStatus
+	results  []*params.FullStatus
+	errors   []error
+	patterns []string
+	filters  []string
+
+	// fetched, if not nil, is sent a value each time the status is
+	// fetched.
+	fetched chan struct{}
 }
 
 func (f *fakeStatusAPI) Status(patterns []string) (*params.FullStatus, error) {
+	return f.FilteredStatus(patterns, nil)
+}
+
+func (f *fakeStatusAPI) FilteredStatus(patterns, filters []string) (*params.FullStatus, error) {
+	f.patterns = patterns
+	f.filters = filters
+	if f.fetched != nil {
+		f.fetched <- struct{}{}
+	}
 	if len(f.errors) > 0 {
 		err, rest := f.errors[0], f.errors[1:]
 		f.errors = rest
 		return nil, err
 	}
+	if len(f.results) > 0 {
+		result := f.results[0]
+		f.results = f.results[1:]
+		return result, nil
+	}
 	return f.result, nil
 }
 
@@ -148,6 +317,44 @@ func (*fakeStatusAPI) Close() error {
 	return nil
 }
 
+// fakeAllWatcher returns a number of batches of changes, and then
+// fails.
+type fakeAllWatcher struct {
+	batches int
+	stopped bool
+
+	// fetched, if not nil, is waited on before each batch but the
+	// first, and before failing.
+	fetched <-chan struct{}
+	started bool
+
+	// drained, if not nil, is closed once the batches have all been
+	// taken, and release is then waited on before failing.
+	drained chan<- struct{}
+	release <-chan struct{}
+}
+
+func (w *fakeAllWatcher) Next() ([]multiwatcher.Delta, error) {
+	if w.fetched != nil && w.started {
+		<-w.fetched
+	}
+	w.started = true
+	if w.batches == 0 {
+		if w.drained != nil {
+			close(w.drained)
+			<-w.release
+		}
+		return nil, errors.New("watcher stopped")
+	}
+	w.batches--
+	return []multiwatcher.Delta{{}}, nil
+}
+
+func (w *fakeAllWatcher) Stop() error {
+	w.stopped = true
+	return nil
+}
+
 type timeRecorder struct {
 	waits  []time.Duration
 	result chan time.Time
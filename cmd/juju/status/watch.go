@@ -0,0 +1,150 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package status
+
+import (
+	"bytes"
+	"os"
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api"
+	"github.com/juju/juju/state/multiwatcher"
+)
+
+// clearScreen moves the cursor to the top left of the terminal and
+// clears it, so that tabular status is redrawn in place.
+const clearScreen = "\x1b[H\x1b[2J"
+
+// watchSettlePeriod is how long the status is left to settle after
+// the model changes before it is fetched, so that a burst of changes
+// causes one fetch rather than one for each change.
+const watchSettlePeriod = time.Second
+
+// allWatcher is the part of api.AllWatcher used to follow changes to
+// the model.
+type allWatcher interface {
+	Next() ([]multiwatcher.Delta, error)
+	Stop() error
+}
+
+type watchAllAPI interface {
+	WatchAll() (*api.AllWatcher, error)
+}
+
+var newAllWatcherForStatus = func(c *statusCommand) (allWatcher, error) {
+	if c.watcher == nil {
+		apiclient, err := newAPIClientForStatus(c)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		watchAPI, ok := apiclient.(watchAllAPI)
+		if !ok {
+			return nil, errors.NotSupportedf("watching status")
+		}
+		w, err := watchAPI.WatchAll()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		c.watcher = w
+	}
+	return c.watcher, nil
+}
+
+// runWatch writes the status each time the model changes, until the
+// command is interrupted or the watcher fails. Changes arriving within
+// watchSettlePeriod of the first are coalesced into one fetch, and the
+// status is only written when its formatted output differs from the
+// last written.
+func (c *statusCommand) runWatch(ctx *cmd.Context) error {
+	formatter, ok := c.formatters[c.out.Name()]
+	if !ok {
+		return errors.NotValidf("output format %q", c.out.Name())
+	}
+	w, err := newAllWatcherForStatus(c)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	changes := make(chan struct{})
+	failed := make(chan error, 1)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			if _, err := w.Next(); err != nil {
+				failed <- err
+				return
+			}
+			select {
+			case changes <- struct{}{}:
+			case <-done:
+				return
+			}
+		}
+	}()
+	defer func() {
+		if err := w.Stop(); err != nil {
+			logger.Warningf("stopping status watcher failed %v", err)
+		}
+	}()
+
+	interrupted := make(chan os.Signal, 1)
+	ctx.InterruptNotify(interrupted)
+	defer ctx.StopInterruptNotify(interrupted)
+
+	showRelations, showStorage := c.showSections(ctx)
+	var last []byte
+	for {
+		select {
+		case <-interrupted:
+			return nil
+		case err := <-failed:
+			return errors.Annotate(err, "watching status")
+		case <-changes:
+		}
+		settled := c.clock.After(watchSettlePeriod)
+	settling:
+		for {
+			select {
+			case <-interrupted:
+				return nil
+			case err := <-failed:
+				return errors.Annotate(err, "watching status")
+			case <-changes:
+			case <-settled:
+				break settling
+			}
+		}
+
+		status, err := c.fetchStatus(ctx)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		formatted, err := c.formatStatus(ctx, status, showRelations, showStorage)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		var buf bytes.Buffer
+		if err := formatter(&buf, formatted); err != nil {
+			return errors.Trace(err)
+		}
+		if bytes.Equal(buf.Bytes(), last) {
+			continue
+		}
+		last = buf.Bytes()
+		var separator string
+		switch c.out.Name() {
+		case "tabular":
+			separator = clearScreen
+		case "yaml":
+			separator = "---\n"
+		}
+		if _, err := ctx.Stdout.Write(append([]byte(separator), last...)); err != nil {
+			return errors.Trace(err)
+		}
+	}
+}
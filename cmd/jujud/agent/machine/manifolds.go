@@ -41,6 +41,7 @@ import (
 	"github.com/juju/juju/worker/apiservercertwatcher"
 	"github.com/juju/juju/worker/auditconfigupdater"
 	"github.com/juju/juju/worker/authenticationworker"
+	"github.com/juju/juju/worker/backupscheduler"
 	"github.com/juju/juju/worker/centralhub"
 	"github.com/juju/juju/worker/certupdater"
 	"github.com/juju/juju/worker/common"
@@ -65,6 +66,7 @@ import (
 	"github.com/juju/juju/worker/machiner"
 	"github.com/juju/juju/worker/migrationflag"
 	"github.com/juju/juju/worker/migrationminion"
+	"github.com/juju/juju/worker/modelcache"
 	"github.com/juju/juju/worker/modelworkermanager"
 	"github.com/juju/juju/worker/peergrouper"
 	prworker "github.com/juju/juju/worker/presence"
@@ -703,6 +705,29 @@ func Manifolds(config ManifoldsConfig) dependency.Manifolds {
 			},
 		))),
 
+		backupSchedulerName: ifNotMigrating(ifPrimaryController(backupscheduler.Manifold(
+			backupscheduler.ManifoldConfig{
+				AgentName:  agentName,
+				ClockName:  clockName,
+				StateName:  stateName,
+				NewBackups: backupscheduler.NewBackups,
+				NewWorker:  backupscheduler.NewWorker,
+			},
+		))),
+
+		// The backup copier runs on every controller machine, to
+		// keep the copies of the scheduled backups on each in step
+		// with those stored, wherever the scheduler is running.
+		backupCopierName: ifNotMigrating(ifController(backupscheduler.Manifold(
+			backupscheduler.ManifoldConfig{
+				AgentName:  agentName,
+				ClockName:  clockName,
+				StateName:  stateName,
+				NewBackups: backupscheduler.NewBackups,
+				NewWorker:  backupscheduler.NewCopier,
+			},
+		))),
+
 		txnPrunerName: ifNotMigrating(ifPrimaryController(txnpruner.Manifold(
 			txnpruner.ManifoldConfig{
 				ClockName:     clockName,
@@ -758,6 +783,7 @@ func Manifolds(config ManifoldsConfig) dependency.Manifolds {
 			UpgradeGateName:                   upgradeStepsGateName,
 			RestoreStatusName:                 restoreWatcherName,
 			AuditConfigUpdaterName:            auditConfigUpdaterName,
+			ModelCacheName:                    modelCacheName,
 			PrometheusRegisterer:              config.PrometheusRegisterer,
 			RegisterIntrospectionHTTPHandlers: config.RegisterIntrospectionHTTPHandlers,
 			Hub:                               config.CentralHub,
@@ -796,9 +822,17 @@ func Manifolds(config ManifoldsConfig) dependency.Manifolds {
 		auditConfigUpdaterName: ifController(auditconfigupdater.Manifold(auditconfigupdater.ManifoldConfig{
 			AgentName: agentName,
 			StateName: stateName,
+			Clock:     config.Clock,
 			NewWorker: auditconfigupdater.New,
 		})),
 
+		modelCacheName: ifController(modelcache.Manifold(modelcache.ManifoldConfig{
+			StateName:            stateName,
+			ClockName:            clockName,
+			PrometheusRegisterer: config.PrometheusRegisterer,
+			NewWorker:            modelcache.NewWorker,
+		})),
+
 		raftTransportName: ifController(rafttransport.Manifold(rafttransport.ManifoldConfig{
 			ClockName:         clockName,
 			AgentName:         agentName,
@@ -983,6 +1017,8 @@ const (
 	isPrimaryControllerFlagName   = "is-primary-controller-flag"
 	isControllerFlagName          = "is-controller-flag"
 	logPrunerName                 = "log-pruner"
+	backupSchedulerName           = "backup-scheduler"
+	backupCopierName              = "backup-copier"
 	txnPrunerName                 = "transaction-pruner"
 	certificateWatcherName        = "certificate-watcher"
 	modelWorkerManagerName        = "model-worker-manager"
@@ -991,6 +1027,7 @@ const (
 	certificateUpdaterName        = "certificate-updater"
 	auditConfigUpdaterName        = "audit-config-updater"
 	leaseManagerName              = "lease-manager"
+	modelCacheName                = "model-cache"
 
 	upgradeSeriesEnabledName = "upgrade-series-enabled"
 	upgradeSeriesWorkerName  = "upgrade-series"
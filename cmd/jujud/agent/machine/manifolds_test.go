@@ -54,6 +54,8 @@ func (*ManifoldsSuite) TestManifoldNames(c *gc.C) {
 		"api-config-watcher",
 		"api-server",
 		"audit-config-updater",
+		"backup-copier",
+		"backup-scheduler",
 		"central-hub",
 		"certificate-updater",
 		"certificate-watcher",
@@ -79,6 +81,7 @@ func (*ManifoldsSuite) TestManifoldNames(c *gc.C) {
 		"migration-fortress",
 		"migration-minion",
 		"migration-inactive-flag",
+		"model-cache",
 		"model-worker-manager",
 		"peer-grouper",
 		"presence",
@@ -146,6 +149,7 @@ func (*ManifoldsSuite) TestMigrationGuardsUsed(c *gc.C) {
 		"lease-clock-updater",
 		"lease-manager",
 		"log-forwarder",
+		"model-cache",
 		"model-worker-manager",
 		"peer-grouper",
 		"presence",
@@ -190,13 +194,16 @@ func (*ManifoldsSuite) TestSingularGuardsUsed(c *gc.C) {
 		Agent: &mockAgent{},
 	})
 	controllerWorkers := set.NewStrings(
+		"backup-copier",
 		"certificate-watcher",
 		"audit-config-updater",
 		"is-primary-controller-flag",
 		"lease-manager",
+		"model-cache",
 		"raft-transport",
 	)
 	primaryControllerWorkers := set.NewStrings(
+		"backup-scheduler",
 		"external-controller-updater",
 		"log-pruner",
 		"transaction-pruner",
@@ -308,6 +315,7 @@ var expectedMachineManifoldsWithDependencies = map[string][]string{
 		"http-server-args",
 		"is-controller-flag",
 		"lease-manager",
+		"model-cache",
 		"restore-watcher",
 		"state",
 		"state-config-watcher",
@@ -319,6 +327,37 @@ var expectedMachineManifoldsWithDependencies = map[string][]string{
 		"state",
 		"state-config-watcher"},
 
+	"backup-copier": {
+		"agent",
+		"api-caller",
+		"api-config-watcher",
+		"clock",
+		"is-controller-flag",
+		"migration-fortress",
+		"migration-inactive-flag",
+		"state",
+		"state-config-watcher",
+		"upgrade-check-flag",
+		"upgrade-check-gate",
+		"upgrade-steps-flag",
+		"upgrade-steps-gate"},
+
+	"backup-scheduler": {
+		"agent",
+		"api-caller",
+		"api-config-watcher",
+		"clock",
+		"is-controller-flag",
+		"is-primary-controller-flag",
+		"migration-fortress",
+		"migration-inactive-flag",
+		"state",
+		"state-config-watcher",
+		"upgrade-check-flag",
+		"upgrade-check-gate",
+		"upgrade-steps-flag",
+		"upgrade-steps-gate"},
+
 	"central-hub": {"agent", "state-config-watcher"},
 
 	"certificate-updater": {
@@ -410,6 +449,7 @@ var expectedMachineManifoldsWithDependencies = map[string][]string{
 		"http-server-args",
 		"is-controller-flag",
 		"lease-manager",
+		"model-cache",
 		"raft-transport",
 		"restore-watcher",
 		"state",
@@ -558,6 +598,13 @@ var expectedMachineManifoldsWithDependencies = map[string][]string{
 		"upgrade-steps-flag",
 		"upgrade-steps-gate"},
 
+	"model-cache": {
+		"agent",
+		"clock",
+		"is-controller-flag",
+		"state",
+		"state-config-watcher"},
+
 	"model-worker-manager": {
 		"agent",
 		"state",
@@ -6,6 +6,7 @@ package controller
 import (
 	"fmt"
 	"net/url"
+	"path/filepath"
 	"regexp"
 	"time"
 
@@ -20,6 +21,7 @@ import (
 	"gopkg.in/macaroon-bakery.v2-unstable/bakery"
 
 	"github.com/juju/juju/cert"
+	"github.com/juju/juju/core/actions"
 	"github.com/juju/juju/core/resources"
 )
 
@@ -30,6 +32,16 @@ const (
 	MongoProfDefault = "default"
 )
 
+const (
+	// AuditLogSinkFile writes audit records to the controller's
+	// audit.log file.
+	AuditLogSinkFile = "file"
+	// AuditLogSinkSyslog forwards audit records to a syslog server.
+	AuditLogSinkSyslog = "syslog"
+	// AuditLogSinkWebhook posts audit records to an HTTP endpoint.
+	AuditLogSinkWebhook = "webhook"
+)
+
 const (
 	// APIPort is the port used for api connections.
 	APIPort = "api-port"
@@ -71,6 +83,39 @@ const (
 	// interesting calls though.)
 	AuditLogExcludeMethods = "audit-log-exclude-methods"
 
+	// AuditLogSink selects where audit records are written: "file"
+	// (the rotating audit.log in the controller's log directory),
+	// "syslog" or "webhook".
+	AuditLogSink = "audit-log-sink"
+
+	// AuditLogSyslogHost is the host-port of the syslog server audit
+	// records are forwarded to when audit-log-sink is "syslog".
+	AuditLogSyslogHost = "audit-log-syslog-host"
+
+	// AuditLogSyslogCACert is the CA certificate (x.509, PEM-encoded)
+	// used to validate the audit syslog server's certificate.
+	AuditLogSyslogCACert = "audit-log-syslog-ca-cert"
+
+	// AuditLogSyslogClientCert is the client certificate (x.509,
+	// PEM-encoded) used when connecting to the audit syslog server.
+	AuditLogSyslogClientCert = "audit-log-syslog-client-cert"
+
+	// AuditLogSyslogClientKey is the client key (PEM-encoded) used
+	// when connecting to the audit syslog server.
+	AuditLogSyslogClientKey = "audit-log-syslog-client-key"
+
+	// AuditLogWebhookURL is the HTTP(S) endpoint batches of audit
+	// records are posted to when audit-log-sink is "webhook".
+	AuditLogWebhookURL = "audit-log-webhook-url"
+
+	// AuditLogWebhookBatchSize is the number of audit records posted
+	// to the webhook in one request.
+	AuditLogWebhookBatchSize = "audit-log-webhook-batch-size"
+
+	// AuditLogWebhookFlushInterval is the longest time audit records
+	// are held before being posted to the webhook, eg "5s".
+	AuditLogWebhookFlushInterval = "audit-log-webhook-flush-interval"
+
 	// ReadOnlyMethodsWildcard is the special value that can be added
 	// to the exclude-methods list that represents all of the read
 	// only methods (see apiserver/observer/auditfilter.go). This
@@ -142,6 +187,24 @@ const (
 	// default value of 1M BatchSize and 100 passes will be used instead.
 	MaxPruneTxnPasses = "max-prune-txn-passes"
 
+	// BackupSchedule is the cron-style schedule on which the
+	// controller backs itself up, eg "@daily". Scheduled backups are
+	// disabled if it's empty.
+	BackupSchedule = "backup-schedule"
+
+	// BackupRetentionCount is the number of scheduled backups kept;
+	// older ones are removed. A value of 0 keeps them all.
+	BackupRetentionCount = "backup-retention-count"
+
+	// BackupRetentionAge is the age at which scheduled backups are
+	// removed, eg "720h". A value of 0 keeps them regardless of age.
+	BackupRetentionAge = "backup-retention-age"
+
+	// BackupCopyDir is a directory on each controller machine that
+	// scheduled backup archives are copied to, in addition to being
+	// stored in the controller.
+	BackupCopyDir = "backup-copy-dir"
+
 	// Attribute Defaults
 
 	// DefaultAuditingEnabled contains the default value for the
@@ -160,6 +223,18 @@ const (
 	// keep.
 	DefaultAuditLogMaxBackups = 10
 
+	// DefaultAuditLogSink is the default destination for audit
+	// records.
+	DefaultAuditLogSink = AuditLogSinkFile
+
+	// DefaultAuditLogWebhookBatchSize is the default number of audit
+	// records posted to the webhook in one request.
+	DefaultAuditLogWebhookBatchSize = 100
+
+	// DefaultAuditLogWebhookFlushInterval is the default value for
+	// audit-log-webhook-flush-interval.
+	DefaultAuditLogWebhookFlushInterval = "5s"
+
 	// DefaultNUMAControlPolicy should not be used by default.
 	// Only use numactl if user specifically requests it
 	DefaultNUMAControlPolicy = false
@@ -242,9 +317,21 @@ var (
 		AuditLogMaxSize,
 		AuditLogMaxBackups,
 		AuditLogExcludeMethods,
+		AuditLogSink,
+		AuditLogSyslogHost,
+		AuditLogSyslogCACert,
+		AuditLogSyslogClientCert,
+		AuditLogSyslogClientKey,
+		AuditLogWebhookURL,
+		AuditLogWebhookBatchSize,
+		AuditLogWebhookFlushInterval,
 		CAASOperatorImagePath,
 		Features,
 		MeteringURL,
+		BackupSchedule,
+		BackupRetentionCount,
+		BackupRetentionAge,
+		BackupCopyDir,
 	}
 
 	// AllowedUpdateConfigAttributes contains all of the controller
@@ -255,6 +342,14 @@ var (
 		AuditingEnabled,
 		AuditLogCaptureArgs,
 		AuditLogExcludeMethods,
+		AuditLogSink,
+		AuditLogSyslogHost,
+		AuditLogSyslogCACert,
+		AuditLogSyslogClientCert,
+		AuditLogSyslogClientKey,
+		AuditLogWebhookURL,
+		AuditLogWebhookBatchSize,
+		AuditLogWebhookFlushInterval,
 		// TODO Juju 3.0: ControllerAPIPort should be required and treated
 		// more like api-port.
 		ControllerAPIPort,
@@ -266,6 +361,10 @@ var (
 		JujuManagementSpace,
 		CAASOperatorImagePath,
 		Features,
+		BackupSchedule,
+		BackupRetentionCount,
+		BackupRetentionAge,
+		BackupCopyDir,
 	)
 
 	// DefaultAuditLogExcludeMethods is the default list of methods to
@@ -437,6 +536,91 @@ func (c Config) AuditLogExcludeMethods() set.Strings {
 	return set.NewStrings(DefaultAuditLogExcludeMethods...)
 }
 
+// AuditLogSink returns where audit records should be written: one of
+// "file", "syslog" or "webhook".
+func (c Config) AuditLogSink() string {
+	if v := c.asString(AuditLogSink); v != "" {
+		return v
+	}
+	return DefaultAuditLogSink
+}
+
+// AuditLogSyslogHost returns the host-port of the syslog server that
+// audit records are forwarded to.
+func (c Config) AuditLogSyslogHost() string {
+	return c.asString(AuditLogSyslogHost)
+}
+
+// AuditLogSyslogCACert returns the CA certificate used to validate
+// the audit syslog server.
+func (c Config) AuditLogSyslogCACert() string {
+	return c.asString(AuditLogSyslogCACert)
+}
+
+// AuditLogSyslogClientCert returns the client certificate used when
+// connecting to the audit syslog server.
+func (c Config) AuditLogSyslogClientCert() string {
+	return c.asString(AuditLogSyslogClientCert)
+}
+
+// AuditLogSyslogClientKey returns the client key used when connecting
+// to the audit syslog server.
+func (c Config) AuditLogSyslogClientKey() string {
+	return c.asString(AuditLogSyslogClientKey)
+}
+
+// AuditLogWebhookURL returns the endpoint audit records are posted
+// to when using the webhook sink.
+func (c Config) AuditLogWebhookURL() string {
+	return c.asString(AuditLogWebhookURL)
+}
+
+// AuditLogWebhookBatchSize returns the number of audit records posted
+// to the webhook in one request.
+func (c Config) AuditLogWebhookBatchSize() int {
+	return c.intOrDefault(AuditLogWebhookBatchSize, DefaultAuditLogWebhookBatchSize)
+}
+
+// AuditLogWebhookFlushInterval returns the longest time audit records
+// are held before being posted to the webhook.
+func (c Config) AuditLogWebhookFlushInterval() time.Duration {
+	v := c.asString(AuditLogWebhookFlushInterval)
+	if v == "" {
+		v = DefaultAuditLogWebhookFlushInterval
+	}
+	// We know that v must be a parseable time.Duration for the config
+	// to be valid.
+	d, _ := time.ParseDuration(v)
+	return d
+}
+
+// BackupSchedule returns the schedule on which the controller is
+// backed up, or "" if scheduled backups are disabled.
+func (c Config) BackupSchedule() string {
+	return c.asString(BackupSchedule)
+}
+
+// BackupRetentionCount returns the number of scheduled backups kept,
+// or 0 to keep them all.
+func (c Config) BackupRetentionCount() int {
+	return c.intOrDefault(BackupRetentionCount, 0)
+}
+
+// BackupRetentionAge returns the age at which scheduled backups are
+// removed, or 0 to keep them regardless of age.
+func (c Config) BackupRetentionAge() time.Duration {
+	// We know that the value must be a parseable time.Duration for
+	// the config to be valid.
+	d, _ := time.ParseDuration(c.asString(BackupRetentionAge))
+	return d
+}
+
+// BackupCopyDir returns the directory scheduled backups are copied to,
+// or "" if they aren't copied.
+func (c Config) BackupCopyDir() string {
+	return c.asString(BackupCopyDir)
+}
+
 // Features returns the controller config set features flags.
 func (c Config) Features() set.Strings {
 	features := set.NewStrings()
@@ -699,6 +883,14 @@ func Validate(c Config) error {
 		}
 	}
 
+	if err := c.validateAuditLogSink(); err != nil {
+		return errors.Trace(err)
+	}
+
+	if err := c.validateBackupSchedule(); err != nil {
+		return errors.Trace(err)
+	}
+
 	if v, ok := c[ControllerAPIPort].(int); ok {
 		// TODO: change the validation so 0 is invalide and --reset is used.
 		// However that doesn't exist yet.
@@ -722,6 +914,81 @@ func Validate(c Config) error {
 	return nil
 }
 
+func (c Config) validateAuditLogSink() error {
+	if v, ok := c[AuditLogWebhookBatchSize].(int); ok && v <= 0 {
+		return errors.Errorf("invalid audit log webhook batch size: should be a positive number of records, got %d", v)
+	}
+	if v, ok := c[AuditLogWebhookFlushInterval].(string); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return errors.Errorf("%s value %q must be a valid duration", AuditLogWebhookFlushInterval, v)
+		}
+		if d < 0 {
+			return errors.Errorf("%s value %q must not be negative", AuditLogWebhookFlushInterval, v)
+		}
+	}
+	if v, ok := c[AuditLogWebhookURL].(string); ok && v != "" {
+		u, err := url.Parse(v)
+		if err != nil {
+			return errors.Annotate(err, "invalid audit log webhook URL")
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return errors.Errorf("invalid audit log webhook URL %q: expected http or https", v)
+		}
+	}
+
+	sink, ok := c[AuditLogSink].(string)
+	if !ok {
+		return nil
+	}
+	switch sink {
+	case AuditLogSinkFile:
+	case AuditLogSinkSyslog:
+		for _, key := range []string{
+			AuditLogSyslogHost,
+			AuditLogSyslogCACert,
+			AuditLogSyslogClientCert,
+			AuditLogSyslogClientKey,
+		} {
+			if c.asString(key) == "" {
+				return errors.Errorf("%s must be set when %s is %q", key, AuditLogSink, sink)
+			}
+		}
+	case AuditLogSinkWebhook:
+		if c.asString(AuditLogWebhookURL) == "" {
+			return errors.Errorf("%s must be set when %s is %q", AuditLogWebhookURL, AuditLogSink, sink)
+		}
+	default:
+		return errors.Errorf("invalid audit log sink %q: expected one of %q, %q or %q",
+			sink, AuditLogSinkFile, AuditLogSinkSyslog, AuditLogSinkWebhook)
+	}
+	return nil
+}
+
+func (c Config) validateBackupSchedule() error {
+	if v, ok := c[BackupSchedule].(string); ok && v != "" {
+		if _, err := actions.ParseSchedule(v); err != nil {
+			return errors.Annotate(err, "invalid backup schedule")
+		}
+	}
+	if v, ok := c[BackupRetentionCount].(int); ok && v < 0 {
+		return errors.Errorf("invalid backup retention count: should be a number of backups (or 0 to keep all), got %d", v)
+	}
+	if v, ok := c[BackupRetentionAge].(string); ok && v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return errors.Errorf("%s value %q must be a valid duration", BackupRetentionAge, v)
+		}
+		if d < 0 {
+			return errors.Errorf("%s value %q must not be negative", BackupRetentionAge, v)
+		}
+	}
+	if v, ok := c[BackupCopyDir].(string); ok && v != "" && !filepath.IsAbs(v) {
+		return errors.Errorf("%s value %q must be an absolute path", BackupCopyDir, v)
+	}
+	return nil
+}
+
 func (c Config) validateSpaceConfig(key, topic string) error {
 	val := c[key]
 	if val == nil {
@@ -774,59 +1041,83 @@ func GenerateControllerCertAndKey(caCert, caKey string, hostAddresses []string)
 }
 
 var configChecker = schema.FieldMap(schema.Fields{
-	AuditingEnabled:         schema.Bool(),
-	AuditLogCaptureArgs:     schema.Bool(),
-	AuditLogMaxSize:         schema.String(),
-	AuditLogMaxBackups:      schema.ForceInt(),
-	AuditLogExcludeMethods:  schema.List(schema.String()),
-	APIPort:                 schema.ForceInt(),
-	APIPortOpenDelay:        schema.String(),
-	ControllerAPIPort:       schema.ForceInt(),
-	StatePort:               schema.ForceInt(),
-	IdentityURL:             schema.String(),
-	IdentityPublicKey:       schema.String(),
-	SetNUMAControlPolicyKey: schema.Bool(),
-	AutocertURLKey:          schema.String(),
-	AutocertDNSNameKey:      schema.String(),
-	AllowModelAccessKey:     schema.Bool(),
-	MongoMemoryProfile:      schema.String(),
-	MaxLogsAge:              schema.String(),
-	MaxLogsSize:             schema.String(),
-	MaxTxnLogSize:           schema.String(),
-	MaxPruneTxnBatchSize:    schema.ForceInt(),
-	MaxPruneTxnPasses:       schema.ForceInt(),
-	JujuHASpace:             schema.String(),
-	JujuManagementSpace:     schema.String(),
-	CAASOperatorImagePath:   schema.String(),
-	Features:                schema.List(schema.String()),
-	CharmStoreURL:           schema.String(),
-	MeteringURL:             schema.String(),
+	AuditingEnabled:              schema.Bool(),
+	AuditLogCaptureArgs:          schema.Bool(),
+	AuditLogMaxSize:              schema.String(),
+	AuditLogMaxBackups:           schema.ForceInt(),
+	AuditLogExcludeMethods:       schema.List(schema.String()),
+	AuditLogSink:                 schema.String(),
+	AuditLogSyslogHost:           schema.String(),
+	AuditLogSyslogCACert:         schema.String(),
+	AuditLogSyslogClientCert:     schema.String(),
+	AuditLogSyslogClientKey:      schema.String(),
+	AuditLogWebhookURL:           schema.String(),
+	AuditLogWebhookBatchSize:     schema.ForceInt(),
+	AuditLogWebhookFlushInterval: schema.String(),
+	APIPort:                      schema.ForceInt(),
+	APIPortOpenDelay:             schema.String(),
+	ControllerAPIPort:            schema.ForceInt(),
+	StatePort:                    schema.ForceInt(),
+	IdentityURL:                  schema.String(),
+	IdentityPublicKey:            schema.String(),
+	SetNUMAControlPolicyKey:      schema.Bool(),
+	AutocertURLKey:               schema.String(),
+	AutocertDNSNameKey:           schema.String(),
+	AllowModelAccessKey:          schema.Bool(),
+	MongoMemoryProfile:           schema.String(),
+	MaxLogsAge:                   schema.String(),
+	MaxLogsSize:                  schema.String(),
+	MaxTxnLogSize:                schema.String(),
+	MaxPruneTxnBatchSize:         schema.ForceInt(),
+	MaxPruneTxnPasses:            schema.ForceInt(),
+	JujuHASpace:                  schema.String(),
+	JujuManagementSpace:          schema.String(),
+	CAASOperatorImagePath:        schema.String(),
+	Features:                     schema.List(schema.String()),
+	CharmStoreURL:                schema.String(),
+	MeteringURL:                  schema.String(),
+	BackupSchedule:               schema.String(),
+	BackupRetentionCount:         schema.ForceInt(),
+	BackupRetentionAge:           schema.String(),
+	BackupCopyDir:                schema.String(),
 }, schema.Defaults{
-	APIPort:                 DefaultAPIPort,
-	APIPortOpenDelay:        DefaultAPIPortOpenDelay,
-	ControllerAPIPort:       schema.Omit,
-	AuditingEnabled:         DefaultAuditingEnabled,
-	AuditLogCaptureArgs:     DefaultAuditLogCaptureArgs,
-	AuditLogMaxSize:         fmt.Sprintf("%vM", DefaultAuditLogMaxSizeMB),
-	AuditLogMaxBackups:      DefaultAuditLogMaxBackups,
-	AuditLogExcludeMethods:  DefaultAuditLogExcludeMethods,
-	StatePort:               DefaultStatePort,
-	IdentityURL:             schema.Omit,
-	IdentityPublicKey:       schema.Omit,
-	SetNUMAControlPolicyKey: DefaultNUMAControlPolicy,
-	AutocertURLKey:          schema.Omit,
-	AutocertDNSNameKey:      schema.Omit,
-	AllowModelAccessKey:     schema.Omit,
-	MongoMemoryProfile:      schema.Omit,
-	MaxLogsAge:              fmt.Sprintf("%vh", DefaultMaxLogsAgeDays*24),
-	MaxLogsSize:             fmt.Sprintf("%vM", DefaultMaxLogCollectionMB),
-	MaxTxnLogSize:           fmt.Sprintf("%vM", DefaultMaxTxnLogCollectionMB),
-	MaxPruneTxnBatchSize:    DefaultMaxPruneTxnBatchSize,
-	MaxPruneTxnPasses:       DefaultMaxPruneTxnPasses,
-	JujuHASpace:             schema.Omit,
-	JujuManagementSpace:     schema.Omit,
-	CAASOperatorImagePath:   schema.Omit,
-	Features:                schema.Omit,
-	CharmStoreURL:           csclient.ServerURL,
-	MeteringURL:             romulus.DefaultAPIRoot,
+	APIPort:                      DefaultAPIPort,
+	APIPortOpenDelay:             DefaultAPIPortOpenDelay,
+	ControllerAPIPort:            schema.Omit,
+	AuditingEnabled:              DefaultAuditingEnabled,
+	AuditLogCaptureArgs:          DefaultAuditLogCaptureArgs,
+	AuditLogMaxSize:              fmt.Sprintf("%vM", DefaultAuditLogMaxSizeMB),
+	AuditLogMaxBackups:           DefaultAuditLogMaxBackups,
+	AuditLogExcludeMethods:       DefaultAuditLogExcludeMethods,
+	AuditLogSink:                 DefaultAuditLogSink,
+	AuditLogSyslogHost:           schema.Omit,
+	AuditLogSyslogCACert:         schema.Omit,
+	AuditLogSyslogClientCert:     schema.Omit,
+	AuditLogSyslogClientKey:      schema.Omit,
+	AuditLogWebhookURL:           schema.Omit,
+	AuditLogWebhookBatchSize:     DefaultAuditLogWebhookBatchSize,
+	AuditLogWebhookFlushInterval: DefaultAuditLogWebhookFlushInterval,
+	StatePort:                    DefaultStatePort,
+	IdentityURL:                  schema.Omit,
+	IdentityPublicKey:            schema.Omit,
+	SetNUMAControlPolicyKey:      DefaultNUMAControlPolicy,
+	AutocertURLKey:               schema.Omit,
+	AutocertDNSNameKey:           schema.Omit,
+	AllowModelAccessKey:          schema.Omit,
+	MongoMemoryProfile:           schema.Omit,
+	MaxLogsAge:                   fmt.Sprintf("%vh", DefaultMaxLogsAgeDays*24),
+	MaxLogsSize:                  fmt.Sprintf("%vM", DefaultMaxLogCollectionMB),
+	MaxTxnLogSize:                fmt.Sprintf("%vM", DefaultMaxTxnLogCollectionMB),
+	MaxPruneTxnBatchSize:         DefaultMaxPruneTxnBatchSize,
+	MaxPruneTxnPasses:            DefaultMaxPruneTxnPasses,
+	JujuHASpace:                  schema.Omit,
+	JujuManagementSpace:          schema.Omit,
+	CAASOperatorImagePath:        schema.Omit,
+	Features:                     schema.Omit,
+	CharmStoreURL:                csclient.ServerURL,
+	MeteringURL:                  romulus.DefaultAPIRoot,
+	BackupSchedule:               schema.Omit,
+	BackupRetentionCount:         schema.Omit,
+	BackupRetentionAge:           schema.Omit,
+	BackupCopyDir:                schema.Omit,
 })
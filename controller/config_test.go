@@ -261,6 +261,86 @@ var validateTests = []struct {
 		controller.APIPortOpenDelay: "15",
 	},
 	expectError: `api-port-open-delay value "15" must be a valid duration`,
+}, {
+	about: "invalid audit log sink",
+	config: controller.Config{
+		controller.CACertKey:    testing.CACert,
+		controller.AuditLogSink: "carrier-pigeon",
+	},
+	expectError: `invalid audit log sink "carrier-pigeon": expected one of "file", "syslog" or "webhook"`,
+}, {
+	about: "syslog audit log sink without host",
+	config: controller.Config{
+		controller.CACertKey:    testing.CACert,
+		controller.AuditLogSink: "syslog",
+	},
+	expectError: `audit-log-syslog-host must be set when audit-log-sink is "syslog"`,
+}, {
+	about: "syslog audit log sink without client key",
+	config: controller.Config{
+		controller.CACertKey:                testing.CACert,
+		controller.AuditLogSink:             "syslog",
+		controller.AuditLogSyslogHost:       "10.0.0.1:6514",
+		controller.AuditLogSyslogCACert:     testing.CACert,
+		controller.AuditLogSyslogClientCert: testing.ServerCert,
+	},
+	expectError: `audit-log-syslog-client-key must be set when audit-log-sink is "syslog"`,
+}, {
+	about: "webhook audit log sink without URL",
+	config: controller.Config{
+		controller.CACertKey:    testing.CACert,
+		controller.AuditLogSink: "webhook",
+	},
+	expectError: `audit-log-webhook-url must be set when audit-log-sink is "webhook"`,
+}, {
+	about: "invalid audit log webhook URL scheme",
+	config: controller.Config{
+		controller.CACertKey:          testing.CACert,
+		controller.AuditLogWebhookURL: "ftp://audit.example.com",
+	},
+	expectError: `invalid audit log webhook URL "ftp://audit.example.com": expected http or https`,
+}, {
+	about: "invalid audit log webhook batch size",
+	config: controller.Config{
+		controller.CACertKey:                testing.CACert,
+		controller.AuditLogWebhookBatchSize: 0,
+	},
+	expectError: `invalid audit log webhook batch size: should be a positive number of records, got 0`,
+}, {
+	about: "audit log webhook flush interval not a duration",
+	config: controller.Config{
+		controller.CACertKey:                    testing.CACert,
+		controller.AuditLogWebhookFlushInterval: "often",
+	},
+	expectError: `audit-log-webhook-flush-interval value "often" must be a valid duration`,
+}, {
+	about: "invalid backup schedule",
+	config: controller.Config{
+		controller.CACertKey:      testing.CACert,
+		controller.BackupSchedule: "every day",
+	},
+	expectError: `invalid backup schedule: invalid schedule "every day": .*`,
+}, {
+	about: "negative backup retention count",
+	config: controller.Config{
+		controller.CACertKey:            testing.CACert,
+		controller.BackupRetentionCount: -1,
+	},
+	expectError: `invalid backup retention count: should be a number of backups \(or 0 to keep all\), got -1`,
+}, {
+	about: "backup retention age not a duration",
+	config: controller.Config{
+		controller.CACertKey:          testing.CACert,
+		controller.BackupRetentionAge: "a month",
+	},
+	expectError: `backup-retention-age value "a month" must be a valid duration`,
+}, {
+	about: "relative backup copy dir",
+	config: controller.Config{
+		controller.CACertKey:     testing.CACert,
+		controller.BackupCopyDir: "backups",
+	},
+	expectError: `backup-copy-dir value "backups" must be an absolute path`,
 }}
 
 func (s *ConfigSuite) TestValidate(c *gc.C) {
@@ -418,6 +498,62 @@ func (s *ConfigSuite) TestAuditLogExcludeMethodsType(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, `audit-log-exclude-methods\[0\]: expected string, got int\(2\)`)
 }
 
+func (s *ConfigSuite) TestAuditLogSinkDefaults(c *gc.C) {
+	cfg, err := controller.NewConfig(testing.ControllerTag.Id(), testing.CACert, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cfg.AuditLogSink(), gc.Equals, "file")
+	c.Assert(cfg.AuditLogWebhookURL(), gc.Equals, "")
+	c.Assert(cfg.AuditLogWebhookBatchSize(), gc.Equals, 100)
+	c.Assert(cfg.AuditLogWebhookFlushInterval(), gc.Equals, 5*time.Second)
+}
+
+func (s *ConfigSuite) TestBackupScheduleDefaults(c *gc.C) {
+	cfg, err := controller.NewConfig(testing.ControllerTag.Id(), testing.CACert, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cfg.BackupSchedule(), gc.Equals, "")
+	c.Assert(cfg.BackupRetentionCount(), gc.Equals, 0)
+	c.Assert(cfg.BackupRetentionAge(), gc.Equals, time.Duration(0))
+	c.Assert(cfg.BackupCopyDir(), gc.Equals, "")
+}
+
+func (s *ConfigSuite) TestBackupScheduleValues(c *gc.C) {
+	cfg, err := controller.NewConfig(
+		testing.ControllerTag.Id(),
+		testing.CACert,
+		map[string]interface{}{
+			"backup-schedule":        "30 2 * * *",
+			"backup-retention-count": 7.0,
+			"backup-retention-age":   "720h",
+			"backup-copy-dir":        "/srv/juju-backups",
+		},
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cfg.BackupSchedule(), gc.Equals, "30 2 * * *")
+	c.Assert(cfg.BackupRetentionCount(), gc.Equals, 7)
+	c.Assert(cfg.BackupRetentionAge(), gc.Equals, 720*time.Hour)
+	c.Assert(cfg.BackupCopyDir(), gc.Equals, "/srv/juju-backups")
+}
+
+func (s *ConfigSuite) TestAuditLogSinkValues(c *gc.C) {
+	cfg, err := controller.NewConfig(
+		testing.ControllerTag.Id(),
+		testing.CACert,
+		map[string]interface{}{
+			"audit-log-sink":                   "webhook",
+			"audit-log-webhook-url":            "https://audit.example.com/records",
+			"audit-log-webhook-batch-size":     20.0,
+			"audit-log-webhook-flush-interval": "30s",
+			"audit-log-syslog-host":            "10.0.0.1:6514",
+		},
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cfg.AuditLogSink(), gc.Equals, "webhook")
+	c.Assert(cfg.AuditLogWebhookURL(), gc.Equals, "https://audit.example.com/records")
+	c.Assert(cfg.AuditLogWebhookBatchSize(), gc.Equals, 20)
+	c.Assert(cfg.AuditLogWebhookFlushInterval(), gc.Equals, 30*time.Second)
+	c.Assert(cfg.AuditLogSyslogHost(), gc.Equals, "10.0.0.1:6514")
+}
+
 func (s *ConfigSuite) TestAuditLogFloatBackupsLoadedDirectly(c *gc.C) {
 	// We still need to be able to handle floats in data loaded from the DB.
 	cfg := controller.Config{
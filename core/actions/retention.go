@@ -0,0 +1,123 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package actions
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"gopkg.in/juju/names.v2"
+)
+
+// RetentionRule overrides the model's maximum action result age for
+// the results of the actions it matches.
+type RetentionRule struct {
+	// Application, if set, restricts the rule to actions run on the
+	// units of the application.
+	Application string
+
+	// Action, if set, restricts the rule to actions with the name.
+	Action string
+
+	// MaxAge is the maximum age of the matching action results. Zero
+	// means that they are never pruned by age.
+	MaxAge time.Duration
+}
+
+// Specificity orders overlapping rules: a rule for an action on an
+// application overrides a rule for the action, which overrides a rule
+// for the application.
+func (r RetentionRule) Specificity() int {
+	specificity := 0
+	if r.Application != "" {
+		specificity++
+	}
+	if r.Action != "" {
+		specificity += 2
+	}
+	return specificity
+}
+
+// String returns the rule in the form accepted by ParseRetentionRules.
+func (r RetentionRule) String() string {
+	selector := r.Action
+	if r.Application != "" {
+		action := r.Action
+		if action == "" {
+			action = "*"
+		}
+		selector = r.Application + ":" + action
+	}
+	return selector + "=" + r.MaxAge.String()
+}
+
+// ParseRetentionRules parses a comma separated list of retention rules.
+// Each rule has the form "<selector>=<duration>", where the selector is
+// one of "<action>", "<application>:<action>" or "<application>:*". The
+// results of juju run commands are selected with the action name
+// "juju-run". A duration of 0 keeps the matching results until the
+// actions collection grows too large. The rules are returned with the
+// most specific first.
+func ParseRetentionRules(spec string) ([]RetentionRule, error) {
+	var rules []RetentionRule
+	seen := make(map[RetentionRule]bool)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		rule, err := parseRetentionRule(part)
+		if err != nil {
+			return nil, errors.Annotatef(err, "invalid retention rule %q", part)
+		}
+		key := RetentionRule{Application: rule.Application, Action: rule.Action}
+		if seen[key] {
+			return nil, errors.Errorf("duplicate retention rule %q", part)
+		}
+		seen[key] = true
+		rules = append(rules, rule)
+	}
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].Specificity() > rules[j].Specificity()
+	})
+	return rules, nil
+}
+
+func parseRetentionRule(spec string) (RetentionRule, error) {
+	var rule RetentionRule
+	eq := strings.Index(spec, "=")
+	if eq < 0 {
+		return rule, errors.New("expected <selector>=<duration>")
+	}
+	selector := strings.TrimSpace(spec[:eq])
+	maxAge, err := time.ParseDuration(strings.TrimSpace(spec[eq+1:]))
+	if err != nil {
+		return rule, errors.Trace(err)
+	}
+	if maxAge < 0 {
+		return rule, errors.New("negative duration")
+	}
+	rule.MaxAge = maxAge
+
+	action := selector
+	if colon := strings.Index(selector, ":"); colon >= 0 {
+		rule.Application = selector[:colon]
+		if !names.IsValidApplication(rule.Application) {
+			return rule, errors.NotValidf("application name %q", rule.Application)
+		}
+		action = selector[colon+1:]
+	} else if action == "*" {
+		return rule, errors.New("use max-action-results-age to set the age of all action results")
+	}
+	switch action {
+	case "":
+		return rule, errors.New("missing action name")
+	case "*":
+	default:
+		rule.Action = action
+	}
+	return rule, nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package actions_test
+
+import (
+	"time"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/core/actions"
+)
+
+type RetentionSuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&RetentionSuite{})
+
+func (s *RetentionSuite) TestParseRetentionRules(c *gc.C) {
+	rules, err := actions.ParseRetentionRules("backup=2160h, health-check=1h,mysql:*=720h,mysql:backup=0s,juju-run=24h")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(rules, jc.DeepEquals, []actions.RetentionRule{
+		{Application: "mysql", Action: "backup", MaxAge: 0},
+		{Action: "backup", MaxAge: 2160 * time.Hour},
+		{Action: "health-check", MaxAge: time.Hour},
+		{Action: "juju-run", MaxAge: 24 * time.Hour},
+		{Application: "mysql", MaxAge: 720 * time.Hour},
+	})
+	c.Check(rules[1].String(), gc.Equals, "backup=2160h0m0s")
+	c.Check(rules[4].String(), gc.Equals, "mysql:*=720h0m0s")
+}
+
+func (s *RetentionSuite) TestParseRetentionRulesEmpty(c *gc.C) {
+	rules, err := actions.ParseRetentionRules("")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(rules, gc.HasLen, 0)
+}
+
+func (s *RetentionSuite) TestParseRetentionRulesInvalid(c *gc.C) {
+	for i, test := range []struct {
+		spec string
+		err  string
+	}{{
+		spec: "backup",
+		err:  `invalid retention rule "backup": expected <selector>=<duration>`,
+	}, {
+		spec: "backup=forever",
+		err:  `invalid retention rule "backup=forever": time: invalid duration forever`,
+	}, {
+		spec: "backup=-1h",
+		err:  `invalid retention rule "backup=-1h": negative duration`,
+	}, {
+		spec: "*=1h",
+		err:  `invalid retention rule "\*=1h": use max-action-results-age to set the age of all action results`,
+	}, {
+		spec: "=1h",
+		err:  `invalid retention rule "=1h": missing action name`,
+	}, {
+		spec: "mysql:=1h",
+		err:  `invalid retention rule "mysql:=1h": missing action name`,
+	}, {
+		spec: "My_App:*=1h",
+		err:  `invalid retention rule "My_App:\*=1h": application name "My_App" not valid`,
+	}, {
+		spec: "backup=1h,backup=2h",
+		err:  `duplicate retention rule "backup=2h"`,
+	}} {
+		c.Logf("test %d: %s", i, test.spec)
+		_, err := actions.ParseRetentionRules(test.spec)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package actions
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+)
+
+// scheduleDescriptors maps the predefined schedules to their cron
+// equivalents.
+var scheduleDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+type scheduleField struct {
+	name     string
+	min, max int
+}
+
+var scheduleFields = []scheduleField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	// Both 0 and 7 are Sunday.
+	{"day of week", 0, 7},
+}
+
+// maxScheduleSearch bounds the search for the next time a schedule
+// runs. Every valid schedule runs at least once in any five years.
+const maxScheduleSearch = 5 * 366 * 24 * time.Hour
+
+// Schedule is a cron-style schedule describing when a recurring action
+// runs. A schedule has five fields: minute, hour, day of month, month
+// and day of week. Each field is "*", a number, a range such as "1-5",
+// or a comma separated list of those, and may have a step such as
+// "*/15". The descriptors @yearly, @monthly, @weekly, @daily and
+// @hourly are also accepted. Schedules are evaluated in UTC.
+type Schedule struct {
+	spec string
+
+	minute, hour, dom, month, dow uint64
+
+	// domAny and dowAny record whether the day fields are
+	// unrestricted, that is whether they start with "*", including
+	// steps such as "*/2". As with cron, if both are restricted then
+	// a day matches if either field matches.
+	domAny, dowAny bool
+}
+
+// ParseSchedule parses a cron-style schedule.
+func ParseSchedule(spec string) (*Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	expanded := spec
+	if descriptor, ok := scheduleDescriptors[spec]; ok {
+		expanded = descriptor
+	}
+	parts := strings.Fields(expanded)
+	if len(parts) != len(scheduleFields) {
+		return nil, errors.Errorf("invalid schedule %q: expected %d fields, got %d", spec, len(scheduleFields), len(parts))
+	}
+	var bits [5]uint64
+	for i, field := range scheduleFields {
+		fieldBits, err := parseScheduleField(parts[i], field)
+		if err != nil {
+			return nil, errors.Annotatef(err, "invalid schedule %q", spec)
+		}
+		bits[i] = fieldBits
+	}
+	// Sunday may be given as 7; fold it into 0.
+	if bits[4]&(1<<7) != 0 {
+		bits[4] = bits[4]&^(1<<7) | 1
+	}
+	s := &Schedule{
+		spec:   spec,
+		minute: bits[0],
+		hour:   bits[1],
+		dom:    bits[2],
+		month:  bits[3],
+		dow:    bits[4],
+		domAny: strings.HasPrefix(parts[2], "*"),
+		dowAny: strings.HasPrefix(parts[4], "*"),
+	}
+	if s.Next(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)).IsZero() {
+		return nil, errors.Errorf("invalid schedule %q: never runs", spec)
+	}
+	return s, nil
+}
+
+func parseScheduleField(expr string, field scheduleField) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(expr, ",") {
+		rangeExpr, step := item, 1
+		if i := strings.Index(item, "/"); i >= 0 {
+			var err error
+			rangeExpr = item[:i]
+			step, err = strconv.Atoi(item[i+1:])
+			if err != nil || step < 1 {
+				return 0, errors.Errorf("invalid step in %s %q", field.name, item)
+			}
+		}
+		start, end := field.min, field.max
+		switch {
+		case rangeExpr == "*":
+		case strings.Contains(rangeExpr, "-"):
+			bounds := strings.SplitN(rangeExpr, "-", 2)
+			var err error
+			if start, err = parseScheduleValue(bounds[0], field); err != nil {
+				return 0, errors.Trace(err)
+			}
+			if end, err = parseScheduleValue(bounds[1], field); err != nil {
+				return 0, errors.Trace(err)
+			}
+			if start > end {
+				return 0, errors.Errorf("invalid range in %s %q", field.name, item)
+			}
+		default:
+			var err error
+			if start, err = parseScheduleValue(rangeExpr, field); err != nil {
+				return 0, errors.Trace(err)
+			}
+			// A single value with a step, such as "5/10", runs from
+			// that value to the end of the field's range.
+			if step == 1 {
+				end = start
+			}
+		}
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func parseScheduleValue(value string, field scheduleField) (int, error) {
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, errors.Errorf("invalid %s %q", field.name, value)
+	}
+	if v < field.min || v > field.max {
+		return 0, errors.Errorf("%s %d out of range %d-%d", field.name, v, field.min, field.max)
+	}
+	return v, nil
+}
+
+// String returns the schedule as it was specified.
+func (s *Schedule) String() string {
+	return s.spec
+}
+
+// Next returns the first time the schedule runs after the given time,
+// or the zero time if the schedule doesn't run in the following five
+// years.
+func (s *Schedule) Next(after time.Time) time.Time {
+	t := after.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxScheduleSearch)
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
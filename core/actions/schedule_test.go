@@ -0,0 +1,138 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package actions_test
+
+import (
+	"time"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/core/actions"
+)
+
+type ScheduleSuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&ScheduleSuite{})
+
+// 2018-10-10 was a Wednesday.
+var scheduleBase = time.Date(2018, 10, 10, 12, 34, 56, 0, time.UTC)
+
+func (s *ScheduleSuite) TestNext(c *gc.C) {
+	for i, test := range []struct {
+		spec     string
+		expected time.Time
+	}{{
+		spec:     "* * * * *",
+		expected: time.Date(2018, 10, 10, 12, 35, 0, 0, time.UTC),
+	}, {
+		spec:     "*/15 * * * *",
+		expected: time.Date(2018, 10, 10, 12, 45, 0, 0, time.UTC),
+	}, {
+		spec:     "30 2 * * *",
+		expected: time.Date(2018, 10, 11, 2, 30, 0, 0, time.UTC),
+	}, {
+		spec:     "@daily",
+		expected: time.Date(2018, 10, 11, 0, 0, 0, 0, time.UTC),
+	}, {
+		spec:     "@hourly",
+		expected: time.Date(2018, 10, 10, 13, 0, 0, 0, time.UTC),
+	}, {
+		spec:     "@weekly",
+		expected: time.Date(2018, 10, 14, 0, 0, 0, 0, time.UTC),
+	}, {
+		spec:     "0 0 * * 7",
+		expected: time.Date(2018, 10, 14, 0, 0, 0, 0, time.UTC),
+	}, {
+		spec:     "@monthly",
+		expected: time.Date(2018, 11, 1, 0, 0, 0, 0, time.UTC),
+	}, {
+		spec:     "@yearly",
+		expected: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC),
+	}, {
+		spec:     "0 9 * * 1-5",
+		expected: time.Date(2018, 10, 11, 9, 0, 0, 0, time.UTC),
+	}, {
+		spec:     "0 0 29 2 *",
+		expected: time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC),
+	}, {
+		// Both day fields are restricted, so either may match.
+		spec:     "0 0 1 * 5",
+		expected: time.Date(2018, 10, 12, 0, 0, 0, 0, time.UTC),
+	}, {
+		// A step over every day of the month doesn't restrict the
+		// day, so both fields must match: an odd-numbered Monday.
+		spec:     "0 0 */2 * 1",
+		expected: time.Date(2018, 10, 15, 0, 0, 0, 0, time.UTC),
+	}, {
+		// Likewise for a step over every day of the week: the 13th
+		// falling on a Sunday, Tuesday, Thursday or Saturday.
+		spec:     "0 0 13 * */2",
+		expected: time.Date(2018, 10, 13, 0, 0, 0, 0, time.UTC),
+	}, {
+		spec:     "5,40 12 * * *",
+		expected: time.Date(2018, 10, 10, 12, 40, 0, 0, time.UTC),
+	}, {
+		spec:     "10/20 * * * *",
+		expected: time.Date(2018, 10, 10, 12, 50, 0, 0, time.UTC),
+	}} {
+		c.Logf("test %d: %q", i, test.spec)
+		schedule, err := actions.ParseSchedule(test.spec)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(schedule.String(), gc.Equals, test.spec)
+		c.Check(schedule.Next(scheduleBase), gc.Equals, test.expected)
+	}
+}
+
+func (s *ScheduleSuite) TestNextConvertsToUTC(c *gc.C) {
+	schedule, err := actions.ParseSchedule("0 0 * * *")
+	c.Assert(err, jc.ErrorIsNil)
+	local := time.FixedZone("test", 10*60*60)
+	after := time.Date(2018, 10, 10, 9, 0, 0, 0, local)
+	c.Assert(schedule.Next(after), gc.Equals, time.Date(2018, 10, 11, 0, 0, 0, 0, time.UTC))
+}
+
+func (s *ScheduleSuite) TestParseErrors(c *gc.C) {
+	for i, test := range []struct {
+		spec string
+		err  string
+	}{{
+		spec: "",
+		err:  `invalid schedule "": expected 5 fields, got 0`,
+	}, {
+		spec: "* * * *",
+		err:  `invalid schedule "\* \* \* \*": expected 5 fields, got 4`,
+	}, {
+		spec: "60 * * * *",
+		err:  `invalid schedule "60 \* \* \* \*": minute 60 out of range 0-59`,
+	}, {
+		spec: "* 24 * * *",
+		err:  `invalid schedule "\* 24 \* \* \*": hour 24 out of range 0-23`,
+	}, {
+		spec: "* * 0 * *",
+		err:  `invalid schedule "\* \* 0 \* \*": day of month 0 out of range 1-31`,
+	}, {
+		spec: "* * * jan *",
+		err:  `invalid schedule "\* \* \* jan \*": invalid month "jan"`,
+	}, {
+		spec: "*/0 * * * *",
+		err:  `invalid schedule "\*/0 \* \* \* \*": invalid step in minute "\*/0"`,
+	}, {
+		spec: "5-1 * * * *",
+		err:  `invalid schedule "5-1 \* \* \* \*": invalid range in minute "5-1"`,
+	}, {
+		spec: "0 0 30 2 *",
+		err:  `invalid schedule "0 0 30 2 \*": never runs`,
+	}, {
+		spec: "@fortnightly",
+		err:  `invalid schedule "@fortnightly": expected 5 fields, got 1`,
+	}} {
+		c.Logf("test %d: %q", i, test.spec)
+		_, err := actions.ParseSchedule(test.spec)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
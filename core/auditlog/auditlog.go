@@ -45,7 +45,9 @@ type ConversationArgs struct {
 }
 
 // Request represents a call to an API facade made as part of
-// a specific conversation.
+// a specific conversation. Requests to the API server's HTTP
+// endpoints are recorded with the HTTPFacade facade name, the HTTP
+// method, and the path and size of the request.
 type Request struct {
 	ConversationID string `json:"conversation-id"`
 	ConnectionID   string `json:"connection-id"`
@@ -55,8 +57,14 @@ type Request struct {
 	Method         string `json:"method"`
 	Version        int    `json:"version"`
 	Args           string `json:"args,omitempty"`
+	Path           string `json:"path,omitempty"`
+	Size           int64  `json:"size,omitempty"`
 }
 
+// HTTPFacade is the facade name used for requests made to the API
+// server's HTTP endpoints rather than over the RPC connection.
+const HTTPFacade = "HTTP"
+
 // RequestArgs is the information about an API call that we want to
 // record.
 type RequestArgs struct {
@@ -64,6 +72,8 @@ type RequestArgs struct {
 	Method    string
 	Version   int
 	Args      string
+	Path      string
+	Size      int64
 	RequestID uint64
 }
 
@@ -151,6 +161,8 @@ func (r *Recorder) AddRequest(m RequestArgs) error {
 		Method:         m.Method,
 		Version:        m.Version,
 		Args:           m.Args,
+		Path:           m.Path,
+		Size:           m.Size,
 	}))
 }
 
@@ -4,8 +4,26 @@
 package auditlog
 
 import (
+	"time"
+
 	"github.com/juju/collections/set"
 	"github.com/juju/errors"
+
+	"github.com/juju/juju/logfwd/syslog"
+)
+
+const (
+	// SinkFile is the sink name for the rotating local audit.log
+	// file.
+	SinkFile = "file"
+
+	// SinkSyslog is the sink name for forwarding audit records to a
+	// remote syslog (RFC 5424) host.
+	SinkSyslog = "syslog"
+
+	// SinkWebhook is the sink name for posting batches of audit
+	// records to an HTTP endpoint.
+	SinkWebhook = "webhook"
 )
 
 // Config holds parameters to control audit logging.
@@ -29,6 +47,28 @@ type Config struct {
 	// consists of these method calls we won't log it.
 	ExcludeMethods set.Strings
 
+	// Sink names the kind of target audit records are written to:
+	// one of SinkFile, SinkSyslog or SinkWebhook. An empty value
+	// means SinkFile.
+	Sink string
+
+	// Syslog holds the connection details for the remote syslog
+	// host, used when Sink is SinkSyslog.
+	Syslog syslog.RawConfig
+
+	// WebhookURL is the HTTP endpoint batches of records are posted
+	// to when Sink is SinkWebhook.
+	WebhookURL string
+
+	// WebhookBatchSize is the number of records collected before a
+	// batch is posted to the webhook.
+	WebhookBatchSize int
+
+	// WebhookFlushInterval is the longest time records will wait
+	// before being posted to the webhook, even if the batch isn't
+	// full.
+	WebhookFlushInterval time.Duration
+
 	// Target is the AuditLog entries should be written to.
 	Target AuditLog
 }
@@ -38,5 +78,37 @@ func (cfg Config) Validate() error {
 	if cfg.Enabled && cfg.Target == nil {
 		return errors.NewNotValid(nil, "logging enabled but no target provided")
 	}
+	switch cfg.Sink {
+	case "", SinkFile, SinkSyslog, SinkWebhook:
+	default:
+		return errors.NotValidf("audit log sink %q", cfg.Sink)
+	}
 	return nil
 }
+
+// TargetChanged returns whether other describes a different
+// destination for audit records than cfg, meaning that a new Target
+// needs to be created for it.
+func (cfg Config) TargetChanged(other Config) bool {
+	if sinkName(cfg.Sink) != sinkName(other.Sink) {
+		return true
+	}
+	switch sinkName(cfg.Sink) {
+	case SinkSyslog:
+		return cfg.Syslog != other.Syslog
+	case SinkWebhook:
+		return cfg.WebhookURL != other.WebhookURL ||
+			cfg.WebhookBatchSize != other.WebhookBatchSize ||
+			cfg.WebhookFlushInterval != other.WebhookFlushInterval
+	}
+	// The log file size and backup settings can't be changed after
+	// bootstrap, so the file target never needs replacing.
+	return false
+}
+
+func sinkName(sink string) string {
+	if sink == "" {
+		return SinkFile
+	}
+	return sink
+}
@@ -0,0 +1,269 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package auditlog
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+)
+
+// Filter describes which conversations should be returned when
+// querying the audit log. Zero-valued fields match everything.
+type Filter struct {
+	// Who matches the user that started the conversation.
+	Who string
+
+	// Model matches either the UUID or the name ("user/name") of the
+	// model the conversation was with.
+	Model string
+
+	// Facade and Method match requests made in the conversation.
+	// When either is set, only conversations with a matching
+	// request are returned, and only the matching requests are
+	// included.
+	Facade string
+	Method string
+
+	// ConversationID matches a specific conversation.
+	ConversationID string
+
+	// After and Before limit the conversations to those started in
+	// the time window [After, Before).
+	After  time.Time
+	Before time.Time
+
+	// Limit, if positive, restricts the result to the most recent
+	// conversations.
+	Limit int
+}
+
+// ConversationRecord is a conversation along with the requests made
+// as part of it.
+type ConversationRecord struct {
+	Conversation
+	Requests []RequestRecord
+}
+
+// RequestRecord is a request along with any errors returned in
+// response to it.
+type RequestRecord struct {
+	Request
+	Errors []*Error
+}
+
+// Query reads the audit log files in logDir (audit.log and any
+// rotated backups) and returns the conversations that match the
+// filter, oldest first. If the filter has a Limit, older backups are
+// only read if the newer files don't hold enough conversations.
+func Query(logDir string, filter Filter) ([]ConversationRecord, error) {
+	paths, err := logFilePaths(logDir)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if filter.Limit > 0 {
+		paths, err = limitLogFilePaths(paths, filter)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	q := newQuery(filter)
+	for _, path := range paths {
+		if err := q.readFile(path); err != nil {
+			return nil, errors.Annotatef(err, "reading %s", path)
+		}
+	}
+	return q.results(), nil
+}
+
+// QueryRecords returns the conversations matching the filter from a
+// stream of JSON records in audit.log format, oldest first.
+func QueryRecords(r io.Reader, filter Filter) ([]ConversationRecord, error) {
+	q := newQuery(filter)
+	if err := q.read(r); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return q.results(), nil
+}
+
+// logFilePaths returns the audit log files in logDir, oldest first.
+// Backups are named by lumberjack with the rotation time, so sorting
+// them by name puts them in order.
+func logFilePaths(logDir string) ([]string, error) {
+	backups, err := filepath.Glob(filepath.Join(logDir, "audit-*.log*"))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	sort.Strings(backups)
+	current := filepath.Join(logDir, "audit.log")
+	if _, err := os.Stat(current); err == nil {
+		backups = append(backups, current)
+	} else if !os.IsNotExist(err) {
+		return nil, errors.Trace(err)
+	}
+	return backups, nil
+}
+
+// limitLogFilePaths returns the newest of the log files that between
+// them hold at least filter.Limit matching conversations. Each file
+// is checked on its own, so requests logged in a later file than
+// their conversation aren't seen; that can only undercount, which
+// means more files are read than strictly needed.
+func limitLogFilePaths(paths []string, filter Filter) ([]string, error) {
+	found := 0
+	for i := len(paths) - 1; i >= 0; i-- {
+		q := newQuery(filter)
+		if err := q.readFile(paths[i]); err != nil {
+			return nil, errors.Annotatef(err, "reading %s", paths[i])
+		}
+		found += len(q.results())
+		if found >= filter.Limit {
+			return paths[i:], nil
+		}
+	}
+	return paths, nil
+}
+
+type query struct {
+	filter        Filter
+	conversations map[string]*ConversationRecord
+	order         []string
+}
+
+func newQuery(filter Filter) *query {
+	return &query{
+		filter:        filter,
+		conversations: make(map[string]*ConversationRecord),
+	}
+}
+
+func (q *query) readFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	return errors.Trace(q.read(r))
+}
+
+func (q *query) read(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	// Captured API args can make for long lines.
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal(line, &rec); err != nil {
+			// A partially written final line shouldn't stop us
+			// reading the rest of the log.
+			logger.Warningf("skipping unparseable audit record: %v", err)
+			continue
+		}
+		q.add(rec)
+	}
+	return errors.Trace(scanner.Err())
+}
+
+func (q *query) add(rec Record) {
+	switch {
+	case rec.Conversation != nil:
+		c := rec.Conversation
+		if !q.matchesConversation(*c) {
+			return
+		}
+		if _, ok := q.conversations[c.ConversationID]; !ok {
+			q.order = append(q.order, c.ConversationID)
+		}
+		q.conversations[c.ConversationID] = &ConversationRecord{Conversation: *c}
+	case rec.Request != nil:
+		c, ok := q.conversations[rec.Request.ConversationID]
+		if !ok || !q.matchesRequest(*rec.Request) {
+			return
+		}
+		c.Requests = append(c.Requests, RequestRecord{Request: *rec.Request})
+	case rec.Errors != nil:
+		c, ok := q.conversations[rec.Errors.ConversationID]
+		if !ok {
+			return
+		}
+		for i := range c.Requests {
+			if c.Requests[i].RequestID == rec.Errors.RequestID {
+				c.Requests[i].Errors = append(c.Requests[i].Errors, rec.Errors.Errors...)
+				break
+			}
+		}
+	}
+}
+
+func (q *query) matchesConversation(c Conversation) bool {
+	f := q.filter
+	if f.Who != "" && c.Who != f.Who {
+		return false
+	}
+	if f.Model != "" && c.ModelUUID != f.Model && c.ModelName != f.Model {
+		return false
+	}
+	if f.ConversationID != "" && c.ConversationID != f.ConversationID {
+		return false
+	}
+	if !f.After.IsZero() || !f.Before.IsZero() {
+		when, err := time.Parse(time.RFC3339, c.When)
+		if err != nil {
+			return false
+		}
+		if !f.After.IsZero() && when.Before(f.After) {
+			return false
+		}
+		if !f.Before.IsZero() && !when.Before(f.Before) {
+			return false
+		}
+	}
+	return true
+}
+
+func (q *query) matchesRequest(r Request) bool {
+	if q.filter.Facade != "" && r.Facade != q.filter.Facade {
+		return false
+	}
+	if q.filter.Method != "" && r.Method != q.filter.Method {
+		return false
+	}
+	return true
+}
+
+func (q *query) results() []ConversationRecord {
+	filterRequests := q.filter.Facade != "" || q.filter.Method != ""
+	var result []ConversationRecord
+	for _, id := range q.order {
+		c := q.conversations[id]
+		if filterRequests && len(c.Requests) == 0 {
+			continue
+		}
+		result = append(result, *c)
+	}
+	if q.filter.Limit > 0 && len(result) > q.filter.Limit {
+		result = result[len(result)-q.filter.Limit:]
+	}
+	return result
+}
@@ -0,0 +1,189 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package auditlog_test
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/core/auditlog"
+)
+
+type QuerySuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&QuerySuite{})
+
+const (
+	backupLogContents = `
+{"conversation":{"who":"fred","what":"juju deploy mysql","when":"2018-05-01T10:00:00Z","model-name":"fred/prod","model-uuid":"0f5dba5b-94f4-4fb4-8e0f-3c7d3e4c8a3a","conversation-id":"0000000000000001","connection-id":"1"}}
+{"request":{"conversation-id":"0000000000000001","connection-id":"1","request-id":1,"when":"2018-05-01T10:00:01Z","facade":"Application","method":"Deploy","version":6}}
+`
+	currentLogContents = `
+{"conversation":{"who":"mary","what":"juju remove-application mysql","when":"2018-05-08T09:30:00Z","model-name":"fred/prod","model-uuid":"0f5dba5b-94f4-4fb4-8e0f-3c7d3e4c8a3a","conversation-id":"0000000000000002","connection-id":"2"}}
+{"request":{"conversation-id":"0000000000000002","connection-id":"2","request-id":1,"when":"2018-05-08T09:30:01Z","facade":"Application","method":"DestroyApplication","version":6}}
+{"errors":{"conversation-id":"0000000000000002","connection-id":"2","request-id":1,"when":"2018-05-08T09:30:02Z","errors":[{"message":"oops","code":""}]}}
+{"conversation":{"who":"fred","what":"juju add-unit mysql","when":"2018-05-09T11:00:00Z","model-name":"fred/staging","model-uuid":"a1e53bcd-0b5a-4c55-8e6e-0c1b8fd3f7a2","conversation-id":"0000000000000003","connection-id":"3"}}
+{"request":{"conversation-id":"0000000000000003","connection-id":"3","request-id":1,"when":"2018-05-09T11:00:01Z","facade":"Application","method":"AddUnits","version":6}}
+{"request":{"conversation-id":"0000000000000003","connection-id":"3","request-id":2,"when":"2018-05-09T11:00:02Z","facade":"Client","method":"FullStatus","version":1}}
+{"conver
+`
+)
+
+func (s *QuerySuite) writeLogs(c *gc.C) string {
+	dir := c.MkDir()
+	f, err := os.Create(filepath.Join(dir, "audit-2018-05-02T00-00-00.000.log.gz"))
+	c.Assert(err, jc.ErrorIsNil)
+	gz := gzip.NewWriter(f)
+	_, err = gz.Write([]byte(backupLogContents[1:]))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(gz.Close(), jc.ErrorIsNil)
+	c.Assert(f.Close(), jc.ErrorIsNil)
+	err = ioutil.WriteFile(filepath.Join(dir, "audit.log"), []byte(currentLogContents[1:]), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+	return dir
+}
+
+func conversationIDs(records []auditlog.ConversationRecord) []string {
+	var ids []string
+	for _, r := range records {
+		ids = append(ids, r.ConversationID)
+	}
+	return ids
+}
+
+func (s *QuerySuite) TestQueryAll(c *gc.C) {
+	records, err := auditlog.Query(s.writeLogs(c), auditlog.Filter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(conversationIDs(records), jc.DeepEquals, []string{
+		"0000000000000001", "0000000000000002", "0000000000000003",
+	})
+	c.Assert(records[1], jc.DeepEquals, auditlog.ConversationRecord{
+		Conversation: auditlog.Conversation{
+			Who:            "mary",
+			What:           "juju remove-application mysql",
+			When:           "2018-05-08T09:30:00Z",
+			ModelName:      "fred/prod",
+			ModelUUID:      "0f5dba5b-94f4-4fb4-8e0f-3c7d3e4c8a3a",
+			ConversationID: "0000000000000002",
+			ConnectionID:   "2",
+		},
+		Requests: []auditlog.RequestRecord{{
+			Request: auditlog.Request{
+				ConversationID: "0000000000000002",
+				ConnectionID:   "2",
+				RequestID:      1,
+				When:           "2018-05-08T09:30:01Z",
+				Facade:         "Application",
+				Method:         "DestroyApplication",
+				Version:        6,
+			},
+			Errors: []*auditlog.Error{{Message: "oops"}},
+		}},
+	})
+	c.Assert(records[2].Requests, gc.HasLen, 2)
+}
+
+func (s *QuerySuite) TestQueryNoLogs(c *gc.C) {
+	records, err := auditlog.Query(c.MkDir(), auditlog.Filter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, gc.HasLen, 0)
+}
+
+func (s *QuerySuite) TestQueryFilters(c *gc.C) {
+	dir := s.writeLogs(c)
+	for i, test := range []struct {
+		about    string
+		filter   auditlog.Filter
+		expected []string
+	}{{
+		about:    "who",
+		filter:   auditlog.Filter{Who: "fred"},
+		expected: []string{"0000000000000001", "0000000000000003"},
+	}, {
+		about:    "model uuid",
+		filter:   auditlog.Filter{Model: "0f5dba5b-94f4-4fb4-8e0f-3c7d3e4c8a3a"},
+		expected: []string{"0000000000000001", "0000000000000002"},
+	}, {
+		about:    "model name",
+		filter:   auditlog.Filter{Model: "fred/staging"},
+		expected: []string{"0000000000000003"},
+	}, {
+		about:    "facade and method",
+		filter:   auditlog.Filter{Facade: "Application", Method: "DestroyApplication"},
+		expected: []string{"0000000000000002"},
+	}, {
+		about:    "method only",
+		filter:   auditlog.Filter{Method: "FullStatus"},
+		expected: []string{"0000000000000003"},
+	}, {
+		about:    "conversation",
+		filter:   auditlog.Filter{ConversationID: "0000000000000001"},
+		expected: []string{"0000000000000001"},
+	}, {
+		about: "time window",
+		filter: auditlog.Filter{
+			After:  time.Date(2018, 5, 8, 0, 0, 0, 0, time.UTC),
+			Before: time.Date(2018, 5, 9, 0, 0, 0, 0, time.UTC),
+		},
+		expected: []string{"0000000000000002"},
+	}, {
+		about:    "limit keeps most recent",
+		filter:   auditlog.Filter{Limit: 2},
+		expected: []string{"0000000000000002", "0000000000000003"},
+	}} {
+		c.Logf("test %d: %s", i, test.about)
+		records, err := auditlog.Query(dir, test.filter)
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(conversationIDs(records), jc.DeepEquals, test.expected)
+	}
+}
+
+func (s *QuerySuite) TestQueryLimitSkipsOlderFiles(c *gc.C) {
+	dir := s.writeLogs(c)
+	// An older backup that can't be read is only a problem if the
+	// newer files don't hold enough conversations.
+	err := ioutil.WriteFile(filepath.Join(dir, "audit-2018-04-01T00-00-00.000.log.gz"), []byte("not gzip"), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+
+	records, err := auditlog.Query(dir, auditlog.Filter{Limit: 2})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(conversationIDs(records), jc.DeepEquals, []string{"0000000000000002", "0000000000000003"})
+
+	records, err = auditlog.Query(dir, auditlog.Filter{Limit: 3})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(conversationIDs(records), jc.DeepEquals, []string{
+		"0000000000000001", "0000000000000002", "0000000000000003",
+	})
+
+	_, err = auditlog.Query(dir, auditlog.Filter{Limit: 4})
+	c.Assert(err, gc.ErrorMatches, `reading .*audit-2018-04-01T00-00-00.000.log.gz: .*`)
+}
+
+func (s *QuerySuite) TestQueryMethodFilterDropsOtherRequests(c *gc.C) {
+	records, err := auditlog.Query(s.writeLogs(c), auditlog.Filter{Method: "AddUnits"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, gc.HasLen, 1)
+	c.Assert(records[0].Requests, gc.HasLen, 1)
+	c.Assert(records[0].Requests[0].Method, gc.Equals, "AddUnits")
+}
+
+func (s *QuerySuite) TestQueryRecords(c *gc.C) {
+	records, err := auditlog.QueryRecords(strings.NewReader(expectedLogContents), auditlog.Filter{Who: "deerhoof"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, gc.HasLen, 1)
+	c.Assert(records[0].Requests, gc.HasLen, 1)
+	c.Assert(records[0].Requests[0].Errors, jc.DeepEquals, []*auditlog.Error{
+		{Message: "oops", Code: "unauthorized access"},
+	})
+}
@@ -0,0 +1,267 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package auditlog
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/juju/clock"
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/logfwd"
+)
+
+// syslogModule is the module name recorded against audit records
+// forwarded to syslog.
+const syslogModule = "juju.audit"
+
+// LogRecordSender sends log records to a remote log sink. It is
+// implemented by *logfwd/syslog.Client.
+type LogRecordSender interface {
+	Send([]logfwd.Record) error
+	Close() error
+}
+
+// OpenSenderFunc opens a connection to a remote log sink.
+type OpenSenderFunc func() (LogRecordSender, error)
+
+// defaultSyslogPending is the number of records queued by a syslog
+// sink when MaxPending isn't set.
+const defaultSyslogPending = 10000
+
+// SyslogConfig holds the parameters for an audit log sink that
+// forwards records to a syslog host.
+type SyslogConfig struct {
+	// Open opens a connection to the syslog host. It should give up
+	// if the connection can't be made in a reasonable time.
+	Open OpenSenderFunc
+
+	// Origin describes the controller as the source of the records.
+	Origin logfwd.Origin
+
+	// Timeout is the longest time sending records to the syslog host
+	// may take. A send that takes longer is abandoned by closing the
+	// connection, and the records are dropped.
+	Timeout time.Duration
+
+	// MaxPending is the most records that will be queued waiting to
+	// be sent; records arriving when the queue is full are dropped.
+	// If it is zero, 10000 records are allowed.
+	MaxPending int
+
+	// Clock is used to timestamp records and time sends.
+	Clock clock.Clock
+}
+
+// Validate checks the syslog configuration.
+func (cfg SyslogConfig) Validate() error {
+	if cfg.Open == nil {
+		return errors.NotValidf("nil Open")
+	}
+	if cfg.Timeout <= 0 {
+		return errors.NotValidf("non-positive Timeout")
+	}
+	if cfg.MaxPending < 0 {
+		return errors.NotValidf("negative MaxPending")
+	}
+	if cfg.Clock == nil {
+		return errors.NotValidf("nil Clock")
+	}
+	return nil
+}
+
+type auditLogSyslog struct {
+	config SyslogConfig
+
+	// mu guards pending and dropped.
+	mu      sync.Mutex
+	pending []logfwd.Record
+	dropped int
+
+	// sender is only used by the sending goroutine, and by Close
+	// once that has stopped.
+	sender LogRecordSender
+
+	// ready is signalled when records are waiting to be sent.
+	ready chan struct{}
+
+	// closeMu guards closing stop.
+	closeMu sync.Mutex
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewSyslog returns an audit entry sink which forwards each record
+// (JSON-encoded, as it would appear in audit.log) to a syslog host.
+// Records are sent in the background so that a slow or unavailable
+// syslog host never holds up API requests. The connection is opened
+// when there are records to send, and reopened for the next records
+// if sending fails. Records are dropped, with a warning, if sending
+// them fails or takes longer than Timeout, or if they arrive while
+// MaxPending records are already waiting.
+func NewSyslog(config SyslogConfig) (AuditLog, error) {
+	if err := config.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if config.MaxPending == 0 {
+		config.MaxPending = defaultSyslogPending
+	}
+	a := &auditLogSyslog{
+		config: config,
+		ready:  make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go a.loop()
+	return a, nil
+}
+
+// AddConversation implements AuditLog.
+func (a *auditLogSyslog) AddConversation(c Conversation) error {
+	return errors.Trace(a.addRecord(Record{Conversation: &c}))
+}
+
+// AddRequest implements AuditLog.
+func (a *auditLogSyslog) AddRequest(m Request) error {
+	return errors.Trace(a.addRecord(Record{Request: &m}))
+}
+
+// AddResponse implements AuditLog.
+func (a *auditLogSyslog) AddResponse(m ResponseErrors) error {
+	return errors.Trace(a.addRecord(Record{Errors: &m}))
+}
+
+// Close implements AuditLog. One attempt is made to send any records
+// that haven't been sent yet before the connection is closed.
+func (a *auditLogSyslog) Close() error {
+	a.closeMu.Lock()
+	defer a.closeMu.Unlock()
+	if a.closed() {
+		return nil
+	}
+	close(a.stop)
+	<-a.done
+	a.sendPending()
+	if a.sender == nil {
+		return nil
+	}
+	err := a.sender.Close()
+	a.sender = nil
+	return errors.Trace(err)
+}
+
+// addRecord queues the record to be sent. It never blocks on the
+// syslog host: if the queue is full, or the sink has been closed,
+// the record is dropped and counted.
+func (a *auditLogSyslog) addRecord(r Record) error {
+	bytes, err := json.Marshal(r)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	rec := logfwd.Record{
+		Origin:    a.config.Origin,
+		Timestamp: a.config.Clock.Now(),
+		Level:     loggo.INFO,
+		Location: logfwd.SourceLocation{
+			Module: syslogModule,
+		},
+		Message: string(bytes),
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed() || len(a.pending) >= a.config.MaxPending {
+		a.dropped++
+		return nil
+	}
+	a.pending = append(a.pending, rec)
+	select {
+	case a.ready <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (a *auditLogSyslog) closed() bool {
+	select {
+	case <-a.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+func (a *auditLogSyslog) loop() {
+	defer close(a.done)
+	for {
+		select {
+		case <-a.stop:
+			return
+		case <-a.ready:
+			a.sendPending()
+		}
+	}
+}
+
+// sendPending sends the records waiting to be sent.
+func (a *auditLogSyslog) sendPending() {
+	a.reportDropped()
+	a.mu.Lock()
+	records := a.pending
+	a.pending = nil
+	a.mu.Unlock()
+	if len(records) == 0 {
+		return
+	}
+	if err := a.send(records); err != nil {
+		logger.Errorf("dropping %d audit records: %v", len(records), err)
+	}
+}
+
+// send sends the records, connecting first if need be.
+func (a *auditLogSyslog) send(records []logfwd.Record) error {
+	if a.sender == nil {
+		sender, err := a.config.Open()
+		if err != nil {
+			return errors.Annotate(err, "connecting to syslog")
+		}
+		a.sender = sender
+	}
+	result := make(chan error, 1)
+	sender := a.sender
+	go func() {
+		result <- sender.Send(records)
+	}()
+	var err error
+	select {
+	case err = <-result:
+	case <-a.config.Clock.After(a.config.Timeout):
+		err = errors.Errorf("timed out after %v", a.config.Timeout)
+	}
+	if err != nil {
+		// Drop the connection so that we try again with a fresh one
+		// next time; closing it also unblocks a send that timed out.
+		if closeErr := a.sender.Close(); closeErr != nil {
+			logger.Warningf("closing syslog connection: %v", closeErr)
+		}
+		a.sender = nil
+		return errors.Annotate(err, "sending audit records to syslog")
+	}
+	return nil
+}
+
+// reportDropped logs the number of records dropped since it was last
+// called.
+func (a *auditLogSyslog) reportDropped() {
+	a.mu.Lock()
+	dropped := a.dropped
+	a.dropped = 0
+	a.mu.Unlock()
+	if dropped > 0 {
+		logger.Warningf("audit syslog queue full, dropped %d audit records", dropped)
+	}
+}
@@ -0,0 +1,265 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package auditlog_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/clock/testclock"
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/core/auditlog"
+	"github.com/juju/juju/logfwd"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type SyslogSuite struct {
+	testing.IsolationSuite
+
+	stub   testing.Stub
+	sender *fakeSender
+	clock  *testclock.Clock
+	origin logfwd.Origin
+}
+
+var _ = gc.Suite(&SyslogSuite{})
+
+func (s *SyslogSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.stub = testing.Stub{}
+	s.sender = &fakeSender{stub: &s.stub}
+	s.clock = testclock.NewClock(time.Date(2018, 5, 3, 12, 0, 0, 0, time.UTC))
+	s.origin = logfwd.Origin{
+		ControllerUUID: "deadbeef-0bad-400d-8000-4b1d0d06f00d",
+		ModelUUID:      "deadbeef-0bad-400d-8000-4b1d0d06f00d",
+		Hostname:       "machine-0.deadbeef-0bad-400d-8000-4b1d0d06f00d",
+		Type:           logfwd.OriginTypeMachine,
+		Name:           "0",
+	}
+}
+
+func (s *SyslogSuite) open() (auditlog.LogRecordSender, error) {
+	s.stub.AddCall("Open")
+	if err := s.stub.NextErr(); err != nil {
+		return nil, err
+	}
+	return s.sender, nil
+}
+
+func (s *SyslogSuite) newSyslog(c *gc.C, maxPending int) auditlog.AuditLog {
+	target, err := auditlog.NewSyslog(auditlog.SyslogConfig{
+		Open:       s.open,
+		Origin:     s.origin,
+		Timeout:    10 * time.Second,
+		MaxPending: maxPending,
+		Clock:      s.clock,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return target
+}
+
+func (s *SyslogSuite) TestValidate(c *gc.C) {
+	for i, test := range []struct {
+		modify func(*auditlog.SyslogConfig)
+		err    string
+	}{{
+		modify: func(cfg *auditlog.SyslogConfig) { cfg.Open = nil },
+		err:    "nil Open not valid",
+	}, {
+		modify: func(cfg *auditlog.SyslogConfig) { cfg.Timeout = 0 },
+		err:    "non-positive Timeout not valid",
+	}, {
+		modify: func(cfg *auditlog.SyslogConfig) { cfg.MaxPending = -1 },
+		err:    "negative MaxPending not valid",
+	}, {
+		modify: func(cfg *auditlog.SyslogConfig) { cfg.Clock = nil },
+		err:    "nil Clock not valid",
+	}} {
+		c.Logf("test %d", i)
+		config := auditlog.SyslogConfig{
+			Open:    s.open,
+			Origin:  s.origin,
+			Timeout: time.Second,
+			Clock:   s.clock,
+		}
+		test.modify(&config)
+		_, err := auditlog.NewSyslog(config)
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *SyslogSuite) TestSendsRecords(c *gc.C) {
+	target := s.newSyslog(c, 0)
+	err := target.AddConversation(auditlog.Conversation{
+		Who:            "deerhoof",
+		What:           "gojira",
+		When:           "2017-11-27T13:21:24Z",
+		ModelName:      "admin/default",
+		ConversationID: "0123456789abcdef",
+		ConnectionID:   "AC1",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	s.waitForCalls(c, 2)
+	err = target.AddResponse(auditlog.ResponseErrors{
+		ConversationID: "0123456789abcdef",
+		ConnectionID:   "AC1",
+		RequestID:      25,
+		When:           "2017-12-12T11:35:11Z",
+		Errors: []*auditlog.Error{
+			{Message: "oops", Code: "unauthorized access"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	s.waitForCalls(c, 3)
+	err = target.Close()
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The connection is opened once and reused.
+	s.stub.CheckCallNames(c, "Open", "Send", "Send", "Close")
+	calls := s.stub.Calls()
+	c.Assert(calls[1].Args[0], jc.DeepEquals, []logfwd.Record{{
+		Origin:    s.origin,
+		Timestamp: s.clock.Now(),
+		Level:     loggo.INFO,
+		Location:  logfwd.SourceLocation{Module: "juju.audit"},
+		Message:   `{"conversation":{"who":"deerhoof","what":"gojira","when":"2017-11-27T13:21:24Z","model-name":"admin/default","model-uuid":"","conversation-id":"0123456789abcdef","connection-id":"AC1"}}`,
+	}})
+	records := calls[2].Args[0].([]logfwd.Record)
+	c.Assert(records, gc.HasLen, 1)
+	c.Assert(records[0].Message, gc.Equals,
+		`{"errors":{"conversation-id":"0123456789abcdef","connection-id":"AC1","request-id":25,"when":"2017-12-12T11:35:11Z","errors":[{"message":"oops","code":"unauthorized access"}]}}`)
+}
+
+func (s *SyslogSuite) TestOpenError(c *gc.C) {
+	s.stub.SetErrors(errors.New("no route to host"))
+	target := s.newSyslog(c, 0)
+	defer target.Close()
+
+	// The record is dropped without failing the request.
+	err := target.AddRequest(auditlog.Request{ConversationID: "0123456789abcdef"})
+	c.Assert(err, jc.ErrorIsNil)
+	s.waitForCalls(c, 1)
+
+	// The next record tries to connect again.
+	err = target.AddRequest(auditlog.Request{ConversationID: "0123456789abcdef"})
+	c.Assert(err, jc.ErrorIsNil)
+	s.waitForCalls(c, 3)
+	s.stub.CheckCallNames(c, "Open", "Open", "Send")
+	c.Assert(c.GetTestLog(), jc.Contains, "dropping 1 audit records: connecting to syslog: no route to host")
+}
+
+func (s *SyslogSuite) TestReconnectsAfterSendError(c *gc.C) {
+	target := s.newSyslog(c, 0)
+	defer target.Close()
+	s.stub.SetErrors(nil, errors.New("connection reset"))
+	err := target.AddRequest(auditlog.Request{ConversationID: "0123456789abcdef"})
+	c.Assert(err, jc.ErrorIsNil)
+	s.waitForCalls(c, 3)
+
+	err = target.AddRequest(auditlog.Request{ConversationID: "0123456789abcdef"})
+	c.Assert(err, jc.ErrorIsNil)
+	s.waitForCalls(c, 5)
+	s.stub.CheckCallNames(c, "Open", "Send", "Close", "Open", "Send")
+	c.Assert(c.GetTestLog(), jc.Contains, "dropping 1 audit records: sending audit records to syslog: connection reset")
+}
+
+func (s *SyslogSuite) TestSendTimeout(c *gc.C) {
+	s.sender.block = make(chan struct{})
+	target := s.newSyslog(c, 0)
+	defer target.Close()
+
+	err := target.AddRequest(auditlog.Request{ConversationID: "0123456789abcdef"})
+	c.Assert(err, jc.ErrorIsNil)
+	s.waitForCalls(c, 2)
+
+	// The send is abandoned, and the connection closed, once the
+	// timeout passes.
+	err = s.clock.WaitAdvance(10*time.Second, coretesting.LongWait, 1)
+	c.Assert(err, jc.ErrorIsNil)
+	s.waitForCalls(c, 3)
+	close(s.sender.block)
+
+	err = target.AddRequest(auditlog.Request{ConversationID: "0123456789abcdef"})
+	c.Assert(err, jc.ErrorIsNil)
+	s.waitForCalls(c, 5)
+	s.stub.CheckCallNames(c, "Open", "Send", "Close", "Open", "Send")
+	c.Assert(c.GetTestLog(), jc.Contains, "dropping 1 audit records: sending audit records to syslog: timed out after 10s")
+}
+
+func (s *SyslogSuite) TestDropsRecordsWhenQueueFull(c *gc.C) {
+	s.sender.block = make(chan struct{})
+	target := s.newSyslog(c, 2)
+
+	// The first record is taken off the queue and its send is held
+	// up by the syslog host.
+	err := target.AddRequest(auditlog.Request{ConversationID: "0123456789abcdef", RequestID: 0})
+	c.Assert(err, jc.ErrorIsNil)
+	s.waitForCalls(c, 2)
+
+	// Only MaxPending more records are queued while it's blocked;
+	// the rest are dropped without blocking.
+	for i := 1; i < 6; i++ {
+		err = target.AddRequest(auditlog.Request{ConversationID: "0123456789abcdef", RequestID: uint64(i)})
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	close(s.sender.block)
+	c.Assert(target.Close(), jc.ErrorIsNil)
+
+	var messages []string
+	for _, call := range s.stub.Calls() {
+		if call.FuncName != "Send" {
+			continue
+		}
+		for _, record := range call.Args[0].([]logfwd.Record) {
+			messages = append(messages, record.Message)
+		}
+	}
+	c.Assert(messages, gc.HasLen, 3)
+	for i, message := range messages {
+		c.Check(message, jc.Contains, fmt.Sprintf(`"request-id":%d,`, i))
+	}
+	c.Assert(c.GetTestLog(), jc.Contains, "audit syslog queue full, dropped 3 audit records")
+}
+
+func (s *SyslogSuite) TestCloseWithoutConnecting(c *gc.C) {
+	target := s.newSyslog(c, 0)
+	err := target.Close()
+	c.Assert(err, jc.ErrorIsNil)
+	s.stub.CheckNoCalls(c)
+}
+
+func (s *SyslogSuite) waitForCalls(c *gc.C, count int) {
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		if len(s.stub.Calls()) >= count {
+			return
+		}
+	}
+	c.Fatalf("timed out waiting for %d calls", count)
+}
+
+type fakeSender struct {
+	stub *testing.Stub
+
+	// If block is set, sends wait until it is closed.
+	block chan struct{}
+}
+
+func (s *fakeSender) Send(records []logfwd.Record) error {
+	s.stub.AddCall("Send", records)
+	if s.block != nil {
+		<-s.block
+	}
+	return s.stub.NextErr()
+}
+
+func (s *fakeSender) Close() error {
+	s.stub.AddCall("Close")
+	return s.stub.NextErr()
+}
@@ -0,0 +1,321 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package auditlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/juju/clock"
+	"github.com/juju/errors"
+	"github.com/juju/retry"
+)
+
+// defaultPendingBatches is the number of batches queued by a webhook
+// sink when MaxPending isn't set.
+const defaultPendingBatches = 100
+
+// HTTPClient is the subset of *http.Client needed by the webhook
+// sink.
+type HTTPClient interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// WebhookConfig holds the parameters for an audit log sink that posts
+// batches of records to an HTTP endpoint.
+type WebhookConfig struct {
+	// URL is the endpoint batches are posted to.
+	URL string
+
+	// BatchSize is the number of records collected before they are
+	// posted.
+	BatchSize int
+
+	// FlushInterval is the longest time a record will be held before
+	// being posted, even if the batch isn't full. If it is zero,
+	// partial batches are only posted when the sink is closed.
+	FlushInterval time.Duration
+
+	// Attempts is the number of times posting a batch is tried
+	// before the batch is dropped.
+	Attempts int
+
+	// RetryDelay is the initial delay between attempts; it doubles
+	// after each failure.
+	RetryDelay time.Duration
+
+	// MaxPending is the most records that will be queued waiting to
+	// be posted; records arriving when the queue is full are
+	// dropped. If it is zero, room for 100 batches is allowed.
+	MaxPending int
+
+	// Client is used to make the HTTP requests.
+	Client HTTPClient
+
+	// Clock is used for flush and retry timing.
+	Clock clock.Clock
+}
+
+// Validate checks the webhook configuration.
+func (cfg WebhookConfig) Validate() error {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return errors.Annotate(err, "parsing webhook URL")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.NotValidf("webhook URL %q", cfg.URL)
+	}
+	if cfg.BatchSize <= 0 {
+		return errors.NotValidf("non-positive BatchSize")
+	}
+	if cfg.FlushInterval < 0 {
+		return errors.NotValidf("negative FlushInterval")
+	}
+	if cfg.Attempts <= 0 {
+		return errors.NotValidf("non-positive Attempts")
+	}
+	if cfg.RetryDelay <= 0 {
+		return errors.NotValidf("non-positive RetryDelay")
+	}
+	if cfg.MaxPending < 0 {
+		return errors.NotValidf("negative MaxPending")
+	}
+	if cfg.Client == nil {
+		return errors.NotValidf("nil Client")
+	}
+	if cfg.Clock == nil {
+		return errors.NotValidf("nil Clock")
+	}
+	return nil
+}
+
+type auditLogWebhook struct {
+	config WebhookConfig
+
+	// mu guards pending and dropped.
+	mu      sync.Mutex
+	pending []Record
+	dropped int
+
+	// ready is signalled when a full batch is waiting to be posted.
+	ready chan struct{}
+
+	// closeMu guards closing stop.
+	closeMu sync.Mutex
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewWebhook returns an audit entry sink which posts records to an
+// HTTP endpoint as a JSON array, in batches of up to BatchSize
+// records. Records are posted in the background so that a slow or
+// unavailable endpoint never holds up API requests; failed posts are
+// retried with exponential backoff, and records that arrive while
+// MaxPending records are already waiting are dropped.
+func NewWebhook(config WebhookConfig) (AuditLog, error) {
+	if err := config.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if config.MaxPending == 0 {
+		config.MaxPending = defaultPendingBatches * config.BatchSize
+	}
+	a := &auditLogWebhook{
+		config: config,
+		ready:  make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go a.loop()
+	return a, nil
+}
+
+// AddConversation implements AuditLog.
+func (a *auditLogWebhook) AddConversation(c Conversation) error {
+	a.addRecord(Record{Conversation: &c})
+	return nil
+}
+
+// AddRequest implements AuditLog.
+func (a *auditLogWebhook) AddRequest(m Request) error {
+	a.addRecord(Record{Request: &m})
+	return nil
+}
+
+// AddResponse implements AuditLog.
+func (a *auditLogWebhook) AddResponse(m ResponseErrors) error {
+	a.addRecord(Record{Errors: &m})
+	return nil
+}
+
+// Close implements AuditLog. One attempt is made to post any records
+// that haven't been sent yet before Close returns.
+func (a *auditLogWebhook) Close() error {
+	a.closeMu.Lock()
+	defer a.closeMu.Unlock()
+	if a.closed() {
+		return nil
+	}
+	close(a.stop)
+	<-a.done
+	a.reportDropped()
+	for {
+		batch := a.nextBatch(true)
+		if batch == nil {
+			return nil
+		}
+		if err := a.send(batch, 1, nil); err != nil {
+			return errors.Trace(err)
+		}
+	}
+}
+
+// addRecord queues the record to be posted. It never blocks on the
+// endpoint: if the queue is full, or the sink has been closed, the
+// record is dropped and counted.
+func (a *auditLogWebhook) addRecord(r Record) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed() || len(a.pending) >= a.config.MaxPending {
+		a.dropped++
+		return
+	}
+	a.pending = append(a.pending, r)
+	if len(a.pending) >= a.config.BatchSize {
+		select {
+		case a.ready <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (a *auditLogWebhook) closed() bool {
+	select {
+	case <-a.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+func (a *auditLogWebhook) loop() {
+	defer close(a.done)
+	var (
+		timer   clock.Timer
+		timeout <-chan time.Time
+	)
+	if a.config.FlushInterval > 0 {
+		timer = a.config.Clock.NewTimer(a.config.FlushInterval)
+		defer timer.Stop()
+		timeout = timer.Chan()
+	}
+	for {
+		select {
+		case <-a.stop:
+			return
+		case <-a.ready:
+			a.postBatches(false)
+		case <-timeout:
+			a.postBatches(true)
+			timer.Reset(a.config.FlushInterval)
+		}
+	}
+}
+
+// postBatches posts the pending records in batches of BatchSize. A
+// final partial batch is only posted if partial is true.
+func (a *auditLogWebhook) postBatches(partial bool) {
+	a.reportDropped()
+	for !a.closed() {
+		batch := a.nextBatch(partial)
+		if batch == nil {
+			return
+		}
+		if err := a.send(batch, a.config.Attempts, a.stop); err != nil {
+			logger.Errorf("posting audit records: %v", err)
+		}
+	}
+}
+
+// nextBatch removes and returns the next batch of pending records, or
+// nil if there isn't one to send.
+func (a *auditLogWebhook) nextBatch(partial bool) []Record {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	n := len(a.pending)
+	if n == 0 || (n < a.config.BatchSize && !partial) {
+		return nil
+	}
+	if n > a.config.BatchSize {
+		n = a.config.BatchSize
+	}
+	batch := make([]Record, n)
+	copy(batch, a.pending)
+	a.pending = a.pending[n:]
+	return batch
+}
+
+// reportDropped logs the number of records dropped since it was last
+// called.
+func (a *auditLogWebhook) reportDropped() {
+	a.mu.Lock()
+	dropped := a.dropped
+	a.dropped = 0
+	a.mu.Unlock()
+	if dropped > 0 {
+		logger.Warningf("audit webhook %s queue full, dropped %d audit records", a.config.URL, dropped)
+	}
+}
+
+// send posts the batch, making up to attempts tries.
+func (a *auditLogWebhook) send(batch []Record, attempts int, stop <-chan struct{}) error {
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	err = retry.Call(retry.CallArgs{
+		Attempts:    attempts,
+		Delay:       a.config.RetryDelay,
+		BackoffFunc: retry.DoubleDelay,
+		Clock:       a.config.Clock,
+		Stop:        stop,
+		Func: func() error {
+			return a.post(body)
+		},
+		NotifyFunc: func(err error, attempt int) {
+			logger.Debugf("posting %d audit records to %s, attempt %d: %v", len(batch), a.config.URL, attempt, err)
+		},
+	})
+	if err != nil {
+		if lastErr := retry.LastError(err); lastErr != nil {
+			err = lastErr
+		}
+		return errors.Annotatef(err, "dropping %d audit records", len(batch))
+	}
+	return nil
+}
+
+func (a *auditLogWebhook) post(body []byte) error {
+	req, err := http.NewRequest("POST", a.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := a.config.Client.Do(req)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer resp.Body.Close()
+	// Drain the body so the connection can be reused.
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package auditlog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/juju/clock/testclock"
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/core/auditlog"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type WebhookSuite struct {
+	testing.IsolationSuite
+
+	client *fakeHTTPClient
+	clock  *testclock.Clock
+	config auditlog.WebhookConfig
+}
+
+var _ = gc.Suite(&WebhookSuite{})
+
+func (s *WebhookSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.client = &fakeHTTPClient{}
+	s.clock = testclock.NewClock(time.Now())
+	s.config = auditlog.WebhookConfig{
+		URL:        "https://audit.example.com/records",
+		BatchSize:  2,
+		Attempts:   3,
+		RetryDelay: time.Second,
+		Client:     s.client,
+		Clock:      s.clock,
+	}
+}
+
+func (s *WebhookSuite) TestValidate(c *gc.C) {
+	for i, test := range []struct {
+		modify func(*auditlog.WebhookConfig)
+		err    string
+	}{{
+		modify: func(cfg *auditlog.WebhookConfig) { cfg.URL = "ftp://audit.example.com" },
+		err:    `webhook URL "ftp://audit.example.com" not valid`,
+	}, {
+		modify: func(cfg *auditlog.WebhookConfig) { cfg.BatchSize = 0 },
+		err:    "non-positive BatchSize not valid",
+	}, {
+		modify: func(cfg *auditlog.WebhookConfig) { cfg.FlushInterval = -time.Second },
+		err:    "negative FlushInterval not valid",
+	}, {
+		modify: func(cfg *auditlog.WebhookConfig) { cfg.Attempts = 0 },
+		err:    "non-positive Attempts not valid",
+	}, {
+		modify: func(cfg *auditlog.WebhookConfig) { cfg.RetryDelay = 0 },
+		err:    "non-positive RetryDelay not valid",
+	}, {
+		modify: func(cfg *auditlog.WebhookConfig) { cfg.MaxPending = -1 },
+		err:    "negative MaxPending not valid",
+	}, {
+		modify: func(cfg *auditlog.WebhookConfig) { cfg.Client = nil },
+		err:    "nil Client not valid",
+	}, {
+		modify: func(cfg *auditlog.WebhookConfig) { cfg.Clock = nil },
+		err:    "nil Clock not valid",
+	}} {
+		c.Logf("test %d", i)
+		config := s.config
+		test.modify(&config)
+		_, err := auditlog.NewWebhook(config)
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *WebhookSuite) TestPostsFullBatches(c *gc.C) {
+	target, err := auditlog.NewWebhook(s.config)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = target.AddConversation(auditlog.Conversation{ConversationID: "0123456789abcdef"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.client.batches(), gc.HasLen, 0)
+
+	err = target.AddRequest(auditlog.Request{ConversationID: "0123456789abcdef", RequestID: 1})
+	c.Assert(err, jc.ErrorIsNil)
+	batches := s.waitForBatches(c, 1)
+	c.Assert(batches, gc.HasLen, 1)
+	c.Assert(batches[0], gc.HasLen, 2)
+	c.Assert(batches[0][0].Conversation.ConversationID, gc.Equals, "0123456789abcdef")
+	c.Assert(batches[0][1].Request.RequestID, gc.Equals, uint64(1))
+
+	// Partial batches are sent on close.
+	err = target.AddResponse(auditlog.ResponseErrors{ConversationID: "0123456789abcdef", RequestID: 1})
+	c.Assert(err, jc.ErrorIsNil)
+	err = target.Close()
+	c.Assert(err, jc.ErrorIsNil)
+	batches = s.client.batches()
+	c.Assert(batches, gc.HasLen, 2)
+	c.Assert(batches[1], gc.HasLen, 1)
+	c.Assert(batches[1][0].Errors.RequestID, gc.Equals, uint64(1))
+
+	req := s.client.requests[0]
+	c.Assert(req.Method, gc.Equals, "POST")
+	c.Assert(req.URL.String(), gc.Equals, "https://audit.example.com/records")
+	c.Assert(req.Header.Get("Content-Type"), gc.Equals, "application/json")
+}
+
+func (s *WebhookSuite) TestFlushInterval(c *gc.C) {
+	s.config.FlushInterval = 5 * time.Second
+	target, err := auditlog.NewWebhook(s.config)
+	c.Assert(err, jc.ErrorIsNil)
+	defer target.Close()
+
+	err = target.AddConversation(auditlog.Conversation{ConversationID: "0123456789abcdef"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.clock.WaitAdvance(5*time.Second, coretesting.LongWait, 1)
+	c.Assert(err, jc.ErrorIsNil)
+	batches := s.waitForBatches(c, 1)
+	c.Assert(batches, gc.HasLen, 1)
+	c.Assert(batches[0], gc.HasLen, 1)
+}
+
+func (s *WebhookSuite) TestRetriesFailedPosts(c *gc.C) {
+	s.client.statuses = []int{http.StatusServiceUnavailable, http.StatusOK}
+	target, err := auditlog.NewWebhook(s.config)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = target.AddConversation(auditlog.Conversation{ConversationID: "0123456789abcdef"})
+	c.Assert(err, jc.ErrorIsNil)
+	// Adding the record that fills the batch doesn't wait for the
+	// post, or the retry.
+	err = target.AddRequest(auditlog.Request{ConversationID: "0123456789abcdef"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.clock.WaitAdvance(time.Second, coretesting.LongWait, 1)
+	c.Assert(err, jc.ErrorIsNil)
+	batches := s.waitForBatches(c, 2)
+	c.Assert(batches, gc.HasLen, 2)
+	c.Assert(batches[0], gc.DeepEquals, batches[1])
+	c.Assert(target.Close(), jc.ErrorIsNil)
+}
+
+func (s *WebhookSuite) TestGivesUpAfterAttempts(c *gc.C) {
+	s.config.Attempts = 1
+	s.client.statuses = []int{http.StatusInternalServerError}
+	target, err := auditlog.NewWebhook(s.config)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = target.AddConversation(auditlog.Conversation{ConversationID: "0123456789abcdef"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = target.AddRequest(auditlog.Request{ConversationID: "0123456789abcdef"})
+	c.Assert(err, jc.ErrorIsNil)
+	s.waitForBatches(c, 1)
+
+	// The failed batch has been dropped, so there's nothing left to
+	// send on close.
+	c.Assert(target.Close(), jc.ErrorIsNil)
+	c.Assert(s.client.batches(), gc.HasLen, 1)
+}
+
+func (s *WebhookSuite) TestDropsRecordsWhenQueueFull(c *gc.C) {
+	s.config.BatchSize = 2
+	s.config.MaxPending = 3
+	s.client.block = make(chan struct{})
+	target, err := auditlog.NewWebhook(s.config)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The first full batch is taken off the queue and its post is
+	// held up by the endpoint.
+	for i := 0; i < 2; i++ {
+		err = target.AddRequest(auditlog.Request{ConversationID: "0123456789abcdef", RequestID: uint64(i)})
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	s.client.waitForRequest(c)
+
+	// Only MaxPending more records are queued while it's blocked;
+	// the rest are dropped without blocking.
+	for i := 2; i < 10; i++ {
+		err = target.AddRequest(auditlog.Request{ConversationID: "0123456789abcdef", RequestID: uint64(i)})
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	close(s.client.block)
+	c.Assert(target.Close(), jc.ErrorIsNil)
+
+	var ids []uint64
+	for _, batch := range s.client.batches() {
+		for _, record := range batch {
+			ids = append(ids, record.Request.RequestID)
+		}
+	}
+	c.Assert(ids, jc.DeepEquals, []uint64{0, 1, 2, 3, 4})
+}
+
+func (s *WebhookSuite) waitForBatches(c *gc.C, count int) [][]auditlog.Record {
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		if batches := s.client.batches(); len(batches) >= count {
+			return batches
+		}
+	}
+	c.Fatalf("timed out waiting for %d batches to be posted", count)
+	return nil
+}
+
+type fakeHTTPClient struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+	statuses []int
+
+	// If block is set, requests wait until it is closed.
+	block   chan struct{}
+	started bool
+}
+
+func (f *fakeHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	if f.block != nil {
+		f.mu.Lock()
+		f.started = true
+		f.mu.Unlock()
+		<-f.block
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	f.requests = append(f.requests, req)
+	f.bodies = append(f.bodies, body)
+	status := http.StatusOK
+	if len(f.statuses) > 0 {
+		status, f.statuses = f.statuses[0], f.statuses[1:]
+	}
+	return &http.Response{
+		Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode: status,
+		Body:       ioutil.NopCloser(&bytes.Buffer{}),
+	}, nil
+}
+
+// waitForRequest waits until a blocked request has been started.
+func (f *fakeHTTPClient) waitForRequest(c *gc.C) {
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		f.mu.Lock()
+		started := f.started
+		f.mu.Unlock()
+		if started {
+			return
+		}
+	}
+	c.Fatalf("timed out waiting for request")
+}
+
+// batches returns the records that were posted, one slice for each
+// request.
+func (f *fakeHTTPClient) batches() [][]auditlog.Record {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var result [][]auditlog.Record
+	for _, body := range f.bodies {
+		var records []auditlog.Record
+		if err := json.Unmarshal(body, &records); err != nil {
+			panic(err)
+		}
+		result = append(result, records)
+	}
+	return result
+}
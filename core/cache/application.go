@@ -0,0 +1,191 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cache
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/juju/pubsub"
+
+	"github.com/juju/juju/core/life"
+	"github.com/juju/juju/core/settings"
+)
+
+const applicationConfigChange = "application-config-change"
+
+func newApplication(model *Model, metrics *ControllerGauges, hub *pubsub.SimpleHub) *Application {
+	return &Application{
+		model:   model,
+		metrics: metrics,
+		hub:     hub,
+	}
+}
+
+// Application is a cached application in a model. The application is
+// kept up to date with changes flowing into the cached controller.
+type Application struct {
+	model   *Model
+	metrics *ControllerGauges
+	hub     *pubsub.SimpleHub
+	mu      sync.Mutex
+
+	details    ApplicationChange
+	configHash string
+	hashCache  *configHashCache
+}
+
+// Name returns the name of the application.
+func (a *Application) Name() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.details.Name
+}
+
+// CharmURL returns the URL of the application's charm.
+func (a *Application) CharmURL() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.details.CharmURL
+}
+
+// Life returns the life of the application.
+func (a *Application) Life() life.Value {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.details.Life
+}
+
+// Config returns the current charm config settings of the application.
+// Only values that have been set are included; charm defaults are not.
+func (a *Application) Config() map[string]interface{} {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.metrics.ApplicationConfigReads.Inc()
+	return a.details.Config
+}
+
+// topic prefixes the topic with the model UUID and application name.
+func (a *Application) topic(topic string) string {
+	return a.details.ModelUUID + ":" + a.details.Name + ":" + topic
+}
+
+func (a *Application) setDetails(details ApplicationChange) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.details = details
+
+	hashCache, configHash := newConfigHashCache(
+		a.metrics.ApplicationHashCacheHit, a.metrics.ApplicationHashCacheMiss,
+		charmConfigHashFunc(details.Name, details.CharmURL), details.Config)
+	if configHash != a.configHash {
+		a.configHash = configHash
+		a.hashCache = hashCache
+		a.hub.Publish(a.topic(applicationConfigChange), applicationConfig{
+			charmURL:  details.CharmURL,
+			hashCache: hashCache,
+		})
+	}
+}
+
+// applicationConfig is published when an application's config
+// changes.
+type applicationConfig struct {
+	charmURL  string
+	hashCache *configHashCache
+}
+
+// charmConfigHashFunc returns a function hashing the application's
+// charm config exactly as the state settings watchers do, so that
+// unit agents see the same hash from the cache and the database. The
+// name must match the key of the settings document in state.
+func charmConfigHashFunc(appName, charmURL string) hashFunc {
+	name := fmt.Sprintf("a#%s#%s", appName, charmURL)
+	return func(config map[string]interface{}) (string, error) {
+		return settings.Hash(name, config)
+	}
+}
+
+// WatchConfig creates a watcher for the application's charm config.
+// The watcher yields a hash of the config whenever it changes, so that
+// the recipient can tell whether it has already seen the values. If
+// keys are specified, only changes to the values of those keys are
+// reported. The hash is that of the config for the application's
+// current charm; once the charm changes the watcher reports nothing
+// further, since units still running the old charm see its config.
+func (a *Application) WatchConfig(keys ...string) StringsWatcher {
+	sort.Strings(keys)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var hash string
+	if a.hashCache != nil {
+		hash = a.hashCache.getHash(keys)
+	}
+	w := &applicationConfigWatcher{
+		configHashWatcher: configHashWatcher{
+			stringsWatcherBase: newStringsWatcherBase(a.metrics, true, []string{hash}),
+			keys:               keys,
+			hash:               hash,
+		},
+		charmURL: a.details.CharmURL,
+	}
+	unsub := a.hub.Subscribe(a.topic(applicationConfigChange), w.configChanged)
+	w.run(unsub)
+	return w
+}
+
+// applicationConfigWatcher is a configHashWatcher for the config of
+// one charm of an application.
+type applicationConfigWatcher struct {
+	configHashWatcher
+	charmURL string
+}
+
+func (w *applicationConfigWatcher) configChanged(topic string, value interface{}) {
+	config, ok := value.(applicationConfig)
+	if !ok {
+		logger.Errorf("programming error, value not an applicationConfig")
+		return
+	}
+	if config.charmURL != w.charmURL {
+		return
+	}
+	w.configHashWatcher.configChanged(topic, config.hashCache)
+}
+
+// WatchUnits creates a watcher for the lifecycle of the application's
+// units. The watcher yields the names of units that have been added,
+// have changed life, or have been removed.
+func (a *Application) WatchUnits() StringsWatcher {
+	prefix := a.Name() + "/"
+	return a.model.watchUnitsLife(func(name string) bool {
+		return strings.HasPrefix(name, prefix)
+	})
+}
+
+// configHashWatcher yields the hash of a config whenever the values
+// of the keys it is interested in change.
+type configHashWatcher struct {
+	*stringsWatcherBase
+	keys []string
+	hash string
+}
+
+func (w *configHashWatcher) configChanged(topic string, value interface{}) {
+	hashCache, ok := value.(*configHashCache)
+	if !ok {
+		logger.Errorf("programming error, value not a *configHashCache")
+		return
+	}
+	hash := hashCache.getHash(w.keys)
+	if hash == w.hash {
+		// Nothing that we care about has changed, so we're done.
+		return
+	}
+	w.hash = hash
+	w.notify(hash)
+}
@@ -0,0 +1,213 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cache_test
+
+import (
+	"github.com/juju/loggo"
+	"github.com/juju/pubsub"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/worker.v1/workertest"
+
+	"github.com/juju/juju/core/cache"
+	"github.com/juju/juju/core/life"
+	"github.com/juju/juju/core/settings"
+	"github.com/juju/juju/core/status"
+)
+
+type ApplicationSuite struct {
+	testing.IsolationSuite
+
+	gauges *cache.ControllerGauges
+	model  *cache.Model
+}
+
+var _ = gc.Suite(&ApplicationSuite{})
+
+func (s *ApplicationSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.gauges = cache.CreateControllerGauges()
+	hub := pubsub.NewSimpleHub(&pubsub.SimpleHubConfig{
+		Logger: loggo.GetLogger("test"),
+	})
+	s.model = cache.NewModel(s.gauges, hub)
+	s.model.SetDetails(modelChange)
+}
+
+func (s *ApplicationSuite) newApplication(c *gc.C) *cache.Application {
+	s.model.UpdateApplication(appChange)
+	app, err := s.model.Application(appChange.Name)
+	c.Assert(err, jc.ErrorIsNil)
+	return app
+}
+
+func (s *ApplicationSuite) TestDetails(c *gc.C) {
+	app := s.newApplication(c)
+	c.Check(app.Name(), gc.Equals, "wordpress")
+	c.Check(app.CharmURL(), gc.Equals, "cs:wordpress-4")
+	c.Check(app.Life(), gc.Equals, life.Alive)
+	c.Check(app.Config(), jc.DeepEquals, map[string]interface{}{
+		"key":     "value",
+		"another": "foo",
+	})
+	c.Check(testutil.ToFloat64(s.gauges.ApplicationConfigReads), gc.Equals, float64(1))
+}
+
+func (s *ApplicationSuite) TestNotFound(c *gc.C) {
+	_, err := s.model.Application("mysql")
+	c.Check(err, gc.ErrorMatches, `application "mysql" not found`)
+}
+
+func (s *ApplicationSuite) TestConfigWatcherStops(c *gc.C) {
+	app := s.newApplication(c)
+	w := app.WatchConfig()
+	wc := NewStringsWatcherC(c, w)
+	// Sends initial event.
+	c.Check(wc.NextChange(), gc.HasLen, 1)
+	wc.AssertStops()
+	c.Check(testutil.ToFloat64(s.gauges.Watchers), gc.Equals, float64(0))
+}
+
+func (s *ApplicationSuite) TestConfigWatcherChange(c *gc.C) {
+	app := s.newApplication(c)
+	w := app.WatchConfig()
+	defer workertest.CleanKill(c, w)
+	wc := NewStringsWatcherC(c, w)
+	initial := wc.NextChange()
+	c.Assert(initial, gc.HasLen, 1)
+
+	change := appChange
+	change.Config = map[string]interface{}{
+		"key": "changed",
+	}
+	s.model.UpdateApplication(change)
+	changed := wc.NextChange()
+	c.Assert(changed, gc.HasLen, 1)
+	c.Check(changed[0], gc.Not(gc.Equals), initial[0])
+	wc.AssertNoChange()
+
+	// Setting the same config again is not a change.
+	s.model.UpdateApplication(change)
+	wc.AssertNoChange()
+
+	// Reverting yields the original hash.
+	s.model.UpdateApplication(appChange)
+	wc.AssertOneChange(initial[0])
+}
+
+func (s *ApplicationSuite) TestConfigWatcherHashMatchesState(c *gc.C) {
+	app := s.newApplication(c)
+	w := app.WatchConfig()
+	defer workertest.CleanKill(c, w)
+	wc := NewStringsWatcherC(c, w)
+
+	// The state settings watchers hash the settings document, named
+	// by its key, so the cache must do the same.
+	expected, err := settings.Hash("a#wordpress#cs:wordpress-4", appChange.Config)
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange(expected)
+}
+
+func (s *ApplicationSuite) TestConfigWatcherIgnoresOtherCharms(c *gc.C) {
+	app := s.newApplication(c)
+	w := app.WatchConfig()
+	defer workertest.CleanKill(c, w)
+	wc := NewStringsWatcherC(c, w)
+	c.Assert(wc.NextChange(), gc.HasLen, 1)
+
+	// Units running the old charm still see its config, so a
+	// new charm's config isn't a change.
+	change := appChange
+	change.CharmURL = "cs:wordpress-5"
+	change.Config = map[string]interface{}{
+		"key": "changed",
+	}
+	s.model.UpdateApplication(change)
+	wc.AssertNoChange()
+}
+
+func (s *ApplicationSuite) TestConfigWatcherOneValueOtherChange(c *gc.C) {
+	app := s.newApplication(c)
+	w := app.WatchConfig("key")
+	defer workertest.CleanKill(c, w)
+	wc := NewStringsWatcherC(c, w)
+	c.Assert(wc.NextChange(), gc.HasLen, 1)
+
+	change := appChange
+	change.Config = map[string]interface{}{
+		"key":     "value",
+		"another": "changed",
+	}
+	s.model.UpdateApplication(change)
+	wc.AssertNoChange()
+}
+
+func (s *ApplicationSuite) TestWatchUnits(c *gc.C) {
+	app := s.newApplication(c)
+	s.model.UpdateUnit(unitChange)
+	other := unitChange
+	other.Name = "mysql/0"
+	other.Application = "mysql"
+	s.model.UpdateUnit(other)
+
+	w := app.WatchUnits()
+	defer workertest.CleanKill(c, w)
+	wc := NewStringsWatcherC(c, w)
+	wc.AssertOneChange("wordpress/0")
+
+	// A change that isn't to the unit's life is not reported.
+	change := unitChange
+	change.WorkloadStatus = status.StatusInfo{Status: status.Active}
+	s.model.UpdateUnit(change)
+	wc.AssertNoChange()
+
+	added := unitChange
+	added.Name = "wordpress/1"
+	s.model.UpdateUnit(added)
+	wc.AssertOneChange("wordpress/1")
+
+	change.Life = life.Dying
+	s.model.UpdateUnit(change)
+	wc.AssertOneChange("wordpress/0")
+
+	s.model.RemoveUnit(cache.RemoveUnit{ModelUUID: "model-uuid", Name: "wordpress/1"})
+	wc.AssertOneChange("wordpress/1")
+
+	// Units of other applications are not reported.
+	other.Life = life.Dying
+	s.model.UpdateUnit(other)
+	wc.AssertNoChange()
+}
+
+func (s *ApplicationSuite) TestWatchUnitsNoUnits(c *gc.C) {
+	app := s.newApplication(c)
+	w := app.WatchUnits()
+	defer workertest.CleanKill(c, w)
+	wc := NewStringsWatcherC(c, w)
+	// The initial event is sent even though it is empty.
+	wc.AssertOneChange()
+	c.Check(testutil.ToFloat64(s.gauges.Watchers), gc.Equals, float64(1))
+}
+
+var appChange = cache.ApplicationChange{
+	ModelUUID: "model-uuid",
+	Name:      "wordpress",
+	CharmURL:  "cs:wordpress-4",
+	Life:      life.Alive,
+	Config: map[string]interface{}{
+		"key":     "value",
+		"another": "foo",
+	},
+}
+
+var unitChange = cache.UnitChange{
+	ModelUUID:   "model-uuid",
+	Name:        "wordpress/0",
+	Application: "wordpress",
+	CharmURL:    "cs:wordpress-4",
+	MachineId:   "0",
+	Life:        life.Alive,
+}
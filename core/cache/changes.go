@@ -24,3 +24,87 @@ type ModelChange struct {
 type RemoveModel struct {
 	ModelUUID string
 }
+
+// ApplicationChange represents either a new application, or a change
+// to an existing application in a model.
+type ApplicationChange struct {
+	ModelUUID       string
+	Name            string
+	Exposed         bool
+	CharmURL        string
+	Life            life.Value
+	MinUnits        int
+	Config          map[string]interface{}
+	Subordinate     bool
+	Status          status.StatusInfo
+	WorkloadVersion string
+}
+
+// RemoveApplication represents the situation when an application
+// is removed from a model in the database.
+type RemoveApplication struct {
+	ModelUUID string
+	Name      string
+}
+
+// UnitChange represents either a new unit, or a change
+// to an existing unit in a model.
+type UnitChange struct {
+	ModelUUID      string
+	Name           string
+	Application    string
+	Series         string
+	CharmURL       string
+	PublicAddress  string
+	PrivateAddress string
+	MachineId      string
+	Life           life.Value
+	Subordinate    bool
+	WorkloadStatus status.StatusInfo
+	AgentStatus    status.StatusInfo
+}
+
+// RemoveUnit represents the situation when a unit
+// is removed from a model in the database.
+type RemoveUnit struct {
+	ModelUUID string
+	Name      string
+}
+
+// MachineChange represents either a new machine, or a change
+// to an existing machine in a model.
+type MachineChange struct {
+	ModelUUID      string
+	Id             string
+	InstanceId     string
+	Life           life.Value
+	Series         string
+	AgentStatus    status.StatusInfo
+	InstanceStatus status.StatusInfo
+	HasVote        bool
+	WantsVote      bool
+}
+
+// RemoveMachine represents the situation when a machine
+// is removed from a model in the database.
+type RemoveMachine struct {
+	ModelUUID string
+	Id        string
+}
+
+// CharmChange represents either a new charm, or a change
+// to an existing charm in a model.
+type CharmChange struct {
+	ModelUUID string
+	CharmURL  string
+	// DefaultConfig holds the default values of the charm's
+	// config options.
+	DefaultConfig map[string]interface{}
+}
+
+// RemoveCharm represents the situation when a charm
+// is removed from a model in the database.
+type RemoveCharm struct {
+	ModelUUID string
+	CharmURL  string
+}
@@ -0,0 +1,41 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cache
+
+import (
+	"sync"
+)
+
+func newCharm() *Charm {
+	return &Charm{}
+}
+
+// Charm is a cached charm in a model. Charms are not part of the
+// change stream from the database, so they are added by the source of
+// the changes when an application starts using a charm.
+type Charm struct {
+	mu      sync.Mutex
+	details CharmChange
+}
+
+// URL returns the charm's URL.
+func (c *Charm) URL() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.details.CharmURL
+}
+
+// DefaultConfig returns the default values of the charm's config
+// options.
+func (c *Charm) DefaultConfig() map[string]interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.details.DefaultConfig
+}
+
+func (c *Charm) setDetails(details CharmChange) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.details = details
+}
@@ -5,7 +5,9 @@ package cache
 
 import (
 	"sync"
+	"time"
 
+	"github.com/juju/clock"
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
 	"github.com/juju/pubsub"
@@ -26,6 +28,10 @@ type ControllerConfig struct {
 	// called by the controller main processing loop after processing a change.
 	// The change processed is passed in as the arg to notify.
 	Notify func(interface{})
+
+	// Clock is used to time the processing of changes, and to
+	// report how long it has been since the last change.
+	Clock clock.Clock
 }
 
 // Validate ensures the controller has the right values to be created.
@@ -33,6 +39,9 @@ func (c *ControllerConfig) Validate() error {
 	if c.Changes == nil {
 		return errors.NotValidf("nil Changes")
 	}
+	if c.Clock == nil {
+		return errors.NotValidf("nil Clock")
+	}
 	return nil
 }
 
@@ -44,6 +53,10 @@ type Controller struct {
 	models  map[string]*Model
 	hub     *pubsub.SimpleHub
 	metrics *ControllerGauges
+
+	// lastChange is the time the most recent change was applied,
+	// or the time the controller started if there have been none.
+	lastChange time.Time
 }
 
 // NewController creates a new cached controller intance.
@@ -60,7 +73,8 @@ func NewController(config ControllerConfig) (*Controller, error) {
 			// TODO: (thumper) add a get child method to loggers.
 			Logger: loggo.GetLogger("juju.core.cache.hub"),
 		}),
-		metrics: createControllerGauges(),
+		metrics:    createControllerGauges(),
+		lastChange: config.Clock.Now(),
 	}
 	c.tomb.Go(c.loop)
 	return c, nil
@@ -72,12 +86,7 @@ func (c *Controller) loop() error {
 		case <-c.tomb.Dying():
 			return nil
 		case change := <-c.config.Changes:
-			switch ch := change.(type) {
-			case ModelChange:
-				c.updateModel(ch)
-			case RemoveModel:
-				c.removeModel(ch)
-			}
+			c.processChange(change)
 			if c.config.Notify != nil {
 				c.config.Notify(change)
 			}
@@ -85,6 +94,63 @@ func (c *Controller) loop() error {
 	}
 }
 
+// processChange applies the change to the cache, recording how long
+// it took.
+func (c *Controller) processChange(change interface{}) {
+	start := c.config.Clock.Now()
+	var changeType string
+	switch ch := change.(type) {
+	case ModelChange:
+		changeType = "model"
+		c.updateModel(ch)
+	case RemoveModel:
+		changeType = "remove-model"
+		c.removeModel(ch)
+	case ApplicationChange:
+		changeType = "application"
+		c.ensureModel(ch.ModelUUID).updateApplication(ch)
+	case RemoveApplication:
+		changeType = "remove-application"
+		c.ensureModel(ch.ModelUUID).removeApplication(ch)
+	case UnitChange:
+		changeType = "unit"
+		c.ensureModel(ch.ModelUUID).updateUnit(ch)
+	case RemoveUnit:
+		changeType = "remove-unit"
+		c.ensureModel(ch.ModelUUID).removeUnit(ch)
+	case MachineChange:
+		changeType = "machine"
+		c.ensureModel(ch.ModelUUID).updateMachine(ch)
+	case RemoveMachine:
+		changeType = "remove-machine"
+		c.ensureModel(ch.ModelUUID).removeMachine(ch)
+	case CharmChange:
+		changeType = "charm"
+		c.ensureModel(ch.ModelUUID).updateCharm(ch)
+	case RemoveCharm:
+		changeType = "remove-charm"
+		c.ensureModel(ch.ModelUUID).removeCharm(ch)
+	default:
+		logger.Debugf("ignoring unknown change type %T", change)
+		return
+	}
+	now := c.config.Clock.Now()
+	c.metrics.ChangesProcessed.WithLabelValues(changeType).Inc()
+	c.metrics.ChangeProcessingTime.Observe(now.Sub(start).Seconds())
+
+	c.mu.Lock()
+	c.lastChange = now
+	c.mu.Unlock()
+}
+
+// lastChangeTime returns the time the most recent change was
+// applied to the cache.
+func (c *Controller) lastChangeTime() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lastChange
+}
+
 // Report returns information that is used in the dependency engine report.
 func (c *Controller) Report() map[string]interface{} {
 	result := make(map[string]interface{})
@@ -132,15 +198,25 @@ func (c *Controller) Model(uuid string) (*Model, error) {
 // updateModel will add or update the model details as
 // described in the ModelChange.
 func (c *Controller) updateModel(ch ModelChange) {
+	c.ensureModel(ch.ModelUUID).setDetails(ch)
+}
+
+// ensureModel returns the model with the specified UUID, adding it
+// to the cache if it isn't there already. Changes to the entities in
+// a model may arrive before the details of the model itself.
+func (c *Controller) ensureModel(uuid string) *Model {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	model, found := c.models[ch.ModelUUID]
+	model, found := c.models[uuid]
 	if !found {
 		model = newModel(c.metrics, c.hub)
-		c.models[ch.ModelUUID] = model
+		// The UUID is needed for the model's topics before the
+		// rest of the details are known.
+		model.details.ModelUUID = uuid
+		c.models[uuid] = model
 	}
-	model.setDetails(ch)
+	return model
 }
 
 // removeModel removes the model from the cache.
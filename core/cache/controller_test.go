@@ -5,9 +5,11 @@ package cache_test
 import (
 	"time"
 
+	"github.com/juju/clock/testclock"
 	"github.com/juju/errors"
 	"github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	gc "gopkg.in/check.v1"
 	"gopkg.in/juju/worker.v1/workertest"
 
@@ -21,6 +23,7 @@ type ControllerSuite struct {
 	gauges *cache.ControllerGauges
 
 	changes chan interface{}
+	clock   *testclock.Clock
 	config  cache.ControllerConfig
 }
 
@@ -29,8 +32,10 @@ var _ = gc.Suite(&ControllerSuite{})
 func (s *ControllerSuite) SetUpTest(c *gc.C) {
 	s.IsolationSuite.SetUpTest(c)
 	s.changes = make(chan interface{})
+	s.clock = testclock.NewClock(time.Date(2018, 10, 1, 12, 0, 0, 0, time.UTC))
 	s.config = cache.ControllerConfig{
 		Changes: s.changes,
+		Clock:   s.clock,
 	}
 }
 
@@ -46,6 +51,13 @@ func (s *ControllerSuite) TestConfigMissingChanges(c *gc.C) {
 	c.Check(err, jc.Satisfies, errors.IsNotValid)
 }
 
+func (s *ControllerSuite) TestConfigMissingClock(c *gc.C) {
+	s.config.Clock = nil
+	err := s.config.Validate()
+	c.Check(err, gc.ErrorMatches, "nil Clock not valid")
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+}
+
 func (s *ControllerSuite) TestController(c *gc.C) {
 	controller, err := cache.NewController(s.config)
 	c.Assert(err, jc.ErrorIsNil)
@@ -87,6 +99,82 @@ func (s *ControllerSuite) TestRemoveModel(c *gc.C) {
 	c.Check(controller.Report(), gc.HasLen, 0)
 }
 
+func (s *ControllerSuite) TestChangeMetrics(c *gc.C) {
+	controller, events := s.new(c)
+	s.processChange(c, modelChange, events)
+	s.processChange(c, modelChange, events)
+	s.processChange(c, cache.RemoveModel{ModelUUID: "model-uuid"}, events)
+
+	metrics := controller.Metrics()
+	c.Check(testutil.ToFloat64(metrics.ChangesProcessed.WithLabelValues("model")), gc.Equals, float64(2))
+	c.Check(testutil.ToFloat64(metrics.ChangesProcessed.WithLabelValues("remove-model")), gc.Equals, float64(1))
+}
+
+func (s *ControllerSuite) TestAddEntities(c *gc.C) {
+	controller, events := s.new(c)
+	s.processChange(c, modelChange, events)
+	s.processChange(c, appChange, events)
+	s.processChange(c, unitChange, events)
+	s.processChange(c, machineChange, events)
+	s.processChange(c, charmChange, events)
+
+	model, err := controller.Model("model-uuid")
+	c.Assert(err, jc.ErrorIsNil)
+	app, err := model.Application("wordpress")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(app.CharmURL(), gc.Equals, "cs:wordpress-4")
+	unit, err := model.Unit("wordpress/0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(unit.Application(), gc.Equals, "wordpress")
+	c.Check(unit.MachineId(), gc.Equals, "0")
+	machine, err := model.Machine("0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(machine.Life(), gc.Equals, life.Alive)
+	charm, err := model.Charm("cs:wordpress-4")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(charm.DefaultConfig(), jc.DeepEquals, map[string]interface{}{
+		"key": "default",
+	})
+}
+
+func (s *ControllerSuite) TestRemoveEntities(c *gc.C) {
+	controller, events := s.new(c)
+	s.processChange(c, modelChange, events)
+	s.processChange(c, appChange, events)
+	s.processChange(c, unitChange, events)
+	s.processChange(c, machineChange, events)
+	s.processChange(c, charmChange, events)
+
+	s.processChange(c, cache.RemoveUnit{ModelUUID: "model-uuid", Name: "wordpress/0"}, events)
+	s.processChange(c, cache.RemoveApplication{ModelUUID: "model-uuid", Name: "wordpress"}, events)
+	s.processChange(c, cache.RemoveMachine{ModelUUID: "model-uuid", Id: "0"}, events)
+	s.processChange(c, cache.RemoveCharm{ModelUUID: "model-uuid", CharmURL: "cs:wordpress-4"}, events)
+
+	model, err := controller.Model("model-uuid")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = model.Application("wordpress")
+	c.Check(err, jc.Satisfies, errors.IsNotFound)
+	_, err = model.Unit("wordpress/0")
+	c.Check(err, jc.Satisfies, errors.IsNotFound)
+	_, err = model.Machine("0")
+	c.Check(err, jc.Satisfies, errors.IsNotFound)
+	_, err = model.Charm("cs:wordpress-4")
+	c.Check(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *ControllerSuite) TestEntityBeforeModel(c *gc.C) {
+	controller, events := s.new(c)
+	s.processChange(c, unitChange, events)
+	c.Check(controller.ModelUUIDs(), jc.SameContents, []string{"model-uuid"})
+
+	// The model's details arriving later don't lose the unit.
+	s.processChange(c, modelChange, events)
+	model, err := controller.Model("model-uuid")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = model.Unit("wordpress/0")
+	c.Check(err, jc.ErrorIsNil)
+}
+
 func (s *ControllerSuite) processChange(c *gc.C, change interface{}, notify <-chan interface{}) {
 	select {
 	case s.changes <- change:
@@ -110,6 +198,14 @@ func (s *ControllerSuite) captureModelEvents(c *gc.C) <-chan interface{} {
 			send = true
 		case cache.RemoveModel:
 			send = true
+		case cache.ApplicationChange, cache.RemoveApplication:
+			send = true
+		case cache.UnitChange, cache.RemoveUnit:
+			send = true
+		case cache.MachineChange, cache.RemoveMachine:
+			send = true
+		case cache.CharmChange, cache.RemoveCharm:
+			send = true
 		default:
 			// no-op
 		}
@@ -134,3 +230,11 @@ func (s *ControllerSuite) nextChange(c *gc.C, changes <-chan interface{}) interf
 	}
 	return obtained
 }
+
+var charmChange = cache.CharmChange{
+	ModelUUID: "model-uuid",
+	CharmURL:  "cs:wordpress-4",
+	DefaultConfig: map[string]interface{}{
+		"key": "default",
+	},
+}
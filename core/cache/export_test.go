@@ -12,3 +12,29 @@ var (
 func (m *Model) SetDetails(details ModelChange) {
 	m.setDetails(details)
 }
+
+// Metrics returns the controller's metrics for testing.
+func (c *Controller) Metrics() *ControllerGauges {
+	return c.metrics
+}
+
+// Expose the entity updates for testing.
+func (m *Model) UpdateApplication(ch ApplicationChange) {
+	m.updateApplication(ch)
+}
+
+func (m *Model) UpdateUnit(ch UnitChange) {
+	m.updateUnit(ch)
+}
+
+func (m *Model) RemoveUnit(ch RemoveUnit) {
+	m.removeUnit(ch)
+}
+
+func (m *Model) UpdateMachine(ch MachineChange) {
+	m.updateMachine(ch)
+}
+
+func (m *Model) RemoveMachine(ch RemoveMachine) {
+	m.removeMachine(ch)
+}
@@ -6,8 +6,11 @@ package cache
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"strings"
+	"sync"
 
 	"github.com/juju/errors"
+	"github.com/prometheus/client_golang/prometheus"
 	"gopkg.in/yaml.v2"
 )
 
@@ -25,3 +28,71 @@ func hash(settings map[string]interface{}) (string, error) {
 	}
 	return hex.EncodeToString(hash.Sum(nil)), nil
 }
+
+// hashFunc returns a hash of the config.
+type hashFunc func(config map[string]interface{}) (string, error)
+
+// configHashCache holds a config map along with the hashes of the
+// subsets of it that watchers are interested in, so that each hash
+// is only generated once per config change.
+type configHashCache struct {
+	hits     prometheus.Gauge
+	misses   prometheus.Gauge
+	hashFunc hashFunc
+	config   map[string]interface{}
+	// The key to the hash map is the stringified keys of the watcher.
+	// They should be sorted and comma delimited.
+	hash map[string]string
+	mu   sync.Mutex
+}
+
+func newConfigHashCache(
+	hits, misses prometheus.Gauge, hashFunc hashFunc, config map[string]interface{},
+) (*configHashCache, string) {
+	configCache := &configHashCache{
+		hits:     hits,
+		misses:   misses,
+		hashFunc: hashFunc,
+		config:   config,
+		hash:     make(map[string]string),
+	}
+	// Generate the hash for the entire config.
+	allHash := configCache.generateHash(nil)
+	configCache.hash[""] = allHash
+	return configCache, allHash
+}
+
+func (c *configHashCache) getHash(keys []string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := strings.Join(keys, ",")
+	value, found := c.hash[key]
+	if found {
+		c.hits.Inc()
+		return value
+	}
+	value = c.generateHash(keys)
+	c.hash[key] = value
+	return value
+}
+
+func (c *configHashCache) generateHash(keys []string) string {
+	// We are generating a hash, so call it a miss.
+	c.misses.Inc()
+
+	interested := c.config
+	if len(keys) > 0 {
+		interested = make(map[string]interface{})
+		for _, key := range keys {
+			if value, found := c.config[key]; found {
+				interested[key] = value
+			}
+		}
+	}
+	h, err := c.hashFunc(interested)
+	if err != nil {
+		logger.Errorf("invariant error - config should be serializable and hashable, %v", err)
+		return ""
+	}
+	return h
+}
@@ -0,0 +1,116 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cache
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/juju/pubsub"
+
+	"github.com/juju/juju/core/instance"
+	"github.com/juju/juju/core/life"
+)
+
+const machineInstanceIDChange = "machine-instance-id-change"
+
+func newMachine(model *Model, metrics *ControllerGauges, hub *pubsub.SimpleHub) *Machine {
+	return &Machine{
+		model:   model,
+		metrics: metrics,
+		hub:     hub,
+	}
+}
+
+// Machine is a cached machine in a model. The machine is kept up to
+// date with changes flowing into the cached controller.
+type Machine struct {
+	model   *Model
+	metrics *ControllerGauges
+	hub     *pubsub.SimpleHub
+	mu      sync.Mutex
+
+	details MachineChange
+}
+
+// Id returns the ID of the machine.
+func (m *Machine) Id() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.details.Id
+}
+
+// InstanceId returns the provider specific instance ID of the machine,
+// which is empty until the machine has been provisioned.
+func (m *Machine) InstanceId() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.details.InstanceId
+}
+
+// Life returns the life of the machine.
+func (m *Machine) Life() life.Value {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.details.Life
+}
+
+// topic prefixes the topic with the model UUID and machine ID.
+func (m *Machine) topic(topic string) string {
+	return m.details.ModelUUID + ":" + m.details.Id + ":" + topic
+}
+
+// setDetails updates the machine, returning the machine's previous life.
+func (m *Machine) setDetails(details MachineChange) life.Value {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	previous := m.details
+	m.details = details
+	if details.InstanceId != previous.InstanceId {
+		m.hub.Publish(m.topic(machineInstanceIDChange), nil)
+	}
+	return previous.Life
+}
+
+// WatchInstanceID creates a watcher that notifies when the instance
+// ID of the machine changes, which is usually when it is provisioned.
+func (m *Machine) WatchInstanceID() NotifyWatcher {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	w := &instanceIDWatcher{
+		notifyWatcherBase: newNotifyWatcherBase(m.metrics),
+	}
+	unsub := m.hub.Subscribe(m.topic(machineInstanceIDChange), w.instanceIDChanged)
+	w.run(unsub)
+	return w
+}
+
+// WatchContainers creates a watcher for the lifecycle of the containers
+// of the given type directly hosted by the machine. If the container
+// type is empty, all containers are watched. The watcher yields the IDs
+// of containers that have been added, have changed life, or have been
+// removed.
+func (m *Machine) WatchContainers(ctype instance.ContainerType) StringsWatcher {
+	prefix := m.Id() + "/"
+	return m.model.watchMachinesLife(func(id string) bool {
+		if !strings.HasPrefix(id, prefix) {
+			return false
+		}
+		// Only direct children, of the form <type>/<number>, are
+		// of interest.
+		parts := strings.Split(id[len(prefix):], "/")
+		if len(parts) != 2 {
+			return false
+		}
+		return ctype == "" || parts[0] == string(ctype)
+	})
+}
+
+type instanceIDWatcher struct {
+	*notifyWatcherBase
+}
+
+func (w *instanceIDWatcher) instanceIDChanged(topic string, _ interface{}) {
+	w.notify()
+}
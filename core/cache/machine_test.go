@@ -0,0 +1,126 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cache_test
+
+import (
+	"github.com/juju/loggo"
+	"github.com/juju/pubsub"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/worker.v1/workertest"
+
+	"github.com/juju/juju/core/cache"
+	"github.com/juju/juju/core/instance"
+	"github.com/juju/juju/core/life"
+)
+
+type MachineSuite struct {
+	testing.IsolationSuite
+
+	model *cache.Model
+}
+
+var _ = gc.Suite(&MachineSuite{})
+
+func (s *MachineSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	hub := pubsub.NewSimpleHub(&pubsub.SimpleHubConfig{
+		Logger: loggo.GetLogger("test"),
+	})
+	s.model = cache.NewModel(cache.CreateControllerGauges(), hub)
+	s.model.SetDetails(modelChange)
+}
+
+func (s *MachineSuite) newMachine(c *gc.C) *cache.Machine {
+	s.model.UpdateMachine(machineChange)
+	machine, err := s.model.Machine(machineChange.Id)
+	c.Assert(err, jc.ErrorIsNil)
+	return machine
+}
+
+func (s *MachineSuite) TestDetails(c *gc.C) {
+	machine := s.newMachine(c)
+	c.Check(machine.Id(), gc.Equals, "0")
+	c.Check(machine.InstanceId(), gc.Equals, "")
+	c.Check(machine.Life(), gc.Equals, life.Alive)
+}
+
+func (s *MachineSuite) TestWatchInstanceID(c *gc.C) {
+	machine := s.newMachine(c)
+	w := machine.WatchInstanceID()
+	defer workertest.CleanKill(c, w)
+	wc := NewNotifyWatcherC(c, w)
+	// Sends initial event.
+	wc.AssertOneChange()
+
+	// Other changes to the machine are not reported.
+	change := machineChange
+	change.Series = "bionic"
+	s.model.UpdateMachine(change)
+	wc.AssertNoChange()
+
+	change.InstanceId = "inst-0"
+	s.model.UpdateMachine(change)
+	wc.AssertOneChange()
+	c.Check(machine.InstanceId(), gc.Equals, "inst-0")
+}
+
+func (s *MachineSuite) TestWatchInstanceIDStops(c *gc.C) {
+	machine := s.newMachine(c)
+	wc := NewNotifyWatcherC(c, machine.WatchInstanceID())
+	wc.AssertOneChange()
+	wc.AssertStops()
+}
+
+func (s *MachineSuite) TestWatchContainers(c *gc.C) {
+	machine := s.newMachine(c)
+	for _, id := range []string{"0/lxd/0", "0/kvm/0", "0/lxd/0/lxd/0", "1/lxd/0"} {
+		change := machineChange
+		change.Id = id
+		s.model.UpdateMachine(change)
+	}
+
+	w := machine.WatchContainers(instance.LXD)
+	defer workertest.CleanKill(c, w)
+	wc := NewStringsWatcherC(c, w)
+	wc.AssertOneChange("0/lxd/0")
+
+	change := machineChange
+	change.Id = "0/lxd/1"
+	s.model.UpdateMachine(change)
+	wc.AssertOneChange("0/lxd/1")
+
+	change.Life = life.Dead
+	s.model.UpdateMachine(change)
+	wc.AssertOneChange("0/lxd/1")
+
+	// Removing a dead machine is not reported.
+	s.model.RemoveMachine(cache.RemoveMachine{ModelUUID: "model-uuid", Id: "0/lxd/1"})
+	wc.AssertNoChange()
+
+	s.model.RemoveMachine(cache.RemoveMachine{ModelUUID: "model-uuid", Id: "0/lxd/0"})
+	wc.AssertOneChange("0/lxd/0")
+}
+
+func (s *MachineSuite) TestWatchAllContainers(c *gc.C) {
+	machine := s.newMachine(c)
+	for _, id := range []string{"0/lxd/0", "0/kvm/0", "0/lxd/0/lxd/0", "1/lxd/0"} {
+		change := machineChange
+		change.Id = id
+		s.model.UpdateMachine(change)
+	}
+
+	w := machine.WatchContainers("")
+	defer workertest.CleanKill(c, w)
+	wc := NewStringsWatcherC(c, w)
+	wc.AssertOneChange("0/lxd/0", "0/kvm/0")
+}
+
+var machineChange = cache.MachineChange{
+	ModelUUID: "model-uuid",
+	Id:        "0",
+	Life:      life.Alive,
+	Series:    "xenial",
+}
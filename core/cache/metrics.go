@@ -19,6 +19,17 @@ const (
 	domainLabel           = "domain"
 	agentStatusLabel      = "agent_status"
 	machineStatusLabel    = "machine_status"
+	modelLabel            = "model"
+	kindLabel             = "kind"
+	changeTypeLabel       = "type"
+)
+
+// The kinds of entity counted per model.
+const (
+	applicationKind = "applications"
+	unitKind        = "units"
+	machineKind     = "machines"
+	charmKind       = "charms"
 )
 
 var (
@@ -40,15 +51,38 @@ var (
 		domainLabel,
 	}
 
+	entityLabelNames = []string{
+		modelLabel,
+		kindLabel,
+	}
+
 	logger = loggo.GetLogger("juju.core.cache")
 )
 
-// ControllerGauges holds the prometheus gauges for ever increasing
-// values used by the controller.
+// ControllerGauges holds the prometheus metrics that are updated by
+// the controller and its models as they are used.
 type ControllerGauges struct {
 	ModelConfigReads   prometheus.Gauge
 	ModelHashCacheHit  prometheus.Gauge
 	ModelHashCacheMiss prometheus.Gauge
+
+	ApplicationConfigReads   prometheus.Gauge
+	ApplicationHashCacheHit  prometheus.Gauge
+	ApplicationHashCacheMiss prometheus.Gauge
+
+	// ChangesProcessed counts the changes applied to the cache,
+	// labelled by the type of change.
+	ChangesProcessed *prometheus.CounterVec
+	// ChangeProcessingTime records how long each change took to
+	// apply to the cache.
+	ChangeProcessingTime prometheus.Histogram
+
+	// Watchers is the number of cache watchers currently running.
+	Watchers prometheus.Gauge
+	// WatcherNotifications counts the change notifications sent to
+	// watchers, so that together with ChangesProcessed it shows the
+	// fan-out of each change.
+	WatcherNotifications prometheus.Counter
 }
 
 func createControllerGauges() *ControllerGauges {
@@ -74,14 +108,86 @@ func createControllerGauges() *ControllerGauges {
 				Help:      "The number of times the model config change hash was generated.",
 			},
 		),
+		ApplicationConfigReads: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: metricsNamespace,
+				Name:      "application_config_reads",
+				Help:      "The number of times the application config is read.",
+			},
+		),
+		ApplicationHashCacheHit: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: metricsNamespace,
+				Name:      "application_hash_cache_hit",
+				Help:      "The number of times the application config change hash was determined using the cached value.",
+			},
+		),
+		ApplicationHashCacheMiss: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: metricsNamespace,
+				Name:      "application_hash_cache_miss",
+				Help:      "The number of times the application config change hash was generated.",
+			},
+		),
+		ChangesProcessed: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: metricsNamespace,
+				Name:      "changes_total",
+				Help:      "The number of changes applied to the cache.",
+			},
+			[]string{changeTypeLabel},
+		),
+		ChangeProcessingTime: prometheus.NewHistogram(
+			prometheus.HistogramOpts{
+				Namespace: metricsNamespace,
+				Name:      "change_processing_seconds",
+				Help:      "The time taken to apply a change to the cache.",
+				Buckets:   prometheus.ExponentialBuckets(0.00001, 4, 10),
+			},
+		),
+		Watchers: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: metricsNamespace,
+				Name:      "watchers",
+				Help:      "The number of cache watchers currently running.",
+			},
+		),
+		WatcherNotifications: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: metricsNamespace,
+				Name:      "watcher_notifications_total",
+				Help:      "The number of change notifications sent to cache watchers.",
+			},
+		),
 	}
 }
 
+// Describe is part of the prometheus.Collector interface.
+func (c *ControllerGauges) Describe(ch chan<- *prometheus.Desc) {
+	c.ModelConfigReads.Describe(ch)
+	c.ModelHashCacheHit.Describe(ch)
+	c.ModelHashCacheMiss.Describe(ch)
+	c.ApplicationConfigReads.Describe(ch)
+	c.ApplicationHashCacheHit.Describe(ch)
+	c.ApplicationHashCacheMiss.Describe(ch)
+	c.ChangesProcessed.Describe(ch)
+	c.ChangeProcessingTime.Describe(ch)
+	c.Watchers.Describe(ch)
+	c.WatcherNotifications.Describe(ch)
+}
+
 // Collect is part of the prometheus.Collector interface.
 func (c *ControllerGauges) Collect(ch chan<- prometheus.Metric) {
 	c.ModelConfigReads.Collect(ch)
 	c.ModelHashCacheHit.Collect(ch)
 	c.ModelHashCacheMiss.Collect(ch)
+	c.ApplicationConfigReads.Collect(ch)
+	c.ApplicationHashCacheHit.Collect(ch)
+	c.ApplicationHashCacheMiss.Collect(ch)
+	c.ChangesProcessed.Collect(ch)
+	c.ChangeProcessingTime.Collect(ch)
+	c.Watchers.Collect(ch)
+	c.WatcherNotifications.Collect(ch)
 }
 
 // Collector is a prometheus.Collector that collects metrics about
@@ -95,6 +201,9 @@ type Collector struct {
 	models   *prometheus.GaugeVec
 	machines *prometheus.GaugeVec
 	users    *prometheus.GaugeVec
+	entities *prometheus.GaugeVec
+
+	lastChangeAge prometheus.Gauge
 }
 
 // NewMetricsCollector returns a new Collector.
@@ -140,6 +249,21 @@ func NewMetricsCollector(controller *Controller) *Collector {
 			},
 			userLabelNames,
 		),
+		entities: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: metricsNamespace,
+				Name:      "model_entities",
+				Help:      "Number of entities of each kind cached for each model.",
+			},
+			entityLabelNames,
+		),
+		lastChangeAge: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: metricsNamespace,
+				Name:      "seconds_since_last_change",
+				Help:      "Time since the cache last applied a change.",
+			},
+		),
 	}
 }
 
@@ -148,6 +272,9 @@ func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
 	c.machines.Describe(ch)
 	c.models.Describe(ch)
 	c.users.Describe(ch)
+	c.entities.Describe(ch)
+	c.lastChangeAge.Describe(ch)
+	c.controller.metrics.Describe(ch)
 
 	c.scrapeErrors.Describe(ch)
 	c.scrapeDuration.Describe(ch)
@@ -164,6 +291,7 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) {
 	c.machines.Reset()
 	c.models.Reset()
 	c.users.Reset()
+	c.entities.Reset()
 
 	c.updateMetrics()
 
@@ -171,6 +299,8 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) {
 	c.machines.Collect(ch)
 	c.models.Collect(ch)
 	c.users.Collect(ch)
+	c.entities.Collect(ch)
+	c.lastChangeAge.Collect(ch)
 }
 
 func (c *Collector) updateMetrics() {
@@ -182,6 +312,11 @@ func (c *Collector) updateMetrics() {
 		c.updateModelMetrics(m)
 	}
 
+	// Until the first change arrives, the age is measured from
+	// when the controller started.
+	age := c.controller.config.Clock.Now().Sub(c.controller.lastChangeTime())
+	c.lastChangeAge.Set(age.Seconds())
+
 	// TODO: add user metrics.
 }
 
@@ -192,10 +327,23 @@ func (c *Collector) updateModelMetrics(modelUUID string) {
 		return
 	}
 
-	// TODO: add machines, applications and units.
-
 	c.models.With(prometheus.Labels{
 		lifeLabel:   string(model.details.Life),
 		statusLabel: string(model.details.Status.Status),
 	}).Inc()
+
+	for _, machine := range model.machineDetails() {
+		c.machines.With(prometheus.Labels{
+			agentStatusLabel:   string(machine.AgentStatus.Status),
+			lifeLabel:          string(machine.Life),
+			machineStatusLabel: string(machine.InstanceStatus.Status),
+		}).Inc()
+	}
+
+	for kind, count := range model.entityCounts() {
+		c.entities.With(prometheus.Labels{
+			modelLabel: modelUUID,
+			kindLabel:  kind,
+		}).Set(float64(count))
+	}
 }
@@ -0,0 +1,114 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cache_test
+
+import (
+	"time"
+
+	"github.com/juju/clock/testclock"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/worker.v1/workertest"
+
+	"github.com/juju/juju/core/cache"
+)
+
+type MetricsSuite struct {
+	testing.IsolationSuite
+
+	changes chan interface{}
+	clock   *testclock.Clock
+}
+
+var _ = gc.Suite(&MetricsSuite{})
+
+func (s *MetricsSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.changes = make(chan interface{})
+	s.clock = testclock.NewClock(time.Date(2018, 10, 1, 12, 0, 0, 0, time.UTC))
+}
+
+func (s *MetricsSuite) newController(c *gc.C) (*cache.Controller, <-chan interface{}) {
+	processed := make(chan interface{}, 1)
+	controller, err := cache.NewController(cache.ControllerConfig{
+		Changes: s.changes,
+		Clock:   s.clock,
+		Notify: func(change interface{}) {
+			processed <- change
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	s.AddCleanup(func(c *gc.C) { workertest.CleanKill(c, controller) })
+	return controller, processed
+}
+
+func (s *MetricsSuite) processChange(c *gc.C, change interface{}, processed <-chan interface{}) {
+	select {
+	case s.changes <- change:
+	case <-time.After(testing.LongWait):
+		c.Fatalf("controller did not read change")
+	}
+	select {
+	case <-processed:
+	case <-time.After(testing.LongWait):
+		c.Fatalf("controller did not handle change")
+	}
+}
+
+func (s *MetricsSuite) gather(c *gc.C, collector prometheus.Collector) map[string]*dto.MetricFamily {
+	registry := prometheus.NewPedanticRegistry()
+	err := registry.Register(collector)
+	c.Assert(err, jc.ErrorIsNil)
+	families, err := registry.Gather()
+	c.Assert(err, jc.ErrorIsNil)
+	result := make(map[string]*dto.MetricFamily)
+	for _, family := range families {
+		result[family.GetName()] = family
+	}
+	return result
+}
+
+func (s *MetricsSuite) TestCollect(c *gc.C) {
+	controller, processed := s.newController(c)
+	s.processChange(c, modelChange, processed)
+	s.clock.Advance(90 * time.Second)
+
+	families := s.gather(c, cache.NewMetricsCollector(controller))
+
+	age := families["juju_cache_seconds_since_last_change"]
+	c.Assert(age, gc.NotNil)
+	c.Check(age.Metric[0].GetGauge().GetValue(), gc.Equals, float64(90))
+
+	entities := families["juju_cache_model_entities"]
+	c.Assert(entities, gc.NotNil)
+	kinds := make(map[string]float64)
+	for _, metric := range entities.Metric {
+		labels := make(map[string]string)
+		for _, label := range metric.Label {
+			labels[label.GetName()] = label.GetValue()
+		}
+		c.Check(labels["model"], gc.Equals, "model-uuid")
+		kinds[labels["kind"]] = metric.GetGauge().GetValue()
+	}
+	c.Check(kinds, jc.DeepEquals, map[string]float64{
+		"applications": 0,
+		"units":        0,
+		"machines":     0,
+		"charms":       0,
+	})
+
+	changes := families["juju_cache_changes_total"]
+	c.Assert(changes, gc.NotNil)
+	c.Check(changes.Metric[0].GetCounter().GetValue(), gc.Equals, float64(1))
+
+	latency := families["juju_cache_change_processing_seconds"]
+	c.Assert(latency, gc.NotNil)
+	c.Check(latency.Metric[0].GetHistogram().GetSampleCount(), gc.Equals, uint64(1))
+
+	c.Check(families["juju_cache_watchers"], gc.NotNil)
+	c.Check(families["juju_cache_watcher_notifications_total"], gc.NotNil)
+}
@@ -5,19 +5,28 @@ package cache
 
 import (
 	"sort"
-	"strings"
 	"sync"
 
+	"github.com/juju/errors"
 	"github.com/juju/pubsub"
-	"gopkg.in/tomb.v2"
+
+	"github.com/juju/juju/core/life"
 )
 
-const modelConfigChange = "model-config-change"
+const (
+	modelConfigChange = "model-config-change"
+	unitLifeChange    = "unit-life-change"
+	machineLifeChange = "machine-life-change"
+)
 
 func newModel(metrics *ControllerGauges, hub *pubsub.SimpleHub) *Model {
 	m := &Model{
-		metrics: metrics,
-		hub:     hub,
+		metrics:      metrics,
+		hub:          hub,
+		applications: make(map[string]*Application),
+		units:        make(map[string]*Unit),
+		machines:     make(map[string]*Machine),
+		charms:       make(map[string]*Charm),
 	}
 	return m
 }
@@ -31,7 +40,12 @@ type Model struct {
 
 	details    ModelChange
 	configHash string
-	hashCache  *modelConfigHashCache
+	hashCache  *configHashCache
+
+	applications map[string]*Application
+	units        map[string]*Unit
+	machines     map[string]*Machine
+	charms       map[string]*Charm
 }
 
 // Report returns information that is used in the dependency engine report.
@@ -44,6 +58,32 @@ func (m *Model) Report() map[string]interface{} {
 	}
 }
 
+// entityCounts returns the number of each kind of entity cached
+// for the model.
+func (m *Model) entityCounts() map[string]int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return map[string]int{
+		applicationKind: len(m.applications),
+		unitKind:        len(m.units),
+		machineKind:     len(m.machines),
+		charmKind:       len(m.charms),
+	}
+}
+
+// machineDetails returns the details of all the model's machines.
+func (m *Model) machineDetails() []MachineChange {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result := make([]MachineChange, 0, len(m.machines))
+	for _, machine := range m.machines {
+		machine.mu.Lock()
+		result = append(result, machine.details)
+		machine.mu.Unlock()
+	}
+	return result
+}
+
 // modelTopic prefixes the topic with the model UUID.
 func (m *Model) modelTopic(topic string) string {
 	return m.details.ModelUUID + ":" + topic
@@ -54,7 +94,8 @@ func (m *Model) setDetails(details ModelChange) {
 	defer m.mu.Unlock()
 	m.details = details
 
-	hashCache, configHash := newModelConfigHashCache(m.metrics, details.Config)
+	hashCache, configHash := newConfigHashCache(
+		m.metrics.ModelHashCacheHit, m.metrics.ModelHashCacheMiss, hash, details.Config)
 	if configHash != m.configHash {
 		m.configHash = configHash
 		m.hashCache = hashCache
@@ -75,145 +116,278 @@ func (m *Model) Config() map[string]interface{} {
 // those keys change values. If no keys are specified, any change in the
 // config will trigger the watcher.
 func (m *Model) WatchConfig(keys ...string) *modelConfigWatcher {
-	// We use a single entry buffered channel for the changes.
-	// This allows the config changed handler to send a value when there
-	// is a change, but if that value hasn't been consumed before the
-	// next change, the second change is discarded.
 	sort.Strings(keys)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	watcher := &modelConfigWatcher{
-		keys:    keys,
-		changes: make(chan struct{}, 1),
+		notifyWatcherBase: newNotifyWatcherBase(m.metrics),
+		keys:              keys,
+	}
+	// Entities may be cached for a model before its details arrive,
+	// in which case there is no config to hash yet.
+	if m.hashCache != nil {
+		watcher.hash = m.hashCache.getHash(keys)
 	}
-	watcher.hash = m.hashCache.getHash(keys)
-	// Send initial event down the channel. We know that this will
-	// execute immediately because it is a buffered channel.
-	watcher.changes <- struct{}{}
 
 	unsub := m.hub.Subscribe(m.modelTopic(modelConfigChange), watcher.configChanged)
+	watcher.run(unsub)
+	return watcher
+}
 
-	watcher.tomb.Go(func() error {
-		<-watcher.tomb.Dying()
-		unsub()
-		return nil
-	})
+// Application returns the application with the given name.
+// If the application isn't found, a NotFoundError is returned.
+func (m *Model) Application(name string) (*Application, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	app, found := m.applications[name]
+	if !found {
+		return nil, errors.NotFoundf("application %q", name)
+	}
+	return app, nil
+}
 
-	return watcher
+// Unit returns the unit with the given name.
+// If the unit isn't found, a NotFoundError is returned.
+func (m *Model) Unit(name string) (*Unit, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	unit, found := m.units[name]
+	if !found {
+		return nil, errors.NotFoundf("unit %q", name)
+	}
+	return unit, nil
 }
 
-type modelConfigHashCache struct {
-	metrics *ControllerGauges
-	config  map[string]interface{}
-	// The key to the hash map is the stringified keys of the watcher.
-	// They should be sorted and comma delimited.
-	hash map[string]string
-	mu   sync.Mutex
-}
-
-func newModelConfigHashCache(metrics *ControllerGauges, config map[string]interface{}) (*modelConfigHashCache, string) {
-	configCache := &modelConfigHashCache{
-		metrics: metrics,
-		config:  config,
-		hash:    make(map[string]string),
-	}
-	// Generate the hash for the entire config.
-	allHash := configCache.generateHash(nil)
-	configCache.hash[""] = allHash
-	return configCache, allHash
-}
-
-func (c *modelConfigHashCache) getHash(keys []string) string {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	key := strings.Join(keys, ",")
-	value, found := c.hash[key]
-	if found {
-		c.metrics.ModelHashCacheHit.Inc()
-		return value
-	}
-	value = c.generateHash(keys)
-	c.hash[key] = value
-	return value
-}
-
-func (c *modelConfigHashCache) generateHash(keys []string) string {
-	// We are generating a hash, so call it a miss.
-	c.metrics.ModelHashCacheMiss.Inc()
-
-	interested := c.config
-	if len(keys) > 0 {
-		interested = make(map[string]interface{})
-		for _, key := range keys {
-			if value, found := c.config[key]; found {
-				interested[key] = value
-			}
-		}
+// Machine returns the machine with the given ID.
+// If the machine isn't found, a NotFoundError is returned.
+func (m *Model) Machine(id string) (*Machine, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	machine, found := m.machines[id]
+	if !found {
+		return nil, errors.NotFoundf("machine %q", id)
 	}
-	h, err := hash(interested)
-	if err != nil {
-		logger.Errorf("invariant error - model config should be yaml serializable and hashable, %v", err)
-		return ""
+	return machine, nil
+}
+
+// Charm returns the charm with the given URL.
+// If the charm isn't found, a NotFoundError is returned.
+func (m *Model) Charm(url string) (*Charm, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	charm, found := m.charms[url]
+	if !found {
+		return nil, errors.NotFoundf("charm %q", url)
 	}
-	return h
+	return charm, nil
 }
 
-type modelConfigWatcher struct {
-	keys    []string
-	hash    string
-	tomb    tomb.Tomb
-	changes chan struct{}
-	// We can't send down a closed channel, so protect the sending
-	// with a mutex and bool. Since you can't really even ask a channel
-	// if it is closed.
-	closed bool
-	mu     sync.Mutex
+// updateApplication adds or updates the application in the model.
+func (m *Model) updateApplication(ch ApplicationChange) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	app, found := m.applications[ch.Name]
+	if !found {
+		app = newApplication(m, m.metrics, m.hub)
+		m.applications[ch.Name] = app
+	}
+	app.setDetails(ch)
 }
 
-func (w *modelConfigWatcher) configChanged(topic string, value interface{}) {
-	hashCache, ok := value.(*modelConfigHashCache)
-	if !ok {
-		logger.Errorf("programming error, value not a *modelConfigHashCache")
+// removeApplication removes the application from the model.
+func (m *Model) removeApplication(ch RemoveApplication) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.applications, ch.Name)
+}
+
+// updateUnit adds or updates the unit in the model, letting the unit
+// lifecycle watchers know if its life has changed.
+func (m *Model) updateUnit(ch UnitChange) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	unit, found := m.units[ch.Name]
+	if !found {
+		unit = newUnit()
+		m.units[ch.Name] = unit
 	}
-	hash := hashCache.getHash(w.keys)
-	if hash == w.hash {
-		// Nothing that we care about has changed, so we're done.
+	if previous := unit.setDetails(ch); previous != ch.Life {
+		m.hub.Publish(m.modelTopic(unitLifeChange), lifeChange{id: ch.Name, life: ch.Life})
+	}
+}
+
+// removeUnit removes the unit from the model, letting the unit
+// lifecycle watchers know.
+func (m *Model) removeUnit(ch RemoveUnit) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, found := m.units[ch.Name]; !found {
 		return
 	}
-	// Let the listener know.
-	w.mu.Lock()
-	defer w.mu.Unlock()
-	if w.closed {
+	delete(m.units, ch.Name)
+	m.hub.Publish(m.modelTopic(unitLifeChange), lifeChange{id: ch.Name, removed: true})
+}
+
+// updateMachine adds or updates the machine in the model, letting the
+// machine lifecycle watchers know if its life has changed.
+func (m *Model) updateMachine(ch MachineChange) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	machine, found := m.machines[ch.Id]
+	if !found {
+		machine = newMachine(m, m.metrics, m.hub)
+		m.machines[ch.Id] = machine
+	}
+	if previous := machine.setDetails(ch); previous != ch.Life {
+		m.hub.Publish(m.modelTopic(machineLifeChange), lifeChange{id: ch.Id, life: ch.Life})
+	}
+}
+
+// removeMachine removes the machine from the model, letting the
+// machine lifecycle watchers know.
+func (m *Model) removeMachine(ch RemoveMachine) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, found := m.machines[ch.Id]; !found {
 		return
 	}
+	delete(m.machines, ch.Id)
+	m.hub.Publish(m.modelTopic(machineLifeChange), lifeChange{id: ch.Id, removed: true})
+}
+
+// updateCharm adds or updates the charm in the model.
+func (m *Model) updateCharm(ch CharmChange) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	charm, found := m.charms[ch.CharmURL]
+	if !found {
+		charm = newCharm()
+		m.charms[ch.CharmURL] = charm
+	}
+	charm.setDetails(ch)
+}
+
+// removeCharm removes the charm from the model.
+func (m *Model) removeCharm(ch RemoveCharm) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.charms, ch.CharmURL)
+}
+
+// watchUnitsLife returns a lifecycle watcher for the units whose
+// names match.
+func (m *Model) watchUnitsLife(match func(string) bool) StringsWatcher {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	known := make(map[string]life.Value)
+	for name, unit := range m.units {
+		if match(name) {
+			known[name] = unit.Life()
+		}
+	}
+	return m.newLifecycleWatcher(unitLifeChange, match, known)
+}
+
+// watchMachinesLife returns a lifecycle watcher for the machines whose
+// IDs match.
+func (m *Model) watchMachinesLife(match func(string) bool) StringsWatcher {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	known := make(map[string]life.Value)
+	for id, machine := range m.machines {
+		if match(id) {
+			known[id] = machine.Life()
+		}
+	}
+	return m.newLifecycleWatcher(machineLifeChange, match, known)
+}
 
-	select {
-	case w.changes <- struct{}{}:
-	default:
-		// Already a pending change, so do nothing.
+// newLifecycleWatcher must be called with the model lock held, so
+// that no changes are missed between gathering the known entities
+// and subscribing to changes.
+func (m *Model) newLifecycleWatcher(topic string, match func(string) bool, known map[string]life.Value) StringsWatcher {
+	initial := make([]string, 0, len(known))
+	for id := range known {
+		initial = append(initial, id)
+	}
+	sort.Strings(initial)
+	w := &lifecycleWatcher{
+		stringsWatcherBase: newStringsWatcherBase(m.metrics, false, initial),
+		match:              match,
+		known:              known,
 	}
+	unsub := m.hub.Subscribe(m.modelTopic(topic), w.lifeChanged)
+	w.run(unsub)
+	return w
 }
 
-// Changes is part of the core watcher definition.
-// The changes channel is never closed.
-func (w *modelConfigWatcher) Changes() <-chan struct{} {
-	return w.changes
+// lifeChange is published when an entity is added, changes life, or
+// is removed.
+type lifeChange struct {
+	id      string
+	life    life.Value
+	removed bool
 }
 
-// Kill is part of the worker.Worker interface.
-func (w *modelConfigWatcher) Kill() {
-	w.mu.Lock()
-	w.closed = true
-	close(w.changes)
-	w.mu.Unlock()
-	w.tomb.Kill(nil)
+// lifecycleWatcher yields the IDs of the matching entities that have
+// been added, have changed life, or have been removed. Removal of an
+// entity that was already known to be dead is not reported, as that
+// is implied by it becoming dead.
+type lifecycleWatcher struct {
+	*stringsWatcherBase
+	match func(string) bool
+	// known is only accessed by the hub's handler, which is never
+	// called concurrently for a subscriber.
+	known map[string]life.Value
+}
+
+func (w *lifecycleWatcher) lifeChanged(topic string, value interface{}) {
+	change, ok := value.(lifeChange)
+	if !ok {
+		logger.Errorf("programming error, value not a lifeChange")
+		return
+	}
+	if !w.match(change.id) {
+		return
+	}
+	previous, found := w.known[change.id]
+	if change.removed {
+		if !found {
+			return
+		}
+		delete(w.known, change.id)
+		if previous == life.Dead {
+			return
+		}
+		w.notify(change.id)
+		return
+	}
+	if found && previous == change.life {
+		return
+	}
+	w.known[change.id] = change.life
+	w.notify(change.id)
 }
 
-// Wait is part of the worker.Worker interface.
-func (w *modelConfigWatcher) Wait() error {
-	return w.tomb.Wait()
+type modelConfigWatcher struct {
+	*notifyWatcherBase
+	keys []string
+	hash string
 }
 
-// Stop is currently required by the Resources wrapper in the apiserver.
-func (w *modelConfigWatcher) Stop() error {
-	w.Kill()
-	return w.Wait()
+func (w *modelConfigWatcher) configChanged(topic string, value interface{}) {
+	hashCache, ok := value.(*configHashCache)
+	if !ok {
+		logger.Errorf("programming error, value not a *configHashCache")
+		return
+	}
+	hash := hashCache.getHash(w.keys)
+	if hash == w.hash {
+		// Nothing that we care about has changed, so we're done.
+		return
+	}
+	w.hash = hash
+	// Let the listener know.
+	w.notify()
 }
@@ -104,6 +104,32 @@ func (s *ModelSuite) TestConfigWatcherChange(c *gc.C) {
 	c.Check(testutil.ToFloat64(s.gauges.ModelHashCacheHit), gc.Equals, float64(2))
 }
 
+func (s *ModelSuite) TestConfigWatcherMetrics(c *gc.C) {
+	m := s.newModel(modelChange)
+	w1 := m.WatchConfig()
+	wc1 := NewNotifyWatcherC(c, w1)
+	wc1.AssertOneChange()
+	w2 := m.WatchConfig("key")
+	defer workertest.CleanKill(c, w2)
+	wc2 := NewNotifyWatcherC(c, w2)
+	wc2.AssertOneChange()
+	c.Check(testutil.ToFloat64(s.gauges.Watchers), gc.Equals, float64(2))
+
+	// Only the first watcher cares about this change.
+	change := modelChange
+	change.Config = map[string]interface{}{
+		"key":     "value",
+		"another": "changed",
+	}
+	m.SetDetails(change)
+	wc1.AssertOneChange()
+	wc2.AssertNoChange()
+	c.Check(testutil.ToFloat64(s.gauges.WatcherNotifications), gc.Equals, float64(1))
+
+	workertest.CleanKill(c, w1)
+	c.Check(testutil.ToFloat64(s.gauges.Watchers), gc.Equals, float64(1))
+}
+
 func (s *ModelSuite) TestConfigWatcherOneValue(c *gc.C) {
 	m := s.newModel(modelChange)
 	w := m.WatchConfig("key")
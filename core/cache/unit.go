@@ -0,0 +1,67 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cache
+
+import (
+	"sync"
+
+	"github.com/juju/juju/core/life"
+)
+
+func newUnit() *Unit {
+	return &Unit{}
+}
+
+// Unit is a cached unit in a model. The unit is kept up to date with
+// changes flowing into the cached controller.
+type Unit struct {
+	mu      sync.Mutex
+	details UnitChange
+}
+
+// Name returns the name of the unit.
+func (u *Unit) Name() string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.details.Name
+}
+
+// Application returns the name of the unit's application.
+func (u *Unit) Application() string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.details.Application
+}
+
+// CharmURL returns the URL of the charm the unit is running, which
+// is empty until the unit agent has set it.
+func (u *Unit) CharmURL() string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.details.CharmURL
+}
+
+// MachineId returns the ID of the machine the unit is assigned to,
+// if any.
+func (u *Unit) MachineId() string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.details.MachineId
+}
+
+// Life returns the life of the unit.
+func (u *Unit) Life() life.Value {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.details.Life
+}
+
+// setDetails updates the unit, returning the unit's previous life.
+func (u *Unit) setDetails(details UnitChange) life.Value {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	previous := u.details.Life
+	u.details = details
+	return previous
+}
@@ -4,7 +4,10 @@
 package cache
 
 import (
+	"sync"
+
 	"gopkg.in/juju/worker.v1"
+	"gopkg.in/tomb.v2"
 )
 
 // The watchers used in the cache package are closer to state watchers
@@ -18,6 +21,9 @@ type Watcher interface {
 	// Stop is currently needed by the apiserver until the resources
 	// work on workers instead of things that can be stopped.
 	Stop() error
+	// Err is needed by the apiserver watcher facades, which report
+	// the error of a watcher whose changes channel has been closed.
+	Err() error
 }
 
 // NotifyWatcher will only say something changed.
@@ -25,3 +31,230 @@ type NotifyWatcher interface {
 	Watcher
 	Changes() <-chan struct{}
 }
+
+// StringsWatcher will tell you what changed.
+type StringsWatcher interface {
+	Watcher
+	Changes() <-chan []string
+}
+
+// notifyWatcherBase implements the mechanics of a NotifyWatcher. The
+// handler that decides whether a change is interesting is supplied
+// by the embedding watcher, which calls notify when it is.
+type notifyWatcherBase struct {
+	metrics *ControllerGauges
+	tomb    tomb.Tomb
+	changes chan struct{}
+	// We can't send down a closed channel, so protect the sending
+	// with a mutex and bool. Since you can't really even ask a channel
+	// if it is closed.
+	closed bool
+	mu     sync.Mutex
+}
+
+func newNotifyWatcherBase(metrics *ControllerGauges) *notifyWatcherBase {
+	// We use a single entry buffered channel for the changes.
+	// This allows the handler to send a value when there is a change,
+	// but if that value hasn't been consumed before the next change,
+	// the second change is discarded.
+	w := &notifyWatcherBase{
+		metrics: metrics,
+		changes: make(chan struct{}, 1),
+	}
+	// Send initial event down the channel. We know that this will
+	// execute immediately because it is a buffered channel.
+	w.changes <- struct{}{}
+	return w
+}
+
+// run records the watcher in the metrics and calls unsub when the
+// watcher is killed.
+func (w *notifyWatcherBase) run(unsub func()) {
+	w.metrics.Watchers.Inc()
+	w.tomb.Go(func() error {
+		<-w.tomb.Dying()
+		unsub()
+		w.metrics.Watchers.Dec()
+		return nil
+	})
+}
+
+func (w *notifyWatcherBase) notify() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+
+	select {
+	case w.changes <- struct{}{}:
+		w.metrics.WatcherNotifications.Inc()
+	default:
+		// Already a pending change, so do nothing.
+	}
+}
+
+// Changes is part of the core watcher definition.
+// The changes channel is never closed.
+func (w *notifyWatcherBase) Changes() <-chan struct{} {
+	return w.changes
+}
+
+// Kill is part of the worker.Worker interface.
+func (w *notifyWatcherBase) Kill() {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.changes)
+	}
+	w.mu.Unlock()
+	w.tomb.Kill(nil)
+}
+
+// Wait is part of the worker.Worker interface.
+func (w *notifyWatcherBase) Wait() error {
+	return w.tomb.Wait()
+}
+
+// Stop is currently required by the Resources wrapper in the apiserver.
+func (w *notifyWatcherBase) Stop() error {
+	w.Kill()
+	return w.Wait()
+}
+
+// Err is part of the Watcher interface.
+func (w *notifyWatcherBase) Err() error {
+	return w.tomb.Err()
+}
+
+// stringsWatcherBase implements the mechanics of a StringsWatcher.
+// Values passed to notify are collected until the consumer of the
+// changes channel is ready for them.
+type stringsWatcherBase struct {
+	metrics *ControllerGauges
+	tomb    tomb.Tomb
+	changes chan []string
+	wake    chan struct{}
+
+	// latestOnly is true if only the most recent value is of any
+	// interest, as is the case for hash watchers.
+	latestOnly bool
+
+	mu      sync.Mutex
+	pending []string
+}
+
+func newStringsWatcherBase(metrics *ControllerGauges, latestOnly bool, initial []string) *stringsWatcherBase {
+	return &stringsWatcherBase{
+		metrics:    metrics,
+		changes:    make(chan []string),
+		wake:       make(chan struct{}, 1),
+		latestOnly: latestOnly,
+		pending:    initial,
+	}
+}
+
+// run starts the loop that delivers the changes, and calls unsub
+// when the watcher is killed.
+func (w *stringsWatcherBase) run(unsub func()) {
+	w.metrics.Watchers.Inc()
+	w.tomb.Go(func() error {
+		defer w.metrics.Watchers.Dec()
+		defer unsub()
+		return w.loop()
+	})
+}
+
+func (w *stringsWatcherBase) loop() error {
+	defer close(w.changes)
+	// The initial event is always sent, even if it is empty.
+	initial := true
+	for {
+		if !initial {
+			select {
+			case <-w.tomb.Dying():
+				return nil
+			case <-w.wake:
+			}
+		}
+		w.mu.Lock()
+		values := w.pending
+		w.pending = nil
+		w.mu.Unlock()
+		if len(values) == 0 && !initial {
+			continue
+		}
+		select {
+		case <-w.tomb.Dying():
+			return nil
+		case w.changes <- values:
+			if !initial {
+				w.metrics.WatcherNotifications.Inc()
+			}
+		}
+		initial = false
+	}
+}
+
+func (w *stringsWatcherBase) notify(values ...string) {
+	if len(values) == 0 {
+		return
+	}
+	w.mu.Lock()
+	if w.latestOnly {
+		w.pending = values[len(values)-1:]
+	} else {
+		w.pending = appendUnique(w.pending, values...)
+	}
+	w.mu.Unlock()
+
+	select {
+	case w.wake <- struct{}{}:
+	default:
+		// Already woken, the loop will pick up the values.
+	}
+}
+
+// Changes is part of the core watcher definition.
+// The changes channel is closed when the watcher stops.
+func (w *stringsWatcherBase) Changes() <-chan []string {
+	return w.changes
+}
+
+// Kill is part of the worker.Worker interface.
+func (w *stringsWatcherBase) Kill() {
+	w.tomb.Kill(nil)
+}
+
+// Wait is part of the worker.Worker interface.
+func (w *stringsWatcherBase) Wait() error {
+	return w.tomb.Wait()
+}
+
+// Stop is currently required by the Resources wrapper in the apiserver.
+func (w *stringsWatcherBase) Stop() error {
+	w.Kill()
+	return w.Wait()
+}
+
+// Err is part of the Watcher interface.
+func (w *stringsWatcherBase) Err() error {
+	return w.tomb.Err()
+}
+
+// appendUnique appends the values that are not already in the slice.
+func appendUnique(existing []string, values ...string) []string {
+	for _, value := range values {
+		found := false
+		for _, e := range existing {
+			if e == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			existing = append(existing, value)
+		}
+	}
+	return existing
+}
@@ -0,0 +1,48 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package settings holds functions shared by the places that
+// watch settings for changes.
+package settings
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+
+	"github.com/juju/errors"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Hash returns a hash of the named settings. The settings are
+// serialised as BSON with their keys, and those of any nested maps,
+// sorted, so that equal settings always have the same hash. Watchers
+// reading settings from the database and from the model cache both
+// use it, so that a unit agent switching between them doesn't see a
+// change.
+func Hash(name string, values map[string]interface{}) (string, error) {
+	data, err := bson.Marshal(toSortedBsonD(values))
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	hash := sha256.New()
+	hash.Write([]byte(name))
+	hash.Write(data)
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
+
+func toSortedBsonD(values map[string]interface{}) bson.D {
+	var items bson.D
+	for name, value := range values {
+		if mapValue, ok := value.(map[string]interface{}); ok {
+			value = toSortedBsonD(mapValue)
+		}
+		items = append(items, bson.DocElem{Name: name, Value: value})
+	}
+	// We know that there aren't any equal names because the source is
+	// a map.
+	sort.Slice(items, func(i int, j int) bool {
+		return items[i].Name < items[j].Name
+	})
+	return items
+}
@@ -0,0 +1,50 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package settings_test
+
+import (
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/core/settings"
+)
+
+type HashSuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&HashSuite{})
+
+func (s *HashSuite) TestHashIgnoresKeyOrder(c *gc.C) {
+	values := map[string]interface{}{
+		"a": "one",
+		"b": 2,
+		"c": map[string]interface{}{"x": true, "y": "why"},
+	}
+	first, err := settings.Hash("a#app#cs:app-1", values)
+	c.Assert(err, jc.ErrorIsNil)
+	for i := 0; i < 10; i++ {
+		hash, err := settings.Hash("a#app#cs:app-1", values)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(hash, gc.Equals, first)
+	}
+}
+
+func (s *HashSuite) TestHashIncludesName(c *gc.C) {
+	values := map[string]interface{}{"a": "one"}
+	first, err := settings.Hash("a#app#cs:app-1", values)
+	c.Assert(err, jc.ErrorIsNil)
+	second, err := settings.Hash("a#app#cs:app-2", values)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(first, gc.Not(gc.Equals), second)
+}
+
+func (s *HashSuite) TestHashChangesWithValues(c *gc.C) {
+	first, err := settings.Hash("a#app#cs:app-1", map[string]interface{}{"a": "one"})
+	c.Assert(err, jc.ErrorIsNil)
+	second, err := settings.Hash("a#app#cs:app-1", map[string]interface{}{"a": "two"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(first, gc.Not(gc.Equals), second)
+}
@@ -0,0 +1,114 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package status
+
+import (
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// The keys that may be used in a status filter.
+const (
+	// FilterWorkload selects units by their workload status.
+	FilterWorkload = "workload"
+
+	// FilterAgent selects units by their agent status.
+	FilterAgent = "agent"
+
+	// FilterRelation selects applications by the relations they take
+	// part in, given as "<application>" or "<application>:<endpoint>".
+	FilterRelation = "relation"
+
+	// FilterCharm selects applications by their charm URL, which may
+	// omit the series and revision.
+	FilterCharm = "charm"
+)
+
+// Filter is a set of terms that the units and applications in status
+// must all satisfy.
+type Filter []FilterTerm
+
+// FilterTerm is satisfied by an entity that matches any of its values
+// for the key.
+type FilterTerm struct {
+	Key    string
+	Values []string
+}
+
+// Values returns the values of the filter's term for the key, or nil
+// if it has none.
+func (f Filter) Values(key string) []string {
+	for _, term := range f {
+		if term.Key == key {
+			return term.Values
+		}
+	}
+	return nil
+}
+
+// String returns the filter in the form accepted by ParseFilter.
+func (f Filter) String() string {
+	terms := make([]string, len(f))
+	for i, term := range f {
+		terms[i] = term.Key + "=" + strings.Join(term.Values, "|")
+	}
+	return strings.Join(terms, ",")
+}
+
+// ParseFilter parses a filter expression: a comma separated list of
+// "<key>=<value>" terms, all of which must be satisfied. A term may
+// list alternative values separated by "|", eg "workload=blocked|waiting".
+func ParseFilter(expr string) (Filter, error) {
+	var filter Filter
+	for _, part := range strings.Split(expr, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		term, err := parseFilterTerm(part)
+		if err != nil {
+			return nil, errors.Annotatef(err, "invalid filter term %q", part)
+		}
+		if filter.Values(term.Key) != nil {
+			return nil, errors.Errorf("duplicate filter key %q", term.Key)
+		}
+		filter = append(filter, term)
+	}
+	if len(filter) == 0 {
+		return nil, errors.New("empty filter")
+	}
+	return filter, nil
+}
+
+func parseFilterTerm(expr string) (FilterTerm, error) {
+	eq := strings.Index(expr, "=")
+	if eq < 0 {
+		return FilterTerm{}, errors.New("expected <key>=<value>")
+	}
+	term := FilterTerm{Key: strings.TrimSpace(expr[:eq])}
+	for _, value := range strings.Split(expr[eq+1:], "|") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return FilterTerm{}, errors.New("empty value")
+		}
+		term.Values = append(term.Values, value)
+	}
+	for _, value := range term.Values {
+		switch term.Key {
+		case FilterWorkload:
+			if !Status(value).KnownWorkloadStatus() {
+				return FilterTerm{}, errors.NotValidf("workload status %q", value)
+			}
+		case FilterAgent:
+			if s := Status(value); !s.KnownAgentStatus() && s != Lost {
+				return FilterTerm{}, errors.NotValidf("agent status %q", value)
+			}
+		case FilterRelation, FilterCharm:
+		default:
+			return FilterTerm{}, errors.NotValidf("filter key %q", term.Key)
+		}
+	}
+	return term, nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package status_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/core/status"
+)
+
+type FilterSuite struct{}
+
+var _ = gc.Suite(&FilterSuite{})
+
+func (s *FilterSuite) TestParseFilter(c *gc.C) {
+	filter, err := status.ParseFilter("workload=blocked|waiting, agent=lost,relation=mysql:db,charm=cs:postgresql")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(filter, jc.DeepEquals, status.Filter{
+		{Key: "workload", Values: []string{"blocked", "waiting"}},
+		{Key: "agent", Values: []string{"lost"}},
+		{Key: "relation", Values: []string{"mysql:db"}},
+		{Key: "charm", Values: []string{"cs:postgresql"}},
+	})
+	c.Check(filter.Values("agent"), jc.DeepEquals, []string{"lost"})
+	c.Check(filter.Values("exposed"), gc.IsNil)
+	c.Check(filter.String(), gc.Equals, "workload=blocked|waiting,agent=lost,relation=mysql:db,charm=cs:postgresql")
+}
+
+func (s *FilterSuite) TestParseFilterInvalid(c *gc.C) {
+	for i, test := range []struct {
+		expr string
+		err  string
+	}{{
+		expr: "",
+		err:  "empty filter",
+	}, {
+		expr: "blocked",
+		err:  `invalid filter term "blocked": expected <key>=<value>`,
+	}, {
+		expr: "workload=",
+		err:  `invalid filter term "workload=": empty value`,
+	}, {
+		expr: "workload=lost",
+		err:  `invalid filter term "workload=lost": workload status "lost" not valid`,
+	}, {
+		expr: "agent=blocked",
+		err:  `invalid filter term "agent=blocked": agent status "blocked" not valid`,
+	}, {
+		expr: "colour=blue",
+		err:  `invalid filter term "colour=blue": filter key "colour" not valid`,
+	}, {
+		expr: "workload=active,workload=blocked",
+		err:  `duplicate filter key "workload"`,
+	}} {
+		c.Logf("test %d: %q", i, test.expr)
+		_, err := status.ParseFilter(test.expr)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package status
+
+import (
+	"sort"
+	"time"
+)
+
+// TimeInState returns how long an entity spent in each status between
+// from and to, given the entity's history of a single kind. An entry
+// lasts until the next one, or until to for the latest entry. The
+// history should include the last entry set at or before from, which
+// gives the status at the start of the window; without it, the time
+// between from and the earliest entry is not counted. Entries without
+// a time are ignored.
+func TimeInState(history History, from, to time.Time) map[Status]time.Duration {
+	var entries History
+	for _, entry := range history {
+		if entry.Since != nil {
+			entries = append(entries, entry)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Since.Before(*entries[j].Since)
+	})
+
+	result := make(map[Status]time.Duration)
+	for i, entry := range entries {
+		start := *entry.Since
+		end := to
+		if i+1 < len(entries) {
+			end = *entries[i+1].Since
+		}
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if end.After(start) {
+			result[entry.Status] += end.Sub(start)
+		}
+	}
+	return result
+}
@@ -0,0 +1,71 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package status_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/core/status"
+)
+
+type HistorySummarySuite struct{}
+
+var _ = gc.Suite(&HistorySummarySuite{})
+
+func (s *HistorySummarySuite) TestTimeInState(c *gc.C) {
+	start := time.Date(2018, 7, 1, 0, 0, 0, 0, time.UTC)
+	at := func(minutes int) *time.Time {
+		t := start.Add(time.Duration(minutes) * time.Minute)
+		return &t
+	}
+	// Newest first, as the API returns it.
+	history := status.History{
+		{Status: status.Active, Since: at(50)},
+		{Status: status.Blocked, Since: at(30)},
+		{Status: status.Error, Since: at(20)},
+		{Status: status.Active, Since: at(10)},
+		{Status: status.Unknown},
+		{Status: status.Waiting, Since: at(-10)},
+	}
+	summary := status.TimeInState(history, start, *at(60))
+	c.Assert(summary, jc.DeepEquals, map[status.Status]time.Duration{
+		status.Waiting: 10 * time.Minute,
+		status.Active:  20 * time.Minute,
+		status.Error:   10 * time.Minute,
+		status.Blocked: 20 * time.Minute,
+	})
+}
+
+func (s *HistorySummarySuite) TestTimeInStateEntriesAfterWindow(c *gc.C) {
+	start := time.Date(2018, 7, 1, 0, 0, 0, 0, time.UTC)
+	later := start.Add(2 * time.Hour)
+	history := status.History{
+		{Status: status.Blocked, Since: &start},
+		{Status: status.Active, Since: &later},
+	}
+	summary := status.TimeInState(history, start, start.Add(time.Hour))
+	c.Assert(summary, jc.DeepEquals, map[status.Status]time.Duration{
+		status.Blocked: time.Hour,
+	})
+}
+
+func (s *HistorySummarySuite) TestTimeInStateNoEntryBeforeWindow(c *gc.C) {
+	start := time.Date(2018, 7, 1, 0, 0, 0, 0, time.UTC)
+	later := start.Add(30 * time.Minute)
+	history := status.History{
+		{Status: status.Active, Since: &later},
+	}
+	summary := status.TimeInState(history, start, start.Add(time.Hour))
+	c.Assert(summary, jc.DeepEquals, map[status.Status]time.Duration{
+		status.Active: 30 * time.Minute,
+	})
+}
+
+func (s *HistorySummarySuite) TestTimeInStateEmpty(c *gc.C) {
+	now := time.Now()
+	c.Assert(status.TimeInState(nil, now.Add(-time.Hour), now), gc.HasLen, 0)
+}
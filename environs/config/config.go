@@ -23,9 +23,12 @@ import (
 	"gopkg.in/yaml.v2"
 
 	"github.com/juju/juju/controller"
+	"github.com/juju/juju/core/actions"
 	"github.com/juju/juju/environs/tags"
 	"github.com/juju/juju/juju/osenv"
 	jujuversion "github.com/juju/juju/juju/version"
+	"github.com/juju/juju/logfwd/httpjson"
+	"github.com/juju/juju/logfwd/logfile"
 	"github.com/juju/juju/logfwd/syslog"
 	"github.com/juju/juju/network"
 )
@@ -188,6 +191,31 @@ const (
 	// forwarding.
 	LogFwdSyslogClientKey = "syslog-client-key"
 
+	// LogFwdSyslogFilter selects the log records forwarded to the
+	// syslog server.
+	LogFwdSyslogFilter = "syslog-filter"
+
+	// LogFwdHTTPURL sets the URL of the HTTP endpoint log records
+	// are forwarded to.
+	LogFwdHTTPURL = "logforward-http-url"
+
+	// LogFwdHTTPFormat sets the payload format expected by the HTTP
+	// log forwarding endpoint: "elasticsearch" or "loki".
+	LogFwdHTTPFormat = "logforward-http-format"
+
+	// LogFwdHTTPFilter selects the log records forwarded to the HTTP
+	// endpoint.
+	LogFwdHTTPFilter = "logforward-http-filter"
+
+	// LogFwdFilePath sets the path of the file on the controller log
+	// records are forwarded to, relative to the model's directory
+	// under the logforward directory in the controller's log
+	// directory. The file is rotated once it reaches 100MB.
+	LogFwdFilePath = "logforward-file-path"
+
+	// LogFwdFileFilter selects the log records forwarded to the file.
+	LogFwdFileFilter = "logforward-file-filter"
+
 	// AutomaticallyRetryHooks determines whether the uniter will
 	// automatically retry a hook that has failed
 	AutomaticallyRetryHooks = "automatically-retry-hooks"
@@ -216,6 +244,16 @@ const (
 	// grow to before it is pruned, eg "5M"
 	MaxActionResultsSize = "max-action-results-size"
 
+	// ActionResultsRetention overrides the maximum age of the results
+	// of particular actions, or of the actions of particular
+	// applications, eg "backup=2160h,mysql:*=720h"
+	ActionResultsRetention = "action-results-retention"
+
+	// ActionResultsExport determines whether action results are
+	// exported to the controller's action results directory before
+	// they are pruned by age.
+	ActionResultsExport = "action-results-export"
+
 	// UpdateStatusHookInterval is how often to run the update-status hook.
 	UpdateStatusHookInterval = "update-status-hook-interval"
 
@@ -482,10 +520,12 @@ var defaultConfigValues = map[string]interface{}{
 	SnapStoreAssertionsKey: "",
 
 	// Status history settings
-	MaxStatusHistoryAge:  DefaultStatusHistoryAge,
-	MaxStatusHistorySize: DefaultStatusHistorySize,
-	MaxActionResultsAge:  DefaultActionResultsAge,
-	MaxActionResultsSize: DefaultActionResultsSize,
+	MaxStatusHistoryAge:    DefaultStatusHistoryAge,
+	MaxStatusHistorySize:   DefaultStatusHistorySize,
+	MaxActionResultsAge:    DefaultActionResultsAge,
+	MaxActionResultsSize:   DefaultActionResultsSize,
+	ActionResultsRetention: "",
+	ActionResultsExport:    false,
 }
 
 // ConfigDefaults returns the config default values
@@ -594,6 +634,16 @@ func Validate(cfg, old *Config) error {
 			return errors.Annotate(err, "invalid syslog forwarding config")
 		}
 	}
+	if lfCfg, ok := cfg.LogFwdHTTP(); ok {
+		if err := lfCfg.Validate(); err != nil {
+			return errors.Annotate(err, "invalid HTTP log forwarding config")
+		}
+	}
+	if lfCfg, ok := cfg.LogFwdFile(); ok {
+		if err := lfCfg.Validate(); err != nil {
+			return errors.Annotate(err, "invalid file log forwarding config")
+		}
+	}
 
 	if uuid := cfg.UUID(); !utils.IsValidUUIDString(uuid) {
 		return errors.Errorf("uuid: expected UUID, got string(%q)", uuid)
@@ -628,6 +678,12 @@ func Validate(cfg, old *Config) error {
 		}
 	}
 
+	if v, ok := cfg.defined[ActionResultsRetention].(string); ok {
+		if _, err := actions.ParseRetentionRules(v); err != nil {
+			return errors.Annotate(err, "invalid action results retention in model configuration")
+		}
+	}
+
 	if v, ok := cfg.defined[UpdateStatusHookInterval].(string); ok {
 		if f, err := time.ParseDuration(v); err != nil {
 			return errors.Annotate(err, "invalid update status hook interval in model configuration")
@@ -1062,12 +1118,60 @@ func (c *Config) LogFwdSyslog() (*syslog.RawConfig, bool) {
 		lfCfg.ClientKey = s.(string)
 	}
 
+	if s, ok := c.defined[LogFwdSyslogFilter]; ok && s != "" {
+		partial = true
+		lfCfg.Filter = s.(string)
+	}
+
+	// Log forwarding may be enabled just to send records to one of
+	// the other sinks, in which case syslog forwarding stays off.
+	if lfCfg.Host == "" && c.otherLogFwdSinkConfigured() {
+		lfCfg.Enabled = false
+	}
+
 	if !partial {
 		return nil, false
 	}
 	return &lfCfg, true
 }
 
+// otherLogFwdSinkConfigured returns true if a log forwarding sink
+// other than syslog has been configured.
+func (c *Config) otherLogFwdSinkConfigured() bool {
+	return c.asString(LogFwdHTTPURL) != "" || c.asString(LogFwdFilePath) != ""
+}
+
+// LogFwdHTTP returns the config for forwarding logs to an HTTP
+// endpoint.
+func (c *Config) LogFwdHTTP() (*httpjson.RawConfig, bool) {
+	lfCfg := httpjson.RawConfig{
+		URL:    c.asString(LogFwdHTTPURL),
+		Format: c.asString(LogFwdHTTPFormat),
+		Filter: c.asString(LogFwdHTTPFilter),
+	}
+	if lfCfg.URL == "" && lfCfg.Format == "" && lfCfg.Filter == "" {
+		return nil, false
+	}
+	enabled, _ := c.defined[LogForwardEnabled].(bool)
+	lfCfg.Enabled = enabled && lfCfg.URL != ""
+	return &lfCfg, true
+}
+
+// LogFwdFile returns the config for forwarding logs to a file on
+// the controller.
+func (c *Config) LogFwdFile() (*logfile.RawConfig, bool) {
+	lfCfg := logfile.RawConfig{
+		Path:   c.asString(LogFwdFilePath),
+		Filter: c.asString(LogFwdFileFilter),
+	}
+	if lfCfg.Path == "" && lfCfg.Filter == "" {
+		return nil, false
+	}
+	enabled, _ := c.defined[LogForwardEnabled].(bool)
+	lfCfg.Enabled = enabled && lfCfg.Path != ""
+	return &lfCfg, true
+}
+
 // FirewallMode returns whether the firewall should
 // manage ports per machine, globally, or not at all.
 // (FwInstance, FwGlobal, or FwNone).
@@ -1316,6 +1420,22 @@ func (c *Config) MaxActionResultsSizeMB() uint {
 	return uint(val)
 }
 
+// ActionResultsRetention returns the rules overriding the maximum age
+// of the results of particular actions, most specific first.
+func (c *Config) ActionResultsRetention() []actions.RetentionRule {
+	// Value has already been validated.
+	rules, _ := actions.ParseRetentionRules(c.asString(ActionResultsRetention))
+	return rules
+}
+
+// ActionResultsExport returns whether action results are exported to
+// the controller's action results directory before they are pruned by
+// age.
+func (c *Config) ActionResultsExport() bool {
+	val, _ := c.defined[ActionResultsExport].(bool)
+	return val
+}
+
 // UpdateStatusHookInterval is how often to run the charm
 // update-status hook.
 func (c *Config) UpdateStatusHookInterval() time.Duration {
@@ -1442,6 +1562,12 @@ var alwaysOptional = schema.Defaults{
 	LogFwdSyslogCACert:     schema.Omit,
 	LogFwdSyslogClientCert: schema.Omit,
 	LogFwdSyslogClientKey:  schema.Omit,
+	LogFwdSyslogFilter:     schema.Omit,
+	LogFwdHTTPURL:          schema.Omit,
+	LogFwdHTTPFormat:       schema.Omit,
+	LogFwdHTTPFilter:       schema.Omit,
+	LogFwdFilePath:         schema.Omit,
+	LogFwdFileFilter:       schema.Omit,
 
 	// Storage related config.
 	// Environ providers will specify their own defaults.
@@ -1493,6 +1619,8 @@ var alwaysOptional = schema.Defaults{
 	MaxStatusHistorySize:         schema.Omit,
 	MaxActionResultsAge:          schema.Omit,
 	MaxActionResultsSize:         schema.Omit,
+	ActionResultsRetention:       schema.Omit,
+	ActionResultsExport:          schema.Omit,
 	UpdateStatusHookInterval:     schema.Omit,
 	EgressSubnets:                schema.Omit,
 	FanConfig:                    schema.Omit,
@@ -1874,7 +2002,7 @@ global or per instance security groups.`,
 		Group:       environschema.EnvironGroup,
 	},
 	LogForwardEnabled: {
-		Description: `Whether syslog forwarding is enabled.`,
+		Description: `Whether log forwarding is enabled.`,
 		Type:        environschema.Tbool,
 		Group:       environschema.EnvironGroup,
 	},
@@ -1898,6 +2026,36 @@ global or per instance security groups.`,
 		Type:        environschema.Tstring,
 		Group:       environschema.EnvironGroup,
 	},
+	LogFwdSyslogFilter: {
+		Description: `The filter selecting the log records forwarded to the syslog server, e.g. "level=WARNING module=juju.worker entity=unit-mysql-*".`,
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	LogFwdHTTPURL: {
+		Description: `The URL of the HTTP endpoint log records are forwarded to.`,
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	LogFwdHTTPFormat: {
+		Description: `The payload format of the HTTP log forwarding endpoint, "elasticsearch" (the default) or "loki".`,
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	LogFwdHTTPFilter: {
+		Description: `The filter selecting the log records forwarded to the HTTP endpoint.`,
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	LogFwdFilePath: {
+		Description: `The path of the file on the controller log records are forwarded to, relative to the model's directory (named for the model UUID) under the logforward directory in the controller's log directory. The file is rotated once it reaches 100MB.`,
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	LogFwdFileFilter: {
+		Description: `The filter selecting the log records forwarded to the file.`,
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
 	"ssl-hostname-verification": {
 		Description: "Whether SSL hostname verification is enabled (default true)",
 		Type:        environschema.Tbool,
@@ -1973,6 +2131,16 @@ data of the store. (default false)`,
 		Type:        environschema.Tstring,
 		Group:       environschema.EnvironGroup,
 	},
+	ActionResultsRetention: {
+		Description: `A comma separated list of rules overriding max-action-results-age, of the form "<action>=<age>", "<application>:<action>=<age>" or "<application>:*=<age>"`,
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	ActionResultsExport: {
+		Description: "Whether action results are exported to the action-results directory in the controller's data directory before they are pruned by age",
+		Type:        environschema.Tbool,
+		Group:       environschema.EnvironGroup,
+	},
 	UpdateStatusHookInterval: {
 		Description: "How often to run the charm update-status hook, in human-readable time format (default 5m, range 1-60m)",
 		Type:        environschema.Tstring,
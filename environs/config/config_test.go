@@ -20,9 +20,12 @@ import (
 	"gopkg.in/juju/environschema.v1"
 
 	"github.com/juju/juju/cert"
+	"github.com/juju/juju/core/actions"
 	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/juju/osenv"
 	jujuversion "github.com/juju/juju/juju/version"
+	"github.com/juju/juju/logfwd/httpjson"
+	"github.com/juju/juju/logfwd/logfile"
 	"github.com/juju/juju/testing"
 )
 
@@ -552,6 +555,48 @@ var configTests = []configTest{
 			"syslog-client-cert": testing.ServerCert,
 			"syslog-client-key":  testing.ServerKey,
 		}),
+	}, {
+		about:       "Valid HTTP and file log forwarding config values",
+		useDefaults: config.UseDefaults,
+		attrs: minimalConfigAttrs.Merge(testing.Attrs{
+			"logforward-http-url":    "https://es.example.com:9200/juju/_bulk",
+			"logforward-http-filter": "level=WARNING",
+			"logforward-file-path":   "forwarded.log",
+		}),
+	}, {
+		about:       "Invalid syslog filter",
+		useDefaults: config.UseDefaults,
+		attrs: minimalConfigAttrs.Merge(testing.Attrs{
+			"logforward-enabled": true,
+			"syslog-host":        "localhost:1234",
+			"syslog-ca-cert":     testing.CACert,
+			"syslog-client-cert": testing.ServerCert,
+			"syslog-client-key":  testing.ServerKey,
+			"syslog-filter":      "level=LOUD",
+		}),
+		err: `invalid syslog forwarding config: filter level "LOUD" not valid`,
+	}, {
+		about:       "Invalid HTTP log forwarding format",
+		useDefaults: config.UseDefaults,
+		attrs: minimalConfigAttrs.Merge(testing.Attrs{
+			"logforward-http-url":    "https://es.example.com:9200/juju/_bulk",
+			"logforward-http-format": "splunk",
+		}),
+		err: `invalid HTTP log forwarding config: format "splunk" not valid`,
+	}, {
+		about:       "Absolute log forwarding file path",
+		useDefaults: config.UseDefaults,
+		attrs: minimalConfigAttrs.Merge(testing.Attrs{
+			"logforward-file-path": "/etc/cron.d/forwarded",
+		}),
+		err: `invalid file log forwarding config: absolute Path "/etc/cron.d/forwarded" not valid`,
+	}, {
+		about:       "Log forwarding file path outside the log directory",
+		useDefaults: config.UseDefaults,
+		attrs: minimalConfigAttrs.Merge(testing.Attrs{
+			"logforward-file-path": "../../../etc/cron.d/forwarded",
+		}),
+		err: `invalid file log forwarding config: Path "../../../etc/cron.d/forwarded" outside the log directory not valid`,
 	}, {
 		about:       "Valid container-inherit-properties",
 		useDefaults: config.UseDefaults,
@@ -1306,6 +1351,40 @@ func (s *ConfigSuite) TestStatusHistoryConfigValues(c *gc.C) {
 	c.Assert(cfg.MaxStatusHistorySizeMB(), gc.Equals, uint(8192))
 }
 
+func (s *ConfigSuite) TestActionResultsRetention(c *gc.C) {
+	cfg := newTestConfig(c, testing.Attrs{})
+	c.Assert(cfg.ActionResultsRetention(), gc.HasLen, 0)
+	c.Assert(cfg.ActionResultsExport(), jc.IsFalse)
+
+	cfg = newTestConfig(c, testing.Attrs{
+		"action-results-retention": "health-check=1h, mysql:backup=2160h",
+		"action-results-export":    true,
+	})
+	c.Assert(cfg.ActionResultsRetention(), jc.DeepEquals, []actions.RetentionRule{
+		{Application: "mysql", Action: "backup", MaxAge: 2160 * time.Hour},
+		{Action: "health-check", MaxAge: time.Hour},
+	})
+	c.Assert(cfg.ActionResultsExport(), jc.IsTrue)
+}
+
+func (s *ConfigSuite) TestActionResultsRetentionInvalid(c *gc.C) {
+	for i, test := range []struct {
+		attrs testing.Attrs
+		err   string
+	}{{
+		attrs: testing.Attrs{"action-results-retention": "backup"},
+		err:   `invalid action results retention in model configuration: invalid retention rule "backup": expected <selector>=<duration>`,
+	}, {
+		attrs: testing.Attrs{"action-results-export": "/var/lib/juju/action-results"},
+		err:   `action-results-export: expected bool, got string\("/var/lib/juju/action-results"\)`,
+	}} {
+		c.Logf("test %d", i)
+		attrs := testing.FakeConfig().Merge(test.attrs)
+		_, err := config.New(config.UseDefaults, attrs)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
 func (s *ConfigSuite) TestUpdateStatusHookIntervalConfigDefault(c *gc.C) {
 	cfg := newTestConfig(c, testing.Attrs{})
 	c.Assert(cfg.UpdateStatusHookInterval(), gc.Equals, 5*time.Minute)
@@ -1325,6 +1404,48 @@ func (s *ConfigSuite) TestEgressSubnets(c *gc.C) {
 	c.Assert(cfg.EgressSubnets(), gc.DeepEquals, []string{"10.0.0.1/32", "192.168.1.1/16"})
 }
 
+func (s *ConfigSuite) TestLogFwdSinks(c *gc.C) {
+	cfg := newTestConfig(c, testing.Attrs{
+		"logforward-enabled":     true,
+		"logforward-http-url":    "http://loki.example.com:3100/loki/api/v1/push",
+		"logforward-http-format": "loki",
+		"logforward-http-filter": "module=juju.worker",
+		"logforward-file-path":   "forwarded.log",
+		"logforward-file-filter": "entity=unit-*",
+	})
+
+	httpCfg, ok := cfg.LogFwdHTTP()
+	c.Assert(ok, jc.IsTrue)
+	c.Check(httpCfg, jc.DeepEquals, &httpjson.RawConfig{
+		Enabled: true,
+		URL:     "http://loki.example.com:3100/loki/api/v1/push",
+		Format:  "loki",
+		Filter:  "module=juju.worker",
+	})
+	fileCfg, ok := cfg.LogFwdFile()
+	c.Assert(ok, jc.IsTrue)
+	c.Check(fileCfg, jc.DeepEquals, &logfile.RawConfig{
+		Enabled: true,
+		Path:    "forwarded.log",
+		Filter:  "entity=unit-*",
+	})
+
+	// Without a syslog host, enabling log forwarding for the other
+	// sinks doesn't enable syslog forwarding.
+	syslogCfg, ok := cfg.LogFwdSyslog()
+	c.Assert(ok, jc.IsTrue)
+	c.Check(syslogCfg.Enabled, jc.IsFalse)
+}
+
+func (s *ConfigSuite) TestLogFwdSinksNotConfigured(c *gc.C) {
+	cfg := newTestConfig(c, testing.Attrs{})
+
+	_, ok := cfg.LogFwdHTTP()
+	c.Check(ok, jc.IsFalse)
+	_, ok = cfg.LogFwdFile()
+	c.Check(ok, jc.IsFalse)
+}
+
 func (s *ConfigSuite) TestCloudInitUserDataFromEnvironment(c *gc.C) {
 	cfg := newTestConfig(c, testing.Attrs{
 		config.CloudInitUserDataKey: validCloudInitUserData,
@@ -0,0 +1,20 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logfwd
+
+// SinkConfig is the configuration for forwarding log records to a
+// single sink. Each type of sink (e.g. syslog) has its own config
+// implementation, found in the sink's sub-package.
+type SinkConfig interface {
+	// IsEnabled returns true if records should be forwarded to
+	// the sink.
+	IsEnabled() bool
+
+	// Validate ensures that the config is valid.
+	Validate() error
+
+	// RecordFilter returns the filter that selects the records
+	// forwarded to the sink.
+	RecordFilter() (Filter, error)
+}
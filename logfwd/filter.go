@@ -0,0 +1,125 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logfwd
+
+import (
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+)
+
+// These are the keys recognized in a filter specification.
+const (
+	filterKeyLevel  = "level"
+	filterKeyModule = "module"
+	filterKeyEntity = "entity"
+)
+
+// Filter selects the log records that are forwarded to a sink.
+// A record must satisfy every kind of criterion that is set; where
+// several modules or entities are given, a record need only match
+// one of them.
+type Filter struct {
+	// Level is the minimum level of the records selected. If it is
+	// loggo.UNSPECIFIED then records of all levels are selected.
+	Level loggo.Level
+
+	// Modules holds the modules whose records are selected. Records
+	// from sub-modules of each module are selected too.
+	Modules []string
+
+	// Entities holds the tags of the entities (e.g. "machine-0" or
+	// "unit-mysql-0") whose records are selected. A tag ending in
+	// "*" selects all entities whose tags start with the preceding
+	// text.
+	Entities []string
+}
+
+// ParseFilter converts a filter specification into a Filter. The
+// specification is a list of "key=value" terms separated by spaces
+// or commas, where key is one of "level", "module" or "entity"; for
+// example:
+//
+//   level=WARNING module=juju.worker entity=unit-mysql-*
+//
+// An empty specification selects all records.
+func ParseFilter(spec string) (Filter, error) {
+	var filter Filter
+	terms := strings.FieldsFunc(spec, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	})
+	for _, term := range terms {
+		parts := strings.SplitN(term, "=", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			return Filter{}, errors.NotValidf("filter term %q", term)
+		}
+		key, value := parts[0], parts[1]
+		switch key {
+		case filterKeyLevel:
+			level, ok := loggo.ParseLevel(value)
+			if !ok {
+				return Filter{}, errors.NotValidf("filter level %q", value)
+			}
+			filter.Level = level
+		case filterKeyModule:
+			filter.Modules = append(filter.Modules, value)
+		case filterKeyEntity:
+			filter.Entities = append(filter.Entities, value)
+		default:
+			return Filter{}, errors.NotValidf("filter key %q", key)
+		}
+	}
+	return filter, nil
+}
+
+// Match returns true if the record is selected by the filter.
+func (f Filter) Match(rec Record) bool {
+	if f.Level != loggo.UNSPECIFIED && rec.Level < f.Level {
+		return false
+	}
+	if len(f.Modules) > 0 && !f.matchModule(rec.Location.Module) {
+		return false
+	}
+	if len(f.Entities) > 0 && !f.matchEntity(rec.Origin.EntityTag()) {
+		return false
+	}
+	return true
+}
+
+// Apply returns the records selected by the filter.
+func (f Filter) Apply(records []Record) []Record {
+	var selected []Record
+	for _, rec := range records {
+		if f.Match(rec) {
+			selected = append(selected, rec)
+		}
+	}
+	return selected
+}
+
+func (f Filter) matchModule(module string) bool {
+	for _, m := range f.Modules {
+		if module == m || strings.HasPrefix(module, m+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func (f Filter) matchEntity(tag string) bool {
+	if tag == "" {
+		return false
+	}
+	for _, e := range f.Entities {
+		if strings.HasSuffix(e, "*") {
+			if strings.HasPrefix(tag, strings.TrimSuffix(e, "*")) {
+				return true
+			}
+		} else if tag == e {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logfwd_test
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/logfwd"
+)
+
+type FilterSuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&FilterSuite{})
+
+func (s *FilterSuite) TestParseFilter(c *gc.C) {
+	filter, err := logfwd.ParseFilter("level=WARNING module=juju.worker,module=juju.apiserver entity=unit-mysql-*")
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(filter, jc.DeepEquals, logfwd.Filter{
+		Level:    loggo.WARNING,
+		Modules:  []string{"juju.worker", "juju.apiserver"},
+		Entities: []string{"unit-mysql-*"},
+	})
+}
+
+func (s *FilterSuite) TestParseFilterEmpty(c *gc.C) {
+	filter, err := logfwd.ParseFilter("")
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(filter, jc.DeepEquals, logfwd.Filter{})
+	c.Check(filter.Match(validRecord), jc.IsTrue)
+}
+
+func (s *FilterSuite) TestParseFilterInvalid(c *gc.C) {
+	for _, test := range []struct {
+		spec string
+		err  string
+	}{{
+		spec: "level",
+		err:  `filter term "level" not valid`,
+	}, {
+		spec: "module=",
+		err:  `filter term "module=" not valid`,
+	}, {
+		spec: "level=LOUD",
+		err:  `filter level "LOUD" not valid`,
+	}, {
+		spec: "host=foo",
+		err:  `filter key "host" not valid`,
+	}} {
+		c.Logf("spec %q", test.spec)
+		_, err := logfwd.ParseFilter(test.spec)
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *FilterSuite) TestMatchLevel(c *gc.C) {
+	filter := logfwd.Filter{Level: loggo.WARNING}
+	rec := validRecord
+
+	rec.Level = loggo.ERROR
+	c.Check(filter.Match(rec), jc.IsTrue)
+	rec.Level = loggo.WARNING
+	c.Check(filter.Match(rec), jc.IsTrue)
+	rec.Level = loggo.INFO
+	c.Check(filter.Match(rec), jc.IsFalse)
+}
+
+func (s *FilterSuite) TestMatchModule(c *gc.C) {
+	filter := logfwd.Filter{Modules: []string{"juju.worker", "juju.state"}}
+	rec := validRecord
+
+	for module, expected := range map[string]bool{
+		"juju.worker":           true,
+		"juju.worker.uniter":    true,
+		"juju.state":            true,
+		"juju.workers":          false,
+		"juju.apiserver.worker": false,
+		"":                      false,
+	} {
+		rec.Location.Module = module
+		c.Check(filter.Match(rec), gc.Equals, expected, gc.Commentf("module %q", module))
+	}
+}
+
+func (s *FilterSuite) TestMatchEntity(c *gc.C) {
+	filter := logfwd.Filter{Entities: []string{"machine-0", "unit-mysql-*"}}
+	rec := validRecord
+
+	for _, test := range []struct {
+		originType logfwd.OriginType
+		name       string
+		expected   bool
+	}{
+		{logfwd.OriginTypeMachine, "0", true},
+		{logfwd.OriginTypeMachine, "1", false},
+		{logfwd.OriginTypeMachine, "0/lxd/0", false},
+		{logfwd.OriginTypeUnit, "mysql/0", true},
+		{logfwd.OriginTypeUnit, "mysql/12", true},
+		{logfwd.OriginTypeUnit, "wordpress/0", false},
+		{logfwd.OriginTypeUser, "a-user", false},
+		{logfwd.OriginTypeUnknown, "", false},
+	} {
+		rec.Origin.Type = test.originType
+		rec.Origin.Name = test.name
+		c.Check(filter.Match(rec), gc.Equals, test.expected, gc.Commentf("%s %q", test.originType, test.name))
+	}
+}
+
+func (s *FilterSuite) TestApply(c *gc.C) {
+	filter := logfwd.Filter{Level: loggo.WARNING}
+	rec0 := validRecord
+	rec0.ID = 10
+	rec1 := validRecord
+	rec1.ID = 11
+	rec1.Level = loggo.DEBUG
+	rec2 := validRecord
+	rec2.ID = 12
+
+	selected := filter.Apply([]logfwd.Record{rec0, rec1, rec2})
+
+	c.Check(selected, jc.DeepEquals, []logfwd.Record{rec0, rec2})
+}
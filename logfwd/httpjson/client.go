@@ -0,0 +1,192 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package httpjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/logfwd"
+)
+
+// HTTPClient is the subset of *http.Client needed by Client.
+type HTTPClient interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// requestTimeout is the longest time a single post may take.
+const requestTimeout = 30 * time.Second
+
+// Client posts log records to an HTTP endpoint.
+type Client struct {
+	// URL is the endpoint records are posted to.
+	URL string
+
+	// Format is the payload format expected by the endpoint.
+	Format string
+
+	// HTTPClient is used to make the requests.
+	HTTPClient HTTPClient
+}
+
+// Open returns a client that posts records to the endpoint described
+// by the config.
+func Open(cfg RawConfig) (*Client, error) {
+	client, err := OpenForClient(cfg, &http.Client{Timeout: requestTimeout})
+	return client, errors.Trace(err)
+}
+
+// OpenForClient returns a client that posts records to the endpoint
+// described by the config, using the given HTTP client.
+func OpenForClient(cfg RawConfig, httpClient HTTPClient) (*Client, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &Client{
+		URL:        cfg.URL,
+		Format:     cfg.format(),
+		HTTPClient: httpClient,
+	}, nil
+}
+
+// Close is part of the logforwarder.SendCloser interface. There's no
+// connection held between posts, so it does nothing.
+func (client Client) Close() error {
+	return nil
+}
+
+// Send posts the records to the endpoint in a single request.
+func (client Client) Send(records []logfwd.Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+	var (
+		body        []byte
+		contentType string
+		err         error
+	)
+	switch client.Format {
+	case FormatElasticsearch:
+		body, err = elasticsearchPayload(records)
+		contentType = "application/x-ndjson"
+	case FormatLoki:
+		body, err = lokiPayload(records)
+		contentType = "application/json"
+	default:
+		return errors.NotSupportedf("format %q", client.Format)
+	}
+	if err != nil {
+		return errors.Annotate(err, "encoding records")
+	}
+
+	req, err := http.NewRequest("POST", client.URL, bytes.NewReader(body))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	resp, err := client.HTTPClient.Do(req)
+	if err != nil {
+		return errors.Annotate(err, "posting records")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		io.Copy(ioutil.Discard, resp.Body)
+		return errors.Errorf("posting records: %s", resp.Status)
+	}
+	if client.Format == FormatElasticsearch {
+		return errors.Trace(checkElasticsearchResponse(resp.Body))
+	}
+	return nil
+}
+
+// elasticsearchPayload returns the records as a bulk API request
+// body, with an index action for each record. Each document is
+// given an ID derived from the record ID, so that records resent
+// after a failure aren't duplicated.
+func elasticsearchPayload(records []logfwd.Record) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, rec := range records {
+		action := map[string]map[string]string{
+			"index": {"_id": fmt.Sprintf("%s-%d", rec.Origin.ModelUUID, rec.ID)},
+		}
+		if err := enc.Encode(action); err != nil {
+			return nil, errors.Trace(err)
+		}
+		if err := enc.Encode(logfwd.NewJSONRecord(rec)); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// checkElasticsearchResponse returns an error if the bulk API
+// reported that any of the records were rejected.
+func checkElasticsearchResponse(body io.Reader) error {
+	var result struct {
+		Errors bool `json:"errors"`
+	}
+	if err := json.NewDecoder(body).Decode(&result); err != nil {
+		return errors.Annotate(err, "decoding bulk response")
+	}
+	if result.Errors {
+		return errors.New("posting records: some records were rejected")
+	}
+	return nil
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// lokiPayload returns the records as a push API request body. The
+// records are grouped into streams by origin and level, keeping
+// the order of the records within each stream.
+func lokiPayload(records []logfwd.Record) ([]byte, error) {
+	var streams []*lokiStream
+	byKey := make(map[string]*lokiStream)
+	for _, rec := range records {
+		labels := map[string]string{
+			"source":          "juju",
+			"controller_uuid": rec.Origin.ControllerUUID,
+			"model_uuid":      rec.Origin.ModelUUID,
+			"level":           rec.Level.String(),
+		}
+		if entity := rec.Origin.EntityTag(); entity != "" {
+			labels["entity"] = entity
+		}
+		key := fmt.Sprintf("%s/%s/%s", rec.Origin.ModelUUID, labels["entity"], labels["level"])
+		stream, ok := byKey[key]
+		if !ok {
+			stream = &lokiStream{Stream: labels}
+			byKey[key] = stream
+			streams = append(streams, stream)
+		}
+		line := rec.Message
+		if loc := rec.Location.String(); loc != "" {
+			line = loc + " " + line
+		}
+		if rec.Location.Module != "" {
+			line = rec.Location.Module + " " + line
+		}
+		stream.Values = append(stream.Values, [2]string{
+			strconv.FormatInt(rec.Timestamp.UnixNano(), 10),
+			line,
+		})
+	}
+	payload := struct {
+		Streams []*lokiStream `json:"streams"`
+	}{streams}
+	data, err := json.Marshal(payload)
+	return data, errors.Trace(err)
+}
@@ -0,0 +1,227 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package httpjson_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/logfwd"
+	"github.com/juju/juju/logfwd/httpjson"
+)
+
+type ClientSuite struct {
+	testing.IsolationSuite
+
+	http *fakeHTTPClient
+	rec  logfwd.Record
+}
+
+var _ = gc.Suite(&ClientSuite{})
+
+func (s *ClientSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.http = &fakeHTTPClient{
+		status: http.StatusOK,
+		body:   `{"took":3,"errors":false,"items":[]}`,
+	}
+	s.rec = logfwd.Record{
+		ID: 10,
+		Origin: logfwd.Origin{
+			ControllerUUID: "9f484882-2f18-4fd2-967d-db9663db7bea",
+			ModelUUID:      "deadbeef-2f18-4fd2-967d-db9663db7bea",
+			Hostname:       "unit-mysql-0.deadbeef-2f18-4fd2-967d-db9663db7bea",
+			Type:           logfwd.OriginTypeUnit,
+			Name:           "mysql/0",
+			Software: logfwd.Software{
+				PrivateEnterpriseNumber: 28978,
+				Name:                    "jujud-unit-agent",
+				Version:                 version.MustParse("2.5.0"),
+			},
+		},
+		Timestamp: time.Date(2018, 10, 1, 12, 30, 0, 0, time.UTC),
+		Level:     loggo.WARNING,
+		Location: logfwd.SourceLocation{
+			Module:   "juju.worker.uniter",
+			Filename: "uniter.go",
+			Line:     42,
+		},
+		Message: "hook failed",
+	}
+}
+
+func (s *ClientSuite) open(c *gc.C, format string) *httpjson.Client {
+	client, err := httpjson.OpenForClient(httpjson.RawConfig{
+		Enabled: true,
+		URL:     "http://logs.example.com/ingest",
+		Format:  format,
+	}, s.http)
+	c.Assert(err, jc.ErrorIsNil)
+	return client
+}
+
+func (s *ClientSuite) TestOpenInvalid(c *gc.C) {
+	_, err := httpjson.OpenForClient(httpjson.RawConfig{Enabled: true}, s.http)
+	c.Assert(err, gc.ErrorMatches, `empty URL not valid`)
+}
+
+func (s *ClientSuite) TestOpenDefaultFormat(c *gc.C) {
+	client := s.open(c, "")
+	c.Assert(client.Format, gc.Equals, httpjson.FormatElasticsearch)
+}
+
+func (s *ClientSuite) TestSendElasticsearch(c *gc.C) {
+	client := s.open(c, httpjson.FormatElasticsearch)
+	rec1 := s.rec
+	rec1.ID = 11
+	rec1.Message = "hook succeeded"
+
+	err := client.Send([]logfwd.Record{s.rec, rec1})
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(s.http.requests, gc.HasLen, 1)
+	req := s.http.requests[0]
+	c.Check(req.method, gc.Equals, "POST")
+	c.Check(req.url, gc.Equals, "http://logs.example.com/ingest")
+	c.Check(req.contentType, gc.Equals, "application/x-ndjson")
+	lines := strings.Split(strings.TrimSuffix(req.body, "\n"), "\n")
+	c.Assert(lines, gc.HasLen, 4)
+	c.Check(lines[0], gc.Equals, `{"index":{"_id":"deadbeef-2f18-4fd2-967d-db9663db7bea-10"}}`)
+	c.Check(lines[2], gc.Equals, `{"index":{"_id":"deadbeef-2f18-4fd2-967d-db9663db7bea-11"}}`)
+	var doc map[string]interface{}
+	err = json.Unmarshal([]byte(lines[1]), &doc)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(doc, jc.DeepEquals, map[string]interface{}{
+		"id":              float64(10),
+		"@timestamp":      "2018-10-01T12:30:00Z",
+		"level":           "WARNING",
+		"module":          "juju.worker.uniter",
+		"location":        "uniter.go:42",
+		"message":         "hook failed",
+		"entity":          "unit-mysql-0",
+		"hostname":        "unit-mysql-0.deadbeef-2f18-4fd2-967d-db9663db7bea",
+		"controller-uuid": "9f484882-2f18-4fd2-967d-db9663db7bea",
+		"model-uuid":      "deadbeef-2f18-4fd2-967d-db9663db7bea",
+		"software":        "jujud-unit-agent",
+		"version":         "2.5.0",
+	})
+}
+
+func (s *ClientSuite) TestSendElasticsearchRejected(c *gc.C) {
+	s.http.body = `{"took":3,"errors":true,"items":[]}`
+	client := s.open(c, httpjson.FormatElasticsearch)
+
+	err := client.Send([]logfwd.Record{s.rec})
+	c.Assert(err, gc.ErrorMatches, `posting records: some records were rejected`)
+}
+
+func (s *ClientSuite) TestSendLoki(c *gc.C) {
+	client := s.open(c, httpjson.FormatLoki)
+	rec1 := s.rec
+	rec1.ID = 11
+	rec1.Level = loggo.INFO
+	rec2 := s.rec
+	rec2.ID = 12
+	rec2.Message = "hook failed again"
+
+	err := client.Send([]logfwd.Record{s.rec, rec1, rec2})
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(s.http.requests, gc.HasLen, 1)
+	req := s.http.requests[0]
+	c.Check(req.contentType, gc.Equals, "application/json")
+	var payload struct {
+		Streams []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][]string        `json:"values"`
+		} `json:"streams"`
+	}
+	err = json.Unmarshal([]byte(req.body), &payload)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(payload.Streams, gc.HasLen, 2)
+	c.Check(payload.Streams[0].Stream, jc.DeepEquals, map[string]string{
+		"source":          "juju",
+		"controller_uuid": "9f484882-2f18-4fd2-967d-db9663db7bea",
+		"model_uuid":      "deadbeef-2f18-4fd2-967d-db9663db7bea",
+		"level":           "WARNING",
+		"entity":          "unit-mysql-0",
+	})
+	c.Check(payload.Streams[0].Values, jc.DeepEquals, [][]string{
+		{"1538397000000000000", "juju.worker.uniter uniter.go:42 hook failed"},
+		{"1538397000000000000", "juju.worker.uniter uniter.go:42 hook failed again"},
+	})
+	c.Check(payload.Streams[1].Stream["level"], gc.Equals, "INFO")
+	c.Check(payload.Streams[1].Values, gc.HasLen, 1)
+}
+
+func (s *ClientSuite) TestSendNoRecords(c *gc.C) {
+	client := s.open(c, httpjson.FormatLoki)
+
+	err := client.Send(nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.http.requests, gc.HasLen, 0)
+}
+
+func (s *ClientSuite) TestSendErrorStatus(c *gc.C) {
+	s.http.status = http.StatusBadRequest
+	client := s.open(c, httpjson.FormatLoki)
+
+	err := client.Send([]logfwd.Record{s.rec})
+	c.Assert(err, gc.ErrorMatches, `posting records: 400 Bad Request`)
+}
+
+func (s *ClientSuite) TestSendRequestError(c *gc.C) {
+	s.http.err = errors.New("connection refused")
+	client := s.open(c, httpjson.FormatLoki)
+
+	err := client.Send([]logfwd.Record{s.rec})
+	c.Assert(err, gc.ErrorMatches, `posting records: connection refused`)
+}
+
+type fakeRequest struct {
+	method      string
+	url         string
+	contentType string
+	body        string
+}
+
+type fakeHTTPClient struct {
+	requests []fakeRequest
+	status   int
+	body     string
+	err      error
+}
+
+func (f *fakeHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	f.requests = append(f.requests, fakeRequest{
+		method:      req.Method,
+		url:         req.URL.String(),
+		contentType: req.Header.Get("Content-Type"),
+		body:        string(body),
+	})
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &http.Response{
+		Status:     fmt.Sprintf("%d %s", f.status, http.StatusText(f.status)),
+		StatusCode: f.status,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(f.body)),
+	}, nil
+}
@@ -0,0 +1,91 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package httpjson
+
+import (
+	"net/url"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/logfwd"
+)
+
+// SinkType identifies HTTP/JSON log forwarding sinks.
+const SinkType = "http"
+
+// These are the supported payload formats.
+const (
+	// FormatElasticsearch posts records in the format accepted by
+	// the Elasticsearch bulk API.
+	FormatElasticsearch = "elasticsearch"
+
+	// FormatLoki posts records in the format accepted by the Loki
+	// push API.
+	FormatLoki = "loki"
+)
+
+// RawConfig holds the raw configuration data for forwarding logs to
+// an HTTP endpoint.
+type RawConfig struct {
+	// Enabled is true if the log forwarding feature is enabled.
+	Enabled bool
+
+	// URL is the endpoint records are posted to, for example
+	// "https://es.example.com:9200/juju/_bulk" or
+	// "http://loki.example.com:3100/loki/api/v1/push".
+	URL string
+
+	// Format is the payload format expected by the endpoint. If it
+	// is empty, FormatElasticsearch is used.
+	Format string
+
+	// Filter is the specification of the filter that selects the
+	// records forwarded to the endpoint. See logfwd.ParseFilter
+	// for the format.
+	Filter string
+}
+
+// IsEnabled is part of the logfwd.SinkConfig interface.
+func (cfg RawConfig) IsEnabled() bool {
+	return cfg.Enabled
+}
+
+// RecordFilter is part of the logfwd.SinkConfig interface.
+func (cfg RawConfig) RecordFilter() (logfwd.Filter, error) {
+	filter, err := logfwd.ParseFilter(cfg.Filter)
+	return filter, errors.Trace(err)
+}
+
+// Validate ensures that the config is currently valid.
+func (cfg RawConfig) Validate() error {
+	if cfg.URL == "" {
+		if cfg.Enabled {
+			return errors.NotValidf("empty URL")
+		}
+	} else {
+		u, err := url.Parse(cfg.URL)
+		if err != nil {
+			return errors.Annotate(err, "parsing URL")
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return errors.NotValidf("URL %q", cfg.URL)
+		}
+	}
+	switch cfg.format() {
+	case FormatElasticsearch, FormatLoki:
+	default:
+		return errors.NotValidf("format %q", cfg.Format)
+	}
+	if _, err := cfg.RecordFilter(); err != nil {
+		return errors.Trace(err)
+	}
+	return nil
+}
+
+func (cfg RawConfig) format() string {
+	if cfg.Format == "" {
+		return FormatElasticsearch
+	}
+	return cfg.Format
+}
@@ -0,0 +1,79 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package httpjson_test
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/logfwd/httpjson"
+)
+
+type ConfigSuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&ConfigSuite{})
+
+func (s *ConfigSuite) TestValidateFull(c *gc.C) {
+	cfg := httpjson.RawConfig{
+		Enabled: true,
+		URL:     "http://loki.example.com:3100/loki/api/v1/push",
+		Format:  httpjson.FormatLoki,
+		Filter:  "level=WARNING",
+	}
+
+	err := cfg.Validate()
+	c.Check(err, jc.ErrorIsNil)
+}
+
+func (s *ConfigSuite) TestValidateZeroValue(c *gc.C) {
+	var cfg httpjson.RawConfig
+	err := cfg.Validate()
+	c.Check(err, jc.ErrorIsNil)
+}
+
+func (s *ConfigSuite) TestValidateMissingURL(c *gc.C) {
+	cfg := httpjson.RawConfig{
+		Enabled: true,
+	}
+
+	err := cfg.Validate()
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+	c.Check(err, gc.ErrorMatches, `empty URL not valid`)
+}
+
+func (s *ConfigSuite) TestValidateBadScheme(c *gc.C) {
+	cfg := httpjson.RawConfig{
+		Enabled: true,
+		URL:     "ftp://es.example.com/juju/_bulk",
+	}
+
+	err := cfg.Validate()
+	c.Check(err, gc.ErrorMatches, `URL "ftp://es.example.com/juju/_bulk" not valid`)
+}
+
+func (s *ConfigSuite) TestValidateBadFormat(c *gc.C) {
+	cfg := httpjson.RawConfig{
+		Enabled: true,
+		URL:     "https://es.example.com/juju/_bulk",
+		Format:  "splunk",
+	}
+
+	err := cfg.Validate()
+	c.Check(err, gc.ErrorMatches, `format "splunk" not valid`)
+}
+
+func (s *ConfigSuite) TestValidateBadFilter(c *gc.C) {
+	cfg := httpjson.RawConfig{
+		Enabled: true,
+		URL:     "https://es.example.com/juju/_bulk",
+		Filter:  "colour=blue",
+	}
+
+	err := cfg.Validate()
+	c.Check(err, gc.ErrorMatches, `filter key "colour" not valid`)
+}
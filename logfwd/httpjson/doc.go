@@ -0,0 +1,7 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The httpjson package holds the tools needed to perform log forwarding
+// from Juju to an HTTP endpoint accepting JSON payloads, such as the
+// Elasticsearch bulk API or the Loki push API.
+package httpjson
@@ -0,0 +1,48 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logfwd
+
+import (
+	"time"
+
+	"github.com/juju/version"
+)
+
+// JSONRecord is the representation of a log record used by sinks
+// that forward records as JSON documents.
+type JSONRecord struct {
+	ID              int64     `json:"id"`
+	Timestamp       time.Time `json:"@timestamp"`
+	Level           string    `json:"level"`
+	Module          string    `json:"module,omitempty"`
+	Location        string    `json:"location,omitempty"`
+	Message         string    `json:"message"`
+	Entity          string    `json:"entity,omitempty"`
+	Hostname        string    `json:"hostname,omitempty"`
+	ControllerUUID  string    `json:"controller-uuid"`
+	ModelUUID       string    `json:"model-uuid"`
+	SoftwareName    string    `json:"software,omitempty"`
+	SoftwareVersion string    `json:"version,omitempty"`
+}
+
+// NewJSONRecord returns the JSON representation of the record.
+func NewJSONRecord(rec Record) JSONRecord {
+	jrec := JSONRecord{
+		ID:             rec.ID,
+		Timestamp:      rec.Timestamp.UTC(),
+		Level:          rec.Level.String(),
+		Module:         rec.Location.Module,
+		Location:       rec.Location.String(),
+		Message:        rec.Message,
+		Entity:         rec.Origin.EntityTag(),
+		Hostname:       rec.Origin.Hostname,
+		ControllerUUID: rec.Origin.ControllerUUID,
+		ModelUUID:      rec.Origin.ModelUUID,
+		SoftwareName:   rec.Origin.Software.Name,
+	}
+	if rec.Origin.Software.Version != (version.Number{}) {
+		jrec.SoftwareVersion = rec.Origin.Software.Version.String()
+	}
+	return jrec
+}
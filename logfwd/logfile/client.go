@@ -0,0 +1,143 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/errors"
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"github.com/juju/juju/logfwd"
+)
+
+const (
+	// maxLogSizeMB is the size a forwarded log file may grow to
+	// before it is rotated.
+	maxLogSizeMB = 100
+
+	// maxLogBackups is the number of rotated forwarded log files
+	// kept, so that a model cannot fill the controller's disk.
+	maxLogBackups = 2
+)
+
+// Client appends log records to a file.
+type Client struct {
+	// Writer is the file records are written to.
+	Writer io.WriteCloser
+}
+
+// Open opens (creating if necessary) the file described by the
+// config, and wraps it in a new client. The config's path is
+// relative to dir, and may not name a file outside it. The file is
+// rotated once it reaches maxLogSizeMB, keeping maxLogBackups old
+// files.
+func Open(dir string, cfg RawConfig) (*Client, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if !filepath.IsAbs(dir) {
+		return nil, errors.NotValidf("relative log directory %q", dir)
+	}
+	path := filepath.Join(dir, filepath.Clean(cfg.Path))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Annotate(err, "creating log directory")
+	}
+	// Check before creating any directories named by the path, so
+	// that a symlink out of dir can't be used to create them
+	// elsewhere, and again afterwards in case one was swapped in.
+	if err := checkWithin(dir, path); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, errors.Annotate(err, "creating log directory")
+	}
+	if err := checkWithin(dir, path); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := primeLogFile(path); err != nil {
+		return nil, errors.Annotate(err, "opening log file")
+	}
+	return &Client{Writer: &lumberjack.Logger{
+		Filename:   path,
+		MaxSize:    maxLogSizeMB,
+		MaxBackups: maxLogBackups,
+		Compress:   true,
+	}}, nil
+}
+
+// primeLogFile creates the log file, if it doesn't already exist,
+// with a mode that rotated files will keep.
+func primeLogFile(path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(f.Close())
+}
+
+// checkWithin returns an error if the directory holding path, once
+// any symlinks are resolved, is not dir or one of its descendants.
+// If the directory doesn't exist yet, its nearest existing ancestor
+// is checked instead.
+func checkWithin(dir, path string) error {
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return errors.Annotate(err, "resolving log directory")
+	}
+	realParent, err := filepath.EvalSymlinks(existingAncestor(filepath.Dir(path)))
+	if err != nil {
+		return errors.Annotate(err, "resolving log file directory")
+	}
+	rel, err := filepath.Rel(realDir, realParent)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return errors.NotValidf("Path %q outside the log directory", path)
+	}
+	return nil
+}
+
+// existingAncestor returns path, or the nearest of its ancestors,
+// that exists.
+func existingAncestor(path string) string {
+	for {
+		if _, err := os.Lstat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
+
+// Close closes the client's file.
+func (client Client) Close() error {
+	return errors.Trace(client.Writer.Close())
+}
+
+// Send appends the records to the file, one JSON document per line.
+// The records are written with a single write, so that a batch is
+// not interleaved with other writers.
+func (client Client) Send(records []logfwd.Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, rec := range records {
+		if err := enc.Encode(logfwd.NewJSONRecord(rec)); err != nil {
+			return errors.Annotate(err, "encoding record")
+		}
+	}
+	if _, err := client.Writer.Write(buf.Bytes()); err != nil {
+		return errors.Annotate(err, "writing records")
+	}
+	return nil
+}
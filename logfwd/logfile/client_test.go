@@ -0,0 +1,201 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logfile_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/juju/loggo"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"github.com/juju/juju/logfwd"
+	"github.com/juju/juju/logfwd/logfile"
+)
+
+type ClientSuite struct {
+	testing.IsolationSuite
+
+	dir  string
+	path string
+	rec  logfwd.Record
+}
+
+var _ = gc.Suite(&ClientSuite{})
+
+func (s *ClientSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.dir = c.MkDir()
+	s.path = filepath.Join(s.dir, "forwarded", "juju.log")
+	s.rec = logfwd.Record{
+		ID: 10,
+		Origin: logfwd.Origin{
+			ControllerUUID: "9f484882-2f18-4fd2-967d-db9663db7bea",
+			ModelUUID:      "deadbeef-2f18-4fd2-967d-db9663db7bea",
+			Type:           logfwd.OriginTypeMachine,
+			Name:           "0",
+		},
+		Timestamp: time.Date(2018, 10, 1, 12, 30, 0, 0, time.UTC),
+		Level:     loggo.INFO,
+		Location: logfwd.SourceLocation{
+			Module: "juju.worker.provisioner",
+			Line:   -1,
+		},
+		Message: "started machine 1",
+	}
+}
+
+func (s *ClientSuite) readLines(c *gc.C) []string {
+	data, err := ioutil.ReadFile(s.path)
+	c.Assert(err, jc.ErrorIsNil)
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+func (s *ClientSuite) TestSend(c *gc.C) {
+	client, err := logfile.Open(s.dir, logfile.RawConfig{Enabled: true, Path: "forwarded/juju.log"})
+	c.Assert(err, jc.ErrorIsNil)
+	rec1 := s.rec
+	rec1.ID = 11
+
+	err = client.Send([]logfwd.Record{s.rec, rec1})
+	c.Assert(err, jc.ErrorIsNil)
+	err = client.Close()
+	c.Assert(err, jc.ErrorIsNil)
+
+	lines := s.readLines(c)
+	c.Assert(lines, gc.HasLen, 2)
+	var doc map[string]interface{}
+	err = json.Unmarshal([]byte(lines[0]), &doc)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(doc, jc.DeepEquals, map[string]interface{}{
+		"id":              float64(10),
+		"@timestamp":      "2018-10-01T12:30:00Z",
+		"level":           "INFO",
+		"module":          "juju.worker.provisioner",
+		"message":         "started machine 1",
+		"entity":          "machine-0",
+		"controller-uuid": "9f484882-2f18-4fd2-967d-db9663db7bea",
+		"model-uuid":      "deadbeef-2f18-4fd2-967d-db9663db7bea",
+	})
+	err = json.Unmarshal([]byte(lines[1]), &doc)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(doc["id"], gc.Equals, float64(11))
+
+	info, err := os.Stat(s.path)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(info.Mode().Perm(), gc.Equals, os.FileMode(0600))
+}
+
+func (s *ClientSuite) TestSendAppends(c *gc.C) {
+	for i := 0; i < 2; i++ {
+		client, err := logfile.Open(s.dir, logfile.RawConfig{Enabled: true, Path: "forwarded/juju.log"})
+		c.Assert(err, jc.ErrorIsNil)
+		err = client.Send([]logfwd.Record{s.rec})
+		c.Assert(err, jc.ErrorIsNil)
+		err = client.Close()
+		c.Assert(err, jc.ErrorIsNil)
+	}
+
+	c.Assert(s.readLines(c), gc.HasLen, 2)
+}
+
+func (s *ClientSuite) TestOpenRotates(c *gc.C) {
+	client, err := logfile.Open(s.dir, logfile.RawConfig{Enabled: true, Path: "forwarded/juju.log"})
+	c.Assert(err, jc.ErrorIsNil)
+	defer client.Close()
+
+	c.Assert(client.Writer, gc.FitsTypeOf, &lumberjack.Logger{})
+	logger := client.Writer.(*lumberjack.Logger)
+	c.Check(logger.Filename, gc.Equals, s.path)
+	c.Check(logger.MaxSize, gc.Equals, 100)
+	c.Check(logger.MaxBackups, gc.Equals, 2)
+	c.Check(logger.Compress, jc.IsTrue)
+}
+
+func (s *ClientSuite) TestOpenInvalid(c *gc.C) {
+	_, err := logfile.Open(s.dir, logfile.RawConfig{Enabled: true, Path: "/tmp/juju.log"})
+	c.Assert(err, gc.ErrorMatches, `absolute Path "/tmp/juju.log" not valid`)
+	_, err = logfile.Open("log", logfile.RawConfig{Enabled: true, Path: "juju.log"})
+	c.Assert(err, gc.ErrorMatches, `relative log directory "log" not valid`)
+}
+
+func (s *ClientSuite) TestOpenOutsideDirectory(c *gc.C) {
+	outside := c.MkDir()
+	for _, path := range []string{
+		"../juju.log",
+		"forwarded/../../juju.log",
+		filepath.Join("..", filepath.Base(outside), "juju.log"),
+		filepath.Join(outside, "juju.log"),
+	} {
+		c.Logf("path %q", path)
+		_, err := logfile.Open(s.dir, logfile.RawConfig{Enabled: true, Path: path})
+		c.Check(err, gc.ErrorMatches, `(absolute )?Path .* not valid`)
+	}
+	_, err := os.Stat(filepath.Join(outside, "juju.log"))
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+}
+
+func (s *ClientSuite) TestOpenSymlinkOutsideDirectory(c *gc.C) {
+	if runtime.GOOS == "windows" {
+		c.Skip("symlinks need privileges on windows")
+	}
+	outside := c.MkDir()
+	err := os.Symlink(outside, filepath.Join(s.dir, "escape"))
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = logfile.Open(s.dir, logfile.RawConfig{Enabled: true, Path: "escape/juju.log"})
+	c.Assert(err, gc.ErrorMatches, `Path .* outside the log directory not valid`)
+	_, err = os.Stat(filepath.Join(outside, "juju.log"))
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+
+	// No directories are created outside before the path is rejected.
+	_, err = logfile.Open(s.dir, logfile.RawConfig{Enabled: true, Path: "escape/sub/juju.log"})
+	c.Assert(err, gc.ErrorMatches, `Path .* outside the log directory not valid`)
+	_, err = os.Stat(filepath.Join(outside, "sub"))
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+}
+
+func (s *ClientSuite) TestValidate(c *gc.C) {
+	for _, test := range []struct {
+		cfg logfile.RawConfig
+		err string
+	}{{
+		cfg: logfile.RawConfig{},
+	}, {
+		cfg: logfile.RawConfig{Enabled: true, Path: "forwarded/juju.log", Filter: "level=ERROR"},
+	}, {
+		cfg: logfile.RawConfig{Enabled: true, Path: "/var/log/juju/forwarded.log"},
+		err: `absolute Path "/var/log/juju/forwarded.log" not valid`,
+	}, {
+		cfg: logfile.RawConfig{Enabled: true, Path: "../forwarded.log"},
+		err: `Path "../forwarded.log" outside the log directory not valid`,
+	}, {
+		cfg: logfile.RawConfig{Enabled: true, Path: "a/../.."},
+		err: `Path "a/../.." outside the log directory not valid`,
+	}, {
+		cfg: logfile.RawConfig{Enabled: true, Path: "."},
+		err: `Path "." outside the log directory not valid`,
+	}, {
+		cfg: logfile.RawConfig{Enabled: true},
+		err: `empty Path not valid`,
+	}, {
+		cfg: logfile.RawConfig{Enabled: true, Path: "forwarded.log", Filter: "level"},
+		err: `filter term "level" not valid`,
+	}} {
+		err := test.cfg.Validate()
+		if test.err == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, gc.ErrorMatches, test.err)
+		}
+	}
+}
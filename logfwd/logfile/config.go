@@ -0,0 +1,72 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logfile
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/logfwd"
+)
+
+// SinkType identifies file log forwarding sinks.
+const SinkType = "file"
+
+// RawConfig holds the raw configuration data for forwarding logs to
+// a local file.
+type RawConfig struct {
+	// Enabled is true if the log forwarding feature is enabled.
+	Enabled bool
+
+	// Path is the path of the file records are appended to,
+	// relative to the directory the sink is confined to (see Open).
+	// The file and its directory are created if necessary.
+	Path string
+
+	// Filter is the specification of the filter that selects the
+	// records forwarded to the file. See logfwd.ParseFilter for
+	// the format.
+	Filter string
+}
+
+// IsEnabled is part of the logfwd.SinkConfig interface.
+func (cfg RawConfig) IsEnabled() bool {
+	return cfg.Enabled
+}
+
+// RecordFilter is part of the logfwd.SinkConfig interface.
+func (cfg RawConfig) RecordFilter() (logfwd.Filter, error) {
+	filter, err := logfwd.ParseFilter(cfg.Filter)
+	return filter, errors.Trace(err)
+}
+
+// Validate ensures that the config is currently valid.
+func (cfg RawConfig) Validate() error {
+	if cfg.Path == "" {
+		if cfg.Enabled {
+			return errors.NotValidf("empty Path")
+		}
+	} else if err := validatePath(cfg.Path); err != nil {
+		return errors.Trace(err)
+	}
+	if _, err := cfg.RecordFilter(); err != nil {
+		return errors.Trace(err)
+	}
+	return nil
+}
+
+// validatePath ensures that the path names a file within, rather than
+// outside of or equal to, the directory it is relative to.
+func validatePath(path string) error {
+	if filepath.IsAbs(path) {
+		return errors.NotValidf("absolute Path %q", path)
+	}
+	clean := filepath.Clean(path)
+	if clean == "." || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return errors.NotValidf("Path %q outside the log directory", path)
+	}
+	return nil
+}
@@ -0,0 +1,7 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The logfile package holds the tools needed to perform log forwarding
+// from Juju to a file on the controller, with one JSON document per
+// line.
+package logfile
@@ -5,6 +5,7 @@ package logfwd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/juju/errors"
 	"github.com/juju/version"
@@ -188,6 +189,15 @@ func (o Origin) Validate() error {
 	return nil
 }
 
+// EntityTag returns the tag of the entity that generated the record,
+// or "" if it isn't known.
+func (o Origin) EntityTag() string {
+	if o.Type == OriginTypeUnknown || o.Name == "" {
+		return ""
+	}
+	return o.Type.String() + "-" + strings.Replace(o.Name, "/", "-", -1)
+}
+
 // Software describes a running application.
 type Software struct {
 	// PrivateEnterpriseNumber is the IANA-registered "SMI Network
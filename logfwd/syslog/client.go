@@ -57,14 +57,30 @@ func Open(cfg RawConfig) (*Client, error) {
 	return client, errors.Trace(err)
 }
 
+// OpenWithTimeout connects to a remote syslog host, giving up if the
+// connection isn't made within the timeout, and wraps that connection
+// in a new client.
+func OpenWithTimeout(cfg RawConfig, timeout time.Duration) (*Client, error) {
+	client, err := OpenForSenderWithTimeout(cfg, &senderOpener{}, timeout)
+	return client, errors.Trace(err)
+}
+
 // OpenForSender connects to a remote syslog host and wraps that
 // connection in a new client.
 func OpenForSender(cfg RawConfig, opener SenderOpener) (*Client, error) {
+	client, err := OpenForSenderWithTimeout(cfg, opener, 0)
+	return client, errors.Trace(err)
+}
+
+// OpenForSenderWithTimeout connects to a remote syslog host, giving
+// up if the connection isn't made within the timeout, and wraps that
+// connection in a new client. A zero timeout means no timeout.
+func OpenForSenderWithTimeout(cfg RawConfig, opener SenderOpener, timeout time.Duration) (*Client, error) {
 	if err := cfg.Validate(); err != nil {
 		return nil, errors.Trace(err)
 	}
 
-	sender, err := open(cfg, opener)
+	sender, err := open(cfg, opener, timeout)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -75,13 +91,12 @@ func OpenForSender(cfg RawConfig, opener SenderOpener) (*Client, error) {
 	return client, nil
 }
 
-func open(cfg RawConfig, opener SenderOpener) (Sender, error) {
+func open(cfg RawConfig, opener SenderOpener, timeout time.Duration) (Sender, error) {
 	tlsCfg, err := cfg.tlsConfig()
 	if err != nil {
 		return nil, errors.Annotate(err, "constructing TLS config")
 	}
 
-	var timeout time.Duration
 	dial, err := opener.DialFunc(tlsCfg, timeout)
 	if err != nil {
 		return nil, errors.Annotate(err, "obtaining dialer")
@@ -69,6 +69,27 @@ func (s *ClientSuite) TestOpen(c *gc.C) {
 	c.Check(client.Sender, gc.Equals, s.sender)
 }
 
+func (s *ClientSuite) TestOpenWithTimeout(c *gc.C) {
+	cfg := syslog.RawConfig{
+		Enabled:    true,
+		Host:       "a.b.c:9876",
+		CACert:     coretesting.CACert,
+		ClientCert: coretesting.ServerCert,
+		ClientKey:  coretesting.ServerKey,
+	}
+	senderOpener := &stubSenderOpener{
+		stub:       s.stub,
+		ReturnOpen: s.sender,
+	}
+
+	client, err := syslog.OpenForSenderWithTimeout(cfg, senderOpener, 10*time.Second)
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.stub.CheckCallNames(c, "DialFunc", "Open")
+	c.Check(s.stub.Calls()[0].Args[1], gc.Equals, 10*time.Second)
+	c.Check(client.Sender, gc.Equals, s.sender)
+}
+
 func (s *ClientSuite) TestClose(c *gc.C) {
 	client := syslog.Client{Sender: s.sender}
 
@@ -10,8 +10,13 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/juju/utils/cert"
+
+	"github.com/juju/juju/logfwd"
 )
 
+// SinkType identifies syslog log forwarding sinks.
+const SinkType = "syslog"
+
 // RawConfig holds the raw configuration data for a connection to a
 // syslog forwarding target.
 type RawConfig struct {
@@ -37,6 +42,22 @@ type RawConfig struct {
 	// ClientKey is the TLS private key (x.509, PEM-encoded) to use
 	// when connecting.
 	ClientKey string
+
+	// Filter is the specification of the filter that selects the
+	// records forwarded to the syslog host. See logfwd.ParseFilter
+	// for the format.
+	Filter string
+}
+
+// IsEnabled is part of the logfwd.SinkConfig interface.
+func (cfg RawConfig) IsEnabled() bool {
+	return cfg.Enabled
+}
+
+// RecordFilter is part of the logfwd.SinkConfig interface.
+func (cfg RawConfig) RecordFilter() (logfwd.Filter, error) {
+	filter, err := logfwd.ParseFilter(cfg.Filter)
+	return filter, errors.Trace(err)
 }
 
 // Validate ensures that the config is currently valid.
@@ -44,6 +65,9 @@ func (cfg RawConfig) Validate() error {
 	if err := cfg.validateHost(); err != nil {
 		return errors.Trace(err)
 	}
+	if _, err := cfg.RecordFilter(); err != nil {
+		return errors.Trace(err)
+	}
 
 	if cfg.Enabled || cfg.ClientKey != "" || cfg.ClientCert != "" || cfg.CACert != "" {
 		if _, err := cfg.tlsConfig(); err != nil {
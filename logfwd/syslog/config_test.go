@@ -4,11 +4,13 @@
 package syslog_test
 
 import (
+	"github.com/juju/loggo"
 	"github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
 	"github.com/juju/juju/cert"
+	"github.com/juju/juju/logfwd"
 	"github.com/juju/juju/logfwd/syslog"
 	coretesting "github.com/juju/juju/testing"
 )
@@ -222,6 +224,32 @@ func (s *ConfigSuite) TestRawValidateCertKeyMismatch(c *gc.C) {
 	c.Check(err, gc.ErrorMatches, `validating TLS config: parsing client key pair: (crypto/)?tls: private key does not match public key`)
 }
 
+func (s *ConfigSuite) TestRawValidateBadFilter(c *gc.C) {
+	cfg := syslog.RawConfig{
+		Host:       "a.b.c:9876",
+		CACert:     coretesting.CACert,
+		ClientCert: coretesting.ServerCert,
+		ClientKey:  coretesting.ServerKey,
+		Filter:     "level=LOUD",
+	}
+
+	err := cfg.Validate()
+	c.Check(err, gc.ErrorMatches, `filter level "LOUD" not valid`)
+}
+
+func (s *ConfigSuite) TestRecordFilter(c *gc.C) {
+	cfg := syslog.RawConfig{
+		Filter: "level=ERROR module=juju.worker",
+	}
+
+	filter, err := cfg.RecordFilter()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(filter, jc.DeepEquals, logfwd.Filter{
+		Level:   loggo.ERROR,
+		Modules: []string{"juju.worker"},
+	})
+}
+
 var invalidCert = `
 -----BEGIN CERTIFICATE-----
 MIIBOgIBAAJAZabKgKInuOxj5vDWLwHHQtK3/45KB+32D15w94Nt83BmuGxo90lw
@@ -35,6 +35,8 @@ type PrecheckBackend interface {
 	ControllerBackend() (PrecheckBackend, error)
 	CloudCredential(tag names.CloudCredentialTag) (state.Credential, error)
 	ListPendingResources(string) ([]resource.Resource, error)
+	ActionScheduleCount() (int, error)
+	RunningOperationCount() (int, error)
 }
 
 // Pool defines the interface to a StatePool used by the migration
@@ -144,6 +146,10 @@ func SourcePrecheck(
 		return errors.New("cleanup needed")
 	}
 
+	if err := ctx.checkActions(); err != nil {
+		return errors.Trace(err)
+	}
+
 	// Check the source controller.
 	controllerBackend, err := backend.ControllerBackend()
 	if err != nil {
@@ -184,6 +190,27 @@ func (ctx *precheckContext) checkModel() error {
 	return nil
 }
 
+// checkActions refuses to migrate a model with action schedules or
+// running operations, because the model description can't carry them
+// to the target controller.
+func (ctx *precheckContext) checkActions() error {
+	schedules, err := ctx.backend.ActionScheduleCount()
+	if err != nil {
+		return errors.Annotate(err, "checking action schedules")
+	}
+	if schedules > 0 {
+		return errors.Errorf("model has %d action schedule(s); remove them before migrating", schedules)
+	}
+	operations, err := ctx.backend.RunningOperationCount()
+	if err != nil {
+		return errors.Annotate(err, "checking operations")
+	}
+	if operations > 0 {
+		return errors.Errorf("model has %d running operation(s); wait for them to finish before migrating", operations)
+	}
+	return nil
+}
+
 // TargetPrecheck checks the state of the target controller to make
 // sure that the preconditions for model migration are met. The
 // backend provided must be for the target controller.
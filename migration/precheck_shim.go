@@ -111,6 +111,32 @@ func (s *precheckShim) ListPendingResources(app string) ([]resource.Resource, er
 	return resources, nil
 }
 
+// ActionScheduleCount implements PrecheckBackend.
+func (s *precheckShim) ActionScheduleCount() (int, error) {
+	model, err := s.State.Model()
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	schedules, err := model.AllActionSchedules()
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	return len(schedules), nil
+}
+
+// RunningOperationCount implements PrecheckBackend.
+func (s *precheckShim) RunningOperationCount() (int, error) {
+	model, err := s.State.Model()
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	operations, err := model.RunningOperations()
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	return len(operations), nil
+}
+
 // ControllerBackend implements PrecheckBackend.
 func (s *precheckShim) ControllerBackend() (PrecheckBackend, error) {
 	return PrecheckShim(s.controllerState, s.controllerState)
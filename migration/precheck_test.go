@@ -107,6 +107,34 @@ func (*SourcePrecheckSuite) TestCleanupsNeeded(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "cleanup needed")
 }
 
+func (*SourcePrecheckSuite) TestActionSchedulesError(c *gc.C) {
+	backend := newFakeBackend()
+	backend.actionSchedulesErr = errors.New("boom")
+	err := sourcePrecheck(backend)
+	c.Assert(err, gc.ErrorMatches, "checking action schedules: boom")
+}
+
+func (*SourcePrecheckSuite) TestActionSchedules(c *gc.C) {
+	backend := newFakeBackend()
+	backend.actionSchedules = 2
+	err := sourcePrecheck(backend)
+	c.Assert(err, gc.ErrorMatches, `model has 2 action schedule\(s\); remove them before migrating`)
+}
+
+func (*SourcePrecheckSuite) TestRunningOperationsError(c *gc.C) {
+	backend := newFakeBackend()
+	backend.runningOperationsErr = errors.New("boom")
+	err := sourcePrecheck(backend)
+	c.Assert(err, gc.ErrorMatches, "checking operations: boom")
+}
+
+func (*SourcePrecheckSuite) TestRunningOperations(c *gc.C) {
+	backend := newFakeBackend()
+	backend.runningOperations = 1
+	err := sourcePrecheck(backend)
+	c.Assert(err, gc.ErrorMatches, `model has 1 running operation\(s\); wait for them to finish before migrating`)
+}
+
 func (s *SourcePrecheckSuite) TestIsUpgradingError(c *gc.C) {
 	backend := newFakeBackend()
 	backend.controllerBackend.isUpgradingErr = errors.New("boom")
@@ -784,6 +812,12 @@ type fakeBackend struct {
 	pendingResources    []resource.Resource
 	pendingResourcesErr error
 
+	actionSchedules    int
+	actionSchedulesErr error
+
+	runningOperations    int
+	runningOperationsErr error
+
 	controllerBackend *fakeBackend
 }
 
@@ -831,6 +865,14 @@ func (b *fakeBackend) ListPendingResources(app string) ([]resource.Resource, err
 	return b.pendingResources, b.pendingResourcesErr
 }
 
+func (b *fakeBackend) ActionScheduleCount() (int, error) {
+	return b.actionSchedules, b.actionSchedulesErr
+}
+
+func (b *fakeBackend) RunningOperationCount() (int, error) {
+	return b.runningOperations, b.runningOperationsErr
+}
+
 func (b *fakeBackend) ControllerBackend() (migration.PrecheckBackend, error) {
 	if b.controllerBackend == nil {
 		return b, nil
@@ -76,6 +76,13 @@ func NewMongoTimestamp(t time.Time) bson.MongoTimestamp {
 	return bson.MongoTimestamp(unixTime << 32)
 }
 
+// MongoTimestampTime returns the time, to the second, that the
+// bson.MongoTimestamp given represents. It is the inverse of
+// NewMongoTimestamp.
+func MongoTimestampTime(ts bson.MongoTimestamp) time.Time {
+	return time.Unix(int64(ts>>32), 0).UTC()
+}
+
 // GetOplog returns the the oplog collection in the local database.
 func GetOplog(session *mgo.Session) *mgo.Collection {
 	return session.DB("local").C("oplog.rs")
@@ -4,7 +4,10 @@
 package state
 
 import (
+	"regexp"
+	"sort"
 	"time"
+	"unicode/utf8"
 
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
@@ -13,10 +16,20 @@ import (
 	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
 	"gopkg.in/mgo.v2/txn"
+
+	"github.com/juju/juju/core/actions"
 )
 
 const (
 	actionMarker = "_a_"
+
+	// maxActionMessages is the number of progress messages kept for
+	// an action; older messages are dropped as new ones are logged.
+	maxActionMessages = 100
+
+	// maxActionMessageLength is the longest progress message, in
+	// bytes, kept for an action; longer messages are truncated.
+	maxActionMessageLength = 1024
 )
 
 var (
@@ -104,6 +117,21 @@ type actionDoc struct {
 
 	// Results are the structured results from the action.
 	Results map[string]interface{} `bson:"results"`
+
+	// Operation is the ID of the operation that queued the action, if
+	// any.
+	Operation string `bson:"operation,omitempty"`
+
+	// Logs holds the progress messages logged by the action while it
+	// runs, in the order they were logged.
+	Logs []ActionMessage `bson:"messages,omitempty"`
+}
+
+// ActionMessage represents a progress message logged by a running
+// action.
+type ActionMessage struct {
+	Timestamp time.Time `bson:"timestamp"`
+	Message   string    `bson:"message"`
 }
 
 // action represents an instruction to do some "action" and is expected
@@ -162,6 +190,12 @@ func (a *action) Results() (map[string]interface{}, string) {
 	return a.doc.Results, a.doc.Message
 }
 
+// Messages returns the progress messages logged by the action, in
+// the order they were logged.
+func (a *action) Messages() []ActionMessage {
+	return a.doc.Logs
+}
+
 // Tag implements the Entity interface and returns a names.Tag that
 // is a names.ActionTag.
 func (a *action) Tag() names.Tag {
@@ -210,6 +244,41 @@ func (a *action) Begin() (Action, error) {
 	return m.Action(a.Id())
 }
 
+// Log adds a progress message to the action. It asserts that the
+// action is currently running. Only the most recent messages are
+// kept, and long messages are truncated.
+func (a *action) Log(message string) error {
+	err := a.st.db().RunTransaction([]txn.Op{{
+		C:      actionsC,
+		Id:     a.doc.DocId,
+		Assert: bson.D{{"status", ActionRunning}},
+		Update: bson.D{{"$push", bson.D{{"messages", bson.D{
+			{"$each", []ActionMessage{{
+				Timestamp: a.st.clock().Now().UTC(),
+				Message:   truncateActionMessage(message),
+			}}},
+			{"$slice", -maxActionMessages},
+		}}}}},
+	}})
+	if err == txn.ErrAborted {
+		return errors.Errorf("cannot log message to action %q: action not running", a.Id())
+	}
+	return errors.Annotatef(err, "cannot log message to action %q", a.Id())
+}
+
+// truncateActionMessage returns the message cut down to at most
+// maxActionMessageLength bytes, without splitting a character.
+func truncateActionMessage(message string) string {
+	if len(message) <= maxActionMessageLength {
+		return message
+	}
+	end := maxActionMessageLength
+	for end > 0 && !utf8.RuneStart(message[end]) {
+		end--
+	}
+	return message[:end]
+}
+
 // Finish removes action from the pending queue and captures the output
 // and end state of the action.
 func (a *action) Finish(results ActionResults) (Action, error) {
@@ -225,7 +294,7 @@ func (a *action) removeAndLog(finalStatus ActionStatus, results map[string]inter
 		return nil, errors.Trace(err)
 	}
 
-	err = m.st.db().RunTransaction([]txn.Op{
+	ops := []txn.Op{
 		{
 			C:  actionsC,
 			Id: a.doc.DocId,
@@ -245,7 +314,21 @@ func (a *action) removeAndLog(finalStatus ActionStatus, results map[string]inter
 			C:      actionNotificationsC,
 			Id:     m.st.docID(ensureActionMarker(a.Receiver()) + a.Id()),
 			Remove: true,
-		}})
+		}}
+	if a.doc.Operation != "" {
+		// Count the action towards the progress of its operation,
+		// which is advanced by the action scheduler worker.
+		inc := bson.D{{"completed", 1}}
+		if finalStatus != ActionCompleted {
+			inc = append(inc, bson.DocElem{"failed", 1})
+		}
+		ops = append(ops, txn.Op{
+			C:      operationsC,
+			Id:     m.st.docID(a.doc.Operation),
+			Update: bson.D{{"$inc", inc}},
+		})
+	}
+	err = m.st.db().RunTransaction(ops)
 	if err != nil {
 		return nil, err
 	}
@@ -483,8 +566,108 @@ func (st *State) matchingActionsByReceiverAndStatus(tag names.Tag, statusConditi
 // PruneActions removes action entries until
 // only logs newer than <maxLogTime> remain and also ensures
 // that the collection is smaller than <maxLogsMB> after the
-// deletion.
-func PruneActions(st *State, maxHistoryTime time.Duration, maxHistoryMB int) error {
-	err := pruneCollection(st, maxHistoryTime, maxHistoryMB, actionsC, "completed", GoTime)
-	return errors.Trace(err)
+// deletion. The results of the actions matched by a retention
+// rule are kept for the rule's maximum age instead.
+func PruneActions(st *State, maxHistoryTime time.Duration, maxHistoryMB int, retention ...actions.RetentionRule) error {
+	entries, closer := st.db().GetRawCollection(actionsC)
+	defer closer()
+
+	p := collectionPruner{
+		st:       st,
+		coll:     entries,
+		maxAge:   maxHistoryTime,
+		maxSize:  maxHistoryMB,
+		ageField: "completed",
+		timeUnit: GoTime,
+	}
+	if err := p.validate(); err != nil {
+		return errors.Trace(err)
+	}
+	for _, agePruner := range actionAgePruners(p, retention) {
+		if err := agePruner.pruneByAge(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return errors.Trace(p.pruneBySize())
+}
+
+// PrunableActions returns the completed actions that PruneActions
+// would remove by age, so that their results can be kept elsewhere
+// before they are pruned. The actions are ordered by completion time,
+// and at most limit of them are returned, starting at offset, so that
+// they can be read a page at a time.
+func PrunableActions(st *State, maxHistoryTime time.Duration, offset, limit int, retention ...actions.RetentionRule) ([]Action, error) {
+	entries, closer := st.db().GetRawCollection(actionsC)
+	defer closer()
+
+	p := collectionPruner{
+		st:       st,
+		coll:     entries,
+		maxAge:   maxHistoryTime,
+		ageField: "completed",
+		timeUnit: GoTime,
+	}
+	var queries []bson.D
+	for _, agePruner := range actionAgePruners(p, retention) {
+		queries = append(queries, agePruner.ageQuery())
+	}
+	var docs []actionDoc
+	query := entries.Find(bson.D{{"$or", queries}}).Sort("completed", "_id").Skip(offset).Limit(limit)
+	if err := query.All(&docs); err != nil {
+		return nil, errors.Annotate(err, "cannot get prunable actions")
+	}
+	result := make([]Action, len(docs))
+	for i, doc := range docs {
+		result[i] = newAction(st, doc)
+	}
+	return result, nil
+}
+
+// actionAgePruners returns a pruner for each retention rule with a
+// maximum age, and one for the actions matched by none of the rules
+// using the model's maximum age. Each action is matched by the most
+// specific rule that selects it.
+func actionAgePruners(p collectionPruner, retention []actions.RetentionRule) []collectionPruner {
+	rules := make([]actions.RetentionRule, len(retention))
+	copy(rules, retention)
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].Specificity() > rules[j].Specificity()
+	})
+
+	var pruners []collectionPruner
+	var moreSpecific []bson.D
+	for _, rule := range rules {
+		selector := retentionRuleSelector(rule)
+		if rule.MaxAge > 0 {
+			rulePruner := p
+			rulePruner.maxAge = rule.MaxAge
+			rulePruner.filter = selector
+			if len(moreSpecific) > 0 {
+				rulePruner.filter = append(rulePruner.filter, bson.DocElem{"$nor", moreSpecific})
+			}
+			pruners = append(pruners, rulePruner)
+		}
+		moreSpecific = append(moreSpecific, selector)
+	}
+	if p.maxAge > 0 {
+		if len(moreSpecific) > 0 {
+			p.filter = bson.D{{"$nor", moreSpecific}}
+		}
+		pruners = append(pruners, p)
+	}
+	return pruners
+}
+
+// retentionRuleSelector returns the query matching the actions
+// selected by the rule.
+func retentionRuleSelector(rule actions.RetentionRule) bson.D {
+	var selector bson.D
+	if rule.Application != "" {
+		pattern := "^" + regexp.QuoteMeta(rule.Application+"/")
+		selector = append(selector, bson.DocElem{"receiver", bson.RegEx{Pattern: pattern}})
+	}
+	if rule.Action != "" {
+		selector = append(selector, bson.DocElem{"name", rule.Action})
+	}
+	return selector
 }
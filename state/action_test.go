@@ -17,6 +17,7 @@ import (
 	gc "gopkg.in/check.v1"
 	"gopkg.in/juju/names.v2"
 
+	"github.com/juju/juju/core/actions"
 	"github.com/juju/juju/state"
 	statetesting "github.com/juju/juju/state/testing"
 	"github.com/juju/juju/testing"
@@ -484,6 +485,85 @@ func (s *ActionSuite) TestComplete(c *gc.C) {
 	c.Assert(len(actions), gc.Equals, 0)
 }
 
+func (s *ActionSuite) TestLog(c *gc.C) {
+	clock := testclock.NewClock(coretesting.NonZeroTime().Round(time.Second))
+	err := s.State.SetClockForTesting(clock)
+	c.Assert(err, jc.ErrorIsNil)
+
+	a, err := s.unit.AddAction("snapshot", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Messages can only be logged while the action is running.
+	err = a.Log("too soon")
+	c.Assert(err, gc.ErrorMatches, `cannot log message to action ".*": action not running`)
+
+	a, err = a.Begin()
+	c.Assert(err, jc.ErrorIsNil)
+	err = a.Log("starting snapshot")
+	c.Assert(err, jc.ErrorIsNil)
+	clock.Advance(time.Minute)
+	err = a.Log("snapshot 50% done")
+	c.Assert(err, jc.ErrorIsNil)
+
+	model, err := s.State.Model()
+	c.Assert(err, jc.ErrorIsNil)
+	a, err = model.Action(a.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	messages := a.Messages()
+	c.Assert(messages, gc.HasLen, 2)
+	c.Check(messages[0].Message, gc.Equals, "starting snapshot")
+	c.Check(messages[0].Timestamp.UTC(), gc.Equals, clock.Now().Add(-time.Minute).UTC())
+	c.Check(messages[1].Message, gc.Equals, "snapshot 50% done")
+	c.Check(messages[1].Timestamp.UTC(), gc.Equals, clock.Now().UTC())
+
+	// The messages are kept once the action finishes, but no more may
+	// be logged.
+	a, err = a.Finish(state.ActionResults{Status: state.ActionCompleted})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(a.Messages(), gc.HasLen, 2)
+	err = a.Log("too late")
+	c.Assert(err, gc.ErrorMatches, `cannot log message to action ".*": action not running`)
+}
+
+func (s *ActionSuite) TestLogIsCapped(c *gc.C) {
+	a, err := s.unit.AddAction("snapshot", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	a, err = a.Begin()
+	c.Assert(err, jc.ErrorIsNil)
+	for i := 0; i < 105; i++ {
+		err = a.Log(fmt.Sprintf("message %d", i))
+		c.Assert(err, jc.ErrorIsNil)
+	}
+
+	model, err := s.State.Model()
+	c.Assert(err, jc.ErrorIsNil)
+	a, err = model.Action(a.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	messages := a.Messages()
+	c.Assert(messages, gc.HasLen, 100)
+	c.Check(messages[0].Message, gc.Equals, "message 5")
+	c.Check(messages[99].Message, gc.Equals, "message 104")
+}
+
+func (s *ActionSuite) TestLogTruncatesLongMessages(c *gc.C) {
+	a, err := s.unit.AddAction("snapshot", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	a, err = a.Begin()
+	c.Assert(err, jc.ErrorIsNil)
+	// The 1024 byte limit falls in the middle of a two byte character,
+	// which must be dropped rather than split.
+	err = a.Log(strings.Repeat("x", 1023) + "\u00e9" + "tail")
+	c.Assert(err, jc.ErrorIsNil)
+
+	model, err := s.State.Model()
+	c.Assert(err, jc.ErrorIsNil)
+	a, err = model.Action(a.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	messages := a.Messages()
+	c.Assert(messages, gc.HasLen, 1)
+	c.Check(messages[0].Message, gc.Equals, strings.Repeat("x", 1023))
+}
+
 func (s *ActionSuite) TestFindActionTagsByPrefix(c *gc.C) {
 	prefix := "feedbeef"
 	uuidMock := uuidMockHelper{}
@@ -906,6 +986,9 @@ var _ state.ActionReceiver = (*mockAR)(nil)
 func (r mockAR) AddAction(name string, payload map[string]interface{}) (state.Action, error) {
 	return nil, nil
 }
+func (r mockAR) PrepareActionPayload(name string, payload map[string]interface{}) (map[string]interface{}, error) {
+	return payload, nil
+}
 func (r mockAR) CancelAction(state.Action) (state.Action, error) { return nil, nil }
 func (r mockAR) WatchActionNotifications() state.StringsWatcher  { return nil }
 func (r mockAR) Actions() ([]state.Action, error)                { return nil, nil }
@@ -1099,3 +1182,79 @@ func (s *ActionPruningSuite) TestDoNotPruneIncompleteActions(c *gc.C) {
 
 	c.Assert(actionsLen, gc.Equals, numZeroValueEntries)
 }
+
+func (s *ActionPruningSuite) TestPruneActionsWithRetention(c *gc.C) {
+	clock := testclock.NewClock(time.Now())
+	err := s.State.SetClockForTesting(clock)
+	c.Assert(err, jc.ErrorIsNil)
+	mysql := s.Factory.MakeUnit(c, &factory.UnitParams{
+		Application: s.Factory.MakeApplication(c, &factory.ApplicationParams{Name: "mysql"}),
+	})
+	wordpress := s.Factory.MakeUnit(c, &factory.UnitParams{
+		Application: s.Factory.MakeApplication(c, &factory.ApplicationParams{Name: "wordpress"}),
+	})
+
+	now := clock.Now()
+	state.PrimeNamedActions(c, now.Add(-10*time.Hour), mysql, "backup", 1)
+	state.PrimeNamedActions(c, now.Add(-10*time.Hour), wordpress, "backup", 1)
+	state.PrimeNamedActions(c, now.Add(-2*time.Hour), mysql, "health-check", 1)
+	state.PrimeNamedActions(c, now.Add(-2*time.Hour), wordpress, "snapshot", 1)
+	state.PrimeNamedActions(c, now.Add(-4*time.Hour), wordpress, "snapshot", 1)
+
+	rules, err := actions.ParseRetentionRules("backup=24h,mysql:backup=5h,health-check=1h")
+	c.Assert(err, jc.ErrorIsNil)
+
+	prunable, err := state.PrunableActions(s.State, 3*time.Hour, 0, 10, rules...)
+	c.Assert(err, jc.ErrorIsNil)
+	var pruned []string
+	for _, action := range prunable {
+		pruned = append(pruned, action.Receiver()+" "+action.Name())
+	}
+	c.Check(pruned, jc.SameContents, []string{
+		"mysql/0 backup",
+		"mysql/0 health-check",
+		"wordpress/0 snapshot",
+	})
+
+	// The prunable actions can be read a page at a time, oldest first.
+	page, err := state.PrunableActions(s.State, 3*time.Hour, 0, 2, rules...)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(page, gc.HasLen, 2)
+	c.Check(page[0].Receiver()+" "+page[0].Name(), gc.Equals, "mysql/0 backup")
+	c.Check(page[1].Receiver()+" "+page[1].Name(), gc.Equals, "wordpress/0 snapshot")
+	page, err = state.PrunableActions(s.State, 3*time.Hour, 2, 2, rules...)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(page, gc.HasLen, 1)
+	c.Check(page[0].Receiver()+" "+page[0].Name(), gc.Equals, "mysql/0 health-check")
+
+	err = state.PruneActions(s.State, 3*time.Hour, 0, rules...)
+	c.Assert(err, jc.ErrorIsNil)
+
+	remaining, err := mysql.Actions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(remaining, gc.HasLen, 0)
+	remaining, err = wordpress.Actions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(remaining, gc.HasLen, 2)
+	kept := []string{remaining[0].Name(), remaining[1].Name()}
+	c.Check(kept, jc.SameContents, []string{"backup", "snapshot"})
+}
+
+func (s *ActionPruningSuite) TestPruneActionsRetentionNeverPrunes(c *gc.C) {
+	clock := testclock.NewClock(time.Now())
+	err := s.State.SetClockForTesting(clock)
+	c.Assert(err, jc.ErrorIsNil)
+	unit := s.Factory.MakeUnit(c, nil)
+	state.PrimeNamedActions(c, clock.Now().Add(-10*time.Hour), unit, "backup", 2)
+	state.PrimeNamedActions(c, clock.Now().Add(-10*time.Hour), unit, "snapshot", 2)
+
+	err = state.PruneActions(s.State, time.Hour, 0, actions.RetentionRule{Action: "backup"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	remaining, err := unit.Actions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(remaining, gc.HasLen, 2)
+	for _, action := range remaining {
+		c.Check(action.Name(), gc.Equals, "backup")
+	}
+}
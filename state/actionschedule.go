@@ -0,0 +1,453 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/juju/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+
+	"github.com/juju/juju/core/actions"
+)
+
+// maxActionScheduleRuns is the number of runs kept in the history of
+// an action schedule.
+const maxActionScheduleRuns = 20
+
+// actionScheduleDoc describes an action that is queued at a given
+// time, or repeatedly according to a cron-style schedule.
+type actionScheduleDoc struct {
+	DocId     string `bson:"_id"`
+	Id        string `bson:"id"`
+	ModelUUID string `bson:"model-uuid"`
+
+	// Receiver is the receiver of the action as it was given when
+	// the schedule was added; see ActionScheduleArgs.
+	Receiver   string                 `bson:"receiver"`
+	Name       string                 `bson:"name"`
+	Parameters map[string]interface{} `bson:"parameters"`
+
+	// Schedule is the cron-style schedule of a recurring action. It
+	// is empty for an action that is queued once.
+	Schedule string `bson:"schedule,omitempty"`
+
+	Created time.Time `bson:"created"`
+
+	// NextRun is the time the action is next due to be queued. It is
+	// the zero time once a one-off schedule has run.
+	NextRun time.Time `bson:"next-run"`
+
+	// Runs holds the most recent runs of the schedule, oldest first.
+	Runs []actionScheduleRunDoc `bson:"runs"`
+}
+
+type actionScheduleRunDoc struct {
+	Time     time.Time `bson:"time"`
+	ActionId string    `bson:"action-id,omitempty"`
+	Error    string    `bson:"error,omitempty"`
+}
+
+// ActionScheduleArgs holds the details of an action schedule to add.
+type ActionScheduleArgs struct {
+	// Receiver identifies the receiver of the action. It is recorded
+	// as given, so that references such as "<application>/leader"
+	// are resolved each time the schedule runs.
+	Receiver string
+
+	// Name is the name of the action to queue.
+	Name string
+
+	// Parameters holds the action's parameters, if any.
+	Parameters map[string]interface{}
+
+	// At is the time at which the action is queued once. Exactly one
+	// of At and Schedule must be specified.
+	At time.Time
+
+	// Schedule is the cron-style schedule on which the action is
+	// queued repeatedly.
+	Schedule string
+}
+
+// Validate returns an error if the arguments are not valid.
+func (args ActionScheduleArgs) Validate() error {
+	if args.Receiver == "" {
+		return errors.NotValidf("empty receiver")
+	}
+	if args.Name == "" {
+		return errors.NotValidf("empty action name")
+	}
+	if args.At.IsZero() == (args.Schedule == "") {
+		return errors.NotValidf("specifying both or neither of a time and a schedule")
+	}
+	if args.Schedule != "" {
+		if _, err := actions.ParseSchedule(args.Schedule); err != nil {
+			return errors.NewNotValid(err, "")
+		}
+	}
+	return nil
+}
+
+// ActionScheduleRun records a single run of an action schedule.
+type ActionScheduleRun struct {
+	// Time is when the schedule ran.
+	Time time.Time
+
+	// ActionId is the ID of the action that was queued, if any.
+	ActionId string
+
+	// Error describes why no action was queued, if that was the case.
+	Error string
+}
+
+// ActionSchedule represents an action that is queued at a given time,
+// or repeatedly on a schedule.
+type ActionSchedule struct {
+	st  *State
+	doc actionScheduleDoc
+}
+
+// Id returns the ID of the schedule within its model.
+func (s *ActionSchedule) Id() string {
+	return s.doc.Id
+}
+
+// Receiver returns the receiver of the action, as it was given when
+// the schedule was added.
+func (s *ActionSchedule) Receiver() string {
+	return s.doc.Receiver
+}
+
+// Name returns the name of the action.
+func (s *ActionSchedule) Name() string {
+	return s.doc.Name
+}
+
+// Parameters returns the parameters of the action.
+func (s *ActionSchedule) Parameters() map[string]interface{} {
+	return s.doc.Parameters
+}
+
+// Schedule returns the cron-style schedule of a recurring action, or
+// the empty string if the action is queued once.
+func (s *ActionSchedule) Schedule() string {
+	return s.doc.Schedule
+}
+
+// Created returns the time the schedule was added.
+func (s *ActionSchedule) Created() time.Time {
+	return s.doc.Created.UTC()
+}
+
+// NextRun returns the time the action is next due to be queued, or
+// the zero time if the schedule will not run again.
+func (s *ActionSchedule) NextRun() time.Time {
+	if s.doc.NextRun.IsZero() {
+		return time.Time{}
+	}
+	return s.doc.NextRun.UTC()
+}
+
+// Runs returns the most recent runs of the schedule, oldest first.
+func (s *ActionSchedule) Runs() []ActionScheduleRun {
+	runs := make([]ActionScheduleRun, len(s.doc.Runs))
+	for i, run := range s.doc.Runs {
+		runs[i] = ActionScheduleRun{
+			Time:     run.Time.UTC(),
+			ActionId: run.ActionId,
+			Error:    run.Error,
+		}
+	}
+	return runs
+}
+
+// Refresh refreshes the contents of the schedule from the database.
+func (s *ActionSchedule) Refresh() error {
+	schedules, closer := s.st.db().GetCollection(actionSchedulesC)
+	defer closer()
+
+	var doc actionScheduleDoc
+	err := schedules.FindId(s.doc.DocId).One(&doc)
+	if err == mgo.ErrNotFound {
+		return errors.NotFoundf("action schedule %q", s.doc.Id)
+	}
+	if err != nil {
+		return errors.Annotatef(err, "cannot refresh action schedule %q", s.doc.Id)
+	}
+	s.doc = doc
+	return nil
+}
+
+// Run queues the schedule's action for the given receiver, records the
+// run in the schedule's history, and advances the schedule to its next
+// run after now. Runs missed while the schedule wasn't being serviced
+// are not made up; the schedule runs once and then resumes. If the
+// action can't be queued, an error is returned and the schedule is left
+// unchanged; see RecordFailure.
+func (s *ActionSchedule) Run(receiver ActionReceiver, now time.Time) (Action, error) {
+	payload, err := receiver.PrepareActionPayload(s.doc.Name, s.doc.Parameters)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	receiverCollectionName, receiverId, err := s.st.tagToCollectionAndId(receiver.Tag())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	doc, ndoc, err := newActionDoc(s.st, receiver.Tag(), s.doc.Name, payload)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	now = now.UTC().Truncate(time.Second)
+	run := actionScheduleRunDoc{
+		Time:     now,
+		ActionId: s.st.localID(doc.DocId),
+	}
+	var updated actionScheduleDoc
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := s.checkUnchanged(); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		if notDead, err := isNotDead(s.st, receiverCollectionName, receiverId); err != nil {
+			return nil, errors.Trace(err)
+		} else if !notDead {
+			return nil, ErrDead
+		}
+		if updated, err = s.advanced(run, now); err != nil {
+			return nil, errors.Trace(err)
+		}
+		return []txn.Op{{
+			C:      receiverCollectionName,
+			Id:     receiverId,
+			Assert: notDeadDoc,
+		}, {
+			C:      actionsC,
+			Id:     doc.DocId,
+			Assert: txn.DocMissing,
+			Insert: doc,
+		}, {
+			C:      actionNotificationsC,
+			Id:     ndoc.DocId,
+			Assert: txn.DocMissing,
+			Insert: ndoc,
+		}, s.advanceOp(updated)}, nil
+	}
+	if err := s.st.db().Run(buildTxn); err != nil {
+		return nil, errors.Annotatef(err, "cannot run action schedule %q", s.doc.Id)
+	}
+	s.doc = updated
+	return newAction(s.st, doc), nil
+}
+
+// RecordFailure records a run of the schedule that didn't queue an
+// action, and advances the schedule to its next run after now.
+func (s *ActionSchedule) RecordFailure(now time.Time, reason error) error {
+	now = now.UTC().Truncate(time.Second)
+	run := actionScheduleRunDoc{
+		Time:  now,
+		Error: reason.Error(),
+	}
+	var updated actionScheduleDoc
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := s.checkUnchanged(); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		var err error
+		if updated, err = s.advanced(run, now); err != nil {
+			return nil, errors.Trace(err)
+		}
+		return []txn.Op{s.advanceOp(updated)}, nil
+	}
+	if err := s.st.db().Run(buildTxn); err != nil {
+		return errors.Annotatef(err, "cannot record failure of action schedule %q", s.doc.Id)
+	}
+	s.doc = updated
+	return nil
+}
+
+// checkUnchanged returns an error if the schedule has been removed, or
+// has already been advanced, since it was read.
+func (s *ActionSchedule) checkUnchanged() error {
+	nextRun := s.doc.NextRun
+	if err := s.Refresh(); err != nil {
+		return errors.Trace(err)
+	}
+	if !s.doc.NextRun.Equal(nextRun) {
+		return errors.Errorf("action schedule %q has already run", s.doc.Id)
+	}
+	return nil
+}
+
+// advanced returns the schedule's document with the run recorded and
+// the next run moved on to the first run after now.
+func (s *ActionSchedule) advanced(run actionScheduleRunDoc, now time.Time) (actionScheduleDoc, error) {
+	doc := s.doc
+	doc.NextRun = time.Time{}
+	if doc.Schedule != "" {
+		schedule, err := actions.ParseSchedule(doc.Schedule)
+		if err != nil {
+			return actionScheduleDoc{}, errors.Trace(err)
+		}
+		doc.NextRun = schedule.Next(now)
+	}
+	doc.Runs = append(append([]actionScheduleRunDoc(nil), s.doc.Runs...), run)
+	if len(doc.Runs) > maxActionScheduleRuns {
+		doc.Runs = doc.Runs[len(doc.Runs)-maxActionScheduleRuns:]
+	}
+	return doc, nil
+}
+
+// advanceOp returns an operation that updates the schedule to the
+// given advanced document, provided it hasn't run in the meantime.
+func (s *ActionSchedule) advanceOp(updated actionScheduleDoc) txn.Op {
+	return txn.Op{
+		C:      actionSchedulesC,
+		Id:     s.doc.DocId,
+		Assert: bson.D{{"next-run", s.doc.NextRun}},
+		Update: bson.D{{"$set", bson.D{
+			{"next-run", updated.NextRun},
+			{"runs", updated.Runs},
+		}}},
+	}
+}
+
+// AddActionSchedule adds a schedule on which an action is queued.
+func (m *Model) AddActionSchedule(args ActionScheduleArgs) (*ActionSchedule, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Annotate(err, "cannot add action schedule")
+	}
+	now := m.st.nowToTheSecond()
+	nextRun := args.At.UTC().Truncate(time.Second)
+	if args.Schedule != "" {
+		schedule, err := actions.ParseSchedule(args.Schedule)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		nextRun = schedule.Next(now)
+	}
+	seq, err := sequence(m.st, "actionschedule")
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	id := strconv.Itoa(seq)
+	doc := actionScheduleDoc{
+		DocId:      m.st.docID(id),
+		Id:         id,
+		ModelUUID:  m.UUID(),
+		Receiver:   args.Receiver,
+		Name:       args.Name,
+		Parameters: args.Parameters,
+		Schedule:   args.Schedule,
+		Created:    now,
+		NextRun:    nextRun,
+	}
+	ops := []txn.Op{{
+		C:      actionSchedulesC,
+		Id:     doc.DocId,
+		Assert: txn.DocMissing,
+		Insert: &doc,
+	}}
+	if err := m.st.db().RunTransaction(ops); err != nil {
+		return nil, errors.Annotate(err, "cannot add action schedule")
+	}
+	return &ActionSchedule{st: m.st, doc: doc}, nil
+}
+
+// ActionSchedule returns the action schedule with the given ID.
+func (m *Model) ActionSchedule(id string) (*ActionSchedule, error) {
+	schedules, closer := m.st.db().GetCollection(actionSchedulesC)
+	defer closer()
+
+	var doc actionScheduleDoc
+	err := schedules.FindId(id).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("action schedule %q", id)
+	}
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot get action schedule %q", id)
+	}
+	return &ActionSchedule{st: m.st, doc: doc}, nil
+}
+
+// AllActionSchedules returns all of the action schedules in the model,
+// ordered by ID.
+func (m *Model) AllActionSchedules() ([]*ActionSchedule, error) {
+	return m.actionSchedules(nil)
+}
+
+// DueActionSchedules returns the action schedules that are due to run
+// at the given time, ordered by ID.
+func (m *Model) DueActionSchedules(now time.Time) ([]*ActionSchedule, error) {
+	return m.actionSchedules(bson.D{{"next-run", bson.D{
+		{"$gt", time.Time{}},
+		{"$lte", now},
+	}}})
+}
+
+func (m *Model) actionSchedules(query bson.D) ([]*ActionSchedule, error) {
+	schedules, closer := m.st.db().GetCollection(actionSchedulesC)
+	defer closer()
+
+	var docs []actionScheduleDoc
+	if err := schedules.Find(query).All(&docs); err != nil {
+		return nil, errors.Annotate(err, "cannot get action schedules")
+	}
+	result := make([]*ActionSchedule, len(docs))
+	for i, doc := range docs {
+		result[i] = &ActionSchedule{st: m.st, doc: doc}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, _ := strconv.Atoi(result[i].doc.Id)
+		b, _ := strconv.Atoi(result[j].doc.Id)
+		return a < b
+	})
+	return result, nil
+}
+
+// NextActionScheduleRun returns the earliest time at which any action
+// schedule in the model is due to run, or the zero time if none are.
+func (m *Model) NextActionScheduleRun() (time.Time, error) {
+	schedules, closer := m.st.db().GetCollection(actionSchedulesC)
+	defer closer()
+
+	var doc actionScheduleDoc
+	err := schedules.Find(bson.D{{"next-run", bson.D{{"$gt", time.Time{}}}}}).Sort("next-run").One(&doc)
+	if err == mgo.ErrNotFound {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, errors.Annotate(err, "cannot get next action schedule run")
+	}
+	return doc.NextRun.UTC(), nil
+}
+
+// RemoveActionSchedule removes the action schedule with the given ID.
+// Actions already queued by the schedule are not affected.
+func (m *Model) RemoveActionSchedule(id string) error {
+	ops := []txn.Op{{
+		C:      actionSchedulesC,
+		Id:     m.st.docID(id),
+		Assert: txn.DocExists,
+		Remove: true,
+	}}
+	err := m.st.db().RunTransaction(ops)
+	if err == txn.ErrAborted {
+		return errors.NotFoundf("action schedule %q", id)
+	}
+	return errors.Annotatef(err, "cannot remove action schedule %q", id)
+}
+
+// WatchActionSchedules returns a NotifyWatcher that notifies when
+// action schedules in the model are added, removed or run.
+func (m *Model) WatchActionSchedules() NotifyWatcher {
+	return newNotifyCollWatcher(m.st, actionSchedulesC, isLocalID(m.st))
+}
@@ -0,0 +1,291 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+	statetesting "github.com/juju/juju/state/testing"
+)
+
+type ActionScheduleSuite struct {
+	ConnSuite
+	unit *state.Unit
+}
+
+var _ = gc.Suite(&ActionScheduleSuite{})
+
+func (s *ActionScheduleSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	ch := s.AddTestingCharm(c, "dummy")
+	app := s.AddTestingApplication(c, "dummy", ch)
+	var err error
+	s.unit, err = app.AddUnit(state.AddUnitParams{})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ActionScheduleSuite) addSchedule(c *gc.C, schedule string, at time.Time) *state.ActionSchedule {
+	sched, err := s.Model.AddActionSchedule(state.ActionScheduleArgs{
+		Receiver:   s.unit.Tag().String(),
+		Name:       "snapshot",
+		Parameters: map[string]interface{}{"outfile": "out.tar.bz2"},
+		At:         at,
+		Schedule:   schedule,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return sched
+}
+
+func (s *ActionScheduleSuite) TestAddOneOff(c *gc.C) {
+	at := s.Clock.Now().Add(time.Hour)
+	sched := s.addSchedule(c, "", at)
+
+	c.Check(sched.Id(), gc.Equals, "0")
+	c.Check(sched.Receiver(), gc.Equals, s.unit.Tag().String())
+	c.Check(sched.Name(), gc.Equals, "snapshot")
+	c.Check(sched.Parameters(), jc.DeepEquals, map[string]interface{}{"outfile": "out.tar.bz2"})
+	c.Check(sched.Schedule(), gc.Equals, "")
+	c.Check(sched.NextRun(), gc.Equals, at.UTC().Truncate(time.Second))
+	c.Check(sched.Runs(), gc.HasLen, 0)
+
+	fromDB, err := s.Model.ActionSchedule("0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(fromDB.NextRun(), gc.Equals, sched.NextRun())
+	c.Check(fromDB.Created(), gc.Equals, sched.Created())
+}
+
+func (s *ActionScheduleSuite) TestAddRecurring(c *gc.C) {
+	now := time.Date(2018, 10, 10, 12, 34, 56, 0, time.UTC)
+	s.Clock.Advance(now.Sub(s.Clock.Now()))
+	sched := s.addSchedule(c, "@daily", time.Time{})
+
+	c.Check(sched.Schedule(), gc.Equals, "@daily")
+	c.Check(sched.NextRun(), gc.Equals, time.Date(2018, 10, 11, 0, 0, 0, 0, time.UTC))
+}
+
+func (s *ActionScheduleSuite) TestAddInvalid(c *gc.C) {
+	for i, test := range []struct {
+		args state.ActionScheduleArgs
+		err  string
+	}{{
+		args: state.ActionScheduleArgs{Name: "snapshot", Schedule: "@daily"},
+		err:  "cannot add action schedule: empty receiver not valid",
+	}, {
+		args: state.ActionScheduleArgs{Receiver: "unit-dummy-0", Schedule: "@daily"},
+		err:  "cannot add action schedule: empty action name not valid",
+	}, {
+		args: state.ActionScheduleArgs{Receiver: "unit-dummy-0", Name: "snapshot"},
+		err:  "cannot add action schedule: specifying both or neither of a time and a schedule not valid",
+	}, {
+		args: state.ActionScheduleArgs{
+			Receiver: "unit-dummy-0",
+			Name:     "snapshot",
+			Schedule: "@daily",
+			At:       time.Now(),
+		},
+		err: "cannot add action schedule: specifying both or neither of a time and a schedule not valid",
+	}, {
+		args: state.ActionScheduleArgs{Receiver: "unit-dummy-0", Name: "snapshot", Schedule: "* * *"},
+		err:  `cannot add action schedule: invalid schedule "\* \* \*": expected 5 fields, got 3`,
+	}} {
+		c.Logf("test %d", i)
+		_, err := s.Model.AddActionSchedule(test.args)
+		c.Check(err, gc.ErrorMatches, test.err)
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+	}
+}
+
+func (s *ActionScheduleSuite) TestAllActionSchedules(c *gc.C) {
+	for i := 0; i < 11; i++ {
+		s.addSchedule(c, "@hourly", time.Time{})
+	}
+	schedules, err := s.Model.AllActionSchedules()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(schedules, gc.HasLen, 11)
+	for i, sched := range schedules {
+		c.Check(sched.Id(), gc.Equals, fmt.Sprint(i))
+	}
+}
+
+func (s *ActionScheduleSuite) TestRemove(c *gc.C) {
+	s.addSchedule(c, "@hourly", time.Time{})
+	err := s.Model.RemoveActionSchedule("0")
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = s.Model.ActionSchedule("0")
+	c.Check(err, jc.Satisfies, errors.IsNotFound)
+
+	err = s.Model.RemoveActionSchedule("0")
+	c.Check(err, gc.ErrorMatches, `action schedule "0" not found`)
+	c.Check(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *ActionScheduleSuite) TestDueActionSchedules(c *gc.C) {
+	now := s.Clock.Now()
+	s.addSchedule(c, "", now.Add(time.Minute))
+	s.addSchedule(c, "", now.Add(time.Hour))
+
+	due, err := s.Model.DueActionSchedules(now)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(due, gc.HasLen, 0)
+
+	due, err = s.Model.DueActionSchedules(now.Add(30 * time.Minute))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(due, gc.HasLen, 1)
+	c.Check(due[0].Id(), gc.Equals, "0")
+
+	next, err := s.Model.NextActionScheduleRun()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(next, gc.Equals, now.Add(time.Minute).UTC().Truncate(time.Second))
+}
+
+func (s *ActionScheduleSuite) TestNextActionScheduleRunNone(c *gc.C) {
+	next, err := s.Model.NextActionScheduleRun()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(next.IsZero(), jc.IsTrue)
+}
+
+func (s *ActionScheduleSuite) TestRunOneOff(c *gc.C) {
+	now := s.Clock.Now()
+	sched := s.addSchedule(c, "", now)
+
+	action, err := sched.Run(s.unit, now)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(action.Name(), gc.Equals, "snapshot")
+	c.Check(action.Receiver(), gc.Equals, s.unit.Name())
+	c.Check(action.Parameters(), jc.DeepEquals, map[string]interface{}{"outfile": "out.tar.bz2"})
+	c.Check(action.Status(), gc.Equals, state.ActionPending)
+
+	c.Check(sched.NextRun().IsZero(), jc.IsTrue)
+	expectedRuns := []state.ActionScheduleRun{{
+		Time:     now.UTC().Truncate(time.Second),
+		ActionId: action.Id(),
+	}}
+	c.Check(sched.Runs(), jc.DeepEquals, expectedRuns)
+
+	err = sched.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(sched.NextRun().IsZero(), jc.IsTrue)
+	c.Check(sched.Runs(), jc.DeepEquals, expectedRuns)
+
+	due, err := s.Model.DueActionSchedules(now.Add(time.Hour))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(due, gc.HasLen, 0)
+}
+
+func (s *ActionScheduleSuite) TestRunRecurring(c *gc.C) {
+	now := time.Date(2018, 10, 10, 12, 34, 56, 0, time.UTC)
+	s.Clock.Advance(now.Sub(s.Clock.Now()))
+	sched := s.addSchedule(c, "0 * * * *", time.Time{})
+	c.Assert(sched.NextRun(), gc.Equals, time.Date(2018, 10, 10, 13, 0, 0, 0, time.UTC))
+
+	// Runs that were missed aren't made up.
+	later := time.Date(2018, 10, 10, 16, 0, 10, 0, time.UTC)
+	_, err := sched.Run(s.unit, later)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(sched.NextRun(), gc.Equals, time.Date(2018, 10, 10, 17, 0, 0, 0, time.UTC))
+
+	actions, err := s.unit.PendingActions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(actions, gc.HasLen, 1)
+}
+
+func (s *ActionScheduleSuite) TestRunStale(c *gc.C) {
+	now := s.Clock.Now()
+	sched := s.addSchedule(c, "", now)
+	stale, err := s.Model.ActionSchedule("0")
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = sched.Run(s.unit, now)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = stale.Run(s.unit, now)
+	c.Assert(err, gc.ErrorMatches, `cannot run action schedule "0": action schedule "0" has already run`)
+
+	actions, err := s.unit.PendingActions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(actions, gc.HasLen, 1)
+}
+
+func (s *ActionScheduleSuite) TestRunDeadReceiver(c *gc.C) {
+	now := s.Clock.Now()
+	sched := s.addSchedule(c, "", now)
+	err := s.unit.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = sched.Run(s.unit, now)
+	c.Assert(errors.Cause(err), gc.Equals, state.ErrDead)
+	c.Check(sched.NextRun(), gc.Equals, now.UTC().Truncate(time.Second))
+}
+
+func (s *ActionScheduleSuite) TestRunInvalidParameters(c *gc.C) {
+	now := s.Clock.Now()
+	sched, err := s.Model.AddActionSchedule(state.ActionScheduleArgs{
+		Receiver:   s.unit.Tag().String(),
+		Name:       "snapshot",
+		Parameters: map[string]interface{}{"outfile": 5},
+		At:         now,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = sched.Run(s.unit, now)
+	c.Assert(err, gc.ErrorMatches, `validation failed: \(root\)\.outfile : must be of type string, given 5`)
+	c.Check(sched.NextRun(), gc.Equals, now.UTC().Truncate(time.Second))
+}
+
+func (s *ActionScheduleSuite) TestRecordFailure(c *gc.C) {
+	now := s.Clock.Now()
+	sched := s.addSchedule(c, "", now)
+
+	err := sched.RecordFailure(now, errors.New("boom"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(sched.NextRun().IsZero(), jc.IsTrue)
+
+	err = sched.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(sched.Runs(), jc.DeepEquals, []state.ActionScheduleRun{{
+		Time:  now.UTC().Truncate(time.Second),
+		Error: "boom",
+	}})
+}
+
+func (s *ActionScheduleSuite) TestRunHistoryIsCapped(c *gc.C) {
+	sched := s.addSchedule(c, "* * * * *", time.Time{})
+	now := s.Clock.Now()
+	for i := 0; i < 25; i++ {
+		err := sched.RecordFailure(now.Add(time.Duration(i)*time.Minute), errors.Errorf("failure %d", i))
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	err := sched.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	runs := sched.Runs()
+	c.Assert(runs, gc.HasLen, 20)
+	c.Check(runs[0].Error, gc.Equals, "failure 5")
+	c.Check(runs[19].Error, gc.Equals, "failure 24")
+}
+
+func (s *ActionScheduleSuite) TestWatchActionSchedules(c *gc.C) {
+	w := s.Model.WatchActionSchedules()
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertOneChange()
+
+	now := s.Clock.Now()
+	sched := s.addSchedule(c, "", now)
+	wc.AssertOneChange()
+
+	_, err := sched.Run(s.unit, now)
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+
+	err = s.Model.RemoveActionSchedule(sched.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+}
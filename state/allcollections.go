@@ -4,6 +4,8 @@
 package state
 
 import (
+	"sort"
+
 	"github.com/juju/juju/state/cloudimagemetadata"
 	"gopkg.in/mgo.v2"
 
@@ -399,6 +401,22 @@ func allCollections() collectionSchema {
 		},
 		actionNotificationsC: {},
 
+		// This collection holds the schedules on which actions are
+		// queued at a later time, or repeatedly.
+		actionSchedulesC: {
+			indexes: []mgo.Index{{
+				Key: []string{"model-uuid", "next-run"},
+			}},
+		},
+
+		// This collection holds the operations that queue an action on
+		// many receivers in batches, tracking their progress.
+		operationsC: {
+			indexes: []mgo.Index{{
+				Key: []string{"model-uuid", "status"},
+			}},
+		},
+
 		// -----
 
 		// This collection holds information associated with charm payloads.
@@ -521,6 +539,21 @@ func allCollections() collectionSchema {
 	return result
 }
 
+// InitialCollections returns the names of the collections that are
+// created in the juju database when a controller is set up, rather than
+// when they're first written to; a dump of the database made by this
+// version of Juju always holds them.
+func InitialCollections() []string {
+	var names []string
+	for name, info := range allCollections() {
+		if info.explicitCreate != nil || len(info.indexes) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
 // These constants are used to avoid sprinkling the package with any more
 // magic strings. If a collection deserves documentation, please document
 // it in allCollections, above; and please keep this list sorted for easy
@@ -528,6 +561,7 @@ func allCollections() collectionSchema {
 const (
 	actionNotificationsC       = "actionnotifications"
 	actionresultsC             = "actionresults"
+	actionSchedulesC           = "actionschedules"
 	actionsC                   = "actions"
 	annotationsC               = "annotations"
 	autocertCacheC             = "autocertCache"
@@ -574,6 +608,7 @@ const (
 	modelsC                    = "models"
 	modelEntityRefsC           = "modelEntityRefs"
 	openedPortsC               = "openedPorts"
+	operationsC                = "operations"
 	payloadsC                  = "payloads"
 	permissionsC               = "permissions"
 	podSpecsC                  = "podSpecs"
@@ -378,6 +378,7 @@ func (u *backingUnit) updated(st *State, store *multiwatcherStore, id string) er
 		Series:      u.Series,
 		MachineId:   u.MachineId,
 		Subordinate: u.Principal != "",
+		Life:        multiwatcher.Life(u.Life.String()),
 	}
 	if u.CharmURL != nil {
 		info.CharmURL = u.CharmURL.String()
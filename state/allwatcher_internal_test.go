@@ -195,6 +195,7 @@ func (s *allWatcherBaseSuite) setUpScenario(c *gc.C, st *State, units int, inclu
 			MachineId:   m.Id(),
 			Ports:       []multiwatcher.Port{},
 			Subordinate: false,
+			Life:        multiwatcher.Life("alive"),
 			WorkloadStatus: multiwatcher.StatusInfo{
 				Current: "waiting",
 				Message: "waiting for machine",
@@ -279,6 +280,7 @@ func (s *allWatcherBaseSuite) setUpScenario(c *gc.C, st *State, units int, inclu
 			Series:      "quantal",
 			Ports:       []multiwatcher.Port{},
 			Subordinate: true,
+			Life:        multiwatcher.Life("alive"),
 			WorkloadStatus: multiwatcher.StatusInfo{
 				Current: "waiting",
 				Message: "waiting for machine",
@@ -742,6 +744,7 @@ func (s *allWatcherStateSuite) TestClosingPorts(c *gc.C) {
 			ModelUUID:      s.state.ModelUUID(),
 			Name:           "wordpress/0",
 			Application:    "wordpress",
+			Life:           multiwatcher.Life("alive"),
 			Series:         "quantal",
 			MachineId:      "0",
 			PublicAddress:  "1.2.3.4",
@@ -779,6 +782,7 @@ func (s *allWatcherStateSuite) TestClosingPorts(c *gc.C) {
 			ModelUUID:      s.state.ModelUUID(),
 			Name:           "wordpress/0",
 			Application:    "wordpress",
+			Life:           multiwatcher.Life("alive"),
 			Series:         "quantal",
 			MachineId:      "0",
 			PublicAddress:  "1.2.3.4",
@@ -1062,6 +1066,7 @@ func (s *allWatcherStateSuite) TestStateWatcher(c *gc.C) {
 			ModelUUID:   s.state.ModelUUID(),
 			Name:        "wordpress/0",
 			Application: "wordpress",
+			Life:        multiwatcher.Life("alive"),
 			Series:      "quantal",
 			MachineId:   "2",
 			WorkloadStatus: multiwatcher.StatusInfo{
@@ -1951,6 +1956,7 @@ func (s *allModelWatcherStateSuite) TestStateWatcher(c *gc.C) {
 			ModelUUID:   st1.ModelUUID(),
 			Name:        "wordpress/0",
 			Application: "wordpress",
+			Life:        multiwatcher.Life("alive"),
 			Series:      "quantal",
 			MachineId:   "1",
 			WorkloadStatus: multiwatcher.StatusInfo{
@@ -2823,6 +2829,7 @@ func testChangeUnits(c *gc.C, owner names.UserTag, runChangeTests func(*gc.C, []
 						ModelUUID:   st.ModelUUID(),
 						Name:        "wordpress/0",
 						Application: "wordpress",
+						Life:        multiwatcher.Life("alive"),
 						Series:      "quantal",
 						MachineId:   "0",
 						Ports: []multiwatcher.Port{
@@ -2895,6 +2902,7 @@ func testChangeUnits(c *gc.C, owner names.UserTag, runChangeTests func(*gc.C, []
 						ModelUUID:   st.ModelUUID(),
 						Name:        "wordpress/0",
 						Application: "wordpress",
+						Life:        multiwatcher.Life("alive"),
 						Series:      "quantal",
 						MachineId:   "0",
 						Ports:       []multiwatcher.Port{{"udp", 17070}},
@@ -2982,6 +2990,7 @@ func testChangeUnits(c *gc.C, owner names.UserTag, runChangeTests func(*gc.C, []
 						ModelUUID:   st.ModelUUID(),
 						Name:        "wordpress/0",
 						Application: "wordpress",
+						Life:        multiwatcher.Life("alive"),
 						Series:      "quantal",
 						MachineId:   "0",
 						WorkloadStatus: multiwatcher.StatusInfo{
@@ -3038,6 +3047,7 @@ func testChangeUnits(c *gc.C, owner names.UserTag, runChangeTests func(*gc.C, []
 						ModelUUID:      st.ModelUUID(),
 						Name:           "wordpress/0",
 						Application:    "wordpress",
+						Life:           multiwatcher.Life("alive"),
 						Series:         "quantal",
 						PublicAddress:  "public",
 						PrivateAddress: "private",
@@ -3096,6 +3106,7 @@ func testChangeUnits(c *gc.C, owner names.UserTag, runChangeTests func(*gc.C, []
 						ModelUUID:   st.ModelUUID(),
 						Name:        "wordpress/0",
 						Application: "wordpress",
+						Life:        multiwatcher.Life("alive"),
 						AgentStatus: multiwatcher.StatusInfo{
 							Current: "idle",
 							Message: "",
@@ -3153,6 +3164,7 @@ func testChangeUnits(c *gc.C, owner names.UserTag, runChangeTests func(*gc.C, []
 						ModelUUID:   st.ModelUUID(),
 						Name:        "wordpress/0",
 						Application: "wordpress",
+						Life:        multiwatcher.Life("alive"),
 						WorkloadStatus: multiwatcher.StatusInfo{
 							Current: "maintenance",
 							Message: "working",
@@ -3217,6 +3229,7 @@ func testChangeUnits(c *gc.C, owner names.UserTag, runChangeTests func(*gc.C, []
 						ModelUUID:   st.ModelUUID(),
 						Name:        "wordpress/0",
 						Application: "wordpress",
+						Life:        multiwatcher.Life("alive"),
 						WorkloadStatus: multiwatcher.StatusInfo{
 							Current: "maintenance",
 							Message: "doing work",
@@ -3275,6 +3288,7 @@ func testChangeUnits(c *gc.C, owner names.UserTag, runChangeTests func(*gc.C, []
 						ModelUUID:   st.ModelUUID(),
 						Name:        "wordpress/0",
 						Application: "wordpress",
+						Life:        multiwatcher.Life("alive"),
 						WorkloadStatus: multiwatcher.StatusInfo{
 							Current: "error",
 							Message: "hook error",
@@ -3348,6 +3362,7 @@ func testChangeUnits(c *gc.C, owner names.UserTag, runChangeTests func(*gc.C, []
 						ModelUUID:   st.ModelUUID(),
 						Name:        "wordpress/0",
 						Application: "wordpress",
+						Life:        multiwatcher.Life("alive"),
 						WorkloadStatus: multiwatcher.StatusInfo{
 							Current: "active",
 							Message: "",
@@ -3434,6 +3449,7 @@ func testChangeUnitsNonNilPorts(c *gc.C, owner names.UserTag, runChangeTests fun
 						ModelUUID:   st.ModelUUID(),
 						Name:        "wordpress/0",
 						Application: "wordpress",
+						Life:        multiwatcher.Life("alive"),
 						Series:      "quantal",
 						MachineId:   "0",
 						Ports:       []multiwatcher.Port{},
@@ -3467,6 +3483,7 @@ func testChangeUnitsNonNilPorts(c *gc.C, owner names.UserTag, runChangeTests fun
 						ModelUUID:      st.ModelUUID(),
 						Name:           "wordpress/0",
 						Application:    "wordpress",
+						Life:           multiwatcher.Life("alive"),
 						Series:         "quantal",
 						MachineId:      "0",
 						PublicAddress:  "1.2.3.4",
@@ -3502,6 +3519,7 @@ func testChangeUnitsNonNilPorts(c *gc.C, owner names.UserTag, runChangeTests fun
 						ModelUUID:      st.ModelUUID(),
 						Name:           "wordpress/0",
 						Application:    "wordpress",
+						Life:           multiwatcher.Life("alive"),
 						Series:         "quantal",
 						MachineId:      "0",
 						PublicAddress:  "1.2.3.4",
@@ -3537,6 +3555,7 @@ func testChangeUnitsNonNilPorts(c *gc.C, owner names.UserTag, runChangeTests fun
 						ModelUUID:   st.ModelUUID(),
 						Name:        "wordpress/0",
 						Application: "wordpress",
+						Life:        multiwatcher.Life("alive"),
 						Series:      "quantal",
 						Ports:       []multiwatcher.Port{},
 						PortRanges:  []multiwatcher.PortRange{},
@@ -714,6 +714,11 @@ func (a *Application) changeCharmOps(
 		// Filter the old settings through to get the new settings.
 		newSettings = ch.Config().FilterSettings(oldKey.Map())
 		for k, v := range updatedSettings {
+			if v == nil {
+				// A nil value resets the setting to its default.
+				delete(newSettings, k)
+				continue
+			}
 			newSettings[k] = v
 		}
 	} else if errors.IsNotFound(err) {
@@ -1158,6 +1163,17 @@ func (a *Application) SetCharm(cfg SetCharmConfig) (err error) {
 		}
 	}
 
+	// If the model's next generation is active, the upgrade is made
+	// in it rather than to the application itself.
+	gen, err := a.st.NextGeneration()
+	if err != nil && !errors.IsNotFound(err) {
+		return errors.Trace(err)
+	}
+	if err == nil && gen.Active() {
+		cfg.ConfigSettings = updatedSettings
+		return errors.Trace(gen.SetCharm(a.doc.Name, cfg))
+	}
+
 	var newCharmModifiedVersion int
 	channel := string(cfg.Channel)
 	acopy := &Application{a.st, a.doc}
@@ -2057,6 +2073,15 @@ func (a *Application) UpdateCharmConfig(changes charm.Settings) error {
 	if err != nil {
 		return err
 	}
+	// If the model's next generation is active, the changes are
+	// made in it rather than to the current config.
+	gen, err := a.st.NextGeneration()
+	if err != nil && !errors.IsNotFound(err) {
+		return errors.Trace(err)
+	}
+	if err == nil && gen.Active() {
+		return errors.Trace(gen.UpdateCharmConfig(a.doc.Name, changes))
+	}
 	// TODO(fwereade) state.Settings is itself really problematic in just
 	// about every use case. This needs to be resolved some time; but at
 	// least the settings docs are keyed by charm url as well as application
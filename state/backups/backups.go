@@ -87,8 +87,15 @@ func StoreArchive(stor filestorage.FileStorage, meta *Metadata, file io.Reader)
 // Backups is an abstraction around all juju backup-related functionality.
 type Backups interface {
 	// Create creates a new juju backup archive. It updates
-	// the provided metadata.
-	Create(meta *Metadata, paths *Paths, dbInfo *DBInfo, keepCopy, noDownload bool) (string, error)
+	// the provided metadata. If key isn't nil, the archive is
+	// encrypted with it.
+	Create(meta *Metadata, paths *Paths, dbInfo *DBInfo, keepCopy, noDownload bool, key *ArchiveKey) (string, error)
+
+	// CreateIncremental creates and stores a new incremental backup
+	// of juju's state, archiving the oplog entries since the full
+	// backup identified by meta.Base, or since its latest incremental
+	// backup. It updates the provided metadata.
+	CreateIncremental(meta *Metadata, paths *Paths, oplog OplogSource) error
 
 	// Add stores the backup archive and returns its new ID.
 	Add(archive io.Reader, meta *Metadata) (string, error)
@@ -122,10 +129,22 @@ func NewBackups(stor filestorage.FileStorage) Backups {
 
 // Create creates and stores a new juju backup archive (based on arguments)
 // and updates the provided metadata.  A filename to download the backup is provided.
-func (b *backups) Create(meta *Metadata, paths *Paths, dbInfo *DBInfo, keepCopy, noDownload bool) (string, error) {
+func (b *backups) Create(meta *Metadata, paths *Paths, dbInfo *DBInfo, keepCopy, noDownload bool, key *ArchiveKey) (string, error) {
 	// TODO(fwereade): 2016-03-17 lp:1558657
 	meta.Started = time.Now().UTC()
 
+	if key != nil {
+		if err := key.Validate(); err != nil {
+			return "", errors.Annotate(err, "invalid encryption key")
+		}
+		fingerprint, err := key.Fingerprint()
+		if err != nil {
+			return "", errors.Annotate(err, "invalid encryption key")
+		}
+		meta.Encrypted = true
+		meta.KeyFingerprint = fingerprint
+	}
+
 	// The metadata file will not contain the ID or the "finished" data.
 	// However, that information is not as critical. The alternatives
 	// are either adding the metadata file to the archive after the fact
@@ -147,7 +166,7 @@ func (b *backups) Create(meta *Metadata, paths *Paths, dbInfo *DBInfo, keepCopy,
 		return "", errors.Annotate(err, "while preparing for DB dump")
 	}
 
-	args := createArgs{paths.BackupDir, filesToBackUp, dumper, metadataFile, noDownload}
+	args := createArgs{paths.BackupDir, filesToBackUp, dumper, metadataFile, noDownload, key}
 	result, err := runCreate(&args)
 	if err != nil {
 		return "", errors.Annotate(err, "while creating backup archive")
@@ -171,6 +190,77 @@ func (b *backups) Create(meta *Metadata, paths *Paths, dbInfo *DBInfo, keepCopy,
 	return result.filename, nil
 }
 
+// CreateIncremental creates and stores a new incremental backup. Only
+// the oplog entries are dumped, so it's much quicker to create than a
+// full backup, and much smaller.
+func (b *backups) CreateIncremental(meta *Metadata, paths *Paths, oplog OplogSource) error {
+	if meta.Base == "" {
+		return errors.New("missing base backup ID")
+	}
+	all, err := b.List()
+	if err != nil {
+		return errors.Annotate(err, "while listing backups")
+	}
+	var base *Metadata
+	for _, m := range all {
+		if m.ID() == meta.Base {
+			base = m
+		}
+	}
+	if base == nil {
+		return errors.NotFoundf("backup %q", meta.Base)
+	}
+	if base.Base != "" {
+		return errors.Errorf("backup %q is incremental, not a full backup", base.ID())
+	}
+
+	from := nextOplogStart(base, incrementalsOf(base.ID(), all))
+	oldest, newest, err := oplog.Range()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !oldest.Before(from) {
+		return errors.Errorf(
+			"the oplog no longer goes back to %s; create a new full backup",
+			from.Format(time.RFC3339))
+	}
+	if newest.Before(from) {
+		newest = from
+	}
+
+	// TODO(fwereade): 2016-03-17 lp:1558657
+	meta.Started = time.Now().UTC()
+	meta.OplogStart = from
+	meta.OplogEnd = newest
+	metadataFile, err := meta.AsJSONBuffer()
+	if err != nil {
+		return errors.Annotate(err, "while preparing the metadata")
+	}
+
+	// The files are still backed up, so that an incremental backup
+	// has the same layout as a full backup.
+	filesToBackUp, err := getFilesToBackUp("", paths, meta.Origin.Machine)
+	if err != nil {
+		return errors.Annotate(err, "while listing files to back up")
+	}
+	dumper := &oplogDumper{oplog: oplog, from: from, to: newest}
+
+	args := createArgs{paths.BackupDir, filesToBackUp, dumper, metadataFile, true, nil}
+	result, err := runCreate(&args)
+	if err != nil {
+		return errors.Annotate(err, "while creating backup archive")
+	}
+	defer result.archiveFile.Close()
+
+	if err := finishMeta(meta, result); err != nil {
+		return errors.Annotate(err, "while updating metadata")
+	}
+	if err := storeArchive(b.storage, meta, result.archiveFile); err != nil {
+		return errors.Annotate(err, "while storing backup archive")
+	}
+	return nil
+}
+
 // Add stores the backup archive and returns its new ID.
 func (b *backups) Add(archive io.Reader, meta *Metadata) (string, error) {
 	// Store the archive.
@@ -6,8 +6,11 @@
 package backups
 
 import (
+	"io"
+	"io/ioutil"
 	"net"
 	"strconv"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/utils/shell"
@@ -76,11 +79,30 @@ func (b *backups) Restore(backupId string, args RestoreArgs) (names.Tag, error)
 
 	defer backupReader.Close()
 
-	workspace, err := NewArchiveWorkspaceReader(backupReader)
+	// An encrypted archive is decrypted as it's unpacked, with the
+	// key given for this restore only.
+	var archive io.Reader = backupReader
+	if meta.Encrypted {
+		if args.Key == nil {
+			return nil, errors.Errorf("backup %q is encrypted; its key is needed to restore it", backupId)
+		}
+		if archive, err = NewDecryptingReader(backupReader, *args.Key); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+
+	workspace, err := NewArchiveWorkspaceReader(archive)
 	if err != nil {
 		return nil, errors.Annotate(err, "cannot unpack backup file")
 	}
 	defer workspace.Close()
+	if meta.Encrypted {
+		// The decrypted archive's integrity is only checked once
+		// it's been read to the end.
+		if _, err := io.Copy(ioutil.Discard, archive); err != nil {
+			return nil, errors.Annotate(err, "cannot decrypt backup file")
+		}
+	}
 
 	// This might actually work, but we don't have a guarantee so we don't allow it.
 	if meta.Origin.Series != args.NewInstSeries {
@@ -94,6 +116,14 @@ func (b *backups) Restore(backupId string, args RestoreArgs) (names.Tag, error)
 	}
 	backupMachine := names.NewMachineTag(meta.Origin.Machine)
 
+	// The incremental backups are read from the backups database,
+	// so this must be done before mongo is stopped.
+	if !args.ToTime.IsZero() {
+		if err := b.prepareOplogReplay(meta, workspace.DBDumpDir, args.ToTime); err != nil {
+			return nil, errors.Annotatef(err, "cannot restore to %s", args.ToTime.Format(time.RFC3339))
+		}
+	}
+
 	// The path for the config file might change if the tag changed
 	// and also the rest of the path, so we assume as little as possible.
 	oldDatadir, err := paths.DataDir(args.NewInstSeries)
@@ -207,6 +237,7 @@ func (b *backups) Restore(backupId string, args RestoreArgs) (names.Tag, error)
 		StopMongo:       mongo.StopService,
 		NewMongoSession: NewMongoSession,
 		GetDB:           GetDB,
+		OplogLimit:      args.ToTime,
 	}
 
 	// Restore mongodb from backup
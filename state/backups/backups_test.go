@@ -62,7 +62,7 @@ func (s *backupsSuite) checkFailure(c *gc.C, expected string) {
 	meta := backupstesting.NewMetadataStarted()
 	meta.Notes = "some notes"
 
-	_, err := s.api.Create(meta, &paths, &dbInfo, true, true)
+	_, err := s.api.Create(meta, &paths, &dbInfo, true, true, nil)
 	c.Check(err, gc.ErrorMatches, expected)
 }
 
@@ -84,6 +84,37 @@ func (s *backupsSuite) TestCreateOkayNoDownloadFalse(c *gc.C) {
 	s.testCreateOkay(c, true, false)
 }
 
+func (s *backupsSuite) TestCreateEncrypted(c *gc.C) {
+	received, testCreate := backups.NewTestCreate(nil)
+	s.PatchValue(backups.RunCreate, testCreate)
+	s.PatchValue(backups.TestGetFilesToBackUp, func(string, *backups.Paths, string) ([]string, error) {
+		return []string{"<some file>"}, nil
+	})
+	s.PatchValue(backups.GetDBDumper, func(*backups.DBInfo) (backups.DBDumper, error) {
+		return &fakeDumper{}, nil
+	})
+	s.PatchValue(backups.StoreArchiveRef, backups.NewTestArchiveStorer(""))
+
+	meta := backupstesting.NewMetadataStarted()
+	key := &backups.ArchiveKey{PublicKey: backupstesting.PublicKey}
+	_, err := s.api.Create(meta, &backups.Paths{}, &backups.DBInfo{}, true, true, key)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(meta.Encrypted, jc.IsTrue)
+	c.Check(meta.KeyFingerprint, gc.Equals, backupstesting.KeyFingerprint)
+	c.Check(backups.ExposeCreateKey(received), gc.Equals, key)
+}
+
+func (s *backupsSuite) TestCreateEncryptedInvalidKey(c *gc.C) {
+	meta := backupstesting.NewMetadataStarted()
+	key := &backups.ArchiveKey{Passphrase: "secret", PublicKey: backupstesting.PublicKey}
+	_, err := s.api.Create(meta, &backups.Paths{}, &backups.DBInfo{}, true, true, key)
+	c.Check(err, gc.ErrorMatches, "invalid encryption key: cannot use both a passphrase and a public key")
+
+	key = &backups.ArchiveKey{PublicKey: "not a key"}
+	_, err = s.api.Create(meta, &backups.Paths{}, &backups.DBInfo{}, true, true, key)
+	c.Check(err, gc.ErrorMatches, "invalid encryption key: cannot read OpenPGP key: .*")
+}
+
 func (s *backupsSuite) testCreateOkay(c *gc.C, keepCopy, noDownload bool) {
 	dataDir := c.MkDir()
 	backupDir := c.MkDir()
@@ -118,7 +149,7 @@ func (s *backupsSuite) testCreateOkay(c *gc.C, keepCopy, noDownload bool) {
 	meta := backupstesting.NewMetadataStarted()
 	backupstesting.SetOrigin(meta, "<model ID>", "<machine ID>", "<hostname>")
 	meta.Notes = "some notes"
-	resultFilename, err := s.api.Create(meta, &paths, &dbInfo, keepCopy, noDownload)
+	resultFilename, err := s.api.Create(meta, &paths, &dbInfo, keepCopy, noDownload, nil)
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(resultFilename, gc.Equals, path.Join(backupDir, backups.TempFilename))
 
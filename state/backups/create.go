@@ -33,6 +33,8 @@ type createArgs struct {
 	db             DBDumper
 	metadataReader io.Reader
 	noDownload     bool
+	// key, if set, is used to encrypt the archive.
+	key *ArchiveKey
 }
 
 type createResult struct {
@@ -50,6 +52,7 @@ func create(args *createArgs) (_ *createResult, err error) {
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	builder.key = args.key
 	defer func() {
 		if cerr := builder.cleanUp(args.noDownload); cerr != nil {
 			cerr.Log(logger)
@@ -105,6 +108,8 @@ type builder struct {
 	// bundleFile is the inner archive file containing all the juju
 	// state-related files gathered during backup.
 	bundleFile io.WriteCloser
+	// key, if set, is used to encrypt the archive file.
+	key *ArchiveKey
 }
 
 // newBuilder returns a new backup archive builder.  It creates the temp
@@ -317,8 +322,24 @@ func (b *builder) buildArchiveAndChecksum() error {
 	// that users can compare the published checksum against the
 	// checksum of the file without having to decompress it first.
 	hasher := hash.NewHashingWriter(b.archiveFile, sha1.New())
-	if err := b.buildArchive(hasher); err != nil {
-		return errors.Trace(err)
+	if b.key == nil {
+		if err := b.buildArchive(hasher); err != nil {
+			return errors.Trace(err)
+		}
+	} else {
+		// The checksum is of the encrypted archive, which is what's
+		// stored and downloaded.
+		encrypter, err := NewEncryptingWriter(hasher, *b.key)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err := b.buildArchive(encrypter); err != nil {
+			encrypter.Close()
+			return errors.Trace(err)
+		}
+		if err := encrypter.Close(); err != nil {
+			return errors.Annotate(err, "while encrypting archive")
+		}
 	}
 
 	// Save the SHA1 checksum.
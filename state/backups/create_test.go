@@ -4,8 +4,10 @@
 package backups_test
 
 import (
+	"io"
 	"os"
 	"path"
+	"path/filepath"
 	"runtime"
 
 	jc "github.com/juju/testing/checkers"
@@ -58,6 +60,44 @@ func (s *createSuite) TestLegacy(c *gc.C) {
 	s.checkArchive(c, file, expected)
 }
 
+func (s *createSuite) TestEncrypted(c *gc.C) {
+	if runtime.GOOS == "windows" {
+		c.Skip("bug 1403084: Currently does not work on windows, see comments inside backups.create function")
+	}
+	meta := backupstesting.NewMetadataStarted()
+	metadataFile, err := meta.AsJSONBuffer()
+	c.Assert(err, jc.ErrorIsNil)
+	backupDir := c.MkDir()
+	_, testFiles, expected := s.createTestFiles(c)
+
+	args := backups.NewTestCreateArgs(backupDir, testFiles, &TestDBDumper{}, metadataFile, true)
+	backups.SetCreateKey(args, &backups.ArchiveKey{PublicKey: backupstesting.PublicKey})
+	result, err := backups.Create(args)
+	c.Assert(err, jc.ErrorIsNil)
+
+	archiveFile, size, checksum, _ := backups.ExposeCreateResult(result)
+	file, ok := archiveFile.(*os.File)
+	c.Assert(ok, jc.IsTrue)
+
+	// The size and checksum are those of the encrypted archive.
+	s.checkSize(c, file, size)
+	s.checkChecksum(c, file, checksum)
+
+	encrypted, archive, err := backups.IsEncryptedArchive(file)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(encrypted, jc.IsTrue)
+	decrypted, err := backups.NewDecryptingReader(archive, backups.ArchiveKey{PrivateKey: backupstesting.PrivateKey})
+	c.Assert(err, jc.ErrorIsNil)
+	plain, err := os.Create(filepath.Join(c.MkDir(), "decrypted.tar.gz"))
+	c.Assert(err, jc.ErrorIsNil)
+	defer plain.Close()
+	_, err = io.Copy(plain, decrypted)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = plain.Seek(0, io.SeekStart)
+	c.Assert(err, jc.ErrorIsNil)
+	s.checkArchive(c, plain, expected)
+}
+
 func (s *createSuite) TestMetadataFileMissing(c *gc.C) {
 	var backupDir string
 	var testFiles []string
@@ -9,6 +9,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"time"
 
 	"github.com/juju/collections/set"
 	"github.com/juju/errors"
@@ -262,7 +263,18 @@ type mongoRestorer struct {
 	tagUser         string
 	tagUserPassword string
 	runCommandFn    func(string, ...string) error
+	oplogLimit      time.Time
 }
+
+// oplogLimitOptions returns the options that stop mongorestore
+// replaying oplog entries from the oplog limit on, if there is one.
+func (md *mongoRestorer) oplogLimitOptions() []string {
+	if md.oplogLimit.IsZero() {
+		return nil
+	}
+	return []string{"--oplogLimit", fmt.Sprintf("%d:0", md.oplogLimit.Unix())}
+}
+
 type mongoRestorer32 struct {
 	mongoRestorer
 	getDB           func(string, MongoSession) MongoDB
@@ -282,9 +294,9 @@ func (md *mongoRestorer24) options(dumpDir string) []string {
 		"--journal",
 		"--oplogReplay",
 		"--dbpath", dbDir,
-		dumpDir,
 	}
-	return options
+	options = append(options, md.oplogLimitOptions()...)
+	return append(options, dumpDir)
 }
 
 func (md *mongoRestorer24) Restore(dumpDir string, _ *mgo.DialInfo) error {
@@ -325,6 +337,10 @@ type RestorerArgs struct {
 	RunCommandFn func(string, ...string) error
 	StartMongo   func() error
 	StopMongo    func() error
+
+	// OplogLimit, if set, stops the restore replaying oplog
+	// entries from that time on.
+	OplogLimit time.Time
 }
 
 var mongoInstalledVersion = func() mongo.Version {
@@ -360,6 +376,7 @@ func NewDBRestorer(args RestorerArgs) (DBRestorer, error) {
 		tagUser:         args.TagUser,
 		tagUserPassword: args.TagUserPassword,
 		runCommandFn:    args.RunCommandFn,
+		oplogLimit:      args.OplogLimit,
 	}
 	switch args.Version.Major {
 	case 2:
@@ -398,9 +415,9 @@ func (md *mongoRestorer32) options(dumpDir string) []string {
 		"--drop",
 		"--oplogReplay",
 		"--batchSize", "10",
-		dumpDir,
 	}
-	return options
+	options = append(options, md.oplogLimitOptions()...)
+	return append(options, dumpDir)
 }
 
 // MongoDB represents a mgo.DB.
@@ -5,6 +5,7 @@ package backups_test
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
@@ -51,6 +52,33 @@ func (s *mongoRestoreSuite) TestRestoreDatabase24(c *gc.C) {
 	c.Assert(ranWithArgs, gc.DeepEquals, []string{"--drop", "--journal", "--oplogReplay", "--dbpath", "/var/lib/juju/db", "fakePath"})
 }
 
+func (s *mongoRestoreSuite) TestRestoreDatabaseOplogLimit(c *gc.C) {
+	s.PatchValue(backups.GetMongorestorePath, func() (string, error) { return "/a/fake/mongorestore", nil })
+	var ranWithArgs []string
+	fakeRunCommand := func(c string, args ...string) error {
+		ranWithArgs = args
+		return nil
+	}
+	args := backups.RestorerArgs{
+		Version:      mongo.Mongo24,
+		RunCommandFn: fakeRunCommand,
+		StartMongo:   func() error { return nil },
+		StopMongo:    func() error { return nil },
+		OplogLimit:   time.Date(2018, 7, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	s.PatchValue(backups.MongoInstalledVersion, func() mongo.Version { return mongo.Mongo24 })
+	restorer, err := backups.NewDBRestorer(args)
+	c.Assert(err, jc.ErrorIsNil)
+	err = restorer.Restore("fakePath", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(ranWithArgs, gc.DeepEquals, []string{
+		"--drop", "--journal", "--oplogReplay", "--dbpath", "/var/lib/juju/db",
+		"--oplogLimit", "1530446400:0", "fakePath",
+	})
+}
+
 type mongoDb struct {
 	user *mgo.User
 }
@@ -0,0 +1,148 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/juju/errors"
+	"golang.org/x/crypto/openpgp"
+)
+
+// ArchiveKey holds what's needed to encrypt or decrypt a backup
+// archive. Archives are encrypted as OpenPGP messages, either with a
+// passphrase or to an armored public key; an archive encrypted to a
+// public key is decrypted with the matching armored private key, which
+// is itself unlocked with the passphrase if it's protected.
+type ArchiveKey struct {
+	// Passphrase is the passphrase the archive is encrypted with, or
+	// which unlocks PrivateKey.
+	Passphrase string
+
+	// PublicKey is the armored OpenPGP public key the archive is
+	// encrypted to.
+	PublicKey string
+
+	// PrivateKey is the armored OpenPGP private key used to decrypt
+	// an archive encrypted to its public key.
+	PrivateKey string
+}
+
+// Validate checks that the key can be used to encrypt an archive.
+func (k ArchiveKey) Validate() error {
+	if k.PublicKey != "" && k.Passphrase != "" {
+		return errors.New("cannot use both a passphrase and a public key")
+	}
+	if k.PublicKey == "" && k.Passphrase == "" {
+		return errors.New("missing passphrase or public key")
+	}
+	return nil
+}
+
+// Fingerprint returns the fingerprint of the OpenPGP key the archive
+// is encrypted to, as upper case hex, read from either the public key
+// or the private key. It's empty if the archive is encrypted with a
+// passphrase.
+func (k ArchiveKey) Fingerprint() (string, error) {
+	armored := k.PublicKey
+	if armored == "" {
+		armored = k.PrivateKey
+	}
+	if armored == "" {
+		return "", nil
+	}
+	keyring, err := readKeyRing(armored)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	return fmt.Sprintf("%X", keyring[0].PrimaryKey.Fingerprint), nil
+}
+
+// NewEncryptingWriter returns a writer that encrypts what's written to
+// it with the key, writing the encrypted archive to w. The writer must
+// be closed to finish the archive.
+func NewEncryptingWriter(w io.Writer, key ArchiveKey) (io.WriteCloser, error) {
+	if err := key.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	hints := &openpgp.FileHints{IsBinary: true}
+	if key.PublicKey == "" {
+		plaintext, err := openpgp.SymmetricallyEncrypt(w, []byte(key.Passphrase), hints, nil)
+		return plaintext, errors.Annotate(err, "while encrypting archive")
+	}
+	recipients, err := readKeyRing(key.PublicKey)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	plaintext, err := openpgp.Encrypt(w, recipients, nil, hints, nil)
+	return plaintext, errors.Annotate(err, "while encrypting archive")
+}
+
+// NewDecryptingReader returns a reader of the archive decrypted from r
+// with the key. The decrypted archive's integrity is only checked once
+// it has been read to the end, so an error reading it means that none
+// of what was read can be trusted.
+func NewDecryptingReader(r io.Reader, key ArchiveKey) (io.Reader, error) {
+	var keyring openpgp.EntityList
+	if key.PrivateKey != "" {
+		var err error
+		keyring, err = readKeyRing(key.PrivateKey)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	prompted := false
+	prompt := func(keys []openpgp.Key, symmetric bool) ([]byte, error) {
+		// The prompt is called again if what it supplied didn't
+		// work, so only try once.
+		if prompted {
+			return nil, errors.New("incorrect passphrase or key")
+		}
+		prompted = true
+		if symmetric {
+			return []byte(key.Passphrase), nil
+		}
+		for _, k := range keys {
+			if k.PrivateKey != nil && k.PrivateKey.Encrypted {
+				if err := k.PrivateKey.Decrypt([]byte(key.Passphrase)); err != nil {
+					return nil, errors.Annotate(err, "cannot unlock private key")
+				}
+			}
+		}
+		return nil, nil
+	}
+	md, err := openpgp.ReadMessage(r, keyring, prompt, nil)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot decrypt archive")
+	}
+	return md.UnverifiedBody, nil
+}
+
+// IsEncryptedArchive reports whether the archive read from r is
+// encrypted, rather than a gzipped tar file. It returns a reader of
+// the whole archive, to be used in place of r.
+func IsEncryptedArchive(r io.Reader) (bool, io.Reader, error) {
+	buffered := bufio.NewReader(r)
+	header, err := buffered.Peek(1)
+	if err != nil {
+		return false, nil, errors.Annotate(err, "cannot read archive")
+	}
+	// Every OpenPGP packet starts with a tag byte with its high bit
+	// set; gzip files start with 0x1f.
+	return header[0]&0x80 != 0, buffered, nil
+}
+
+func readKeyRing(armored string) (openpgp.EntityList, error) {
+	keyring, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armored))
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot read OpenPGP key")
+	}
+	if len(keyring) == 0 {
+		return nil, errors.New("no OpenPGP key found")
+	}
+	return keyring, nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state/backups"
+	bt "github.com/juju/juju/state/backups/testing"
+)
+
+type encryptSuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&encryptSuite{})
+
+func (s *encryptSuite) encrypt(c *gc.C, key backups.ArchiveKey, data string) []byte {
+	var buf bytes.Buffer
+	w, err := backups.NewEncryptingWriter(&buf, key)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = w.Write([]byte(data))
+	c.Assert(err, jc.ErrorIsNil)
+	err = w.Close()
+	c.Assert(err, jc.ErrorIsNil)
+	return buf.Bytes()
+}
+
+func (s *encryptSuite) decrypt(c *gc.C, key backups.ArchiveKey, data []byte) (string, error) {
+	encrypted, archive, err := backups.IsEncryptedArchive(bytes.NewReader(data))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(encrypted, jc.IsTrue)
+	r, err := backups.NewDecryptingReader(archive, key)
+	if err != nil {
+		return "", err
+	}
+	plain, err := ioutil.ReadAll(r)
+	return string(plain), err
+}
+
+func (s *encryptSuite) TestPassphrase(c *gc.C) {
+	data := s.encrypt(c, backups.ArchiveKey{Passphrase: "secret"}, "<archive>")
+	c.Check(bytes.Contains(data, []byte("<archive>")), jc.IsFalse)
+
+	plain, err := s.decrypt(c, backups.ArchiveKey{Passphrase: "secret"}, data)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(plain, gc.Equals, "<archive>")
+
+	_, err = s.decrypt(c, backups.ArchiveKey{Passphrase: "wrong"}, data)
+	c.Check(err, gc.ErrorMatches, "cannot decrypt archive: incorrect passphrase or key")
+}
+
+func (s *encryptSuite) TestPublicKey(c *gc.C) {
+	data := s.encrypt(c, backups.ArchiveKey{PublicKey: bt.PublicKey}, "<archive>")
+
+	plain, err := s.decrypt(c, backups.ArchiveKey{PrivateKey: bt.PrivateKey}, data)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(plain, gc.Equals, "<archive>")
+
+	_, err = s.decrypt(c, backups.ArchiveKey{Passphrase: "secret"}, data)
+	c.Check(err, gc.ErrorMatches, "cannot decrypt archive: .*")
+}
+
+func (s *encryptSuite) TestFingerprint(c *gc.C) {
+	fingerprint, err := backups.ArchiveKey{PublicKey: bt.PublicKey}.Fingerprint()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(fingerprint, gc.Equals, bt.KeyFingerprint)
+
+	fingerprint, err = backups.ArchiveKey{PrivateKey: bt.PrivateKey}.Fingerprint()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(fingerprint, gc.Equals, bt.KeyFingerprint)
+
+	fingerprint, err = backups.ArchiveKey{Passphrase: "secret"}.Fingerprint()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(fingerprint, gc.Equals, "")
+}
+
+func (s *encryptSuite) TestValidate(c *gc.C) {
+	err := backups.ArchiveKey{}.Validate()
+	c.Check(err, gc.ErrorMatches, "missing passphrase or public key")
+	err = backups.ArchiveKey{Passphrase: "secret", PublicKey: bt.PublicKey}.Validate()
+	c.Check(err, gc.ErrorMatches, "cannot use both a passphrase and a public key")
+	_, err = backups.NewEncryptingWriter(ioutil.Discard, backups.ArchiveKey{PublicKey: "<not a key>"})
+	c.Check(err, gc.ErrorMatches, "cannot read OpenPGP key: .*")
+}
+
+func (s *encryptSuite) TestIsEncryptedArchiveGzip(c *gc.C) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write([]byte("<archive>"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(w.Close(), jc.ErrorIsNil)
+	size := buf.Len()
+
+	encrypted, archive, err := backups.IsEncryptedArchive(&buf)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(encrypted, jc.IsFalse)
+	data, err := ioutil.ReadAll(archive)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(data, gc.HasLen, size)
+}
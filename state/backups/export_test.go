@@ -110,6 +110,16 @@ func ExposeCreateArgs(args *createArgs) (string, []string, DBDumper) {
 	return args.backupDir, args.filesToBackUp, args.db
 }
 
+// SetCreateKey sets the key create() encrypts the archive with.
+func SetCreateKey(args *createArgs, key *ArchiveKey) {
+	args.key = key
+}
+
+// ExposeCreateKey extracts the encryption key in a create() args value.
+func ExposeCreateKey(args *createArgs) *ArchiveKey {
+	return args.key
+}
+
 // NewTestCreateResult builds a new create() result.
 func NewTestCreateResult(file io.ReadCloser, size int64, checksum, filename string) *createResult {
 	result := createResult{
@@ -169,3 +179,9 @@ func NewTestArchiveStorer(failure string) func(filestorage.FileStorage, *Metadat
 
 // Export for patching in tests
 var RestorePath = &getMongorestorePath
+
+// Export the incremental backup helpers for testing.
+var (
+	RestoreChain  = restoreChain
+	OplogFilename = oplogFilename
+)
@@ -68,6 +68,34 @@ type Metadata struct {
 	// Notes is an optional user-supplied annotation.
 	Notes string
 
+	// Scheduled records whether the backup was created on the
+	// controller's backup schedule, rather than on demand. Only
+	// scheduled backups are removed by the schedule's retention
+	// policy.
+	Scheduled bool
+
+	// Base is the ID of the full backup that an incremental backup
+	// builds on. It's empty for full backups.
+	Base string
+
+	// OplogStart and OplogEnd bound the database operations archived
+	// in an incremental backup. Restoring the base backup and then
+	// replaying its incremental backups' operations, in order,
+	// restores the database as it was at any time up to the last
+	// OplogEnd.
+	OplogStart time.Time
+	OplogEnd   time.Time
+
+	// Encrypted records whether the archive is encrypted. Encrypted
+	// archives must be decrypted, outside the controller, before
+	// they can be restored.
+	Encrypted bool
+
+	// KeyFingerprint is the fingerprint of the OpenPGP public key an
+	// encrypted archive was encrypted to. It's empty if the archive
+	// was encrypted with a passphrase.
+	KeyFingerprint string
+
 	// TODO(wallyworld) - remove these ASAP
 	// These are only used by the restore CLI when re-bootstrapping.
 	// We will use a better solution but the way restore currently
@@ -168,6 +196,10 @@ type flatMetadata struct {
 	Version     version.Number
 	Series      string
 
+	Base       string
+	OplogStart time.Time
+	OplogEnd   time.Time
+
 	CACert       string
 	CAPrivateKey string
 }
@@ -190,6 +222,9 @@ func (m *Metadata) AsJSONBuffer() (io.Reader, error) {
 		Hostname:     m.Origin.Hostname,
 		Version:      m.Origin.Version,
 		Series:       m.Origin.Series,
+		Base:         m.Base,
+		OplogStart:   m.OplogStart,
+		OplogEnd:     m.OplogEnd,
 		CACert:       m.CACert,
 		CAPrivateKey: m.CAPrivateKey,
 	}
@@ -241,6 +276,9 @@ func NewMetadataJSONReader(in io.Reader) (*Metadata, error) {
 		Version:  flat.Version,
 		Series:   flat.Series,
 	}
+	meta.Base = flat.Base
+	meta.OplogStart = flat.OplogStart
+	meta.OplogEnd = flat.OplogEnd
 
 	// TODO(wallyworld) - put these in a separate file.
 	meta.CACert = flat.CACert
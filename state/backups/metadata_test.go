@@ -61,6 +61,9 @@ func (s *metadataSuite) TestAsJSONBuffer(c *gc.C) {
 		`"Hostname":"myhost",`+
 		`"Version":"1.21-alpha3",`+
 		`"Series":"trusty",`+
+		`"Base":"",`+
+		`"OplogStart":"0001-01-01T00:00:00Z",`+
+		`"OplogEnd":"0001-01-01T00:00:00Z",`+
 		`"CACert":"ca-cert",`+
 		`"CAPrivateKey":"ca-private-key"`+
 		`}`+"\n")
@@ -79,7 +82,10 @@ func (s *metadataSuite) TestNewMetadataJSONReader(c *gc.C) {
 		`"Environment":"asdf-zxcv-qwe",` +
 		`"Machine":"0",` +
 		`"Hostname":"myhost",` +
-		`"Version":"1.21-alpha3"` +
+		`"Version":"1.21-alpha3",` +
+		`"Base":"20140909-110000.asdf-zxcv-qwe",` +
+		`"OplogStart":"2014-09-09T11:00:00Z",` +
+		`"OplogEnd":"2014-09-09T11:59:34Z"` +
 		`}` + "\n")
 	meta, err := backups.NewMetadataJSONReader(file)
 	c.Assert(err, jc.ErrorIsNil)
@@ -96,6 +102,9 @@ func (s *metadataSuite) TestNewMetadataJSONReader(c *gc.C) {
 	c.Check(meta.Origin.Machine, gc.Equals, "0")
 	c.Check(meta.Origin.Hostname, gc.Equals, "myhost")
 	c.Check(meta.Origin.Version.String(), gc.Equals, "1.21-alpha3")
+	c.Check(meta.Base, gc.Equals, "20140909-110000.asdf-zxcv-qwe")
+	c.Check(meta.OplogStart.Unix(), gc.Equals, int64(1410260400))
+	c.Check(meta.OplogEnd.Unix(), gc.Equals, int64(1410263974))
 }
 
 func (s *metadataSuite) TestBuildMetadata(c *gc.C) {
@@ -0,0 +1,220 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/juju/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/juju/juju/mongo"
+)
+
+// oplogFilename is the name of the file in a DB dump directory that
+// holds the oplog entries mongorestore replays after restoring the
+// dumped databases. mongodump --oplog writes it for full backups, and
+// incremental backups hold nothing else.
+const oplogFilename = "oplog.bson"
+
+// OplogSource provides the replication oplog entries archived by
+// incremental backups.
+type OplogSource interface {
+	// Range returns the times of the oldest and newest entries in
+	// the oplog.
+	Range() (oldest, newest time.Time, err error)
+
+	// Dump writes the entries from the start of the from second to
+	// the end of the to second to w, as a stream of BSON documents
+	// in the format mongorestore replays.
+	Dump(w io.Writer, from, to time.Time) error
+}
+
+// NewOplogSource returns an OplogSource that reads the oplog of the
+// replica set member the session is connected to.
+func NewOplogSource(session *mgo.Session) OplogSource {
+	return &mongoOplog{mongo.GetOplog(session)}
+}
+
+type mongoOplog struct {
+	collection *mgo.Collection
+}
+
+// Range implements OplogSource.
+func (o *mongoOplog) Range() (oldest, newest time.Time, err error) {
+	var doc struct {
+		Timestamp bson.MongoTimestamp `bson:"ts"`
+	}
+	if err := o.collection.Find(nil).Sort("$natural").One(&doc); err != nil {
+		return time.Time{}, time.Time{}, errors.Annotate(err, "reading oldest oplog entry")
+	}
+	oldest = mongo.MongoTimestampTime(doc.Timestamp)
+	if err := o.collection.Find(nil).Sort("-$natural").One(&doc); err != nil {
+		return time.Time{}, time.Time{}, errors.Annotate(err, "reading newest oplog entry")
+	}
+	newest = mongo.MongoTimestampTime(doc.Timestamp)
+	return oldest, newest, nil
+}
+
+// Dump implements OplogSource.
+func (o *mongoOplog) Dump(w io.Writer, from, to time.Time) error {
+	query := bson.D{
+		{"ts", bson.D{
+			{"$gte", mongo.NewMongoTimestamp(from)},
+			{"$lt", mongo.NewMongoTimestamp(to.Add(time.Second))},
+		}},
+		// The backups database isn't restored, so neither are
+		// the changes made to it.
+		{"ns", bson.D{{"$not", bson.RegEx{Pattern: "^" + storageDBName + `\.`}}}},
+	}
+	iter := o.collection.Find(query).LogReplay().Iter()
+	var doc bson.Raw
+	for iter.Next(&doc) {
+		if _, err := w.Write(doc.Data); err != nil {
+			iter.Close()
+			return errors.Trace(err)
+		}
+	}
+	return errors.Trace(iter.Close())
+}
+
+// oplogDumper is a DBDumper that dumps the oplog entries for an
+// incremental backup, rather than the databases.
+type oplogDumper struct {
+	oplog OplogSource
+	from  time.Time
+	to    time.Time
+}
+
+// Dump implements DBDumper.
+func (d *oplogDumper) Dump(dumpDir string) error {
+	f, err := os.Create(filepath.Join(dumpDir, oplogFilename))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err := d.oplog.Dump(f, d.from, d.to); err != nil {
+		f.Close()
+		return errors.Annotate(err, "dumping oplog")
+	}
+	return errors.Trace(f.Close())
+}
+
+// incrementalsOf returns the incremental backups that build on the
+// full backup with the ID, oldest first.
+func incrementalsOf(id string, all []*Metadata) []*Metadata {
+	var result []*Metadata
+	for _, meta := range all {
+		if meta.Base == id {
+			result = append(result, meta)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].OplogStart.Before(result[j].OplogStart)
+	})
+	return result
+}
+
+// nextOplogStart returns the time from which the next incremental
+// backup of the full backup archives the oplog. The oplog entries
+// from before the end of the full backup's dump are replayed again
+// on restore, which is safe because oplog entries are idempotent.
+func nextOplogStart(base *Metadata, incrementals []*Metadata) time.Time {
+	if n := len(incrementals); n > 0 {
+		return incrementals[n-1].OplogEnd
+	}
+	return base.Started
+}
+
+// restoreChain returns the incremental backups whose oplog entries
+// must be replayed, in order, after restoring the full backup, to
+// restore the database as it was at the given time.
+func restoreChain(base *Metadata, all []*Metadata, to time.Time) ([]*Metadata, error) {
+	if base.Base != "" {
+		return nil, errors.Errorf(
+			"backup %q is incremental; restore its full backup %q to a time instead",
+			base.ID(), base.Base)
+	}
+	if base.Finished != nil && to.Before(*base.Finished) {
+		return nil, errors.Errorf(
+			"cannot restore backup %q to %s, before it finished at %s",
+			base.ID(), to.Format(time.RFC3339), base.Finished.Format(time.RFC3339))
+	}
+	var chain []*Metadata
+	end := base.Started
+	for _, meta := range incrementalsOf(base.ID(), all) {
+		if meta.OplogStart.After(end) {
+			return nil, errors.Errorf(
+				"incremental backups of %q are missing the changes from %s to %s",
+				base.ID(), end.Format(time.RFC3339), meta.OplogStart.Format(time.RFC3339))
+		}
+		chain = append(chain, meta)
+		end = meta.OplogEnd
+		if !end.Before(to) {
+			return chain, nil
+		}
+	}
+	return nil, errors.Errorf(
+		"incremental backups of %q only extend to %s, not %s",
+		base.ID(), end.Format(time.RFC3339), to.Format(time.RFC3339))
+}
+
+// prepareOplogReplay appends to dumpDir, the unpacked dump of the full
+// backup, the oplog entries needed to restore the database as it was
+// at the given time.
+func (b *backups) prepareOplogReplay(meta *Metadata, dumpDir string, to time.Time) error {
+	all, err := b.List()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	chain, err := restoreChain(meta, all, to)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(b.appendOplogs(dumpDir, chain))
+}
+
+// appendOplogs appends the oplog entries archived in the incremental
+// backups to the oplog.bson file in dumpDir, for mongorestore to
+// replay after the full backup it builds on.
+func (b *backups) appendOplogs(dumpDir string, chain []*Metadata) error {
+	path := filepath.Join(dumpDir, oplogFilename)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, meta := range chain {
+		if err := b.appendOplog(f, meta.ID()); err != nil {
+			f.Close()
+			return errors.Annotatef(err, "reading incremental backup %q", meta.ID())
+		}
+	}
+	return errors.Trace(f.Close())
+}
+
+func (b *backups) appendOplog(w io.Writer, id string) error {
+	_, archive, err := b.Get(id)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer archive.Close()
+
+	workspace, err := NewArchiveWorkspaceReader(archive)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer workspace.Close()
+
+	oplog, err := os.Open(filepath.Join(workspace.DBDumpDir, oplogFilename))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer oplog.Close()
+	_, err = io.Copy(w, oplog)
+	return errors.Trace(err)
+}
@@ -0,0 +1,199 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups_test
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils/filestorage"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state/backups"
+	backupstesting "github.com/juju/juju/state/backups/testing"
+)
+
+type incrementalSuite struct {
+	backupstesting.BaseSuite
+
+	api   backups.Backups
+	start time.Time
+}
+
+var _ = gc.Suite(&incrementalSuite{})
+
+func (s *incrementalSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.api = backups.NewBackups(s.Storage)
+	s.start = time.Date(2018, 7, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func (s *incrementalSuite) at(hours int) time.Time {
+	return s.start.Add(time.Duration(hours) * time.Hour)
+}
+
+// newMeta returns metadata for a stored backup. The backup is
+// incremental if base is set, in which case it archives the oplog
+// between the from and to hours.
+func (s *incrementalSuite) newMeta(id, base string, from, to int) *backups.Metadata {
+	meta := backupstesting.NewMetadataStarted()
+	meta.SetID(id)
+	meta.Started = s.at(from)
+	finished := s.at(from).Add(time.Minute)
+	meta.Finished = &finished
+	if base != "" {
+		meta.Base = base
+		meta.OplogStart = s.at(from)
+		meta.OplogEnd = s.at(to)
+	}
+	return meta
+}
+
+func (s *incrementalSuite) setStored(metas ...*backups.Metadata) {
+	s.Storage.MetaList = nil
+	for _, meta := range metas {
+		s.Storage.MetaList = append(s.Storage.MetaList, filestorage.Metadata(meta))
+	}
+}
+
+func (s *incrementalSuite) TestCreateIncremental(c *gc.C) {
+	s.setStored(
+		s.newMeta("base", "", 0, 0),
+		s.newMeta("inc-2", "base", 1, 2),
+		s.newMeta("inc-1", "base", 0, 1),
+	)
+	received, testCreate := backups.NewTestCreate(nil)
+	s.PatchValue(backups.RunCreate, testCreate)
+	s.PatchValue(backups.TestGetFilesToBackUp, func(string, *backups.Paths, string) ([]string, error) {
+		return []string{"<some file>"}, nil
+	})
+	s.PatchValue(backups.StoreArchiveRef, backups.NewTestArchiveStorer(""))
+
+	oplog := &fakeOplog{oldest: s.at(-1), newest: s.at(3), entries: "<oplog entries>"}
+	meta := backupstesting.NewMetadataStarted()
+	meta.Base = "base"
+	paths := backups.Paths{BackupDir: c.MkDir(), DataDir: c.MkDir()}
+	err := s.api.CreateIncremental(meta, &paths, oplog)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(meta.OplogStart, gc.Equals, s.at(2))
+	c.Check(meta.OplogEnd, gc.Equals, s.at(3))
+	c.Check(meta.Checksum(), gc.Equals, "<checksum>")
+
+	// The archive holds only the oplog entries.
+	_, filesToBackUp, dumper := backups.ExposeCreateArgs(received)
+	c.Check(filesToBackUp, jc.DeepEquals, []string{"<some file>"})
+	dumpDir := c.MkDir()
+	err = dumper.Dump(dumpDir)
+	c.Assert(err, jc.ErrorIsNil)
+	data, err := ioutil.ReadFile(filepath.Join(dumpDir, backups.OplogFilename))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(string(data), gc.Equals, "<oplog entries>")
+	c.Check(oplog.from, gc.Equals, s.at(2))
+	c.Check(oplog.to, gc.Equals, s.at(3))
+}
+
+func (s *incrementalSuite) TestCreateIncrementalFirst(c *gc.C) {
+	s.setStored(s.newMeta("base", "", 1, 0))
+	_, testCreate := backups.NewTestCreate(nil)
+	s.PatchValue(backups.RunCreate, testCreate)
+	s.PatchValue(backups.TestGetFilesToBackUp, func(string, *backups.Paths, string) ([]string, error) {
+		return []string{"<some file>"}, nil
+	})
+	s.PatchValue(backups.StoreArchiveRef, backups.NewTestArchiveStorer(""))
+
+	meta := backupstesting.NewMetadataStarted()
+	meta.Base = "base"
+	err := s.api.CreateIncremental(meta, &backups.Paths{}, &fakeOplog{oldest: s.at(0), newest: s.at(3)})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(meta.OplogStart, gc.Equals, s.at(1))
+	c.Check(meta.OplogEnd, gc.Equals, s.at(3))
+}
+
+func (s *incrementalSuite) TestCreateIncrementalOplogRolledOver(c *gc.C) {
+	s.setStored(s.newMeta("base", "", 0, 0))
+	meta := backupstesting.NewMetadataStarted()
+	meta.Base = "base"
+	err := s.api.CreateIncremental(meta, &backups.Paths{}, &fakeOplog{oldest: s.at(1), newest: s.at(3)})
+	c.Assert(err, gc.ErrorMatches, "the oplog no longer goes back to 2018-07-01T00:00:00Z; create a new full backup")
+}
+
+func (s *incrementalSuite) TestCreateIncrementalBadBase(c *gc.C) {
+	s.setStored(s.newMeta("base", "", 0, 0), s.newMeta("inc-1", "base", 0, 1))
+	meta := backupstesting.NewMetadataStarted()
+	meta.Base = "inc-1"
+	err := s.api.CreateIncremental(meta, &backups.Paths{}, &fakeOplog{})
+	c.Check(err, gc.ErrorMatches, `backup "inc-1" is incremental, not a full backup`)
+
+	meta.Base = "missing"
+	err = s.api.CreateIncremental(meta, &backups.Paths{}, &fakeOplog{})
+	c.Check(err, gc.ErrorMatches, `backup "missing" not found`)
+}
+
+func (s *incrementalSuite) TestRestoreChain(c *gc.C) {
+	base := s.newMeta("base", "", 0, 0)
+	all := []*backups.Metadata{
+		base,
+		s.newMeta("inc-3", "base", 2, 3),
+		s.newMeta("inc-1", "base", 0, 1),
+		s.newMeta("inc-2", "base", 1, 2),
+		s.newMeta("other", "other-base", 0, 5),
+	}
+	ids := func(chain []*backups.Metadata) []string {
+		var result []string
+		for _, meta := range chain {
+			result = append(result, meta.ID())
+		}
+		return result
+	}
+
+	chain, err := backups.RestoreChain(base, all, s.at(1))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(ids(chain), jc.DeepEquals, []string{"inc-1"})
+
+	chain, err = backups.RestoreChain(base, all, s.at(2).Add(time.Minute))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(ids(chain), jc.DeepEquals, []string{"inc-1", "inc-2", "inc-3"})
+}
+
+func (s *incrementalSuite) TestRestoreChainErrors(c *gc.C) {
+	base := s.newMeta("base", "", 0, 0)
+	inc := s.newMeta("inc-1", "base", 0, 1)
+	all := []*backups.Metadata{base, inc, s.newMeta("inc-3", "base", 2, 3)}
+
+	_, err := backups.RestoreChain(base, all, s.at(4))
+	c.Check(err, gc.ErrorMatches, `incremental backups of "base" are missing the changes from 2018-07-01T01:00:00Z to 2018-07-01T02:00:00Z`)
+
+	_, err = backups.RestoreChain(base, all[:2], s.at(4))
+	c.Check(err, gc.ErrorMatches, `incremental backups of "base" only extend to 2018-07-01T01:00:00Z, not 2018-07-01T04:00:00Z`)
+
+	_, err = backups.RestoreChain(base, all, s.start)
+	c.Check(err, gc.ErrorMatches, `cannot restore backup "base" to 2018-07-01T00:00:00Z, before it finished at 2018-07-01T00:01:00Z`)
+
+	_, err = backups.RestoreChain(inc, all, s.at(1))
+	c.Check(err, gc.ErrorMatches, `backup "inc-1" is incremental; restore its full backup "base" to a time instead`)
+}
+
+type fakeOplog struct {
+	oldest  time.Time
+	newest  time.Time
+	entries string
+
+	from time.Time
+	to   time.Time
+}
+
+func (o *fakeOplog) Range() (time.Time, time.Time, error) {
+	return o.oldest, o.newest, nil
+}
+
+func (o *fakeOplog) Dump(w io.Writer, from, to time.Time) error {
+	o.from = from
+	o.to = to
+	_, err := io.Copy(w, bytes.NewBufferString(o.entries))
+	return err
+}
@@ -4,6 +4,8 @@
 package backups
 
 import (
+	"time"
+
 	"gopkg.in/juju/names.v2"
 
 	"github.com/juju/juju/core/instance"
@@ -16,4 +18,12 @@ type RestoreArgs struct {
 	NewInstId      instance.Id
 	NewInstTag     names.Tag
 	NewInstSeries  string
+
+	// ToTime, if set, restores the database as it was at that time,
+	// by replaying the oplogs of the backup's incremental backups.
+	ToTime time.Time
+
+	// Key, if set, decrypts an encrypted backup as it's restored.
+	// The controller never stores it, or the decrypted archive.
+	Key *ArchiveKey
 }
@@ -48,6 +48,19 @@ type storageMetaDoc struct {
 	Finished int64  `bson:"finished,minsize"`
 	Notes    string `bson:"notes,omitempty"`
 
+	Scheduled bool `bson:"scheduled,omitempty"`
+
+	// incremental backups
+
+	Base       string `bson:"base,omitempty"`
+	OplogStart int64  `bson:"oplogstart,omitempty"`
+	OplogEnd   int64  `bson:"oplogend,omitempty"`
+
+	// encryption
+
+	Encrypted      bool   `bson:"encrypted,omitempty"`
+	KeyFingerprint string `bson:"keyfingerprint,omitempty"`
+
 	// origin
 
 	Model    string         `bson:"model"`
@@ -124,6 +137,16 @@ func docAsMetadata(doc *storageMetaDoc) *Metadata {
 	meta := NewMetadata()
 	meta.Started = metadocUnixToTime(doc.Started)
 	meta.Notes = doc.Notes
+	meta.Scheduled = doc.Scheduled
+	meta.Base = doc.Base
+	if doc.OplogStart != 0 {
+		meta.OplogStart = metadocUnixToTime(doc.OplogStart)
+	}
+	if doc.OplogEnd != 0 {
+		meta.OplogEnd = metadocUnixToTime(doc.OplogEnd)
+	}
+	meta.Encrypted = doc.Encrypted
+	meta.KeyFingerprint = doc.KeyFingerprint
 
 	meta.Origin.Model = doc.Model
 	meta.Origin.Machine = doc.Machine
@@ -175,6 +198,16 @@ func newStorageMetaDoc(meta *Metadata) storageMetaDoc {
 		doc.Finished = metadocTimeToUnix(*meta.Finished)
 	}
 	doc.Notes = meta.Notes
+	doc.Scheduled = meta.Scheduled
+	doc.Base = meta.Base
+	if !meta.OplogStart.IsZero() {
+		doc.OplogStart = metadocTimeToUnix(meta.OplogStart)
+	}
+	if !meta.OplogEnd.IsZero() {
+		doc.OplogEnd = metadocTimeToUnix(meta.OplogEnd)
+	}
+	doc.Encrypted = meta.Encrypted
+	doc.KeyFingerprint = meta.KeyFingerprint
 
 	doc.Model = meta.Origin.Model
 	doc.Machine = meta.Origin.Machine
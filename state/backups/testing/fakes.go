@@ -5,6 +5,7 @@ package testing
 
 import (
 	"io"
+	"time"
 
 	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
@@ -53,6 +54,12 @@ type FakeBackups struct {
 	KeepCopy bool
 	// NoDownload holds the noDownload bool that was passed in.
 	NoDownload bool
+	// KeyArg holds the archive encryption key that was passed in.
+	KeyArg *backups.ArchiveKey
+	// OplogArg holds the OplogSource that was passed in.
+	OplogArg backups.OplogSource
+	// ToTime holds the time to restore to that was passed in.
+	ToTime time.Time
 }
 
 var _ backups.Backups = (*FakeBackups)(nil)
@@ -64,6 +71,7 @@ func (b *FakeBackups) Create(
 	paths *backups.Paths,
 	dbInfo *backups.DBInfo,
 	keepCopy, noDownload bool,
+	key *backups.ArchiveKey,
 ) (string, error) {
 	b.Calls = append(b.Calls, "Create")
 
@@ -72,6 +80,7 @@ func (b *FakeBackups) Create(
 	b.MetaArg = meta
 	b.KeepCopy = keepCopy
 	b.NoDownload = noDownload
+	b.KeyArg = key
 
 	if b.Meta != nil {
 		*meta = *b.Meta
@@ -80,6 +89,26 @@ func (b *FakeBackups) Create(
 	return b.Filename, b.Error
 }
 
+// CreateIncremental creates and stores a new incremental backup and
+// updates its metadata.
+func (b *FakeBackups) CreateIncremental(
+	meta *backups.Metadata,
+	paths *backups.Paths,
+	oplog backups.OplogSource,
+) error {
+	b.Calls = append(b.Calls, "CreateIncremental")
+
+	b.PathsArg = paths
+	b.MetaArg = meta
+	b.OplogArg = oplog
+
+	if b.Meta != nil {
+		*meta = *b.Meta
+	}
+
+	return errors.Trace(b.Error)
+}
+
 // Add stores the backup and returns its new ID.
 func (b *FakeBackups) Add(archive io.Reader, meta *backups.Metadata) (string, error) {
 	b.Calls = append(b.Calls, "Add")
@@ -117,6 +146,7 @@ func (b *FakeBackups) Restore(bkpId string, args backups.RestoreArgs) (names.Tag
 	b.Calls = append(b.Calls, "Restore")
 	b.PrivateAddr = args.PrivateAddress
 	b.InstanceId = args.NewInstId
+	b.ToTime = args.ToTime
 	return nil, errors.Trace(b.Error)
 }
 
@@ -0,0 +1,376 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups
+
+import (
+	"archive/tar"
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils/hash"
+	"github.com/juju/utils/set"
+	"github.com/juju/version"
+
+	"github.com/juju/juju/state"
+	jujuversion "github.com/juju/juju/version"
+)
+
+// jujuDatabase is the name of the database holding the Juju state.
+const jujuDatabase = "juju"
+
+// collectionsFor returns the collections that must be in a backup of
+// the juju database made by the Juju version. They're known only for
+// backups made by this version of Juju or a later one, whose schema
+// creates at least the collections this version's does; for earlier
+// versions, nil is returned.
+func collectionsFor(v version.Number) []string {
+	current := jujuversion.Current
+	if v.Major < current.Major || v.Major == current.Major && v.Minor < current.Minor {
+		return nil
+	}
+	return state.InitialCollections()
+}
+
+// requiredFiles holds the files, relative to the data directory, that
+// must be in the files bundle of every backup archive.
+var requiredFiles = []string{
+	sshIdentFile,
+	dbPEM,
+	dbSecret,
+}
+
+// Verification holds the results of checking a backup archive.
+type Verification struct {
+	// Metadata is the metadata read from the archive, if it could
+	// be read.
+	Metadata *Metadata
+
+	// Checksum and Size describe the compressed archive as it was read.
+	Checksum string
+	Size     int64
+
+	// Encrypted records whether the archive was encrypted.
+	Encrypted bool
+
+	// Databases maps the name of each database in the archive's dump
+	// to the names of the collections dumped from it.
+	Databases map[string][]string
+
+	// Files holds the paths, relative to the root directory, of the
+	// files (but not directories) in the archive's files bundle, which
+	// restore puts back on the controller machine.
+	Files []string
+
+	// Problems describes everything found wrong with the archive.
+	// The archive is usable only if there are none.
+	Problems []string
+}
+
+func (v *Verification) addProblem(format string, args ...interface{}) {
+	v.Problems = append(v.Problems, fmt.Sprintf(format, args...))
+}
+
+// RestoredPaths returns the paths on the controller machine that
+// restoring the archive would replace. Files in the data directory
+// are grouped by the top-level entry they're in.
+func (v *Verification) RestoredPaths() []string {
+	dataDirPrefix := strings.TrimPrefix(dataDir, "/") + "/"
+	paths := set.NewStrings()
+	for _, file := range v.Files {
+		if strings.HasPrefix(file, dataDirPrefix) {
+			rel := strings.TrimPrefix(file, dataDirPrefix)
+			file = path.Join(dataDirPrefix, strings.SplitN(rel, "/", 2)[0])
+		}
+		paths.Add("/" + file)
+	}
+	return paths.SortedValues()
+}
+
+// VerifyArchive unpacks the compressed backup archive and checks that
+// it could be restored: that its metadata is complete, that its files
+// bundle holds the files restore needs, and that its database dump
+// holds the collections expected for the Juju version that made it.
+// If expected isn't nil, the archive's checksum, size and metadata
+// must also match it.
+//
+// Problems with the archive are recorded in the result; an error is
+// only returned if the archive couldn't be checked at all.
+func VerifyArchive(archive io.Reader, expected *Metadata) (*Verification, error) {
+	return verifyArchive(archive, expected, nil)
+}
+
+// VerifyEncryptedArchive checks the backup archive as VerifyArchive
+// does, decrypting it with the key if it's encrypted.
+func VerifyEncryptedArchive(archive io.Reader, expected *Metadata, key ArchiveKey) (*Verification, error) {
+	return verifyArchive(archive, expected, &key)
+}
+
+func verifyArchive(archive io.Reader, expected *Metadata, key *ArchiveKey) (*Verification, error) {
+	var v Verification
+
+	counter := &countingWriter{}
+	hasher := hash.NewHashingWriter(counter, sha1.New())
+	encrypted, reader, err := IsEncryptedArchive(io.TeeReader(archive, hasher))
+	if err != nil {
+		v.addProblem("cannot unpack archive: %v", errors.Cause(err))
+		return &v, nil
+	}
+	v.Encrypted = encrypted
+	finish := func() (*Verification, error) {
+		// Read any trailing data, so it's included in the checksum.
+		if _, err := io.Copy(ioutil.Discard, reader); err != nil {
+			return nil, errors.Trace(err)
+		}
+		v.Checksum = hasher.Base64Sum()
+		v.Size = counter.size
+		if expected != nil {
+			verifyFileInfo(&v, expected)
+		}
+		return &v, nil
+	}
+
+	plain := reader
+	if encrypted {
+		if key == nil {
+			v.addProblem("archive is encrypted; its key is needed to verify it")
+			return finish()
+		}
+		plain, err = NewDecryptingReader(reader, *key)
+		if err != nil {
+			v.addProblem("%v", err)
+			return finish()
+		}
+	}
+	ws, err := NewArchiveWorkspaceReader(plain)
+	if ws == nil {
+		return nil, errors.Trace(err)
+	}
+	defer ws.Close()
+	if err != nil {
+		v.addProblem("cannot unpack archive: %v", errors.Cause(err))
+		return finish()
+	}
+	if encrypted {
+		// The decrypted archive's integrity is only checked once
+		// it's been read to the end.
+		if _, err := io.Copy(ioutil.Discard, plain); err != nil {
+			v.addProblem("cannot decrypt archive: %v", errors.Cause(err))
+			return finish()
+		}
+	}
+	if _, err := finish(); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	meta, err := ws.Metadata()
+	if os.IsNotExist(errors.Cause(err)) {
+		v.addProblem("missing %s", metadataFile)
+	} else if err != nil {
+		v.addProblem("cannot read %s: %v", metadataFile, errors.Cause(err))
+	} else {
+		v.Metadata = meta
+		verifyMetadata(&v, expected)
+	}
+
+	if err := verifyFilesBundle(&v, ws.FilesBundle); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := verifyDump(&v, ws.DBDumpDir); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &v, nil
+}
+
+// verifyFileInfo checks the archive's checksum and size against the
+// expected metadata.
+func verifyFileInfo(v *Verification, expected *Metadata) {
+	if expected.Checksum() != "" && expected.Checksum() != v.Checksum {
+		v.addProblem("checksum %q does not match expected %q", v.Checksum, expected.Checksum())
+	}
+	if expected.Size() != 0 && expected.Size() != v.Size {
+		v.addProblem("size %d does not match expected %d", v.Size, expected.Size())
+	}
+	if expected.Encrypted && !v.Encrypted {
+		v.addProblem("archive is not encrypted, but was expected to be")
+	}
+}
+
+// verifyMetadata checks that the archive's metadata is complete, and
+// matches the expected metadata if there is any.
+func verifyMetadata(v *Verification, expected *Metadata) {
+	meta := v.Metadata
+	if meta.Started.IsZero() {
+		v.addProblem("metadata is missing the start time")
+	}
+	if meta.Origin.Model == "" {
+		v.addProblem("metadata is missing the model UUID")
+	}
+	if meta.Origin.Machine == "" {
+		v.addProblem("metadata is missing the machine ID")
+	}
+	if meta.Origin.Version == version.Zero {
+		v.addProblem("metadata is missing the Juju version")
+	} else if meta.Origin.Version.Major != jujuversion.Current.Major {
+		v.addProblem("backups made by Juju %s cannot be restored by Juju %s", meta.Origin.Version, jujuversion.Current)
+	}
+	if meta.Base != "" && meta.OplogEnd.Before(meta.OplogStart) {
+		v.addProblem("metadata has an oplog range ending before it starts")
+	}
+	if expected == nil {
+		return
+	}
+	if meta.Origin.Model != expected.Origin.Model {
+		v.addProblem("metadata model UUID %q does not match expected %q", meta.Origin.Model, expected.Origin.Model)
+	}
+	if meta.Origin.Version != expected.Origin.Version {
+		v.addProblem("metadata Juju version %s does not match expected %s", meta.Origin.Version, expected.Origin.Version)
+	}
+	if meta.Base != expected.Base {
+		v.addProblem("metadata base backup %q does not match expected %q", meta.Base, expected.Base)
+	}
+}
+
+// verifyFilesBundle lists the files in the bundle, and checks that
+// those restore needs are there.
+func verifyFilesBundle(v *Verification, filename string) error {
+	f, err := os.Open(filename)
+	if os.IsNotExist(err) {
+		v.addProblem("missing %s", filesBundle)
+		return nil
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	defer f.Close()
+
+	found := set.NewStrings()
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			v.addProblem("cannot read %s: %v", filesBundle, err)
+			return nil
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(hdr.Name, "/"), "/")
+		if hdr.Typeflag != tar.TypeDir {
+			v.Files = append(v.Files, name)
+		}
+		found.Add(name)
+	}
+
+	dataDirPrefix := strings.TrimPrefix(dataDir, "/")
+	for _, required := range requiredFiles {
+		if !found.Contains(path.Join(dataDirPrefix, required)) {
+			v.addProblem("%s is missing %s", filesBundle, path.Join(dataDir, required))
+		}
+	}
+	var agentConf, tools bool
+	for _, name := range found.Values() {
+		rel := strings.TrimPrefix(name, dataDirPrefix+"/")
+		if ok, _ := path.Match(path.Join(agentsDir, agentsConfs, "agent.conf"), rel); ok {
+			agentConf = true
+		}
+		if strings.HasPrefix(rel, toolsDir+"/") {
+			tools = true
+		}
+	}
+	if !agentConf {
+		v.addProblem("%s is missing the machine agent configuration", filesBundle)
+	}
+	if !tools {
+		v.addProblem("%s is missing the agent binaries", filesBundle)
+	}
+	return nil
+}
+
+// verifyDump lists the databases and collections in the dump, and
+// checks that they're the ones expected for the Juju version that
+// made the backup. Incremental backups hold only oplog entries.
+func verifyDump(v *Verification, dumpDir string) error {
+	if _, err := os.Stat(dumpDir); os.IsNotExist(err) {
+		v.addProblem("missing database dump")
+		return nil
+	}
+	databases, err := listDatabases(dumpDir)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	v.Databases = make(map[string][]string)
+	for _, db := range databases.SortedValues() {
+		collections, err := listCollections(filepath.Join(dumpDir, db))
+		if err != nil {
+			return errors.Trace(err)
+		}
+		v.Databases[db] = collections
+	}
+
+	if v.Metadata != nil && v.Metadata.Base != "" {
+		if _, err := os.Stat(filepath.Join(dumpDir, oplogFilename)); os.IsNotExist(err) {
+			v.addProblem("incremental backup is missing %s", oplogFilename)
+		} else if err != nil {
+			return errors.Trace(err)
+		}
+		return nil
+	}
+
+	collections, ok := v.Databases[jujuDatabase]
+	if !ok {
+		v.addProblem("database dump is missing the %s database", jujuDatabase)
+		return nil
+	}
+	if v.Metadata == nil {
+		// Without the Juju version, the schema is unknown.
+		return nil
+	}
+	var missing []string
+	dumped := set.NewStrings(collections...)
+	for _, name := range collectionsFor(v.Metadata.Origin.Version) {
+		if !dumped.Contains(name) {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		v.addProblem("%s database dump is missing collections expected for Juju %s: %s",
+			jujuDatabase, v.Metadata.Origin.Version, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// listCollections returns the names of the collections dumped by
+// mongodump into the database's dump directory.
+func listCollections(dbDumpDir string) ([]string, error) {
+	list, err := ioutil.ReadDir(dbDumpDir)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var collections []string
+	for _, info := range list {
+		if info.IsDir() || filepath.Ext(info.Name()) != ".bson" {
+			continue
+		}
+		collections = append(collections, strings.TrimSuffix(info.Name(), ".bson"))
+	}
+	sort.Strings(collections)
+	return collections, nil
+}
+
+// countingWriter discards what's written to it, counting the bytes.
+type countingWriter struct {
+	size int64
+}
+
+func (w *countingWriter) Write(data []byte) (int, error) {
+	w.size += int64(len(data))
+	return len(data), nil
+}
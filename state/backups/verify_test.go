@@ -0,0 +1,235 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups_test
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/backups"
+	bt "github.com/juju/juju/state/backups/testing"
+	jujuversion "github.com/juju/juju/version"
+)
+
+type verifySuite struct {
+	testing.IsolationSuite
+	meta  *backups.Metadata
+	files []bt.File
+	dump  []bt.File
+}
+
+var _ = gc.Suite(&verifySuite{})
+
+func (s *verifySuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.meta = bt.NewMetadataStarted()
+	s.meta.Origin.Version = jujuversion.Current
+	s.files = []bt.File{
+		{Name: "var/lib/juju/agents/machine-0/agent.conf", Content: "<agent config>"},
+		{Name: "var/lib/juju/tools/2.4.0-bionic-amd64/jujud", Content: "<jujud>"},
+		{Name: "var/lib/juju/init/jujud-machine-0/exec-start.sh", Content: "<script>"},
+		{Name: "var/lib/juju/system-identity", Content: "<ssh key>"},
+		{Name: "var/lib/juju/server.pem", Content: "<cert>"},
+		{Name: "var/lib/juju/shared-secret", Content: "<secret>"},
+		{Name: "home/ubuntu/.ssh/authorized_keys", Content: "<keys>"},
+	}
+	s.dump = []bt.File{{Name: "juju", IsDir: true}, {Name: "admin", IsDir: true}}
+	for _, name := range state.InitialCollections() {
+		s.dump = append(s.dump, bt.File{Name: "juju/" + name + ".bson", Content: "<BSON>"})
+	}
+	s.dump = append(s.dump,
+		bt.File{Name: "admin/system.users.bson", Content: "<BSON>"},
+		bt.File{Name: "oplog.bson", Content: "<BSON>"},
+	)
+}
+
+func (s *verifySuite) verify(c *gc.C, meta *backups.Metadata, expected *backups.Metadata) *backups.Verification {
+	archive, err := bt.NewArchive(meta, s.files, s.dump)
+	c.Assert(err, jc.ErrorIsNil)
+	v, err := backups.VerifyArchive(archive, expected)
+	c.Assert(err, jc.ErrorIsNil)
+	return v
+}
+
+func (s *verifySuite) TestVerifyArchive(c *gc.C) {
+	archive, err := bt.NewArchive(s.meta, s.files, s.dump)
+	c.Assert(err, jc.ErrorIsNil)
+	data := archive.Bytes()
+	expected := backups.NewMetadata()
+	expected.Origin = s.meta.Origin
+	sum := sha1.Sum(data)
+	checksum := base64.StdEncoding.EncodeToString(sum[:])
+	err = expected.SetFileInfo(int64(len(data)), checksum, "SHA-1, base64 encoded")
+	c.Assert(err, jc.ErrorIsNil)
+
+	v, err := backups.VerifyArchive(bytes.NewBuffer(data), expected)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(v.Problems, gc.HasLen, 0)
+	c.Check(v.Checksum, gc.Equals, expected.Checksum())
+	c.Check(v.Size, gc.Equals, int64(len(data)))
+	c.Check(v.Metadata.Origin, jc.DeepEquals, s.meta.Origin)
+	c.Check(v.Databases, jc.DeepEquals, map[string][]string{
+		"admin": {"system.users"},
+		"juju":  state.InitialCollections(),
+	})
+	c.Check(v.RestoredPaths(), jc.DeepEquals, []string{
+		"/home/ubuntu/.ssh/authorized_keys",
+		"/var/lib/juju/agents",
+		"/var/lib/juju/init",
+		"/var/lib/juju/server.pem",
+		"/var/lib/juju/shared-secret",
+		"/var/lib/juju/system-identity",
+		"/var/lib/juju/tools",
+	})
+}
+
+func (s *verifySuite) TestVerifyArchiveMismatch(c *gc.C) {
+	expected := bt.NewMetadata()
+	expected.Origin.Model = "another-model"
+	expected.Origin.Version = s.meta.Origin.Version
+
+	v := s.verify(c, s.meta, expected)
+	c.Assert(v.Problems, gc.HasLen, 3)
+	c.Check(v.Problems[0], gc.Matches, `checksum ".*" does not match expected "787b8915389d921fa23fb40e16ae81ea979758bf"`)
+	c.Check(v.Problems[1], gc.Matches, `size \d+ does not match expected 10`)
+	c.Check(v.Problems[2], gc.Equals, `metadata model UUID "`+s.meta.Origin.Model+`" does not match expected "another-model"`)
+}
+
+func (s *verifySuite) TestVerifyArchiveMissingMetadata(c *gc.C) {
+	v := s.verify(c, nil, nil)
+	c.Check(v.Problems, jc.DeepEquals, []string{"missing metadata.json"})
+	c.Check(v.Databases["juju"], gc.HasLen, len(state.InitialCollections()))
+}
+
+func (s *verifySuite) TestVerifyArchiveIncompleteMetadata(c *gc.C) {
+	s.meta.Origin.Model = ""
+	s.meta.Origin.Machine = ""
+	v := s.verify(c, s.meta, nil)
+	c.Check(v.Problems, jc.DeepEquals, []string{
+		"metadata is missing the model UUID",
+		"metadata is missing the machine ID",
+	})
+}
+
+func (s *verifySuite) TestVerifyArchiveMissingFiles(c *gc.C) {
+	var files []bt.File
+	for _, file := range s.files {
+		if !strings.Contains(file.Name, "agents") && !strings.HasSuffix(file.Name, "server.pem") {
+			files = append(files, file)
+		}
+	}
+	s.files = files
+	v := s.verify(c, s.meta, nil)
+	c.Check(v.Problems, jc.DeepEquals, []string{
+		"root.tar is missing /var/lib/juju/server.pem",
+		"root.tar is missing the machine agent configuration",
+	})
+}
+
+func (s *verifySuite) TestVerifyArchiveMissingCollections(c *gc.C) {
+	collections := state.InitialCollections()
+	missing := collections[:2]
+	var dump []bt.File
+	for _, file := range s.dump {
+		if file.Name != "juju/"+missing[0]+".bson" && file.Name != "juju/"+missing[1]+".bson" {
+			dump = append(dump, file)
+		}
+	}
+	s.dump = dump
+	v := s.verify(c, s.meta, nil)
+	c.Check(v.Problems, jc.DeepEquals, []string{
+		fmt.Sprintf("juju database dump is missing collections expected for Juju %s: %s, %s",
+			jujuversion.Current, missing[0], missing[1]),
+	})
+}
+
+func (s *verifySuite) TestVerifyArchiveEarlierSchema(c *gc.C) {
+	// The collections created by earlier versions of Juju aren't
+	// known, so they aren't checked.
+	s.meta.Origin.Version = version.MustParse("2.4.0")
+	s.dump = []bt.File{
+		{Name: "juju", IsDir: true},
+		{Name: "juju/txns.bson", Content: "<BSON>"},
+	}
+	v := s.verify(c, s.meta, nil)
+	c.Check(v.Problems, gc.HasLen, 0)
+}
+
+func (s *verifySuite) TestVerifyArchiveLegacyVersion(c *gc.C) {
+	s.meta.Origin.Version = version.MustParse("1.25.6")
+	v := s.verify(c, s.meta, nil)
+	c.Check(v.Problems, jc.DeepEquals, []string{
+		fmt.Sprintf("backups made by Juju 1.25.6 cannot be restored by Juju %s", jujuversion.Current),
+	})
+}
+
+func (s *verifySuite) TestVerifyArchiveMissingDatabase(c *gc.C) {
+	s.dump = []bt.File{{Name: "oplog.bson", Content: "<BSON>"}}
+	v := s.verify(c, s.meta, nil)
+	c.Check(v.Problems, jc.DeepEquals, []string{"database dump is missing the juju database"})
+}
+
+func (s *verifySuite) TestVerifyArchiveIncremental(c *gc.C) {
+	s.meta.Base = "base-id"
+	s.meta.OplogStart = s.meta.Started.Add(-time.Hour)
+	s.meta.OplogEnd = s.meta.Started
+	s.dump = []bt.File{{Name: "oplog.bson", Content: "<BSON>"}}
+	v := s.verify(c, s.meta, nil)
+	c.Check(v.Problems, gc.HasLen, 0)
+
+	s.dump = nil
+	v = s.verify(c, s.meta, nil)
+	c.Check(v.Problems, jc.DeepEquals, []string{"incremental backup is missing oplog.bson"})
+}
+
+func (s *verifySuite) TestVerifyEncryptedArchive(c *gc.C) {
+	archive, err := bt.NewArchive(s.meta, s.files, s.dump)
+	c.Assert(err, jc.ErrorIsNil)
+	var buf bytes.Buffer
+	w, err := backups.NewEncryptingWriter(&buf, backups.ArchiveKey{Passphrase: "secret"})
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = w.Write(archive.Bytes())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(w.Close(), jc.ErrorIsNil)
+	data := buf.Bytes()
+	sum := sha1.Sum(data)
+	expected := backups.NewMetadata()
+	expected.Origin = s.meta.Origin
+	expected.Encrypted = true
+	err = expected.SetFileInfo(int64(len(data)), base64.StdEncoding.EncodeToString(sum[:]), "SHA-1, base64 encoded")
+	c.Assert(err, jc.ErrorIsNil)
+
+	v, err := backups.VerifyEncryptedArchive(bytes.NewReader(data), expected, backups.ArchiveKey{Passphrase: "secret"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(v.Problems, gc.HasLen, 0)
+	c.Check(v.Encrypted, jc.IsTrue)
+	c.Check(v.Size, gc.Equals, int64(len(data)))
+	c.Check(v.Databases["juju"], gc.HasLen, len(state.InitialCollections()))
+
+	v, err = backups.VerifyArchive(bytes.NewReader(data), expected)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(v.Problems, jc.DeepEquals, []string{"archive is encrypted; its key is needed to verify it"})
+	c.Check(v.Checksum, gc.Equals, expected.Checksum())
+
+	v, err = backups.VerifyEncryptedArchive(bytes.NewReader(data), nil, backups.ArchiveKey{Passphrase: "wrong"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(v.Problems, jc.DeepEquals, []string{"cannot decrypt archive: incorrect passphrase or key"})
+}
+
+func (s *verifySuite) TestVerifyArchiveNotGzipped(c *gc.C) {
+	v, err := backups.VerifyArchive(bytes.NewBufferString("not an archive"), nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(v.Problems, gc.HasLen, 1)
+	c.Check(v.Problems[0], gc.Matches, "cannot unpack archive: .*")
+}
@@ -0,0 +1,129 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+const backupScheduleStatusKey = "backupScheduleStatus"
+
+// BackupScheduleStatus records the progress of the controller's
+// scheduled backups.
+type BackupScheduleStatus struct {
+	// NextRun is when the next scheduled backup is due, or zero if
+	// backups aren't scheduled.
+	NextRun time.Time
+
+	// LastRun is when the last scheduled backup was started, or zero
+	// if there hasn't been one.
+	LastRun time.Time
+
+	// LastBackupID is the ID of the backup made by the last run, if
+	// it made one.
+	LastBackupID string
+
+	// LastError describes why the last run failed, if it did.
+	LastError string
+}
+
+// backupScheduleStatusDoc is the document recording the progress of
+// the controller's scheduled backups, in the controllers collection.
+type backupScheduleStatusDoc struct {
+	DocID        string `bson:"_id"`
+	NextRun      int64  `bson:"next-run"`
+	LastRun      int64  `bson:"last-run"`
+	LastBackupID string `bson:"last-backup-id"`
+	LastError    string `bson:"last-error"`
+}
+
+func unixNanoOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func timeOrZero(nanos int64) time.Time {
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos).UTC()
+}
+
+// BackupScheduleStatus returns the progress of the controller's
+// scheduled backups. Nothing is recorded until the backup scheduler
+// has first run, in which case the status is empty.
+func (st *State) BackupScheduleStatus() (BackupScheduleStatus, error) {
+	controllers, closer := st.db().GetCollection(controllersC)
+	defer closer()
+
+	var doc backupScheduleStatusDoc
+	err := controllers.FindId(backupScheduleStatusKey).One(&doc)
+	if err == mgo.ErrNotFound {
+		return BackupScheduleStatus{}, nil
+	} else if err != nil {
+		return BackupScheduleStatus{}, errors.Annotate(err, "cannot get backup schedule status")
+	}
+	return BackupScheduleStatus{
+		NextRun:      timeOrZero(doc.NextRun),
+		LastRun:      timeOrZero(doc.LastRun),
+		LastBackupID: doc.LastBackupID,
+		LastError:    doc.LastError,
+	}, nil
+}
+
+// SetBackupScheduleStatus records the progress of the controller's
+// scheduled backups.
+func (st *State) SetBackupScheduleStatus(status BackupScheduleStatus) error {
+	doc := backupScheduleStatusDoc{
+		DocID:        backupScheduleStatusKey,
+		NextRun:      unixNanoOrZero(status.NextRun),
+		LastRun:      unixNanoOrZero(status.LastRun),
+		LastBackupID: status.LastBackupID,
+		LastError:    status.LastError,
+	}
+	buildTxn := func(int) ([]txn.Op, error) {
+		controllers, closer := st.db().GetCollection(controllersC)
+		defer closer()
+		count, err := controllers.FindId(backupScheduleStatusKey).Count()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if count == 0 {
+			return []txn.Op{{
+				C:      controllersC,
+				Id:     backupScheduleStatusKey,
+				Assert: txn.DocMissing,
+				Insert: &doc,
+			}}, nil
+		}
+		return []txn.Op{{
+			C:      controllersC,
+			Id:     backupScheduleStatusKey,
+			Assert: txn.DocExists,
+			Update: bson.D{{"$set", bson.D{
+				{"next-run", doc.NextRun},
+				{"last-run", doc.LastRun},
+				{"last-backup-id", doc.LastBackupID},
+				{"last-error", doc.LastError},
+			}}},
+		}}, nil
+	}
+	if err := st.db().Run(buildTxn); err != nil {
+		return errors.Annotate(err, "cannot set backup schedule status")
+	}
+	return nil
+}
+
+// WatchBackupScheduleStatus returns a NotifyWatcher that notifies
+// when the progress of the controller's scheduled backups changes.
+func (st *State) WatchBackupScheduleStatus() NotifyWatcher {
+	return newEntityWatcher(st, controllersC, backupScheduleStatusKey)
+}
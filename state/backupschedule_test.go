@@ -0,0 +1,77 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+	statetesting "github.com/juju/juju/state/testing"
+)
+
+type BackupScheduleSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&BackupScheduleSuite{})
+
+func (s *BackupScheduleSuite) TestStatusNotSet(c *gc.C) {
+	status, err := s.State.BackupScheduleStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status, jc.DeepEquals, state.BackupScheduleStatus{})
+}
+
+func (s *BackupScheduleSuite) TestSetStatus(c *gc.C) {
+	next := time.Date(2018, 7, 2, 0, 0, 0, 0, time.UTC)
+	last := time.Date(2018, 7, 1, 0, 0, 0, 0, time.UTC)
+	err := s.State.SetBackupScheduleStatus(state.BackupScheduleStatus{NextRun: next})
+	c.Assert(err, jc.ErrorIsNil)
+	status, err := s.State.BackupScheduleStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status, jc.DeepEquals, state.BackupScheduleStatus{NextRun: next})
+
+	err = s.State.SetBackupScheduleStatus(state.BackupScheduleStatus{
+		NextRun:   next,
+		LastRun:   last,
+		LastError: "boom",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	status, err = s.State.BackupScheduleStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status, jc.DeepEquals, state.BackupScheduleStatus{
+		NextRun:   next,
+		LastRun:   last,
+		LastError: "boom",
+	})
+
+	err = s.State.SetBackupScheduleStatus(state.BackupScheduleStatus{
+		LastRun:      last,
+		LastBackupID: "20180701-000000.deadbeef",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	status, err = s.State.BackupScheduleStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status, jc.DeepEquals, state.BackupScheduleStatus{
+		LastRun:      last,
+		LastBackupID: "20180701-000000.deadbeef",
+	})
+}
+
+func (s *BackupScheduleSuite) TestWatchStatus(c *gc.C) {
+	w := s.State.WatchBackupScheduleStatus()
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertOneChange()
+
+	err := s.State.SetBackupScheduleStatus(state.BackupScheduleStatus{LastError: "boom"})
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+
+	err = s.State.SetBackupScheduleStatus(state.BackupScheduleStatus{LastBackupID: "20180701-000000.deadbeef"})
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+}
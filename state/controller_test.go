@@ -46,6 +46,15 @@ func (s *ControllerSuite) TestControllerAndModelConfigInitialisation(c *gc.C) {
 		controller.MeteringURL,
 		controller.APIPortOpenDelay,
 		controller.ControllerAPIPort,
+		controller.AuditLogSyslogHost,
+		controller.AuditLogSyslogCACert,
+		controller.AuditLogSyslogClientCert,
+		controller.AuditLogSyslogClientKey,
+		controller.AuditLogWebhookURL,
+		controller.BackupSchedule,
+		controller.BackupRetentionCount,
+		controller.BackupRetentionAge,
+		controller.BackupCopyDir,
 	)
 	for _, controllerAttr := range controller.ControllerOnlyConfigAttributes {
 		v, ok := controllerSettings.Get(controllerAttr)
@@ -717,6 +717,30 @@ func PrimeActions(c *gc.C, age time.Time, unit *Unit, count int) {
 	c.Assert(err, jc.ErrorIsNil)
 }
 
+// PrimeNamedActions adds count completed actions with the given name
+// to the unit, completed at the given time.
+func PrimeNamedActions(c *gc.C, completed time.Time, unit *Unit, name string, count int) {
+	actionCollection, closer := unit.st.db().GetCollection(actionsC)
+	defer closer()
+
+	var actionDocs []interface{}
+	for i := 0; i < count; i++ {
+		id, err := jutils.NewUUID()
+		c.Assert(err, jc.ErrorIsNil)
+		actionDocs = append(actionDocs, actionDoc{
+			DocId:     id.String(),
+			ModelUUID: unit.st.ModelUUID(),
+			Receiver:  unit.Name(),
+			Name:      name,
+			Completed: completed,
+			Status:    ActionCompleted,
+		})
+	}
+
+	err := actionCollection.Writeable().Insert(actionDocs...)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
 // GetInternalWorkers returns the internal workers managed by a State
 // to allow inspection in tests.
 func GetInternalWorkers(st *State) worker.Worker {
@@ -257,6 +257,59 @@ func (logger *DbLogger) Log(records []LogRecord) error {
 	return errors.Annotatef(err, "inserting %d log record(s)", len(records))
 }
 
+// ImportLog writes the log records that aren't already in the
+// database, for importing previously exported logs. A record is
+// already there if one with the same time, entity, module, location,
+// level and message is, so that importing overlapping archives does
+// not duplicate records.
+func (logger *DbLogger) ImportLog(records []LogRecord) error {
+	type recordKey struct {
+		time     int64
+		entity   string
+		module   string
+		location string
+		level    int
+		message  string
+	}
+	seen := make(map[recordKey]bool)
+	var missing []LogRecord
+	for _, r := range records {
+		if err := validateInputLogRecord(r); err != nil {
+			return errors.Annotate(err, "validating input log record")
+		}
+		key := recordKey{
+			time:     r.Time.UnixNano(),
+			entity:   r.Entity.String(),
+			module:   r.Module,
+			location: r.Location,
+			level:    int(r.Level),
+			message:  r.Message,
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		count, err := logger.logsColl.Find(bson.D{
+			{"t", key.time},
+			{"n", key.entity},
+			{"m", key.module},
+			{"l", key.location},
+			{"v", key.level},
+			{"x", key.message},
+		}).Count()
+		if err != nil {
+			return errors.Annotate(err, "checking for existing log record")
+		}
+		if count == 0 {
+			missing = append(missing, r)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return errors.Trace(logger.Log(missing))
+}
+
 func validateInputLogRecord(r LogRecord) error {
 	if r.Entity == nil {
 		return errors.NotValidf("missing Entity")
@@ -315,16 +368,34 @@ type LogRecord struct {
 // LogTailerParams specifies the filtering a LogTailer should apply to
 // logs in order to decide which to return.
 type LogTailerParams struct {
-	StartID       int64
-	StartTime     time.Time
-	MinLevel      loggo.Level
-	InitialLines  int
-	NoTail        bool
-	IncludeEntity []string
-	ExcludeEntity []string
-	IncludeModule []string
-	ExcludeModule []string
-	Oplog         *mgo.Collection // For testing only
+	StartID         int64
+	StartTime       time.Time
+	MinLevel        loggo.Level
+	InitialLines    int
+	NoTail          bool
+	IncludeEntity   []string
+	ExcludeEntity   []string
+	IncludeModule   []string
+	ExcludeModule   []string
+	IncludeLocation []string
+	ExcludeLocation []string
+	Oplog           *mgo.Collection // For testing only
+
+	// EndTime, if set, excludes logs recorded at or after that time.
+	// Once it has passed, the tailer stops.
+	EndTime time.Time
+
+	// IncludeMessage and ExcludeMessage, if set, are patterns that
+	// log messages must, or must not, contain. In a pattern "*"
+	// matches any sequence of characters and every other character
+	// matches itself. See ValidateLogMessagePattern for the limits
+	// placed on patterns.
+	IncludeMessage string
+	ExcludeMessage string
+
+	// Clock is used to determine when EndTime has passed. If it is
+	// nil, the wall clock is used.
+	Clock clock.Clock
 }
 
 // oplogOverlap is used to decide on the initial oplog timestamp to
@@ -360,6 +431,14 @@ type LogTailerState interface {
 // NewLogTailer returns a LogTailer which filters according to the
 // parameters given.
 func NewLogTailer(st LogTailerState, params LogTailerParams) (LogTailer, error) {
+	for _, pattern := range []string{params.IncludeMessage, params.ExcludeMessage} {
+		if err := ValidateLogMessagePattern(pattern); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if params.Clock == nil {
+		params.Clock = clock.WallClock
+	}
 	session := st.MongoSession().Copy()
 	t := &logTailer{
 		modelUUID:       st.ModelUUID(),
@@ -422,13 +501,22 @@ func (t *logTailer) loop() error {
 		return err
 	}
 
-	if t.params.NoTail {
+	if t.params.NoTail || t.endTimePassed() {
 		return nil
 	}
 
 	return t.tailOplog()
 }
 
+// endTimePassed returns true if no more logs can be reported
+// because the requested end time has passed.
+func (t *logTailer) endTimePassed() bool {
+	if t.params.EndTime.IsZero() {
+		return false
+	}
+	return !t.params.Clock.Now().Before(t.params.EndTime)
+}
+
 func (t *logTailer) processReversed(query *mgo.Query) error {
 	// We must sort by exactly the fields in the index and exactly reversed
 	// so that Mongo will use the index and not try to sort in memory.
@@ -565,6 +653,11 @@ func (t *logTailer) tailOplog() error {
 	logger.Tracef("LogTailer starting oplog tailing: recent id count=%d, lastTime=%s, minOplogTs=%s",
 		recentIds.Length(), t.lastTime, minOplogTs)
 
+	var endTimeReached <-chan time.Time
+	if !t.params.EndTime.IsZero() {
+		endTimeReached = t.params.Clock.After(t.params.EndTime.Sub(t.params.Clock.Now()))
+	}
+
 	// If we get a deserialisation error, write out the first failure,
 	// but don't write out any additional errors until we either hit
 	// a good value, or end the method.
@@ -574,6 +667,8 @@ func (t *logTailer) tailOplog() error {
 		select {
 		case <-t.tomb.Dying():
 			return tomb.ErrDying
+		case <-endTimeReached:
+			return nil
 		case oplogDoc, ok := <-oplogTailer.Out():
 			if !ok {
 				return errors.Annotate(oplogTailer.Err(), "oplog tailer died")
@@ -621,8 +716,15 @@ func (t *logTailer) tailOplog() error {
 
 func (t *logTailer) paramsToSelector(params LogTailerParams, prefix string) bson.D {
 	sel := bson.D{}
+	timeSel := bson.M{}
 	if !params.StartTime.IsZero() {
-		sel = append(sel, bson.DocElem{"t", bson.M{"$gte": params.StartTime.UnixNano()}})
+		timeSel["$gte"] = params.StartTime.UnixNano()
+	}
+	if !params.EndTime.IsZero() {
+		timeSel["$lt"] = params.EndTime.UnixNano()
+	}
+	if len(timeSel) > 0 {
+		sel = append(sel, bson.DocElem{"t", timeSel})
 	}
 	if params.MinLevel > loggo.UNSPECIFIED {
 		sel = append(sel, bson.DocElem{"v", bson.M{"$gte": int(params.MinLevel)}})
@@ -643,6 +745,22 @@ func (t *logTailer) paramsToSelector(params LogTailerParams, prefix string) bson
 		sel = append(sel,
 			bson.DocElem{"m", bson.M{"$not": bson.RegEx{Pattern: makeModulePattern(params.ExcludeModule)}}})
 	}
+	if len(params.IncludeLocation) > 0 {
+		sel = append(sel,
+			bson.DocElem{"l", bson.RegEx{Pattern: makeLocationPattern(params.IncludeLocation)}})
+	}
+	if len(params.ExcludeLocation) > 0 {
+		sel = append(sel,
+			bson.DocElem{"l", bson.M{"$not": bson.RegEx{Pattern: makeLocationPattern(params.ExcludeLocation)}}})
+	}
+	if params.IncludeMessage != "" {
+		sel = append(sel,
+			bson.DocElem{"x", bson.RegEx{Pattern: makeMessagePattern(params.IncludeMessage)}})
+	}
+	if params.ExcludeMessage != "" {
+		sel = append(sel,
+			bson.DocElem{"x", bson.M{"$not": bson.RegEx{Pattern: makeMessagePattern(params.ExcludeMessage)}}})
+	}
 	if prefix != "" {
 		for i, elem := range sel {
 			sel[i].Name = prefix + elem.Name
@@ -654,9 +772,11 @@ func (t *logTailer) paramsToSelector(params LogTailerParams, prefix string) bson
 func makeEntityPattern(entities []string) string {
 	var patterns []string
 	for _, entity := range entities {
-		// Convert * wildcard to the regex equivalent. This is safe
-		// because * never appears in entity names.
-		patterns = append(patterns, strings.Replace(entity, "*", ".*", -1))
+		// Convert * wildcard to the regex equivalent, quoting
+		// everything else. This is safe because * never appears
+		// in entity names.
+		quoted := regexp.QuoteMeta(entity)
+		patterns = append(patterns, strings.Replace(quoted, `\*`, ".*", -1))
 	}
 	return `^(` + strings.Join(patterns, "|") + `)$`
 }
@@ -669,6 +789,64 @@ func makeModulePattern(modules []string) string {
 	return `^(` + strings.Join(patterns, "|") + `)(\..+)?$`
 }
 
+// makeLocationPattern returns a pattern matching any of the given
+// locations. A location without a line number matches every line of
+// the source file.
+func makeLocationPattern(locations []string) string {
+	var patterns []string
+	for _, location := range locations {
+		patterns = append(patterns, regexp.QuoteMeta(location))
+	}
+	return `^(` + strings.Join(patterns, "|") + `)(:[0-9]+)?$`
+}
+
+const (
+	// MaxLogMessagePatternLength is the longest message pattern a log
+	// tailer accepts.
+	MaxLogMessagePatternLength = 256
+
+	// MaxLogMessagePatternWildcards is the most wildcards a message
+	// pattern may hold. The patterns are matched by the database, so
+	// this bounds the backtracking needed to match each message.
+	MaxLogMessagePatternWildcards = 4
+)
+
+// ValidateLogMessagePattern returns an error if the pattern can't be
+// used to filter log messages. An empty pattern is valid.
+func ValidateLogMessagePattern(pattern string) error {
+	if len(pattern) > MaxLogMessagePatternLength {
+		return errors.NotValidf("message pattern longer than %d characters", MaxLogMessagePatternLength)
+	}
+	if n := len(messagePatternParts(pattern)) - 1; n > MaxLogMessagePatternWildcards {
+		return errors.NotValidf("message pattern %q with more than %d wildcards", pattern, MaxLogMessagePatternWildcards)
+	}
+	return nil
+}
+
+// makeMessagePattern returns a regular expression matching messages
+// that contain the pattern. Only the "*" wildcard is special; every
+// other character is quoted.
+func makeMessagePattern(pattern string) string {
+	parts := messagePatternParts(pattern)
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return strings.Join(parts, ".*")
+}
+
+// messagePatternParts splits the pattern at its wildcards. Leading,
+// trailing and repeated wildcards are dropped because a pattern only
+// has to match part of a message.
+func messagePatternParts(pattern string) []string {
+	var parts []string
+	for _, part := range strings.Split(strings.Trim(pattern, "*"), "*") {
+		if part != "" || len(parts) == 0 {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
 func newRecentIdTracker(maxLen int) *recentIdTracker {
 	return &recentIdTracker{
 		ids: deque.NewWithMaxLen(maxLen),
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/juju/clock/testclock"
 	"github.com/juju/loggo"
 	jc "github.com/juju/testing/checkers"
 	"github.com/juju/version"
@@ -184,6 +185,45 @@ func (s *LogsSuite) TestDbLogger(c *gc.C) {
 	c.Assert(docs[1]["x"], gc.Equals, "oh noes")
 }
 
+func (s *LogsSuite) TestDbLoggerImportLogSkipsExisting(c *gc.C) {
+	logger := state.NewDbLogger(s.State)
+	defer logger.Close()
+
+	t0 := coretesting.ZeroTime().Truncate(time.Millisecond)
+	existing := state.LogRecord{
+		Time:     t0,
+		Entity:   names.NewMachineTag("45"),
+		Module:   "some.where",
+		Location: "foo.go:99",
+		Level:    loggo.INFO,
+		Message:  "all is well",
+	}
+	err := logger.Log([]state.LogRecord{existing})
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Same time, different message.
+	other := existing
+	other.Message = "all is not well"
+	// A new record, repeated within the batch.
+	later := existing
+	later.Time = t0.Add(time.Second)
+	err = logger.ImportLog([]state.LogRecord{existing, other, later, later})
+	c.Assert(err, jc.ErrorIsNil)
+
+	var docs []bson.M
+	err = s.logsColl.Find(nil).Sort("t", "x").All(&docs)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(docs, gc.HasLen, 3)
+	c.Check(docs[0]["x"], gc.Equals, "all is not well")
+	c.Check(docs[1]["x"], gc.Equals, "all is well")
+	c.Check(docs[2]["t"], gc.Equals, later.Time.UnixNano())
+
+	// Importing the same records again changes nothing.
+	err = logger.ImportLog([]state.LogRecord{existing, other, later})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.countLogs(c, s.State), gc.Equals, 3)
+}
+
 func (s *LogsSuite) TestPruneLogsByTime(c *gc.C) {
 	dbLogger := state.NewDbLogger(s.State)
 	defer dbLogger.Close()
@@ -785,6 +825,152 @@ func (s *LogTailerSuite) TestIncludeExcludeModule(c *gc.C) {
 	s.checkLogTailerFiltering(c, s.otherState, params, writeLogs, assert)
 }
 
+func (s *LogTailerSuite) TestIncludeExcludeLocation(c *gc.C) {
+	foo := logTemplate{Location: "foo.go:12"}
+	fooOther := logTemplate{Location: "foo.go:42"}
+	foobar := logTemplate{Location: "foobar.go:12"}
+	bar := logTemplate{Location: "bar.go:7"}
+	writeLogs := func() {
+		s.writeLogs(c, s.otherUUID, 1, foo)
+		s.writeLogs(c, s.otherUUID, 1, foobar)
+		s.writeLogs(c, s.otherUUID, 1, fooOther)
+		s.writeLogs(c, s.otherUUID, 1, bar)
+		s.writeLogs(c, s.otherUUID, 1, foo)
+	}
+	params := state.LogTailerParams{
+		IncludeLocation: []string{"foo.go", "bar.go"},
+		ExcludeLocation: []string{"foo.go:42", "bar.go:7"},
+	}
+	assert := func(tailer state.LogTailer) {
+		// Just "foo.go:12", as "foobar.go" isn't included and the
+		// other locations are excluded.
+		s.assertTailer(c, tailer, 2, foo)
+	}
+	s.checkLogTailerFiltering(c, s.otherState, params, writeLogs, assert)
+}
+
+func (s *LogTailerSuite) TestIncludeExcludeMessage(c *gc.C) {
+	started := logTemplate{Message: "started hook (install)"}
+	failed := logTemplate{Message: "started hook: failed.*"}
+	other := logTemplate{Message: "something else started"}
+	writeLogs := func() {
+		s.writeLogs(c, s.otherUUID, 1, started)
+		s.writeLogs(c, s.otherUUID, 1, failed)
+		s.writeLogs(c, s.otherUUID, 1, other)
+		s.writeLogs(c, s.otherUUID, 1, started)
+	}
+	params := state.LogTailerParams{
+		IncludeMessage: "started*hook",
+		ExcludeMessage: "failed.*",
+	}
+	assert := func(tailer state.LogTailer) {
+		s.assertTailer(c, tailer, 2, started)
+	}
+	s.checkLogTailerFiltering(c, s.otherState, params, writeLogs, assert)
+}
+
+func (s *LogTailerSuite) TestMessagePatternsAreNotRegexps(c *gc.C) {
+	parens := logTemplate{Message: "hook (install) failed"}
+	other := logTemplate{Message: "hook install failed"}
+	writeLogs := func() {
+		s.writeLogs(c, s.otherUUID, 1, other)
+		s.writeLogs(c, s.otherUUID, 1, parens)
+	}
+	params := state.LogTailerParams{
+		IncludeMessage: "(install)*fail",
+	}
+	assert := func(tailer state.LogTailer) {
+		s.assertTailer(c, tailer, 1, parens)
+	}
+	s.checkLogTailerFiltering(c, s.otherState, params, writeLogs, assert)
+}
+
+func (s *LogTailerSuite) TestBadMessagePattern(c *gc.C) {
+	_, err := state.NewLogTailer(s.otherState, state.LogTailerParams{
+		IncludeMessage: "a*b*c*d*e*f",
+	})
+	c.Assert(err, gc.ErrorMatches, `message pattern "a\*b\*c\*d\*e\*f" with more than 4 wildcards not valid`)
+	_, err = state.NewLogTailer(s.otherState, state.LogTailerParams{
+		ExcludeMessage: strings.Repeat("(x+)+", 100),
+	})
+	c.Assert(err, gc.ErrorMatches, `message pattern longer than 256 characters not valid`)
+}
+
+func (s *LogTailerSuite) TestEndTime(c *gc.C) {
+	threshT := coretesting.NonZeroTime()
+	want := logTemplate{Message: "want"}
+	s.writeLogsT(c, s.otherUUID, threshT.Add(-5*time.Second), threshT.Add(-time.Millisecond), 5, want)
+	s.writeLogsT(c,
+		s.otherUUID,
+		threshT, threshT.Add(5*time.Second), 5,
+		logTemplate{Message: "dont want"},
+	)
+
+	tailer, err := state.NewLogTailer(s.otherState, state.LogTailerParams{
+		EndTime: threshT,
+		Oplog:   s.oplogColl,
+		Clock:   testclock.NewClock(threshT.Add(time.Minute)),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	// Not strictly necessary, just in case EndTime doesn't work in the test.
+	defer tailer.Stop()
+
+	// Only the logs before the end time should be reported and,
+	// as the end time has passed, the tailer should stop itself
+	// once the log collection has been read.
+	s.assertTailer(c, tailer, 5, want)
+	select {
+	case _, ok := <-tailer.Logs():
+		if ok {
+			c.Fatal("shouldn't be any further logs")
+		}
+	case <-time.After(coretesting.LongWait):
+		c.Fatal("timed out waiting for logs channel to close")
+	}
+
+	select {
+	case <-tailer.Dying():
+		// Success.
+	case <-time.After(coretesting.LongWait):
+		c.Fatal("tailer didn't stop itself")
+	}
+}
+
+func (s *LogTailerSuite) TestEndTimeWhileTailing(c *gc.C) {
+	threshT := coretesting.NonZeroTime()
+	clock := testclock.NewClock(threshT.Add(-time.Minute))
+	tailer, err := state.NewLogTailer(s.otherState, state.LogTailerParams{
+		EndTime: threshT,
+		Oplog:   s.oplogColl,
+		Clock:   clock,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	defer tailer.Stop()
+
+	want := logTemplate{Message: "want"}
+	s.writeLogsT(c, s.otherUUID, threshT.Add(-5*time.Second), threshT.Add(-time.Millisecond), 5, want)
+	s.assertTailer(c, tailer, 5, want)
+
+	// Once the end time passes, the tailer stops.
+	err = clock.WaitAdvance(time.Minute, coretesting.LongWait, 1)
+	c.Assert(err, jc.ErrorIsNil)
+	select {
+	case _, ok := <-tailer.Logs():
+		if ok {
+			c.Fatal("shouldn't be any further logs")
+		}
+	case <-time.After(coretesting.LongWait):
+		c.Fatal("timed out waiting for logs channel to close")
+	}
+
+	select {
+	case <-tailer.Dying():
+		// Success.
+	case <-time.After(coretesting.LongWait):
+		c.Fatal("tailer didn't stop itself")
+	}
+}
+
 func (s *LogTailerSuite) checkLogTailerFiltering(
 	c *gc.C,
 	st *state.State,
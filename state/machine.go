@@ -2087,17 +2087,7 @@ func (m *Machine) VolumeAttachments() ([]VolumeAttachment, error) {
 
 // AddAction is part of the ActionReceiver interface.
 func (m *Machine) AddAction(name string, payload map[string]interface{}) (Action, error) {
-	spec, ok := actions.PredefinedActionsSpec[name]
-	if !ok {
-		return nil, errors.Errorf("cannot add action %q to a machine; only predefined actions allowed", name)
-	}
-
-	// Reject bad payloads before attempting to insert defaults.
-	err := spec.ValidateParams(payload)
-	if err != nil {
-		return nil, err
-	}
-	payloadWithDefaults, err := spec.InsertDefaults(payload)
+	payloadWithDefaults, err := m.PrepareActionPayload(name, payload)
 	if err != nil {
 		return nil, err
 	}
@@ -2110,6 +2100,21 @@ func (m *Machine) AddAction(name string, payload map[string]interface{}) (Action
 	return model.EnqueueAction(m.Tag(), name, payloadWithDefaults)
 }
 
+// PrepareActionPayload is part of the ActionReceiver interface.
+func (m *Machine) PrepareActionPayload(name string, payload map[string]interface{}) (map[string]interface{}, error) {
+	spec, ok := actions.PredefinedActionsSpec[name]
+	if !ok {
+		return nil, errors.Errorf("cannot add action %q to a machine; only predefined actions allowed", name)
+	}
+
+	// Reject bad payloads before attempting to insert defaults.
+	err := spec.ValidateParams(payload)
+	if err != nil {
+		return nil, err
+	}
+	return spec.InsertDefaults(payload)
+}
+
 // CancelAction is part of the ActionReceiver interface.
 func (m *Machine) CancelAction(action Action) (Action, error) {
 	return action.Finish(ActionResults{Status: ActionCancelled})
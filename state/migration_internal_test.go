@@ -215,6 +215,12 @@ func (s *MigrationSuite) TestKnownCollections(c *gc.C) {
 		// sure the leader units' leases are claimed in the target
 		// controller when leases are managed in raft.
 		leaseHoldersC,
+		// The model description can't carry action schedules or
+		// operations, so the migration prechecks refuse to migrate a
+		// model with schedules or running operations. Finished
+		// operations are history and are left behind.
+		actionSchedulesC,
+		operationsC,
 	)
 
 	modelCollections := set.NewStrings()
@@ -715,6 +721,13 @@ func (s *MigrationSuite) TestSSHHostKeyDocFields(c *gc.C) {
 func (s *MigrationSuite) TestActionDocFields(c *gc.C) {
 	ignored := set.NewStrings(
 		"ModelUUID",
+		// Operations aren't migrated, so the action's
+		// operation is dropped.
+		"Operation",
+		// The model description has nowhere to put progress
+		// messages, so they are left behind; the action's results
+		// and final message are migrated.
+		"Logs",
 	)
 	migrated := set.NewStrings(
 		"DocId",
@@ -4,10 +4,15 @@
 package state
 
 import (
+	"fmt"
 	"sort"
 	"strconv"
+	"time"
 
 	"github.com/juju/errors"
+	jujutxn "github.com/juju/txn"
+	"gopkg.in/juju/charm.v6"
+	"gopkg.in/juju/names.v2"
 	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
 	"gopkg.in/mgo.v2/txn"
@@ -39,9 +44,44 @@ type generationDoc struct {
 	// generation, but no units currently set to be in it.
 	AssignedUnits map[string][]string `bson:"assigned-units"`
 
+	// Config holds the charm config changes made in this generation,
+	// keyed by application name. A nil value indicates that the
+	// setting is reset to its default.
+	Config map[string]settingsMap `bson:"config"`
+
+	// Charms holds the charm upgrades made in this generation,
+	// keyed by application name.
+	Charms map[string]generationCharmDoc `bson:"charms"`
+
+	// Resources holds the pending IDs of resources attached in this
+	// generation, keyed by application name and then resource name.
+	Resources map[string]map[string]string `bson:"resources"`
+
+	// CharmModifiedVersion records, for each application, the number
+	// of charm or resource changes made in this generation. It is added
+	// to the application's CharmModifiedVersion for units assigned to
+	// the generation, so that they see the changes.
+	CharmModifiedVersion map[string]int `bson:"charm-modified-version"`
+
+	// Created is the Unix time at which the generation was added.
+	Created int64 `bson:"created"`
+
 	// Completed, if set, indicates when this generation was completed and
 	// effectively became the current model generation.
 	Completed int64 `bson:"completed"`
+
+	// Cancelled indicates that the generation was completed by
+	// cancelling it, rather than by every unit being advanced to it.
+	Cancelled bool `bson:"cancelled"`
+}
+
+// generationCharmDoc records a charm upgrade made in a model generation.
+type generationCharmDoc struct {
+	CharmURL    *charm.URL        `bson:"charmurl"`
+	Channel     string            `bson:"cs-channel"`
+	ForceUnits  bool              `bson:"forcecharm"`
+	Config      settingsMap       `bson:"config,omitempty"`
+	ResourceIDs map[string]string `bson:"resource-ids,omitempty"`
 }
 
 // Generation represents the state of a model generation.
@@ -71,16 +111,275 @@ func (g *Generation) AssignedUnits() map[string][]string {
 	return g.doc.AssignedUnits
 }
 
+// unitAssigned returns true if the unit with the input name
+// is assigned to this generation.
+func (g *Generation) unitAssigned(appName, unitName string) bool {
+	for _, u := range g.doc.AssignedUnits[appName] {
+		if u == unitName {
+			return true
+		}
+	}
+	return false
+}
+
+// Config returns the charm config changes made in this generation,
+// keyed by application name.
+func (g *Generation) Config() map[string]charm.Settings {
+	result := make(map[string]charm.Settings, len(g.doc.Config))
+	for app, settings := range g.doc.Config {
+		result[app] = charm.Settings(settings)
+	}
+	return result
+}
+
+// CharmURL returns the URL of the charm that the application with the
+// input name was upgraded to in this generation, and false if its charm
+// was not changed.
+func (g *Generation) CharmURL(appName string) (*charm.URL, bool) {
+	doc, ok := g.doc.Charms[appName]
+	if !ok {
+		return nil, false
+	}
+	return doc.CharmURL, true
+}
+
+// Resources returns the pending IDs of the resources attached to the
+// application with the input name in this generation, keyed by
+// resource name.
+func (g *Generation) Resources(appName string) map[string]string {
+	return g.doc.Resources[appName]
+}
+
+// Created returns the time at which the generation was added.
+func (g *Generation) Created() time.Time {
+	return time.Unix(g.doc.Created, 0).UTC()
+}
+
+// Completed returns the time at which the generation was completed,
+// and false if it has not been.
+func (g *Generation) Completed() (time.Time, bool) {
+	if g.doc.Completed == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(g.doc.Completed, 0).UTC(), true
+}
+
+// Cancelled indicates whether the generation was completed by
+// cancelling it.
+func (g *Generation) Cancelled() bool {
+	return g.doc.Cancelled
+}
+
 // AssignApplication indicates that the application with the input name has had
 // changes in this generation.
 func (g *Generation) AssignApplication(appName string) error {
-	return errors.NotImplementedf("AssignApplication")
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := g.Refresh(); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		if err := g.checkNotCompleted(); err != nil {
+			return nil, errors.Trace(err)
+		}
+		if _, ok := g.doc.AssignedUnits[appName]; ok {
+			return nil, jujutxn.ErrNoOperations
+		}
+		return assignGenerationAppTxnOps(g.doc.Id, appName), nil
+	}
+	return errors.Trace(g.st.db().Run(buildTxn))
+}
+
+func assignGenerationAppTxnOps(id, appName string) []txn.Op {
+	appField := fmt.Sprintf("assigned-units.%s", appName)
+
+	return []txn.Op{
+		{
+			C:  generationsC,
+			Id: id,
+			Assert: bson.D{
+				{"completed", 0},
+				{appField, bson.D{{"$exists", false}}},
+			},
+			Update: bson.D{
+				{"$set", bson.D{{appField, []string{}}}},
+			},
+		},
+	}
 }
 
 // AssignUnit indicates that the unit with the input name has had been added
 // to this generation and should realise config changes applied to it.
 func (g *Generation) AssignUnit(unitName string) error {
-	return errors.NotImplementedf("AssignApplication")
+	appName, err := names.UnitApplication(unitName)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := g.Refresh(); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		if err := g.checkNotCompleted(); err != nil {
+			return nil, errors.Trace(err)
+		}
+		if g.unitAssigned(appName, unitName) {
+			return nil, jujutxn.ErrNoOperations
+		}
+		if _, err := g.st.Unit(unitName); err != nil {
+			return nil, errors.Trace(err)
+		}
+		ops := assignGenerationUnitTxnOps(g.doc.Id, appName, unitName)
+		if g.doc.CharmModifiedVersion[appName] > 0 {
+			// The unit now needs to see the charm or resources
+			// changed in the generation.
+			ops = append(ops, touchApplicationOp(appName))
+		}
+		return ops, nil
+	}
+	return errors.Trace(g.st.db().Run(buildTxn))
+}
+
+func assignGenerationUnitTxnOps(id, appName, unitName string) []txn.Op {
+	appField := fmt.Sprintf("assigned-units.%s", appName)
+
+	return []txn.Op{
+		{
+			C:  generationsC,
+			Id: id,
+			Assert: bson.D{
+				{"completed", 0},
+				{appField, bson.D{{"$ne", unitName}}},
+			},
+			Update: bson.D{
+				{"$push", bson.D{{appField, unitName}}},
+			},
+		},
+	}
+}
+
+// UpdateCharmConfig records charm config changes for the input application
+// in this generation. The changes are realised by units assigned to the
+// generation, and by the whole application once the generation completes.
+// The application is assigned to the generation if it isn't already.
+func (g *Generation) UpdateCharmConfig(appName string, changes charm.Settings) error {
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := g.Refresh(); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		if err := g.checkNotCompleted(); err != nil {
+			return nil, errors.Trace(err)
+		}
+		merged := make(settingsMap)
+		for k, v := range g.doc.Config[appName] {
+			merged[k] = v
+		}
+		for k, v := range changes {
+			merged[k] = v
+		}
+
+		configField := fmt.Sprintf("config.%s", appName)
+		update := bson.D{{configField, merged}}
+		if _, ok := g.doc.AssignedUnits[appName]; !ok {
+			update = append(update, bson.DocElem{fmt.Sprintf("assigned-units.%s", appName), []string{}})
+		}
+		return []txn.Op{{
+			C:      generationsC,
+			Id:     g.doc.Id,
+			Assert: bson.D{{"completed", 0}},
+			Update: bson.D{{"$set", update}},
+		}}, nil
+	}
+	return errors.Trace(g.st.db().Run(buildTxn))
+}
+
+// SetCharm records an upgrade of the input application's charm in this
+// generation. Units assigned to the generation are upgraded to the charm,
+// and the whole application is upgraded once the generation completes.
+// The application is assigned to the generation if it isn't already.
+func (g *Generation) SetCharm(appName string, cfg SetCharmConfig) error {
+	doc := generationCharmDoc{
+		CharmURL:    cfg.Charm.URL(),
+		Channel:     string(cfg.Channel),
+		ForceUnits:  cfg.ForceUnits,
+		ResourceIDs: cfg.ResourceIDs,
+	}
+	if len(cfg.ConfigSettings) > 0 {
+		doc.Config = settingsMap(cfg.ConfigSettings)
+	}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := g.Refresh(); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		if err := g.checkNotCompleted(); err != nil {
+			return nil, errors.Trace(err)
+		}
+		update := bson.D{{fmt.Sprintf("charms.%s", appName), doc}}
+		return g.charmChangeTxnOps(appName, update), nil
+	}
+	return errors.Trace(g.st.db().Run(buildTxn))
+}
+
+// SetResource records that the resource with the input name was attached
+// to the application in this generation, as the pending resource with the
+// input ID. Units assigned to the generation use the pending resource,
+// and it is made the application's resource once the generation completes.
+// The application is assigned to the generation if it isn't already.
+func (g *Generation) SetResource(appName, resName, pendingID string) error {
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := g.Refresh(); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		if err := g.checkNotCompleted(); err != nil {
+			return nil, errors.Trace(err)
+		}
+		update := bson.D{{fmt.Sprintf("resources.%s.%s", appName, resName), pendingID}}
+		return g.charmChangeTxnOps(appName, update), nil
+	}
+	return errors.Trace(g.st.db().Run(buildTxn))
+}
+
+// charmChangeTxnOps returns the operations that apply the input update
+// to the generation, recording a change to the application's charm or
+// resources that units assigned to the generation need to see.
+func (g *Generation) charmChangeTxnOps(appName string, update bson.D) []txn.Op {
+	if _, ok := g.doc.AssignedUnits[appName]; !ok {
+		update = append(update, bson.DocElem{fmt.Sprintf("assigned-units.%s", appName), []string{}})
+	}
+	ops := []txn.Op{{
+		C:      generationsC,
+		Id:     g.doc.Id,
+		Assert: bson.D{{"completed", 0}},
+		Update: bson.D{
+			{"$set", update},
+			{"$inc", bson.D{{fmt.Sprintf("charm-modified-version.%s", appName), 1}}},
+		},
+	}}
+	if len(g.doc.AssignedUnits[appName]) > 0 {
+		ops = append(ops, touchApplicationOp(appName))
+	}
+	return ops
+}
+
+// touchApplicationOp returns an operation that changes nothing but the
+// revision of the application's document. This causes the application's
+// watchers, notably those of its units' uniters, to re-read its charm
+// details, which may differ for units assigned to a generation.
+func touchApplicationOp(appName string) txn.Op {
+	return txn.Op{
+		C:      applicationsC,
+		Id:     appName,
+		Assert: txn.DocExists,
+		Update: bson.D{{"$set", bson.D{{"name", appName}}}},
+	}
 }
 
 // CanAutoComplete returns true if every application that has had configuration
@@ -135,6 +434,219 @@ func (g *Generation) canComplete(allowEmpty bool) (bool, error) {
 	return true, nil
 }
 
+// AutoComplete completes the generation if every application with
+// changes in it has had all of its units assigned, making the
+// generation's config changes current. It returns true if the
+// generation was completed.
+func (g *Generation) AutoComplete() (bool, error) {
+	completed := false
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := g.Refresh(); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		if err := g.checkNotCompleted(); err != nil {
+			return nil, errors.Trace(err)
+		}
+		can, err := g.CanAutoComplete()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if !can {
+			completed = false
+			return nil, jujutxn.ErrNoOperations
+		}
+		completed = true
+		return g.completeTxnOps(false)
+	}
+	if err := g.st.db().Run(buildTxn); err != nil {
+		return false, errors.Trace(err)
+	}
+	return completed, nil
+}
+
+// Cancel completes the generation without waiting for every unit to be
+// advanced to it. This is only allowed if every application with changes
+// in the generation has either all or none of its units assigned.
+// Config changes for applications with all units assigned become current;
+// those for applications with no units assigned are discarded.
+func (g *Generation) Cancel() error {
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := g.Refresh(); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		if err := g.checkNotCompleted(); err != nil {
+			return nil, errors.Trace(err)
+		}
+		can, err := g.CanCancel()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if !can {
+			return nil, errors.New("cannot cancel generation, there are units behind a generation")
+		}
+		ops, err := g.completeTxnOps(true)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		// The pending resources for applications with no units assigned
+		// are removed along with the cancellation, so none are left
+		// behind if it fails part way.
+		resources := NewResourcePersistence(g.st.newPersistence())
+		for appName, pendingIDs := range g.discardedResources() {
+			resOps, err := resources.NewRemovePendingAppResourcesOps(appName, pendingIDs)
+			if err != nil {
+				return nil, errors.Annotatef(err, "removing resources discarded from application %q", appName)
+			}
+			ops = append(ops, resOps...)
+		}
+		return ops, nil
+	}
+	return errors.Trace(g.st.db().Run(buildTxn))
+}
+
+// completeTxnOps returns the operations required to mark the generation
+// as completed, applying the config changes, charm upgrades and resources
+// for each application that has units assigned to the generation.
+func (g *Generation) completeTxnOps(cancelled bool) ([]txn.Op, error) {
+	var ops []txn.Op
+	for appName := range g.doc.AssignedUnits {
+		if len(g.doc.AssignedUnits[appName]) == 0 {
+			continue
+		}
+		appOps, err := g.completeApplicationTxnOps(appName)
+		if err != nil {
+			return nil, errors.Annotatef(err, "application %q", appName)
+		}
+		ops = append(ops, appOps...)
+	}
+
+	now := g.st.clock().Now().Unix()
+	ops = append(ops, txn.Op{
+		C:      generationsC,
+		Id:     g.doc.Id,
+		Assert: bson.D{{"completed", 0}},
+		Update: bson.D{{"$set", bson.D{
+			{"completed", now},
+			{"cancelled", cancelled},
+			{"active", false},
+		}}},
+	})
+	return ops, nil
+}
+
+// completeApplicationTxnOps returns the operations that make the changes
+// to the input application in this generation current.
+func (g *Generation) completeApplicationTxnOps(appName string) ([]txn.Op, error) {
+	app, err := g.st.Application(appName)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	changes := g.doc.Config[appName]
+
+	var ops []txn.Op
+	if charmDoc, ok := g.doc.Charms[appName]; ok {
+		ch, err := g.st.Charm(charmDoc.CharmURL)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		// Config changes made in the generation are applied on
+		// top of any made as part of the upgrade.
+		settings := make(charm.Settings)
+		for name, value := range charmDoc.Config {
+			settings[name] = value
+		}
+		for name, value := range changes {
+			settings[name] = value
+		}
+		charmOps, err := app.changeCharmOps(
+			ch,
+			charmDoc.Channel,
+			settings,
+			charmDoc.ForceUnits,
+			charmDoc.ResourceIDs,
+			nil,
+		)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ops = append(ops, charmOps...)
+	} else if len(changes) > 0 {
+		node, err := readSettings(app.st.db(), settingsC, app.charmConfigKey())
+		if err != nil {
+			return nil, errors.Annotate(err, "charm config")
+		}
+		for name, value := range changes {
+			if value == nil {
+				node.Delete(name)
+			} else {
+				node.Set(name, value)
+			}
+		}
+		_, settingsOps := node.settingsUpdateOps()
+		ops = append(ops, settingsOps...)
+	}
+
+	if pendingIDs := g.doc.Resources[appName]; len(pendingIDs) > 0 {
+		resources, err := g.st.Resources()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		resOps, err := resources.NewResolvePendingResourcesOps(appName, pendingIDs)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ops = append(ops, resOps...)
+		if _, ok := g.doc.Charms[appName]; !ok {
+			// Upgrading the charm already increments the version.
+			ops = append(ops, incCharmModifiedVersionOps(appName)...)
+		}
+	}
+	return ops, nil
+}
+
+// discardedResources returns the pending IDs of resources attached in
+// this generation to applications with no units assigned to it, keyed
+// by application name. These are discarded when the generation is
+// cancelled.
+func (g *Generation) discardedResources() map[string]map[string]string {
+	result := make(map[string]map[string]string)
+	for appName, pendingIDs := range g.doc.Resources {
+		if len(g.doc.AssignedUnits[appName]) == 0 && len(pendingIDs) > 0 {
+			result[appName] = pendingIDs
+		}
+	}
+	return result
+}
+
+// checkNotCompleted returns an error if the generation has already
+// been completed.
+func (g *Generation) checkNotCompleted() error {
+	if g.doc.Completed > 0 {
+		return errors.New("generation has been completed")
+	}
+	return nil
+}
+
+// Refresh refreshes the contents of the generation from the underlying state.
+func (g *Generation) Refresh() error {
+	col, closer := g.st.db().GetCollection(generationsC)
+	defer closer()
+
+	var doc generationDoc
+	if err := col.FindId(g.doc.Id).One(&doc); err != nil {
+		if err == mgo.ErrNotFound {
+			return errors.NotFoundf("generation %q", g.doc.Id)
+		}
+		return errors.Annotatef(err, "refreshing generation %q", g.doc.Id)
+	}
+	g.doc = doc
+	return nil
+}
+
 func appUnitNames(st *State, appId string) ([]string, error) {
 	unitsCollection, closer := st.db().GetCollection(unitsC)
 	defer closer()
@@ -177,7 +689,7 @@ func (st *State) AddGeneration() error {
 			return nil, errors.Errorf("model has a next generation that is not completed")
 		}
 
-		return insertGenerationTxnOps(strconv.Itoa(seq)), nil
+		return insertGenerationTxnOps(strconv.Itoa(seq), st.clock().Now()), nil
 	}
 	err = st.db().Run(buildTxn)
 	if err != nil {
@@ -187,11 +699,16 @@ func (st *State) AddGeneration() error {
 	return err
 }
 
-func insertGenerationTxnOps(id string) []txn.Op {
+func insertGenerationTxnOps(id string, now time.Time) []txn.Op {
 	doc := &generationDoc{
-		Id:            id,
-		Active:        true,
-		AssignedUnits: map[string][]string{},
+		Id:                   id,
+		Active:               true,
+		AssignedUnits:        map[string][]string{},
+		Config:               map[string]settingsMap{},
+		Charms:               map[string]generationCharmDoc{},
+		Resources:            map[string]map[string]string{},
+		CharmModifiedVersion: map[string]int{},
+		Created:              now.Unix(),
 	}
 
 	return []txn.Op{
@@ -310,6 +827,44 @@ func (st *State) getNextGenerationDoc() (*generationDoc, error) {
 	}
 }
 
+// WatchGenerations returns a NotifyWatcher that triggers whenever a
+// generation of the model is added or changed.
+func (st *State) WatchGenerations() NotifyWatcher {
+	return newNotifyCollWatcher(st, generationsC, isLocalID(st))
+}
+
+// Generations returns all generations for the model, completed or not,
+// in the order they were added.
+func (m *Model) Generations() ([]*Generation, error) {
+	gens, err := m.st.Generations()
+	return gens, errors.Trace(err)
+}
+
+// Generations returns all generations for the current model, completed
+// or not, in the order they were added.
+func (st *State) Generations() ([]*Generation, error) {
+	col, closer := st.db().GetCollection(generationsC)
+	defer closer()
+
+	var docs []generationDoc
+	if err := col.Find(nil).All(&docs); err != nil {
+		return nil, errors.Annotatef(err, "retrieving generations")
+	}
+	sort.Slice(docs, func(i, j int) bool {
+		// Generation IDs come from a sequence, so compare them
+		// numerically.
+		a, _ := strconv.Atoi(docs[i].Id)
+		b, _ := strconv.Atoi(docs[j].Id)
+		return a < b
+	})
+
+	gens := make([]*Generation, len(docs))
+	for i := range docs {
+		gens[i] = newGeneration(st, &docs[i])
+	}
+	return gens, nil
+}
+
 func newGeneration(st *State, doc *generationDoc) *Generation {
 	return &Generation{
 		st:  st,
@@ -4,10 +4,17 @@
 package state_test
 
 import (
+	"bytes"
+	"io/ioutil"
+
 	"github.com/juju/errors"
-	"github.com/juju/juju/core/model"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/charm.v6"
+
+	"github.com/juju/juju/core/model"
+	"github.com/juju/juju/resource/resourcetesting"
+	"github.com/juju/juju/state"
 )
 
 type generationSuite struct {
@@ -80,9 +87,402 @@ func (s *generationSuite) TestCanAutoCompleteAndCanCancel(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 	c.Check(auto, jc.IsFalse)
 
-	// TODO (manadart 2018-12-07) Implement AddApplication and AddUnit.
-	// Check CanCancel and CanAutoComplete with:
-	// - 2 apps, all units from one and none from the other.
-	// - 2 apps, all units from one and some from the other.
-	// - 2 apps, all units from both.
+}
+
+func (s *generationSuite) setupAssignAllUnits(c *gc.C) *state.Generation {
+	ch := s.AddTestingCharm(c, "dummy")
+	for _, name := range []string{"riak", "redis"} {
+		app := s.AddTestingApplication(c, name, ch)
+		for i := 0; i < 2; i++ {
+			_, err := app.AddUnit(state.AddUnitParams{})
+			c.Assert(err, jc.ErrorIsNil)
+		}
+	}
+
+	c.Assert(s.Model.AddGeneration(), jc.ErrorIsNil)
+	gen, err := s.Model.NextGeneration()
+	c.Assert(err, jc.ErrorIsNil)
+	return gen
+}
+
+func (s *generationSuite) TestCanAutoCompleteAndCanCancelOneAppAllUnits(c *gc.C) {
+	gen := s.setupAssignAllUnits(c)
+
+	c.Assert(gen.AssignApplication("riak"), jc.ErrorIsNil)
+	c.Assert(gen.AssignApplication("redis"), jc.ErrorIsNil)
+	c.Assert(gen.AssignUnit("riak/0"), jc.ErrorIsNil)
+	c.Assert(gen.AssignUnit("riak/1"), jc.ErrorIsNil)
+	c.Assert(gen.Refresh(), jc.ErrorIsNil)
+
+	c.Check(gen.AssignedUnits(), jc.DeepEquals, map[string][]string{
+		"riak":  {"riak/0", "riak/1"},
+		"redis": {},
+	})
+
+	comp, err := gen.CanCancel()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(comp, jc.IsTrue)
+
+	auto, err := gen.CanAutoComplete()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(auto, jc.IsFalse)
+}
+
+func (s *generationSuite) TestCanAutoCompleteAndCanCancelOneAppSomeUnits(c *gc.C) {
+	gen := s.setupAssignAllUnits(c)
+
+	c.Assert(gen.AssignUnit("riak/0"), jc.ErrorIsNil)
+	c.Assert(gen.AssignUnit("riak/1"), jc.ErrorIsNil)
+	c.Assert(gen.AssignUnit("redis/0"), jc.ErrorIsNil)
+	c.Assert(gen.Refresh(), jc.ErrorIsNil)
+
+	comp, err := gen.CanCancel()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(comp, jc.IsFalse)
+
+	auto, err := gen.CanAutoComplete()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(auto, jc.IsFalse)
+}
+
+func (s *generationSuite) TestCanAutoCompleteAndCanCancelAllAppsAllUnits(c *gc.C) {
+	gen := s.setupAssignAllUnits(c)
+
+	for _, u := range []string{"riak/0", "riak/1", "redis/0", "redis/1"} {
+		c.Assert(gen.AssignUnit(u), jc.ErrorIsNil)
+	}
+	c.Assert(gen.Refresh(), jc.ErrorIsNil)
+
+	comp, err := gen.CanCancel()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(comp, jc.IsTrue)
+
+	auto, err := gen.CanAutoComplete()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(auto, jc.IsTrue)
+}
+
+func (s *generationSuite) TestAssignUnitIdempotent(c *gc.C) {
+	gen := s.setupAssignAllUnits(c)
+
+	c.Assert(gen.AssignUnit("riak/0"), jc.ErrorIsNil)
+	c.Assert(gen.AssignUnit("riak/0"), jc.ErrorIsNil)
+	c.Assert(gen.Refresh(), jc.ErrorIsNil)
+	c.Check(gen.AssignedUnits(), jc.DeepEquals, map[string][]string{
+		"riak": {"riak/0"},
+	})
+}
+
+func (s *generationSuite) TestAssignUnitNotFound(c *gc.C) {
+	gen := s.setupAssignAllUnits(c)
+
+	err := gen.AssignUnit("riak/42")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *generationSuite) TestCharmConfigInNextGeneration(c *gc.C) {
+	gen := s.setupAssignAllUnits(c)
+
+	app, err := s.State.Application("riak")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(app.UpdateCharmConfig(charm.Settings{"title": "next title"}), jc.ErrorIsNil)
+
+	// The application config is unchanged...
+	cfg, err := app.CharmConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(cfg["title"], gc.Equals, "My Title")
+
+	// ...the change is recorded in the generation...
+	c.Assert(gen.Refresh(), jc.ErrorIsNil)
+	c.Check(gen.Config(), jc.DeepEquals, map[string]charm.Settings{
+		"riak": {"title": "next title"},
+	})
+	c.Check(gen.AssignedUnits(), jc.DeepEquals, map[string][]string{
+		"riak": {},
+	})
+
+	// ...and only seen by units assigned to the generation.
+	c.Assert(gen.AssignUnit("riak/0"), jc.ErrorIsNil)
+	ch, _, err := app.Charm()
+	c.Assert(err, jc.ErrorIsNil)
+	for _, name := range []string{"riak/0", "riak/1"} {
+		unit, err := s.State.Unit(name)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(unit.SetCharmURL(ch.URL()), jc.ErrorIsNil)
+	}
+	s.assertUnitTitle(c, "riak/0", "next title")
+	s.assertUnitTitle(c, "riak/1", "My Title")
+}
+
+func (s *generationSuite) assertUnitTitle(c *gc.C, unitName, title string) {
+	unit, err := s.State.Unit(unitName)
+	c.Assert(err, jc.ErrorIsNil)
+	cfg, err := unit.ConfigSettings()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(cfg["title"], gc.Equals, title)
+}
+
+func (s *generationSuite) TestCharmConfigWithCurrentGenerationActive(c *gc.C) {
+	s.setupAssignAllUnits(c)
+	c.Assert(s.Model.SwitchGeneration(model.GenerationCurrent), jc.ErrorIsNil)
+
+	app, err := s.State.Application("riak")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(app.UpdateCharmConfig(charm.Settings{"title": "current title"}), jc.ErrorIsNil)
+
+	cfg, err := app.CharmConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(cfg["title"], gc.Equals, "current title")
+}
+
+func (s *generationSuite) TestAutoComplete(c *gc.C) {
+	gen := s.setupAssignAllUnits(c)
+
+	app, err := s.State.Application("riak")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(app.UpdateCharmConfig(charm.Settings{"title": "next title"}), jc.ErrorIsNil)
+
+	c.Assert(gen.AssignUnit("riak/0"), jc.ErrorIsNil)
+	completed, err := gen.AutoComplete()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(completed, jc.IsFalse)
+
+	c.Assert(gen.AssignUnit("riak/1"), jc.ErrorIsNil)
+	completed, err = gen.AutoComplete()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(completed, jc.IsTrue)
+
+	cfg, err := app.CharmConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(cfg["title"], gc.Equals, "next title")
+
+	_, err = s.Model.NextGeneration()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	v, err := s.Model.ActiveGeneration()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(v, gc.Equals, model.GenerationCurrent)
+
+	c.Assert(gen.Refresh(), jc.ErrorIsNil)
+	_, ok := gen.Completed()
+	c.Check(ok, jc.IsTrue)
+	c.Check(gen.Cancelled(), jc.IsFalse)
+}
+
+func (s *generationSuite) TestCancelDiscardsUnadvancedChanges(c *gc.C) {
+	gen := s.setupAssignAllUnits(c)
+
+	app, err := s.State.Application("riak")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(app.UpdateCharmConfig(charm.Settings{"title": "next title"}), jc.ErrorIsNil)
+
+	c.Assert(gen.Cancel(), jc.ErrorIsNil)
+
+	cfg, err := app.CharmConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(cfg["title"], gc.Equals, "My Title")
+
+	c.Assert(gen.Refresh(), jc.ErrorIsNil)
+	_, ok := gen.Completed()
+	c.Check(ok, jc.IsTrue)
+	c.Check(gen.Cancelled(), jc.IsTrue)
+
+	err = gen.AssignUnit("riak/0")
+	c.Assert(err, gc.ErrorMatches, "generation has been completed")
+}
+
+func (s *generationSuite) TestCancelWithUnitsBehind(c *gc.C) {
+	gen := s.setupAssignAllUnits(c)
+
+	c.Assert(gen.AssignUnit("riak/0"), jc.ErrorIsNil)
+	err := gen.Cancel()
+	c.Assert(err, gc.ErrorMatches, "cannot cancel generation, there are units behind a generation")
+}
+
+func (s *generationSuite) TestSetCharmInNextGeneration(c *gc.C) {
+	gen := s.setupAssignAllUnits(c)
+
+	app, err := s.State.Application("riak")
+	c.Assert(err, jc.ErrorIsNil)
+	oldURL, _ := app.CharmURL()
+	version := app.CharmModifiedVersion()
+	newCh := state.AddCustomCharm(c, s.State, "dummy", "", "", "quantal", 2)
+	c.Assert(app.SetCharm(state.SetCharmConfig{Charm: newCh}), jc.ErrorIsNil)
+
+	// The application's charm is unchanged...
+	c.Assert(app.Refresh(), jc.ErrorIsNil)
+	curl, _ := app.CharmURL()
+	c.Check(curl, gc.DeepEquals, oldURL)
+	c.Check(app.CharmModifiedVersion(), gc.Equals, version)
+
+	// ...the upgrade is recorded in the generation...
+	c.Assert(gen.Refresh(), jc.ErrorIsNil)
+	genURL, ok := gen.CharmURL("riak")
+	c.Assert(ok, jc.IsTrue)
+	c.Check(genURL, gc.DeepEquals, newCh.URL())
+	c.Check(gen.AssignedUnits(), jc.DeepEquals, map[string][]string{
+		"riak": {},
+	})
+
+	// ...and only seen by units assigned to the generation.
+	c.Assert(gen.AssignUnit("riak/0"), jc.ErrorIsNil)
+	s.assertUnitTargetCharm(c, "riak/0", newCh.URL(), version+1)
+	s.assertUnitTargetCharm(c, "riak/1", oldURL, version)
+
+	// The assigned unit can be upgraded to the generation's charm
+	// before the application is.
+	unit, err := s.State.Unit("riak/0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(unit.SetCharmURL(newCh.URL()), jc.ErrorIsNil)
+	s.assertUnitTitle(c, "riak/0", "My Title")
+}
+
+func (s *generationSuite) assertUnitTargetCharm(c *gc.C, unitName string, curl *charm.URL, version int) {
+	unit, err := s.State.Unit(unitName)
+	c.Assert(err, jc.ErrorIsNil)
+	target, _, err := unit.TargetCharmURL()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(target, gc.DeepEquals, curl)
+	targetVersion, err := unit.TargetCharmModifiedVersion()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(targetVersion, gc.Equals, version)
+}
+
+func (s *generationSuite) TestAutoCompleteUpgradesCharm(c *gc.C) {
+	gen := s.setupAssignAllUnits(c)
+
+	app, err := s.State.Application("riak")
+	c.Assert(err, jc.ErrorIsNil)
+	version := app.CharmModifiedVersion()
+	newCh := state.AddCustomCharm(c, s.State, "dummy", "", "", "quantal", 2)
+	c.Assert(app.SetCharm(state.SetCharmConfig{Charm: newCh}), jc.ErrorIsNil)
+	c.Assert(app.UpdateCharmConfig(charm.Settings{"title": "next title"}), jc.ErrorIsNil)
+
+	c.Assert(gen.AssignUnit("riak/0"), jc.ErrorIsNil)
+	unit, err := s.State.Unit("riak/0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(unit.SetCharmURL(newCh.URL()), jc.ErrorIsNil)
+
+	c.Assert(gen.AssignUnit("riak/1"), jc.ErrorIsNil)
+	completed, err := gen.AutoComplete()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(completed, jc.IsTrue)
+
+	c.Assert(app.Refresh(), jc.ErrorIsNil)
+	curl, _ := app.CharmURL()
+	c.Check(curl, gc.DeepEquals, newCh.URL())
+	c.Check(app.CharmModifiedVersion(), gc.Equals, version+1)
+	cfg, err := app.CharmConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(cfg["title"], gc.Equals, "next title")
+}
+
+func (s *generationSuite) TestCancelDiscardsUnadvancedCharm(c *gc.C) {
+	gen := s.setupAssignAllUnits(c)
+
+	app, err := s.State.Application("riak")
+	c.Assert(err, jc.ErrorIsNil)
+	oldURL, _ := app.CharmURL()
+	newCh := state.AddCustomCharm(c, s.State, "dummy", "", "", "quantal", 2)
+	c.Assert(app.SetCharm(state.SetCharmConfig{Charm: newCh}), jc.ErrorIsNil)
+
+	c.Assert(gen.Cancel(), jc.ErrorIsNil)
+
+	c.Assert(app.Refresh(), jc.ErrorIsNil)
+	curl, _ := app.CharmURL()
+	c.Check(curl, gc.DeepEquals, oldURL)
+}
+
+func (s *generationSuite) TestCancelDiscardsUnadvancedResource(c *gc.C) {
+	gen := s.setupAssignAllUnits(c)
+
+	resources, err := s.State.Resources()
+	c.Assert(err, jc.ErrorIsNil)
+	data := "spamspamspam"
+	res := resourcetesting.NewResource(c, nil, "spam", "riak", data).Resource
+	_, err = resources.SetResource("riak", res.Username, res.Resource, bytes.NewBufferString(data))
+	c.Assert(err, jc.ErrorIsNil)
+	pending, err := resources.ListPendingResources("riak")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pending, gc.HasLen, 1)
+
+	// The pending resource is removed by the cancellation.
+	c.Assert(gen.Cancel(), jc.ErrorIsNil)
+	pending, err = resources.ListPendingResources("riak")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(pending, gc.HasLen, 0)
+	appResources, err := resources.ListResources("riak")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(appResources.Resources, gc.HasLen, 0)
+}
+
+func (s *generationSuite) TestSetResourceInNextGeneration(c *gc.C) {
+	gen := s.setupAssignAllUnits(c)
+
+	app, err := s.State.Application("riak")
+	c.Assert(err, jc.ErrorIsNil)
+	version := app.CharmModifiedVersion()
+	resources, err := s.State.Resources()
+	c.Assert(err, jc.ErrorIsNil)
+
+	data := "spamspamspam"
+	res := resourcetesting.NewResource(c, nil, "spam", "riak", data).Resource
+	_, err = resources.SetResource("riak", res.Username, res.Resource, bytes.NewBufferString(data))
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The application's resource is unchanged...
+	appResources, err := resources.ListResources("riak")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(appResources.Resources, gc.HasLen, 0)
+
+	// ...the resource is recorded in the generation...
+	c.Assert(gen.Refresh(), jc.ErrorIsNil)
+	c.Check(gen.Resources("riak"), gc.HasLen, 1)
+
+	// ...and only seen by units assigned to the generation.
+	c.Assert(gen.AssignUnit("riak/0"), jc.ErrorIsNil)
+	curl, _ := app.CharmURL()
+	s.assertUnitTargetCharm(c, "riak/0", curl, version+1)
+	s.assertUnitTargetCharm(c, "riak/1", curl, version)
+
+	unit, err := s.State.Unit("riak/0")
+	c.Assert(err, jc.ErrorIsNil)
+	_, reader, err := resources.OpenResourceForUniter(unit, "spam")
+	c.Assert(err, jc.ErrorIsNil)
+	content, err := ioutil.ReadAll(reader)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(reader.Close(), jc.ErrorIsNil)
+	c.Check(string(content), gc.Equals, data)
+
+	unit, err = s.State.Unit("riak/1")
+	c.Assert(err, jc.ErrorIsNil)
+	_, _, err = resources.OpenResourceForUniter(unit, "spam")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	// Completing the generation makes the resource the application's.
+	c.Assert(gen.AssignUnit("riak/1"), jc.ErrorIsNil)
+	completed, err := gen.AutoComplete()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(completed, jc.IsTrue)
+
+	appResources, err = resources.ListResources("riak")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(appResources.Resources, gc.HasLen, 1)
+	c.Check(appResources.Resources[0].Name, gc.Equals, "spam")
+	c.Assert(app.Refresh(), jc.ErrorIsNil)
+	c.Check(app.CharmModifiedVersion(), gc.Equals, version+1)
+}
+
+func (s *generationSuite) TestGenerations(c *gc.C) {
+	gen := s.setupAssignAllUnits(c)
+	c.Assert(gen.Cancel(), jc.ErrorIsNil)
+	c.Assert(s.Model.AddGeneration(), jc.ErrorIsNil)
+
+	gens, err := s.Model.Generations()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(gens, gc.HasLen, 2)
+	c.Check(gens[0].Id(), gc.Equals, gen.Id())
+	c.Check(gens[0].Cancelled(), jc.IsTrue)
+	_, ok := gens[1].Completed()
+	c.Check(ok, jc.IsFalse)
+	c.Check(gens[1].Active(), jc.IsTrue)
 }
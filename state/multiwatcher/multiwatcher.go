@@ -273,6 +273,7 @@ type UnitInfo struct {
 	Ports          []Port      `json:"ports"`
 	PortRanges     []PortRange `json:"port-ranges"`
 	Subordinate    bool        `json:"subordinate"`
+	Life           Life        `json:"life"`
 	// Workload and agent state are modelled separately.
 	WorkloadStatus StatusInfo `json:"workload-status"`
 	AgentStatus    StatusInfo `json:"agent-status"`
@@ -0,0 +1,482 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/juju/errors"
+	jujutxn "github.com/juju/txn"
+	"gopkg.in/juju/names.v2"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// OperationStatus describes the progress of an operation.
+type OperationStatus string
+
+const (
+	// OperationRunning is the status of an operation that has
+	// receivers on which its action is yet to be queued or finished.
+	OperationRunning OperationStatus = "running"
+
+	// OperationCompleted is the status of an operation whose action
+	// has finished on every receiver.
+	OperationCompleted OperationStatus = "completed"
+
+	// OperationStopped is the status of an operation that reached its
+	// failure threshold; its action is not queued on the remaining
+	// receivers.
+	OperationStopped OperationStatus = "stopped"
+)
+
+// operationDoc describes an action that is queued on a set of
+// receivers in batches, no more than a given number at a time.
+type operationDoc struct {
+	DocId     string `bson:"_id"`
+	Id        string `bson:"id"`
+	ModelUUID string `bson:"model-uuid"`
+
+	Name       string                 `bson:"name"`
+	Parameters map[string]interface{} `bson:"parameters"`
+
+	// Receivers holds the tags of the receivers of the action, in the
+	// order in which the action is queued on them.
+	Receivers []string `bson:"receivers"`
+
+	// MaxParallel is the maximum number of the operation's actions
+	// that may be queued or running at once; zero means no limit.
+	MaxParallel int `bson:"max-parallel"`
+
+	// MaxFailures is the number of failed actions after which the
+	// operation stops; zero means the operation never stops early.
+	MaxFailures int `bson:"max-failures"`
+
+	// Actions records, in order, the receivers on which the action
+	// has been queued, or has been skipped.
+	Actions []operationActionDoc `bson:"actions"`
+
+	// Completed is the number of receivers on which the action has
+	// finished or been skipped, and Failed is the number of those on
+	// which it didn't complete successfully.
+	Completed int `bson:"completed"`
+	Failed    int `bson:"failed"`
+
+	Status   OperationStatus `bson:"status"`
+	Created  time.Time       `bson:"created"`
+	Finished time.Time       `bson:"finished"`
+}
+
+type operationActionDoc struct {
+	Receiver string `bson:"receiver"`
+	ActionId string `bson:"action-id,omitempty"`
+	Error    string `bson:"error,omitempty"`
+}
+
+// OperationArgs holds the details of an operation to add.
+type OperationArgs struct {
+	// Receivers holds the tags of the receivers of the action.
+	Receivers []names.Tag
+
+	// Name is the name of the action to queue.
+	Name string
+
+	// Parameters holds the action's parameters, if any.
+	Parameters map[string]interface{}
+
+	// MaxParallel is the maximum number of actions that may be queued
+	// or running at once; zero means no limit.
+	MaxParallel int
+
+	// MaxFailures is the number of failed actions after which no more
+	// actions are queued; zero means the operation never stops early.
+	MaxFailures int
+}
+
+// Validate returns an error if the arguments are not valid.
+func (args OperationArgs) Validate() error {
+	if len(args.Receivers) == 0 {
+		return errors.NotValidf("empty receivers")
+	}
+	if args.Name == "" {
+		return errors.NotValidf("empty action name")
+	}
+	if args.MaxParallel < 0 {
+		return errors.NotValidf("negative max parallel")
+	}
+	if args.MaxFailures < 0 {
+		return errors.NotValidf("negative max failures")
+	}
+	return nil
+}
+
+// OperationAction records the action queued on one receiver of an
+// operation.
+type OperationAction struct {
+	// Receiver is the tag of the receiver.
+	Receiver string
+
+	// ActionId is the ID of the action that was queued, if any.
+	ActionId string
+
+	// Error describes why no action was queued, if that was the case.
+	Error string
+}
+
+// Operation represents an action that is queued on a set of receivers
+// in batches.
+type Operation struct {
+	st  *State
+	doc operationDoc
+}
+
+// Id returns the ID of the operation within its model.
+func (o *Operation) Id() string {
+	return o.doc.Id
+}
+
+// Name returns the name of the action.
+func (o *Operation) Name() string {
+	return o.doc.Name
+}
+
+// Parameters returns the parameters of the action.
+func (o *Operation) Parameters() map[string]interface{} {
+	return o.doc.Parameters
+}
+
+// Receivers returns the tags of the receivers of the action.
+func (o *Operation) Receivers() []string {
+	return o.doc.Receivers
+}
+
+// MaxParallel returns the maximum number of actions that may be
+// queued or running at once, or zero if there is no limit.
+func (o *Operation) MaxParallel() int {
+	return o.doc.MaxParallel
+}
+
+// MaxFailures returns the number of failed actions after which the
+// operation stops, or zero if it never stops early.
+func (o *Operation) MaxFailures() int {
+	return o.doc.MaxFailures
+}
+
+// Actions returns the actions queued, or skipped, so far.
+func (o *Operation) Actions() []OperationAction {
+	result := make([]OperationAction, len(o.doc.Actions))
+	for i, a := range o.doc.Actions {
+		result[i] = OperationAction{
+			Receiver: a.Receiver,
+			ActionId: a.ActionId,
+			Error:    a.Error,
+		}
+	}
+	return result
+}
+
+// Completed returns the number of receivers on which the action has
+// finished or been skipped.
+func (o *Operation) Completed() int {
+	return o.doc.Completed
+}
+
+// Failed returns the number of receivers on which the action didn't
+// complete successfully.
+func (o *Operation) Failed() int {
+	return o.doc.Failed
+}
+
+// Status returns the status of the operation.
+func (o *Operation) Status() OperationStatus {
+	return o.doc.Status
+}
+
+// Created returns the time the operation was added.
+func (o *Operation) Created() time.Time {
+	return o.doc.Created.UTC()
+}
+
+// Finished returns the time the operation completed or stopped, or the
+// zero time if it is still running.
+func (o *Operation) Finished() time.Time {
+	if o.doc.Finished.IsZero() {
+		return time.Time{}
+	}
+	return o.doc.Finished.UTC()
+}
+
+// Refresh refreshes the contents of the operation from the database.
+func (o *Operation) Refresh() error {
+	operations, closer := o.st.db().GetCollection(operationsC)
+	defer closer()
+
+	var doc operationDoc
+	err := operations.FindId(o.doc.DocId).One(&doc)
+	if err == mgo.ErrNotFound {
+		return errors.NotFoundf("operation %q", o.doc.Id)
+	}
+	if err != nil {
+		return errors.Annotatef(err, "cannot refresh operation %q", o.doc.Id)
+	}
+	o.doc = doc
+	return nil
+}
+
+// Advance queues the operation's action on as many of the remaining
+// receivers as its parallelism allows, and completes or stops the
+// operation as appropriate. Receivers on which the action can't be
+// queued, because they are dead or the action isn't valid for them,
+// are skipped and counted as failures. The actions queued are
+// returned.
+func (o *Operation) Advance() ([]Action, error) {
+	var (
+		queued  []Action
+		updated operationDoc
+	)
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := o.Refresh(); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		queued = nil
+		updated = o.doc
+		if o.doc.Status != OperationRunning {
+			return nil, jujutxn.ErrNoOperations
+		}
+		var ops []txn.Op
+		var added []operationActionDoc
+		next := len(o.doc.Actions)
+		for next < len(o.doc.Receivers) && o.canQueue(updated, len(added)) {
+			entry, entryOps, action, err := o.queueAction(o.doc.Receivers[next])
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			next++
+			added = append(added, entry)
+			ops = append(ops, entryOps...)
+			if action != nil {
+				queued = append(queued, action)
+			} else {
+				updated.Completed++
+				updated.Failed++
+			}
+		}
+		updated.Actions = append(append([]operationActionDoc(nil), o.doc.Actions...), added...)
+		switch {
+		case updated.MaxFailures > 0 && updated.Failed >= updated.MaxFailures:
+			updated.Status = OperationStopped
+		case updated.Completed >= len(updated.Receivers):
+			updated.Status = OperationCompleted
+		}
+		if updated.Status != OperationRunning {
+			updated.Finished = o.st.nowToTheSecond()
+		}
+		if len(added) == 0 && updated.Status == OperationRunning {
+			return nil, jujutxn.ErrNoOperations
+		}
+		set := bson.D{
+			{"completed", updated.Completed},
+			{"failed", updated.Failed},
+			{"status", updated.Status},
+			{"finished", updated.Finished},
+		}
+		update := bson.D{{"$set", set}}
+		if len(added) > 0 {
+			update = append(update, bson.DocElem{"$push", bson.D{
+				{"actions", bson.D{{"$each", added}}},
+			}})
+		}
+		ops = append(ops, txn.Op{
+			C:  operationsC,
+			Id: o.doc.DocId,
+			Assert: bson.D{
+				{"status", OperationRunning},
+				{"completed", o.doc.Completed},
+				{"failed", o.doc.Failed},
+				{"actions", bson.D{{"$size", len(o.doc.Actions)}}},
+			},
+			Update: update,
+		})
+		return ops, nil
+	}
+	if err := o.st.db().Run(buildTxn); err != nil {
+		return nil, errors.Annotatef(err, "cannot advance operation %q", o.doc.Id)
+	}
+	o.doc = updated
+	return queued, nil
+}
+
+// canQueue reports whether the operation, with the given counters and
+// the given number of entries added to its actions, may queue its
+// action on another receiver.
+func (o *Operation) canQueue(doc operationDoc, added int) bool {
+	if doc.MaxFailures > 0 && doc.Failed >= doc.MaxFailures {
+		return false
+	}
+	if doc.MaxParallel == 0 {
+		return true
+	}
+	inFlight := len(o.doc.Actions) + added - doc.Completed
+	return inFlight < doc.MaxParallel
+}
+
+// queueAction returns the operation's record of queueing its action on
+// the given receiver, with the transaction operations required to do
+// so. If the action can't be queued on the receiver, the record holds
+// the reason and no action is returned.
+func (o *Operation) queueAction(receiverTag string) (operationActionDoc, []txn.Op, Action, error) {
+	entry := operationActionDoc{Receiver: receiverTag}
+	skip := func(err error) (operationActionDoc, []txn.Op, Action, error) {
+		entry.Error = err.Error()
+		return entry, nil, nil, nil
+	}
+	tag, err := names.ParseTag(receiverTag)
+	if err != nil {
+		return skip(err)
+	}
+	entity, err := o.st.FindEntity(tag)
+	if err != nil {
+		return skip(err)
+	}
+	receiver, ok := entity.(ActionReceiver)
+	if !ok {
+		return skip(errors.Errorf("%s cannot receive actions", names.ReadableString(tag)))
+	}
+	payload, err := receiver.PrepareActionPayload(o.doc.Name, o.doc.Parameters)
+	if err != nil {
+		return skip(err)
+	}
+	receiverCollectionName, receiverId, err := o.st.tagToCollectionAndId(tag)
+	if err != nil {
+		return skip(err)
+	}
+	if notDead, err := isNotDead(o.st, receiverCollectionName, receiverId); err != nil {
+		return entry, nil, nil, errors.Trace(err)
+	} else if !notDead {
+		return skip(ErrDead)
+	}
+	doc, ndoc, err := newActionDoc(o.st, tag, o.doc.Name, payload)
+	if err != nil {
+		return entry, nil, nil, errors.Trace(err)
+	}
+	doc.Operation = o.doc.Id
+	entry.ActionId = o.st.localID(doc.DocId)
+	ops := []txn.Op{{
+		C:      receiverCollectionName,
+		Id:     receiverId,
+		Assert: notDeadDoc,
+	}, {
+		C:      actionsC,
+		Id:     doc.DocId,
+		Assert: txn.DocMissing,
+		Insert: doc,
+	}, {
+		C:      actionNotificationsC,
+		Id:     ndoc.DocId,
+		Assert: txn.DocMissing,
+		Insert: ndoc,
+	}}
+	return entry, ops, newAction(o.st, doc), nil
+}
+
+// AddOperation adds an operation that queues an action on the given
+// receivers in batches. No actions are queued until the operation is
+// advanced; see Operation.Advance.
+func (m *Model) AddOperation(args OperationArgs) (*Operation, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Annotate(err, "cannot add operation")
+	}
+	seq, err := sequence(m.st, "operation")
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	id := strconv.Itoa(seq)
+	receivers := make([]string, len(args.Receivers))
+	for i, tag := range args.Receivers {
+		receivers[i] = tag.String()
+	}
+	doc := operationDoc{
+		DocId:       m.st.docID(id),
+		Id:          id,
+		ModelUUID:   m.UUID(),
+		Name:        args.Name,
+		Parameters:  args.Parameters,
+		Receivers:   receivers,
+		MaxParallel: args.MaxParallel,
+		MaxFailures: args.MaxFailures,
+		Actions:     []operationActionDoc{},
+		Status:      OperationRunning,
+		Created:     m.st.nowToTheSecond(),
+	}
+	ops := []txn.Op{{
+		C:      operationsC,
+		Id:     doc.DocId,
+		Assert: txn.DocMissing,
+		Insert: &doc,
+	}}
+	if err := m.st.db().RunTransaction(ops); err != nil {
+		return nil, errors.Annotate(err, "cannot add operation")
+	}
+	return &Operation{st: m.st, doc: doc}, nil
+}
+
+// Operation returns the operation with the given ID.
+func (m *Model) Operation(id string) (*Operation, error) {
+	operations, closer := m.st.db().GetCollection(operationsC)
+	defer closer()
+
+	var doc operationDoc
+	err := operations.FindId(id).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("operation %q", id)
+	}
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot get operation %q", id)
+	}
+	return &Operation{st: m.st, doc: doc}, nil
+}
+
+// AllOperations returns all of the operations in the model, ordered by
+// ID.
+func (m *Model) AllOperations() ([]*Operation, error) {
+	return m.operations(nil)
+}
+
+// RunningOperations returns the operations in the model that are still
+// running, ordered by ID.
+func (m *Model) RunningOperations() ([]*Operation, error) {
+	return m.operations(bson.D{{"status", OperationRunning}})
+}
+
+func (m *Model) operations(query bson.D) ([]*Operation, error) {
+	operations, closer := m.st.db().GetCollection(operationsC)
+	defer closer()
+
+	var docs []operationDoc
+	if err := operations.Find(query).All(&docs); err != nil {
+		return nil, errors.Annotate(err, "cannot get operations")
+	}
+	result := make([]*Operation, len(docs))
+	for i, doc := range docs {
+		result[i] = &Operation{st: m.st, doc: doc}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, _ := strconv.Atoi(result[i].doc.Id)
+		b, _ := strconv.Atoi(result[j].doc.Id)
+		return a < b
+	})
+	return result, nil
+}
+
+// WatchOperations returns a NotifyWatcher that notifies when operations
+// in the model are added or change, including when their actions
+// finish.
+func (m *Model) WatchOperations() NotifyWatcher {
+	return newNotifyCollWatcher(m.st, operationsC, isLocalID(m.st))
+}
@@ -0,0 +1,260 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"fmt"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/state"
+	statetesting "github.com/juju/juju/state/testing"
+)
+
+type OperationSuite struct {
+	ConnSuite
+	units []*state.Unit
+}
+
+var _ = gc.Suite(&OperationSuite{})
+
+func (s *OperationSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	ch := s.AddTestingCharm(c, "dummy")
+	app := s.AddTestingApplication(c, "dummy", ch)
+	s.units = nil
+	for i := 0; i < 3; i++ {
+		unit, err := app.AddUnit(state.AddUnitParams{})
+		c.Assert(err, jc.ErrorIsNil)
+		s.units = append(s.units, unit)
+	}
+}
+
+func (s *OperationSuite) addOperation(c *gc.C, maxParallel, maxFailures int) *state.Operation {
+	var receivers []names.Tag
+	for _, unit := range s.units {
+		receivers = append(receivers, unit.Tag())
+	}
+	op, err := s.Model.AddOperation(state.OperationArgs{
+		Receivers:   receivers,
+		Name:        "snapshot",
+		Parameters:  map[string]interface{}{"outfile": "out.tar.bz2"},
+		MaxParallel: maxParallel,
+		MaxFailures: maxFailures,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return op
+}
+
+func (s *OperationSuite) finish(c *gc.C, action state.Action, status state.ActionStatus) {
+	_, err := action.Finish(state.ActionResults{Status: status})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *OperationSuite) TestAdd(c *gc.C) {
+	op := s.addOperation(c, 2, 1)
+
+	c.Check(op.Id(), gc.Equals, "0")
+	c.Check(op.Name(), gc.Equals, "snapshot")
+	c.Check(op.Parameters(), jc.DeepEquals, map[string]interface{}{"outfile": "out.tar.bz2"})
+	c.Check(op.Receivers(), jc.DeepEquals, []string{"unit-dummy-0", "unit-dummy-1", "unit-dummy-2"})
+	c.Check(op.MaxParallel(), gc.Equals, 2)
+	c.Check(op.MaxFailures(), gc.Equals, 1)
+	c.Check(op.Status(), gc.Equals, state.OperationRunning)
+	c.Check(op.Actions(), gc.HasLen, 0)
+	c.Check(op.Finished().IsZero(), jc.IsTrue)
+
+	fromDB, err := s.Model.Operation("0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(fromDB.Receivers(), jc.DeepEquals, op.Receivers())
+	c.Check(fromDB.Created(), gc.Equals, op.Created())
+
+	pending, err := s.units[0].PendingActions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(pending, gc.HasLen, 0)
+}
+
+func (s *OperationSuite) TestAddInvalid(c *gc.C) {
+	receivers := []names.Tag{s.units[0].Tag()}
+	for i, test := range []struct {
+		args state.OperationArgs
+		err  string
+	}{{
+		args: state.OperationArgs{Name: "snapshot"},
+		err:  "cannot add operation: empty receivers not valid",
+	}, {
+		args: state.OperationArgs{Receivers: receivers},
+		err:  "cannot add operation: empty action name not valid",
+	}, {
+		args: state.OperationArgs{Receivers: receivers, Name: "snapshot", MaxParallel: -1},
+		err:  "cannot add operation: negative max parallel not valid",
+	}, {
+		args: state.OperationArgs{Receivers: receivers, Name: "snapshot", MaxFailures: -1},
+		err:  "cannot add operation: negative max failures not valid",
+	}} {
+		c.Logf("test %d", i)
+		_, err := s.Model.AddOperation(test.args)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *OperationSuite) TestAdvanceUnlimited(c *gc.C) {
+	op := s.addOperation(c, 0, 0)
+	queued, err := op.Advance()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(queued, gc.HasLen, 3)
+	for i, action := range queued {
+		c.Check(action.Receiver(), gc.Equals, s.units[i].Name())
+		c.Check(action.Parameters(), jc.DeepEquals, map[string]interface{}{"outfile": "out.tar.bz2"})
+	}
+	c.Check(op.Actions(), gc.HasLen, 3)
+	c.Check(op.Status(), gc.Equals, state.OperationRunning)
+}
+
+func (s *OperationSuite) TestAdvanceInBatches(c *gc.C) {
+	op := s.addOperation(c, 2, 0)
+	queued, err := op.Advance()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(queued, gc.HasLen, 2)
+	c.Check(queued[0].Receiver(), gc.Equals, "dummy/0")
+	c.Check(queued[1].Receiver(), gc.Equals, "dummy/1")
+
+	// No more actions are queued while the first batch is in flight.
+	more, err := op.Advance()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(more, gc.HasLen, 0)
+
+	s.finish(c, queued[0], state.ActionCompleted)
+	err = op.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(op.Completed(), gc.Equals, 1)
+	c.Check(op.Failed(), gc.Equals, 0)
+
+	more, err = op.Advance()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(more, gc.HasLen, 1)
+	c.Check(more[0].Receiver(), gc.Equals, "dummy/2")
+	c.Check(op.Actions(), jc.DeepEquals, []state.OperationAction{
+		{Receiver: "unit-dummy-0", ActionId: queued[0].Id()},
+		{Receiver: "unit-dummy-1", ActionId: queued[1].Id()},
+		{Receiver: "unit-dummy-2", ActionId: more[0].Id()},
+	})
+
+	s.finish(c, queued[1], state.ActionFailed)
+	s.finish(c, more[0], state.ActionCompleted)
+	err = op.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = op.Advance()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(op.Status(), gc.Equals, state.OperationCompleted)
+	c.Check(op.Completed(), gc.Equals, 3)
+	c.Check(op.Failed(), gc.Equals, 1)
+	c.Check(op.Finished().IsZero(), jc.IsFalse)
+
+	fromDB, err := s.Model.Operation(op.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(fromDB.Status(), gc.Equals, state.OperationCompleted)
+}
+
+func (s *OperationSuite) TestAdvanceStale(c *gc.C) {
+	op := s.addOperation(c, 1, 0)
+	stale, err := s.Model.Operation(op.Id())
+	c.Assert(err, jc.ErrorIsNil)
+
+	queued, err := op.Advance()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(queued, gc.HasLen, 1)
+
+	// The stale operation sees the action already queued, and queues
+	// no more.
+	more, err := stale.Advance()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(more, gc.HasLen, 0)
+	c.Check(stale.Actions(), gc.HasLen, 1)
+}
+
+func (s *OperationSuite) TestStopsOnFailures(c *gc.C) {
+	op := s.addOperation(c, 1, 1)
+	queued, err := op.Advance()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(queued, gc.HasLen, 1)
+
+	s.finish(c, queued[0], state.ActionFailed)
+	err = op.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	more, err := op.Advance()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(more, gc.HasLen, 0)
+	c.Check(op.Status(), gc.Equals, state.OperationStopped)
+
+	running, err := s.Model.RunningOperations()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(running, gc.HasLen, 0)
+
+	pending, err := s.units[1].PendingActions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(pending, gc.HasLen, 0)
+}
+
+func (s *OperationSuite) TestCancelledActionCountsAsFailure(c *gc.C) {
+	op := s.addOperation(c, 1, 0)
+	queued, err := op.Advance()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(queued, gc.HasLen, 1)
+
+	_, err = s.units[0].CancelAction(queued[0])
+	c.Assert(err, jc.ErrorIsNil)
+	err = op.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(op.Completed(), gc.Equals, 1)
+	c.Check(op.Failed(), gc.Equals, 1)
+}
+
+func (s *OperationSuite) TestAdvanceSkipsDeadReceivers(c *gc.C) {
+	err := s.units[0].EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	op := s.addOperation(c, 1, 0)
+
+	queued, err := op.Advance()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(queued, gc.HasLen, 1)
+	c.Check(queued[0].Receiver(), gc.Equals, "dummy/1")
+	c.Check(op.Actions(), jc.DeepEquals, []state.OperationAction{
+		{Receiver: "unit-dummy-0", Error: state.ErrDead.Error()},
+		{Receiver: "unit-dummy-1", ActionId: queued[0].Id()},
+	})
+	c.Check(op.Completed(), gc.Equals, 1)
+	c.Check(op.Failed(), gc.Equals, 1)
+}
+
+func (s *OperationSuite) TestAllOperations(c *gc.C) {
+	for i := 0; i < 11; i++ {
+		s.addOperation(c, 0, 0)
+	}
+	all, err := s.Model.AllOperations()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(all, gc.HasLen, 11)
+	for i, op := range all {
+		c.Check(op.Id(), gc.Equals, fmt.Sprint(i))
+	}
+}
+
+func (s *OperationSuite) TestWatchOperations(c *gc.C) {
+	w := s.Model.WatchOperations()
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertOneChange()
+
+	op := s.addOperation(c, 1, 0)
+	wc.AssertOneChange()
+
+	queued, err := op.Advance()
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+
+	s.finish(c, queued[0], state.ActionCompleted)
+	wc.AssertOneChange()
+}
@@ -64,6 +64,10 @@ type collectionPruner struct {
 
 	ageField string
 	timeUnit TimeUnit
+
+	// filter, if set, restricts pruning by age to the matching
+	// entries.
+	filter bson.D
 }
 
 func (p *collectionPruner) validate() error {
@@ -84,22 +88,7 @@ func (p *collectionPruner) pruneByAge() error {
 		return nil
 	}
 
-	t := p.st.clock().Now().Add(-p.maxAge)
-	var age interface{}
-	var notSet interface{}
-
-	if p.timeUnit == NanoSeconds {
-		age = t.UnixNano()
-		notSet = 0
-	} else {
-		age = t
-		notSet = time.Time{}
-	}
-
-	iter := p.coll.Find(bson.D{
-		{"model-uuid", p.st.modelUUID()},
-		{p.ageField, bson.M{"$gt": notSet, "$lt": age}},
-	}).Select(bson.M{"_id": 1}).Iter()
+	iter := p.coll.Find(p.ageQuery()).Select(bson.M{"_id": 1}).Iter()
 	defer iter.Close()
 
 	modelName, err := p.st.modelName()
@@ -117,6 +106,28 @@ func (p *collectionPruner) pruneByAge() error {
 	return nil
 }
 
+// ageQuery returns the query matching the entries older than the
+// pruner's maximum age.
+func (p *collectionPruner) ageQuery() bson.D {
+	t := p.st.clock().Now().Add(-p.maxAge)
+	var age interface{}
+	var notSet interface{}
+
+	if p.timeUnit == NanoSeconds {
+		age = t.UnixNano()
+		notSet = 0
+	} else {
+		age = t
+		notSet = time.Time{}
+	}
+
+	query := bson.D{
+		{"model-uuid", p.st.modelUUID()},
+		{p.ageField, bson.M{"$gt": notSet, "$lt": age}},
+	}
+	return append(query, p.filter...)
+}
+
 func (p *collectionPruner) pruneBySize() error {
 	if !p.st.isController() {
 		// Only prune by size in the controller. Otherwise we might
@@ -71,3 +71,34 @@ func (st rawState) VerifyApplication(id string) error {
 	}
 	return nil
 }
+
+// activeGeneration returns the model's next generation if it is active,
+// and nil otherwise.
+func (st rawState) activeGeneration() (*Generation, error) {
+	gen, err := st.base.NextGeneration()
+	if errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if !gen.Active() {
+		return nil, nil
+	}
+	return gen, nil
+}
+
+// generationResource returns the pending ID of the named resource if it
+// was attached to the unit's application in the model's next generation,
+// and the unit is assigned to the generation. Otherwise it returns "".
+func (st rawState) generationResource(unitName, applicationID, name string) (string, error) {
+	gen, err := st.base.NextGeneration()
+	if errors.IsNotFound(err) {
+		return "", nil
+	} else if err != nil {
+		return "", errors.Trace(err)
+	}
+	if !gen.unitAssigned(applicationID, unitName) {
+		return "", nil
+	}
+	return gen.Resources(applicationID)[name], nil
+}
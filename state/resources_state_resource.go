@@ -169,14 +169,33 @@ func (st resourceState) GetPendingResource(applicationID, name, pendingID string
 
 // TODO(ericsnow) Separate setting the metadata from storing the blob?
 
-// SetResource stores the resource in the Juju model.
+// SetResource stores the resource in the Juju model. If the model's next
+// generation is active, a file resource is stored as pending and recorded
+// in the generation instead, to be used by units assigned to it.
 func (st resourceState) SetResource(applicationID, userID string, chRes charmresource.Resource, r io.Reader) (resource.Resource, error) {
 	logger.Tracef("adding resource %q for application %q", chRes.Name, applicationID)
 	pendingID := ""
+	var gen *Generation
+	if chRes.Type == charmresource.TypeFile && r != nil {
+		var err error
+		if gen, err = st.raw.activeGeneration(); err != nil {
+			return resource.Resource{}, errors.Trace(err)
+		}
+		if gen != nil {
+			if pendingID, err = newPendingID(); err != nil {
+				return resource.Resource{}, errors.Annotate(err, "could not generate resource ID")
+			}
+		}
+	}
 	res, err := st.setResource(pendingID, applicationID, userID, chRes, r)
 	if err != nil {
 		return res, errors.Trace(err)
 	}
+	if gen != nil {
+		if err := gen.SetResource(applicationID, chRes.Name, pendingID); err != nil {
+			return res, errors.Annotatef(err, "adding resource %q to generation", chRes.Name)
+		}
+	}
 	return res, nil
 }
 
@@ -375,7 +394,7 @@ func (st resourceState) OpenResourceForUniter(unit resource.Unit, name string) (
 		return resource.Resource{}, nil, errors.Trace(err)
 	}
 
-	resourceInfo, resourceReader, err := st.OpenResource(applicationID, name)
+	resourceInfo, resourceReader, err := st.openResourceForUnit(unit, name)
 	if err != nil {
 		return resource.Resource{}, nil, errors.Trace(err)
 	}
@@ -400,6 +419,39 @@ func (st resourceState) OpenResourceForUniter(unit resource.Unit, name string) (
 	return resourceInfo, resourceReader, nil
 }
 
+// openResourceForUnit returns metadata about the revision of the resource
+// that the unit should use, and a reader for it. This is the revision
+// attached in the model's next generation if the unit is assigned to it,
+// and the application's otherwise.
+func (st resourceState) openResourceForUnit(unit resource.Unit, name string) (resource.Resource, io.ReadCloser, error) {
+	applicationID := unit.ApplicationName()
+	genPendingID, err := st.raw.generationResource(unit.Name(), applicationID, name)
+	if err != nil {
+		return resource.Resource{}, nil, errors.Trace(err)
+	}
+	if genPendingID == "" {
+		return st.OpenResource(applicationID, name)
+	}
+
+	resourceInfo, err := st.GetPendingResource(applicationID, name, genPendingID)
+	if err != nil {
+		return resource.Resource{}, nil, errors.Trace(err)
+	}
+	resourceReader, resSize, err := st.storage.Get(storagePath(name, applicationID, genPendingID))
+	if err != nil {
+		return resource.Resource{}, nil, errors.Annotate(err, "while retrieving resource data")
+	}
+	if resSize != resourceInfo.Size {
+		resourceReader.Close()
+		msg := "storage returned a size (%d) which doesn't match resource metadata (%d)"
+		return resource.Resource{}, nil, errors.Errorf(msg, resSize, resourceInfo.Size)
+	}
+	// The unit records the resource as it will be once the
+	// generation completes.
+	resourceInfo.PendingID = ""
+	return resourceInfo, resourceReader, nil
+}
+
 // SetCharmStoreResources sets the "polled" resources for the
 // application to the provided values.
 func (st resourceState) SetCharmStoreResources(applicationID string, info []charmresource.Resource, lastPolled time.Time) error {
@@ -150,9 +150,121 @@ func (u *Unit) ConfigSettings() (charm.Settings, error) {
 	if err != nil {
 		return nil, errors.Annotatef(err, "charm config for unit %q", u.Name())
 	}
+	if err := u.applyGenerationConfig(s); err != nil {
+		return nil, errors.Annotatef(err, "charm config for unit %q", u.Name())
+	}
 	return s, nil
 }
 
+// applyGenerationConfig overlays the config changes made in the model's
+// next generation onto the input settings, if the unit is assigned to it.
+func (u *Unit) applyGenerationConfig(settings charm.Settings) error {
+	gen, err := u.assignedGeneration()
+	if err != nil || gen == nil {
+		return errors.Trace(err)
+	}
+	changes := gen.Config()[u.doc.Application]
+	if len(changes) == 0 {
+		return nil
+	}
+	chrm, err := u.st.Charm(u.doc.CharmURL)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defaults := chrm.Config().DefaultSettings()
+	for name, value := range changes {
+		if value == nil {
+			value = defaults[name]
+		}
+		settings[name] = value
+	}
+	return nil
+}
+
+// GenerationConfigSettingsHash returns the hash yielded by
+// WatchConfigSettingsHash when the unit is assigned to a model
+// generation that changes its charm config. If it is not, false is
+// returned, and the hash is that of the application's charm config.
+func (u *Unit) GenerationConfigSettingsHash() (string, bool, error) {
+	if u.doc.CharmURL == nil {
+		return "", false, errors.New("unit charm not set")
+	}
+	changes, err := u.generationConfigChanges()
+	if err != nil || len(changes) == 0 {
+		return "", false, errors.Trace(err)
+	}
+	key := applicationCharmConfigKey(u.doc.Application, u.doc.CharmURL)
+	hash, err := u.overlaidSettingsHash(key, changes)
+	if err != nil {
+		return "", false, errors.Trace(err)
+	}
+	return hash, true, nil
+}
+
+// configSettingsHash returns a hash of the unit's charm config
+// settings document, with the given ID and key, overlaid with the
+// changes made in the model's next generation if the unit is assigned
+// to it. Without any such changes the hash is that of the settings
+// document alone, so units outside the generation see no change.
+func (u *Unit) configSettingsHash(docID, key string) (string, error) {
+	changes, err := u.generationConfigChanges()
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	if len(changes) == 0 {
+		return hashSettings(u.st.db(), docID, key)
+	}
+	return u.overlaidSettingsHash(key, changes)
+}
+
+// generationConfigChanges returns the changes to the unit's charm
+// config made in the model generation it is assigned to, if any.
+func (u *Unit) generationConfigChanges() (charm.Settings, error) {
+	gen, err := u.assignedGeneration()
+	if err != nil || gen == nil {
+		return nil, errors.Trace(err)
+	}
+	return gen.Config()[u.doc.Application], nil
+}
+
+// overlaidSettingsHash returns the hash of the settings with the given
+// key once the changes are applied; a nil value deletes a setting.
+func (u *Unit) overlaidSettingsHash(key string, changes charm.Settings) (string, error) {
+	doc, err := readSettingsDoc(u.st.db(), settingsC, key)
+	if errors.IsNotFound(err) {
+		return "", nil
+	} else if err != nil {
+		return "", errors.Trace(err)
+	}
+	settings := make(map[string]interface{}, len(doc.Settings)+len(changes))
+	for name, value := range doc.Settings {
+		settings[name] = value
+	}
+	for name, value := range changes {
+		if value == nil {
+			delete(settings, name)
+		} else {
+			settings[name] = value
+		}
+	}
+	return hashSettingsMap(key, settings)
+}
+
+// assignedGeneration returns the model's next generation if the unit is
+// assigned to it, and nil otherwise.
+func (u *Unit) assignedGeneration() (*Generation, error) {
+	gen, err := u.st.NextGeneration()
+	if errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if !gen.unitAssigned(u.doc.Application, u.doc.Name) {
+		return nil, nil
+	}
+	return gen, nil
+}
+
 // ApplicationName returns the application name.
 func (u *Unit) ApplicationName() string {
 	return u.doc.Application
@@ -1387,6 +1499,48 @@ func (u *Unit) CharmURL() (*charm.URL, bool) {
 	return u.doc.CharmURL, true
 }
 
+// TargetCharmURL returns the URL of the charm the unit should be running,
+// and whether it should upgrade to it even if it is in an error state.
+// This is the charm the application was upgraded to in the model's next
+// generation if the unit is assigned to it, and the application's charm
+// otherwise.
+func (u *Unit) TargetCharmURL() (*charm.URL, bool, error) {
+	gen, err := u.assignedGeneration()
+	if err != nil {
+		return nil, false, errors.Trace(err)
+	}
+	if gen != nil {
+		if doc, ok := gen.doc.Charms[u.doc.Application]; ok {
+			return doc.CharmURL, doc.ForceUnits, nil
+		}
+	}
+	app, err := u.Application()
+	if err != nil {
+		return nil, false, errors.Trace(err)
+	}
+	curl, force := app.CharmURL()
+	return curl, force, nil
+}
+
+// TargetCharmModifiedVersion returns the version of the charm and its
+// resources that the unit should be running. This includes the changes
+// made in the model's next generation if the unit is assigned to it.
+func (u *Unit) TargetCharmModifiedVersion() (int, error) {
+	app, err := u.Application()
+	if err != nil {
+		return -1, errors.Trace(err)
+	}
+	gen, err := u.assignedGeneration()
+	if err != nil {
+		return -1, errors.Trace(err)
+	}
+	version := app.CharmModifiedVersion()
+	if gen != nil {
+		version += gen.doc.CharmModifiedVersion[u.doc.Application]
+	}
+	return version, nil
+}
+
 // SetCharmURL marks the unit as currently using the supplied charm URL.
 // An error will be returned if the unit is dead, or the charm URL not known.
 func (u *Unit) SetCharmURL(curl *charm.URL) error {
@@ -1427,15 +1581,24 @@ func (u *Unit) SetCharmURL(curl *charm.URL) error {
 			return nil, errors.Errorf("unknown charm url %q", curl)
 		}
 
+		// A unit assigned to the model's next generation may be
+		// upgraded to the generation's charm before the application
+		// is, in which case the settings for it may not exist yet.
+		settingsOps, canCreate, err := u.generationCharmSettingsOps(curl)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+
 		// Add a reference to the application settings for the new charm.
-		incOps, err := appCharmIncRefOps(u.st, u.doc.Application, curl, false)
+		incOps, err := appCharmIncRefOps(u.st, u.doc.Application, curl, canCreate)
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
 
 		// Set the new charm URL.
 		differentCharm := bson.D{{"charmurl", bson.D{{"$ne", curl}}}}
-		ops := append(incOps,
+		ops := append(settingsOps, incOps...)
+		ops = append(ops,
 			txn.Op{
 				C:      unitsC,
 				Id:     u.doc.DocID,
@@ -1459,6 +1622,52 @@ func (u *Unit) SetCharmURL(curl *charm.URL) error {
 	return err
 }
 
+// generationCharmSettingsOps returns the operations needed to create the
+// application's settings for the input charm, if it is the charm that the
+// application was upgraded to in a generation that the unit is assigned to.
+// It also returns whether a reference to the settings may be created.
+func (u *Unit) generationCharmSettingsOps(curl *charm.URL) ([]txn.Op, bool, error) {
+	gen, err := u.assignedGeneration()
+	if err != nil || gen == nil {
+		return nil, false, errors.Trace(err)
+	}
+	doc, ok := gen.doc.Charms[u.doc.Application]
+	if !ok || doc.CharmURL.String() != curl.String() {
+		return nil, false, nil
+	}
+
+	key := applicationCharmConfigKey(u.doc.Application, curl)
+	if _, err := readSettings(u.st.db(), settingsC, key); err == nil {
+		return nil, true, nil
+	} else if !errors.IsNotFound(err) {
+		return nil, false, errors.Trace(err)
+	}
+
+	// Build the settings from what can be used of the application's
+	// current ones, as the upgrade itself does.
+	app, err := u.Application()
+	if err != nil {
+		return nil, false, errors.Trace(err)
+	}
+	ch, err := u.st.Charm(curl)
+	if err != nil {
+		return nil, false, errors.Trace(err)
+	}
+	current, err := readSettings(u.st.db(), settingsC, app.charmConfigKey())
+	if err != nil {
+		return nil, false, errors.Annotatef(err, "charm config for application %q", u.doc.Application)
+	}
+	settings := ch.Config().FilterSettings(current.Map())
+	for name, value := range doc.Config {
+		if value == nil {
+			delete(settings, name)
+			continue
+		}
+		settings[name] = value
+	}
+	return []txn.Op{createSettingsOp(settingsC, key, settings)}, true, nil
+}
+
 // charm returns the charm for the unit, or the application if the unit's charm
 // has not been set yet.
 func (u *Unit) charm() (*Charm, error) {
@@ -2693,6 +2902,20 @@ type ActionSpecsByName map[string]charm.ActionSpec
 // this Unit, and returns its ID.  Note that the use of spec.InsertDefaults
 // mutates payload.
 func (u *Unit) AddAction(name string, payload map[string]interface{}) (Action, error) {
+	payloadWithDefaults, err := u.PrepareActionPayload(name, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := u.st.Model()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return m.EnqueueAction(u.Tag(), name, payloadWithDefaults)
+}
+
+// PrepareActionPayload is part of the ActionReceiver interface.
+func (u *Unit) PrepareActionPayload(name string, payload map[string]interface{}) (map[string]interface{}, error) {
 	if len(name) == 0 {
 		return nil, errors.New("no action name given")
 	}
@@ -2714,16 +2937,7 @@ func (u *Unit) AddAction(name string, payload map[string]interface{}) (Action, e
 	if err != nil {
 		return nil, err
 	}
-	payloadWithDefaults, err := spec.InsertDefaults(payload)
-	if err != nil {
-		return nil, err
-	}
-
-	m, err := u.st.Model()
-	if err != nil {
-		return nil, errors.Trace(err)
-	}
-	return m.EnqueueAction(u.Tag(), name, payloadWithDefaults)
+	return spec.InsertDefaults(payload)
 }
 
 // ActionSpecs gets the ActionSpec map for the Unit's charm.
@@ -18,6 +18,7 @@ import (
 
 	"github.com/juju/juju/core/application"
 	"github.com/juju/juju/core/instance"
+	"github.com/juju/juju/core/model"
 	"github.com/juju/juju/core/status"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/state"
@@ -250,6 +251,78 @@ func (s *UnitSuite) TestWatchConfigSettingsHash(c *gc.C) {
 	wc.AssertChange("")
 }
 
+func (s *UnitSuite) TestWatchConfigSettingsHashGeneration(c *gc.C) {
+	err := s.unit.SetCharmURL(s.charm.URL())
+	c.Assert(err, jc.ErrorIsNil)
+	other, err := s.application.AddUnit(state.AddUnitParams{})
+	c.Assert(err, jc.ErrorIsNil)
+	err = other.SetCharmURL(s.charm.URL())
+	c.Assert(err, jc.ErrorIsNil)
+
+	w, err := s.unit.WatchConfigSettingsHash()
+	c.Assert(err, jc.ErrorIsNil)
+	defer testing.AssertStop(c, w)
+	wc := testing.NewStringsWatcherC(c, s.State, w)
+	initial := s.nextConfigHash(c, w)
+
+	otherW, err := other.WatchConfigSettingsHash()
+	c.Assert(err, jc.ErrorIsNil)
+	defer testing.AssertStop(c, otherW)
+	otherWC := testing.NewStringsWatcherC(c, s.State, otherW)
+	c.Assert(s.nextConfigHash(c, otherW), gc.Equals, initial)
+
+	// Config changed in the generation isn't seen until a unit is
+	// assigned to it.
+	c.Assert(s.Model.AddGeneration(), jc.ErrorIsNil)
+	err = s.application.UpdateCharmConfig(charm.Settings{"blog-title": "canary"})
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertNoChange()
+	otherWC.AssertNoChange()
+
+	gen, err := s.Model.NextGeneration()
+	c.Assert(err, jc.ErrorIsNil)
+	err = gen.AssignUnit(s.unit.Name())
+	c.Assert(err, jc.ErrorIsNil)
+	canary := s.nextConfigHash(c, w)
+	c.Assert(canary, gc.Not(gc.Equals), initial)
+	otherWC.AssertNoChange()
+
+	config, err := s.unit.ConfigSettings()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(config["blog-title"], gc.Equals, "canary")
+
+	// Further changes in the generation are seen by the assigned unit.
+	err = s.application.UpdateCharmConfig(charm.Settings{"blog-title": "canary two"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.nextConfigHash(c, w), gc.Not(gc.Equals), canary)
+	otherWC.AssertNoChange()
+
+	// Changing the current config of a setting the generation
+	// overrides is only seen by units outside the generation.
+	err = s.State.SwitchGeneration(model.GenerationCurrent)
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertNoChange()
+	otherWC.AssertNoChange()
+	err = s.application.UpdateCharmConfig(charm.Settings{"blog-title": "stable"})
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertNoChange()
+	c.Assert(s.nextConfigHash(c, otherW), gc.Not(gc.Equals), initial)
+}
+
+// nextConfigHash returns the next hash yielded by the watcher.
+func (s *UnitSuite) nextConfigHash(c *gc.C, w state.StringsWatcher) string {
+	s.State.StartSync()
+	select {
+	case hashes, ok := <-w.Changes():
+		c.Assert(ok, jc.IsTrue)
+		c.Assert(hashes, gc.HasLen, 1)
+		return hashes[0]
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for config hash")
+	}
+	return ""
+}
+
 func (s *UnitSuite) TestConfigHashesDifferentForDifferentCharms(c *gc.C) {
 	// Config hashes should be different if the charm url changes,
 	// even if the config is otherwise unchanged. This ensures that
@@ -24,6 +24,7 @@ import (
 
 	"github.com/juju/juju/core/instance"
 	"github.com/juju/juju/core/lxdprofile"
+	"github.com/juju/juju/core/settings"
 	"github.com/juju/juju/mongo"
 	"github.com/juju/juju/state/watcher"
 
@@ -1900,15 +1901,33 @@ func (u *Unit) WatchApplicationConfigSettings() (NotifyWatcher, error) {
 }
 
 // WatchConfigSettingsHash returns a watcher that yields a hash of the
-// unit's charm config settings whenever they are changed. The
-// returned watcher will be valid only while the application's charm
-// URL is not changed.
+// unit's charm config settings whenever they are changed. If the unit
+// is assigned to the model's next generation, the config changes made
+// in the generation are included. The returned watcher will be valid
+// only while the application's charm URL is not changed.
 func (u *Unit) WatchConfigSettingsHash() (StringsWatcher, error) {
 	if u.doc.CharmURL == nil {
 		return nil, fmt.Errorf("unit charm not set")
 	}
+	unit := &Unit{st: u.st, doc: u.doc}
 	charmConfigKey := applicationCharmConfigKey(u.doc.Application, u.doc.CharmURL)
-	return newSettingsHashWatcher(u.st, charmConfigKey), nil
+	docID := u.st.docID(charmConfigKey)
+	w := &hashWatcher{
+		commonWatcher: newCommonWatcher(u.st),
+		out:           make(chan []string),
+		collection:    settingsC,
+		id:            docID,
+		// Generations are watched as a whole, since assigning the
+		// unit or changing config in a generation both affect the
+		// settings it sees.
+		relatedCollection: generationsC,
+		relatedFilter:     isLocalID(u.st),
+		hash: func() (string, error) {
+			return unit.configSettingsHash(docID, charmConfigKey)
+		},
+	}
+	w.start()
+	return w, nil
 }
 
 // WatchApplicationConfigSettingsHash is the same as
@@ -3744,17 +3763,13 @@ func hashSettings(db Database, id string, name string) (string, error) {
 	} else if err != nil {
 		return "", errors.Trace(err)
 	}
-	// Ensure elements are in a consistent order. If any are maps,
-	// replace them with the equivalent sorted bson.Ds.
-	items := toSortedBsonD(doc.Settings)
-	data, err := bson.Marshal(items)
-	if err != nil {
-		return "", errors.Trace(err)
-	}
-	hash := sha256.New()
-	hash.Write([]byte(name))
-	hash.Write(data)
-	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+	return hashSettingsMap(name, doc.Settings)
+}
+
+// hashSettingsMap returns the hash of the named settings.
+func hashSettingsMap(name string, values map[string]interface{}) (string, error) {
+	hash, err := settings.Hash(name, values)
+	return hash, errors.Trace(err)
 }
 
 // WatchAddressesHash returns a StringsWatcher that emits the hash of
@@ -3848,6 +3863,12 @@ type hashWatcher struct {
 	id         string
 	hash       func() (string, error)
 	out        chan []string
+
+	// relatedCollection, if set, names a collection whose changes
+	// (to documents matching relatedFilter) can also change the
+	// hash.
+	relatedCollection string
+	relatedFilter     func(interface{}) bool
 }
 
 func (w *hashWatcher) Changes() <-chan []string {
@@ -3871,6 +3892,10 @@ func (w *hashWatcher) loop() error {
 	changesCh := make(chan watcher.Change)
 	w.watcher.Watch(w.collection, w.id, revno, changesCh)
 	defer w.watcher.Unwatch(w.collection, w.id, changesCh)
+	if w.relatedCollection != "" {
+		w.watcher.WatchCollectionWithFilter(w.relatedCollection, changesCh, w.relatedFilter)
+		defer w.watcher.UnwatchCollection(w.relatedCollection, changesCh)
+	}
 
 	lastHash, err := w.hash()
 	if err != nil {
@@ -3900,19 +3925,3 @@ func (w *hashWatcher) loop() error {
 		}
 	}
 }
-
-func toSortedBsonD(values map[string]interface{}) bson.D {
-	var items bson.D
-	for name, value := range values {
-		if mapValue, ok := value.(map[string]interface{}); ok {
-			value = toSortedBsonD(mapValue)
-		}
-		items = append(items, bson.DocElem{Name: name, Value: value})
-	}
-	// We know that there aren't any equal names because the source is
-	// a map.
-	sort.Slice(items, func(i int, j int) bool {
-		return items[i].Name < items[j].Name
-	})
-	return items
-}
@@ -0,0 +1,143 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package actionpruner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/juju/clock"
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/worker/pruner"
+)
+
+var logger = loggo.GetLogger("juju.worker.actionpruner")
+
+// ExportFacade is a pruner.Facade that can also list, a page at a
+// time, the actions that would be pruned by age.
+type ExportFacade interface {
+	pruner.Facade
+	PrunableActions(maxHistoryTime time.Duration, offset, limit int) ([]params.ActionResult, error)
+}
+
+// exportPageSize is the number of action results read from the
+// controller at a time while exporting them.
+const exportPageSize = 500
+
+// ExportDir is the directory, within the controller agent's data
+// directory, to which action results are exported before they are
+// pruned by age.
+const ExportDir = "action-results"
+
+// exportingFacade writes the results of the actions about to be pruned
+// by age to the export directory, if the model's configuration asks
+// for them to be exported, before pruning them.
+type exportingFacade struct {
+	ExportFacade
+	dir   string
+	clock clock.Clock
+}
+
+// NewExportingFacade returns a pruner.Facade that exports the results
+// of the actions it prunes by age to dir, when the model's
+// configuration asks for them to be exported.
+func NewExportingFacade(facade ExportFacade, dir string, clock clock.Clock) pruner.Facade {
+	return &exportingFacade{ExportFacade: facade, dir: dir, clock: clock}
+}
+
+// Prune is part of the pruner.Facade interface. If the action results
+// can't be exported, nothing is pruned, so that results the model's
+// configuration asks to keep are never lost.
+func (f *exportingFacade) Prune(maxHistoryTime time.Duration, maxHistoryMB int) error {
+	cfg, err := f.ModelConfig()
+	if err != nil {
+		return errors.Annotate(err, "cannot load model configuration")
+	}
+	if cfg.ActionResultsExport() {
+		if err := f.export(cfg.UUID(), maxHistoryTime); err != nil {
+			return errors.Annotate(err, "cannot export action results")
+		}
+	}
+	return f.ExportFacade.Prune(maxHistoryTime, maxHistoryMB)
+}
+
+// export writes the results of the actions that would be pruned by age
+// to a JSON file in the export directory, reading them from the
+// controller a page at a time. Nothing is written if there are no
+// results to export.
+func (f *exportingFacade) export(modelUUID string, maxHistoryTime time.Duration) (err error) {
+	var (
+		file  *os.File
+		count int
+	)
+	defer func() {
+		if file != nil && err != nil {
+			file.Close()
+			os.Remove(file.Name())
+		}
+	}()
+	for {
+		results, err := f.PrunableActions(maxHistoryTime, count, exportPageSize)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if len(results) == 0 {
+			break
+		}
+		if file == nil {
+			if err := os.MkdirAll(f.dir, 0700); err != nil {
+				return errors.Trace(err)
+			}
+			if file, err = ioutil.TempFile(f.dir, "exporting-"); err != nil {
+				return errors.Trace(err)
+			}
+			if _, err := file.WriteString("["); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		for _, result := range results {
+			data, err := json.MarshalIndent(result, "  ", "  ")
+			if err != nil {
+				return errors.Trace(err)
+			}
+			sep := ",\n  "
+			if count == 0 {
+				sep = "\n  "
+			}
+			if _, err := file.WriteString(sep); err != nil {
+				return errors.Trace(err)
+			}
+			if _, err := file.Write(data); err != nil {
+				return errors.Trace(err)
+			}
+			count++
+		}
+	}
+	if file == nil {
+		return nil
+	}
+	if _, err := file.WriteString("\n]\n"); err != nil {
+		return errors.Trace(err)
+	}
+	if err := file.Sync(); err != nil {
+		return errors.Trace(err)
+	}
+	if err := file.Close(); err != nil {
+		return errors.Trace(err)
+	}
+	name := fmt.Sprintf("action-results-%s-%s.json", modelUUID, f.clock.Now().UTC().Format("20060102T150405Z"))
+	path := filepath.Join(f.dir, name)
+	if err := os.Rename(file.Name(), path); err != nil {
+		return errors.Trace(err)
+	}
+	logger.Infof("exported %d action results to %s", count, path)
+	return nil
+}
@@ -0,0 +1,192 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package actionpruner_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/juju/clock/testclock"
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/core/watcher"
+	"github.com/juju/juju/environs/config"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/actionpruner"
+)
+
+type facadeSuite struct {
+	coretesting.BaseSuite
+
+	clock  *testclock.Clock
+	stub   *testing.Stub
+	config *config.Config
+	dir    string
+
+	prunable []params.ActionResult
+}
+
+var _ = gc.Suite(&facadeSuite{})
+
+func (s *facadeSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.clock = testclock.NewClock(time.Date(2018, 7, 1, 12, 0, 0, 0, time.UTC))
+	s.stub = &testing.Stub{}
+	s.config = coretesting.ModelConfig(c)
+	s.dir = filepath.Join(c.MkDir(), actionpruner.ExportDir)
+	s.prunable = []params.ActionResult{{
+		Action: &params.Action{
+			Tag:      "action-f47ac10b-58cc-4372-a567-0e02b2c3d479",
+			Receiver: "unit-mysql-0",
+			Name:     "backup",
+		},
+		Status: "completed",
+		Output: map[string]interface{}{"path": "/tmp/backup.tgz"},
+	}}
+}
+
+func (s *facadeSuite) readExported(c *gc.C) []params.ActionResult {
+	path := filepath.Join(s.dir, "action-results-"+s.config.UUID()+"-20180701T120000Z.json")
+	data, err := ioutil.ReadFile(path)
+	c.Assert(err, jc.ErrorIsNil)
+	var exported []params.ActionResult
+	err = json.Unmarshal(data, &exported)
+	c.Assert(err, jc.ErrorIsNil)
+	return exported
+}
+
+func (s *facadeSuite) TestPruneWithoutExport(c *gc.C) {
+	facade := actionpruner.NewExportingFacade(&mockFacade{s}, s.dir, s.clock)
+	err := facade.Prune(time.Hour, 5)
+	c.Assert(err, jc.ErrorIsNil)
+	s.stub.CheckCalls(c, []testing.StubCall{
+		{"ModelConfig", nil},
+		{"Prune", []interface{}{time.Hour, 5}},
+	})
+}
+
+func (s *facadeSuite) TestPruneExportsResults(c *gc.C) {
+	s.config = coretesting.CustomModelConfig(c, coretesting.Attrs{"action-results-export": true})
+	facade := actionpruner.NewExportingFacade(&mockFacade{s}, s.dir, s.clock)
+	err := facade.Prune(time.Hour, 5)
+	c.Assert(err, jc.ErrorIsNil)
+	s.stub.CheckCalls(c, []testing.StubCall{
+		{"ModelConfig", nil},
+		{"PrunableActions", []interface{}{time.Hour, 0, 500}},
+		{"PrunableActions", []interface{}{time.Hour, 1, 500}},
+		{"Prune", []interface{}{time.Hour, 5}},
+	})
+
+	exported := s.readExported(c)
+	c.Assert(exported, gc.HasLen, 1)
+	c.Check(exported[0].Action.Name, gc.Equals, "backup")
+	c.Check(exported[0].Output, jc.DeepEquals, map[string]interface{}{"path": "/tmp/backup.tgz"})
+}
+
+func (s *facadeSuite) TestPruneExportsResultsInPages(c *gc.C) {
+	s.config = coretesting.CustomModelConfig(c, coretesting.Attrs{"action-results-export": true})
+	for i := 0; i < 500; i++ {
+		s.prunable = append(s.prunable, params.ActionResult{Status: "completed"})
+	}
+	facade := actionpruner.NewExportingFacade(&mockFacade{s}, s.dir, s.clock)
+	err := facade.Prune(time.Hour, 5)
+	c.Assert(err, jc.ErrorIsNil)
+	s.stub.CheckCalls(c, []testing.StubCall{
+		{"ModelConfig", nil},
+		{"PrunableActions", []interface{}{time.Hour, 0, 500}},
+		{"PrunableActions", []interface{}{time.Hour, 500, 500}},
+		{"PrunableActions", []interface{}{time.Hour, 501, 500}},
+		{"Prune", []interface{}{time.Hour, 5}},
+	})
+	c.Assert(s.readExported(c), gc.HasLen, 501)
+}
+
+func (s *facadeSuite) TestPruneExportsNothing(c *gc.C) {
+	s.config = coretesting.CustomModelConfig(c, coretesting.Attrs{"action-results-export": true})
+	s.prunable = nil
+	facade := actionpruner.NewExportingFacade(&mockFacade{s}, s.dir, s.clock)
+	err := facade.Prune(time.Hour, 5)
+	c.Assert(err, jc.ErrorIsNil)
+	s.stub.CheckCallNames(c, "ModelConfig", "PrunableActions", "Prune")
+	_, err = os.Stat(s.dir)
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+}
+
+func (s *facadeSuite) TestPruneExportFailed(c *gc.C) {
+	s.config = coretesting.CustomModelConfig(c, coretesting.Attrs{"action-results-export": true})
+	s.stub.SetErrors(nil, errors.New("boom"))
+	facade := actionpruner.NewExportingFacade(&mockFacade{s}, s.dir, s.clock)
+	err := facade.Prune(time.Hour, 5)
+	c.Assert(err, gc.ErrorMatches, "cannot export action results: boom")
+	s.stub.CheckCallNames(c, "ModelConfig", "PrunableActions")
+}
+
+func (s *facadeSuite) TestPruneExportFailedPartWay(c *gc.C) {
+	s.config = coretesting.CustomModelConfig(c, coretesting.Attrs{"action-results-export": true})
+	s.stub.SetErrors(nil, nil, errors.New("boom"))
+	facade := actionpruner.NewExportingFacade(&mockFacade{s}, s.dir, s.clock)
+	err := facade.Prune(time.Hour, 5)
+	c.Assert(err, gc.ErrorMatches, "cannot export action results: boom")
+	s.stub.CheckCallNames(c, "ModelConfig", "PrunableActions", "PrunableActions")
+
+	// The partly written export is removed.
+	files, err := ioutil.ReadDir(s.dir)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(files, gc.HasLen, 0)
+}
+
+func (s *facadeSuite) TestPruneExportWriteFailed(c *gc.C) {
+	s.config = coretesting.CustomModelConfig(c, coretesting.Attrs{"action-results-export": true})
+	// A file where the export directory should be stops the export.
+	err := ioutil.WriteFile(s.dir, nil, 0600)
+	c.Assert(err, jc.ErrorIsNil)
+	facade := actionpruner.NewExportingFacade(&mockFacade{s}, s.dir, s.clock)
+	err = facade.Prune(time.Hour, 5)
+	c.Assert(err, gc.ErrorMatches, "cannot export action results: .*")
+	s.stub.CheckCallNames(c, "ModelConfig", "PrunableActions")
+}
+
+type mockFacade struct {
+	s *facadeSuite
+}
+
+func (f *mockFacade) Prune(maxHistoryTime time.Duration, maxHistoryMB int) error {
+	f.s.stub.AddCall("Prune", maxHistoryTime, maxHistoryMB)
+	return f.s.stub.NextErr()
+}
+
+func (f *mockFacade) PrunableActions(maxHistoryTime time.Duration, offset, limit int) ([]params.ActionResult, error) {
+	f.s.stub.AddCall("PrunableActions", maxHistoryTime, offset, limit)
+	if err := f.s.stub.NextErr(); err != nil {
+		return nil, err
+	}
+	if offset >= len(f.s.prunable) {
+		return nil, nil
+	}
+	end := offset + limit
+	if end > len(f.s.prunable) {
+		end = len(f.s.prunable)
+	}
+	return f.s.prunable[offset:end], nil
+}
+
+func (f *mockFacade) WatchForModelConfigChanges() (watcher.NotifyWatcher, error) {
+	f.s.stub.AddCall("WatchForModelConfigChanges")
+	return nil, errors.NotImplementedf("WatchForModelConfigChanges")
+}
+
+func (f *mockFacade) ModelConfig() (*config.Config, error) {
+	f.s.stub.AddCall("ModelConfig")
+	if err := f.s.stub.NextErr(); err != nil {
+		return nil, err
+	}
+	return f.s.config, nil
+}
@@ -4,8 +4,10 @@
 package actionpruner
 
 import (
+	"path/filepath"
 	"time"
 
+	"github.com/juju/clock"
 	"github.com/juju/errors"
 	"gopkg.in/juju/worker.v1"
 	"gopkg.in/juju/worker.v1/catacomb"
@@ -21,8 +23,14 @@ type Worker struct {
 	pruner.PrunerWorker
 }
 
-func NewFacade(caller base.APICaller) pruner.Facade {
-	return action.NewFacade(caller)
+// NewFacade returns a function that makes facades that export the
+// results of the actions they prune by age to the ExportDir within
+// dataDir.
+func NewFacade(dataDir string) func(base.APICaller) pruner.Facade {
+	dir := filepath.Join(dataDir, ExportDir)
+	return func(caller base.APICaller) pruner.Facade {
+		return NewExportingFacade(action.NewFacade(caller), dir, clock.WallClock)
+	}
 }
 
 func (w *Worker) loop() error {
@@ -0,0 +1,145 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package actionscheduler
+
+import (
+	"time"
+
+	"github.com/juju/clock"
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"gopkg.in/juju/worker.v1"
+	"gopkg.in/juju/worker.v1/catacomb"
+
+	"github.com/juju/juju/core/watcher"
+)
+
+var logger = loggo.GetLogger("juju.worker.actionscheduler")
+
+const (
+	// maxPeriod is the longest the worker waits between runs, even if
+	// no schedule is due. This guards against the controller's clock
+	// jumping while it waits.
+	maxPeriod = time.Hour
+
+	// retryPeriod is how long the worker waits before trying again
+	// when running the due schedules fails.
+	retryPeriod = time.Minute
+)
+
+// Facade exposes the controller functionality used by the action
+// scheduler worker.
+type Facade interface {
+	WatchActionSchedules() (watcher.NotifyWatcher, error)
+	RunDueActionSchedules() (time.Time, error)
+	WatchOperations() (watcher.NotifyWatcher, error)
+	AdvanceOperations() error
+}
+
+// Scheduler queues actions as their schedules fall due, and as the
+// earlier actions of operations finish.
+type Scheduler struct {
+	catacomb   catacomb.Catacomb
+	facade     Facade
+	watcher    watcher.NotifyWatcher
+	opsWatcher watcher.NotifyWatcher
+	clock      clock.Clock
+}
+
+// NewScheduler returns a worker that runs the model's due action
+// schedules whenever the schedules change, and again when the next
+// schedule is due. It also advances the model's running operations
+// whenever they change.
+func NewScheduler(facade Facade, clock clock.Clock) (worker.Worker, error) {
+	watcher, err := facade.WatchActionSchedules()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	opsWatcher, err := facade.WatchOperations()
+	if err != nil {
+		worker.Stop(watcher)
+		return nil, errors.Trace(err)
+	}
+	s := &Scheduler{
+		facade:     facade,
+		watcher:    watcher,
+		opsWatcher: opsWatcher,
+		clock:      clock,
+	}
+	if err := catacomb.Invoke(catacomb.Plan{
+		Site: &s.catacomb,
+		Work: s.loop,
+		Init: []worker.Worker{watcher, opsWatcher},
+	}); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return s, nil
+}
+
+func (s *Scheduler) loop() error {
+	timer := s.clock.NewTimer(maxPeriod)
+	defer timer.Stop()
+	for {
+		select {
+		case <-s.catacomb.Dying():
+			return s.catacomb.ErrDying()
+		case _, ok := <-s.watcher.Changes():
+			if !ok {
+				return errors.New("change channel closed")
+			}
+			timer.Reset(s.runDue())
+		case _, ok := <-s.opsWatcher.Changes():
+			if !ok {
+				return errors.New("operations change channel closed")
+			}
+			// Advancing an operation changes it, so a failure here is
+			// retried when the operation next changes, or when one of
+			// its actions finishes.
+			if err := s.facade.AdvanceOperations(); err != nil {
+				logger.Errorf("cannot advance operations: %v", err)
+			}
+		case <-timer.Chan():
+			timer.Reset(s.runDue())
+		}
+	}
+}
+
+// runDue runs the due schedules, and returns how long to wait before
+// running them again.
+func (s *Scheduler) runDue() time.Duration {
+	nextRun, err := s.facade.RunDueActionSchedules()
+	if err != nil {
+		// We don't exit if running the schedules fails, so that a
+		// transient failure doesn't hold up the schedules until the
+		// worker is restarted.
+		logger.Errorf("cannot run action schedules: %v", err)
+		return retryPeriod
+	}
+	if nextRun.IsZero() {
+		return maxPeriod
+	}
+	wait := nextRun.Sub(s.clock.Now())
+	if wait <= 0 {
+		// Any schedule that was due has just been run, so one that is
+		// still due couldn't have its run recorded. Back off rather
+		// than running it again straight away.
+		logger.Warningf("action schedule still due at %v after running schedules", nextRun)
+		return retryPeriod
+	}
+	if wait > maxPeriod {
+		wait = maxPeriod
+	}
+	logger.Debugf("next action schedule due at %v", nextRun)
+	return wait
+}
+
+// Kill is part of the worker.Worker interface.
+func (s *Scheduler) Kill() {
+	s.catacomb.Kill(nil)
+}
+
+// Wait is part of the worker.Worker interface.
+func (s *Scheduler) Wait() error {
+	return s.catacomb.Wait()
+}
@@ -0,0 +1,221 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package actionscheduler_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/juju/clock/testclock"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/worker.v1"
+	"gopkg.in/juju/worker.v1/workertest"
+
+	"github.com/juju/juju/core/watcher"
+	"github.com/juju/juju/core/watcher/watchertest"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/actionscheduler"
+)
+
+type SchedulerSuite struct {
+	coretesting.BaseSuite
+	facade *mockFacade
+	clock  *testclock.Clock
+}
+
+var _ = gc.Suite(&SchedulerSuite{})
+
+func (s *SchedulerSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.clock = testclock.NewClock(time.Date(2018, 7, 1, 12, 0, 0, 0, time.UTC))
+	changes := make(chan struct{}, 1)
+	changes <- struct{}{}
+	s.facade = &mockFacade{
+		changes:    changes,
+		opsChanges: make(chan struct{}, 1),
+		calls:      make(chan string, 1),
+	}
+}
+
+func (s *SchedulerSuite) assertReceived(c *gc.C, expect string) {
+	select {
+	case call := <-s.facade.calls:
+		c.Assert(call, gc.Equals, expect)
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for %s", expect)
+	}
+}
+
+func (s *SchedulerSuite) assertEmpty(c *gc.C) {
+	select {
+	case call := <-s.facade.calls:
+		c.Fatalf("unexpected %s", call)
+	case <-time.After(coretesting.ShortWait):
+	}
+}
+
+// assertRan checks that the due schedules were run, and waits for the
+// scheduler to set its timer for the next run.
+func (s *SchedulerSuite) assertRan(c *gc.C) {
+	s.assertReceived(c, "RunDueActionSchedules")
+	s.waitAlarm(c)
+}
+
+func (s *SchedulerSuite) waitAlarm(c *gc.C) {
+	select {
+	case <-s.clock.Alarms():
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for timer")
+	}
+}
+
+func (s *SchedulerSuite) startScheduler(c *gc.C) worker.Worker {
+	w, err := actionscheduler.NewScheduler(s.facade, s.clock)
+	c.Assert(err, jc.ErrorIsNil)
+	s.AddCleanup(func(c *gc.C) { workertest.CleanKill(c, w) })
+	s.assertReceived(c, "WatchActionSchedules")
+	s.assertReceived(c, "WatchOperations")
+	s.waitAlarm(c)
+	return w
+}
+
+func (s *SchedulerSuite) TestRunsOnChange(c *gc.C) {
+	s.startScheduler(c)
+	s.assertRan(c)
+	s.assertEmpty(c)
+
+	s.facade.changes <- struct{}{}
+	s.assertRan(c)
+	s.assertEmpty(c)
+}
+
+func (s *SchedulerSuite) TestRunsWhenNextDue(c *gc.C) {
+	s.facade.nextRun = []time.Time{s.clock.Now().Add(10 * time.Minute)}
+	s.startScheduler(c)
+	s.assertRan(c)
+
+	s.clock.Advance(9 * time.Minute)
+	s.assertEmpty(c)
+	s.clock.Advance(time.Minute)
+	s.assertRan(c)
+	s.assertEmpty(c)
+}
+
+func (s *SchedulerSuite) TestRunsHourlyWhenNothingDue(c *gc.C) {
+	s.facade.nextRun = []time.Time{s.clock.Now().Add(48 * time.Hour)}
+	s.startScheduler(c)
+	s.assertRan(c)
+
+	s.clock.Advance(59 * time.Minute)
+	s.assertEmpty(c)
+	s.clock.Advance(time.Minute)
+	s.assertRan(c)
+	s.assertEmpty(c)
+}
+
+func (s *SchedulerSuite) TestRetriesAfterError(c *gc.C) {
+	s.facade.err = []error{errors.New("boom")}
+	s.startScheduler(c)
+	s.assertRan(c)
+
+	s.clock.Advance(time.Minute)
+	s.assertRan(c)
+	s.assertEmpty(c)
+	c.Assert(c.GetTestLog(), jc.Contains, "ERROR juju.worker.actionscheduler cannot run action schedules: boom")
+}
+
+func (s *SchedulerSuite) TestBacksOffWhenStillDue(c *gc.C) {
+	// A schedule whose run couldn't be recorded is still due after
+	// running; the scheduler must not spin on it.
+	now := s.clock.Now()
+	s.facade.nextRun = []time.Time{now, now.Add(-time.Second)}
+	s.startScheduler(c)
+	s.assertRan(c)
+
+	s.clock.Advance(59 * time.Second)
+	s.assertEmpty(c)
+	s.clock.Advance(time.Second)
+	s.assertRan(c)
+	s.assertEmpty(c)
+}
+
+func (s *SchedulerSuite) TestAdvancesOperationsOnChange(c *gc.C) {
+	s.startScheduler(c)
+	s.assertRan(c)
+
+	s.facade.opsChanges <- struct{}{}
+	s.assertReceived(c, "AdvanceOperations")
+	s.assertEmpty(c)
+}
+
+func (s *SchedulerSuite) TestAdvanceOperationsError(c *gc.C) {
+	s.facade.advanceErr = errors.New("boom")
+	w := s.startScheduler(c)
+	s.assertRan(c)
+
+	s.facade.opsChanges <- struct{}{}
+	s.assertReceived(c, "AdvanceOperations")
+	s.assertEmpty(c)
+	workertest.CheckAlive(c, w)
+	c.Assert(c.GetTestLog(), jc.Contains, "ERROR juju.worker.actionscheduler cannot advance operations: boom")
+}
+
+func (s *SchedulerSuite) TestWatchError(c *gc.C) {
+	s.facade.watchErr = errors.New("boom")
+	_, err := actionscheduler.NewScheduler(s.facade, s.clock)
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
+
+func (s *SchedulerSuite) TestWatchOperationsError(c *gc.C) {
+	s.facade.opsWatchErr = errors.New("boom")
+	s.facade.calls = make(chan string, 2)
+	_, err := actionscheduler.NewScheduler(s.facade, s.clock)
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
+
+type mockFacade struct {
+	changes     chan struct{}
+	opsChanges  chan struct{}
+	calls       chan string
+	watchErr    error
+	opsWatchErr error
+	nextRun     []time.Time
+	err         []error
+	advanceErr  error
+}
+
+func (f *mockFacade) WatchActionSchedules() (watcher.NotifyWatcher, error) {
+	f.calls <- "WatchActionSchedules"
+	if f.watchErr != nil {
+		return nil, f.watchErr
+	}
+	return watchertest.NewMockNotifyWatcher(f.changes), nil
+}
+
+func (f *mockFacade) RunDueActionSchedules() (time.Time, error) {
+	f.calls <- "RunDueActionSchedules"
+	var nextRun time.Time
+	var err error
+	if len(f.nextRun) > 0 {
+		nextRun, f.nextRun = f.nextRun[0], f.nextRun[1:]
+	}
+	if len(f.err) > 0 {
+		err, f.err = f.err[0], f.err[1:]
+	}
+	return nextRun, err
+}
+
+func (f *mockFacade) WatchOperations() (watcher.NotifyWatcher, error) {
+	f.calls <- "WatchOperations"
+	if f.opsWatchErr != nil {
+		return nil, f.opsWatchErr
+	}
+	return watchertest.NewMockNotifyWatcher(f.opsChanges), nil
+}
+
+func (f *mockFacade) AdvanceOperations() error {
+	f.calls <- "AdvanceOperations"
+	return f.advanceErr
+}
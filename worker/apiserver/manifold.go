@@ -17,6 +17,7 @@ import (
 	"github.com/juju/juju/apiserver/apiserverhttp"
 	"github.com/juju/juju/apiserver/httpcontext"
 	"github.com/juju/juju/core/auditlog"
+	"github.com/juju/juju/core/cache"
 	"github.com/juju/juju/core/lease"
 	"github.com/juju/juju/core/presence"
 	"github.com/juju/juju/state"
@@ -38,6 +39,11 @@ type ManifoldConfig struct {
 	AuditConfigUpdaterName string
 	LeaseManagerName       string
 
+	// ModelCacheName is optional. When set, facades serve watchers
+	// from the in-memory model cache instead of the database; the
+	// apiserver is then restarted whenever the cache worker is.
+	ModelCacheName string
+
 	PrometheusRegisterer              prometheus.Registerer
 	RegisterIntrospectionHTTPHandlers func(func(path string, _ http.Handler))
 	Hub                               *pubsub.StructuredHub
@@ -97,19 +103,23 @@ func (config ManifoldConfig) Validate() error {
 // worker. The manifold outputs an *apiserverhttp.Mux, for other workers
 // to register handlers against.
 func Manifold(config ManifoldConfig) dependency.Manifold {
+	inputs := []string{
+		config.AgentName,
+		config.AuthenticatorName,
+		config.ClockName,
+		config.MuxName,
+		config.RestoreStatusName,
+		config.StateName,
+		config.UpgradeGateName,
+		config.AuditConfigUpdaterName,
+		config.LeaseManagerName,
+	}
+	if config.ModelCacheName != "" {
+		inputs = append(inputs, config.ModelCacheName)
+	}
 	return dependency.Manifold{
-		Inputs: []string{
-			config.AgentName,
-			config.AuthenticatorName,
-			config.ClockName,
-			config.MuxName,
-			config.RestoreStatusName,
-			config.StateName,
-			config.UpgradeGateName,
-			config.AuditConfigUpdaterName,
-			config.LeaseManagerName,
-		},
-		Start: config.start,
+		Inputs: inputs,
+		Start:  config.start,
 	}
 }
 
@@ -163,6 +173,13 @@ func (config ManifoldConfig) start(context dependency.Context) (worker.Worker, e
 		return nil, errors.Trace(err)
 	}
 
+	var controller *cache.Controller
+	if config.ModelCacheName != "" {
+		if err := context.Get(config.ModelCacheName, &controller); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+
 	// Get the state pool after grabbing dependencies so we don't need
 	// to remember to call Done on it if they're not running yet.
 	statePool, err := stTracker.Use()
@@ -176,6 +193,7 @@ func (config ManifoldConfig) start(context dependency.Context) (worker.Worker, e
 		Mux:                               mux,
 		StatePool:                         statePool,
 		LeaseManager:                      leaseManager,
+		Controller:                        controller,
 		PrometheusRegisterer:              config.PrometheusRegisterer,
 		RegisterIntrospectionHTTPHandlers: config.RegisterIntrospectionHTTPHandlers,
 		RestoreStatus:                     restoreStatus,
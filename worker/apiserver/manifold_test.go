@@ -25,6 +25,7 @@ import (
 	"github.com/juju/juju/apiserver/httpcontext"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/core/auditlog"
+	"github.com/juju/juju/core/cache"
 	"github.com/juju/juju/core/presence"
 	"github.com/juju/juju/state"
 	coretesting "github.com/juju/juju/testing"
@@ -36,6 +37,7 @@ import (
 type ManifoldSuite struct {
 	testing.IsolationSuite
 
+	config               apiserver.ManifoldConfig
 	manifold             dependency.Manifold
 	context              dependency.Context
 	agent                *mockAgent
@@ -48,6 +50,7 @@ type ManifoldSuite struct {
 	upgradeGate          stubGateWaiter
 	auditConfig          stubAuditConfig
 	leaseManager         *lease.Manager
+	controller           *cache.Controller
 
 	stub testing.Stub
 }
@@ -66,10 +69,11 @@ func (s *ManifoldSuite) SetUpTest(c *gc.C) {
 	s.upgradeGate = stubGateWaiter{}
 	s.auditConfig = stubAuditConfig{}
 	s.leaseManager = &lease.Manager{}
+	s.controller = &cache.Controller{}
 	s.stub.ResetCalls()
 
 	s.context = s.newContext(nil)
-	s.manifold = apiserver.Manifold(apiserver.ManifoldConfig{
+	s.config = apiserver.ManifoldConfig{
 		AgentName:                         "agent",
 		AuthenticatorName:                 "authenticator",
 		ClockName:                         "clock",
@@ -79,12 +83,14 @@ func (s *ManifoldSuite) SetUpTest(c *gc.C) {
 		UpgradeGateName:                   "upgrade",
 		AuditConfigUpdaterName:            "auditconfig-updater",
 		LeaseManagerName:                  "lease-manager",
+		ModelCacheName:                    "model-cache",
 		PrometheusRegisterer:              &s.prometheusRegisterer,
 		RegisterIntrospectionHTTPHandlers: func(func(string, http.Handler)) {},
 		Hub:                               &s.hub,
 		Presence:                          presence.New(s.clock),
 		NewWorker:                         s.newWorker,
-	})
+	}
+	s.manifold = apiserver.Manifold(s.config)
 }
 
 func (s *ManifoldSuite) newContext(overlay map[string]interface{}) dependency.Context {
@@ -98,6 +104,7 @@ func (s *ManifoldSuite) newContext(overlay map[string]interface{}) dependency.Co
 		"upgrade":             &s.upgradeGate,
 		"auditconfig-updater": s.auditConfig.get,
 		"lease-manager":       s.leaseManager,
+		"model-cache":         s.controller,
 	}
 	for k, v := range overlay {
 		resources[k] = v
@@ -120,6 +127,7 @@ func (s *ManifoldSuite) newWorker(config apiserver.Config) (worker.Worker, error
 
 var expectedInputs = []string{
 	"agent", "authenticator", "clock", "mux", "restore-status", "state", "upgrade", "auditconfig-updater", "lease-manager",
+	"model-cache",
 }
 
 func (s *ManifoldSuite) TestInputs(c *gc.C) {
@@ -183,11 +191,29 @@ func (s *ManifoldSuite) TestStart(c *gc.C) {
 		Mux:                  s.mux,
 		StatePool:            &s.state.pool,
 		LeaseManager:         s.leaseManager,
+		Controller:           s.controller,
 		PrometheusRegisterer: &s.prometheusRegisterer,
 		Hub:                  &s.hub,
 	})
 }
 
+func (s *ManifoldSuite) TestStartWithoutModelCache(c *gc.C) {
+	s.config.ModelCacheName = ""
+	manifold := apiserver.Manifold(s.config)
+	c.Assert(manifold.Inputs, jc.SameContents, expectedInputs[:len(expectedInputs)-1])
+
+	context := s.newContext(map[string]interface{}{
+		"model-cache": dependency.ErrMissing,
+	})
+	w, err := manifold.Start(context)
+	c.Assert(err, jc.ErrorIsNil)
+	workertest.CleanKill(c, w)
+
+	s.stub.CheckCallNames(c, "NewWorker")
+	config := s.stub.Calls()[0].Args[0].(apiserver.Config)
+	c.Assert(config.Controller, gc.IsNil)
+}
+
 func (s *ManifoldSuite) TestStopWorkerClosesState(c *gc.C) {
 	w := s.startWorkerClean(c)
 	defer workertest.CleanKill(c, w)
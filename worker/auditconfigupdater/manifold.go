@@ -4,22 +4,48 @@
 package auditconfigupdater
 
 import (
+	"net/http"
+	"time"
+
+	"github.com/juju/clock"
 	"github.com/juju/errors"
+	"gopkg.in/juju/names.v2"
 	"gopkg.in/juju/worker.v1"
 	"gopkg.in/juju/worker.v1/dependency"
 
 	jujuagent "github.com/juju/juju/agent"
 	"github.com/juju/juju/core/auditlog"
+	"github.com/juju/juju/logfwd"
+	"github.com/juju/juju/logfwd/syslog"
+	jujuversion "github.com/juju/juju/version"
 	"github.com/juju/juju/worker/common"
 	workerstate "github.com/juju/juju/worker/state"
 )
 
+const (
+	// webhookAttempts is the number of times posting a batch of
+	// audit records is tried before it's dropped.
+	webhookAttempts = 5
+
+	// webhookRetryDelay is the initial delay between attempts to
+	// post a batch of audit records.
+	webhookRetryDelay = time.Second
+
+	// webhookTimeout bounds each webhook request.
+	webhookTimeout = 30 * time.Second
+
+	// syslogTimeout bounds connecting to the syslog host, and
+	// sending each batch of audit records to it.
+	syslogTimeout = 30 * time.Second
+)
+
 // ManifoldConfig holds the information needed to run an
 // auditconfigupdater in a dependency.Engine.
 type ManifoldConfig struct {
 	AgentName string
 	StateName string
-	NewWorker func(ConfigSource, auditlog.Config, AuditLogFactory) (worker.Worker, error)
+	Clock     clock.Clock
+	NewWorker func(ConfigSource, auditlog.Config, AuditLogFactory, clock.Clock) (worker.Worker, error)
 }
 
 // Validate validates the manifold configuration.
@@ -30,6 +56,9 @@ func (config ManifoldConfig) Validate() error {
 	if config.StateName == "" {
 		return errors.NotValidf("empty StateName")
 	}
+	if config.Clock == nil {
+		return errors.NotValidf("nil Clock")
+	}
 	if config.NewWorker == nil {
 		return errors.NotValidf("nil NewWorker")
 	}
@@ -73,13 +102,9 @@ func (config ManifoldConfig) start(context dependency.Context) (_ worker.Worker,
 		}
 	}()
 
-	logDir := agent.CurrentConfig().LogDir()
-
 	st := statePool.SystemState()
 
-	logFactory := func(cfg auditlog.Config) auditlog.AuditLog {
-		return auditlog.NewLogFile(logDir, cfg.MaxSizeMB, cfg.MaxBackups)
-	}
+	logFactory := newAuditLogFactory(agent.CurrentConfig())
 	auditConfig, err := initialConfig(st)
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -88,7 +113,7 @@ func (config ManifoldConfig) start(context dependency.Context) (_ worker.Worker,
 		auditConfig.Target = logFactory(auditConfig)
 	}
 
-	w, err := config.NewWorker(st, auditConfig, logFactory)
+	w, err := config.NewWorker(st, auditConfig, logFactory, config.Clock)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -120,12 +145,62 @@ func initialConfig(source ConfigSource) (auditlog.Config, error) {
 	if err != nil {
 		return auditlog.Config{}, errors.Trace(err)
 	}
-	result := auditlog.Config{
-		Enabled:        cfg.AuditingEnabled(),
-		CaptureAPIArgs: cfg.AuditLogCaptureArgs(),
-		MaxSizeMB:      cfg.AuditLogMaxSizeMB(),
-		MaxBackups:     cfg.AuditLogMaxBackups(),
-		ExcludeMethods: cfg.AuditLogExcludeMethods(),
+	return configFromController(cfg), nil
+}
+
+// newAuditLogFactory returns an AuditLogFactory that creates the
+// target selected by the audit log sink setting.
+func newAuditLogFactory(agentConfig jujuagent.Config) AuditLogFactory {
+	logDir := agentConfig.LogDir()
+	return func(cfg auditlog.Config) auditlog.AuditLog {
+		switch cfg.Sink {
+		case auditlog.SinkSyslog:
+			target, err := auditlog.NewSyslog(auditlog.SyslogConfig{
+				Open: func() (auditlog.LogRecordSender, error) {
+					client, err := syslog.OpenWithTimeout(cfg.Syslog, syslogTimeout)
+					if err != nil {
+						return nil, errors.Trace(err)
+					}
+					return client, nil
+				},
+				Origin:  auditOrigin(agentConfig),
+				Timeout: syslogTimeout,
+				Clock:   clock.WallClock,
+			})
+			if err == nil {
+				return target
+			}
+			logger.Errorf("creating audit log syslog sink (falling back to audit.log): %v", err)
+		case auditlog.SinkWebhook:
+			target, err := auditlog.NewWebhook(auditlog.WebhookConfig{
+				URL:           cfg.WebhookURL,
+				BatchSize:     cfg.WebhookBatchSize,
+				FlushInterval: cfg.WebhookFlushInterval,
+				Attempts:      webhookAttempts,
+				RetryDelay:    webhookRetryDelay,
+				Client:        &http.Client{Timeout: webhookTimeout},
+				Clock:         clock.WallClock,
+			})
+			if err == nil {
+				return target
+			}
+			// The controller config has already been validated, so
+			// this shouldn't happen; don't lose the records though.
+			logger.Errorf("creating audit log webhook (falling back to audit.log): %v", err)
+		}
+		return auditlog.NewLogFile(logDir, cfg.MaxSizeMB, cfg.MaxBackups)
 	}
-	return result, nil
+}
+
+// auditOrigin describes the controller agent as the source of
+// forwarded audit records.
+func auditOrigin(agentConfig jujuagent.Config) logfwd.Origin {
+	// Controller agents always run as machines.
+	tag, _ := agentConfig.Tag().(names.MachineTag)
+	return logfwd.OriginForMachineAgent(
+		tag,
+		agentConfig.Controller().Id(),
+		agentConfig.Model().Id(),
+		jujuversion.Current,
+	)
 }
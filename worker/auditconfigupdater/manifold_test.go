@@ -4,6 +4,10 @@
 package auditconfigupdater_test
 
 import (
+	"time"
+
+	"github.com/juju/clock"
+	"github.com/juju/clock/testclock"
 	"github.com/juju/collections/set"
 	"github.com/juju/errors"
 	"github.com/juju/testing"
@@ -28,6 +32,7 @@ type manifoldSuite struct {
 	context      dependency.Context
 	agent        *mockAgent
 	stateTracker stubStateTracker
+	clock        *testclock.Clock
 
 	stub testing.Stub
 }
@@ -53,10 +58,12 @@ func (s *manifoldSuite) SetUpTest(c *gc.C) {
 	s.stub.ResetCalls()
 
 	s.context = s.newContext(nil)
+	s.clock = testclock.NewClock(time.Now())
 
 	s.manifold = auditconfigupdater.Manifold(auditconfigupdater.ManifoldConfig{
 		AgentName: "agent",
 		StateName: "state",
+		Clock:     s.clock,
 		NewWorker: s.newWorker,
 	})
 }
@@ -76,8 +83,9 @@ func (s *manifoldSuite) newWorker(
 	source auditconfigupdater.ConfigSource,
 	initial auditlog.Config,
 	factory auditconfigupdater.AuditLogFactory,
+	clock clock.Clock,
 ) (worker.Worker, error) {
-	s.stub.MethodCall(s, "NewWorker", source, initial, factory)
+	s.stub.MethodCall(s, "NewWorker", source, initial, factory, clock)
 	err := s.stub.NextErr()
 	if err != nil {
 		return nil, err
@@ -111,7 +119,7 @@ func (s *manifoldSuite) TestStart(c *gc.C) {
 	s.stub.CheckCallNames(c, "NewWorker")
 
 	args := s.stub.Calls()[0].Args
-	c.Assert(args, gc.HasLen, 3)
+	c.Assert(args, gc.HasLen, 4)
 	c.Assert(args[0], gc.Equals, s.State)
 
 	auditConfig := args[1].(auditlog.Config)
@@ -121,14 +129,18 @@ func (s *manifoldSuite) TestStart(c *gc.C) {
 
 	auditConfig.Target = nil
 	c.Assert(auditConfig, gc.DeepEquals, auditlog.Config{
-		Enabled:        true,
-		CaptureAPIArgs: true,
-		ExcludeMethods: set.NewStrings("This.Method"),
-		MaxSizeMB:      10,
-		MaxBackups:     10,
+		Enabled:              true,
+		CaptureAPIArgs:       true,
+		ExcludeMethods:       set.NewStrings("This.Method"),
+		MaxSizeMB:            10,
+		MaxBackups:           10,
+		Sink:                 auditlog.SinkFile,
+		WebhookBatchSize:     100,
+		WebhookFlushInterval: 5 * time.Second,
 	})
 
 	c.Assert(args[2], gc.NotNil)
+	c.Assert(args[3], gc.Equals, s.clock)
 }
 
 func (s *manifoldSuite) TestStartWithAuditingDisabled(c *gc.C) {
@@ -143,7 +155,7 @@ func (s *manifoldSuite) TestStartWithAuditingDisabled(c *gc.C) {
 	s.stub.CheckCallNames(c, "NewWorker")
 
 	args := s.stub.Calls()[0].Args
-	c.Assert(args, gc.HasLen, 3)
+	c.Assert(args, gc.HasLen, 4)
 	c.Assert(args[0], gc.Equals, s.State)
 
 	auditConfig := args[1].(auditlog.Config)
@@ -162,7 +174,7 @@ func (s *manifoldSuite) TestOutput(c *gc.C) {
 	s.stub.CheckCallNames(c, "NewWorker")
 
 	args := s.stub.Calls()[0].Args
-	c.Assert(args, gc.HasLen, 3)
+	c.Assert(args, gc.HasLen, 4)
 	c.Assert(args[0], gc.Equals, s.State)
 
 	auditConfig := args[1].(auditlog.Config)
@@ -5,16 +5,22 @@ package auditconfigupdater
 
 import (
 	"sync"
+	"time"
 
+	"github.com/juju/clock"
 	"github.com/juju/errors"
+	"github.com/juju/loggo"
 	"gopkg.in/juju/worker.v1"
 	"gopkg.in/juju/worker.v1/catacomb"
 
 	"github.com/juju/juju/controller"
 	"github.com/juju/juju/core/auditlog"
+	"github.com/juju/juju/logfwd/syslog"
 	"github.com/juju/juju/state"
 )
 
+var logger = loggo.GetLogger("juju.worker.auditconfigupdater")
+
 // ConfigSource lets us get notifications of changes to controller
 // configuration, and then get the changed config. (Primary
 // implementation is State.)
@@ -27,12 +33,20 @@ type ConfigSource interface {
 // config.
 type AuditLogFactory func(auditlog.Config) auditlog.AuditLog
 
+// retiredTargetDrainInterval is how long a target is kept open after
+// it's been replaced. Audited API connections are made by users, so
+// most are short-lived and will have finished with the old target by
+// then; any still using it afterwards write to a closed target, and
+// the syslog and webhook sinks drop their records.
+const retiredTargetDrainInterval = 5 * time.Minute
+
 // New returns a worker that will keep an up-to-date audit log config.
-func New(source ConfigSource, initial auditlog.Config, logFactory AuditLogFactory) (worker.Worker, error) {
+func New(source ConfigSource, initial auditlog.Config, logFactory AuditLogFactory, clock clock.Clock) (worker.Worker, error) {
 	u := &updater{
 		source:     source,
 		current:    initial,
 		logFactory: logFactory,
+		clock:      clock,
 	}
 	err := catacomb.Invoke(catacomb.Plan{
 		Site: &u.catacomb,
@@ -50,6 +64,18 @@ type updater struct {
 	source     ConfigSource
 	current    auditlog.Config
 	logFactory AuditLogFactory
+	clock      clock.Clock
+
+	// retired holds targets that have been replaced, oldest first.
+	// API connections that started before the replacement still
+	// write to them, so they're closed once they've had time to
+	// drain, or when the worker stops.
+	retired []retiredTarget
+}
+
+type retiredTarget struct {
+	target  auditlog.AuditLog
+	closeAt time.Time
 }
 
 // Kill is part of the worker.Worker interface.
@@ -67,10 +93,17 @@ func (u *updater) loop() error {
 	if err := u.catacomb.Add(watcher); err != nil {
 		return errors.Trace(err)
 	}
+	defer u.closeRetired(time.Time{})
 	for {
+		var drained <-chan time.Time
+		if len(u.retired) > 0 {
+			drained = u.clock.After(u.retired[0].closeAt.Sub(u.clock.Now()))
+		}
 		select {
 		case <-u.catacomb.Dying():
 			return u.catacomb.ErrDying()
+		case <-drained:
+			u.closeRetired(u.clock.Now())
 		case _, ok := <-watcher.Changes():
 			if !ok {
 				return errors.Errorf("watcher channel closed")
@@ -89,22 +122,77 @@ func (u *updater) newConfig() (auditlog.Config, error) {
 	if err != nil {
 		return auditlog.Config{}, errors.Trace(err)
 	}
-	result := auditlog.Config{
+	result := configFromController(cfg)
+	current := u.CurrentConfig()
+	if result.Enabled && (current.Target == nil || current.TargetChanged(result)) {
+		// Auditing has been turned on for the first time, or the
+		// sink settings have changed - swap in a new target and
+		// retire the old one.
+		result.Target = u.logFactory(result)
+		if current.Target != nil {
+			u.retired = append(u.retired, retiredTarget{
+				target:  current.Target,
+				closeAt: u.clock.Now().Add(retiredTargetDrainInterval),
+			})
+		}
+	} else {
+		// Keep the existing target to avoid file handle leaks from
+		// disabling and enabling auditing - we'll still stop logging
+		// because enabled is false. We also need to keep the sink
+		// settings that the target was created with, so that
+		// changes to them are noticed when auditing is re-enabled.
+		result.Target = current.Target
+		if current.Target != nil && current.TargetChanged(result) {
+			copySinkSettings(&result, current)
+		}
+	}
+	return result, nil
+}
+
+// configFromController extracts the audit logging settings from
+// controller config. The Target isn't set.
+func configFromController(cfg controller.Config) auditlog.Config {
+	return auditlog.Config{
 		Enabled:        cfg.AuditingEnabled(),
 		CaptureAPIArgs: cfg.AuditLogCaptureArgs(),
 		MaxSizeMB:      cfg.AuditLogMaxSizeMB(),
 		MaxBackups:     cfg.AuditLogMaxBackups(),
 		ExcludeMethods: cfg.AuditLogExcludeMethods(),
+		Sink:           cfg.AuditLogSink(),
+		Syslog: syslog.RawConfig{
+			Enabled:    cfg.AuditLogSink() == controller.AuditLogSinkSyslog,
+			Host:       cfg.AuditLogSyslogHost(),
+			CACert:     cfg.AuditLogSyslogCACert(),
+			ClientCert: cfg.AuditLogSyslogClientCert(),
+			ClientKey:  cfg.AuditLogSyslogClientKey(),
+		},
+		WebhookURL:           cfg.AuditLogWebhookURL(),
+		WebhookBatchSize:     cfg.AuditLogWebhookBatchSize(),
+		WebhookFlushInterval: cfg.AuditLogWebhookFlushInterval(),
 	}
-	if result.Enabled && u.current.Target == nil {
-		result.Target = u.logFactory(result)
-	} else {
-		// Keep the existing target to avoid file handle leaks from
-		// disabling and enabling auditing - we'll still stop logging
-		// because enabled is false.
-		result.Target = u.current.Target
+}
+
+func copySinkSettings(to *auditlog.Config, from auditlog.Config) {
+	to.Sink = from.Sink
+	to.Syslog = from.Syslog
+	to.WebhookURL = from.WebhookURL
+	to.WebhookBatchSize = from.WebhookBatchSize
+	to.WebhookFlushInterval = from.WebhookFlushInterval
+}
+
+// closeRetired closes the replaced targets that were due to be closed
+// by the given time, or all of them if it's zero.
+func (u *updater) closeRetired(now time.Time) {
+	for len(u.retired) > 0 {
+		retired := u.retired[0]
+		if !now.IsZero() && retired.closeAt.After(now) {
+			return
+		}
+		if err := retired.target.Close(); err != nil {
+			logger.Warningf("closing previous audit log target: %v", err)
+		}
+		u.retired = u.retired[1:]
 	}
-	return result, nil
 }
 
 func (u *updater) update(newConfig auditlog.Config) {
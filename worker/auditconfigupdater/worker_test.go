@@ -6,7 +6,9 @@ package auditconfigupdater_test
 import (
 	"reflect"
 	"sync"
+	"time"
 
+	"github.com/juju/clock/testclock"
 	"github.com/juju/collections/set"
 	"github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
@@ -25,10 +27,17 @@ import (
 
 type updaterSuite struct {
 	jujutesting.BaseSuite
+
+	clock *testclock.Clock
 }
 
 var _ = gc.Suite(&updaterSuite{})
 
+func (s *updaterSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.clock = testclock.NewClock(time.Now())
+}
+
 var ding = struct{}{}
 
 func (s *updaterSuite) TestWorker(c *gc.C) {
@@ -48,7 +57,7 @@ func (s *updaterSuite) TestWorker(c *gc.C) {
 		return &fakeTarget
 	}
 
-	w, err := auditconfigupdater.New(&source, initial, factory)
+	w, err := auditconfigupdater.New(&source, initial, factory, s.clock)
 	c.Assert(err, jc.ErrorIsNil)
 	defer workertest.CleanKill(c, w)
 
@@ -90,7 +99,7 @@ func (s *updaterSuite) TestKeepsLogFileWhenAuditingDisabled(c *gc.C) {
 
 	// Passing a nil factory means we can be sure it didn't try to
 	// create a new logfile.
-	w, err := auditconfigupdater.New(&source, initial, nil)
+	w, err := auditconfigupdater.New(&source, initial, nil, s.clock)
 	c.Assert(err, jc.ErrorIsNil)
 	defer workertest.CleanKill(c, w)
 
@@ -118,7 +127,7 @@ func (s *updaterSuite) TestKeepsLogFileWhenEnabled(c *gc.C) {
 
 	// Passing a nil factory means we can be sure it didn't try to
 	// create a new logfile.
-	w, err := auditconfigupdater.New(&source, initial, nil)
+	w, err := auditconfigupdater.New(&source, initial, nil, s.clock)
 	c.Assert(err, jc.ErrorIsNil)
 	defer workertest.CleanKill(c, w)
 
@@ -145,7 +154,7 @@ func (s *updaterSuite) TestChangingExcludeMethod(c *gc.C) {
 		cfg:     makeControllerConfig(true, false, "Pink.Floyd"),
 	}
 
-	w, err := auditconfigupdater.New(&source, initial, nil)
+	w, err := auditconfigupdater.New(&source, initial, nil, s.clock)
 	c.Assert(err, jc.ErrorIsNil)
 	defer workertest.CleanKill(c, w)
 
@@ -176,7 +185,7 @@ func (s *updaterSuite) TestChangingCaptureArgs(c *gc.C) {
 		cfg:     makeControllerConfig(true, false, "Pink.Floyd"),
 	}
 
-	w, err := auditconfigupdater.New(&source, initial, nil)
+	w, err := auditconfigupdater.New(&source, initial, nil, s.clock)
 	c.Assert(err, jc.ErrorIsNil)
 	defer workertest.CleanKill(c, w)
 
@@ -188,6 +197,117 @@ func (s *updaterSuite) TestChangingCaptureArgs(c *gc.C) {
 	})
 }
 
+func (s *updaterSuite) TestChangingSinkSwapsTarget(c *gc.C) {
+	configChanged := make(chan struct{}, 1)
+	oldTarget := &closeCountingAuditLog{}
+	initial := auditlog.Config{
+		Enabled: true,
+		Sink:    auditlog.SinkFile,
+		Target:  oldTarget,
+	}
+	source := configSource{
+		watcher: watchertest.NewNotifyWatcher(configChanged),
+		cfg:     makeControllerConfig(true, false),
+	}
+
+	newTarget := &closeCountingAuditLog{}
+	var calls []auditlog.Config
+	factory := func(cfg auditlog.Config) auditlog.AuditLog {
+		calls = append(calls, cfg)
+		return newTarget
+	}
+
+	w, err := auditconfigupdater.New(&source, initial, factory, s.clock)
+	c.Assert(err, jc.ErrorIsNil)
+	defer workertest.CleanKill(c, w)
+
+	cfg := makeControllerConfig(true, false)
+	cfg["audit-log-sink"] = "webhook"
+	cfg["audit-log-webhook-url"] = "https://audit.example.com/records"
+	source.setConfig(cfg)
+	configChanged <- ding
+
+	newConfig := waitForConfig(c, w, func(cfg auditlog.Config) bool {
+		return cfg.Sink == auditlog.SinkWebhook
+	})
+
+	c.Assert(newConfig.Target, gc.Equals, auditlog.AuditLog(newTarget))
+	c.Assert(newConfig.WebhookURL, gc.Equals, "https://audit.example.com/records")
+	c.Assert(calls, gc.HasLen, 1)
+	c.Assert(calls[0].Sink, gc.Equals, auditlog.SinkWebhook)
+
+	// Connections opened before the change may still be using the
+	// old target, so it's only closed once they've had time to
+	// finish with it.
+	c.Assert(oldTarget.closeCount(), gc.Equals, 0)
+	err = s.clock.WaitAdvance(5*time.Minute-time.Second, jujutesting.LongWait, 1)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(oldTarget.closeCount(), gc.Equals, 0)
+	err = s.clock.WaitAdvance(time.Second, jujutesting.LongWait, 1)
+	c.Assert(err, jc.ErrorIsNil)
+	for a := jujutesting.LongAttempt.Start(); a.Next(); {
+		if oldTarget.closeCount() > 0 {
+			break
+		}
+	}
+	c.Assert(oldTarget.closeCount(), gc.Equals, 1)
+
+	workertest.CleanKill(c, w)
+	c.Assert(oldTarget.closeCount(), gc.Equals, 1)
+	c.Assert(newTarget.closeCount(), gc.Equals, 0)
+}
+
+func (s *updaterSuite) TestChangingSinkWhileDisabled(c *gc.C) {
+	configChanged := make(chan struct{}, 1)
+	oldTarget := &closeCountingAuditLog{}
+	initial := auditlog.Config{
+		Enabled: true,
+		Sink:    auditlog.SinkFile,
+		Target:  oldTarget,
+	}
+	source := configSource{
+		watcher: watchertest.NewNotifyWatcher(configChanged),
+		cfg:     makeControllerConfig(true, false),
+	}
+
+	newTarget := &closeCountingAuditLog{}
+	factory := func(cfg auditlog.Config) auditlog.AuditLog {
+		return newTarget
+	}
+
+	w, err := auditconfigupdater.New(&source, initial, factory, s.clock)
+	c.Assert(err, jc.ErrorIsNil)
+	defer workertest.CleanKill(c, w)
+
+	// Changing the sink while auditing is disabled leaves the old
+	// target alone...
+	cfg := makeControllerConfig(false, false)
+	cfg["audit-log-sink"] = "webhook"
+	cfg["audit-log-webhook-url"] = "https://audit.example.com/records"
+	source.setConfig(cfg)
+	configChanged <- ding
+
+	newConfig := waitForConfig(c, w, func(cfg auditlog.Config) bool {
+		return !cfg.Enabled
+	})
+	c.Assert(newConfig.Target, gc.Equals, auditlog.AuditLog(oldTarget))
+
+	// ...until it's enabled again.
+	cfg = makeControllerConfig(true, false)
+	cfg["audit-log-sink"] = "webhook"
+	cfg["audit-log-webhook-url"] = "https://audit.example.com/records"
+	source.setConfig(cfg)
+	configChanged <- ding
+
+	newConfig = waitForConfig(c, w, func(cfg auditlog.Config) bool {
+		return cfg.Enabled
+	})
+	c.Assert(newConfig.Target, gc.Equals, auditlog.AuditLog(newTarget))
+	c.Assert(newConfig.Sink, gc.Equals, auditlog.SinkWebhook)
+	workertest.CleanKill(c, w)
+	c.Assert(oldTarget.closeCount(), gc.Equals, 1)
+}
+
 func makeControllerConfig(auditEnabled bool, captureArgs bool, methods ...interface{}) controller.Config {
 	result := map[string]interface{}{
 		"other-setting":             "something",
@@ -235,3 +355,22 @@ func (s *configSource) setConfig(cfg controller.Config) {
 	defer s.mu.Unlock()
 	s.cfg = cfg
 }
+
+type closeCountingAuditLog struct {
+	apitesting.FakeAuditLog
+	mu     sync.Mutex
+	closed int
+}
+
+func (l *closeCountingAuditLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.closed++
+	return nil
+}
+
+func (l *closeCountingAuditLog) closeCount() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.closed
+}
@@ -0,0 +1,210 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backupscheduler
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/juju/collections/set"
+	"github.com/juju/errors"
+	"gopkg.in/juju/worker.v1"
+	"gopkg.in/tomb.v2"
+)
+
+// copyRetryDelay is how long the copier waits before trying again
+// after failing to bring the copy directory up to date.
+const copyRetryDelay = 5 * time.Minute
+
+const (
+	copyPrefix = "juju-backup-"
+	copySuffix = ".tar.gz"
+)
+
+// copyPath returns the path of the copy of the backup with the ID.
+func copyPath(dir, id string) string {
+	return filepath.Join(dir, fmt.Sprintf("%s%s%s", copyPrefix, id, copySuffix))
+}
+
+// NewCopier returns a worker which keeps the copy directory in the
+// controller configuration in step with the scheduled backups stored
+// in the controller: missing backups are copied to it, and copies of
+// backups that have since been removed are deleted. It runs on every
+// controller machine, so that the copies stay current whichever
+// machine makes the backups.
+func NewCopier(config Config) (worker.Worker, error) {
+	if err := config.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	w := &copier{
+		config: config,
+	}
+	w.tomb.Go(w.loop)
+	return w, nil
+}
+
+type copier struct {
+	tomb   tomb.Tomb
+	config Config
+}
+
+func (w *copier) loop() error {
+	controllerConfigWatcher := w.config.Backend.WatchControllerConfig()
+	defer worker.Stop(controllerConfigWatcher)
+	statusWatcher := w.config.Backend.WatchBackupScheduleStatus()
+	defer worker.Stop(statusWatcher)
+
+	var (
+		configured bool
+		copyDir    string
+		retry      <-chan time.Time
+	)
+	for {
+		select {
+		case <-w.tomb.Dying():
+			return tomb.ErrDying
+
+		case _, ok := <-controllerConfigWatcher.Changes():
+			if !ok {
+				return errors.New("controller configuration watcher closed")
+			}
+			controllerConfig, err := w.config.Backend.ControllerConfig()
+			if err != nil {
+				return errors.Annotate(err, "cannot load controller configuration")
+			}
+			configured = true
+			copyDir = controllerConfig.BackupCopyDir()
+
+		case _, ok := <-statusWatcher.Changes():
+			if !ok {
+				return errors.New("backup schedule status watcher closed")
+			}
+
+		case <-retry:
+		}
+		if !configured || copyDir == "" {
+			continue
+		}
+		retry = nil
+		if err := w.sync(copyDir); err == tomb.ErrDying {
+			return err
+		} else if err != nil {
+			logger.Errorf("cannot update copies of scheduled backups in %q: %v", copyDir, err)
+			retry = w.config.Clock.After(copyRetryDelay)
+		}
+	}
+}
+
+// sync copies the stored scheduled backups missing from dir, and
+// removes the copies of those no longer stored along with any partial
+// copies.
+func (w *copier) sync(dir string) error {
+	all, err := w.config.Backups.List()
+	if err != nil {
+		return errors.Annotate(err, "listing backups")
+	}
+	stored := set.NewStrings()
+	for _, meta := range all {
+		if meta.Scheduled {
+			stored.Add(meta.ID())
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return errors.Trace(err)
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, copyPrefix+"*"+copySuffix+"*"))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	copied := set.NewStrings()
+	for _, path := range paths {
+		id := strings.TrimPrefix(filepath.Base(path), copyPrefix)
+		if strings.HasSuffix(id, copySuffix) {
+			id = strings.TrimSuffix(id, copySuffix)
+			if stored.Contains(id) {
+				copied.Add(id)
+				continue
+			}
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return errors.Trace(err)
+		}
+		logger.Infof("removed %q", path)
+	}
+
+	for _, id := range stored.Difference(copied).SortedValues() {
+		select {
+		case <-w.tomb.Dying():
+			return tomb.ErrDying
+		default:
+		}
+		if err := w.copyBackup(id, dir); err != nil {
+			return errors.Annotatef(err, "copying backup %q", id)
+		}
+		logger.Infof("copied scheduled backup %q to %q", id, dir)
+	}
+	return nil
+}
+
+// copyBackup writes the archive of the backup with the ID to dir.
+func (w *copier) copyBackup(id, dir string) error {
+	_, archive, err := w.config.Backups.Get(id)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer archive.Close()
+
+	// Write to a temporary file first, so that a partial copy is
+	// never mistaken for a complete one.
+	path := copyPath(dir, id)
+	tempPath := path + ".tmp"
+	f, err := os.OpenFile(tempPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	_, err = io.Copy(f, &abortableReader{archive, w.tomb.Dying()})
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tempPath)
+		if err == tomb.ErrDying {
+			return err
+		}
+		return errors.Trace(err)
+	}
+	return errors.Trace(os.Rename(tempPath, path))
+}
+
+// abortableReader stops reading once abort is closed, so that copying
+// a large archive doesn't hold up stopping the worker.
+type abortableReader struct {
+	io.Reader
+	abort <-chan struct{}
+}
+
+// Read implements io.Reader.
+func (r *abortableReader) Read(p []byte) (int, error) {
+	select {
+	case <-r.abort:
+		return 0, tomb.ErrDying
+	default:
+	}
+	return r.Reader.Read(p)
+}
+
+// Kill implements Worker.Kill().
+func (w *copier) Kill() {
+	w.tomb.Kill(nil)
+}
+
+// Wait implements Worker.Wait().
+func (w *copier) Wait() error {
+	return w.tomb.Wait()
+}
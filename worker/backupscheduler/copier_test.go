@@ -0,0 +1,155 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backupscheduler_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/juju/clock/testclock"
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/worker.v1/workertest"
+
+	"github.com/juju/juju/controller"
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/backupscheduler"
+)
+
+type CopierSuite struct {
+	testing.IsolationSuite
+	clock   *testclock.Clock
+	now     time.Time
+	backend *fakeBackend
+	backups *fakeBackups
+	copyDir string
+}
+
+var _ = gc.Suite(&CopierSuite{})
+
+func (s *CopierSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.now = time.Date(2018, 7, 1, 10, 30, 0, 0, time.UTC)
+	s.clock = testclock.NewClock(s.now)
+	s.copyDir = c.MkDir()
+	s.backend = &fakeBackend{
+		changes:       make(chan struct{}, 1),
+		statusChanges: make(chan struct{}, 1),
+		statusSet:     make(chan state.BackupScheduleStatus, 10),
+		config: controller.Config{
+			controller.BackupSchedule: "@daily",
+			controller.BackupCopyDir:  s.copyDir,
+		},
+	}
+	s.backups = &fakeBackups{
+		clock:   s.clock,
+		removed: make(chan string, 10),
+	}
+}
+
+func (s *CopierSuite) startCopier(c *gc.C) {
+	w, err := backupscheduler.NewCopier(backupscheduler.Config{
+		Backend: s.backend,
+		Backups: s.backups,
+		Clock:   s.clock,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	s.AddCleanup(func(c *gc.C) { workertest.CleanKill(c, w) })
+	s.backend.changes <- struct{}{}
+	s.backend.statusChanges <- struct{}{}
+}
+
+func (s *CopierSuite) writeFile(c *gc.C, name, content string) {
+	err := ioutil.WriteFile(filepath.Join(s.copyDir, name), []byte(content), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+// waitForFiles waits until the copy directory holds exactly the files
+// with the names and contents in expect.
+func (s *CopierSuite) waitForFiles(c *gc.C, expect map[string]string) {
+	var found map[string]string
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		infos, err := ioutil.ReadDir(s.copyDir)
+		c.Assert(err, jc.ErrorIsNil)
+		found = make(map[string]string)
+		for _, info := range infos {
+			data, err := ioutil.ReadFile(filepath.Join(s.copyDir, info.Name()))
+			c.Assert(err, jc.ErrorIsNil)
+			found[info.Name()] = string(data)
+		}
+		if len(found) == len(expect) {
+			matched := true
+			for name, content := range expect {
+				if found[name] != content {
+					matched = false
+				}
+			}
+			if matched {
+				return
+			}
+		}
+	}
+	c.Fatalf("copy directory holds %v, expected %v", found, expect)
+}
+
+func (s *CopierSuite) TestValidate(c *gc.C) {
+	_, err := backupscheduler.NewCopier(backupscheduler.Config{
+		Backend: s.backend,
+		Clock:   s.clock,
+	})
+	c.Check(err, gc.ErrorMatches, "nil Backups not valid")
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *CopierSuite) TestCopiesScheduledBackups(c *gc.C) {
+	s.backups.add("kept", s.now.Add(-48*time.Hour), true)
+	s.backups.add("new", s.now.Add(-24*time.Hour), true)
+	s.backups.add("manual", s.now.Add(-24*time.Hour), false)
+	s.writeFile(c, "juju-backup-kept.tar.gz", "existing copy")
+	s.writeFile(c, "juju-backup-new.tar.gz.tmp", "partial copy")
+	s.writeFile(c, "juju-backup-removed.tar.gz", "stale copy")
+	s.writeFile(c, "notes.txt", "not a copy")
+
+	s.startCopier(c)
+	s.waitForFiles(c, map[string]string{
+		"juju-backup-kept.tar.gz": "existing copy",
+		"juju-backup-new.tar.gz":  "archive of new",
+		"notes.txt":               "not a copy",
+	})
+}
+
+func (s *CopierSuite) TestCopiesWhenStatusChanges(c *gc.C) {
+	s.backups.add("first", s.now.Add(-24*time.Hour), true)
+	s.startCopier(c)
+	s.waitForFiles(c, map[string]string{
+		"juju-backup-first.tar.gz": "archive of first",
+	})
+
+	// The scheduler on another controller machine makes a new
+	// backup, and removes the old one.
+	s.backups.mu.Lock()
+	s.backups.stored = nil
+	s.backups.add("second", s.now, true)
+	s.backups.mu.Unlock()
+	s.backend.statusChanges <- struct{}{}
+	s.waitForFiles(c, map[string]string{
+		"juju-backup-second.tar.gz": "archive of second",
+	})
+}
+
+func (s *CopierSuite) TestRetriesAfterFailure(c *gc.C) {
+	s.backups.add("first", s.now.Add(-24*time.Hour), true)
+	s.backups.listErr = errors.New("no reachable servers")
+
+	s.startCopier(c)
+	err := s.clock.WaitAdvance(5*time.Minute, coretesting.LongWait, 1)
+	c.Assert(err, jc.ErrorIsNil)
+	s.waitForFiles(c, map[string]string{
+		"juju-backup-first.tar.gz": "archive of first",
+	})
+}
@@ -0,0 +1,18 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backupscheduler
+
+// TrackedBackups is a Backups whose Close waits for the calls in
+// progress to return.
+type TrackedBackups struct {
+	*trackedBackups
+}
+
+func NewTrackedBackups(backups Backups) TrackedBackups {
+	return TrackedBackups{newTrackedBackups(backups)}
+}
+
+func (t TrackedBackups) Close() {
+	t.close()
+}
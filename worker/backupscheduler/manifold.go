@@ -0,0 +1,217 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backupscheduler
+
+import (
+	"io"
+	"sync"
+
+	"github.com/juju/clock"
+	"github.com/juju/errors"
+	"gopkg.in/juju/worker.v1"
+	"gopkg.in/juju/worker.v1/dependency"
+
+	jujuagent "github.com/juju/juju/agent"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/backups"
+	workerstate "github.com/juju/juju/worker/state"
+)
+
+// ManifoldConfig holds the information necessary to run a backup
+// scheduler or copier worker in a dependency.Engine.
+type ManifoldConfig struct {
+	AgentName string
+	ClockName string
+	StateName string
+
+	NewBackups func(*state.State, jujuagent.Config) (Backups, error)
+	NewWorker  func(Config) (worker.Worker, error)
+}
+
+// Validate returns an error if the config cannot be used to start the
+// manifold.
+func (config ManifoldConfig) Validate() error {
+	if config.AgentName == "" {
+		return errors.NotValidf("empty AgentName")
+	}
+	if config.ClockName == "" {
+		return errors.NotValidf("empty ClockName")
+	}
+	if config.StateName == "" {
+		return errors.NotValidf("empty StateName")
+	}
+	if config.NewBackups == nil {
+		return errors.NotValidf("nil NewBackups")
+	}
+	if config.NewWorker == nil {
+		return errors.NotValidf("nil NewWorker")
+	}
+	return nil
+}
+
+// Manifold returns a dependency.Manifold that will run the worker
+// returned by NewWorker, which is NewWorker or NewCopier.
+func Manifold(config ManifoldConfig) dependency.Manifold {
+	return dependency.Manifold{
+		Inputs: []string{
+			config.AgentName,
+			config.ClockName,
+			config.StateName,
+		},
+		Start: config.start,
+	}
+}
+
+// start is a method on ManifoldConfig because it's more readable than a closure.
+func (config ManifoldConfig) start(context dependency.Context) (worker.Worker, error) {
+	if err := config.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	var agent jujuagent.Agent
+	if err := context.Get(config.AgentName, &agent); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	var clock clock.Clock
+	if err := context.Get(config.ClockName, &clock); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	var stTracker workerstate.StateTracker
+	if err := context.Get(config.StateName, &stTracker); err != nil {
+		return nil, errors.Trace(err)
+	}
+	statePool, err := stTracker.Use()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	st := statePool.SystemState()
+	backups, err := config.NewBackups(st, agent.CurrentConfig())
+	if err != nil {
+		stTracker.Done()
+		return nil, errors.Trace(err)
+	}
+	tracked := newTrackedBackups(backups)
+	worker, err := config.NewWorker(Config{
+		Backend: st,
+		Backups: tracked,
+		Clock:   clock,
+	})
+	if err != nil {
+		stTracker.Done()
+		return nil, errors.Trace(err)
+	}
+
+	go func() {
+		worker.Wait()
+		// A backup may still be running after the worker has
+		// stopped; the state must stay open until it finishes.
+		tracked.close()
+		stTracker.Done()
+	}()
+	return worker, nil
+}
+
+// trackedBackups counts the calls in progress on a Backups, so that
+// the state they use is released only once they have all returned.
+type trackedBackups struct {
+	backups Backups
+
+	mu     sync.Mutex
+	idle   *sync.Cond
+	calls  int
+	closed bool
+}
+
+func newTrackedBackups(backups Backups) *trackedBackups {
+	t := &trackedBackups{backups: backups}
+	t.idle = sync.NewCond(&t.mu)
+	return t
+}
+
+func (t *trackedBackups) start() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return errors.New("backups closed")
+	}
+	t.calls++
+	return nil
+}
+
+func (t *trackedBackups) done() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.calls--
+	if t.calls == 0 {
+		t.idle.Broadcast()
+	}
+}
+
+// close refuses any further calls, and waits for those in progress to
+// return.
+func (t *trackedBackups) close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	for t.calls > 0 {
+		t.idle.Wait()
+	}
+}
+
+// Create implements Backups.
+func (t *trackedBackups) Create() (*backups.Metadata, error) {
+	if err := t.start(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer t.done()
+	return t.backups.Create()
+}
+
+// Get implements Backups. The call is in progress until the archive
+// is closed.
+func (t *trackedBackups) Get(id string) (*backups.Metadata, io.ReadCloser, error) {
+	if err := t.start(); err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	meta, archive, err := t.backups.Get(id)
+	if err != nil {
+		t.done()
+		return nil, nil, err
+	}
+	return meta, &trackedArchive{ReadCloser: archive, done: t.done}, nil
+}
+
+// List implements Backups.
+func (t *trackedBackups) List() ([]*backups.Metadata, error) {
+	if err := t.start(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer t.done()
+	return t.backups.List()
+}
+
+// Remove implements Backups.
+func (t *trackedBackups) Remove(id string) error {
+	if err := t.start(); err != nil {
+		return errors.Trace(err)
+	}
+	defer t.done()
+	return t.backups.Remove(id)
+}
+
+type trackedArchive struct {
+	io.ReadCloser
+	once sync.Once
+	done func()
+}
+
+// Close implements io.Closer.
+func (a *trackedArchive) Close() error {
+	err := a.ReadCloser.Close()
+	a.once.Do(a.done)
+	return err
+}
@@ -0,0 +1,153 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backupscheduler_test
+
+import (
+	"time"
+
+	"github.com/juju/clock/testclock"
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/worker.v1"
+
+	"github.com/juju/juju/agent"
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/backupscheduler"
+)
+
+type ManifoldSuite struct {
+	testing.IsolationSuite
+	config backupscheduler.ManifoldConfig
+}
+
+var _ = gc.Suite(&ManifoldSuite{})
+
+func (s *ManifoldSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.config = backupscheduler.ManifoldConfig{
+		AgentName: "agent",
+		ClockName: "clock",
+		StateName: "state",
+		NewBackups: func(*state.State, agent.Config) (backupscheduler.Backups, error) {
+			return nil, errors.New("unused")
+		},
+		NewWorker: func(backupscheduler.Config) (worker.Worker, error) {
+			return nil, errors.New("unused")
+		},
+	}
+}
+
+func (s *ManifoldSuite) TestValid(c *gc.C) {
+	c.Check(s.config.Validate(), jc.ErrorIsNil)
+}
+
+func (s *ManifoldSuite) TestInputs(c *gc.C) {
+	manifold := backupscheduler.Manifold(s.config)
+	c.Check(manifold.Inputs, jc.SameContents, []string{"agent", "clock", "state"})
+}
+
+func (s *ManifoldSuite) TestMissingAgentName(c *gc.C) {
+	s.config.AgentName = ""
+	s.checkNotValid(c, "empty AgentName not valid")
+}
+
+func (s *ManifoldSuite) TestMissingClockName(c *gc.C) {
+	s.config.ClockName = ""
+	s.checkNotValid(c, "empty ClockName not valid")
+}
+
+func (s *ManifoldSuite) TestMissingStateName(c *gc.C) {
+	s.config.StateName = ""
+	s.checkNotValid(c, "empty StateName not valid")
+}
+
+func (s *ManifoldSuite) TestMissingNewBackups(c *gc.C) {
+	s.config.NewBackups = nil
+	s.checkNotValid(c, "nil NewBackups not valid")
+}
+
+func (s *ManifoldSuite) TestMissingNewWorker(c *gc.C) {
+	s.config.NewWorker = nil
+	s.checkNotValid(c, "nil NewWorker not valid")
+}
+
+func (s *ManifoldSuite) checkNotValid(c *gc.C, expect string) {
+	err := s.config.Validate()
+	c.Check(err, gc.ErrorMatches, expect)
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *ManifoldSuite) TestTrackedBackupsCloseWaitsForCalls(c *gc.C) {
+	backups := &fakeBackups{
+		clock:    testclock.NewClock(time.Now()),
+		creating: make(chan struct{}, 1),
+		release:  make(chan struct{}),
+	}
+	tracked := backupscheduler.NewTrackedBackups(backups)
+	created := make(chan error, 1)
+	go func() {
+		_, err := tracked.Create()
+		created <- err
+	}()
+	select {
+	case <-backups.creating:
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for backup")
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		tracked.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+		c.Fatalf("closed while a backup was running")
+	case <-time.After(coretesting.ShortWait):
+	}
+
+	close(backups.release)
+	select {
+	case err := <-created:
+		c.Check(err, jc.ErrorIsNil)
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for backup")
+	}
+	select {
+	case <-closed:
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for close")
+	}
+
+	_, err := tracked.List()
+	c.Check(err, gc.ErrorMatches, "backups closed")
+}
+
+func (s *ManifoldSuite) TestTrackedBackupsArchiveHeldUntilClosed(c *gc.C) {
+	backups := &fakeBackups{clock: testclock.NewClock(time.Now())}
+	backups.add("kept", time.Now(), true)
+	tracked := backupscheduler.NewTrackedBackups(backups)
+	_, archive, err := tracked.Get("kept")
+	c.Assert(err, jc.ErrorIsNil)
+
+	closed := make(chan struct{})
+	go func() {
+		tracked.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+		c.Fatalf("closed while an archive was open")
+	case <-time.After(coretesting.ShortWait):
+	}
+	c.Assert(archive.Close(), jc.ErrorIsNil)
+	select {
+	case <-closed:
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for close")
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backupscheduler
+
+import (
+	"io"
+
+	"github.com/juju/errors"
+	"github.com/juju/replicaset"
+
+	"github.com/juju/juju/agent"
+	"github.com/juju/juju/mongo"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/backups"
+)
+
+// stateShim provides the database of the controller model to
+// state/backups.
+type stateShim struct {
+	*state.State
+	*state.Model
+}
+
+// NewBackups returns a Backups that backs up the controller the agent
+// is running on, in the same way as the backups facade does.
+func NewBackups(st *state.State, agentConfig agent.Config) (Backups, error) {
+	model, err := st.Model()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &stateBackups{
+		st:          &stateShim{st, model},
+		agentConfig: agentConfig,
+	}, nil
+}
+
+type stateBackups struct {
+	st          *stateShim
+	agentConfig agent.Config
+}
+
+func (b *stateBackups) open() (backups.Backups, io.Closer) {
+	stor := backups.NewStorage(b.st)
+	return backups.NewBackups(stor), stor
+}
+
+// Create implements Backups.
+func (b *stateBackups) Create() (*backups.Metadata, error) {
+	backupsMethods, closer := b.open()
+	defer closer.Close()
+
+	session := b.st.MongoSession().Copy()
+	defer session.Close()
+
+	// Don't go if HA isn't ready.
+	if err := replicaset.WaitUntilReady(session, 60); err != nil {
+		return nil, errors.Annotatef(err, "HA not ready")
+	}
+
+	mgoInfo, ok := b.agentConfig.MongoInfo()
+	if !ok {
+		return nil, errors.New("no mongo info found in agent config")
+	}
+	v, err := b.st.MongoVersion()
+	if err != nil {
+		return nil, errors.Annotatef(err, "discovering mongo version")
+	}
+	mongoVersion, err := mongo.NewVersion(v)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	dbInfo, err := backups.NewDBInfo(mgoInfo, session, mongoVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	machineID := b.agentConfig.Tag().Id()
+	machine, err := b.st.Machine(machineID)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	meta, err := backups.NewMetadataState(b.st, machineID, machine.Series())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	meta.Scheduled = true
+
+	modelConfig, err := b.st.ModelConfig()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	paths := backups.Paths{
+		BackupDir: modelConfig.BackupDir(),
+		DataDir:   b.agentConfig.DataDir(),
+		LogsDir:   b.agentConfig.LogDir(),
+	}
+	// Scheduled backups are always kept in the controller, and never
+	// left around for download.
+	if _, err := backupsMethods.Create(meta, &paths, dbInfo, true, true, nil); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return meta, nil
+}
+
+// Get implements Backups.
+func (b *stateBackups) Get(id string) (*backups.Metadata, io.ReadCloser, error) {
+	backupsMethods, closer := b.open()
+	meta, archive, err := backupsMethods.Get(id)
+	if err != nil {
+		closer.Close()
+		return nil, nil, errors.Trace(err)
+	}
+	// The archive is read from the storage, so the storage stays
+	// open until the archive is closed.
+	return meta, &archiveCloser{archive, closer}, nil
+}
+
+type archiveCloser struct {
+	io.ReadCloser
+	storage io.Closer
+}
+
+// Close implements io.Closer.
+func (a *archiveCloser) Close() error {
+	err := a.ReadCloser.Close()
+	a.storage.Close()
+	return err
+}
+
+// List implements Backups.
+func (b *stateBackups) List() ([]*backups.Metadata, error) {
+	backupsMethods, closer := b.open()
+	defer closer.Close()
+	return backupsMethods.List()
+}
+
+// Remove implements Backups.
+func (b *stateBackups) Remove(id string) error {
+	backupsMethods, closer := b.open()
+	defer closer.Close()
+	return backupsMethods.Remove(id)
+}
@@ -0,0 +1,350 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backupscheduler
+
+import (
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/juju/clock"
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"gopkg.in/juju/worker.v1"
+	"gopkg.in/tomb.v2"
+
+	"github.com/juju/juju/controller"
+	"github.com/juju/juju/core/actions"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/backups"
+)
+
+var logger = loggo.GetLogger("juju.worker.backupscheduler")
+
+// Backend provides the controller configuration that drives the
+// backup schedule, and records the schedule's progress.
+type Backend interface {
+	ControllerConfig() (controller.Config, error)
+	WatchControllerConfig() state.NotifyWatcher
+	BackupScheduleStatus() (state.BackupScheduleStatus, error)
+	SetBackupScheduleStatus(state.BackupScheduleStatus) error
+	WatchBackupScheduleStatus() state.NotifyWatcher
+}
+
+// Backups creates, lists and removes the controller's backups.
+type Backups interface {
+	// Create creates and stores a new scheduled backup, returning
+	// its metadata.
+	Create() (*backups.Metadata, error)
+
+	// Get returns the metadata and archive of the backup with the ID.
+	Get(id string) (*backups.Metadata, io.ReadCloser, error)
+
+	// List returns the metadata of all stored backups.
+	List() ([]*backups.Metadata, error)
+
+	// Remove removes the backup with the ID.
+	Remove(id string) error
+}
+
+// Config holds the dependencies of a backup scheduler or copier worker.
+type Config struct {
+	Backend Backend
+	Backups Backups
+	Clock   clock.Clock
+}
+
+// Validate returns an error if the config cannot be used to start a
+// backup scheduler or copier worker.
+func (config Config) Validate() error {
+	if config.Backend == nil {
+		return errors.NotValidf("nil Backend")
+	}
+	if config.Backups == nil {
+		return errors.NotValidf("nil Backups")
+	}
+	if config.Clock == nil {
+		return errors.NotValidf("nil Clock")
+	}
+	return nil
+}
+
+// NewWorker returns a worker which backs up the controller on the
+// schedule in its configuration, and removes scheduled backups beyond
+// the retention limits. The progress of the schedule is recorded in
+// the controller, so that it survives the worker moving to another
+// controller machine. This worker must not be run in more than one
+// agent concurrently.
+//
+// A backup can take a long time, so it runs apart from the worker,
+// which doesn't wait for it to finish when stopped; the Backups must
+// stay usable until any call made on them returns.
+func NewWorker(config Config) (worker.Worker, error) {
+	if err := config.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	w := &backupWorker{
+		config: config,
+	}
+	w.tomb.Go(w.loop)
+	return w, nil
+}
+
+type backupWorker struct {
+	tomb    tomb.Tomb
+	mu      sync.Mutex
+	config  Config
+	current report
+}
+
+type report struct {
+	schedule       string
+	retentionCount int
+	retentionAge   time.Duration
+	running        bool
+	status         state.BackupScheduleStatus
+}
+
+// Report is shown in the engine report.
+func (w *backupWorker) Report() map[string]interface{} {
+	w.mu.Lock()
+	report := w.current
+	w.mu.Unlock()
+
+	result := map[string]interface{}{
+		"schedule": report.schedule,
+	}
+	if report.retentionCount > 0 {
+		result["retention-count"] = report.retentionCount
+	}
+	if report.retentionAge > 0 {
+		result["retention-age"] = report.retentionAge
+	}
+	if report.running {
+		result["running"] = true
+	}
+	status := report.status
+	if !status.NextRun.IsZero() {
+		result["next-backup"] = status.NextRun.Round(time.Second)
+	}
+	if !status.LastRun.IsZero() {
+		result["last-backup"] = status.LastRun.Round(time.Second)
+	}
+	if status.LastBackupID != "" {
+		result["last-backup-id"] = status.LastBackupID
+	}
+	if status.LastError != "" {
+		result["last-error"] = status.LastError
+	}
+	return result
+}
+
+// setStatus records the progress of the schedule in the controller,
+// and in the report.
+func (w *backupWorker) setStatus(status state.BackupScheduleStatus) error {
+	if err := w.config.Backend.SetBackupScheduleStatus(status); err != nil {
+		return errors.Trace(err)
+	}
+	w.mu.Lock()
+	w.current.status = status
+	w.mu.Unlock()
+	return nil
+}
+
+// backupResult is the outcome of a scheduled backup.
+type backupResult struct {
+	started time.Time
+	id      string
+	err     error
+}
+
+func (w *backupWorker) loop() error {
+	controllerConfigWatcher := w.config.Backend.WatchControllerConfig()
+	defer worker.Stop(controllerConfigWatcher)
+
+	// Carry on from where the last scheduler, which may have been on
+	// another controller machine, left off.
+	status, err := w.config.Backend.BackupScheduleStatus()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	w.mu.Lock()
+	w.current.status = status
+	w.mu.Unlock()
+
+	var (
+		configured bool
+		schedule   *actions.Schedule
+		backup     <-chan time.Time
+		// running is not nil while a backup is running, and
+		// receives its result.
+		running chan backupResult
+	)
+	// resetTimer starts waiting for the next scheduled backup, if any.
+	// A backup that came due while no scheduler was running, such as
+	// during an agent restart or a controller failover, runs now
+	// rather than being skipped.
+	resetTimer := func() error {
+		backup = nil
+		now := w.config.Clock.Now()
+		due := status.NextRun
+		status.NextRun = time.Time{}
+		if schedule != nil {
+			status.NextRun = schedule.Next(now)
+			if !due.IsZero() && !due.After(now) {
+				status.NextRun = due
+			}
+		}
+		if err := w.setStatus(status); err != nil {
+			return errors.Trace(err)
+		}
+		if schedule != nil {
+			backup = w.config.Clock.After(status.NextRun.Sub(now))
+		}
+		return nil
+	}
+
+	for {
+		select {
+		case <-w.tomb.Dying():
+			// Any running backup carries on, but its result is
+			// lost; it is still recorded as due, so the next
+			// scheduler runs it again.
+			return tomb.ErrDying
+
+		case _, ok := <-controllerConfigWatcher.Changes():
+			if !ok {
+				return errors.New("controller configuration watcher closed")
+			}
+			controllerConfig, err := w.config.Backend.ControllerConfig()
+			if err != nil {
+				return errors.Annotate(err, "cannot load controller configuration")
+			}
+			spec := controllerConfig.BackupSchedule()
+			w.mu.Lock()
+			changed := !configured || spec != w.current.schedule
+			w.current.schedule = spec
+			w.current.retentionCount = controllerConfig.BackupRetentionCount()
+			w.current.retentionAge = controllerConfig.BackupRetentionAge()
+			w.mu.Unlock()
+			if !changed {
+				continue
+			}
+			configured = true
+			schedule = nil
+			if spec != "" {
+				if schedule, err = actions.ParseSchedule(spec); err != nil {
+					return errors.Annotate(err, "invalid backup schedule")
+				}
+			}
+			logger.Infof("backup schedule: %q", spec)
+			if running != nil {
+				// The timer is reset when the running
+				// backup finishes.
+				continue
+			}
+			if err := resetTimer(); err != nil {
+				return errors.Trace(err)
+			}
+
+		case <-backup:
+			backup = nil
+			running = make(chan backupResult, 1)
+			w.mu.Lock()
+			w.current.running = true
+			current := w.current
+			w.mu.Unlock()
+			go func(result chan<- backupResult) {
+				started := w.config.Clock.Now()
+				id, err := w.backup(current)
+				result <- backupResult{started: started, id: id, err: err}
+			}(running)
+
+		case result := <-running:
+			running = nil
+			w.mu.Lock()
+			w.current.running = false
+			w.mu.Unlock()
+			// A failed backup is logged and recorded, and tried
+			// again at the next scheduled time; it doesn't stop
+			// the worker.
+			if result.err != nil {
+				logger.Errorf("scheduled backup failed: %v", result.err)
+			}
+			// The backup that was due has run.
+			status.NextRun = time.Time{}
+			status.LastRun = result.started
+			status.LastBackupID = result.id
+			status.LastError = ""
+			if result.err != nil {
+				status.LastError = result.err.Error()
+			}
+			if err := resetTimer(); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+}
+
+// backup creates a scheduled backup, and then removes any scheduled
+// backups beyond the retention limits. It returns the ID of the new
+// backup, if one was made.
+func (w *backupWorker) backup(current report) (string, error) {
+	meta, err := w.config.Backups.Create()
+	if err != nil {
+		return "", errors.Annotate(err, "creating backup")
+	}
+	logger.Infof("created scheduled backup %q", meta.ID())
+	return meta.ID(), errors.Trace(w.prune(current))
+}
+
+// prune removes the scheduled backups that are beyond the retention
+// count or older than the retention age. Backups made on demand are
+// never removed.
+func (w *backupWorker) prune(current report) error {
+	if current.retentionCount == 0 && current.retentionAge == 0 {
+		return nil
+	}
+	all, err := w.config.Backups.List()
+	if err != nil {
+		return errors.Annotate(err, "listing backups")
+	}
+	var scheduled []*backups.Metadata
+	for _, meta := range all {
+		if meta.Scheduled {
+			scheduled = append(scheduled, meta)
+		}
+	}
+	// Newest first.
+	sort.Slice(scheduled, func(i, j int) bool {
+		return scheduled[i].Started.After(scheduled[j].Started)
+	})
+
+	cutoff := w.config.Clock.Now().Add(-current.retentionAge)
+	for i, meta := range scheduled {
+		expired := current.retentionCount > 0 && i >= current.retentionCount
+		if current.retentionAge > 0 && meta.Started.Before(cutoff) {
+			expired = true
+		}
+		if !expired {
+			continue
+		}
+		if err := w.config.Backups.Remove(meta.ID()); err != nil {
+			return errors.Annotatef(err, "removing backup %q", meta.ID())
+		}
+		logger.Infof("removed scheduled backup %q", meta.ID())
+	}
+	return nil
+}
+
+// Kill implements Worker.Kill().
+func (w *backupWorker) Kill() {
+	w.tomb.Kill(nil)
+}
+
+// Wait implements Worker.Wait().
+func (w *backupWorker) Wait() error {
+	return w.tomb.Wait()
+}
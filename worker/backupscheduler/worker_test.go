@@ -0,0 +1,366 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backupscheduler_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/juju/clock/testclock"
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/worker.v1"
+	"gopkg.in/juju/worker.v1/workertest"
+
+	"github.com/juju/juju/controller"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/backups"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/backupscheduler"
+)
+
+type WorkerSuite struct {
+	testing.IsolationSuite
+	clock   *testclock.Clock
+	now     time.Time
+	backend *fakeBackend
+	backups *fakeBackups
+	copyDir string
+}
+
+var _ = gc.Suite(&WorkerSuite{})
+
+func (s *WorkerSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.now = time.Date(2018, 7, 1, 10, 30, 0, 0, time.UTC)
+	s.clock = testclock.NewClock(s.now)
+	s.copyDir = c.MkDir()
+	s.backend = &fakeBackend{
+		changes:       make(chan struct{}, 1),
+		statusChanges: make(chan struct{}, 1),
+		statusSet:     make(chan state.BackupScheduleStatus, 10),
+		config: controller.Config{
+			controller.BackupSchedule: "@daily",
+			controller.BackupCopyDir:  s.copyDir,
+		},
+	}
+	s.backups = &fakeBackups{
+		clock:   s.clock,
+		removed: make(chan string, 10),
+	}
+}
+
+func (s *WorkerSuite) startWorker(c *gc.C) worker.Worker {
+	w, err := backupscheduler.NewWorker(backupscheduler.Config{
+		Backend: s.backend,
+		Backups: s.backups,
+		Clock:   s.clock,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	s.AddCleanup(func(c *gc.C) { workertest.CleanKill(c, w) })
+	s.backend.changes <- struct{}{}
+	return w
+}
+
+// backupDue advances the clock to midnight, when the daily backup is
+// due.
+func (s *WorkerSuite) backupDue(c *gc.C) {
+	err := s.clock.WaitAdvance(13*time.Hour+30*time.Minute, coretesting.LongWait, 1)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+// nextStatus returns the next status recorded by the worker.
+func (s *WorkerSuite) nextStatus(c *gc.C) state.BackupScheduleStatus {
+	select {
+	case status := <-s.backend.statusSet:
+		return status
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for backup schedule status")
+	}
+	return state.BackupScheduleStatus{}
+}
+
+func (s *WorkerSuite) checkRemoved(c *gc.C, expect ...string) {
+	var removed []string
+	for range expect {
+		select {
+		case id := <-s.backups.removed:
+			removed = append(removed, id)
+		case <-time.After(coretesting.LongWait):
+			c.Fatalf("timed out waiting for backups to be removed")
+		}
+	}
+	c.Check(removed, jc.SameContents, expect)
+	select {
+	case id := <-s.backups.removed:
+		c.Fatalf("unexpected removal of %q", id)
+	case <-time.After(coretesting.ShortWait):
+	}
+}
+
+func (s *WorkerSuite) TestValidate(c *gc.C) {
+	_, err := backupscheduler.NewWorker(backupscheduler.Config{
+		Backups: s.backups,
+		Clock:   s.clock,
+	})
+	c.Check(err, gc.ErrorMatches, "nil Backend not valid")
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *WorkerSuite) TestBackupPrunesByCount(c *gc.C) {
+	s.backend.config[controller.BackupRetentionCount] = 2
+	s.backups.add("old-1", s.now.Add(-72*time.Hour), true)
+	s.backups.add("old-2", s.now.Add(-48*time.Hour), true)
+	s.backups.add("manual", s.now.Add(-96*time.Hour), false)
+
+	s.startWorker(c)
+	s.backupDue(c)
+	s.checkRemoved(c, "old-1")
+}
+
+func (s *WorkerSuite) TestBackupPrunesByAge(c *gc.C) {
+	s.backend.config[controller.BackupRetentionAge] = "36h"
+	s.backups.add("old-1", s.now.Add(-72*time.Hour), true)
+	s.backups.add("old-2", s.now.Add(-12*time.Hour), true)
+	s.backups.add("manual", s.now.Add(-96*time.Hour), false)
+
+	s.startWorker(c)
+	s.backupDue(c)
+	s.checkRemoved(c, "old-1")
+}
+
+func (s *WorkerSuite) TestBackupFailureReported(c *gc.C) {
+	s.backups.createErr = errors.New("disk full")
+
+	w := s.startWorker(c)
+	s.backupDue(c)
+	// The worker keeps running, and waits for the next backup.
+	err := s.clock.WaitAdvance(time.Hour, coretesting.LongWait, 1)
+	c.Assert(err, jc.ErrorIsNil)
+	workertest.CheckAlive(c, w)
+
+	report := w.(interface {
+		Report() map[string]interface{}
+	}).Report()
+	c.Check(report["schedule"], gc.Equals, "@daily")
+	c.Check(report["last-error"], gc.Equals, "creating backup: disk full")
+	c.Check(report["next-backup"], gc.Equals, time.Date(2018, 7, 3, 0, 0, 0, 0, time.UTC))
+	c.Check(s.backend.currentStatus().LastError, gc.Equals, "creating backup: disk full")
+}
+
+func (s *WorkerSuite) TestStatusRecorded(c *gc.C) {
+	s.startWorker(c)
+	c.Check(s.nextStatus(c), jc.DeepEquals, state.BackupScheduleStatus{
+		NextRun: time.Date(2018, 7, 2, 0, 0, 0, 0, time.UTC),
+	})
+
+	s.backupDue(c)
+	c.Check(s.nextStatus(c), jc.DeepEquals, state.BackupScheduleStatus{
+		NextRun:      time.Date(2018, 7, 3, 0, 0, 0, 0, time.UTC),
+		LastRun:      time.Date(2018, 7, 2, 0, 0, 0, 0, time.UTC),
+		LastBackupID: "new-1",
+	})
+}
+
+func (s *WorkerSuite) TestCarriesOnFromRecordedStatus(c *gc.C) {
+	lastRun := time.Date(2018, 7, 1, 0, 0, 0, 0, time.UTC)
+	s.backend.status = state.BackupScheduleStatus{
+		NextRun:      time.Date(2018, 7, 2, 0, 0, 0, 0, time.UTC),
+		LastRun:      lastRun,
+		LastBackupID: "earlier",
+	}
+
+	w := s.startWorker(c)
+	c.Check(s.nextStatus(c), jc.DeepEquals, state.BackupScheduleStatus{
+		NextRun:      time.Date(2018, 7, 2, 0, 0, 0, 0, time.UTC),
+		LastRun:      lastRun,
+		LastBackupID: "earlier",
+	})
+	report := w.(interface {
+		Report() map[string]interface{}
+	}).Report()
+	c.Check(report["last-backup-id"], gc.Equals, "earlier")
+}
+
+func (s *WorkerSuite) TestRunsBackupMissedWhileStopped(c *gc.C) {
+	lastRun := time.Date(2018, 6, 30, 0, 0, 0, 0, time.UTC)
+	missed := time.Date(2018, 7, 1, 0, 0, 0, 0, time.UTC)
+	s.backend.status = state.BackupScheduleStatus{
+		NextRun:      missed,
+		LastRun:      lastRun,
+		LastBackupID: "earlier",
+	}
+
+	s.startWorker(c)
+	c.Check(s.nextStatus(c), jc.DeepEquals, state.BackupScheduleStatus{
+		NextRun:      missed,
+		LastRun:      lastRun,
+		LastBackupID: "earlier",
+	})
+	// The missed backup runs straight away, without waiting for the
+	// next scheduled time, and the schedule carries on from there.
+	c.Check(s.nextStatus(c), jc.DeepEquals, state.BackupScheduleStatus{
+		NextRun:      time.Date(2018, 7, 2, 0, 0, 0, 0, time.UTC),
+		LastRun:      s.now,
+		LastBackupID: "new-1",
+	})
+}
+
+func (s *WorkerSuite) TestStopsWithoutWaitingForBackup(c *gc.C) {
+	s.backups.creating = make(chan struct{}, 1)
+	s.backups.release = make(chan struct{})
+	defer close(s.backups.release)
+
+	w := s.startWorker(c)
+	s.backupDue(c)
+	select {
+	case <-s.backups.creating:
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for backup")
+	}
+	workertest.CleanKill(c, w)
+}
+
+type fakeBackend struct {
+	mu            sync.Mutex
+	changes       chan struct{}
+	statusChanges chan struct{}
+	statusSet     chan state.BackupScheduleStatus
+	config        controller.Config
+	status        state.BackupScheduleStatus
+}
+
+func (b *fakeBackend) ControllerConfig() (controller.Config, error) {
+	return b.config, nil
+}
+
+func (b *fakeBackend) WatchControllerConfig() state.NotifyWatcher {
+	return &fakeNotifyWatcher{changes: b.changes}
+}
+
+func (b *fakeBackend) BackupScheduleStatus() (state.BackupScheduleStatus, error) {
+	return b.currentStatus(), nil
+}
+
+func (b *fakeBackend) SetBackupScheduleStatus(status state.BackupScheduleStatus) error {
+	b.mu.Lock()
+	b.status = status
+	b.mu.Unlock()
+	select {
+	case b.statusSet <- status:
+	default:
+	}
+	return nil
+}
+
+func (b *fakeBackend) WatchBackupScheduleStatus() state.NotifyWatcher {
+	return &fakeNotifyWatcher{changes: b.statusChanges}
+}
+
+func (b *fakeBackend) currentStatus() state.BackupScheduleStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.status
+}
+
+type fakeNotifyWatcher struct {
+	changes <-chan struct{}
+}
+
+func (w *fakeNotifyWatcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+func (*fakeNotifyWatcher) Stop() error {
+	return nil
+}
+
+func (*fakeNotifyWatcher) Kill() {}
+
+func (*fakeNotifyWatcher) Wait() error {
+	return nil
+}
+
+func (*fakeNotifyWatcher) Err() error {
+	return nil
+}
+
+type fakeBackups struct {
+	mu        sync.Mutex
+	clock     *testclock.Clock
+	stored    []*backups.Metadata
+	created   int
+	createErr error
+	listErr   error
+	removed   chan string
+
+	// If set, Create signals creating and then waits for release.
+	creating chan struct{}
+	release  chan struct{}
+}
+
+func (b *fakeBackups) add(id string, started time.Time, scheduled bool) *backups.Metadata {
+	meta := backups.NewMetadata()
+	meta.SetID(id)
+	meta.Started = started
+	meta.Scheduled = scheduled
+	b.stored = append(b.stored, meta)
+	return meta
+}
+
+func (b *fakeBackups) Create() (*backups.Metadata, error) {
+	if b.creating != nil {
+		b.creating <- struct{}{}
+		<-b.release
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.createErr != nil {
+		return nil, b.createErr
+	}
+	b.created++
+	id := fmt.Sprintf("new-%d", b.created)
+	return b.add(id, b.clock.Now(), true), nil
+}
+
+func (b *fakeBackups) Get(id string) (*backups.Metadata, io.ReadCloser, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, meta := range b.stored {
+		if meta.ID() == id {
+			archive := ioutil.NopCloser(bytes.NewBufferString("archive of " + id))
+			return meta, archive, nil
+		}
+	}
+	return nil, nil, errors.NotFoundf("backup %q", id)
+}
+
+func (b *fakeBackups) List() ([]*backups.Metadata, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.listErr; err != nil {
+		b.listErr = nil
+		return nil, err
+	}
+	return append([]*backups.Metadata(nil), b.stored...), nil
+}
+
+func (b *fakeBackups) Remove(id string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, meta := range b.stored {
+		if meta.ID() == id {
+			b.stored = append(b.stored[:i], b.stored[i+1:]...)
+			b.removed <- id
+			return nil
+		}
+	}
+	return errors.NotFoundf("backup %q", id)
+}
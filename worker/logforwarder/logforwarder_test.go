@@ -4,6 +4,7 @@
 package logforwarder_test
 
 import (
+	"sync"
 	"time"
 
 	"github.com/juju/errors"
@@ -28,6 +29,7 @@ type LogForwarderSuite struct {
 
 	stream *stubStream
 	sender *stubSender
+	caller *mockCaller
 	rec    logfwd.Record
 }
 
@@ -38,6 +40,7 @@ func (s *LogForwarderSuite) SetUpTest(c *gc.C) {
 
 	s.stream = newStubStream()
 	s.sender = newStubSender()
+	s.caller = &mockCaller{}
 	s.rec = logfwd.Record{
 		Origin: logfwd.Origin{
 			ControllerUUID: "feebdaed-2f18-4fd2-967d-db9663db7bea",
@@ -82,11 +85,13 @@ func (s *LogForwarderSuite) newLogForwarderArgsWithAPI(
 	sender *stubSender,
 ) logforwarder.OpenLogForwarderArgs {
 	return logforwarder.OpenLogForwarderArgs{
-		Caller:           &mockCaller{},
+		Caller:           s.caller,
 		LogForwardConfig: configAPI,
 		ControllerUUID:   "feebdaed-2f18-4fd2-967d-db9663db7bea",
-		OpenSink: func(cfg *syslog.RawConfig) (*logforwarder.LogSink, error) {
-			sender.host = cfg.Host
+		Name:             "juju-log-forward",
+		SinkType:         syslog.SinkType,
+		OpenSink: func(cfg logfwd.SinkConfig) (*logforwarder.LogSink, error) {
+			sender.host = cfg.(*syslog.RawConfig).Host
 			sink := &logforwarder.LogSink{
 				sender,
 			}
@@ -206,10 +211,42 @@ func (s *LogForwarderSuite) TestSenderError(c *gc.C) {
 	})
 }
 
+func (s *LogForwarderSuite) TestFilter(c *gc.C) {
+	rec0 := s.rec
+	rec1 := s.rec
+	rec1.ID = 11
+	rec1.Level = loggo.WARNING
+
+	api := &mockLogForwardConfig{
+		enabled: true,
+		host:    "10.0.0.1",
+		filter:  "level=WARNING",
+	}
+	lf, err := logforwarder.NewLogForwarder(s.newLogForwarderArgsWithAPI(c, api, s.stream, s.sender))
+	c.Assert(err, jc.ErrorIsNil)
+	defer workertest.DirtyKill(c, lf)
+
+	s.stream.addRecords(c, rec0, rec1)
+	s.sender.waitForSend(c)
+	workertest.CleanKill(c, lf)
+
+	c.Check(api.sinkType, gc.Equals, syslog.SinkType)
+
+	// Only the second record is sent, but both are tracked as sent
+	// so the filtered record isn't considered again.
+	s.sender.stub.CheckCalls(c, []testing.StubCall{
+		{"Send", []interface{}{[]logfwd.Record{rec1}}},
+		{"Close", nil},
+	})
+	c.Check(s.caller.lastSentIDs(), jc.DeepEquals, []int64{10, 11})
+}
+
 type mockLogForwardConfig struct {
-	enabled bool
-	host    string
-	changes chan struct{}
+	enabled  bool
+	host     string
+	filter   string
+	sinkType string
+	changes  chan struct{}
 }
 
 type mockWatcher struct {
@@ -230,12 +267,30 @@ func (*mockWatcher) Wait() error {
 
 type mockCaller struct {
 	base.APICaller
+
+	mu       sync.Mutex
+	lastSent []params.LogForwardingSetLastSentParam
 }
 
-func (*mockCaller) APICall(objType string, version int, id, request string, params, response interface{}) error {
+func (c *mockCaller) APICall(objType string, version int, id, request string, args, response interface{}) error {
+	if request == "SetLastSent" {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.lastSent = append(c.lastSent, args.(params.LogForwardingSetLastSentParams).Params...)
+	}
 	return nil
 }
 
+func (c *mockCaller) lastSentIDs() []int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var ids []int64
+	for _, p := range c.lastSent {
+		ids = append(ids, p.RecordID)
+	}
+	return ids
+}
+
 func (*mockCaller) BestFacadeVersion(facade string) int {
 	return 0
 }
@@ -248,13 +303,15 @@ func (c *mockLogForwardConfig) WatchForLogForwardConfigChanges() (watcher.Notify
 	}, nil
 }
 
-func (c *mockLogForwardConfig) LogForwardConfig() (*syslog.RawConfig, bool, error) {
+func (c *mockLogForwardConfig) LogForwardConfig(sinkType string) (logfwd.SinkConfig, bool, error) {
+	c.sinkType = sinkType
 	return &syslog.RawConfig{
 		Enabled:    c.enabled,
 		Host:       c.host,
 		CACert:     coretesting.CACert,
 		ClientCert: coretesting.ServerCert,
 		ClientKey:  coretesting.ServerKey,
+		Filter:     c.filter,
 	}, true, nil
 }
 
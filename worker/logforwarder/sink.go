@@ -4,8 +4,10 @@
 package logforwarder
 
 import (
+	"github.com/juju/errors"
+
 	"github.com/juju/juju/core/watcher"
-	"github.com/juju/juju/logfwd/syslog"
+	"github.com/juju/juju/logfwd"
 )
 
 // LogForwardConfig provides access to the log forwarding config for a model.
@@ -14,22 +16,42 @@ type LogForwardConfig interface {
 	// log forward configuration to change.
 	WatchForLogForwardConfigChanges() (watcher.NotifyWatcher, error)
 
-	// LogForwardConfig returns the current log forward configuration.
-	LogForwardConfig() (*syslog.RawConfig, bool, error)
+	// LogForwardConfig returns the current log forward configuration
+	// for the given type of sink.
+	LogForwardConfig(sinkType string) (logfwd.SinkConfig, bool, error)
 }
 
 type LogSinkSpec struct {
 	// Name is the name of the log sink.
 	Name string
 
+	// Type is the type of the log sink (e.g. syslog.SinkType), used
+	// to look up its configuration.
+	Type string
+
 	// OpenFn is a function that opens a log sink.
 	OpenFn LogSinkFn
 }
 
 // LogSinkFn is a function that opens a log sink.
-type LogSinkFn func(cfg *syslog.RawConfig) (*LogSink, error)
+type LogSinkFn func(cfg logfwd.SinkConfig) (*LogSink, error)
 
 // LogSink is a single log sink, to which log records may be sent.
 type LogSink struct {
 	SendCloser
 }
+
+// filteringSender sends only the records selected by its filter.
+type filteringSender struct {
+	SendCloser
+	filter logfwd.Filter
+}
+
+// Send implements Sender.
+func (s *filteringSender) Send(records []logfwd.Record) error {
+	selected := s.filter.Apply(records)
+	if len(selected) == 0 {
+		return nil
+	}
+	return errors.Trace(s.SendCloser.Send(selected))
+}
@@ -0,0 +1,48 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package sinks
+
+import (
+	"path/filepath"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/logfwd"
+	"github.com/juju/juju/logfwd/logfile"
+	"github.com/juju/juju/worker/logforwarder"
+)
+
+// FileSinkDir is the directory, relative to the agent's log directory,
+// that file log forwarding sinks are confined to. Each model's sinks
+// are confined to a subdirectory named for the model's UUID.
+const FileSinkDir = "logforward"
+
+// NewFileOpener returns a function that opens sinks appending log
+// messages to be forwarded to files within the model's directory
+// under the FileSinkDir directory of logDir. The path in the model
+// config is relative to that directory, so that model users cannot
+// write to other models' files or elsewhere on the controller.
+func NewFileOpener(logDir, modelUUID string) logforwarder.LogSinkFn {
+	dir := filepath.Join(logDir, FileSinkDir, modelUUID)
+	return func(sinkCfg logfwd.SinkConfig) (*logforwarder.LogSink, error) {
+		return openFile(dir, sinkCfg)
+	}
+}
+
+func openFile(dir string, sinkCfg logfwd.SinkConfig) (*logforwarder.LogSink, error) {
+	cfg, ok := sinkCfg.(*logfile.RawConfig)
+	if !ok {
+		return nil, errors.Errorf("expected file log forwarding config, got %T", sinkCfg)
+	}
+	if !cfg.Enabled {
+		return nil, errors.New("log forwarding not enabled")
+	}
+	client, err := logfile.Open(dir, *cfg)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &logforwarder.LogSink{
+		SendCloser: client,
+	}, nil
+}
@@ -0,0 +1,127 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package sinks_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/juju/loggo"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/logfwd"
+	"github.com/juju/juju/logfwd/httpjson"
+	"github.com/juju/juju/logfwd/logfile"
+	"github.com/juju/juju/logfwd/syslog"
+	"github.com/juju/juju/worker/logforwarder/sinks"
+)
+
+const (
+	modelUUID      = "deadbeef-2f18-4fd2-967d-db9663db7bea"
+	otherModelUUID = "9f484882-2f18-4fd2-967d-db9663db7bea"
+)
+
+type SinksSuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&SinksSuite{})
+
+func (s *SinksSuite) TestOpenFile(c *gc.C) {
+	logDir := c.MkDir()
+	sink, err := sinks.NewFileOpener(logDir, modelUUID)(&logfile.RawConfig{
+		Enabled: true,
+		Path:    "juju.log",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = sink.Send([]logfwd.Record{{
+		ID:        10,
+		Timestamp: time.Date(2018, 10, 1, 12, 30, 0, 0, time.UTC),
+		Level:     loggo.INFO,
+		Message:   "hello",
+	}})
+	c.Assert(err, jc.ErrorIsNil)
+	err = sink.Close()
+	c.Assert(err, jc.ErrorIsNil)
+
+	data, err := ioutil.ReadFile(filepath.Join(logDir, sinks.FileSinkDir, modelUUID, "juju.log"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(string(data), jc.Contains, `"message":"hello"`)
+}
+
+func (s *SinksSuite) TestOpenFileSeparatesModels(c *gc.C) {
+	logDir := c.MkDir()
+	for _, uuid := range []string{modelUUID, otherModelUUID} {
+		sink, err := sinks.NewFileOpener(logDir, uuid)(&logfile.RawConfig{
+			Enabled: true,
+			Path:    "juju.log",
+		})
+		c.Assert(err, jc.ErrorIsNil)
+		err = sink.Send([]logfwd.Record{{
+			ID:        10,
+			Timestamp: time.Date(2018, 10, 1, 12, 30, 0, 0, time.UTC),
+			Level:     loggo.INFO,
+			Message:   "hello from " + uuid,
+		}})
+		c.Assert(err, jc.ErrorIsNil)
+		err = sink.Close()
+		c.Assert(err, jc.ErrorIsNil)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(logDir, sinks.FileSinkDir, modelUUID, "juju.log"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(string(data), jc.Contains, "hello from "+modelUUID)
+	c.Check(string(data), gc.Not(jc.Contains), "hello from "+otherModelUUID)
+
+	// A model can't reach another model's file.
+	_, err = sinks.NewFileOpener(logDir, modelUUID)(&logfile.RawConfig{
+		Enabled: true,
+		Path:    "../" + otherModelUUID + "/juju.log",
+	})
+	c.Assert(err, gc.ErrorMatches, `Path .* outside the log directory not valid`)
+}
+
+func (s *SinksSuite) TestOpenFileNotEnabled(c *gc.C) {
+	_, err := sinks.NewFileOpener(c.MkDir(), modelUUID)(&logfile.RawConfig{
+		Path: "juju.log",
+	})
+	c.Assert(err, gc.ErrorMatches, "log forwarding not enabled")
+}
+
+func (s *SinksSuite) TestOpenFileOutsideLogDir(c *gc.C) {
+	logDir := c.MkDir()
+	_, err := sinks.NewFileOpener(logDir, modelUUID)(&logfile.RawConfig{
+		Enabled: true,
+		Path:    "../juju.log",
+	})
+	c.Assert(err, gc.ErrorMatches, `Path "../juju.log" outside the log directory not valid`)
+	_, err = sinks.NewFileOpener(logDir, modelUUID)(&logfile.RawConfig{
+		Enabled: true,
+		Path:    filepath.Join(logDir, "juju.log"),
+	})
+	c.Assert(err, gc.ErrorMatches, `absolute Path .* not valid`)
+}
+
+func (s *SinksSuite) TestOpenHTTP(c *gc.C) {
+	sink, err := sinks.OpenHTTP(&httpjson.RawConfig{
+		Enabled: true,
+		URL:     "http://loki.example.com:3100/loki/api/v1/push",
+		Format:  httpjson.FormatLoki,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(sink.Close(), jc.ErrorIsNil)
+}
+
+func (s *SinksSuite) TestOpenWrongConfigType(c *gc.C) {
+	_, err := sinks.OpenHTTP(&syslog.RawConfig{Enabled: true})
+	c.Check(err, gc.ErrorMatches, `expected HTTP log forwarding config, got \*syslog.RawConfig`)
+	_, err = sinks.NewFileOpener(c.MkDir(), modelUUID)(&httpjson.RawConfig{Enabled: true})
+	c.Check(err, gc.ErrorMatches, `expected file log forwarding config, got \*httpjson.RawConfig`)
+	_, err = sinks.OpenSyslog(&logfile.RawConfig{Enabled: true})
+	c.Check(err, gc.ErrorMatches, `expected syslog config, got \*logfile.RawConfig`)
+}
@@ -10,13 +10,12 @@ import (
 	"github.com/juju/juju/api/base"
 	logfwdapi "github.com/juju/juju/api/logfwd"
 	"github.com/juju/juju/logfwd"
-	"github.com/juju/juju/logfwd/syslog"
 )
 
 // TrackingSinkArgs holds the args to OpenTrackingSender.
 type TrackingSinkArgs struct {
 	// Config is the logging config that will be used.
-	Config *syslog.RawConfig
+	Config logfwd.SinkConfig
 
 	// Caller is the API caller that will be used.
 	Caller base.APICaller
@@ -30,8 +29,15 @@ type TrackingSinkArgs struct {
 }
 
 // OpenTrackingSink opens a log record sender to use with a worker.
-// The sender also tracks records that were successfully sent.
+// The sender only sends the records selected by the config's filter,
+// and tracks records that were successfully sent. Records that are
+// filtered out are tracked as sent, so they are not reconsidered
+// when the worker restarts.
 func OpenTrackingSink(args TrackingSinkArgs) (*LogSink, error) {
+	filter, err := args.Config.RecordFilter()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 	sink, err := args.OpenSink(args.Config)
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -39,8 +45,11 @@ func OpenTrackingSink(args TrackingSinkArgs) (*LogSink, error) {
 
 	return &LogSink{
 		&trackingSender{
-			SendCloser: sink,
-			tracker:    newLastSentTracker(args.Name, args.Caller),
+			SendCloser: &filteringSender{
+				SendCloser: sink,
+				filter:     filter,
+			},
+			tracker: newLastSentTracker(args.Name, args.Caller),
 		},
 	}, nil
 }
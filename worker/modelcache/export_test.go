@@ -0,0 +1,18 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package modelcache
+
+import (
+	"gopkg.in/juju/worker.v1"
+
+	"github.com/juju/juju/core/cache"
+)
+
+// ExtractCacheController returns the cache controller of a model
+// cache worker, as the manifold's output function does.
+func ExtractCacheController(in worker.Worker) (*cache.Controller, error) {
+	var controller *cache.Controller
+	err := output(in, &controller)
+	return controller, err
+}
@@ -0,0 +1,155 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package modelcache_test
+
+import (
+	"time"
+
+	"github.com/juju/clock/testclock"
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/prometheus/client_golang/prometheus"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/worker.v1"
+	"gopkg.in/juju/worker.v1/dependency"
+	dt "gopkg.in/juju/worker.v1/dependency/testing"
+	"gopkg.in/juju/worker.v1/workertest"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/worker/modelcache"
+)
+
+type ManifoldSuite struct {
+	testing.IsolationSuite
+
+	config       modelcache.ManifoldConfig
+	clock        *testclock.Clock
+	registerer   prometheus.Registerer
+	stateTracker stubStateTracker
+	stub         testing.Stub
+}
+
+var _ = gc.Suite(&ManifoldSuite{})
+
+func (s *ManifoldSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.clock = testclock.NewClock(time.Now())
+	s.registerer = prometheus.NewRegistry()
+	s.stateTracker = stubStateTracker{pool: &state.StatePool{}}
+	s.stub.ResetCalls()
+	s.config = modelcache.ManifoldConfig{
+		StateName:            "state",
+		ClockName:            "clock",
+		PrometheusRegisterer: s.registerer,
+		NewWorker: func(config modelcache.Config) (worker.Worker, error) {
+			s.stub.MethodCall(s, "NewWorker", config)
+			if err := s.stub.NextErr(); err != nil {
+				return nil, err
+			}
+			return workertest.NewErrorWorker(nil), nil
+		},
+	}
+}
+
+func (s *ManifoldSuite) manifold() dependency.Manifold {
+	return modelcache.Manifold(s.config)
+}
+
+func (s *ManifoldSuite) newContext(overlay map[string]interface{}) dependency.Context {
+	resources := map[string]interface{}{
+		"state": &s.stateTracker,
+		"clock": s.clock,
+	}
+	for k, v := range overlay {
+		resources[k] = v
+	}
+	return dt.StubContext(nil, resources)
+}
+
+func (s *ManifoldSuite) TestInputs(c *gc.C) {
+	c.Check(s.manifold().Inputs, jc.SameContents, []string{"state", "clock"})
+}
+
+func (s *ManifoldSuite) TestConfigValidation(c *gc.C) {
+	err := s.config.Validate()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ManifoldSuite) TestConfigValidationMissingStateName(c *gc.C) {
+	s.config.StateName = ""
+	err := s.config.Validate()
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+	c.Check(err, gc.ErrorMatches, "empty StateName not valid")
+}
+
+func (s *ManifoldSuite) TestConfigValidationMissingClockName(c *gc.C) {
+	s.config.ClockName = ""
+	err := s.config.Validate()
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+	c.Check(err, gc.ErrorMatches, "empty ClockName not valid")
+}
+
+func (s *ManifoldSuite) TestConfigValidationMissingPrometheusRegisterer(c *gc.C) {
+	s.config.PrometheusRegisterer = nil
+	err := s.config.Validate()
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+	c.Check(err, gc.ErrorMatches, "nil PrometheusRegisterer not valid")
+}
+
+func (s *ManifoldSuite) TestConfigValidationMissingNewWorker(c *gc.C) {
+	s.config.NewWorker = nil
+	err := s.config.Validate()
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+	c.Check(err, gc.ErrorMatches, "nil NewWorker not valid")
+}
+
+func (s *ManifoldSuite) TestMissingInputs(c *gc.C) {
+	for _, input := range []string{"state", "clock"} {
+		context := s.newContext(map[string]interface{}{
+			input: dependency.ErrMissing,
+		})
+		_, err := s.manifold().Start(context)
+		c.Check(errors.Cause(err), gc.Equals, dependency.ErrMissing)
+	}
+}
+
+func (s *ManifoldSuite) TestNewWorkerArgs(c *gc.C) {
+	w, err := s.manifold().Start(s.newContext(nil))
+	c.Assert(err, jc.ErrorIsNil)
+	defer workertest.CleanKill(c, w)
+
+	s.stub.CheckCallNames(c, "NewWorker")
+	config := s.stub.Calls()[0].Args[0].(modelcache.Config)
+	c.Check(config.StatePool, gc.Equals, s.stateTracker.pool)
+	c.Check(config.Clock, gc.Equals, s.clock)
+	c.Check(config.PrometheusRegisterer, gc.Equals, s.registerer)
+}
+
+func (s *ManifoldSuite) TestNewWorkerErrorReleasesState(c *gc.C) {
+	s.stub.SetErrors(errors.New("boom"))
+	_, err := s.manifold().Start(s.newContext(nil))
+	c.Check(err, gc.ErrorMatches, "boom")
+	s.stateTracker.CheckCallNames(c, "Use", "Done")
+}
+
+type stubStateTracker struct {
+	testing.Stub
+	pool *state.StatePool
+}
+
+func (s *stubStateTracker) Use() (*state.StatePool, error) {
+	s.MethodCall(s, "Use")
+	return s.pool, s.NextErr()
+}
+
+func (s *stubStateTracker) Done() error {
+	s.MethodCall(s, "Done")
+	return s.NextErr()
+}
+
+func (s *stubStateTracker) Report() map[string]interface{} {
+	s.MethodCall(s, "Report")
+	return nil
+}
@@ -0,0 +1,320 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package modelcache
+
+import (
+	"github.com/juju/clock"
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/juju/charm.v6"
+	"gopkg.in/juju/worker.v1"
+	"gopkg.in/juju/worker.v1/catacomb"
+
+	"github.com/juju/juju/core/cache"
+	"github.com/juju/juju/core/life"
+	"github.com/juju/juju/core/status"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/multiwatcher"
+)
+
+var logger = loggo.GetLogger("juju.worker.modelcache")
+
+// Config describes the necessary fields for NewWorker.
+type Config struct {
+	StatePool            *state.StatePool
+	Clock                clock.Clock
+	PrometheusRegisterer prometheus.Registerer
+
+	// Notify is used primarily for testing, and is passed through
+	// to the cache.Controller.
+	Notify func(interface{})
+}
+
+// Validate ensures all the necessary values are specified
+func (c *Config) Validate() error {
+	if c.StatePool == nil {
+		return errors.NotValidf("missing state pool")
+	}
+	if c.Clock == nil {
+		return errors.NotValidf("missing clock")
+	}
+	if c.PrometheusRegisterer == nil {
+		return errors.NotValidf("missing prometheus registerer")
+	}
+	return nil
+}
+
+type cacheWorker struct {
+	config     Config
+	catacomb   catacomb.Catacomb
+	controller *cache.Controller
+	changes    chan interface{}
+
+	// appCharms records the charm URL used by each application in
+	// each model, so that charms can be added to the cache when an
+	// application starts using them, and removed when no application
+	// uses them any longer. Charms are not part of the change stream.
+	appCharms map[string]map[string]string
+}
+
+// NewWorker creates a new cacheWorker, and starts an
+// all model watcher.
+func NewWorker(config Config) (worker.Worker, error) {
+	if err := config.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	w := &cacheWorker{
+		config:    config,
+		changes:   make(chan interface{}),
+		appCharms: make(map[string]map[string]string),
+	}
+	controller, err := cache.NewController(cache.ControllerConfig{
+		Changes: w.changes,
+		Clock:   config.Clock,
+		Notify:  config.Notify,
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	w.controller = controller
+	if err := catacomb.Invoke(catacomb.Plan{
+		Site: &w.catacomb,
+		Work: w.loop,
+		Init: []worker.Worker{w.controller},
+	}); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return w, nil
+}
+
+// Report returns information that is used in the dependency engine report.
+func (c *cacheWorker) Report() map[string]interface{} {
+	return c.controller.Report()
+}
+
+func (c *cacheWorker) loop() error {
+	collector := cache.NewMetricsCollector(c.controller)
+	if err := c.config.PrometheusRegisterer.Register(collector); err != nil {
+		return errors.Annotate(err, "registering cache metrics")
+	}
+	defer c.config.PrometheusRegisterer.Unregister(collector)
+
+	pool := c.config.StatePool
+	allWatcher := pool.SystemState().WatchAllModels(pool)
+	defer func() {
+		_ = allWatcher.Stop()
+	}()
+
+	// Next blocks until there are changes, so it is called in its
+	// own goroutine. Stopping the watcher unblocks it.
+	watcherChanges := make(chan []multiwatcher.Delta)
+	go func() {
+		for {
+			deltas, err := allWatcher.Next()
+			if err != nil {
+				if errors.Cause(err) != state.ErrStopped {
+					c.catacomb.Kill(errors.Annotate(err, "watching models"))
+				}
+				return
+			}
+			select {
+			case <-c.catacomb.Dying():
+				return
+			case watcherChanges <- deltas:
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-c.catacomb.Dying():
+			return c.catacomb.ErrDying()
+		case deltas := <-watcherChanges:
+			for _, d := range deltas {
+				for _, change := range c.translate(d) {
+					select {
+					case <-c.catacomb.Dying():
+						return c.catacomb.ErrDying()
+					case c.changes <- change:
+					}
+				}
+			}
+		}
+	}
+}
+
+// translate converts a delta from the all model watcher into the
+// changes understood by the cache. Deltas for entities that aren't
+// cached are ignored.
+func (c *cacheWorker) translate(d multiwatcher.Delta) []interface{} {
+	id := d.Entity.EntityId()
+	switch value := d.Entity.(type) {
+	case *multiwatcher.ModelInfo:
+		if d.Removed {
+			delete(c.appCharms, id.ModelUUID)
+			return []interface{}{cache.RemoveModel{ModelUUID: id.ModelUUID}}
+		}
+		return []interface{}{cache.ModelChange{
+			ModelUUID: value.ModelUUID,
+			Name:      value.Name,
+			Life:      life.Value(value.Life),
+			Owner:     value.Owner,
+			Config:    value.Config,
+			Status:    coreStatus(value.Status),
+		}}
+	case *multiwatcher.ApplicationInfo:
+		if d.Removed {
+			changes := []interface{}{cache.RemoveApplication{
+				ModelUUID: id.ModelUUID,
+				Name:      id.Id,
+			}}
+			return append(changes, c.updateCharms(id.ModelUUID, id.Id, "")...)
+		}
+		changes := c.updateCharms(value.ModelUUID, value.Name, value.CharmURL)
+		return append(changes, cache.ApplicationChange{
+			ModelUUID:       value.ModelUUID,
+			Name:            value.Name,
+			Exposed:         value.Exposed,
+			CharmURL:        value.CharmURL,
+			Life:            life.Value(value.Life),
+			MinUnits:        value.MinUnits,
+			Config:          value.Config,
+			Subordinate:     value.Subordinate,
+			Status:          coreStatus(value.Status),
+			WorkloadVersion: value.WorkloadVersion,
+		})
+	case *multiwatcher.UnitInfo:
+		if d.Removed {
+			return []interface{}{cache.RemoveUnit{
+				ModelUUID: id.ModelUUID,
+				Name:      id.Id,
+			}}
+		}
+		return []interface{}{cache.UnitChange{
+			ModelUUID:      value.ModelUUID,
+			Name:           value.Name,
+			Application:    value.Application,
+			Series:         value.Series,
+			CharmURL:       value.CharmURL,
+			PublicAddress:  value.PublicAddress,
+			PrivateAddress: value.PrivateAddress,
+			MachineId:      value.MachineId,
+			Life:           life.Value(value.Life),
+			Subordinate:    value.Subordinate,
+			WorkloadStatus: coreStatus(value.WorkloadStatus),
+			AgentStatus:    coreStatus(value.AgentStatus),
+		}}
+	case *multiwatcher.MachineInfo:
+		if d.Removed {
+			return []interface{}{cache.RemoveMachine{
+				ModelUUID: id.ModelUUID,
+				Id:        id.Id,
+			}}
+		}
+		return []interface{}{cache.MachineChange{
+			ModelUUID:      value.ModelUUID,
+			Id:             value.Id,
+			InstanceId:     value.InstanceId,
+			Life:           life.Value(value.Life),
+			Series:         value.Series,
+			AgentStatus:    coreStatus(value.AgentStatus),
+			InstanceStatus: coreStatus(value.InstanceStatus),
+			HasVote:        value.HasVote,
+			WantsVote:      value.WantsVote,
+		}}
+	}
+	return nil
+}
+
+// updateCharms records the charm now used by the application, which
+// is empty if the application has been removed. It returns the
+// changes needed to add the charm to the cache if no other application
+// was using it, and to remove the charm the application was using if
+// no other application is using that.
+func (c *cacheWorker) updateCharms(modelUUID, appName, charmURL string) []interface{} {
+	apps, found := c.appCharms[modelUUID]
+	if !found {
+		apps = make(map[string]string)
+		c.appCharms[modelUUID] = apps
+	}
+	previous := apps[appName]
+	if previous == charmURL {
+		return nil
+	}
+
+	var changes []interface{}
+	if charmURL == "" {
+		delete(apps, appName)
+	} else {
+		if !charmInUse(apps, charmURL) {
+			change, err := c.charmChange(modelUUID, charmURL)
+			if err != nil {
+				// The application is still cached, it's just that
+				// the charm details aren't available.
+				logger.Warningf("cannot cache charm %q: %v", charmURL, err)
+			} else {
+				changes = append(changes, change)
+			}
+		}
+		apps[appName] = charmURL
+	}
+	if previous != "" && !charmInUse(apps, previous) {
+		changes = append(changes, cache.RemoveCharm{
+			ModelUUID: modelUUID,
+			CharmURL:  previous,
+		})
+	}
+	return changes
+}
+
+func charmInUse(apps map[string]string, charmURL string) bool {
+	for _, url := range apps {
+		if url == charmURL {
+			return true
+		}
+	}
+	return false
+}
+
+// charmChange reads the charm's details from the database.
+func (c *cacheWorker) charmChange(modelUUID, charmURL string) (cache.CharmChange, error) {
+	curl, err := charm.ParseURL(charmURL)
+	if err != nil {
+		return cache.CharmChange{}, errors.Trace(err)
+	}
+	st, err := c.config.StatePool.Get(modelUUID)
+	if err != nil {
+		return cache.CharmChange{}, errors.Trace(err)
+	}
+	defer st.Release()
+	ch, err := st.Charm(curl)
+	if err != nil {
+		return cache.CharmChange{}, errors.Trace(err)
+	}
+	return cache.CharmChange{
+		ModelUUID:     modelUUID,
+		CharmURL:      charmURL,
+		DefaultConfig: ch.Config().DefaultSettings(),
+	}, nil
+}
+
+func coreStatus(info multiwatcher.StatusInfo) status.StatusInfo {
+	return status.StatusInfo{
+		Status:  info.Current,
+		Message: info.Message,
+		Data:    info.Data,
+		Since:   info.Since,
+	}
+}
+
+// Kill is part of the worker.Worker interface.
+func (c *cacheWorker) Kill() {
+	c.catacomb.Kill(nil)
+}
+
+// Wait is part of the worker.Worker interface.
+func (c *cacheWorker) Wait() error {
+	return c.catacomb.Wait()
+}
@@ -0,0 +1,157 @@
+// Copyright 2018 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package modelcache_test
+
+import (
+	"time"
+
+	"github.com/juju/clock/testclock"
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	"github.com/prometheus/client_golang/prometheus"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/worker.v1"
+	"gopkg.in/juju/worker.v1/workertest"
+
+	"github.com/juju/juju/core/cache"
+	"github.com/juju/juju/core/life"
+	statetesting "github.com/juju/juju/state/testing"
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/modelcache"
+)
+
+type WorkerSuite struct {
+	statetesting.StateSuite
+	config  modelcache.Config
+	notify  func(interface{})
+	changes chan interface{}
+}
+
+var _ = gc.Suite(&WorkerSuite{})
+
+func (s *WorkerSuite) SetUpTest(c *gc.C) {
+	s.StateSuite.SetUpTest(c)
+	s.changes = make(chan interface{}, 100)
+	s.config = modelcache.Config{
+		StatePool:            s.StatePool,
+		Clock:                testclock.NewClock(time.Now()),
+		PrometheusRegisterer: prometheus.NewRegistry(),
+		Notify: func(change interface{}) {
+			select {
+			case s.changes <- change:
+			default:
+				// The test isn't interested in all the changes.
+			}
+		},
+	}
+}
+
+func (s *WorkerSuite) TestConfigMissingStatePool(c *gc.C) {
+	s.config.StatePool = nil
+	c.Check(s.config.Validate(), gc.ErrorMatches, "missing state pool not valid")
+	c.Check(s.config.Validate(), jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *WorkerSuite) TestConfigMissingClock(c *gc.C) {
+	s.config.Clock = nil
+	c.Check(s.config.Validate(), gc.ErrorMatches, "missing clock not valid")
+}
+
+func (s *WorkerSuite) TestConfigMissingRegisterer(c *gc.C) {
+	s.config.PrometheusRegisterer = nil
+	c.Check(s.config.Validate(), gc.ErrorMatches, "missing prometheus registerer not valid")
+}
+
+func (s *WorkerSuite) start(c *gc.C) (worker.Worker, *cache.Controller) {
+	w, err := modelcache.NewWorker(s.config)
+	c.Assert(err, jc.ErrorIsNil)
+	s.AddCleanup(func(c *gc.C) { workertest.DirtyKill(c, w) })
+	controller, err := modelcache.ExtractCacheController(w)
+	c.Assert(err, jc.ErrorIsNil)
+	return w, controller
+}
+
+// waitForChange waits for a change that satisfies the predicate,
+// prodding the state watchers while it does so.
+func (s *WorkerSuite) waitForChange(c *gc.C, predicate func(interface{}) bool) {
+	timeout := time.After(testing.LongWait)
+	for {
+		s.State.StartSync()
+		select {
+		case change := <-s.changes:
+			if predicate(change) {
+				return
+			}
+		case <-time.After(testing.ShortWait):
+		case <-timeout:
+			c.Fatalf("change not seen")
+		}
+	}
+}
+
+func (s *WorkerSuite) TestInitialModel(c *gc.C) {
+	_, controller := s.start(c)
+	s.waitForChange(c, func(change interface{}) bool {
+		model, ok := change.(cache.ModelChange)
+		return ok && model.ModelUUID == s.State.ModelUUID()
+	})
+	c.Check(controller.ModelUUIDs(), jc.SameContents, []string{s.State.ModelUUID()})
+}
+
+func (s *WorkerSuite) TestAddMachine(c *gc.C) {
+	_, controller := s.start(c)
+	machine := s.Factory.MakeMachine(c, nil)
+	s.waitForChange(c, func(change interface{}) bool {
+		ch, ok := change.(cache.MachineChange)
+		return ok && ch.Id == machine.Id()
+	})
+
+	model, err := controller.Model(s.State.ModelUUID())
+	c.Assert(err, jc.ErrorIsNil)
+	cached, err := model.Machine(machine.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(cached.Life(), gc.Equals, life.Alive)
+}
+
+func (s *WorkerSuite) TestAddApplicationAddsCharm(c *gc.C) {
+	_, controller := s.start(c)
+	app := s.Factory.MakeApplication(c, nil)
+	curl, _ := app.CharmURL()
+	s.waitForChange(c, func(change interface{}) bool {
+		ch, ok := change.(cache.ApplicationChange)
+		return ok && ch.Name == app.Name()
+	})
+
+	model, err := controller.Model(s.State.ModelUUID())
+	c.Assert(err, jc.ErrorIsNil)
+	cached, err := model.Application(app.Name())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(cached.CharmURL(), gc.Equals, curl.String())
+	ch, err := model.Charm(curl.String())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(ch.DefaultConfig(), gc.NotNil)
+}
+
+func (s *WorkerSuite) TestRegistersMetrics(c *gc.C) {
+	registry := prometheus.NewRegistry()
+	s.config.PrometheusRegisterer = registry
+	w, _ := s.start(c)
+
+	// The collector is registered once the worker is running.
+	var families int
+	for a := testing.LongAttempt.Start(); a.Next(); {
+		gathered, err := registry.Gather()
+		c.Assert(err, jc.ErrorIsNil)
+		families = len(gathered)
+		if families > 0 {
+			break
+		}
+	}
+	c.Check(families, gc.Not(gc.Equals), 0)
+
+	workertest.CleanKill(c, w)
+	gathered, err := registry.Gather()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(gathered, gc.HasLen, 0)
+}
@@ -7,6 +7,7 @@ package context
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -20,6 +21,7 @@ import (
 	"github.com/juju/juju/api/base"
 	"github.com/juju/juju/api/uniter"
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/caas"
 	"github.com/juju/juju/core/application"
 	"github.com/juju/juju/core/status"
 	"github.com/juju/juju/network"
@@ -521,9 +523,56 @@ func (ctx *HookContext) SetPodSpec(specYaml string) error {
 		//return ErrIsNotLeader
 	}
 	entityName = ctx.unit.ApplicationName()
+	specYaml, err = caas.ResolveSecretData(specYaml, podSpecSecretData{ctx})
+	if err != nil {
+		return errors.Annotate(err, "cannot resolve pod spec secret data")
+	}
 	return ctx.state.SetPodSpec(entityName, specYaml)
 }
 
+// podSpecSecretData supplies the charm config and relation data which
+// the secrets in pod specs take their data from.
+type podSpecSecretData struct {
+	ctx *HookContext
+}
+
+// ConfigValue is part of the caas.SecretDataSource interface.
+func (d podSpecSecretData) ConfigValue(option string) (string, bool, error) {
+	settings, err := d.ctx.ConfigSettings()
+	if err != nil {
+		return "", false, errors.Trace(err)
+	}
+	value, ok := settings[option]
+	if !ok || value == nil {
+		return "", false, nil
+	}
+	return fmt.Sprint(value), true, nil
+}
+
+// RelationValue is part of the caas.SecretDataSource interface.
+func (d podSpecSecretData) RelationValue(endpoint, setting string) (string, bool, error) {
+	var ids []int
+	for id, r := range d.ctx.relations {
+		if r.Name() == endpoint {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		r := d.ctx.relations[id]
+		for _, unit := range r.UnitNames() {
+			settings, err := r.ReadSettings(unit)
+			if err != nil {
+				return "", false, errors.Trace(err)
+			}
+			if value, ok := settings[setting]; ok {
+				return value, true, nil
+			}
+		}
+	}
+	return "", false, nil
+}
+
 // CloudSpec return the cloud specification for the running unit's model
 func (ctx *HookContext) CloudSpec() (*params.CloudSpec, error) {
 	var err error
@@ -568,6 +617,16 @@ func (ctx *HookContext) SetActionFailed() error {
 	return nil
 }
 
+// LogActionMessage records a progress message for the Action. Unlike
+// the results, the message is sent to the controller straight away, so
+// that it may be followed while the Action runs.
+func (ctx *HookContext) LogActionMessage(message string) error {
+	if ctx.actionData == nil {
+		return errors.New("not running an action")
+	}
+	return ctx.state.LogActionMessage(ctx.actionData.Tag, message)
+}
+
 // UpdateActionResults inserts new values for use with action-set and
 // action-fail.  The results struct will be delivered to the controller
 // upon completion of the Action.  It returns an error if not called on an